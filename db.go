@@ -49,6 +49,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"reflect"
 	"sort"
@@ -56,6 +58,7 @@ import (
 
 	"github.com/coregx/relica/internal/core"
 	"github.com/coregx/relica/internal/logger"
+	"github.com/coregx/relica/internal/security"
 	"github.com/coregx/relica/internal/util"
 )
 
@@ -216,6 +219,22 @@ type TxOptions = core.TxOptions
 // It provides insights into connection pool health and usage patterns.
 type PoolStats = core.PoolStats
 
+// Pool is a named connection pool created with DB.Pool.
+type Pool = core.Pool
+
+// ReplicaStatus describes a read replica and its last lag measurement (see DB.Replicas).
+type ReplicaStatus = core.ReplicaStatus
+
+// StmtCacheStats represents prepared statement cache statistics.
+type StmtCacheStats = core.StmtCacheStats
+
+// TagStats is the aggregate of all executions of queries with one tag
+// (see DB.QueryStats).
+type TagStats = core.TagStats
+
+// StmtCacheEntry describes a single cached prepared statement.
+type StmtCacheEntry = core.StmtCacheEntry
+
 // Option is a functional option for configuring DB.
 //
 // Example:
@@ -348,6 +367,39 @@ func (d *DB) Close() error {
 	return d.db.Close()
 }
 
+// Shutdown gracefully closes the database: it rejects new queries and
+// transactions with ErrShuttingDown, waits for the in-flight ones to finish,
+// then closes like Close. If ctx is done first, the database is closed anyway
+// and the context error is returned.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := db.Shutdown(ctx); err != nil {
+//	    log.Printf("database shutdown: %v", err)
+//	}
+func (d *DB) Shutdown(ctx context.Context) error {
+	return d.db.Shutdown(ctx)
+}
+
+// Reconfigure applies options while the database is in use, e.g. to tune a
+// long-running service without restarting it. It is safe to call concurrently
+// with queries. Only WithMaxOpenConns, WithMaxIdleConns, WithConnMaxLifetime,
+// WithConnMaxIdleTime, WithSlowQueryThreshold and WithLogLevel can change at
+// runtime; any other option makes Reconfigure return ErrNotReconfigurable
+// without applying anything.
+//
+// Example:
+//
+//	err := db.Reconfigure(
+//	    relica.WithMaxOpenConns(50),
+//	    relica.WithSlowQueryThreshold(200*time.Millisecond),
+//	    relica.WithLogLevel(slog.LevelWarn))
+func (d *DB) Reconfigure(opts ...Option) error {
+	return d.db.Reconfigure(opts...)
+}
+
 // WithContext returns a new DB with the given context.
 //
 // The context will be used for all subsequent query operations
@@ -418,6 +470,26 @@ func (d *DB) WarmCache(queries []string) (int, error) {
 	return d.db.WarmCache(queries)
 }
 
+// WarmPool pre-establishes n connections before the service starts taking
+// traffic, so the first requests after a deploy do not pay for opening them.
+// If queries are given, they are also prepared into the statement cache as by
+// WarmCache.
+//
+// Idle connections beyond WithMaxIdleConns (2 by default) are closed again, so
+// set it to at least n. n is capped at WithMaxOpenConns.
+// Returns the number of connections established and any error encountered.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithMaxIdleConns(20))
+//	...
+//	if _, err := db.WarmPool(ctx, 20, "SELECT * FROM users WHERE id = $1"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (d *DB) WarmPool(ctx context.Context, n int, queries ...string) (int, error) {
+	return d.db.WarmPool(ctx, n, queries...)
+}
+
 // PinQuery marks a query as pinned in the statement cache, preventing eviction.
 //
 // Pinned queries remain in cache indefinitely, useful for frequently-used queries.
@@ -444,6 +516,214 @@ func (d *DB) UnpinQuery(query string) bool {
 	return d.db.UnpinQuery(query)
 }
 
+// RegisterQuery builds the query returned by build and stores it under name
+// for execution with Query.
+//
+// The query is built once and its statement is prepared and pinned in the
+// statement cache; drivers that prepare on the server (PostgreSQL, MySQL)
+// report a bad query at registration rather than on first use. The
+// parameters given while building are placeholders: Query binds its own
+// parameters in their place, in the same order. Queries without a tag are
+// tagged with name (see QueryStats).
+//
+// Example:
+//
+//	err := db.RegisterQuery("activeUsersByOrg", func(qb *relica.QueryBuilder) *relica.Query {
+//	    return qb.Select("id", "name").From("users").
+//	        Where("org_id = ? AND status = ?", 0, "").
+//	        OrderBy("name").
+//	        Build()
+//	})
+func (d *DB) RegisterQuery(name string, build func(qb *QueryBuilder) *Query) error {
+	var buildErr error
+	err := d.db.RegisterQuery(name, func(qb *core.QueryBuilder) *core.Query {
+		q := build(&QueryBuilder{qb: qb})
+		if q == nil {
+			return nil
+		}
+		buildErr = q.err
+		return q.q
+	})
+	if buildErr != nil {
+		return fmt.Errorf("relica: query %q: %w", name, buildErr)
+	}
+	return err
+}
+
+// UnregisterQuery removes a query registered with RegisterQuery and unpins
+// its statement. Returns false if no query is registered under name.
+func (d *DB) UnregisterQuery(name string) bool {
+	return d.db.UnregisterQuery(name)
+}
+
+// RegisteredQueries returns the sorted names of the queries registered with RegisterQuery.
+func (d *DB) RegisteredQueries() []string {
+	return d.db.RegisteredQueries()
+}
+
+// Query returns the query registered under name (see RegisterQuery) with
+// params bound in place of the parameters it was built with.
+//
+// An unknown name (ErrQueryNotRegistered) or a wrong number of parameters is
+// returned when the query is executed.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Query("activeUsersByOrg", orgID, "active").WithContext(ctx).All(&users)
+func (d *DB) Query(name string, params ...interface{}) *Query {
+	return &Query{q: d.db.Query(name, params...)}
+}
+
+// StmtCacheStats returns prepared statement cache statistics
+// (hits, misses, evictions, size, pinned count and hit rate).
+//
+// Example:
+//
+//	stats := db.StmtCacheStats()
+//	if stats.HitRate < 0.9 && stats.Evictions > 0 {
+//	    log.Printf("statement cache too small: %d/%d", stats.Size, stats.Capacity)
+//	}
+func (d *DB) StmtCacheStats() StmtCacheStats {
+	return d.db.StmtCacheStats()
+}
+
+// QueryStats returns per-tag execution statistics (count, error rate, p50/p95
+// latency) of queries named with Tag, keyed by tag.
+//
+// Example:
+//
+//	s := db.QueryStats()["checkout.load_cart"]
+//	log.Printf("n=%d p95=%s errors=%.2f", s.Count, s.P95, s.ErrorRate)
+func (d *DB) QueryStats() map[string]TagStats {
+	return d.db.QueryStats()
+}
+
+// ResetQueryStats discards all per-tag statistics.
+func (d *DB) ResetQueryStats() {
+	d.db.ResetQueryStats()
+}
+
+// StmtCacheEntries returns a snapshot of the cached statements with their hit
+// counts and last-used times, ordered from most to least recently used.
+//
+// Example:
+//
+//	for _, e := range db.StmtCacheEntries() {
+//	    fmt.Println(e.Hits, e.LastUsed, e.SQL)
+//	}
+func (d *DB) StmtCacheEntries() []StmtCacheEntry {
+	return d.db.StmtCacheEntries()
+}
+
+// InvalidateStatements closes and evicts cached prepared statements that reference
+// table (pinned statements included) and returns how many were evicted.
+//
+// DDL executed through relica (ALTER/DROP/TRUNCATE/RENAME/CREATE) invalidates
+// affected statements automatically; call this after schema changes applied by
+// other processes, such as external migration tools.
+//
+// Example:
+//
+//	db.InvalidateStatements("users")
+func (d *DB) InvalidateStatements(table string) int {
+	return d.db.InvalidateStatements(table)
+}
+
+// Pool returns the named connection pool, creating it on first use.
+//
+// A named pool is a separate connection pool opened with the same driver and DSN,
+// with its own connection limits and statement cache. Route queries to it with
+// OnPool so heavy analytical queries cannot starve the transactional pool.
+// Named pools are closed by DB.Close. They are not available for WrapDB.
+//
+// Example:
+//
+//	reporting, err := db.Pool("reporting")
+//	reporting.SetMaxOpenConns(4)
+//	reporting.SetConnMaxLifetime(5 * time.Minute)
+//
+//	db.Builder().Select("region", "SUM(total)").From("orders").
+//	    GroupBy("region").OnPool("reporting").All(&rows)
+func (d *DB) Pool(name string) (*Pool, error) {
+	return d.db.Pool(name)
+}
+
+// Conn returns a DB handle whose queries all run on a single pooled
+// connection, so advisory locks, temporary tables and session variables
+// persist across queries without a transaction. Transactions begun on the
+// handle use the same connection. Close the handle to return the connection
+// to the pool; it does not close the DB. Do not use the handle concurrently.
+//
+// Example:
+//
+//	conn, err := db.Conn(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer conn.Close()
+//
+//	conn.ExecContext(ctx, "SET search_path TO tenant_42")
+//	conn.Select().From("orders").All(&orders) // same session
+func (d *DB) Conn(ctx context.Context) (*DB, error) {
+	c, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: c}, nil
+}
+
+// Pipeline collects the statements of DB.Pipeline.
+type Pipeline struct {
+	queries []*core.Query
+	err     error // first construction error of a queued query
+}
+
+// Queue adds q to the pipeline. Statements run in the order queued.
+func (p *Pipeline) Queue(q *Query) {
+	if q.err != nil && p.err == nil {
+		p.err = fmt.Errorf("relica: pipeline statement %d: %w", len(p.queries), q.err)
+	}
+	p.queries = append(p.queries, q.q)
+}
+
+// Pipeline runs the statements queued by fn as a unit, all or nothing, and
+// returns the rows affected by each in queue order.
+//
+// On PostgreSQL, INSERT, UPDATE and DELETE statements are sent in a single
+// round trip, combined into one statement of data-modifying CTEs with all
+// parameters still bound. They share one snapshot, so queue only independent
+// statements: none sees the changes of another, and a row must not be
+// modified twice. Other statements and databases run one by one in a
+// transaction.
+//
+// Example:
+//
+//	affected, err := db.Pipeline(ctx, func(p *relica.Pipeline) {
+//	    for _, e := range events {
+//	        p.Queue(db.Insert("events", e))
+//	    }
+//	    p.Queue(db.Update("counters").Set(counts).Where(relica.Eq("id", 1)).Build())
+//	})
+func (d *DB) Pipeline(ctx context.Context, fn func(*Pipeline)) ([]int64, error) {
+	p := &Pipeline{}
+	fn(p)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return d.db.Pipeline(ctx, func(cp *core.Pipeline) {
+		for _, q := range p.queries {
+			cp.Queue(q)
+		}
+	})
+}
+
+// Replicas returns the status of the read replicas (see WithReplicas), in
+// registration order, including their last measured replication lag.
+func (d *DB) Replicas() []ReplicaStatus {
+	return d.db.Replicas()
+}
+
 // Builder returns a new QueryBuilder for constructing queries.
 //
 // The query builder provides a fluent interface for building
@@ -471,7 +751,12 @@ func (d *DB) Builder() *QueryBuilder {
 //
 //	// With parameters
 //	var user User
-//	err := db.NewQuery("SELECT * FROM users WHERE id = ?").Bind(1).One(&user)
+//	err := db.NewQuery("SELECT * FROM users WHERE id = ?", 1).One(&user)
+//
+//	// With a context
+//	err := db.NewQuery("DELETE FROM sessions WHERE expires_at < ?", now).
+//	    WithContext(ctx).
+//	    Execute()
 //
 //	// With Prepare for repeated execution
 //	q := db.NewQuery("SELECT * FROM users WHERE status = ?").Prepare()
@@ -479,8 +764,11 @@ func (d *DB) Builder() *QueryBuilder {
 //	for _, status := range statuses {
 //	    q.Bind(status).All(&users)
 //	}
-func (d *DB) NewQuery(query string) *Query {
-	return &Query{q: d.db.NewQuery(query)}
+//
+// Raw queries go through the same logging, tracing hooks, statement cache and
+// concurrency limits as builder queries.
+func (d *DB) NewQuery(query string, params ...interface{}) *Query {
+	return &Query{q: d.db.NewQuery(query, params...)}
 }
 
 // Model creates a ModelQuery for performing CRUD operations on a struct model.
@@ -525,6 +813,182 @@ func (d *DB) Model(model interface{}) *ModelQuery {
 	return &ModelQuery{mq: d.db.Model(model)}
 }
 
+// NamingStrategy maps a Go struct field name to a database column name for
+// exported fields without a db tag.
+type NamingStrategy = core.NamingStrategy
+
+// Built-in naming strategies for SetNamingStrategy.
+var (
+	// FieldNameNaming uses the field name unchanged (the default).
+	FieldNameNaming = core.FieldNameNaming
+
+	// SnakeCaseNaming maps "CreatedAt" to "created_at" and "UserID" to "user_id".
+	SnakeCaseNaming = core.SnakeCaseNaming
+
+	// CamelCaseNaming maps "CreatedAt" to "createdAt" and "ID" to "id".
+	CamelCaseNaming = core.CamelCaseNaming
+)
+
+// SetNamingStrategy sets how fields without a db tag map to columns, for both
+// scanning and Model writes. nil restores the default. The setting is global:
+// call it once during initialization, before running queries.
+//
+// Fields tagged relica:"pk" form the primary key regardless of their name,
+// which fits legacy schemas whose key is not "id".
+//
+// Example:
+//
+//	relica.SetNamingStrategy(relica.SnakeCaseNaming)
+//
+//	type Customer struct {
+//	    CustomerNo int `relica:"pk"` // column customer_no, primary key
+//	    FullName   string            // column full_name
+//	}
+func SetNamingStrategy(ns NamingStrategy) {
+	core.SetNamingStrategy(ns)
+}
+
+// TypedQuery is a SELECT query whose rows are scanned into values of type T.
+// Create one with Q or QTx.
+type TypedQuery[T any] = core.TypedQuery[T]
+
+// Q creates a typed query for the struct type T.
+//
+// The table is T's TableName() if implemented, otherwise the pluralized
+// lowercase struct name. The SELECT list contains the columns mapped by T's
+// fields. Results are returned as []T or T, with no interface{} destination.
+//
+// Example:
+//
+//	users, err := relica.Q[User](db).
+//	    Where(relica.Eq("status", "active")).
+//	    OrderBy("name").
+//	    All(ctx)
+func Q[T any](db *DB) *TypedQuery[T] {
+	return core.NewTypedQuery[T](db.db.Builder())
+}
+
+// QTx creates a typed query for the struct type T within a transaction.
+//
+// Example:
+//
+//	user, err := relica.QTx[User](tx).Where("id = ?", 1).One(ctx)
+func QTx[T any](tx *Tx) *TypedQuery[T] {
+	return core.NewTypedQuery[T](tx.tx.Builder())
+}
+
+// RowData is one raw result row passed to a RowMapper, keyed by column name.
+type RowData = core.RowData
+
+// RowMapper converts one raw result row into a T (see MapRows and TypedQuery.Map).
+type RowMapper[T any] = core.RowMapper[T]
+
+// MapRows executes sq and returns fn applied to each row as it is read,
+// without scanning into an intermediate slice.
+//
+// Example:
+//
+//	users, err := relica.MapRows(db.Builder().Select("*").From("users"),
+//	    func(raw relica.RowData) (User, error) {
+//	        email, err := decrypt(raw.Bytes("email_enc"))
+//	        return User{ID: int(raw.Int64("id")), Email: email}, err
+//	    })
+func MapRows[T any](sq *SelectQuery, fn RowMapper[T]) ([]T, error) {
+	return core.MapRows(sq.sq, fn)
+}
+
+// BatchRows executes sq in batches of up to size rows using keyset pagination
+// (WHERE key > last ORDER BY key LIMIT size), passing each batch to fn.
+// No transaction or cursor is held between batches, so multi-million-row
+// tables can be processed safely; BatchTimeout gives each batch query its own
+// deadline. sq must not have ORDER BY, LIMIT or OFFSET. For typed queries,
+// use TypedQuery.Batch.
+//
+// Example:
+//
+//	err := relica.BatchRows(db.Select().From("events").Where("archived = ?", false), 1000,
+//	    func(batch []Event) error {
+//	        return archive(batch)
+//	    }, relica.BatchTimeout(30*time.Second))
+func BatchRows[T any](sq *SelectQuery, size int, fn func(batch []T) error, opts ...BatchOption) error {
+	return core.BatchRows(sq.sq, size, fn, opts...)
+}
+
+// BatchOption configures BatchRows and TypedQuery.Batch.
+type BatchOption = core.BatchOption
+
+// BatchKey sets the unique, ordered column batches are paginated by
+// (default: the primary key of T, or "id"). Qualify it ("u.id") for joins.
+func BatchKey(col string) BatchOption { return core.BatchKey(col) }
+
+// BatchTimeout gives each batch query its own deadline of d; the callback is not subject to it.
+func BatchTimeout(d time.Duration) BatchOption { return core.BatchTimeout(d) }
+
+// Repository provides generic CRUD operations for the struct type T.
+// Writes follow Model semantics; reads use TypedQuery.
+type Repository[T any] struct {
+	r *core.Repository[T]
+}
+
+// NewRepository creates a repository for the struct type T.
+//
+// Example:
+//
+//	users := relica.NewRepository[User](db)
+//	user, err := users.Get(ctx, 42)
+//	active, err := users.List(ctx, relica.HashExp{"status": "active"})
+func NewRepository[T any](db *DB) *Repository[T] {
+	return &Repository[T]{r: core.NewRepository[T](db.db)}
+}
+
+// WithTx returns a copy of the repository whose operations run in tx.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    return users.WithTx(tx).Create(ctx, &user)
+//	})
+func (r *Repository[T]) WithTx(tx *Tx) *Repository[T] {
+	return &Repository[T]{r: r.r.WithTx(tx.tx)}
+}
+
+// Query returns a typed query for T, for reads not covered by Get and List.
+func (r *Repository[T]) Query() *TypedQuery[T] {
+	return r.r.Query()
+}
+
+// Get returns the row whose primary key equals id, or sql.ErrNoRows.
+// T must have a single-column primary key.
+func (r *Repository[T]) Get(ctx context.Context, id interface{}) (T, error) {
+	return r.r.Get(ctx, id)
+}
+
+// List returns the rows matching filter (any Where condition, or nil for all rows).
+func (r *Repository[T]) List(ctx context.Context, filter interface{}) ([]T, error) {
+	return r.r.List(ctx, filter)
+}
+
+// Count returns the number of rows matching filter (nil counts all rows).
+func (r *Repository[T]) Count(ctx context.Context, filter interface{}) (int64, error) {
+	return r.r.Count(ctx, filter)
+}
+
+// Create inserts entity. An auto-increment primary key is set on entity.
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	return r.r.Create(ctx, entity)
+}
+
+// Update updates entity by primary key. If attrs are given, only those
+// columns are updated.
+func (r *Repository[T]) Update(ctx context.Context, entity *T, attrs ...string) error {
+	return r.r.Update(ctx, entity, attrs...)
+}
+
+// Delete deletes entity by primary key.
+func (r *Repository[T]) Delete(ctx context.Context, entity *T) error {
+	return r.r.Delete(ctx, entity)
+}
+
 // Select creates a new SELECT query.
 //
 // This is a convenience method equivalent to db.Builder().Select(cols...).
@@ -627,7 +1091,8 @@ func (d *DB) InsertStruct(table string, data interface{}) *Query {
 //	    return err
 //	}
 //
-// For single struct inserts, use InsertStruct instead.
+// For single struct inserts, use InsertStruct instead. To split large slices
+// into several statements and populate generated IDs, use InsertAll.
 func (d *DB) BatchInsertStruct(table string, data interface{}) *Query {
 	return d.Builder().BatchInsertStruct(table, data)
 }
@@ -717,6 +1182,42 @@ func (d *DB) BatchInsert(table string, columns []string) *BatchInsertQuery {
 	return d.Builder().BatchInsert(table, columns)
 }
 
+// InsertAll creates a query inserting a slice of structs with multi-row
+// INSERT statements.
+//
+// This is a convenience method equivalent to db.Builder().InsertAll(table, rows).
+//
+// Example:
+//
+//	users := []User{{Name: "Alice"}, {Name: "Bob"}}
+//	n, err := db.InsertAll("users", users).Execute()
+//	// users[0].ID and users[1].ID are populated
+func (d *DB) InsertAll(table string, rows interface{}) *InsertAllQuery {
+	return d.Builder().InsertAll(table, rows)
+}
+
+// BatchDelete creates a query deleting rows by key in chunks.
+//
+// This is a convenience method equivalent to db.Builder().BatchDelete(table, keyColumn).
+//
+// Example:
+//
+//	n, err := db.BatchDelete("events", "id").Keys(ids).ChunkSize(5000).Execute()
+func (d *DB) BatchDelete(table, keyColumn string) *BatchDeleteQuery {
+	return d.Builder().BatchDelete(table, keyColumn)
+}
+
+// Truncate creates a query removing every row of table.
+//
+// This is a convenience method equivalent to db.Builder().Truncate(table).
+//
+// Example:
+//
+//	err := db.Truncate("events").RestartIdentity().Execute()
+func (d *DB) Truncate(table string) *TruncateQuery {
+	return d.Builder().Truncate(table)
+}
+
 // BatchUpdate creates a new batch UPDATE query for updating multiple rows with different values.
 //
 // This is a convenience method equivalent to db.Builder().BatchUpdate(table, keyColumn).
@@ -731,74 +1232,410 @@ func (d *DB) BatchUpdate(table, keyColumn string) *BatchUpdateQuery {
 	return d.Builder().BatchUpdate(table, keyColumn)
 }
 
-// Upsert creates a new UPSERT query (INSERT ... ON CONFLICT).
-//
-// This is a convenience method equivalent to db.Builder().Upsert(table, values).
+// ImportCSV streams CSV rows from r into table using batched multi-row INSERTs
+// inside one transaction. The first row is the header unless opts.Columns is
+// set; ColumnMap renames headers, Coerce converts fields per column, OnError
+// decides whether bad rows are skipped or abort the import, and Progress
+// reports the totals after each batch.
 //
 // Example:
 //
-//	result, err := db.Upsert("users", map[string]interface{}{
-//	    "email": "alice@example.com",
-//	    "name":  "Alice",
-//	}).OnConflict("email").DoUpdate("name").Execute()
-func (d *DB) Upsert(table string, values map[string]interface{}) *UpsertQuery {
-	return d.Builder().Upsert(table, values)
+//	res, err := db.ImportCSV(ctx, "users", file, relica.ImportOptions{
+//	    ColumnMap: map[string]string{"E-mail": "email", "Age": "age"},
+//	    Coerce:    map[string]relica.CoerceFunc{"age": relica.CoerceInt},
+//	    OnError:   func(err *relica.ImportError) error { return nil }, // skip, see res.Errors
+//	})
+func (d *DB) ImportCSV(ctx context.Context, table string, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	return d.db.ImportCSV(ctx, table, r, opts)
 }
 
-// Begin starts a transaction with default options.
-//
-// The transaction must be committed or rolled back to release resources.
-// It's safe to call Rollback() even after Commit().
+// CreateOutboxTable creates the outbox table used by Tx.Outbox and OutboxRelay
+// if it does not exist. An empty name uses DefaultOutboxTable.
+func (d *DB) CreateOutboxTable(ctx context.Context, table string) error {
+	return d.db.CreateOutboxTable(ctx, table)
+}
+
+// CreateHistoryTable creates the history table of table (see WithHistory) if
+// it does not exist: valid_from and valid_to timestamps followed by a copy of
+// the table's columns, without constraints or defaults.
+func (d *DB) CreateHistoryTable(ctx context.Context, table string) error {
+	return d.db.CreateHistoryTable(ctx, table)
+}
+
+// OutboxRelay returns a relay that dispatches outbox messages to handler and
+// deletes them once delivered. Messages are claimed with FOR UPDATE SKIP LOCKED
+// on PostgreSQL and MySQL 8, so several relays can poll the same table.
 //
 // Example:
 //
-//	tx, err := db.Begin(ctx)
-//	if err != nil {
-//	    return err
-//	}
-//	defer tx.Rollback() // Safe even after Commit
+//	relay := db.OutboxRelay(func(ctx context.Context, msg relica.OutboxMessage) error {
+//	    return broker.Publish(ctx, msg.Topic, msg.Payload)
+//	}).MaxAttempts(10)
+//	go relay.Run(ctx, time.Second)
+func (d *DB) OutboxRelay(handler OutboxHandler) *OutboxRelay {
+	return d.db.OutboxRelay(handler)
+}
+
+// AdvisoryLock acquires a named, database-wide lock, waiting until it is free
+// or ctx is done. It uses pg_advisory_lock on PostgreSQL and GET_LOCK on MySQL,
+// holding one pool connection until Unlock; SQLite falls back to rows of a
+// relica_locks table.
 //
-//	// Use transaction
-//	_, err = tx.Insert("users", data).Execute()
+// Example:
+//
+//	lock, err := db.AdvisoryLock(ctx, "migrations")
 //	if err != nil {
 //	    return err
 //	}
-//
-//	return tx.Commit()
-func (d *DB) Begin(ctx context.Context) (*Tx, error) {
-	coreTx, err := d.db.Begin(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return &Tx{tx: coreTx}, nil
+//	defer lock.Unlock()
+func (d *DB) AdvisoryLock(ctx context.Context, key string) (*AdvisoryLock, error) {
+	return d.db.AdvisoryLock(ctx, key)
 }
 
-// BeginTx starts a transaction with specified options.
-//
-// Options can specify isolation level and read-only mode:
-//   - Isolation: sql.LevelReadUncommitted, sql.LevelReadCommitted,
-//     sql.LevelRepeatableRead, sql.LevelSerializable
-//   - ReadOnly: true for read-only transactions (some databases optimize these)
+// TryAdvisoryLock acquires a named lock if it is free and returns ErrLockHeld
+// otherwise, e.g. to run a scheduled job on a single instance.
 //
 // Example:
 //
-//	opts := &relica.TxOptions{
-//	    Isolation: sql.LevelSerializable,
-//	    ReadOnly:  false,
+//	lock, err := db.TryAdvisoryLock(ctx, "cron:daily-report")
+//	if errors.Is(err, relica.ErrLockHeld) {
+//	    return nil // another instance runs the job
 //	}
-//	tx, err := db.BeginTx(ctx, opts)
-func (d *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
-	coreTx, err := d.db.BeginTx(ctx, opts)
-	if err != nil {
-		return nil, err
-	}
-	return &Tx{tx: coreTx}, nil
+func (d *DB) TryAdvisoryLock(ctx context.Context, key string) (*AdvisoryLock, error) {
+	return d.db.TryAdvisoryLock(ctx, key)
 }
 
-// Transactional executes f within a transaction with automatic commit/rollback.
+// CreateSequence creates the named sequence if it does not exist: CREATE
+// SEQUENCE on PostgreSQL, or the relica_sequences counter table on MySQL and SQLite.
+func (d *DB) CreateSequence(ctx context.Context, name string) error {
+	return d.db.CreateSequence(ctx, name)
+}
+
+// NextSequence returns the next value of the named sequence, starting at 1.
+// PostgreSQL uses nextval; MySQL and SQLite increment a relica_sequences row.
 //
-// If f returns an error, the transaction is rolled back and the error is returned.
-// If f panics, the transaction is rolled back and the panic is re-raised.
+// Example:
+//
+//	n, err := db.NextSequence(ctx, "order_seq")
+func (d *DB) NextSequence(ctx context.Context, name string) (int64, error) {
+	return d.db.NextSequence(ctx, name)
+}
+
+// TableStats describes the size and maintenance state of a table: estimated
+// rows, table and index sizes, estimated bloat, and the last ANALYZE and
+// VACUUM times. Values the database does not report are zero.
+type TableStats = core.TableStats
+
+// TableStats returns the size and maintenance statistics of table, which may
+// be schema-qualified ("app.orders"):
+//
+//   - PostgreSQL: planner row estimate, dead rows, pg_table_size and
+//     pg_indexes_size, bloat estimated from the dead row share, and the last
+//     (auto)analyze and (auto)vacuum times.
+//   - MySQL: information_schema row estimate, data and index length,
+//     data_free as bloat, and the last statistics update if mysql.innodb_table_stats
+//     is readable.
+//   - SQLite: exact row count, and sizes when the driver has the dbstat table.
+//
+// Example:
+//
+//	stats, err := db.TableStats(ctx, "orders")
+//	metrics.Gauge("orders_bytes", stats.TotalBytes)
+func (d *DB) TableStats(ctx context.Context, table string) (*TableStats, error) {
+	return d.db.TableStats(ctx, table)
+}
+
+// ActiveQuery is a statement running in a database session: its process ID,
+// user, database, session state, statement text, start and duration.
+type ActiveQuery = core.ActiveQuery
+
+// ActiveQueries returns the statements running in other sessions of the
+// database server, longest running first, from pg_stat_activity on
+// PostgreSQL and the process list on MySQL. SQLite returns
+// ErrUnsupportedDialect.
+//
+// Example:
+//
+//	queries, err := db.ActiveQueries(ctx)
+//	for _, q := range queries {
+//	    log.Printf("%d %s %s: %s", q.PID, q.User, q.Duration, q.Query)
+//	}
+func (d *DB) ActiveQueries(ctx context.Context) ([]ActiveQuery, error) {
+	return d.db.ActiveQueries(ctx)
+}
+
+// CancelQuery cancels the statement running in the session with process ID
+// pid, as listed by ActiveQueries, without closing the session
+// (pg_cancel_backend on PostgreSQL, KILL QUERY on MySQL). The cancellation
+// is written to the audit log as a CANCEL operation.
+//
+// Example:
+//
+//	err := db.CancelQuery(ctx, q.PID)
+func (d *DB) CancelQuery(ctx context.Context, pid int64) error {
+	return d.db.CancelQuery(ctx, pid)
+}
+
+// Schema returns the schema helpers of the database (materialized views).
+func (d *DB) Schema() *Schema {
+	return &Schema{s: d.db.Schema()}
+}
+
+// RefreshMaterializedView re-runs the query of the named materialized view
+// and replaces its contents. With Concurrently, readers keep reading the old
+// contents during the refresh (the view needs a unique index).
+// PostgreSQL only; other databases return ErrUnsupportedDialect.
+//
+// Example:
+//
+//	err := db.RefreshMaterializedView(ctx, "daily_revenue", relica.Concurrently())
+func (d *DB) RefreshMaterializedView(ctx context.Context, name string, opts ...ViewOption) error {
+	return d.db.RefreshMaterializedView(ctx, name, opts...)
+}
+
+// Schema manages database objects (see DB.Schema).
+type Schema struct {
+	s *core.Schema
+}
+
+// CreateMaterializedView creates the named materialized view from query if it
+// does not exist, and populates it unless WithNoData is given. The query
+// cannot have bind parameters; write constants into it instead.
+// PostgreSQL only; other databases return ErrUnsupportedDialect.
+//
+// Example:
+//
+//	daily := db.Select("date_trunc('day', created_at) AS day", "SUM(total) AS revenue").
+//	    From("orders").
+//	    GroupBy("day")
+//	err := db.Schema().CreateMaterializedView(ctx, "daily_revenue", daily)
+func (s *Schema) CreateMaterializedView(ctx context.Context, name string, query *SelectQuery, opts ...ViewOption) error {
+	var sq *core.SelectQuery
+	if query != nil {
+		sq = query.sq
+	}
+	return s.s.CreateMaterializedView(ctx, name, sq, opts...)
+}
+
+// DropMaterializedView drops the named materialized view if it exists.
+func (s *Schema) DropMaterializedView(ctx context.Context, name string) error {
+	return s.s.DropMaterializedView(ctx, name)
+}
+
+// ViewOption configures materialized view creation and refresh.
+type ViewOption = core.ViewOption
+
+// Concurrently refreshes a materialized view without locking out readers.
+// PostgreSQL requires a unique index on the view.
+func Concurrently() ViewOption { return core.Concurrently() }
+
+// WithNoData creates or refreshes a materialized view without running its
+// query, leaving it unreadable until the next refresh.
+func WithNoData() ViewOption { return core.WithNoData() }
+
+// Maintenance returns the table maintenance commands of the database:
+// ANALYZE, VACUUM and OPTIMIZE, each mapped to the statement of the dialect
+// and written to the audit log.
+//
+// Example:
+//
+//	err := db.Maintenance().Vacuum(ctx, "orders", relica.Full())
+func (d *DB) Maintenance() *Maintenance {
+	return &Maintenance{m: d.db.Maintenance()}
+}
+
+// Maintenance runs table maintenance commands (see DB.Maintenance). They
+// cannot run inside a transaction on PostgreSQL and SQLite. Every statement
+// is written to the audit log (see WithAuditLog) with the operation ANALYZE,
+// VACUUM or OPTIMIZE, also at the AuditWrites level.
+type Maintenance struct {
+	m *core.Maintenance
+}
+
+// Analyze updates the planner statistics of table: ANALYZE on PostgreSQL and
+// SQLite, ANALYZE TABLE on MySQL. On PostgreSQL and SQLite an empty table
+// analyzes the whole database.
+func (m *Maintenance) Analyze(ctx context.Context, table string) error {
+	return m.m.Analyze(ctx, table)
+}
+
+// Vacuum reclaims the space of deleted and updated rows of table: VACUUM
+// [FULL] on PostgreSQL, OPTIMIZE TABLE on MySQL, and VACUUM of the whole
+// database file on SQLite. On PostgreSQL an empty table vacuums every table.
+func (m *Maintenance) Vacuum(ctx context.Context, table string, opts ...MaintenanceOption) error {
+	return m.m.Vacuum(ctx, table, opts...)
+}
+
+// OptimizeTable rebuilds table and refreshes its statistics: VACUUM (FULL,
+// ANALYZE) on PostgreSQL, OPTIMIZE TABLE on MySQL, VACUUM and ANALYZE on
+// SQLite. The table is locked while it is rebuilt.
+func (m *Maintenance) OptimizeTable(ctx context.Context, table string) error {
+	return m.m.OptimizeTable(ctx, table)
+}
+
+// MaintenanceOption configures a maintenance command.
+type MaintenanceOption = core.MaintenanceOption
+
+// Full makes Vacuum rewrite the table to return its free space to the
+// operating system (PostgreSQL VACUUM FULL), locking the table while it runs.
+// MySQL and SQLite always rebuild.
+func Full() MaintenanceOption { return core.Full() }
+
+// CreateOptimizerStore creates the optimizer store tables (see WithOptimizerStore)
+// if they do not exist and loads the dismissed suggestions.
+func (d *DB) CreateOptimizerStore(ctx context.Context) error {
+	return d.db.CreateOptimizerStore(ctx)
+}
+
+// FlushOptimizerStore merges the query statistics and suggestions gathered since
+// the last flush into the optimizer store tables. Close flushes them too.
+//
+// Example:
+//
+//	go func() {
+//	    for range time.Tick(time.Minute) {
+//	        _ = db.FlushOptimizerStore(ctx)
+//	    }
+//	}()
+func (d *DB) FlushOptimizerStore(ctx context.Context) error {
+	return d.db.FlushOptimizerStore(ctx)
+}
+
+// QueryProfiles returns the persisted query history, highest total time first.
+// limit <= 0 returns all queries.
+func (d *DB) QueryProfiles(ctx context.Context, limit int) ([]QueryProfile, error) {
+	return d.db.QueryProfiles(ctx, limit)
+}
+
+// OptimizerSuggestions returns the persisted suggestions with the given status
+// ("" for all), ranked by the total time of the query they are about.
+//
+// Example:
+//
+//	open, err := db.OptimizerSuggestions(ctx, relica.SuggestionOpen)
+//	for _, s := range open {
+//	    fmt.Printf("%s (%v): %s\n  %s\n", s.ID, s.Impact, s.Message, s.Fix)
+//	}
+func (d *DB) OptimizerSuggestions(ctx context.Context, status SuggestionStatus) ([]StoredSuggestion, error) {
+	return d.db.OptimizerSuggestions(ctx, status)
+}
+
+// DismissSuggestion stops reporting a stored suggestion, also after restarts.
+func (d *DB) DismissSuggestion(ctx context.Context, id string) error {
+	return d.db.DismissSuggestion(ctx, id)
+}
+
+// AcceptSuggestion marks a stored suggestion as applied.
+func (d *DB) AcceptSuggestion(ctx context.Context, id string) error {
+	return d.db.AcceptSuggestion(ctx, id)
+}
+
+// CreateAuditTable creates the audit log table (see WithAuditTable) and its
+// index on occurred_at if they do not exist.
+func (d *DB) CreateAuditTable(ctx context.Context) error {
+	return d.db.CreateAuditTable(ctx)
+}
+
+// FlushAuditLog writes the buffered audit events now. The background writer
+// flushes them periodically, and Close flushes what is left.
+func (d *DB) FlushAuditLog(ctx context.Context) error {
+	return d.db.FlushAuditLog(ctx)
+}
+
+// PruneAuditLog deletes the audit records that occurred before cutoff and
+// returns their number. AuditTableRetention does this periodically.
+//
+// Example:
+//
+//	n, err := db.PruneAuditLog(ctx, time.Now().AddDate(0, 0, -90))
+func (d *DB) PruneAuditLog(ctx context.Context, cutoff time.Time) (int64, error) {
+	return d.db.PruneAuditLog(ctx, cutoff)
+}
+
+// Upsert creates a new UPSERT query (INSERT ... ON CONFLICT).
+//
+// This is a convenience method equivalent to db.Builder().Upsert(table, values).
+//
+// Example:
+//
+//	result, err := db.Upsert("users", map[string]interface{}{
+//	    "email": "alice@example.com",
+//	    "name":  "Alice",
+//	}).OnConflict("email").DoUpdate("name").Execute()
+func (d *DB) Upsert(table string, values map[string]interface{}) *UpsertQuery {
+	return d.Builder().Upsert(table, values)
+}
+
+// Merge creates a MERGE query into the target table ("table" or "table alias").
+//
+// This is a convenience method equivalent to db.Builder().Merge(target).
+//
+// Example:
+//
+//	staged := db.Select("id", "name").From("staging_users")
+//	_, err := db.Merge("users u").
+//	    Using(staged, "s", "u.id = s.id").Keys("id").
+//	    WhenMatchedUpdate(map[string]interface{}{"name": relica.NewExp("s.name")}).
+//	    WhenNotMatchedInsert(map[string]interface{}{"id": relica.NewExp("s.id"), "name": relica.NewExp("s.name")}).
+//	    Execute()
+func (d *DB) Merge(target string) *MergeQuery {
+	return d.Builder().Merge(target)
+}
+
+// Begin starts a transaction with default options.
+//
+// The transaction must be committed or rolled back to release resources.
+// It's safe to call Rollback() even after Commit().
+//
+// Example:
+//
+//	tx, err := db.Begin(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer tx.Rollback() // Safe even after Commit
+//
+//	// Use transaction
+//	_, err = tx.Insert("users", data).Execute()
+//	if err != nil {
+//	    return err
+//	}
+//
+//	return tx.Commit()
+func (d *DB) Begin(ctx context.Context) (*Tx, error) {
+	coreTx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: coreTx}, nil
+}
+
+// BeginTx starts a transaction with specified options.
+//
+// Options can specify isolation level and read-only mode:
+//   - Isolation: sql.LevelReadUncommitted, sql.LevelReadCommitted,
+//     sql.LevelRepeatableRead, sql.LevelSerializable
+//   - ReadOnly: true for read-only transactions (some databases optimize these)
+//
+// Example:
+//
+//	opts := &relica.TxOptions{
+//	    Isolation: sql.LevelSerializable,
+//	    ReadOnly:  false,
+//	}
+//	tx, err := db.BeginTx(ctx, opts)
+func (d *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	coreTx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: coreTx}, nil
+}
+
+// Transactional executes f within a transaction with automatic commit/rollback.
+//
+// If f returns an error, the transaction is rolled back and the error is returned.
+// If f panics, the transaction is rolled back and the panic is re-raised.
 // If f completes successfully, the transaction is committed.
 //
 // This helper simplifies transaction management and ensures proper cleanup
@@ -848,6 +1685,71 @@ func (d *DB) TransactionalTx(ctx context.Context, opts *TxOptions, f func(*Tx) e
 	})
 }
 
+// TxCoordinator runs transactions spanning several databases (see Coordinator).
+type TxCoordinator struct {
+	c *core.TxCoordinator
+}
+
+// CoordinatedTx holds the transactions of a coordinated transaction, one per
+// database in the order given to Coordinator.
+type CoordinatedTx struct {
+	ct *core.CoordinatedTx
+}
+
+// Coordinator returns a coordinator for transactions spanning dbs, for
+// applications writing to several databases that must not commit on some
+// and not others.
+//
+// Transactional commits on a best-effort basis: PostgreSQL participants are
+// prepared first (PREPARE TRANSACTION) if the server has prepared
+// transactions enabled (max_prepared_transactions > 0); the other
+// participants are then committed in order, and the prepared ones last
+// (COMMIT PREPARED). If a commit fails, the participants not yet committed
+// are rolled back and the compensation hooks of those already committed are
+// run (see CoordinatedTx.OnCompensate). When a committed participant cannot
+// be compensated, the error wraps ErrPartialCommit. MySQL XA is not used, as
+// database/sql transactions cannot join an XA transaction.
+//
+// Example:
+//
+//	err := relica.Coordinator(ordersDB, billingDB).Transactional(ctx, func(ct *relica.CoordinatedTx) error {
+//	    if err := ct.Tx(0).Model(&order).Insert(); err != nil {
+//	        return err
+//	    }
+//	    ct.OnCompensate(0, func(ctx context.Context) error {
+//	        return ordersDB.Model(&order).Delete()
+//	    })
+//	    return ct.Tx(1).Model(&invoice).Insert()
+//	})
+func Coordinator(dbs ...*DB) *TxCoordinator {
+	coreDBs := make([]*core.DB, len(dbs))
+	for i, d := range dbs {
+		coreDBs[i] = d.db
+	}
+	return &TxCoordinator{c: core.NewTxCoordinator(coreDBs...)}
+}
+
+// Transactional begins a transaction on every database, runs f and commits
+// them all (see Coordinator). If f returns an error or panics, all
+// transactions are rolled back.
+func (c *TxCoordinator) Transactional(ctx context.Context, f func(*CoordinatedTx) error) error {
+	return c.c.Transactional(ctx, func(ct *core.CoordinatedTx) error {
+		return f(&CoordinatedTx{ct: ct})
+	})
+}
+
+// Tx returns the transaction on the i-th database given to Coordinator.
+func (ct *CoordinatedTx) Tx(i int) *Tx {
+	return &Tx{tx: ct.ct.Tx(i)}
+}
+
+// OnCompensate registers hook to undo the changes made on the i-th database
+// if it commits but a later participant fails to. Hooks run outside any
+// transaction, most recently registered first.
+func (ct *CoordinatedTx) OnCompensate(i int, hook CompensateFunc) {
+	ct.ct.OnCompensate(i, hook)
+}
+
 // ExecContext executes a raw SQL query (INSERT/UPDATE/DELETE).
 //
 // This bypasses the query builder and executes SQL directly.
@@ -1101,6 +2003,7 @@ func (t *Tx) Delete(table string) *DeleteQuery {
 }
 
 // Commit commits the transaction.
+// For a nested transaction begun on a Tx.DB handle, it releases the savepoint.
 //
 // After calling Commit, the transaction cannot be used for further queries.
 //
@@ -1114,6 +2017,7 @@ func (t *Tx) Commit() error {
 }
 
 // Rollback rolls back the transaction.
+// For a nested transaction begun on a Tx.DB handle, it rolls back to the savepoint.
 //
 // After calling Rollback, the transaction cannot be used for further queries.
 // It's safe to call Rollback even after Commit (it will be a no-op).
@@ -1125,6 +2029,31 @@ func (t *Tx) Rollback() error {
 	return t.tx.Rollback()
 }
 
+// DeferConstraints defers constraint checks to the end of the transaction:
+// SET CONSTRAINTS ALL DEFERRED on PostgreSQL (DEFERRABLE constraints) and
+// PRAGMA defer_foreign_keys on SQLite. MySQL has no deferred constraints.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    if err := tx.DeferConstraints(); err != nil {
+//	        return err
+//	    }
+//	    // insert children before their parents ...
+//	    return nil
+//	})
+func (t *Tx) DeferConstraints() error {
+	return t.tx.DeferConstraints()
+}
+
+// DisableForeignKeys turns off foreign key checks for the rest of the
+// transaction on MySQL, restoring them before Commit or Rollback. SQLite can
+// only change enforcement outside transactions, and PostgreSQL uses
+// DeferConstraints instead.
+func (t *Tx) DisableForeignKeys() error {
+	return t.tx.DisableForeignKeys()
+}
+
 // Unwrap returns the underlying core.Tx for advanced use cases.
 //
 // This method is provided for edge cases where direct access to
@@ -1133,6 +2062,25 @@ func (t *Tx) Unwrap() *core.Tx {
 	return t.tx
 }
 
+// DB returns a DB handle whose queries all run inside this transaction.
+//
+// Begin and Transactional on the handle create savepoints instead of new
+// transactions, so code written against *DB can run inside a transaction that
+// is rolled back afterwards. Close on the handle is a no-op. A transaction uses
+// a single connection: do not use the handle concurrently.
+//
+// Example:
+//
+//	tx, _ := db.Begin(ctx)
+//	defer tx.Rollback()
+//
+//	svc := NewUserService(tx.DB()) // all writes are discarded on rollback
+//
+// See relicatest.NewRollbackDB for the test helper built on this.
+func (t *Tx) DB() *DB {
+	return &DB{db: t.tx.DB()}
+}
+
 // Model creates a ModelQuery within transaction context.
 //
 // All operations performed through this ModelQuery will execute
@@ -1168,6 +2116,30 @@ func (t *Tx) BatchInsert(table string, columns []string) *BatchInsertQuery {
 	return t.Builder().BatchInsert(table, columns)
 }
 
+// InsertAll creates a query inserting a slice of structs within the transaction.
+//
+// This is a convenience method equivalent to tx.Builder().InsertAll(table, rows).
+func (t *Tx) InsertAll(table string, rows interface{}) *InsertAllQuery {
+	return t.Builder().InsertAll(table, rows)
+}
+
+// BatchDelete creates a query deleting rows by key in chunks within the
+// transaction. The chunks are committed together with the transaction.
+//
+// This is a convenience method equivalent to tx.Builder().BatchDelete(table, keyColumn).
+func (t *Tx) BatchDelete(table, keyColumn string) *BatchDeleteQuery {
+	return t.Builder().BatchDelete(table, keyColumn)
+}
+
+// Truncate creates a query removing every row of table within the transaction.
+// PostgreSQL and SQLite roll it back with the transaction; MySQL commits
+// implicitly on TRUNCATE.
+//
+// This is a convenience method equivalent to tx.Builder().Truncate(table).
+func (t *Tx) Truncate(table string) *TruncateQuery {
+	return t.Builder().Truncate(table)
+}
+
 // BatchUpdate creates a new batch UPDATE query within the transaction.
 //
 // This is a convenience method equivalent to tx.Builder().BatchUpdate(table, keyColumn).
@@ -1196,6 +2168,13 @@ func (t *Tx) Upsert(table string, values map[string]interface{}) *UpsertQuery {
 	return t.Builder().Upsert(table, values)
 }
 
+// Merge creates a new MERGE query within the transaction.
+//
+// This is a convenience method equivalent to tx.Builder().Merge(target).
+func (t *Tx) Merge(target string) *MergeQuery {
+	return t.Builder().Merge(target)
+}
+
 // NewQuery creates a raw SQL query within the transaction.
 //
 // This is a convenience method equivalent to tx.Builder()-based raw query execution.
@@ -1204,8 +2183,24 @@ func (t *Tx) Upsert(table string, values map[string]interface{}) *UpsertQuery {
 //
 //	var count int
 //	err := tx.NewQuery("SELECT COUNT(*) FROM users").Row(&count)
-func (t *Tx) NewQuery(query string) *Query {
-	return &Query{q: t.tx.NewQuery(query)}
+func (t *Tx) NewQuery(query string, params ...interface{}) *Query {
+	return &Query{q: t.tx.NewQuery(query, params...)}
+}
+
+// Outbox returns the transactional outbox of the transaction. Published
+// messages are committed or rolled back with the rest of the transaction and
+// delivered by an OutboxRelay.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    if err := tx.Model(&order).Insert(); err != nil {
+//	        return err
+//	    }
+//	    return tx.Outbox().Publish("orders.created", order)
+//	})
+func (t *Tx) Outbox() *Outbox {
+	return t.tx.Outbox()
 }
 
 // ============================================================================
@@ -1292,7 +2287,7 @@ func (mq *ModelQuery) Upsert(fields ...string) error {
 //
 // It compares each field of the current model against original.
 // Only changed fields are included in the UPDATE SET clause.
-// Primary key fields are never included in the SET clause.
+// Primary key fields and fields excluded with Exclude are never included.
 //
 // If nothing has changed, no query is executed and nil is returned.
 // The original parameter must be the same type as the model struct.
@@ -1312,6 +2307,79 @@ func (mq *ModelQuery) UpdateChanged(original interface{}) error {
 	return mq.mq.UpdateChanged(original)
 }
 
+// FindByIDs loads the rows whose primary key is in ids into dest (a pointer to a
+// slice), in the order of ids. Large ID lists are split into chunks of
+// DefaultInChunkSize. Requires a single-column primary key.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Model(&User{}).FindByIDs([]int64{3, 1, 2}, &users)
+func (mq *ModelQuery) FindByIDs(ids interface{}, dest interface{}) error {
+	return mq.mq.FindByIDs(ids, dest)
+}
+
+// Find loads the rows matching the model's non-zero fields (query-by-example)
+// into dest, which is a pointer to a slice, or a pointer to a struct for the
+// first match. Fields are compared with "=" unless Match sets another operator.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Model(&User{Status: "active"}).Find(&users)
+func (mq *ModelQuery) Find(dest interface{}) error {
+	return mq.mq.Find(dest)
+}
+
+// One reloads the model from the row with its primary key, overwriting its
+// fields. Returns ErrNotFound if the row does not exist.
+//
+// Combined with LockForUpdate inside a transaction, it makes
+// read-modify-write sequences safe.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    order := Order{ID: id}
+//	    if err := tx.Model(&order).LockForUpdate().One(); err != nil {
+//	        return err
+//	    }
+//	    order.Status = "paid"
+//	    return tx.Model(&order).Update("status")
+//	})
+func (mq *ModelQuery) One() error {
+	return mq.mq.One()
+}
+
+// LockForUpdate makes One lock the row with SELECT ... FOR UPDATE until the
+// transaction ends. Requires a transaction (tx.Model, or db.Model on a
+// Tx.DB). On SQLite, which has no row locks, the clause is omitted.
+func (mq *ModelQuery) LockForUpdate() *ModelQuery {
+	return &ModelQuery{mq: mq.mq.LockForUpdate()}
+}
+
+// LockForShare makes One take a shared row lock (FOR SHARE on PostgreSQL,
+// LOCK IN SHARE MODE on MySQL) until the transaction ends: other
+// transactions can read the row but not update or delete it.
+// Requires a transaction.
+func (mq *ModelQuery) LockForShare() *ModelQuery {
+	return &ModelQuery{mq: mq.mq.LockForShare()}
+}
+
+// Match sets the comparison operator Find uses for column (default "=").
+// Supported operators: =, <>, !=, >, >=, <, <=, LIKE, NOT LIKE.
+// A column with an explicit operator is compared even if its field is zero.
+//
+// Example:
+//
+//	err := db.Model(&User{Name: "A%", Age: 18}).
+//	    Match("name", "LIKE").
+//	    Match("age", ">=").
+//	    Find(&users)
+func (mq *ModelQuery) Match(column, op string) *ModelQuery {
+	return &ModelQuery{mq: mq.mq.Match(column, op)}
+}
+
 // Exclude excludes the specified fields from the operation.
 //
 // This is useful for auto-managed fields like timestamps.
@@ -1517,10 +2585,60 @@ func (qb *QueryBuilder) Delete(table string) *DeleteQuery {
 	return &DeleteQuery{dq: qb.qb.Delete(table)}
 }
 
-// BatchInsert creates a batch INSERT query for multiple rows.
-//
-// This is 3.3x faster than individual INSERTs for 100 rows.
-// Use Values() or ValuesMap() to add rows.
+// InsertFromSelect creates an INSERT ... SELECT query copying the rows returned
+// by query into table. If columns is empty, the column list is omitted.
+//
+// Example:
+//
+//	db.Builder().InsertFromSelect("users_archive", []string{"id", "name"},
+//	    db.Builder().Select("id", "name").From("users").Where("deleted = ?", true),
+//	).Execute()
+func (qb *QueryBuilder) InsertFromSelect(table string, columns []string, query *SelectQuery) *InsertSelectQuery {
+	var inner *core.SelectQuery
+	if query != nil {
+		inner = query.sq
+	}
+	return &InsertSelectQuery{isq: qb.qb.InsertFromSelect(table, columns, inner)}
+}
+
+// With returns a new QueryBuilder that prepends the CTE to every statement it
+// builds (SELECT, UPDATE, DELETE and InsertFromSelect). The original builder is unchanged.
+//
+// The CTE body may be a SELECT or, on PostgreSQL, a data-modifying
+// UPDATE/DELETE/InsertFromSelect query (usually with Returning()).
+//
+// Example (move rows in a single statement):
+//
+//	moved := db.Builder().Delete("orders").Where("created_at < ?", cutoff).Returning("*")
+//	db.Builder().
+//	    With("moved", moved).
+//	    InsertFromSelect("orders_archive", nil, db.Builder().Select("*").From("moved")).
+//	    Execute()
+func (qb *QueryBuilder) With(name string, query CTEQuery, opts ...CTEOption) *QueryBuilder {
+	return &QueryBuilder{qb: qb.qb.With(name, unwrapCTEQuery(query), opts...)}
+}
+
+// OnPool returns a builder whose queries execute on the named connection pool
+// (see DB.Pool). The receiver is unchanged. Ignored for transactional builders.
+//
+// Example:
+//
+//	reporting := db.Builder().OnPool("reporting")
+//	reporting.Select("*").From("daily_sales").All(&sales)
+func (qb *QueryBuilder) OnPool(name string) *QueryBuilder {
+	return &QueryBuilder{qb: qb.qb.OnPool(name)}
+}
+
+// Tag returns a builder whose queries are named for logs, hooks, audit records
+// and DB.QueryStats. The receiver is unchanged.
+func (qb *QueryBuilder) Tag(name string) *QueryBuilder {
+	return &QueryBuilder{qb: qb.qb.Tag(name)}
+}
+
+// BatchInsert creates a batch INSERT query for multiple rows.
+//
+// This is 3.3x faster than individual INSERTs for 100 rows.
+// Use Values() or ValuesMap() to add rows.
 //
 // Example:
 //
@@ -1532,6 +2650,55 @@ func (qb *QueryBuilder) BatchInsert(table string, columns []string) *BatchInsert
 	return &BatchInsertQuery{biq: qb.qb.BatchInsert(table, columns)}
 }
 
+// InsertAll creates a query inserting every element of rows, a slice of
+// structs or struct pointers, into table.
+//
+// The struct type is inspected once. Rows are split into INSERT statements
+// of at most 999 bind parameters (see BatchSize), which run in one
+// transaction. Like Model().Insert, generated IDs are filled, models are
+// validated, and a zero auto-increment primary key is populated on every
+// element: with RETURNING on PostgreSQL, from LastInsertId on MySQL and SQLite.
+// If table is empty, it is inferred from the element type like Model does.
+//
+// Example:
+//
+//	users := []User{{Name: "Alice"}, {Name: "Bob"}}
+//	n, err := db.Builder().InsertAll("users", users).Execute()
+func (qb *QueryBuilder) InsertAll(table string, rows interface{}) *InsertAllQuery {
+	return &InsertAllQuery{iq: qb.qb.InsertAll(table, rows)}
+}
+
+// BatchDelete creates a query deleting the rows of table whose keyColumn is
+// one of the keys given to Keys, with one DELETE ... WHERE keyColumn IN (...)
+// statement per chunk of keys. Outside a transaction every chunk is committed
+// on its own, so a large cleanup never locks the table for minutes; Sleep
+// paces the chunks.
+//
+// Example:
+//
+//	n, err := db.Builder().BatchDelete("events", "id").
+//	    Keys(ids...).
+//	    ChunkSize(5000).
+//	    Sleep(100 * time.Millisecond).
+//	    Execute()
+func (qb *QueryBuilder) BatchDelete(table, keyColumn string) *BatchDeleteQuery {
+	return &BatchDeleteQuery{bq: qb.qb.BatchDelete(table, keyColumn)}
+}
+
+// Truncate creates a query removing every row of table.
+//
+// PostgreSQL and MySQL run TRUNCATE TABLE; SQLite, which has no TRUNCATE,
+// runs DELETE FROM. The statement passes the query validator, hooks and
+// change feed like other builders, and is written to the audit log as a
+// TRUNCATE operation.
+//
+// Example:
+//
+//	err := db.Builder().Truncate("events").Cascade().RestartIdentity().Execute()
+func (qb *QueryBuilder) Truncate(table string) *TruncateQuery {
+	return &TruncateQuery{tq: qb.qb.Truncate(table)}
+}
+
 // BatchUpdate creates a batch UPDATE query for multiple rows.
 //
 // This is 2.5x faster than individual UPDATEs for 100 rows.
@@ -1564,6 +2731,21 @@ func (qb *QueryBuilder) Upsert(table string, values map[string]interface{}) *Ups
 	return &UpsertQuery{uq: qb.qb.Upsert(table, values)}
 }
 
+// Merge creates a MERGE query synchronizing the target table with a source.
+// PostgreSQL 15+ runs a MERGE statement; MySQL and SQLite run an upsert-based
+// emulation that matches rows by the Keys columns.
+//
+// Example:
+//
+//	db.Builder().Merge("users u").
+//	    Using(staged, "s", "u.id = s.id").Keys("id").
+//	    WhenMatchedUpdate(map[string]interface{}{"name": relica.NewExp("s.name")}).
+//	    WhenNotMatchedInsert(map[string]interface{}{"id": relica.NewExp("s.id"), "name": relica.NewExp("s.name")}).
+//	    Execute()
+func (qb *QueryBuilder) Merge(target string) *MergeQuery {
+	return &MergeQuery{mq: qb.qb.Merge(target)}
+}
+
 // Unwrap returns the underlying core.QueryBuilder for advanced use cases.
 //
 // This method is provided for edge cases where direct access to
@@ -1587,6 +2769,57 @@ func (sq *SelectQuery) WithContext(ctx context.Context) *SelectQuery {
 	return &SelectQuery{sq: sq.sq.WithContext(ctx)}
 }
 
+// Clone returns an independent copy of the query: chained calls on the copy
+// do not affect the original and vice versa.
+//
+// Example:
+//
+//	base := db.Select().From("orders").Where("tenant_id = ?", tenant)
+//	open := base.Clone().Where("status = ?", "open")
+//	late := base.Clone().Where("due_at < ?", now)
+func (sq *SelectQuery) Clone() *SelectQuery {
+	return &SelectQuery{sq: sq.sq.Clone()}
+}
+
+// Immutable returns a copy of the query on which every chained call returns
+// a modified copy instead of changing the query itself. Use it for base
+// queries shared between requests or goroutines.
+//
+// Example:
+//
+//	var activeUsers = db.Select("id", "name").From("users").Where("active = ?", true).Immutable()
+//
+//	func byTeam(team int) *relica.SelectQuery {
+//	    return activeUsers.Where("team_id = ?", team) // activeUsers is unchanged
+//	}
+func (sq *SelectQuery) Immutable() *SelectQuery {
+	return &SelectQuery{sq: sq.sq.Immutable()}
+}
+
+// ReadOnly makes the query fail with ErrReadOnly, before any SQL is sent,
+// if it may change data: a WITH clause with a data-modifying statement, a
+// row lock, or SELECT ... INTO in a raw expression.
+//
+// Example:
+//
+//	err := db.Select("*").With("rows", pluginCTE).From("rows").ReadOnly().All(&rows)
+func (sq *SelectQuery) ReadOnly() *SelectQuery {
+	return &SelectQuery{sq: sq.sq.ReadOnly()}
+}
+
+// BindLimits sends LIMIT and OFFSET (and UnionLimit/UnionOffset) as
+// parameters instead of literals, so one prepared statement serves every page
+// size and page instead of one cached statement per page.
+//
+// Example:
+//
+//	err := db.Select("*").From("events").OrderBy("id").
+//	    Limit(pageSize).Offset(page * pageSize).BindLimits().All(&events)
+//	// SELECT * FROM "events" ORDER BY "id" LIMIT $1 OFFSET $2
+func (sq *SelectQuery) BindLimits() *SelectQuery {
+	return &SelectQuery{sq: sq.sq.BindLimits()}
+}
+
 // From specifies the table to select from.
 //
 // Supports table aliases: From("users u")
@@ -1595,8 +2828,7 @@ func (sq *SelectQuery) WithContext(ctx context.Context) *SelectQuery {
 //
 //	db.Builder().Select("*").From("users").All(&users)
 func (sq *SelectQuery) From(table string) *SelectQuery {
-	sq.sq.From(table)
-	return sq
+	return &SelectQuery{sq: sq.sq.From(table)}
 }
 
 // FromSelect specifies a subquery as the FROM source.
@@ -1610,8 +2842,7 @@ func (sq *SelectQuery) From(table string) *SelectQuery {
 //	db.Builder().Select("*").FromSelect(sub, "order_counts").
 //	    Where("cnt > ?", 10).All(&results)
 func (sq *SelectQuery) FromSelect(subquery *SelectQuery, alias string) *SelectQuery {
-	sq.sq.FromSelect(subquery.sq, alias)
-	return sq
+	return &SelectQuery{sq: sq.sq.FromSelect(subquery.sq, alias)}
 }
 
 // SelectExpr adds a raw SQL expression to the SELECT clause.
@@ -1624,8 +2855,7 @@ func (sq *SelectQuery) FromSelect(subquery *SelectQuery, alias string) *SelectQu
 //	    SelectExpr("(SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id)", "order_count").
 //	    From("users").All(&results)
 func (sq *SelectQuery) SelectExpr(expr string, args ...interface{}) *SelectQuery {
-	sq.sq.SelectExpr(expr, args...)
-	return sq
+	return &SelectQuery{sq: sq.sq.SelectExpr(expr, args...)}
 }
 
 // AndSelect appends additional columns to the SELECT clause.
@@ -1641,8 +2871,7 @@ func (sq *SelectQuery) SelectExpr(expr string, args ...interface{}) *SelectQuery
 //	    q = q.AndSelect("phone")
 //	}
 func (sq *SelectQuery) AndSelect(cols ...string) *SelectQuery {
-	sq.sq.AndSelect(cols...)
-	return sq
+	return &SelectQuery{sq: sq.sq.AndSelect(cols...)}
 }
 
 // SelectSub adds a type-safe subquery or computed expression to the SELECT clause with a quoted alias.
@@ -1658,8 +2887,27 @@ func (sq *SelectQuery) AndSelect(cols ...string) *SelectQuery {
 //
 //	SELECT "id", "name", (SELECT COUNT(*) FROM "orders" WHERE "orders"."user_id" = "users"."id") AS "order_count" FROM "users"
 func (sq *SelectQuery) SelectSub(exp Expression, alias string) *SelectQuery {
-	sq.sq.SelectSub(exp, alias)
-	return sq
+	return &SelectQuery{sq: sq.sq.SelectSub(exp, alias)}
+}
+
+// SelectExp adds type-safe expressions to the SELECT clause as-is.
+// Unlike SelectSub, no parentheses or alias are added, so expressions that carry
+// their own alias (CASE, COALESCE, etc.) can be selected directly.
+//
+// Example:
+//
+//	db.Select("id").
+//	    SelectExp(relica.Case().
+//	        When(relica.Eq("status", 1), "active").
+//	        Else("unknown").
+//	        As("status_label")).
+//	    From("users")
+//
+// Generates (PostgreSQL):
+//
+//	SELECT "id", CASE WHEN "status" = $1 THEN $2 ELSE $3 END AS "status_label" FROM "users"
+func (sq *SelectQuery) SelectExp(exps ...Expression) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.SelectExp(exps...)}
 }
 
 // Where adds a WHERE condition.
@@ -1678,8 +2926,7 @@ func (sq *SelectQuery) SelectSub(exp Expression, alias string) *SelectQuery {
 //	    relica.GreaterThan("age", 18),
 //	))
 func (sq *SelectQuery) Where(condition interface{}, params ...interface{}) *SelectQuery {
-	sq.sq.Where(condition, params...)
-	return sq
+	return &SelectQuery{sq: sq.sq.Where(condition, params...)}
 }
 
 // AndWhere adds a WHERE condition with AND logic.
@@ -1691,8 +2938,7 @@ func (sq *SelectQuery) Where(condition interface{}, params ...interface{}) *Sele
 //	    Where("status = ?", 1).
 //	    AndWhere("age > ?", 18)
 func (sq *SelectQuery) AndWhere(condition interface{}, params ...interface{}) *SelectQuery {
-	sq.sq.AndWhere(condition, params...)
-	return sq
+	return &SelectQuery{sq: sq.sq.AndWhere(condition, params...)}
 }
 
 // OrWhere adds a WHERE condition with OR logic.
@@ -1704,8 +2950,19 @@ func (sq *SelectQuery) AndWhere(condition interface{}, params ...interface{}) *S
 //	    Where("status = ?", 1).
 //	    OrWhere("role = ?", "admin")
 func (sq *SelectQuery) OrWhere(condition interface{}, params ...interface{}) *SelectQuery {
-	sq.sq.OrWhere(condition, params...)
-	return sq
+	return &SelectQuery{sq: sq.sq.OrWhere(condition, params...)}
+}
+
+// WhereCond adds the condition of a ConditionBuilder to the WHERE clause,
+// combined with AND like Where. A nil or empty builder adds nothing.
+//
+// Example:
+//
+//	filter := relica.NewCondition(relica.Eq("status", "active"))
+//	db.Select().From("users").WhereCond(filter).Limit(20).All(&page)
+//	total, err := db.Select().From("users").WhereCond(filter).Count()
+func (sq *SelectQuery) WhereCond(cb *ConditionBuilder) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.WhereCond(cb)}
 }
 
 // InnerJoin adds an INNER JOIN clause.
@@ -1717,8 +2974,7 @@ func (sq *SelectQuery) OrWhere(condition interface{}, params ...interface{}) *Se
 //	    InnerJoin("orders o", "o.user_id = u.id").
 //	    All(&results)
 func (sq *SelectQuery) InnerJoin(table string, on interface{}) *SelectQuery {
-	sq.sq.InnerJoin(table, on)
-	return sq
+	return &SelectQuery{sq: sq.sq.InnerJoin(table, on)}
 }
 
 // LeftJoin adds a LEFT JOIN clause.
@@ -1730,8 +2986,7 @@ func (sq *SelectQuery) InnerJoin(table string, on interface{}) *SelectQuery {
 //	    LeftJoin("orders o", "o.user_id = u.id").
 //	    All(&results)
 func (sq *SelectQuery) LeftJoin(table string, on interface{}) *SelectQuery {
-	sq.sq.LeftJoin(table, on)
-	return sq
+	return &SelectQuery{sq: sq.sq.LeftJoin(table, on)}
 }
 
 // RightJoin adds a RIGHT JOIN clause.
@@ -1743,8 +2998,7 @@ func (sq *SelectQuery) LeftJoin(table string, on interface{}) *SelectQuery {
 //	    RightJoin("orders o", "o.user_id = u.id").
 //	    All(&results)
 func (sq *SelectQuery) RightJoin(table string, on interface{}) *SelectQuery {
-	sq.sq.RightJoin(table, on)
-	return sq
+	return &SelectQuery{sq: sq.sq.RightJoin(table, on)}
 }
 
 // FullJoin adds a FULL OUTER JOIN clause.
@@ -1758,8 +3012,7 @@ func (sq *SelectQuery) RightJoin(table string, on interface{}) *SelectQuery {
 //	    FullJoin("orders o", "o.user_id = u.id").
 //	    All(&results)
 func (sq *SelectQuery) FullJoin(table string, on interface{}) *SelectQuery {
-	sq.sq.FullJoin(table, on)
-	return sq
+	return &SelectQuery{sq: sq.sq.FullJoin(table, on)}
 }
 
 // CrossJoin adds a CROSS JOIN clause (Cartesian product).
@@ -1771,20 +3024,73 @@ func (sq *SelectQuery) FullJoin(table string, on interface{}) *SelectQuery {
 //	    CrossJoin("sizes").
 //	    All(&results)
 func (sq *SelectQuery) CrossJoin(table string) *SelectQuery {
-	sq.sq.CrossJoin(table)
-	return sq
+	return &SelectQuery{sq: sq.sq.CrossJoin(table)}
+}
+
+// JoinSelect adds a JOIN of a subquery (derived table) with the given alias.
+// The subquery's parameters are bound in clause order: after the FROM source
+// and earlier joins, before WHERE.
+//
+// Example:
+//
+//	totals := db.Select("user_id", "SUM(total) AS spent").From("orders").
+//	    Where("status = ?", "paid").GroupBy("user_id")
+//	db.Select("u.name", "t.spent").From("users u").
+//	    JoinSelect("INNER JOIN", totals, "t", "t.user_id = u.id").
+//	    All(&results)
+func (sq *SelectQuery) JoinSelect(joinType string, subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	var sub *core.SelectQuery
+	if subquery != nil {
+		sub = subquery.sq
+	}
+	return &SelectQuery{sq: sq.sq.JoinSelect(joinType, sub, alias, on)}
+}
+
+// InnerJoinSelect adds an INNER JOIN of a subquery with the given alias.
+//
+// Example:
+//
+//	db.Select("u.name", "t.spent").From("users u").
+//	    InnerJoinSelect(totals, "t", "t.user_id = u.id").
+//	    All(&results)
+func (sq *SelectQuery) InnerJoinSelect(subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	return sq.JoinSelect("INNER JOIN", subquery, alias, on)
+}
+
+// LeftJoinSelect adds a LEFT JOIN of a subquery with the given alias.
+//
+// Example:
+//
+//	db.Select("u.name", "l.last_at").From("users u").
+//	    LeftJoinSelect(lastLogins, "l", relica.EqCol("l.user_id", "u.id")).
+//	    All(&results)
+func (sq *SelectQuery) LeftJoinSelect(subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	return sq.JoinSelect("LEFT JOIN", subquery, alias, on)
+}
+
+// RightJoinSelect adds a RIGHT JOIN of a subquery with the given alias.
+//
+// Example:
+//
+//	db.Select("u.name", "t.spent").From("users u").
+//	    RightJoinSelect(totals, "t", "t.user_id = u.id").
+//	    All(&results)
+func (sq *SelectQuery) RightJoinSelect(subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	return sq.JoinSelect("RIGHT JOIN", subquery, alias, on)
 }
 
 // OrderBy adds ORDER BY clause with optional direction (ASC/DESC).
 //
 // Supports multiple columns. Multiple OrderBy() calls are additive.
+// Plain columns are quoted; expressions such as "LOWER(name) DESC" are
+// emitted as-is. Use OrderBySafe for user input.
 //
 // Example:
 //
 //	OrderBy("age DESC", "name ASC")
+//	OrderBy("LOWER(name) DESC", "created_at NULLS LAST")
 func (sq *SelectQuery) OrderBy(columns ...string) *SelectQuery {
-	sq.sq.OrderBy(columns...)
-	return sq
+	return &SelectQuery{sq: sq.sq.OrderBy(columns...)}
 }
 
 // OrderByExpr adds a raw SQL expression to the ORDER BY clause.
@@ -1796,8 +3102,7 @@ func (sq *SelectQuery) OrderBy(columns ...string) *SelectQuery {
 //	OrderByExpr("CASE WHEN status = ? THEN 0 ELSE 1 END", "active")
 //	OrderByExpr("FIELD(id, ?, ?, ?)", 3, 1, 2)
 func (sq *SelectQuery) OrderByExpr(expr string, args ...interface{}) *SelectQuery {
-	sq.sq.OrderByExpr(expr, args...)
-	return sq
+	return &SelectQuery{sq: sq.sq.OrderByExpr(expr, args...)}
 }
 
 // OrderBySub adds a type-safe expression to the ORDER BY clause.
@@ -1813,8 +3118,28 @@ func (sq *SelectQuery) OrderByExpr(expr string, args ...interface{}) *SelectQuer
 //	    OrderBy("t.due_date ASC").
 //	    All(&rows)
 func (sq *SelectQuery) OrderBySub(exp Expression) *SelectQuery {
-	sq.sq.OrderBySub(exp)
-	return sq
+	return &SelectQuery{sq: sq.sq.OrderBySub(exp)}
+}
+
+// OrderByRandom adds a random term to the ORDER BY clause: RANDOM() on
+// PostgreSQL and SQLite, RAND() on MySQL.
+//
+// Example:
+//
+//	db.Select().From("users").OrderByRandom().Limit(3).All(&winners)
+func (sq *SelectQuery) OrderByRandom() *SelectQuery {
+	return &SelectQuery{sq: sq.sq.OrderByRandom()}
+}
+
+// Sample reads about percent (0 < percent <= 100) percent of the rows of the
+// FROM table: TABLESAMPLE BERNOULLI on PostgreSQL, a random WHERE predicate
+// on MySQL and SQLite. Count and Exists sample the same way.
+//
+// Example:
+//
+//	db.Select().From("events").Sample(1).All(&events)
+func (sq *SelectQuery) Sample(percent float64) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.Sample(percent)}
 }
 
 // Limit sets the LIMIT clause.
@@ -1823,8 +3148,7 @@ func (sq *SelectQuery) OrderBySub(exp Expression) *SelectQuery {
 //
 //	Limit(100)  // Return at most 100 rows
 func (sq *SelectQuery) Limit(limit int64) *SelectQuery {
-	sq.sq.Limit(limit)
-	return sq
+	return &SelectQuery{sq: sq.sq.Limit(limit)}
 }
 
 // Offset sets the OFFSET clause.
@@ -1833,20 +3157,19 @@ func (sq *SelectQuery) Limit(limit int64) *SelectQuery {
 //
 //	Offset(200)  // Skip first 200 rows
 func (sq *SelectQuery) Offset(offset int64) *SelectQuery {
-	sq.sq.Offset(offset)
-	return sq
+	return &SelectQuery{sq: sq.sq.Offset(offset)}
 }
 
 // GroupBy adds GROUP BY clause.
 //
 // Multiple columns supported. Multiple GroupBy() calls are additive.
+// Plain identifiers are quoted; expressions such as "created_at::date" are emitted as-is.
 //
 // Example:
 //
 //	GroupBy("user_id", "status")
 func (sq *SelectQuery) GroupBy(columns ...string) *SelectQuery {
-	sq.sq.GroupBy(columns...)
-	return sq
+	return &SelectQuery{sq: sq.sq.GroupBy(columns...)}
 }
 
 // GroupByExpr adds a raw SQL expression to the GROUP BY clause.
@@ -1857,14 +3180,41 @@ func (sq *SelectQuery) GroupBy(columns ...string) *SelectQuery {
 //	GroupByExpr("DATE(created_at)")
 //	GroupByExpr("EXTRACT(YEAR FROM order_date)")
 func (sq *SelectQuery) GroupByExpr(expr string, args ...interface{}) *SelectQuery {
-	sq.sq.GroupByExpr(expr, args...)
-	return sq
+	return &SelectQuery{sq: sq.sq.GroupByExpr(expr, args...)}
 }
 
 // GroupBySub adds a type-safe expression to the GROUP BY clause.
 func (sq *SelectQuery) GroupBySub(exp Expression) *SelectQuery {
-	sq.sq.GroupBySub(exp)
-	return sq
+	return &SelectQuery{sq: sq.sq.GroupBySub(exp)}
+}
+
+// GroupByRollup adds a ROLLUP grouping (subtotals per column prefix plus a grand total).
+//
+// PostgreSQL renders GROUP BY ROLLUP (...); MySQL renders GROUP BY ... WITH ROLLUP
+// and does not allow other grouping elements alongside it. SQLite is not supported.
+//
+// Example:
+//
+//	db.Select("region", "product", "SUM(amount) AS total").
+//	    From("sales").
+//	    GroupByRollup("region", "product")
+func (sq *SelectQuery) GroupByRollup(columns ...string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.GroupByRollup(columns...)}
+}
+
+// GroupByCube adds a CUBE grouping (subtotals for every column combination). PostgreSQL only.
+func (sq *SelectQuery) GroupByCube(columns ...string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.GroupByCube(columns...)}
+}
+
+// GroupingSets adds explicit GROUPING SETS; an empty set yields the grand total. PostgreSQL only.
+//
+// Example:
+//
+//	GroupingSets([]string{"region", "product"}, []string{"region"}, []string{})
+//	// GROUP BY GROUPING SETS (("region", "product"), ("region"), ())
+func (sq *SelectQuery) GroupingSets(sets ...[]string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.GroupingSets(sets...)}
 }
 
 // Having adds HAVING clause (WHERE for aggregates).
@@ -1875,8 +3225,116 @@ func (sq *SelectQuery) GroupBySub(exp Expression) *SelectQuery {
 //
 //	Having("COUNT(*) > ?", 100)
 func (sq *SelectQuery) Having(condition interface{}, args ...interface{}) *SelectQuery {
-	sq.sq.Having(condition, args...)
-	return sq
+	return &SelectQuery{sq: sq.sq.Having(condition, args...)}
+}
+
+// AndHaving adds a HAVING condition with AND logic (same as Having).
+//
+// Example:
+//
+//	Having("COUNT(*) > ?", 10).AndHaving("SUM(total) < ?", 1000)
+func (sq *SelectQuery) AndHaving(condition interface{}, args ...interface{}) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.AndHaving(condition, args...)}
+}
+
+// OrHaving adds a HAVING condition with OR logic, mirroring OrWhere.
+// Conditions added afterwards are ANDed with the whole OR group.
+//
+// Example:
+//
+//	Having("COUNT(*) > ?", 100).OrHaving(relica.GreaterThan("SUM(total)", 5000))
+//	// HAVING (COUNT(*) > ?) OR (SUM(total) > ?)
+func (sq *SelectQuery) OrHaving(condition interface{}, args ...interface{}) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.OrHaving(condition, args...)}
+}
+
+// UseIndex adds a MySQL USE INDEX hint for the FROM table (ignored on other dialects).
+//
+// Example:
+//
+//	db.Builder().Select("*").From("users").UseIndex("idx_users_email").Where("email = ?", email)
+func (sq *SelectQuery) UseIndex(indexes ...string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.UseIndex(indexes...)}
+}
+
+// ForceIndex adds a MySQL FORCE INDEX hint for the FROM table (ignored on other dialects).
+func (sq *SelectQuery) ForceIndex(indexes ...string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.ForceIndex(indexes...)}
+}
+
+// IgnoreIndex adds a MySQL IGNORE INDEX hint for the FROM table (ignored on other dialects).
+func (sq *SelectQuery) IgnoreIndex(indexes ...string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.IgnoreIndex(indexes...)}
+}
+
+// Hint adds an optimizer hint comment such as "/*+ IndexScan(users idx) */".
+//
+// PostgreSQL (pg_hint_plan) receives the comment before the statement; MySQL
+// optimizer hints go right after SELECT. Bare text is wrapped in /*+ ... */.
+//
+// Example:
+//
+//	db.Builder().Select("*").From("users").Hint("IndexScan(users idx_users_email)")
+func (sq *SelectQuery) Hint(hint string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.Hint(hint)}
+}
+
+// OnPool executes this query on the named connection pool (see DB.Pool).
+// Ignored inside transactions.
+//
+// Example:
+//
+//	db.Builder().Select("region", "SUM(total)").From("orders").
+//	    GroupBy("region").OnPool("reporting").All(&rows)
+func (sq *SelectQuery) OnPool(name string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.OnPool(name)}
+}
+
+// RequireFresh runs the query on a read replica only if its last measured
+// replication lag is at most maxLag (see WithReplicaLagCheck); otherwise, or
+// when lag is not checked, it runs on the primary. Use it for reads that must
+// see recent writes.
+//
+// Example:
+//
+//	db.Select().From("orders").Where(relica.Eq("id", id)).
+//	    RequireFresh(2 * time.Second).One(&order)
+func (sq *SelectQuery) RequireFresh(maxLag time.Duration) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.RequireFresh(maxLag)}
+}
+
+// Tag names this query for logs, hooks, audit records and DB.QueryStats.
+//
+// Example:
+//
+//	db.Builder().Select("*").From("cart_items").
+//	    Where(relica.HashExp{"cart_id": id}).
+//	    Tag("checkout.load_cart").
+//	    All(&items)
+func (sq *SelectQuery) Tag(name string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.Tag(name)}
+}
+
+// WhereInChunked adds "col IN (values...)" and, at execution, splits values into
+// chunks of chunkSize, runs one query per chunk and merges the results.
+// If chunkSize <= 0, DefaultInChunkSize is used. Only All() and Column() can
+// execute chunked queries; Limit(), Offset() and set operations are not supported.
+//
+// Example:
+//
+//	db.Builder().Select().From("users").
+//	    WhereInChunked("id", ids, 1000).
+//	    PreserveOrder().
+//	    All(&users)
+func (sq *SelectQuery) WhereInChunked(col string, values interface{}, chunkSize int) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.WhereInChunked(col, values, chunkSize)}
+}
+
+// PreserveOrder sorts the results of a WhereInChunked query in the order of the
+// given values. The destination must be a slice of structs with a field mapped
+// to the chunked column.
+func (sq *SelectQuery) PreserveOrder() *SelectQuery {
+	return &SelectQuery{sq: sq.sq.PreserveOrder()}
 }
 
 // Distinct adds the DISTINCT keyword to the SELECT clause, eliminating duplicate rows.
@@ -1886,8 +3344,7 @@ func (sq *SelectQuery) Having(condition interface{}, args ...interface{}) *Selec
 //	db.Builder().Select("category").From("products").Distinct().All(&categories)
 //	// SELECT DISTINCT "category" FROM "products"
 func (sq *SelectQuery) Distinct() *SelectQuery {
-	sq.sq.Distinct()
-	return sq
+	return &SelectQuery{sq: sq.sq.Distinct()}
 }
 
 // Union combines this query with another using UNION (removes duplicates).
@@ -1898,8 +3355,7 @@ func (sq *SelectQuery) Distinct() *SelectQuery {
 //	q2 := db.Builder().Select("name").From("archived_users")
 //	q1.Union(q2).All(&names)
 func (sq *SelectQuery) Union(other *SelectQuery) *SelectQuery {
-	sq.sq.Union(other.sq)
-	return sq
+	return &SelectQuery{sq: sq.sq.Union(other.sq)}
 }
 
 // UnionAll combines this query with another using UNION ALL (keeps duplicates).
@@ -1910,8 +3366,7 @@ func (sq *SelectQuery) Union(other *SelectQuery) *SelectQuery {
 //	q2 := db.Builder().Select("id").From("orders_2024")
 //	q1.UnionAll(q2).All(&orderIDs)
 func (sq *SelectQuery) UnionAll(other *SelectQuery) *SelectQuery {
-	sq.sq.UnionAll(other.sq)
-	return sq
+	return &SelectQuery{sq: sq.sq.UnionAll(other.sq)}
 }
 
 // Intersect combines queries using INTERSECT (rows in both).
@@ -1924,8 +3379,7 @@ func (sq *SelectQuery) UnionAll(other *SelectQuery) *SelectQuery {
 //	q2 := db.Builder().Select("user_id").From("orders")
 //	q1.Intersect(q2).All(&ids)  // Users who have placed orders
 func (sq *SelectQuery) Intersect(other *SelectQuery) *SelectQuery {
-	sq.sq.Intersect(other.sq)
-	return sq
+	return &SelectQuery{sq: sq.sq.Intersect(other.sq)}
 }
 
 // Except combines queries using EXCEPT (rows in first but not second).
@@ -1938,21 +3392,46 @@ func (sq *SelectQuery) Intersect(other *SelectQuery) *SelectQuery {
 //	q2 := db.Builder().Select("user_id").From("banned_users")
 //	q1.Except(q2).All(&activeUsers)
 func (sq *SelectQuery) Except(other *SelectQuery) *SelectQuery {
-	sq.sq.Except(other.sq)
-	return sq
+	return &SelectQuery{sq: sq.sq.Except(other.sq)}
+}
+
+// UnionOrderBy sets ORDER BY for the combined result of UNION/INTERSECT/EXCEPT.
+//
+// OrderBy() applies only to the query it is called on; UnionOrderBy sorts the
+// whole combined result.
+//
+// Example:
+//
+//	q1.UnionAll(q2).UnionOrderBy("created_at DESC").UnionLimit(20).All(&events)
+func (sq *SelectQuery) UnionOrderBy(columns ...string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.UnionOrderBy(columns...)}
+}
+
+// UnionLimit sets LIMIT for the combined result of UNION/INTERSECT/EXCEPT.
+func (sq *SelectQuery) UnionLimit(limit int64) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.UnionLimit(limit)}
+}
+
+// UnionOffset sets OFFSET for the combined result of UNION/INTERSECT/EXCEPT.
+func (sq *SelectQuery) UnionOffset(offset int64) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.UnionOffset(offset)}
 }
 
 // With adds a Common Table Expression (CTE).
 //
+// The CTE body is usually a SELECT; on PostgreSQL it may also be a data-modifying
+// UPDATE/DELETE/InsertFromSelect query with Returning().
+// Options such as Materialized() or NotMaterialized() add planner hints
+// (PostgreSQL 12+, SQLite 3.35+; silently dropped on MySQL).
+//
 // Example:
 //
 //	cte := db.Builder().Select("user_id", "SUM(total) as total").
 //	    From("orders").GroupBy("user_id")
-//	db.Builder().Select("*").With("order_totals", cte).
+//	db.Builder().Select("*").With("order_totals", cte, relica.Materialized()).
 //	    From("order_totals").Where("total > ?", 1000).All(&users)
-func (sq *SelectQuery) With(name string, query *SelectQuery) *SelectQuery {
-	sq.sq.With(name, query.sq)
-	return sq
+func (sq *SelectQuery) With(name string, query CTEQuery, opts ...CTEOption) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.With(name, unwrapCTEQuery(query), opts...)}
 }
 
 // WithRecursive adds a recursive Common Table Expression.
@@ -1969,9 +3448,8 @@ func (sq *SelectQuery) With(name string, query *SelectQuery) *SelectQuery {
 //	cte := anchor.UnionAll(recursive)
 //	db.Builder().Select("*").WithRecursive("hierarchy", cte).
 //	    From("hierarchy").OrderBy("level", "name").All(&employees)
-func (sq *SelectQuery) WithRecursive(name string, query *SelectQuery) *SelectQuery {
-	sq.sq.WithRecursive(name, query.sq)
-	return sq
+func (sq *SelectQuery) WithRecursive(name string, query *SelectQuery, opts ...CTEOption) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.WithRecursive(name, query.sq, opts...)}
 }
 
 // Build constructs the Query object from SelectQuery.
@@ -1997,7 +3475,51 @@ func (sq *SelectQuery) One(dest interface{}) error {
 	return sq.sq.One(dest)
 }
 
-// All scans all rows into dest slice.
+// OneOrNil scans a single row into dest like One, but reports a missing row
+// as found == false with a nil error instead of ErrNotFound.
+//
+// Example:
+//
+//	var user User
+//	found, err := db.Select().From("users").Where("email = ?", email).OneOrNil(&user)
+func (sq *SelectQuery) OneOrNil(dest interface{}) (bool, error) {
+	return sq.sq.OneOrNil(dest)
+}
+
+// First scans the first row into dest: by the query's ORDER BY, or else by
+// the primary key of dest ascending.
+//
+// Example:
+//
+//	var user User
+//	err := db.Select().From("users").Where("status = ?", "active").First(&user)
+func (sq *SelectQuery) First(dest interface{}) error {
+	return sq.sq.First(dest)
+}
+
+// Last scans the last row into dest: by the query's ORDER BY reversed, or
+// else by the primary key of dest descending.
+//
+// Example:
+//
+//	var event Event
+//	err := db.Select().From("events").Where("user_id = ?", 7).Last(&event)
+func (sq *SelectQuery) Last(dest interface{}) error {
+	return sq.sq.Last(dest)
+}
+
+// Take scans any one row into dest, with LIMIT 1 but no implicit ordering.
+//
+// Example:
+//
+//	var user User
+//	err := db.Select().From("users").Where("email = ?", email).Take(&user)
+func (sq *SelectQuery) Take(dest interface{}) error {
+	return sq.sq.Take(dest)
+}
+
+// All scans all rows into dest slice, appending them to its contents.
+// When no rows match, a nil dest stays nil (see WithEmptySlices).
 //
 // Example:
 //
@@ -2007,6 +3529,77 @@ func (sq *SelectQuery) All(dest interface{}) error {
 	return sq.sq.All(dest)
 }
 
+// Each scans the rows one at a time into dest (a pointer to a struct or a
+// *NullStringMap) and calls fn after each row, without collecting the rows in
+// memory. dest is overwritten by every row; an error from fn stops the iteration.
+//
+// Example:
+//
+//	var u User
+//	err := db.Select().From("users").Each(&u, func() error {
+//	    return w.Write([]string{u.Name, u.Email})
+//	})
+func (sq *SelectQuery) Each(dest interface{}, fn func() error) error {
+	return sq.sq.Each(dest, fn)
+}
+
+// WithCursor makes All and Each read the result through a server-side cursor,
+// fetchSize rows per round trip. On PostgreSQL this uses DECLARE CURSOR and
+// FETCH inside the query's transaction (or a read-only one that is committed
+// afterwards); other dialects already stream rows and run the query unchanged.
+//
+// Example:
+//
+//	var ev Event
+//	err := db.Select().From("events").WithCursor(1000).Each(&ev, func() error {
+//	    return enc.Encode(ev)
+//	})
+func (sq *SelectQuery) WithCursor(fetchSize int) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.WithCursor(fetchSize)}
+}
+
+// AsOf reads the FROM table as it was at the given time, from its history
+// table (see WithHistory): the row versions valid at that time and the current
+// rows not changed since. Rows inserted through Model() later are excluded.
+// Result rows carry the valid_from and valid_to columns too.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Select().From("users").AsOf(lastMonth).All(&users)
+func (sq *SelectQuery) AsOf(at time.Time) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.AsOf(at)}
+}
+
+// ExportCSV streams the query result to w as CSV with a header row, formatting
+// each value from its database type (NULL as opts.Null, times as RFC 3339,
+// binary columns as base64). A nil opts uses the defaults.
+//
+// Example:
+//
+//	w.Header().Set("Content-Type", "text/csv")
+//	err := db.Select("id", "email", "created_at").From("users").ExportCSV(w, nil)
+func (sq *SelectQuery) ExportCSV(w io.Writer, opts *CSVOptions) error {
+	return sq.sq.ExportCSV(w, opts)
+}
+
+// ExportJSON streams the query result to w as a JSON array of objects keyed
+// by column name.
+func (sq *SelectQuery) ExportJSON(w io.Writer) error {
+	return sq.sq.ExportJSON(w)
+}
+
+// ExportNDJSON streams the query result to w as newline-delimited JSON,
+// one object per row keyed by column name.
+//
+// Example:
+//
+//	w.Header().Set("Content-Type", "application/x-ndjson")
+//	err := db.Select().From("events").WithCursor(1000).ExportNDJSON(w)
+func (sq *SelectQuery) ExportNDJSON(w io.Writer) error {
+	return sq.sq.ExportNDJSON(w)
+}
+
 // Row scans a single row into individual variables.
 // Returns sql.ErrNoRows if no rows are found.
 //
@@ -2032,35 +3625,85 @@ func (sq *SelectQuery) Column(slice interface{}) error {
 	return sq.sq.Column(slice)
 }
 
-// Count executes a COUNT(*) query and returns the number of matching rows.
-// Any columns specified in Select() are ignored; COUNT(*) is always used.
+// ColumnNullable scans the first column of all rows into slice like Column,
+// storing the zero value for NULLs the element type cannot hold. valid, if
+// not nil, receives one entry per row: false where the column was NULL.
 //
 // Example:
 //
-//	count, err := db.Select().From("users").Where(relica.Eq("status", 1)).Count()
-func (sq *SelectQuery) Count() (int64, error) {
-	return sq.sq.Count()
+//	var scores []int
+//	var known []bool
+//	err := db.Select("score").From("players").OrderBy("id").ColumnNullable(&scores, &known)
+func (sq *SelectQuery) ColumnNullable(slice interface{}, valid *[]bool) error {
+	return sq.sq.ColumnNullable(slice, valid)
 }
 
-// Exists executes the query wrapped in SELECT EXISTS(...) and returns true if any rows match.
+// Pluck scans the first len(dests) selected columns of all rows into the
+// slices dests point to, one slice per column. NULLs are handled like in
+// Column (see WithColumnNulls).
 //
 // Example:
 //
-//	exists, err := db.Select().From("users").Where(relica.Eq("email", email)).Exists()
-func (sq *SelectQuery) Exists() (bool, error) {
-	return sq.sq.Exists()
+//	var ids []int
+//	var names []string
+//	err := db.Select("id", "name").From("users").OrderBy("id").Pluck(&ids, &names)
+func (sq *SelectQuery) Pluck(dests ...interface{}) error {
+	return sq.sq.Pluck(dests...)
 }
 
-// ToSQL returns the SQL string and parameters without executing the query.
-// This is useful for debugging, logging, or passing the query to another layer.
+// ToMap builds a map from two result columns: keyCol becomes the key and
+// valueCol the value of each entry. dest must point to a map; a nil map is
+// allocated. Later rows replace earlier entries with the same key.
 //
 // Example:
 //
-//	sql, params := db.Select().From("users").Where(relica.Eq("id", 1)).ToSQL()
+//	var prices map[string]float64
+//	err := db.Select("sku", "price").From("products").ToMap(&prices, "sku", "price")
+func (sq *SelectQuery) ToMap(dest interface{}, keyCol, valueCol string) error {
+	return sq.sq.ToMap(dest, keyCol, valueCol)
+}
+
+// Count executes a COUNT(*) query and returns the number of matching rows.
+// Any columns specified in Select() are ignored; COUNT(*) is always used.
+//
+// Example:
+//
+//	count, err := db.Select().From("users").Where(relica.Eq("status", 1)).Count()
+func (sq *SelectQuery) Count() (int64, error) {
+	return sq.sq.Count()
+}
+
+// Exists executes the query wrapped in SELECT EXISTS(...) and returns true if any rows match.
+//
+// Example:
+//
+//	exists, err := db.Select().From("users").Where(relica.Eq("email", email)).Exists()
+func (sq *SelectQuery) Exists() (bool, error) {
+	return sq.sq.Exists()
+}
+
+// ToSQL returns the SQL string and parameters without executing the query.
+// This is useful for debugging, logging, or passing the query to another layer.
+//
+// Example:
+//
+//	sql, params := db.Select().From("users").Where(relica.Eq("id", 1)).ToSQL()
 func (sq *SelectQuery) ToSQL() (string, []interface{}) {
 	return sq.sq.ToSQL()
 }
 
+// ExpandedSQL returns the built query with its parameters interpolated as
+// escaped SQL literals. The result is for debugging and logging only; never
+// execute it.
+//
+// Example:
+//
+//	fmt.Println(db.Select().From("users").Where("name = ?", "O'Hara").ExpandedSQL())
+//	// SELECT * FROM "users" WHERE name = 'O''Hara'
+func (sq *SelectQuery) ExpandedSQL() string {
+	return sq.sq.ExpandedSQL()
+}
+
 // AsExpression converts a SelectQuery to an Expression for subquery use.
 //
 // Example:
@@ -2072,11 +3715,47 @@ func (sq *SelectQuery) AsExpression() Expression {
 	return sq.sq.AsExpression()
 }
 
+// OrderBySafe adds ORDER BY terms parsed from untrusted input (e.g. a "sort" query parameter).
+// Every column is resolved through the allowed whitelist; unknown columns or malformed
+// terms produce an error wrapping ErrColumnNotAllowed at execution time.
+//
+// Input is comma-separated: "name", "-created_at" (descending), "+name", "name desc".
+//
+// Example:
+//
+//	db.Select().From("users").
+//	    OrderBySafe(r.URL.Query().Get("sort"), relica.AllowedColumns("name", "created_at")).
+//	    All(&users)
+func (sq *SelectQuery) OrderBySafe(input string, allowed *AllowedColumnSet) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.OrderBySafe(input, allowed)}
+}
+
+// ApplyFilters adds WHERE conditions and ORDER BY terms derived from values
+// (typically r.URL.Query()) according to spec. Only declared parameters and
+// operators are accepted and all values are bound as parameters.
+//
+// Example:
+//
+//	spec := relica.NewFilterSpec().
+//	    Allow("status", "status", relica.FilterEq, relica.FilterIn).
+//	    Allow("age", "age", relica.FilterGte, relica.FilterLte).
+//	    SortBy("sort", relica.AllowedColumns("name", "created_at"))
+//
+//	// GET /users?status=active&age[gte]=18&sort=-created_at
+//	db.Select().From("users").ApplyFilters(spec, r.URL.Query()).All(&users)
+func (sq *SelectQuery) ApplyFilters(spec *FilterSpec, values map[string][]string) *SelectQuery {
+	return &SelectQuery{sq: sq.sq.ApplyFilters(spec, values)}
+}
+
 // Unwrap returns the underlying core.SelectQuery for advanced use cases.
 //
 // This method is provided for edge cases where direct access to
 // internal types is needed. Most users should not need this.
+// Unwrap of a nil query returns nil.
 func (sq *SelectQuery) Unwrap() *core.SelectQuery {
+	if sq == nil {
+		return nil
+	}
 	return sq.sq
 }
 
@@ -2143,6 +3822,51 @@ func (uq *UpdateQuery) OrWhere(condition interface{}, params ...interface{}) *Up
 	return uq
 }
 
+// WhereCond adds the condition of a ConditionBuilder to the WHERE clause,
+// combined with AND like Where.
+func (uq *UpdateQuery) WhereCond(cb *ConditionBuilder) *UpdateQuery {
+	uq.uq.WhereCond(cb)
+	return uq
+}
+
+// OrderBy sets the order in which rows are updated, which decides the rows
+// a Limit keeps. Without Limit, only MySQL accepts it.
+func (uq *UpdateQuery) OrderBy(columns ...string) *UpdateQuery {
+	uq.uq.OrderBy(columns...)
+	return uq
+}
+
+// Limit updates at most limit rows: UPDATE ... LIMIT on MySQL, a ctid
+// (PostgreSQL) or rowid (SQLite) subquery elsewhere.
+//
+// Example:
+//
+//	db.Update("jobs").Set(map[string]interface{}{"state": "queued"}).
+//	    Where("state = ?", "new").OrderBy("created_at").Limit(500).Execute()
+func (uq *UpdateQuery) Limit(limit int64) *UpdateQuery {
+	uq.uq.Limit(limit)
+	return uq
+}
+
+// Returning adds a RETURNING clause ("*" for all columns).
+// Supported by PostgreSQL and SQLite 3.35+; MySQL returns an error.
+//
+// Example:
+//
+//	db.Builder().Update("users").Set(map[string]interface{}{"status": 0}).
+//	    Where("last_login < ?", cutoff).Returning("id").Build().Column(&ids)
+func (uq *UpdateQuery) Returning(cols ...string) *UpdateQuery {
+	uq.uq.Returning(cols...)
+	return uq
+}
+
+// AllowFullTableWrite allows the UPDATE to change every row of the table
+// when it has no WHERE condition (see WithSafeWrites).
+func (uq *UpdateQuery) AllowFullTableWrite() *UpdateQuery {
+	uq.uq.AllowFullTableWrite()
+	return uq
+}
+
 // Build constructs the Query object.
 func (uq *UpdateQuery) Build() *Query {
 	if uq.err != nil {
@@ -2222,6 +3946,51 @@ func (dq *DeleteQuery) OrWhere(condition interface{}, params ...interface{}) *De
 	return dq
 }
 
+// WhereCond adds the condition of a ConditionBuilder to the WHERE clause,
+// combined with AND like Where.
+func (dq *DeleteQuery) WhereCond(cb *ConditionBuilder) *DeleteQuery {
+	dq.dq.WhereCond(cb)
+	return dq
+}
+
+// OrderBy sets the order in which rows are deleted, which decides the rows
+// a Limit keeps. Without Limit, only MySQL accepts it.
+func (dq *DeleteQuery) OrderBy(columns ...string) *DeleteQuery {
+	dq.dq.OrderBy(columns...)
+	return dq
+}
+
+// Limit deletes at most limit rows: DELETE ... LIMIT on MySQL, a ctid
+// (PostgreSQL) or rowid (SQLite) subquery elsewhere.
+//
+// Example:
+//
+//	db.Delete("sessions").Where("expires_at < ?", now).
+//	    OrderBy("expires_at").Limit(10000).Execute()
+func (dq *DeleteQuery) Limit(limit int64) *DeleteQuery {
+	dq.dq.Limit(limit)
+	return dq
+}
+
+// Returning adds a RETURNING clause ("*" for all columns).
+// Supported by PostgreSQL and SQLite 3.35+; MySQL returns an error.
+//
+// Example:
+//
+//	db.Builder().Delete("sessions").Where("expires_at < ?", now).
+//	    Returning("id").Build().Column(&ids)
+func (dq *DeleteQuery) Returning(cols ...string) *DeleteQuery {
+	dq.dq.Returning(cols...)
+	return dq
+}
+
+// AllowFullTableWrite allows the DELETE to remove every row of the table
+// when it has no WHERE condition (see WithSafeWrites).
+func (dq *DeleteQuery) AllowFullTableWrite() *DeleteQuery {
+	dq.dq.AllowFullTableWrite()
+	return dq
+}
+
 // Build constructs the Query object.
 func (dq *DeleteQuery) Build() *Query {
 	return &Query{q: dq.dq.Build()}
@@ -2242,6 +4011,89 @@ func (dq *DeleteQuery) ToSQL() (string, []interface{}) {
 	return dq.dq.ToSQL()
 }
 
+// ============================================================================
+// InsertSelectQuery Methods
+// ============================================================================
+
+// InsertSelectQuery represents an INSERT ... SELECT query being built.
+type InsertSelectQuery struct {
+	isq *core.InsertSelectQuery
+}
+
+// WithContext sets the context for this INSERT ... SELECT query.
+func (isq *InsertSelectQuery) WithContext(ctx context.Context) *InsertSelectQuery {
+	return &InsertSelectQuery{isq: isq.isq.WithContext(ctx)}
+}
+
+// Returning adds a RETURNING clause ("*" for all columns).
+// Supported by PostgreSQL and SQLite 3.35+; MySQL returns an error.
+func (isq *InsertSelectQuery) Returning(cols ...string) *InsertSelectQuery {
+	isq.isq.Returning(cols...)
+	return isq
+}
+
+// Build constructs the Query object.
+func (isq *InsertSelectQuery) Build() *Query {
+	return &Query{q: isq.isq.Build()}
+}
+
+// Execute executes the INSERT ... SELECT query.
+func (isq *InsertSelectQuery) Execute() (sql.Result, error) {
+	return isq.Build().Execute()
+}
+
+// ToSQL returns the SQL string and parameters without executing the query.
+func (isq *InsertSelectQuery) ToSQL() (string, []interface{}) {
+	return isq.isq.ToSQL()
+}
+
+// ============================================================================
+// CTE bodies
+// ============================================================================
+
+// CTEQuery is a query usable as the body of a Common Table Expression:
+// *SelectQuery, or (PostgreSQL only) a data-modifying *UpdateQuery, *DeleteQuery
+// or *InsertSelectQuery.
+type CTEQuery interface {
+	cteQuery() core.CTEQuery
+}
+
+func (sq *SelectQuery) cteQuery() core.CTEQuery {
+	if sq == nil {
+		return nil
+	}
+	return sq.sq
+}
+
+func (uq *UpdateQuery) cteQuery() core.CTEQuery {
+	if uq == nil {
+		return nil
+	}
+	return uq.uq
+}
+
+func (dq *DeleteQuery) cteQuery() core.CTEQuery {
+	if dq == nil {
+		return nil
+	}
+	return dq.dq
+}
+
+func (isq *InsertSelectQuery) cteQuery() core.CTEQuery {
+	if isq == nil {
+		return nil
+	}
+	return isq.isq
+}
+
+// unwrapCTEQuery returns the core query behind a CTEQuery (nil-safe).
+func unwrapCTEQuery(query CTEQuery) core.CTEQuery {
+	if query == nil {
+		return nil
+	}
+	return query.cteQuery()
+}
+
 // ============================================================================
 // UpsertQuery Methods
 // ============================================================================
@@ -2286,6 +4138,37 @@ func (uq *UpsertQuery) DoNothing() *UpsertQuery {
 	return uq
 }
 
+// OnConstraint specifies the conflict target by constraint name (PostgreSQL).
+//
+// Example:
+//
+//	Upsert(...).OnConstraint("users_email_key").DoUpdate("name")
+func (uq *UpsertQuery) OnConstraint(name string) *UpsertQuery {
+	uq.uq.OnConstraint(name)
+	return uq
+}
+
+// DoUpdateExpr sets column to a SQL expression on conflict. The expression may
+// reference the existing row by table name and the proposed row as EXCLUDED.
+//
+// Example:
+//
+//	Upsert(...).OnConflict("path").DoUpdateExpr("count", "page_views.count + EXCLUDED.count")
+func (uq *UpsertQuery) DoUpdateExpr(column, expr string, args ...interface{}) *UpsertQuery {
+	uq.uq.DoUpdateExpr(column, expr, args...)
+	return uq
+}
+
+// Where makes the update on conflict conditional (PostgreSQL, SQLite).
+//
+// Example:
+//
+//	Upsert(...).OnConflict("sku").DoUpdate("price").Where("EXCLUDED.updated_at > products.updated_at")
+func (uq *UpsertQuery) Where(condition interface{}, params ...interface{}) *UpsertQuery {
+	uq.uq.Where(condition, params...)
+	return uq
+}
+
 // Build constructs the Query object.
 func (uq *UpsertQuery) Build() *Query {
 	return &Query{q: uq.uq.Build()}
@@ -2308,6 +4191,93 @@ func (uq *UpsertQuery) ToSQL() (string, []interface{}) {
 	return q.SQL(), q.Params()
 }
 
+// ============================================================================
+// MergeQuery Methods
+// ============================================================================
+
+// MergeQuery represents a MERGE query being built.
+type MergeQuery struct {
+	mq *core.MergeQuery
+}
+
+// WithContext sets the context for this MERGE query.
+func (mq *MergeQuery) WithContext(ctx context.Context) *MergeQuery {
+	mq.mq.WithContext(ctx)
+	return mq
+}
+
+// Using sets the source query, its alias and the match condition (a string
+// with "?" placeholders or an Expression).
+//
+// Example:
+//
+//	Merge("users u").Using(staged, "s", "u.id = s.id")
+func (mq *MergeQuery) Using(source *SelectQuery, alias string, on interface{}, params ...interface{}) *MergeQuery {
+	mq.mq.Using(source.Unwrap(), alias, on, params...)
+	return mq
+}
+
+// UsingTable sets a source table, its alias and the match condition.
+//
+// Example:
+//
+//	Merge("inventory i").UsingTable("deliveries", "d", "i.sku = d.sku")
+func (mq *MergeQuery) UsingTable(table, alias string, on interface{}, params ...interface{}) *MergeQuery {
+	mq.mq.UsingTable(table, alias, on, params...)
+	return mq
+}
+
+// Keys sets the target's unique key columns that the ON condition compares,
+// used as the conflict target by the MySQL and SQLite emulation.
+func (mq *MergeQuery) Keys(columns ...string) *MergeQuery {
+	mq.mq.Keys(columns...)
+	return mq
+}
+
+// WhenMatchedUpdate updates matched target rows. Values are bound as
+// parameters unless they are Expressions, e.g. relica.NewExp("s.name").
+func (mq *MergeQuery) WhenMatchedUpdate(values map[string]interface{}) *MergeQuery {
+	mq.mq.WhenMatchedUpdate(values)
+	return mq
+}
+
+// WhenMatchedDelete deletes matched target rows (PostgreSQL 15+ only).
+func (mq *MergeQuery) WhenMatchedDelete() *MergeQuery {
+	mq.mq.WhenMatchedDelete()
+	return mq
+}
+
+// WhenNotMatchedInsert inserts source rows without a matching target row.
+func (mq *MergeQuery) WhenNotMatchedInsert(values map[string]interface{}) *MergeQuery {
+	mq.mq.WhenNotMatchedInsert(values)
+	return mq
+}
+
+// And adds a condition to the last WHEN clause (PostgreSQL 15+ only).
+//
+// Example:
+//
+//	WhenMatchedDelete().And("s.deleted = ?", true)
+func (mq *MergeQuery) And(condition string, params ...interface{}) *MergeQuery {
+	mq.mq.And(condition, params...)
+	return mq
+}
+
+// Build constructs the Query object.
+func (mq *MergeQuery) Build() *Query {
+	return &Query{q: mq.mq.Build()}
+}
+
+// Execute executes the MERGE query.
+func (mq *MergeQuery) Execute() (sql.Result, error) {
+	return mq.Build().Execute()
+}
+
+// ToSQL returns the SQL string and parameters without executing the query.
+func (mq *MergeQuery) ToSQL() (string, []interface{}) {
+	return mq.mq.ToSQL()
+}
+
 // ============================================================================
 // BatchInsertQuery Methods
 // ============================================================================
@@ -2367,6 +4337,123 @@ func (biq *BatchInsertQuery) ToSQL() (string, []interface{}) {
 	return q.SQL(), q.Params()
 }
 
+// ============================================================================
+// InsertAllQuery Methods
+// ============================================================================
+
+// InsertAllQuery inserts a slice of structs (see QueryBuilder.InsertAll).
+type InsertAllQuery struct {
+	iq *core.InsertAllQuery
+}
+
+// WithContext sets the context for this query.
+func (iq *InsertAllQuery) WithContext(ctx context.Context) *InsertAllQuery {
+	return &InsertAllQuery{iq: iq.iq.WithContext(ctx)}
+}
+
+// BatchSize sets the maximum number of rows per INSERT statement
+// (default: as many as fit in 999 bind parameters).
+func (iq *InsertAllQuery) BatchSize(n int) *InsertAllQuery {
+	return &InsertAllQuery{iq: iq.iq.BatchSize(n)}
+}
+
+// Execute inserts the rows and returns the number of inserted rows.
+// An empty slice inserts nothing.
+func (iq *InsertAllQuery) Execute() (int64, error) {
+	return iq.iq.Execute()
+}
+
+// ============================================================================
+// BatchDeleteQuery Methods
+// ============================================================================
+
+// BatchDeleteQuery deletes rows by key in chunks (see QueryBuilder.BatchDelete).
+type BatchDeleteQuery struct {
+	bq *core.BatchDeleteQuery
+}
+
+// BatchDeleteProgress reports the progress of a BatchDelete after a chunk:
+// the rows deleted and the keys processed so far, and the number of keys.
+type BatchDeleteProgress = core.BatchDeleteProgress
+
+// WithContext sets the context for this query.
+func (bq *BatchDeleteQuery) WithContext(ctx context.Context) *BatchDeleteQuery {
+	return &BatchDeleteQuery{bq: bq.bq.WithContext(ctx)}
+}
+
+// Keys adds keys of the rows to delete. A slice argument (other than
+// []byte) adds its elements, so Keys(ids) works for an []int64.
+func (bq *BatchDeleteQuery) Keys(keys ...interface{}) *BatchDeleteQuery {
+	return &BatchDeleteQuery{bq: bq.bq.Keys(keys...)}
+}
+
+// ChunkSize sets the number of keys deleted per statement
+// (default DefaultInChunkSize).
+func (bq *BatchDeleteQuery) ChunkSize(n int) *BatchDeleteQuery {
+	return &BatchDeleteQuery{bq: bq.bq.ChunkSize(n)}
+}
+
+// Sleep pauses for d between chunks. The pause ends early, with the
+// context's error, when the context is canceled.
+func (bq *BatchDeleteQuery) Sleep(d time.Duration) *BatchDeleteQuery {
+	return &BatchDeleteQuery{bq: bq.bq.Sleep(d)}
+}
+
+// Progress calls fn after every chunk with the totals so far.
+func (bq *BatchDeleteQuery) Progress(fn func(BatchDeleteProgress)) *BatchDeleteQuery {
+	return &BatchDeleteQuery{bq: bq.bq.Progress(fn)}
+}
+
+// Execute deletes the rows and returns the number of rows deleted. If a
+// chunk fails, the rows of the previous chunks stay deleted and their count
+// is returned with the error, so the cleanup can be resumed.
+func (bq *BatchDeleteQuery) Execute() (int64, error) {
+	return bq.bq.Execute()
+}
+
+// ============================================================================
+// TruncateQuery Methods
+// ============================================================================
+
+// TruncateQuery represents a TRUNCATE query being built.
+type TruncateQuery struct {
+	tq *core.TruncateQuery
+}
+
+// WithContext sets the context for this TRUNCATE query.
+func (tq *TruncateQuery) WithContext(ctx context.Context) *TruncateQuery {
+	return &TruncateQuery{tq: tq.tq.WithContext(ctx)}
+}
+
+// Cascade also truncates the tables with foreign keys referencing the table
+// (PostgreSQL only; Execute returns ErrUnsupportedDialect elsewhere).
+func (tq *TruncateQuery) Cascade() *TruncateQuery {
+	return &TruncateQuery{tq: tq.tq.Cascade()}
+}
+
+// RestartIdentity resets the sequences and auto-increment counters of the
+// table, so the next inserted row gets the first ID again. MySQL always does
+// this on TRUNCATE; on SQLite the AUTOINCREMENT counter is reset.
+func (tq *TruncateQuery) RestartIdentity() *TruncateQuery {
+	return &TruncateQuery{tq: tq.tq.RestartIdentity()}
+}
+
+// Build constructs the Query object. On SQLite it is the DELETE statement
+// only; Execute also resets the AUTOINCREMENT counter.
+func (tq *TruncateQuery) Build() *Query {
+	return &Query{q: tq.tq.Build()}
+}
+
+// Execute removes every row of the table.
+func (tq *TruncateQuery) Execute() error {
+	return tq.tq.Execute()
+}
+
+// ToSQL returns the SQL string without executing the query.
+func (tq *TruncateQuery) ToSQL() (string, []interface{}) {
+	return tq.tq.ToSQL()
+}
+
 // ============================================================================
 // BatchUpdateQuery Methods
 // ============================================================================
@@ -2393,6 +4480,23 @@ func (buq *BatchUpdateQuery) Set(keyValue interface{}, values map[string]interfa
 	return buq
 }
 
+// LockOrdered sorts the batch by key and makes Execute lock the rows in key
+// order (SELECT ... ORDER BY key FOR UPDATE) before updating them, so workers
+// batch-updating overlapping keys cannot deadlock. Execute runs in the
+// current transaction, or in a new one if there is none.
+//
+// Example:
+//
+//	db.BatchUpdate("accounts", "id").
+//	    Set(7, map[string]interface{}{"balance": 120}).
+//	    Set(3, map[string]interface{}{"balance": 80}).
+//	    LockOrdered().
+//	    Execute()
+func (buq *BatchUpdateQuery) LockOrdered() *BatchUpdateQuery {
+	buq.buq.LockOrdered()
+	return buq
+}
+
 // Build constructs the Query object.
 func (buq *BatchUpdateQuery) Build() *Query {
 	return &Query{q: buq.buq.Build()}
@@ -2400,7 +4504,11 @@ func (buq *BatchUpdateQuery) Build() *Query {
 
 // Execute executes the batch UPDATE query.
 func (buq *BatchUpdateQuery) Execute() (sql.Result, error) {
-	return buq.Build().Execute()
+	result, err := buq.buq.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return result.(sql.Result), nil
 }
 
 // ToSQL returns the SQL string and parameters without executing the query.
@@ -2439,10 +4547,19 @@ func (q *Query) One(dest interface{}) error {
 	return q.q.One(dest)
 }
 
-// All fetches all rows into dest slice.
-func (q *Query) All(dest interface{}) error {
+// OneOrNil fetches a single row into dest like One, but reports a missing row
+// as found == false with a nil error.
+func (q *Query) OneOrNil(dest interface{}) (bool, error) {
 	if q.err != nil {
-		return q.err
+		return false, q.err
+	}
+	return q.q.OneOrNil(dest)
+}
+
+// All fetches all rows into dest slice.
+func (q *Query) All(dest interface{}) error {
+	if q.err != nil {
+		return q.err
 	}
 	return q.q.All(dest)
 }
@@ -2476,6 +4593,44 @@ func (q *Query) Column(slice interface{}) error {
 	return q.q.Column(slice)
 }
 
+// ColumnNullable scans the first column of all rows into slice like Column,
+// storing the zero value for NULLs the element type cannot hold. valid, if
+// not nil, receives one entry per row: false where the column was NULL.
+func (q *Query) ColumnNullable(slice interface{}, valid *[]bool) error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.q.ColumnNullable(slice, valid)
+}
+
+// Pluck scans the first len(dests) columns of all rows into the slices
+// dests point to, one slice per column.
+//
+// Example:
+//
+//	var ids []int
+//	var names []string
+//	err := db.NewQuery("SELECT id, name FROM users ORDER BY id").Pluck(&ids, &names)
+func (q *Query) Pluck(dests ...interface{}) error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.q.Pluck(dests...)
+}
+
+// ToMap builds a map from the result columns keyCol and valueCol.
+//
+// Example:
+//
+//	var names map[int]string
+//	err := db.NewQuery("SELECT id, name FROM users").ToMap(&names, "id", "name")
+func (q *Query) ToMap(dest interface{}, keyCol, valueCol string) error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.q.ToMap(dest, keyCol, valueCol)
+}
+
 // Prepare prepares the query for repeated execution.
 // Call Close() when done to release the prepared statement.
 // The prepared statement bypasses the automatic statement cache,
@@ -2533,6 +4688,58 @@ func (q *Query) Bind(params ...interface{}) *Query {
 	return q
 }
 
+// WithContext sets the context used to execute the query.
+// It overrides the context inherited from the DB or transaction.
+//
+// Example:
+//
+//	err := db.NewQuery("SELECT COUNT(*) FROM users").WithContext(ctx).Row(&count)
+func (q *Query) WithContext(ctx context.Context) *Query {
+	if q.err != nil {
+		return q
+	}
+	q.q.WithContext(ctx)
+	return q
+}
+
+// OnPool executes this query on the named connection pool (see DB.Pool).
+// Ignored inside transactions and for statements created with Prepare.
+//
+// Example:
+//
+//	db.NewQuery("SELECT COUNT(*) FROM events").OnPool("reporting").Row(&count)
+func (q *Query) OnPool(name string) *Query {
+	if q.err != nil {
+		return q
+	}
+	q.q.OnPool(name)
+	return q
+}
+
+// Tag names this query for logs, hooks, audit records and DB.QueryStats.
+func (q *Query) Tag(name string) *Query {
+	if q.err != nil {
+		return q
+	}
+	q.q.Tag(name)
+	return q
+}
+
+// Idempotent marks a write as safe to repeat, so that WithRetry retries it on
+// transient errors. A retried statement may already have been applied.
+//
+// Example:
+//
+//	db.Builder().Update("users").Set(relica.Params{"status": "active"}).
+//	    Where(relica.HashExp{"id": id}).Build().Idempotent().Execute()
+func (q *Query) Idempotent() *Query {
+	if q.err != nil {
+		return q
+	}
+	q.q.Idempotent()
+	return q
+}
+
 // BindParams binds named parameters using Params map.
 // Named parameters are specified using {:name} syntax.
 //
@@ -2557,6 +4764,16 @@ func (q *Query) SQL() string {
 	return q.q.SQL()
 }
 
+// ExpandedSQL returns the SQL with its parameters interpolated as escaped SQL
+// literals, ready to paste into psql/mysql/sqlite3. The result is for
+// debugging and logging only; never execute it.
+func (q *Query) ExpandedSQL() string {
+	if q.q == nil {
+		return ""
+	}
+	return q.q.ExpandedSQL()
+}
+
 // Params returns the query parameters.
 func (q *Query) Params() []interface{} {
 	if q.q == nil {
@@ -2589,6 +4806,121 @@ func (q *Query) QueryParams() []interface{} {
 //	}
 var ErrNotFound = core.ErrNotFound
 
+// ErrColumnNotAllowed is returned when user-supplied sort input references a column
+// outside the whitelist passed to OrderBySafe (or a FilterSpec), or is malformed.
+var ErrColumnNotAllowed = core.ErrColumnNotAllowed
+
+// ErrFilterNotAllowed is returned when a FilterSpec parameter is used with an
+// operator that was not declared for it.
+var ErrFilterNotAllowed = core.ErrFilterNotAllowed
+
+// ErrQueueFull is returned when the concurrency limiter's wait queue is full
+// (see WithMaxConcurrentQueries).
+var ErrQueueFull = core.ErrQueueFull
+
+// ErrQueueTimeout is returned when a query waited longer than QueuePolicy.Timeout
+// for a concurrency slot.
+var ErrQueueTimeout = core.ErrQueueTimeout
+
+// ErrInvalidEnumValue is returned when a query parameter of a registered enum
+// type (see RegisterEnum) holds a value that is not allowed.
+var ErrInvalidEnumValue = core.ErrInvalidEnumValue
+
+// ErrLockHeld is returned by DB.TryAdvisoryLock when another session holds the lock.
+var ErrLockHeld = core.ErrLockHeld
+
+// ErrNoOptimizerStore is returned by the optimizer store methods of a DB opened
+// without WithOptimizerStore.
+var ErrNoOptimizerStore = core.ErrNoOptimizerStore
+
+// ErrNoAuditTable is returned by the audit log table methods of a DB opened
+// without WithAuditTable.
+var ErrNoAuditTable = core.ErrNoAuditTable
+
+// ErrShuttingDown is returned for queries and transactions started after DB.Shutdown.
+var ErrShuttingDown = core.ErrShuttingDown
+
+// ErrNotReconfigurable is returned by DB.Reconfigure for options that cannot
+// change while the database is in use.
+var ErrNotReconfigurable = core.ErrNotReconfigurable
+
+// ErrQueryNotRegistered is returned when executing a DB.Query name that was
+// not registered with RegisterQuery.
+var ErrQueryNotRegistered = core.ErrQueryNotRegistered
+
+// ErrUnsupportedDialect is returned by features the connected database does
+// not support, e.g. materialized views outside PostgreSQL.
+var ErrUnsupportedDialect = core.ErrUnsupportedDialect
+
+// ErrUnknownTable is returned in strict schema mode when a query references a
+// table that does not exist (see WithStrictSchema).
+var ErrUnknownTable = core.ErrUnknownTable
+
+// ErrUnknownColumn is returned in strict schema mode when a query references a
+// column that does not exist (see WithStrictSchema).
+var ErrUnknownColumn = core.ErrUnknownColumn
+
+// ErrLintViolation is returned when a query breaks a lint rule that the lint
+// policy blocks (see WithQueryLint).
+var ErrLintViolation = core.ErrLintViolation
+
+// ErrMissingWhere is returned in safe-writes mode for an UPDATE or DELETE
+// without a WHERE condition (see WithSafeWrites).
+var ErrMissingWhere = core.ErrMissingWhere
+
+// ErrReadOnly is returned when a statement that may change data is built or
+// executed through a read-only handle or query (see WithReadOnly,
+// SelectQuery.ReadOnly).
+var ErrReadOnly = core.ErrReadOnly
+
+// ErrTooManyParams is returned when a built statement has more bind parameters
+// than the database allows (PostgreSQL and MySQL 65535, SQLite 32766). The
+// error names the parameter count and the limit.
+var ErrTooManyParams = core.ErrTooManyParams
+
+// ErrQueryWatchdog is returned when a query is canceled for running longer
+// than the maximum runtime of WithQueryWatchdog. Such errors are ErrTimeout
+// as well.
+var ErrQueryWatchdog = core.ErrQueryWatchdog
+
+// ErrPartialCommit is returned when a coordinated transaction was committed
+// on some databases but not others, and compensation did not undo it (see
+// Coordinator).
+var ErrPartialCommit = core.ErrPartialCommit
+
+// Database error kinds. Query, Exec and Commit errors of these kinds are
+// returned as a *DBError, so they can be matched with errors.Is on
+// PostgreSQL, MySQL and SQLite alike.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, transfer)
+//	if errors.Is(err, relica.ErrSerializationFailure) || errors.Is(err, relica.ErrDeadlock) {
+//	    // retry the transaction
+//	}
+var (
+	ErrUniqueViolation      = core.ErrUniqueViolation
+	ErrForeignKeyViolation  = core.ErrForeignKeyViolation
+	ErrNotNullViolation     = core.ErrNotNullViolation
+	ErrCheckViolation       = core.ErrCheckViolation
+	ErrSerializationFailure = core.ErrSerializationFailure
+	ErrDeadlock             = core.ErrDeadlock
+	ErrTimeout              = core.ErrTimeout
+)
+
+// DBError is a database error of a known kind (ErrUniqueViolation,
+// ErrDeadlock, ...) with the violated constraint, table and column when the
+// driver reports them. Error returns the driver message, and Unwrap the
+// driver error.
+//
+// Example:
+//
+//	var dbErr *relica.DBError
+//	if errors.As(err, &dbErr) && dbErr.Kind == relica.ErrUniqueViolation {
+//	    return fmt.Errorf("%s is already taken", dbErr.Column)
+//	}
+type DBError = core.DBError
+
 // IsUniqueViolation reports whether err represents a unique constraint violation.
 // Works with PostgreSQL, MySQL, and SQLite. Returns false for nil errors.
 //
@@ -2633,6 +4965,11 @@ func IsNotNullViolation(err error) bool { return core.IsNotNullViolation(err) }
 //	}
 func IsCheckViolation(err error) bool { return core.IsCheckViolation(err) }
 
+// IsTransientError reports whether err is a transient connection failure
+// (driver.ErrBadConn, a reset or refused connection, a network timeout) that
+// may succeed when retried. Returns false for nil errors and context cancellation.
+func IsTransientError(err error) bool { return core.IsTransientError(err) }
+
 // ============================================================================
 // Re-export configuration options
 // ============================================================================
@@ -2663,19 +5000,187 @@ func WithConnMaxLifetime(d time.Duration) Option { return core.WithConnMaxLifeti
 //	    relica.WithConnMaxIdleTime(1*time.Minute))
 func WithConnMaxIdleTime(d time.Duration) Option { return core.WithConnMaxIdleTime(d) }
 
+// WithSlowQueryThreshold logs queries taking at least d as "slow query"
+// warnings through the logger set with WithLogger. Zero disables it.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn,
+//	    relica.WithLogger(relica.NewSlogAdapter(slog.Default())),
+//	    relica.WithSlowQueryThreshold(500*time.Millisecond))
+func WithSlowQueryThreshold(d time.Duration) Option { return core.WithSlowQueryThreshold(d) }
+
+// WithLogLevel sets the minimum level of the messages passed to the logger
+// set with WithLogger. The default passes all messages.
+func WithLogLevel(level slog.Level) Option { return core.WithLogLevel(level) }
+
+// WithTracer creates a span for every query and transaction. Query spans are
+// named after the query tag, else the operation and table ("SELECT users");
+// Begin starts a "relica.transaction" span that the transaction's queries are
+// children of, ended by Commit or Rollback. See Tracer for writing an adapter.
+// Options sample query spans and control which attributes they carry.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithTracer(otelTracer{otel.Tracer("relica")},
+//	    relica.TraceSampleRate(0.01, "SELECT"),
+//	    relica.TraceSlowQueries(500*time.Millisecond),
+//	    relica.TraceStatement(relica.TraceStatementFingerprint)))
+func WithTracer(tracer Tracer, opts ...TracingOption) Option {
+	return core.WithTracer(tracer, opts...)
+}
+
+// TracingOption configures WithTracer.
+type TracingOption = core.TracingOption
+
+// TraceStatementMode selects the statement attribute of query spans (see
+// TraceStatement).
+type TraceStatementMode = core.TraceStatementMode
+
+const (
+	// TraceStatementFull attaches the SQL as executed (the default).
+	TraceStatementFull = core.TraceStatementFull
+	// TraceStatementFingerprint attaches the SQL with literals replaced by ?
+	// and IN lists collapsed, so no values reach the tracing backend.
+	TraceStatementFingerprint = core.TraceStatementFingerprint
+	// TraceStatementNone attaches no SQL.
+	TraceStatementNone = core.TraceStatementNone
+)
+
+// TraceSampleRate traces the given fraction (0 to 1) of the queries of
+// operations ("SELECT", "INSERT", ...), or of all queries if none are given.
+// Failed queries, and queries slower than TraceSlowQueries, are always
+// traced; their span is started after the query ran (see TimestampTracer).
+// Transaction spans are not sampled.
+func TraceSampleRate(rate float64, operations ...string) TracingOption {
+	return core.TraceSampleRate(rate, operations...)
+}
+
+// TraceSlowQueries traces queries running for at least threshold even if
+// TraceSampleRate left them out.
+func TraceSlowQueries(threshold time.Duration) TracingOption {
+	return core.TraceSlowQueries(threshold)
+}
+
+// TraceStatement selects how the SQL of a query is attached to its span.
+func TraceStatement(mode TraceStatementMode) TracingOption { return core.TraceStatement(mode) }
+
+// TraceParams attaches the query parameters to query spans as
+// db.statement.params, masked like in logs (see WithSensitiveFields).
+func TraceParams() TracingOption { return core.TraceParams() }
+
+// WithMeterProvider records query durations, query errors and connection pool
+// gauges (db.client.operation.duration, db.client.operation.errors,
+// db.client.connection.*) through the meter of mp, typically an adapter over
+// an OpenTelemetry MeterProvider (see the logging guide).
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn,
+//	    relica.WithMeterProvider(otelMeterProvider{otel.GetMeterProvider()}))
+func WithMeterProvider(mp MeterProvider) Option { return core.WithMeterProvider(mp) }
+
 // WithHealthCheck enables periodic health checks on database connections.
 // The health checker pings the database at the specified interval to detect dead connections.
-// If interval <= 0, health checks are disabled.
+// Options set a probe query, a latency threshold for the degraded state and
+// a callback on state changes. If interval <= 0, health checks are disabled.
 //
 // Example:
 //
 //	db, err := relica.Open("postgres", dsn,
-//	    relica.WithHealthCheck(30*time.Second))
-func WithHealthCheck(interval time.Duration) Option { return core.WithHealthCheck(interval) }
+//	    relica.WithHealthCheck(30*time.Second,
+//	        relica.HealthCheckDegradedAfter(200*time.Millisecond),
+//	        relica.HealthCheckOnChange(func(c relica.HealthChange) {
+//	            ready.Store(c.To != relica.HealthStateUnhealthy)
+//	        })))
+func WithHealthCheck(interval time.Duration, opts ...HealthCheckOption) Option {
+	return core.WithHealthCheck(interval, opts...)
+}
+
+// HealthCheckOption configures WithHealthCheck.
+type HealthCheckOption = core.HealthCheckOption
+
+// HealthState is the state reported by the health checker.
+type HealthState = core.HealthState
+
+// Health states reported in PoolStats and HealthChange.
+const (
+	// HealthStateHealthy means the last probe succeeded in time.
+	HealthStateHealthy = core.HealthStateHealthy
+
+	// HealthStateDegraded means the last probe succeeded but was slower than
+	// the HealthCheckDegradedAfter threshold.
+	HealthStateDegraded = core.HealthStateDegraded
+
+	// HealthStateUnhealthy means the last probe failed.
+	HealthStateUnhealthy = core.HealthStateUnhealthy
+)
+
+// HealthChange describes a health state transition (see HealthCheckOnChange).
+type HealthChange = core.HealthChange
+
+// HealthCheckQuery probes with query instead of a ping; its rows are discarded.
+func HealthCheckQuery(query string) HealthCheckOption { return core.HealthCheckQuery(query) }
+
+// HealthCheckDegradedAfter reports the database as degraded when a probe
+// succeeds but takes longer than latency.
+func HealthCheckDegradedAfter(latency time.Duration) HealthCheckOption {
+	return core.HealthCheckDegradedAfter(latency)
+}
+
+// HealthCheckOnChange calls fn from the health check goroutine whenever the
+// health state changes.
+func HealthCheckOnChange(fn func(HealthChange)) HealthCheckOption {
+	return core.HealthCheckOnChange(fn)
+}
+
+// WithReplicas adds read replicas opened with the DB's driver and the given
+// DSNs. SELECT queries built outside transactions are spread over them in
+// turn; writes, raw SQL, transactions, locking reads and OnPool queries run
+// on their own pool. Replicas are the named pools "replica-0", "replica-1", ...
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", primaryDSN,
+//	    relica.WithReplicas(replica1DSN, replica2DSN),
+//	    relica.WithReplicaLagCheck(5*time.Second, 10*time.Second))
+func WithReplicas(dsns ...string) Option { return core.WithReplicas(dsns...) }
+
+// WithReplicaLagCheck measures the replication lag of the read replicas every
+// interval (pg_last_xact_replay_timestamp on PostgreSQL, SHOW REPLICA STATUS
+// on MySQL). Replicas lagging more than maxLag (0 = no limit), or whose lag
+// could not be measured, serve no reads until a later check succeeds; queries
+// with RequireFresh run on the primary unless a replica is fresh enough.
+func WithReplicaLagCheck(interval, maxLag time.Duration) Option {
+	return core.WithReplicaLagCheck(interval, maxLag)
+}
+
+// WithSessionSettings applies session settings to every new connection of
+// the DB, its named pools and its replicas: set_config on PostgreSQL (e.g.
+// search_path, statement_timeout), SET SESSION on MySQL (e.g. sql_mode,
+// time_zone) and PRAGMA on SQLite (e.g. foreign_keys, busy_timeout). Use
+// Pool.SetSessionSettings to give a pool its own settings. DBs created with
+// WrapDB ignore the option.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithSessionSettings(map[string]string{
+//	    "search_path":       "app, public",
+//	    "statement_timeout": "5s",
+//	}))
+func WithSessionSettings(settings map[string]string) Option {
+	return core.WithSessionSettings(settings)
+}
 
 // WithStmtCacheCapacity sets the prepared statement cache capacity.
 func WithStmtCacheCapacity(capacity int) Option { return core.WithStmtCacheCapacity(capacity) }
 
+// WithStmtCacheShards splits the prepared statement cache into n shards, each
+// with its own lock, to reduce lock contention under high concurrency. n is
+// rounded up to a power of two. By default the cache has up to 16 shards of at
+// least 64 statements; statements are evicted LRU within their shard.
+func WithStmtCacheShards(n int) Option { return core.WithStmtCacheShards(n) }
+
 // WithLogger sets the logger for database query logging.
 // If not set, a NoopLogger is used (zero overhead when logging is disabled).
 //
@@ -2699,6 +5204,302 @@ func WithLogger(l Logger) Option { return core.WithLogger(l) }
 //	    }))
 func WithQueryHook(hook QueryHook) Option { return core.WithQueryHook(hook) }
 
+// WithChangeSink enables change data capture: every INSERT, UPDATE, DELETE and
+// UPSERT executed through the query builder or Model emits a ChangeEvent to sink.
+// Events of transactional statements are delivered on commit and dropped on
+// rollback. Raw SQL emits no events.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithChangeSink(func(ctx context.Context, e relica.ChangeEvent) {
+//	        for _, key := range e.Keys {
+//	            cache.Delete(e.Table, key["id"])
+//	        }
+//	    }))
+func WithChangeSink(sink ChangeSink) Option { return core.WithChangeSink(sink) }
+
+// WithHistory records the previous version of every row of table changed or
+// deleted through Model() in <table>_history, and the time of every Model()
+// insert, in the same transaction as the write. keys are the primary key
+// columns matched by AsOf (default "id").
+// Create the history table with DB.CreateHistoryTable or a migration.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithHistory("users"))
+//	_ = db.CreateHistoryTable(ctx, "users")
+//	_ = db.Model(&user).Update() // previous version copied to users_history
+func WithHistory(table string, keys ...string) Option { return core.WithHistory(table, keys...) }
+
+// WithSnowflakeNode sets the node number (0-1023) of the Snowflake IDs that
+// Model().Insert() generates for fields tagged relica:"snowflake" (default 0).
+// Give every process inserting into the same tables its own node.
+func WithSnowflakeNode(node int64) Option { return core.WithSnowflakeNode(node) }
+
+// WithExpandedSQLLogging makes the logger record the expanded SQL (see
+// Query.ExpandedSQL) of failed queries under the "expanded_sql" key.
+// Sensitive parameters (see WithSensitiveFields) are masked before expansion.
+func WithExpandedSQLLogging() Option { return core.WithExpandedSQLLogging() }
+
+// WithSQLCommenter appends a sqlcommenter comment (/*application='svc',traceparent='...'*/)
+// to every executed statement, so database slow query logs can be correlated with
+// application traces. Queries carrying per-request tags bypass the statement cache.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithSQLCommenter(
+//	        relica.CommentApplication("billing"),
+//	        relica.CommentFromContext(func(ctx context.Context) map[string]string {
+//	            return map[string]string{"traceparent": traceparentFrom(ctx)}
+//	        }),
+//	    ))
+func WithSQLCommenter(opts ...SQLCommenterOption) Option { return core.WithSQLCommenter(opts...) }
+
+// SQLCommenterOption configures WithSQLCommenter.
+type SQLCommenterOption = core.SQLCommenterOption
+
+// CommentTag adds a static key/value tag to every statement comment.
+func CommentTag(key, value string) SQLCommenterOption { return core.CommentTag(key, value) }
+
+// CommentApplication adds the standard application='name' tag.
+func CommentApplication(name string) SQLCommenterOption { return core.CommentApplication(name) }
+
+// CommentFromContext registers a function that extracts per-request tags
+// (typically "traceparent") from the query context.
+func CommentFromContext(fn func(ctx context.Context) map[string]string) SQLCommenterOption {
+	return core.CommentFromContext(fn)
+}
+
+// ContextWithCommentTags returns a context carrying additional comment tags
+// (e.g. route or controller) for queries executed with it.
+func ContextWithCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	return core.ContextWithCommentTags(ctx, tags)
+}
+
+// WithMaxConcurrentQueries limits the number of concurrently executing queries to n.
+// Excess queries wait in a priority queue (see WithQueryPriority) according to policy,
+// instead of piling onto the connection pool. Queries inside transactions and raw
+// ExecContext/QueryContext calls are not limited. If n <= 0, no limit is applied.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithMaxConcurrentQueries(20, relica.QueuePolicy{
+//	        MaxQueueLength: 500,
+//	        Timeout:        2 * time.Second,
+//	    }))
+func WithMaxConcurrentQueries(n int, policy QueuePolicy) Option {
+	return core.WithMaxConcurrentQueries(n, policy)
+}
+
+// QueuePolicy configures how queries wait when the concurrency limit is reached.
+type QueuePolicy = core.QueuePolicy
+
+// WithRetry retries read-only statements that fail with a transient error,
+// with jittered exponential backoff. Writes are retried only when marked with
+// Query.Idempotent, and statements inside transactions never.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithRetry(relica.RetryPolicy{MaxAttempts: 4, MaxBackoff: time.Second}))
+func WithRetry(policy RetryPolicy) Option { return core.WithRetry(policy) }
+
+// RetryPolicy configures how statements are retried on transient errors (see WithRetry).
+type RetryPolicy = core.RetryPolicy
+
+// DefaultInChunkSize is the chunk size used by WhereInChunked when chunkSize <= 0
+// and by ModelQuery.FindByIDs.
+const DefaultInChunkSize = core.DefaultInChunkSize
+
+// Query priorities for WithQueryPriority. Any int is valid; higher runs first.
+const (
+	PriorityLow    = core.PriorityLow
+	PriorityNormal = core.PriorityNormal
+	PriorityHigh   = core.PriorityHigh
+)
+
+// WithQueryPriority returns a context whose queries are dequeued before queries
+// with a lower priority when the concurrency limit is reached.
+//
+// Example:
+//
+//	ctx = relica.WithQueryPriority(ctx, relica.PriorityHigh)
+//	db.Builder().WithContext(ctx).Select("*").From("orders").All(&orders)
+func WithQueryPriority(ctx context.Context, priority int) context.Context {
+	return core.WithQueryPriority(ctx, priority)
+}
+
+// WithNPlusOneDetector reports N+1 query patterns: the same SELECT run
+// threshold times within one query scope (see WithQueryScope) with only one
+// parameter changing. A threshold below 2 defaults to 10. With a nil handler,
+// warnings are written to stderr.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithNPlusOneDetector(10, func(ctx context.Context, w relica.NPlusOneWarning) {
+//	        slog.WarnContext(ctx, "N+1 query", "sql", w.SQL, "count", w.Count, "fix", w.Suggestion())
+//	    }))
+func WithNPlusOneDetector(threshold int, handler NPlusOneHandler) Option {
+	return core.WithNPlusOneDetector(threshold, handler)
+}
+
+// WithQueryLint checks queries before execution for UPDATE and DELETE
+// without WHERE, SELECT * with joins, LIKE patterns starting with a wildcard
+// and functions applied to columns in WHERE comparisons. Findings are
+// reported once per statement to policy.Handler (stderr if nil), or stop the
+// query with ErrLintViolation when their rule is in policy.Block.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithQueryLint(relica.LintPolicy{
+//	        Block:          []relica.LintRule{relica.LintWriteWithoutWhere},
+//	        IndexedColumns: []string{"email", "created_at"},
+//	    }))
+func WithQueryLint(policy LintPolicy) Option { return core.WithQueryLint(policy) }
+
+// WithOptimizerStore records query fingerprints, call counts, latencies and
+// optimizer and N+1 suggestions, and persists them in the tables
+// <table>_queries and <table>_suggestions (DefaultOptimizerTable if table is
+// ""), so the history survives restarts. Create the tables once with
+// DB.CreateOptimizerStore and flush with DB.FlushOptimizerStore.
+func WithOptimizerStore(table string) Option { return core.WithOptimizerStore(table) }
+
+// WithAuditTable audits operations at level into a relica-managed table of the
+// database (DefaultAuditTable if table is ""). Events are buffered and
+// inserted in batches in the background; Close flushes what is left. Create
+// the table once with DB.CreateAuditTable and read it as AuditRecord rows.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn,
+//	    relica.WithAuditTable("", relica.AuditWrites,
+//	        relica.AuditTableRetention(90*24*time.Hour)))
+//	...
+//	ctx = relica.WithAuditUser(ctx, "alice@example.com")
+//	_, err = db.ExecContext(ctx, "UPDATE accounts SET plan = ? WHERE id = ?", "pro", 7)
+func WithAuditTable(table string, level AuditLevel, opts ...AuditTableOption) Option {
+	return core.WithAuditTable(table, level, opts...)
+}
+
+// AuditTableOption configures WithAuditTable.
+type AuditTableOption = core.AuditTableOption
+
+// AuditTableBatchSize sets the number of events inserted per statement; a full
+// batch is written without waiting for the flush interval. Default 100.
+func AuditTableBatchSize(n int) AuditTableOption { return core.AuditTableBatchSize(n) }
+
+// AuditTableFlushInterval sets how often buffered events are written. Default 1s.
+func AuditTableFlushInterval(d time.Duration) AuditTableOption {
+	return core.AuditTableFlushInterval(d)
+}
+
+// AuditTableBufferSize sets the maximum number of events waiting to be
+// written; further events are dropped with a logged warning. Default 10000.
+func AuditTableBufferSize(n int) AuditTableOption { return core.AuditTableBufferSize(n) }
+
+// AuditTableRetention deletes audit records older than d once an hour.
+// Zero, the default, keeps them forever.
+func AuditTableRetention(d time.Duration) AuditTableOption { return core.AuditTableRetention(d) }
+
+// AuditTableLogger also logs the audit events to logger.
+func AuditTableLogger(logger *slog.Logger) AuditTableOption { return core.AuditTableLogger(logger) }
+
+// AuditLevel selects the operations that are audited (see WithAuditTable).
+type AuditLevel = security.AuditLevel
+
+const (
+	// AuditNone disables auditing.
+	AuditNone = security.AuditNone
+	// AuditWrites audits write operations (INSERT, UPDATE, DELETE, UPSERT, TRUNCATE)
+	// and administrative commands (ANALYZE, VACUUM, OPTIMIZE, CANCEL).
+	AuditWrites = security.AuditWrites
+	// AuditReads audits reads (SELECT) as well as writes.
+	AuditReads = security.AuditReads
+	// AuditAll audits all operations, including utility statements.
+	AuditAll = security.AuditAll
+)
+
+// WithAuditUser returns a context whose queries are audited as run by user.
+func WithAuditUser(ctx context.Context, user string) context.Context {
+	return security.WithUser(ctx, user)
+}
+
+// WithAuditClientIP returns a context whose queries are audited with the
+// client IP address clientIP.
+func WithAuditClientIP(ctx context.Context, clientIP string) context.Context {
+	return security.WithClientIP(ctx, clientIP)
+}
+
+// WithAuditRequestID returns a context whose queries are audited with the
+// request ID requestID.
+func WithAuditRequestID(ctx context.Context, requestID string) context.Context {
+	return security.WithRequestID(ctx, requestID)
+}
+
+// WithValidator checks DB.ExecContext, QueryContext and QueryRowContext
+// queries and their parameters for SQL injection patterns and the given
+// policies before they run. Rejected queries fail, and are logged as security
+// events when auditing is enabled; with ValidatorReportOnly they are logged
+// as warnings and run anyway.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn,
+//	    relica.WithValidator(
+//	        relica.ValidatorDenyLiterals(),
+//	        relica.ValidatorMaxParams(1000),
+//	        relica.ValidatorDenyTables("secrets", "pg_shadow"),
+//	    ))
+func WithValidator(opts ...ValidatorOption) Option {
+	return core.WithValidator(security.NewValidator(opts...))
+}
+
+// ValidatorOption configures WithValidator.
+type ValidatorOption = security.ValidatorOption
+
+// ValidatorStrict also rejects patterns that are usually but not always
+// malicious, such as OR conditions.
+func ValidatorStrict() ValidatorOption { return security.WithStrict(true) }
+
+// ValidatorDenyLiterals rejects queries comparing against string literals in
+// their WHERE clause, a sign of values concatenated into the SQL instead of
+// passed as parameters. The check is a heuristic.
+func ValidatorDenyLiterals() ValidatorOption { return security.WithDenyLiterals(true) }
+
+// ValidatorMaxParams rejects queries with more than n parameters.
+func ValidatorMaxParams(n int) ValidatorOption { return security.WithMaxParams(n) }
+
+// ValidatorAllowTables rejects queries referencing tables other than tables.
+// Names are matched case-insensitively; a schema-qualified reference also
+// matches the unqualified name.
+func ValidatorAllowTables(tables ...string) ValidatorOption {
+	return security.WithAllowedTables(tables...)
+}
+
+// ValidatorDenyTables rejects queries referencing any of tables.
+func ValidatorDenyTables(tables ...string) ValidatorOption {
+	return security.WithDeniedTables(tables...)
+}
+
+// ValidatorReportOnly logs violations as warnings (and as security events
+// when auditing is enabled) without rejecting the query, to try out a policy.
+func ValidatorReportOnly() ValidatorOption { return security.WithReportOnly(true) }
+
+// WithQueryScope returns a context starting a new query scope, the unit of work
+// (an HTTP request, a job) in which WithNPlusOneDetector counts repeated queries.
+//
+// Example:
+//
+//	next.ServeHTTP(w, r.WithContext(relica.WithQueryScope(r.Context())))
+func WithQueryScope(ctx context.Context) context.Context {
+	return core.WithQueryScope(ctx)
+}
+
 // WithSensitiveFields sets the list of sensitive field names for parameter masking.
 // If not set, default sensitive field patterns are used.
 //
@@ -2708,6 +5509,196 @@ func WithQueryHook(hook QueryHook) Option { return core.WithQueryHook(hook) }
 //	    relica.WithSensitiveFields([]string{"password", "token", "api_key"}))
 func WithSensitiveFields(fields []string) Option { return core.WithSensitiveFields(fields) }
 
+// WithUTCTimes converts time.Time, *time.Time and sql.NullTime query parameters
+// to UTC before execution, so times are bound consistently regardless of the
+// zone they were created in.
+//
+// Example:
+//
+//	db, err := relica.Open("mysql", dsn, relica.WithUTCTimes())
+func WithUTCTimes() Option { return core.WithUTCTimes() }
+
+// WithEmptySlices makes All and Column set a nil destination slice to an
+// empty slice when no rows match, so results encode as [] rather than null
+// in JSON.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithEmptySlices())
+func WithEmptySlices() Option { return core.WithEmptySlices() }
+
+// ColumnNulls selects what Column does with a NULL scanned into an element
+// type that cannot hold it, such as []string or []int (see WithColumnNulls).
+type ColumnNulls = core.ColumnNulls
+
+// NULL handling modes for WithColumnNulls.
+const (
+	// ColumnNullsError fails the scan (the default).
+	ColumnNullsError = core.ColumnNullsError
+
+	// ColumnNullsSkip leaves rows with a NULL out of the slice.
+	ColumnNullsSkip = core.ColumnNullsSkip
+
+	// ColumnNullsZero stores the zero value of the element type.
+	ColumnNullsZero = core.ColumnNullsZero
+)
+
+// WithColumnNulls sets how Column handles NULLs scanned into element types
+// that cannot hold them. Pointer and sql.Null* element types ([]*string,
+// []sql.NullString) always receive NULLs as such.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithColumnNulls(relica.ColumnNullsSkip))
+func WithColumnNulls(mode ColumnNulls) Option { return core.WithColumnNulls(mode) }
+
+// WithSafeWrites makes UPDATE and DELETE statements without a WHERE
+// condition fail with ErrMissingWhere before any SQL is sent, including a
+// Where with an empty expression. Call AllowFullTableWrite on a query that
+// is meant to change every row.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithSafeWrites())
+//
+//	_, err = db.Delete("sessions").Where(relica.HashExp(filters)).Execute()
+//	// ErrMissingWhere if filters is empty
+func WithSafeWrites() Option { return core.WithSafeWrites() }
+
+// WithReadOnly makes the DB a read-only handle for reporting: any statement
+// other than a SELECT, built or executed through it or its transactions,
+// fails with ErrReadOnly before it is sent, whatever the database user is
+// allowed to do. Row locks and data-modifying WITH queries are rejected too.
+//
+// Example:
+//
+//	reports, err := relica.Open("postgres", dsn, relica.WithReadOnly())
+//
+//	_, err = reports.Delete("users").Where(relica.Eq("id", 1)).Execute()
+//	// errors.Is(err, relica.ErrReadOnly)
+func WithReadOnly() Option { return core.WithReadOnly() }
+
+// WithBoundLimits makes every SELECT built through the DB send its LIMIT and
+// OFFSET values as parameters, as SelectQuery.BindLimits does for one query,
+// so paginated queries reuse one prepared statement instead of filling the
+// statement cache with one entry per page.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithBoundLimits())
+func WithBoundLimits() Option { return core.WithBoundLimits() }
+
+// WithQueryWatchdog cancels queries issued through the DB that run longer
+// than maxRuntime, even when the caller's context has no deadline; a sooner
+// caller deadline still applies. Canceled queries fail with ErrQueryWatchdog
+// and are logged as warnings. Rows returned by QueryContext are not bounded.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithQueryWatchdog(30*time.Second))
+func WithQueryWatchdog(maxRuntime time.Duration) Option { return core.WithQueryWatchdog(maxRuntime) }
+
+// WithModelValidator validates models with v before Model().Insert, Update,
+// UpdateChanged and Upsert build any SQL. Models with a Validate() error
+// method are validated by it as well, with or without this option.
+//
+// Example:
+//
+//	type User struct {
+//	    ID    int64  `db:"id"`
+//	    Name  string `db:"name" validate:"required,max=100"`
+//	    Email string `db:"email" validate:"required,email"`
+//	}
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithModelValidator(relica.ValidateTags))
+func WithModelValidator(v ModelValidator) Option { return core.WithModelValidator(v) }
+
+// WithStrictSchema makes builder queries check that the tables and columns
+// they reference exist before sending SQL, returning ErrUnknownTable or
+// ErrUnknownColumn (with a "did you mean" hint for near misses) instead of a
+// driver error. The columns of each table are read once from the database
+// catalog and cached; DDL run through relica refreshes the cache.
+//
+// FROM and JOIN tables, plain SELECT columns, and the tables and columns of
+// INSERT, UPDATE, UPSERT, DELETE, TRUNCATE and batch statements are checked.
+// WHERE strings, expressions and raw SQL are not.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithStrictSchema())
+func WithStrictSchema() Option { return core.WithStrictSchema() }
+
+// ModelValidator validates a model before Model writes (see WithModelValidator).
+type ModelValidator = core.ModelValidator
+
+// ValidateTags is a ModelValidator checking validate struct tags: required,
+// min=N and max=N (string length, collection size or number value), and email.
+// Failures are returned as a *ValidationError.
+func ValidateTags(ctx context.Context, model interface{}) error { return core.ValidateTags(ctx, model) }
+
+// ValidationError is returned when a model fails validation, with one
+// FieldError per invalid field.
+type ValidationError = core.ValidationError
+
+// FieldError is the validation failure of one field.
+type FieldError = core.FieldError
+
+// Seed generates n rows for model from its struct fields and bulk-inserts them
+// into the model's table inside one transaction. It is meant for load testing
+// and local development data.
+//
+// Values are chosen from the field type and column name (email, first_name,
+// city, created_at, ...), or from a seed tag:
+//
+//	type User struct {
+//	    ID    int64  `db:"id"`                          // left to the database
+//	    Email string `db:"email"`                       // unique fake email
+//	    Role  string `db:"role" seed:"oneof:admin|member"`
+//	    Age   int    `db:"age" seed:"range:18,90"`
+//	    Notes string `db:"notes" seed:"-"`              // database default
+//	}
+//
+// Use WithFaker to plug in a fake data library, and WithRandSeed for
+// reproducible rows. The DB's context (see WithContext) is used.
+//
+// Example:
+//
+//	err := relica.Seed(db, &User{}, 1000, relica.WithRandSeed(42))
+func Seed(db *DB, model interface{}, n int, opts ...SeedOption) error {
+	return core.Seed(db.db, model, n, opts...)
+}
+
+// SeedOption configures Seed.
+type SeedOption = core.SeedOption
+
+// SeedField describes the column a Faker generates a value for.
+type SeedField = core.SeedField
+
+// Faker generates Seed values. Fake returns ok=false to fall back to the
+// built-in generator for the field.
+type Faker = core.Faker
+
+// FakerFunc adapts a function to the Faker interface.
+type FakerFunc = core.FakerFunc
+
+// WithFaker sets a Faker consulted before Seed's built-in generators.
+//
+// Example:
+//
+//	relica.WithFaker(relica.FakerFunc(func(f relica.SeedField) (interface{}, bool) {
+//	    if f.Column == "sku" {
+//	        return fmt.Sprintf("SKU-%05d", f.Row), true
+//	    }
+//	    return nil, false
+//	}))
+func WithFaker(f Faker) SeedOption { return core.WithFaker(f) }
+
+// WithSeedBatchSize sets the maximum number of rows per INSERT statement used by Seed.
+func WithSeedBatchSize(n int) SeedOption { return core.WithSeedBatchSize(n) }
+
+// WithRandSeed makes the rows generated by Seed reproducible.
+func WithRandSeed(seed uint64) SeedOption { return core.WithRandSeed(seed) }
+
 // Logger defines the logging interface for Relica.
 // Implementations should handle structured logging with key-value pairs.
 type Logger = logger.Logger
@@ -2729,6 +5720,87 @@ type QueryEvent = core.QueryEvent
 // Use this for logging, metrics, distributed tracing, or debugging.
 type QueryHook = core.QueryHook
 
+// ChangeEvent describes the rows changed by one write statement (see WithChangeSink).
+type ChangeEvent = core.ChangeEvent
+
+// ChangeSink receives a ChangeEvent for every successful write statement.
+type ChangeSink = core.ChangeSink
+
+// NPlusOneWarning describes a burst of same-shape queries within one query scope.
+type NPlusOneWarning = core.NPlusOneWarning
+
+// NPlusOneHandler receives the warnings of WithNPlusOneDetector.
+type NPlusOneHandler = core.NPlusOneHandler
+
+// LintPolicy configures query linting (see WithQueryLint).
+type LintPolicy = core.LintPolicy
+
+// LintRule identifies a query lint check.
+type LintRule = core.LintRule
+
+// Query lint rules (see WithQueryLint).
+const (
+	// LintWriteWithoutWhere finds UPDATE and DELETE statements without WHERE.
+	LintWriteWithoutWhere = core.LintWriteWithoutWhere
+	// LintSelectStarJoin finds SELECT * over a JOIN.
+	LintSelectStarJoin = core.LintSelectStarJoin
+	// LintLeadingWildcard finds LIKE patterns starting with % or _.
+	LintLeadingWildcard = core.LintLeadingWildcard
+	// LintNonSargable finds functions applied to a column in a WHERE comparison.
+	LintNonSargable = core.LintNonSargable
+)
+
+// LintWarning is a lint finding in a query.
+type LintWarning = core.LintWarning
+
+// LintHandler receives the warnings of WithQueryLint.
+type LintHandler = core.LintHandler
+
+// Tracer starts spans for queries and transactions (see WithTracer). Relica
+// has no tracing dependency; implement Tracer on top of your tracing library.
+//
+// Example (OpenTelemetry):
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) StartSpan(ctx context.Context, name string) (context.Context, relica.Span) {
+//	    ctx, span := o.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//	    return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ s trace.Span }
+//
+//	func (o otelSpan) SetAttribute(key string, value any) { o.s.SetAttributes(attribute.String(key, fmt.Sprint(value))) }
+//	func (o otelSpan) RecordError(err error) { o.s.RecordError(err); o.s.SetStatus(codes.Error, err.Error()) }
+//	func (o otelSpan) End()                  { o.s.End() }
+type Tracer = core.Tracer
+
+// TimestampTracer is a Tracer that can start a span at a past time, so the
+// spans of sampled-out queries kept for an error or slowness (see
+// TraceSampleRate) cover the whole query. With OpenTelemetry, pass
+// trace.WithTimestamp(start) to Start.
+type TimestampTracer = core.TimestampTracer
+
+// Span is a span started by a Tracer.
+type Span = core.Span
+
+// MeterProvider provides the Meter relica records metrics with (see
+// WithMeterProvider). Relica has no metrics dependency; implement it on top
+// of your metrics library.
+type MeterProvider = core.MeterProvider
+
+// Meter creates the instruments relica records metrics with.
+type Meter = core.Meter
+
+// Float64Histogram records a distribution of values.
+type Float64Histogram = core.Float64Histogram
+
+// Int64Counter records increments of a sum.
+type Int64Counter = core.Int64Counter
+
+// Attribute is a metric attribute.
+type Attribute = core.Attribute
+
 // Params represents named parameter values for query binding.
 // Named parameters are specified in SQL using {:name} syntax.
 //
@@ -2739,9 +5811,30 @@ type QueryHook = core.QueryHook
 //	    All(&users)
 type Params = core.Params
 
-// DetectOperation detects the SQL operation type (SELECT, INSERT, UPDATE, DELETE, UNKNOWN).
+// DetectOperation detects the SQL operation type (SELECT, INSERT, UPDATE, DELETE, MERGE, UNKNOWN).
 func DetectOperation(query string) string { return core.DetectOperation(query) }
 
+// Fingerprint returns a stable identifier of the logical query of sql, the
+// hexadecimal hash of NormalizeSQL(sql). Executions of a query with other
+// values, IN list sizes, comments or formatting share it. Slow query logs,
+// query metrics and the optimizer group statements by it.
+//
+// Example:
+//
+//	relica.Fingerprint("SELECT * FROM users WHERE id IN (1, 2, 3)") ==
+//	    relica.Fingerprint("select * from users where id in ($1, $2)") // true
+func Fingerprint(sql string) string { return core.Fingerprint(sql) }
+
+// NormalizeSQL returns sql with literals and placeholders replaced by ?, IN
+// lists and multi-row VALUES collapsed, comments removed, and whitespace and
+// case folded: the text Fingerprint hashes.
+//
+// Example:
+//
+//	relica.NormalizeSQL("SELECT * FROM users WHERE name = 'ann' AND id IN (?, ?)")
+//	// "select * from users where name = ? and id in (?)"
+func NormalizeSQL(sql string) string { return core.NormalizeSQL(sql) }
+
 // NullStringMap represents a map of nullable string values scanned from database rows.
 // Each value is a sql.NullString that can be checked for NULL.
 // This type is useful for dynamic queries where the schema is not known at compile time.
@@ -2760,6 +5853,110 @@ func DetectOperation(query string) string { return core.DetectOperation(query) }
 //	db.Select("*").From("users").All(&results)
 type NullStringMap = core.NullStringMap
 
+// CSVOptions configures SelectQuery.ExportCSV. The zero value writes a header
+// row, comma-separated fields, empty strings for NULL and RFC 3339 timestamps.
+//
+// Example:
+//
+//	err := db.Select().From("orders").ExportCSV(w, &relica.CSVOptions{Comma: ';', Null: "NULL"})
+type CSVOptions = core.CSVOptions
+
+// ImportOptions configures DB.ImportCSV.
+type ImportOptions = core.ImportOptions
+
+// ImportResult summarizes a DB.ImportCSV run.
+type ImportResult = core.ImportResult
+
+// ImportError describes a CSV row that DB.ImportCSV could not import.
+type ImportError = core.ImportError
+
+// CoerceFunc converts a CSV field to the value inserted into a column
+// (see ImportOptions.Coerce).
+type CoerceFunc = core.CoerceFunc
+
+// CoerceInt parses a CSV field as a base-10 integer.
+func CoerceInt(value string) (interface{}, error) { return core.CoerceInt(value) }
+
+// CoerceFloat parses a CSV field as a floating-point number.
+func CoerceFloat(value string) (interface{}, error) { return core.CoerceFloat(value) }
+
+// CoerceBool parses a CSV field as a boolean, accepting yes/no, y/n and on/off
+// in addition to the strconv.ParseBool forms.
+func CoerceBool(value string) (interface{}, error) { return core.CoerceBool(value) }
+
+// CoerceTime returns a CoerceFunc that parses a CSV field with the first
+// matching layout (default: RFC 3339, "2006-01-02 15:04:05", "2006-01-02").
+func CoerceTime(layouts ...string) CoerceFunc { return core.CoerceTime(layouts...) }
+
+// HistoryTableSuffix is appended to a table name to form its history table.
+const HistoryTableSuffix = core.HistoryTableSuffix
+
+// DefaultOutboxTable is the outbox table used unless another is set with Table.
+const DefaultOutboxTable = core.DefaultOutboxTable
+
+// CompensateFunc undoes the committed changes of a coordinated transaction
+// participant (see CoordinatedTx.OnCompensate).
+type CompensateFunc = core.CompensateFunc
+
+// Outbox publishes messages within a transaction (see Tx.Outbox).
+type Outbox = core.Outbox
+
+// OutboxMessage is a message stored in the outbox table.
+type OutboxMessage = core.OutboxMessage
+
+// OutboxHandler delivers an outbox message; returning an error keeps the
+// message for a later poll.
+type OutboxHandler = core.OutboxHandler
+
+// OutboxRelay dispatches outbox messages to a handler (see DB.OutboxRelay).
+type OutboxRelay = core.OutboxRelay
+
+// AdvisoryLock is an acquired advisory lock (see DB.AdvisoryLock). Release it with Unlock.
+type AdvisoryLock = core.AdvisoryLock
+
+// DefaultOptimizerTable is the default name prefix of the optimizer store tables.
+const DefaultOptimizerTable = core.DefaultOptimizerTable
+
+// DefaultAuditTable is the default name of the audit log table (see WithAuditTable).
+const DefaultAuditTable = core.DefaultAuditTable
+
+// AuditRecord is a row of the audit log table (see WithAuditTable).
+type AuditRecord = core.AuditRecord
+
+// QueryProfile is the persisted history of one query fingerprint (see DB.QueryProfiles).
+type QueryProfile = core.QueryProfile
+
+// StoredSuggestion is a suggestion recorded by the optimizer store (see DB.OptimizerSuggestions).
+type StoredSuggestion = core.StoredSuggestion
+
+// SuggestionStatus is the review state of a stored suggestion.
+type SuggestionStatus = core.SuggestionStatus
+
+// Review states of stored suggestions.
+const (
+	SuggestionOpen      = core.SuggestionOpen
+	SuggestionAccepted  = core.SuggestionAccepted
+	SuggestionDismissed = core.SuggestionDismissed
+)
+
+// NewULID returns a new ULID, a 26-character, time-ordered unique ID. Model().Insert()
+// fills zero string fields tagged relica:"ulid" with it.
+func NewULID() string { return core.NewULID() }
+
+// NewUUID returns a random (version 4) UUID in canonical form. Model().Insert()
+// fills zero fields tagged relica:"uuid" with it.
+func NewUUID() string { return core.NewUUID() }
+
+// NewUUIDv7 returns a time-ordered (version 7) UUID in canonical form.
+// Model().Insert() fills zero fields tagged relica:"uuidv7" with it.
+func NewUUIDv7() string { return core.NewUUIDv7() }
+
+// Snowflake generates 63-bit, time-ordered integer IDs (see NewSnowflake).
+type Snowflake = core.Snowflake
+
+// NewSnowflake returns a Snowflake ID generator for node, which must be in [0, 1023].
+func NewSnowflake(node int64) (*Snowflake, error) { return core.NewSnowflake(node) }
+
 // ============================================================================
 // Re-export expression builders
 // ============================================================================
@@ -2767,6 +5964,28 @@ type NullStringMap = core.NullStringMap
 // NewExp creates a new raw SQL expression.
 func NewExp(rawSQL string, args ...interface{}) Expression { return core.NewExp(rawSQL, args...) }
 
+// RawOrder returns a raw ORDER BY or GROUP BY term for OrderBySub and
+// GroupBySub, emitted as-is without quoting; ? placeholders are bound to args.
+//
+// Example:
+//
+//	db.Select().From("users").OrderBySub(relica.RawOrder("LOWER(name) DESC"))
+func RawOrder(rawSQL string, args ...interface{}) Expression { return core.RawOrder(rawSQL, args...) }
+
+// ConditionBuilder is an immutable, reusable WHERE condition, applied to
+// queries with WhereCond (see NewCondition).
+type ConditionBuilder = core.ConditionBuilder
+
+// NewCondition returns a condition builder combining exps with AND; extend it
+// with And and Or, which accept the same conditions as Where.
+//
+// Example:
+//
+//	cb := relica.NewCondition(relica.Eq("tenant_id", tenantID)).And("status = ?", "active")
+//	db.Select().From("accounts").WhereCond(cb).All(&accounts)
+//	db.Delete("accounts").WhereCond(cb.And("expired = ?", true)).Execute()
+func NewCondition(exps ...Expression) *ConditionBuilder { return core.NewCondition(exps...) }
+
 // Eq creates an equality expression (column = value).
 func Eq(col string, value interface{}) Expression { return core.Eq(col, value) }
 
@@ -2805,12 +6024,48 @@ func GreaterOrEqual(col string, value interface{}) Expression {
 // LessOrEqual creates a less-or-equal expression (column <= value).
 func LessOrEqual(col string, value interface{}) Expression { return core.LessOrEqual(col, value) }
 
+// IsDistinctFrom creates a NULL-safe inequality expression
+// (column IS DISTINCT FROM value; NOT (column <=> value) on MySQL).
+func IsDistinctFrom(col string, value interface{}) Expression {
+	return core.IsDistinctFrom(col, value)
+}
+
+// IsNotDistinctFrom creates a NULL-safe equality expression
+// (column IS NOT DISTINCT FROM value; column <=> value on MySQL).
+func IsNotDistinctFrom(col string, value interface{}) Expression {
+	return core.IsNotDistinctFrom(col, value)
+}
+
 // In creates an IN expression (column IN (values...)).
 func In(col string, values ...interface{}) Expression { return core.In(col, values...) }
 
 // NotIn creates a NOT IN expression (column NOT IN (values...)).
 func NotIn(col string, values ...interface{}) Expression { return core.NotIn(col, values...) }
 
+// InEnum creates an IN expression for typed enum values (see RegisterEnum).
+func InEnum[E EnumValue](col string, values ...E) Expression { return core.InEnum(col, values...) }
+
+// EnumValue is the set of underlying types an enum type may have.
+type EnumValue = core.EnumValue
+
+// Enum is the set of allowed values of the enum type E.
+type Enum[E EnumValue] = core.Enum[E]
+
+// RegisterEnum declares the allowed values of E. Query parameters of type E
+// are validated before execution and fail with ErrInvalidEnumValue.
+//
+// Example:
+//
+//	type Status string
+//
+//	const (
+//	    StatusActive Status = "active"
+//	    StatusBanned Status = "banned"
+//	)
+//
+//	var Statuses = relica.RegisterEnum(StatusActive, StatusBanned)
+func RegisterEnum[E EnumValue](values ...E) *Enum[E] { return core.RegisterEnum(values...) }
+
 // Between creates a BETWEEN expression (column BETWEEN low AND high).
 func Between(col string, from, to interface{}) Expression { return core.Between(col, from, to) }
 
@@ -2846,12 +6101,132 @@ func Exists(exp Expression) Expression { return core.Exists(exp) }
 // NotExists creates a NOT EXISTS subquery expression.
 func NotExists(exp Expression) Expression { return core.NotExists(exp) }
 
+// ScalarSubqueryExp is a single-value subquery for SELECT lists and comparisons.
+type ScalarSubqueryExp = core.ScalarSubqueryExp
+
+// ScalarSubquery wraps sub as a scalar subquery expression. Select it with
+// SelectExp(ScalarSubquery(sub).As("alias")) or compare against it with
+// GreaterThan("col", ScalarSubquery(sub)); its parameters are merged with
+// the enclosing query's.
+//
+// Example:
+//
+//	latest := db.Select("MAX(o.created_at)").From("orders o").
+//	    Where(relica.EqCol("o.user_id", "u.id"))
+//	db.Select("u.id", "u.name").
+//	    SelectExp(relica.ScalarSubquery(latest).As("latest_order_at")).
+//	    From("users u").All(&users)
+func ScalarSubquery(sub *SelectQuery) *ScalarSubqueryExp {
+	if sub == nil {
+		return core.ScalarSubquery(nil)
+	}
+	return core.ScalarSubquery(sub.sq)
+}
+
+// QuantifiedExp compares a column with the rows of a subquery using ANY or ALL.
+type QuantifiedExp = core.QuantifiedExp
+
+// EqAny creates a "column = ANY (subquery)" expression (IN on SQLite).
+//
+// Example:
+//
+//	vip := db.Select("user_id").From("vip_members").Where(relica.Eq("tier", "gold"))
+//	db.Select().From("orders").Where(relica.EqAny("user_id", vip)).All(&orders)
+func EqAny(col string, sub *SelectQuery) *QuantifiedExp { return core.EqAny(col, sub.Unwrap()) }
+
+// NotEqAny creates a "column <> ANY (subquery)" expression.
+func NotEqAny(col string, sub *SelectQuery) *QuantifiedExp { return core.NotEqAny(col, sub.Unwrap()) }
+
+// GtAny creates a "column > ANY (subquery)" expression.
+func GtAny(col string, sub *SelectQuery) *QuantifiedExp { return core.GtAny(col, sub.Unwrap()) }
+
+// GteAny creates a "column >= ANY (subquery)" expression.
+func GteAny(col string, sub *SelectQuery) *QuantifiedExp { return core.GteAny(col, sub.Unwrap()) }
+
+// LtAny creates a "column < ANY (subquery)" expression.
+func LtAny(col string, sub *SelectQuery) *QuantifiedExp { return core.LtAny(col, sub.Unwrap()) }
+
+// LteAny creates a "column <= ANY (subquery)" expression.
+func LteAny(col string, sub *SelectQuery) *QuantifiedExp { return core.LteAny(col, sub.Unwrap()) }
+
+// EqAll creates a "column = ALL (subquery)" expression.
+func EqAll(col string, sub *SelectQuery) *QuantifiedExp { return core.EqAll(col, sub.Unwrap()) }
+
+// NotEqAll creates a "column <> ALL (subquery)" expression (NOT IN on SQLite).
+func NotEqAll(col string, sub *SelectQuery) *QuantifiedExp { return core.NotEqAll(col, sub.Unwrap()) }
+
+// GtAll creates a "column > ALL (subquery)" expression.
+//
+// Example:
+//
+//	rivals := db.Select("score").From("players").Where(relica.Eq("team", "red"))
+//	db.Select("name").From("players").Where(relica.GtAll("score", rivals)).Column(&names)
+func GtAll(col string, sub *SelectQuery) *QuantifiedExp { return core.GtAll(col, sub.Unwrap()) }
+
+// GteAll creates a "column >= ALL (subquery)" expression.
+func GteAll(col string, sub *SelectQuery) *QuantifiedExp { return core.GteAll(col, sub.Unwrap()) }
+
+// LtAll creates a "column < ALL (subquery)" expression.
+func LtAll(col string, sub *SelectQuery) *QuantifiedExp { return core.LtAll(col, sub.Unwrap()) }
+
+// LteAll creates a "column <= ALL (subquery)" expression.
+func LteAll(col string, sub *SelectQuery) *QuantifiedExp { return core.LteAll(col, sub.Unwrap()) }
+
+// ============================================================================
+// Re-export CTE options
+// ============================================================================
+
+// CTEOption configures a Common Table Expression added via With or WithRecursive.
+type CTEOption = core.CTEOption
+
+// Materialized emits AS MATERIALIZED for the CTE (PostgreSQL 12+, SQLite 3.35+; dropped on MySQL).
+func Materialized() CTEOption { return core.Materialized() }
+
+// NotMaterialized emits AS NOT MATERIALIZED for the CTE (PostgreSQL 12+, SQLite 3.35+; dropped on MySQL).
+func NotMaterialized() CTEOption { return core.NotMaterialized() }
+
+// ============================================================================
+// Re-export safe sorting and filtering
+// ============================================================================
+
+// AllowedColumnSet is a whitelist of user-facing names mapped to columns.
+type AllowedColumnSet = core.AllowedColumnSet
+
+// FilterSpec maps validated user-supplied filter and sort parameters into WHERE/ORDER BY.
+type FilterSpec = core.FilterSpec
+
+// FilterOp is a filter operator accepted by FilterSpec.
+type FilterOp = core.FilterOp
+
+// Filter operators, written as a bracket suffix in query strings ("age[gte]=18").
+const (
+	FilterEq   = core.FilterEq
+	FilterNe   = core.FilterNe
+	FilterGt   = core.FilterGt
+	FilterGte  = core.FilterGte
+	FilterLt   = core.FilterLt
+	FilterLte  = core.FilterLte
+	FilterLike = core.FilterLike
+	FilterIn   = core.FilterIn
+)
+
+// AllowedColumns creates a sort/filter whitelist; each name maps to the column of the same name.
+// Use Alias to expose a column under a different public name.
+func AllowedColumns(columns ...string) *AllowedColumnSet { return core.AllowedColumns(columns...) }
+
+// NewFilterSpec creates an empty FilterSpec.
+func NewFilterSpec() *FilterSpec { return core.NewFilterSpec() }
+
 // ============================================================================
 // Re-export functional expressions (CASE, COALESCE, NULLIF, etc.)
 // ============================================================================
 
-// Case creates a simple CASE expression.
-func Case(column string) *CaseExp { return core.Case(column) }
+// Case creates a CASE expression.
+// With a column it creates a simple CASE; without arguments it creates a searched
+// CASE whose When conditions may be Expressions:
+//
+//	relica.Case().When(relica.Eq("status", 1), "active").Else("unknown").As("status_label")
+func Case(column ...string) *CaseExp { return core.Case(column...) }
 
 // CaseWhen creates a searched CASE expression (without column).
 func CaseWhen() *CaseExp { return core.CaseWhen() }
@@ -2871,12 +6246,109 @@ func Least(values ...interface{}) *GreatestLeastExp { return core.Least(values..
 // Concat creates a string concatenation expression.
 func Concat(values ...interface{}) *ConcatExp { return core.Concat(values...) }
 
+// Lower creates a LOWER(x) expression. A string argument is a column name.
+//
+//	db.Select().From("users").Where(relica.Lower("email").Eq(email))
+func Lower(arg interface{}) *FuncExp { return core.Lower(arg) }
+
+// Upper creates an UPPER(x) expression. A string argument is a column name.
+func Upper(arg interface{}) *FuncExp { return core.Upper(arg) }
+
+// Cast creates a CAST(x AS type) expression.
+// The type name is validated; MySQL integer/text types are mapped to SIGNED/CHAR.
+func Cast(arg interface{}, typeName string) *FuncExp { return core.Cast(arg, typeName) }
+
+// DateTrunc truncates a timestamp to the given unit (year, month, day, hour, minute, second).
+// Renders DATE_TRUNC on PostgreSQL, DATE_FORMAT on MySQL and strftime on SQLite.
+//
+//	db.Select().SelectExp(relica.DateTrunc("day", "created_at").As("day")).
+//	    From("orders").GroupBySub(relica.DateTrunc("day", "created_at"))
+func DateTrunc(unit string, arg interface{}) *FuncExp { return core.DateTrunc(unit, arg) }
+
+// Match creates a full-text search condition over comma-separated columns.
+// Renders to_tsvector @@ plainto_tsquery on PostgreSQL, MATCH ... AGAINST on
+// MySQL and FTS5 MATCH on SQLite. The search string is plain words, not query syntax.
+//
+//	db.Select().From("articles").
+//	    Where(relica.Match("title,body", q)).
+//	    OrderBySub(relica.MatchRank("title,body", q).Desc())
+func Match(columns, query string) *MatchExp { return core.Match(columns, query) }
+
+// MatchRank creates a full-text relevance expression (higher is more relevant)
+// for ORDER BY or SELECT lists. Renders ts_rank on PostgreSQL, MATCH ... AGAINST
+// on MySQL and the FTS5 rank on SQLite.
+func MatchRank(columns, query string) *MatchExp { return core.MatchRank(columns, query) }
+
+// STDWithin creates a condition that col is within meters of p
+// (ST_DWithin on PostGIS, PtDistWithin on SpatiaLite).
+//
+//	db.Select().From("shops").
+//	    Where(relica.STDWithin("location", relica.Point{Lng: 13.40, Lat: 52.52}, 500)).
+//	    OrderBySub(relica.STDistance("location", relica.Point{Lng: 13.40, Lat: 52.52}))
+func STDWithin(col string, p Point, meters float64) *GeoExp { return core.STDWithin(col, p, meters) }
+
+// STContains creates a condition that the geometry in col contains geom (a Point or BBox).
+func STContains(col string, geom interface{}) *GeoExp { return core.STContains(col, geom) }
+
+// STWithin creates a condition that the geometry in col lies within geom (a Point or BBox).
+func STWithin(col string, geom interface{}) *GeoExp { return core.STWithin(col, geom) }
+
+// STIntersects creates a condition that the geometry in col intersects geom (a Point or BBox).
+func STIntersects(col string, geom interface{}) *GeoExp { return core.STIntersects(col, geom) }
+
+// STDistance creates the distance in meters between col and p, for ORDER BY or SELECT lists.
+func STDistance(col string, p Point) *GeoExp { return core.STDistance(col, p) }
+
+// Today creates a condition matching the current calendar day (in time.Local,
+// or the location set with In): col >= midnight AND col < next midnight.
+//
+// Example:
+//
+//	db.Builder().Select().From("orders").Where(relica.Today("created_at"))
+func Today(col string) *TimeRangeExp { return core.Today(col) }
+
+// WithinLast creates a condition matching the last d up to now: col >= now - d.
+//
+// Example:
+//
+//	db.Builder().Select().From("events").Where(relica.WithinLast("created_at", 24*time.Hour))
+func WithinLast(col string, d time.Duration) *TimeRangeExp { return core.WithinLast(col, d) }
+
+// DateBetween creates a condition matching the calendar days from through to,
+// both inclusive. If to is before from, the condition matches nothing.
+//
+// Example:
+//
+//	relica.DateBetween("paid_at", monthStart, monthEnd).In(time.UTC)
+func DateBetween(col string, from, to time.Time) *TimeRangeExp {
+	return core.DateBetween(col, from, to)
+}
+
 // CaseExp represents a SQL CASE expression.
 type CaseExp = core.CaseExp
 
 // CoalesceExp represents a SQL COALESCE expression.
 type CoalesceExp = core.CoalesceExp
 
+// FuncExp represents a scalar SQL function (LOWER, UPPER, CAST, DATE_TRUNC).
+type FuncExp = core.FuncExp
+
+// MatchExp represents a full-text search condition or relevance expression.
+type MatchExp = core.MatchExp
+
+// GeoExp represents a geospatial predicate or distance expression (PostGIS, SpatiaLite).
+type GeoExp = core.GeoExp
+
+// Point is a WGS 84 longitude/latitude position. It scans point geometry
+// columns returned as WKB, hex EWKB, (E)WKT or SpatiaLite blobs.
+type Point = core.Point
+
+// BBox is a WGS 84 bounding box. It scans the extent of any geometry column.
+type BBox = core.BBox
+
+// TimeRangeExp represents a half-open time range condition (Today, WithinLast, DateBetween).
+type TimeRangeExp = core.TimeRangeExp
+
 // NullIfExp represents a SQL NULLIF expression.
 type NullIfExp = core.NullIfExp
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/coregx/relica"
@@ -161,6 +162,107 @@ func TestDB_PinQuery_UnpinQuery(t *testing.T) {
 	})
 }
 
+// ============================================================================
+// DB.StmtCacheStats() / DB.StmtCacheEntries()
+// ============================================================================
+
+func TestDB_StmtCacheStats(t *testing.T) {
+	db := newCoverageTestDB(t)
+	setupCoverageTable(t, db)
+
+	query := `SELECT * FROM cover_users WHERE id = ?`
+	_, err := db.WarmCache([]string{query})
+	require.NoError(t, err)
+	db.PinQuery(query)
+
+	// Builder queries go through the cache: first run misses, second hits.
+	for i := 0; i < 2; i++ {
+		var count int
+		require.NoError(t, db.NewQuery(`SELECT COUNT(*) FROM cover_users`).Row(&count))
+	}
+
+	stats := db.StmtCacheStats()
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, 1, stats.Pinned)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, 0.5, stats.HitRate)
+	assert.Positive(t, stats.Capacity)
+
+	entries := db.StmtCacheEntries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, `SELECT COUNT(*) FROM cover_users`, entries[0].SQL)
+	assert.Equal(t, uint64(1), entries[0].Hits)
+	assert.Equal(t, query, entries[1].SQL)
+	assert.True(t, entries[1].Pinned)
+	assert.False(t, entries[1].LastUsed.IsZero())
+}
+
+func TestDB_InvalidateStatements(t *testing.T) {
+	db := newCoverageTestDB(t)
+	setupCoverageTable(t, db)
+
+	query := `SELECT * FROM cover_users WHERE id = ?`
+	_, err := db.WarmCache([]string{query, `SELECT 1`})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, db.InvalidateStatements("cover_users"))
+	assert.Equal(t, 1, db.StmtCacheStats().Size)
+
+	// DDL through relica invalidates automatically.
+	_, err = db.WarmCache([]string{query})
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), `ALTER TABLE cover_users ADD COLUMN age INTEGER`)
+	require.NoError(t, err)
+
+	entries := db.StmtCacheEntries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, `SELECT 1`, entries[0].SQL)
+}
+
+// ============================================================================
+// DB.Pool() / OnPool()
+// ============================================================================
+
+func TestDB_Pool_OnPool(t *testing.T) {
+	db, err := relica.Open("sqlite", filepath.Join(t.TempDir(), "pool.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	setupCoverageTable(t, db)
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `INSERT INTO cover_users (name, email, status) VALUES ('Alice', 'alice@pool.com', 'active')`)
+	require.NoError(t, err)
+
+	reporting, err := db.Pool("reporting")
+	require.NoError(t, err)
+	reporting.SetMaxOpenConns(2)
+	assert.Equal(t, 2, reporting.Stats().MaxOpenConnections)
+
+	var count int
+	err = db.Builder().Select("COUNT(*)").From("cover_users").OnPool("reporting").Row(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var name string
+	err = db.NewQuery(`SELECT name FROM cover_users WHERE email = ?`).Bind("alice@pool.com").OnPool("reporting").Row(&name)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", name)
+
+	var users []struct {
+		Name string `db:"name"`
+	}
+	err = db.Builder().OnPool("reporting").Select("name").From("cover_users").All(&users)
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	// Nothing was prepared on the primary pool.
+	assert.Equal(t, 0, db.StmtCacheStats().Size)
+
+	err = db.Builder().Select("COUNT(*)").From("cover_users").OnPool("missing").Row(&count)
+	assert.ErrorContains(t, err, "unknown connection pool")
+}
+
 // ============================================================================
 // DB.NewQuery()
 // ============================================================================
@@ -1242,3 +1344,62 @@ func TestModelQuery_ExcludeChain(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+// ============================================================================
+// WhereInChunked / ModelQuery.FindByIDs
+// ============================================================================
+
+func TestDB_FindByIDs_WhereInChunked(t *testing.T) {
+	db := newCoverageTestDB(t)
+	setupCoverageTable(t, db)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, db.Model(&coverageUserInsert{Name: name, Status: "active"}).Table("cover_users").Insert())
+	}
+
+	var users []coverageUser
+	require.NoError(t, db.Model(&coverageUser{}).FindByIDs([]int{4, 2, 5}, &users))
+	require.Len(t, users, 3)
+	assert.Equal(t, []string{"d", "b", "e"}, []string{users[0].Name, users[1].Name, users[2].Name})
+
+	var names []string
+	err := db.Builder().Select("name").From("cover_users").
+		WhereInChunked("id", []int{1, 2, 3, 4, 5}, 2).
+		OrderBy("name").
+		Column(&names)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, names)
+
+	users = nil
+	err = db.Builder().Select().From("cover_users").
+		WhereInChunked("id", []int{5, 1, 3}, 1).
+		PreserveOrder().
+		All(&users)
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	assert.Equal(t, 5, users[0].ID)
+	assert.Equal(t, 3, users[2].ID)
+}
+
+// ============================================================================
+// ModelQuery.Find / Match
+// ============================================================================
+
+func TestDB_ModelFind(t *testing.T) {
+	db := newCoverageTestDB(t)
+	setupCoverageTable(t, db)
+	for _, u := range []coverageUserInsert{
+		{Name: "Ann", Email: "ann@example.com", Status: "active"},
+		{Name: "Andy", Email: "andy@example.com", Status: "inactive"},
+		{Name: "Bob", Email: "bob@example.com", Status: "active"},
+	} {
+		require.NoError(t, db.Model(&u).Table("cover_users").Insert())
+	}
+
+	var users []coverageUser
+	require.NoError(t, db.Model(&coverageUser{Status: "active"}).Find(&users))
+	assert.Len(t, users, 2)
+
+	var user coverageUser
+	require.NoError(t, db.Model(&coverageUser{Name: "An%", Status: "inactive"}).Match("name", "LIKE").Find(&user))
+	assert.Equal(t, "Andy", user.Name)
+}
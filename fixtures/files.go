@@ -0,0 +1,123 @@
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadFiles decodes the fixture files at paths (chosen by extension, see
+// WithDecoder) and loads them as one Set. Each file holds an object mapping
+// table names to lists of rows:
+//
+//	{"users": [{"id": 1, "name": "alice"}], "orders": [{"id": 10, "user_id": 1}]}
+func (l *Loader) LoadFiles(ctx context.Context, paths ...string) error {
+	set, err := l.ReadFS(osFS{}, paths...)
+	if err != nil {
+		return err
+	}
+	return l.Load(ctx, set)
+}
+
+// LoadFS is like LoadFiles but reads from fsys, e.g. an embed.FS.
+func (l *Loader) LoadFS(ctx context.Context, fsys fs.FS, paths ...string) error {
+	set, err := l.ReadFS(fsys, paths...)
+	if err != nil {
+		return err
+	}
+	return l.Load(ctx, set)
+}
+
+// ReadFS decodes the fixture files at paths in fsys into a single Set without
+// loading it. Rows of a table that appears in several files are concatenated
+// in file order.
+func (l *Loader) ReadFS(fsys fs.FS, paths ...string) (Set, error) {
+	set := make(Set)
+	for _, path := range paths {
+		decode := l.decoders[strings.ToLower(filepath.Ext(path))]
+		if decode == nil {
+			return nil, fmt.Errorf("fixtures: no decoder for %s (see WithDecoder)", path)
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: %w", err)
+		}
+
+		var doc map[string]interface{}
+		if err := decode(data, &doc); err != nil {
+			return nil, fmt.Errorf("fixtures: decode %s: %w", path, err)
+		}
+		for table, v := range doc {
+			rows, err := toRows(v)
+			if err != nil {
+				return nil, fmt.Errorf("fixtures: %s: table %s: %w", path, table, err)
+			}
+			set[table] = append(set[table], rows...)
+		}
+	}
+	return set, nil
+}
+
+// osFS reads paths relative to the working directory or absolute, like os.ReadFile.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// toRows converts a decoded list of objects to rows.
+func toRows(v interface{}) ([]Row, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list of rows, got %T", v)
+	}
+	rows := make([]Row, len(list))
+	for i, item := range list {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("row %d: expected an object, got %T", i, item)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// decodeJSON decodes JSON with numbers converted to int64 where exact, float64 otherwise.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if doc, ok := v.(*map[string]interface{}); ok {
+		for k, val := range *doc {
+			(*doc)[k] = convertNumbers(val)
+		}
+	}
+	return nil
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case []interface{}:
+		for i := range val {
+			val[i] = convertNumbers(val[i])
+		}
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = convertNumbers(val[k])
+		}
+	}
+	return v
+}
@@ -0,0 +1,229 @@
+// Package fixtures loads fixture data into database tables for tests and demos.
+//
+// A fixture Set maps table names to rows. Sets can be written as Go literals
+// or loaded from JSON files; YAML and other formats are supported by
+// registering a decoder, which keeps Relica free of dependencies:
+//
+//	loader := fixtures.New(db, fixtures.WithDecoder(".yml", yaml.Unmarshal))
+//	err := loader.LoadFiles(ctx, "testdata/users.yml", "testdata/orders.json")
+//
+// Tables are inserted in foreign-key order (parents first) and cleared in
+// reverse order, using batched multi-row INSERT statements. String values may
+// contain template actions such as {{now}}; see Loader.Load.
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coregx/relica"
+)
+
+// DefaultBatchSize is the maximum number of rows per INSERT statement.
+const DefaultBatchSize = 500
+
+// Row is a single fixture row mapping column names to values.
+type Row = map[string]interface{}
+
+// Set maps table names to the rows to insert into them.
+//
+// Example:
+//
+//	fixtures.Set{
+//	    "users":  {{"id": 1, "name": "alice", "created_at": "{{now}}"}},
+//	    "orders": {{"id": 10, "user_id": 1, "total": 99.5}},
+//	}
+type Set map[string][]Row
+
+// Func is a template function callable from fixture values as {{name arg...}}.
+// Arguments are the whitespace-separated words after the name; double-quoted
+// arguments may contain spaces.
+type Func func(args ...string) (interface{}, error)
+
+// DecodeFunc decodes a fixture file into v (a *map[string]interface{}),
+// with the same signature as json.Unmarshal and yaml.Unmarshal.
+type DecodeFunc func(data []byte, v interface{}) error
+
+// Loader loads fixture sets into a database.
+type Loader struct {
+	db        *relica.DB
+	order     []string
+	batchSize int
+	funcs     map[string]Func
+	decoders  map[string]DecodeFunc
+	clock     func() time.Time
+}
+
+// Option configures a Loader.
+type Option func(*Loader)
+
+// WithOrder sets the table insertion order explicitly instead of reading
+// foreign keys from the database. Tables not listed follow in alphabetical order.
+func WithOrder(tables ...string) Option {
+	return func(l *Loader) {
+		l.order = tables
+	}
+}
+
+// WithBatchSize sets the maximum number of rows per INSERT statement
+// (default DefaultBatchSize).
+func WithBatchSize(n int) Option {
+	return func(l *Loader) {
+		if n > 0 {
+			l.batchSize = n
+		}
+	}
+}
+
+// WithFunc registers a template function, replacing any built-in of the same name.
+func WithFunc(name string, fn Func) Option {
+	return func(l *Loader) {
+		l.funcs[name] = fn
+	}
+}
+
+// WithDecoder registers a decoder for fixture files with the given extension
+// (e.g. ".yml"). JSON (".json") is supported out of the box.
+func WithDecoder(ext string, fn DecodeFunc) Option {
+	return func(l *Loader) {
+		l.decoders[strings.ToLower(ext)] = fn
+	}
+}
+
+// WithClock sets the time source for the {{now}} template function
+// (default time.Now).
+func WithClock(now func() time.Time) Option {
+	return func(l *Loader) {
+		l.clock = now
+	}
+}
+
+// New creates a Loader for db.
+//
+// db may be a regular DB or a transaction-bound handle such as the one
+// returned by relicatest.NewRollbackDB.
+func New(db *relica.DB, opts ...Option) *Loader {
+	l := &Loader{
+		db:        db,
+		batchSize: DefaultBatchSize,
+		funcs:     make(map[string]Func),
+		decoders:  map[string]DecodeFunc{".json": decodeJSON},
+		clock:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load inserts all rows of set inside a single transaction, parents before
+// children. Consecutive rows with the same columns of a table are inserted
+// with one multi-row INSERT per batch; columns a row omits keep their defaults.
+//
+// String values are templates when they contain {{...}} actions. A value that
+// consists of a single action is replaced by the function's result, keeping its
+// type; otherwise each action is replaced by its formatted result. Built-in
+// functions:
+//
+//	{{now}}          current time (the same instant for the whole Load call)
+//	{{now "-24h"}}   current time plus a time.ParseDuration offset
+func (l *Loader) Load(ctx context.Context, set Set) error {
+	tables, err := l.tableOrder(ctx, set.tables())
+	if err != nil {
+		return err
+	}
+	funcs := l.templateFuncs()
+
+	return l.db.Transactional(ctx, func(tx *relica.Tx) error {
+		for _, table := range tables {
+			if err := l.insertRows(tx, table, set[table], funcs); err != nil {
+				return fmt.Errorf("fixtures: table %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Truncate deletes all rows from tables, children before parents.
+// DELETE is used rather than TRUNCATE so that it works inside transactions
+// on every dialect; auto-increment counters are not reset.
+func (l *Loader) Truncate(ctx context.Context, tables ...string) error {
+	ordered, err := l.tableOrder(ctx, tables)
+	if err != nil {
+		return err
+	}
+
+	return l.db.Transactional(ctx, func(tx *relica.Tx) error {
+		for i := len(ordered) - 1; i >= 0; i-- {
+			if _, err := tx.Delete(ordered[i]).Execute(); err != nil {
+				return fmt.Errorf("fixtures: truncate %s: %w", ordered[i], err)
+			}
+		}
+		return nil
+	})
+}
+
+// Reset truncates every table in set and loads set again.
+func (l *Loader) Reset(ctx context.Context, set Set) error {
+	if err := l.Truncate(ctx, set.tables()...); err != nil {
+		return err
+	}
+	return l.Load(ctx, set)
+}
+
+// insertRows inserts rows into table in batches of consecutive rows sharing a column set.
+func (l *Loader) insertRows(tx *relica.Tx, table string, rows []Row, funcs map[string]Func) error {
+	for start := 0; start < len(rows); {
+		cols := rowColumns(rows[start])
+		if len(cols) == 0 {
+			return fmt.Errorf("row %d has no columns", start)
+		}
+		key := strings.Join(cols, "\x00")
+
+		end := start + 1
+		for end < len(rows) && end-start < l.batchSize && strings.Join(rowColumns(rows[end]), "\x00") == key {
+			end++
+		}
+
+		batch := tx.BatchInsert(table, cols)
+		for i := start; i < end; i++ {
+			values := make([]interface{}, len(cols))
+			for j, col := range cols {
+				v, err := render(rows[i][col], funcs)
+				if err != nil {
+					return fmt.Errorf("row %d, column %s: %w", i, col, err)
+				}
+				values[j] = v
+			}
+			batch.Values(values...)
+		}
+		if _, err := batch.Execute(); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// tables returns the table names of s in alphabetical order.
+func (s Set) tables() []string {
+	tables := make([]string, 0, len(s))
+	for table := range s {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// rowColumns returns the column names of row in alphabetical order.
+func rowColumns(row Row) []string {
+	cols := make([]string, 0, len(row))
+	for col := range row {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	return cols
+}
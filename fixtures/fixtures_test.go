@@ -0,0 +1,167 @@
+package fixtures
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	_ "modernc.org/sqlite"
+)
+
+var fixedNow = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func openShopDB(t *testing.T) *relica.DB {
+	t.Helper()
+	db, err := relica.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "shop.db")+"?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	for _, ddl := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, role TEXT DEFAULT 'member', created_at DATETIME)`,
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users(id), total REAL, note TEXT)`,
+		`CREATE TABLE order_items (order_id INTEGER NOT NULL REFERENCES orders(id), sku TEXT)`,
+	} {
+		_, err = db.ExecContext(context.Background(), ddl)
+		require.NoError(t, err)
+	}
+	return db
+}
+
+func count(t *testing.T, db *relica.DB, table string) int {
+	t.Helper()
+	var n int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM "+table).Row(&n))
+	return n
+}
+
+func TestLoader_Load(t *testing.T) {
+	db := openShopDB(t)
+	ctx := context.Background()
+	loader := New(db, WithClock(func() time.Time { return fixedNow }), WithBatchSize(2))
+
+	err := loader.Load(ctx, Set{
+		"order_items": {{"order_id": 10, "sku": "A"}, {"order_id": 10, "sku": "B"}, {"order_id": 11, "sku": "C"}},
+		"orders": {
+			{"id": 10, "user_id": 1, "total": 9.5, "note": "placed {{now \"-1h\"}}"},
+			{"id": 11, "user_id": 2, "total": 3.0},
+		},
+		"users": {
+			{"id": 1, "name": "alice", "created_at": "{{now}}"},
+			{"id": 2, "name": "bob", "role": "admin", "created_at": "{{ now \"-24h\" }}"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count(t, db, "users"))
+	assert.Equal(t, 3, count(t, db, "order_items"))
+
+	var users []struct {
+		Name      string    `db:"name"`
+		Role      string    `db:"role"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	require.NoError(t, db.Select("name", "role", "created_at").From("users").OrderBy("id").All(&users))
+	require.Len(t, users, 2)
+	assert.Equal(t, "member", users[0].Role, "omitted columns keep their defaults")
+	assert.Equal(t, "admin", users[1].Role)
+	assert.True(t, fixedNow.Equal(users[0].CreatedAt))
+	assert.True(t, fixedNow.Add(-24*time.Hour).Equal(users[1].CreatedAt))
+
+	var note string
+	require.NoError(t, db.NewQuery("SELECT note FROM orders WHERE id = 10").Row(&note))
+	assert.Equal(t, "placed 2026-05-01T11:00:00Z", note)
+}
+
+func TestLoader_LoadRollsBackOnError(t *testing.T) {
+	db := openShopDB(t)
+
+	err := New(db).Load(context.Background(), Set{
+		"users":  {{"id": 1, "name": "alice"}},
+		"orders": {{"id": 10, "user_id": 99}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fixtures: table orders")
+	assert.Equal(t, 0, count(t, db, "users"))
+
+	err = New(db).Load(context.Background(), Set{"users": {{"id": 1, "name": "{{nope}}"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown template function "nope"`)
+}
+
+func TestLoader_TruncateAndReset(t *testing.T) {
+	db := openShopDB(t)
+	ctx := context.Background()
+	loader := New(db)
+	set := Set{
+		"users":  {{"id": 1, "name": "alice"}},
+		"orders": {{"id": 10, "user_id": 1}},
+	}
+
+	require.NoError(t, loader.Load(ctx, set))
+	require.NoError(t, loader.Reset(ctx, set))
+	assert.Equal(t, 1, count(t, db, "users"))
+
+	require.NoError(t, loader.Truncate(ctx, "users", "orders"))
+	assert.Equal(t, 0, count(t, db, "users"))
+	assert.Equal(t, 0, count(t, db, "orders"))
+}
+
+func TestLoader_Files(t *testing.T) {
+	db := openShopDB(t)
+	ctx := context.Background()
+	loader := New(db, WithDecoder(".yml", yaml.Unmarshal), WithFunc("upper", func(args ...string) (interface{}, error) {
+		return strings.ToUpper(strings.Join(args, " ")), nil
+	}))
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "orders.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"orders": [{"id": 10, "user_id": 1, "total": 12.25}]}`), 0o600))
+	yamlPath := filepath.Join(dir, "users.yml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+users:
+  - id: 1
+    name: '{{upper "alice b"}}'
+`), 0o600))
+
+	require.NoError(t, loader.LoadFiles(ctx, jsonPath, yamlPath))
+	var name string
+	require.NoError(t, db.NewQuery("SELECT name FROM users WHERE id = 1").Row(&name))
+	assert.Equal(t, "ALICE B", name)
+	var total float64
+	require.NoError(t, db.NewQuery("SELECT total FROM orders WHERE id = 10").Row(&total))
+	assert.Equal(t, 12.25, total)
+
+	set, err := loader.ReadFS(fstest.MapFS{
+		"a.json": {Data: []byte(`{"users": [{"id": 1}]}`)},
+		"b.json": {Data: []byte(`{"users": [{"id": 2.5}]}`)},
+	}, "a.json", "b.json")
+	require.NoError(t, err)
+	assert.Equal(t, Set{"users": {{"id": int64(1)}, {"id": 2.5}}}, set)
+
+	_, err = loader.ReadFS(fstest.MapFS{"x.toml": {}}, "x.toml")
+	assert.ErrorContains(t, err, "no decoder for x.toml")
+	_, err = loader.ReadFS(fstest.MapFS{"x.json": {Data: []byte(`{"users": {"id": 1}}`)}}, "x.json")
+	assert.ErrorContains(t, err, "expected a list of rows")
+}
+
+func TestTableOrder(t *testing.T) {
+	sorted, err := topoSort([]string{"c", "b", "a", "d"}, map[string][]string{
+		"c": {"b", "external"},
+		"b": {"a", "b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "d", "b", "c"}, sorted)
+
+	_, err = topoSort([]string{"a", "b"}, map[string][]string{"a": {"b"}, "b": {"a"}})
+	assert.ErrorContains(t, err, "foreign key cycle between a, b")
+
+	assert.Equal(t, []string{"users", "orders", "audit", "tags"},
+		explicitOrder([]string{"users", "orders"}, []string{"tags", "orders", "audit", "users"}))
+}
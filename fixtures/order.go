@@ -0,0 +1,117 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// tableOrder returns tables sorted parents first: by WithOrder if set,
+// otherwise by the foreign keys declared in the database.
+func (l *Loader) tableOrder(ctx context.Context, tables []string) ([]string, error) {
+	if len(l.order) > 0 {
+		return explicitOrder(l.order, tables), nil
+	}
+
+	deps := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		refs, err := l.references(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: read foreign keys of %s: %w", table, err)
+		}
+		deps[table] = refs
+	}
+	return topoSort(tables, deps)
+}
+
+// explicitOrder sorts tables by their position in order; unlisted tables
+// follow alphabetically.
+func explicitOrder(order, tables []string) []string {
+	pos := make(map[string]int, len(order))
+	for i, table := range order {
+		pos[table] = i
+	}
+	sorted := append([]string(nil), tables...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, iok := pos[sorted[i]]
+		pj, jok := pos[sorted[j]]
+		switch {
+		case iok && jok:
+			return pi < pj
+		case iok != jok:
+			return iok
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+// references returns the tables that table has foreign keys to.
+func (l *Loader) references(ctx context.Context, table string) ([]string, error) {
+	var query string
+	switch dialects.GetDialect(l.db.Unwrap().DriverName()).(type) {
+	case *dialects.PostgresDialect:
+		query = `SELECT DISTINCT ccu.table_name
+FROM information_schema.table_constraints tc
+JOIN information_schema.constraint_column_usage ccu
+  ON ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = current_schema() AND tc.table_name = $1`
+	case *dialects.MySQLDialect:
+		query = `SELECT DISTINCT REFERENCED_TABLE_NAME FROM information_schema.KEY_COLUMN_USAGE
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL`
+	case *dialects.SQLiteDialect:
+		query = `SELECT DISTINCT "table" FROM pragma_foreign_key_list(?)`
+	default:
+		return nil, fmt.Errorf("unsupported driver %q (use WithOrder)", l.db.Unwrap().DriverName())
+	}
+
+	var refs []string
+	if err := l.db.NewQuery(query, table).WithContext(ctx).Column(&refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// topoSort orders tables so that every table follows the tables it references.
+// References to tables outside the list and self-references are ignored; ties
+// are broken alphabetically.
+func topoSort(tables []string, deps map[string][]string) ([]string, error) {
+	pending := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		pending[table] = true
+	}
+
+	sorted := make([]string, 0, len(tables))
+	for len(pending) > 0 {
+		var ready []string
+		for table := range pending {
+			blocked := false
+			for _, ref := range deps[table] {
+				if ref != table && pending[ref] {
+					blocked = true
+					break
+				}
+			}
+			if !blocked {
+				ready = append(ready, table)
+			}
+		}
+		if len(ready) == 0 {
+			cycle := make([]string, 0, len(pending))
+			for table := range pending {
+				cycle = append(cycle, table)
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("fixtures: foreign key cycle between %s (use WithOrder)", strings.Join(cycle, ", "))
+		}
+		sort.Strings(ready)
+		for _, table := range ready {
+			delete(pending, table)
+		}
+		sorted = append(sorted, ready...)
+	}
+	return sorted, nil
+}
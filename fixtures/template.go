@@ -0,0 +1,114 @@
+package fixtures
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// actionRegex matches a template action such as {{now "-1h"}}.
+var actionRegex = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+// templateFuncs returns the built-in functions merged with those set by WithFunc.
+// The clock is read once, so {{now}} is the same instant for a whole load.
+func (l *Loader) templateFuncs() map[string]Func {
+	now := l.clock()
+	funcs := map[string]Func{
+		"now": func(args ...string) (interface{}, error) {
+			switch len(args) {
+			case 0:
+				return now, nil
+			case 1:
+				d, err := time.ParseDuration(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return now.Add(d), nil
+			}
+			return nil, fmt.Errorf("now takes at most one argument")
+		},
+	}
+	for name, fn := range l.funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// render evaluates the template actions in string values; other values are returned as is.
+func render(v interface{}, funcs map[string]Func) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || !strings.Contains(s, "{{") {
+		return v, nil
+	}
+
+	if m := actionRegex.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		return call(s[m[2]:m[3]], funcs)
+	}
+
+	var firstErr error
+	out := actionRegex.ReplaceAllStringFunc(s, func(action string) string {
+		res, err := call(actionRegex.FindStringSubmatch(action)[1], funcs)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return ""
+		}
+		if t, ok := res.(time.Time); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+		return fmt.Sprint(res)
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// call runs a single action such as `now "-1h"`.
+func call(action string, funcs map[string]Func) (interface{}, error) {
+	words, err := splitArgs(action)
+	if err != nil {
+		return nil, fmt.Errorf("{{%s}}: %w", action, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty template action")
+	}
+	fn := funcs[words[0]]
+	if fn == nil {
+		return nil, fmt.Errorf("unknown template function %q", words[0])
+	}
+	res, err := fn(words[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("{{%s}}: %w", action, err)
+	}
+	return res, nil
+}
+
+// splitArgs splits an action into whitespace-separated words; double-quoted
+// words may contain spaces and Go escape sequences.
+func splitArgs(action string) ([]string, error) {
+	var words []string
+	for s := strings.TrimSpace(action); s != ""; s = strings.TrimSpace(s) {
+		if s[0] != '"' {
+			end := strings.IndexAny(s, " \t")
+			if end < 0 {
+				end = len(s)
+			}
+			words = append(words, s[:end])
+			s = s[end:]
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("malformed quoted argument %s", s)
+		}
+		word, _ := strconv.Unquote(quoted)
+		words = append(words, word)
+		s = s[len(quoted):]
+	}
+	return words, nil
+}
@@ -4,21 +4,36 @@ package cache
 import (
 	"container/list"
 	"database/sql"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const (
 	// DefaultStmtCacheCapacity is the default maximum number of cached prepared statements.
 	DefaultStmtCacheCapacity = 1000
+
+	// MaxStmtCacheShards is the largest number of shards a cache is split into
+	// by default.
+	MaxStmtCacheShards = 16
+
+	// minShardCapacity is the smallest shard capacity chosen by default. Each
+	// shard evicts on its own, so tiny shards would evict hot statements while
+	// other shards have room.
+	minShardCapacity = 64
 )
 
 // StmtCache stores prepared statements with LRU eviction policy.
+//
+// The cache is split into shards by a hash of the SQL, each with its own lock
+// and LRU list, so concurrent lookups of different statements rarely contend.
+// Eviction is LRU within a shard. Small caches use a single shard, which is an
+// exact LRU.
 type StmtCache struct {
-	mu       sync.RWMutex
 	capacity int
-	items    map[string]*list.Element
-	lruList  *list.List
+	shards   []*stmtShard
+	mask     uint32 // len(shards) - 1; the shard count is a power of two
 
 	// Metrics using atomic for lock-free access.
 	hits      atomic.Uint64
@@ -26,11 +41,22 @@ type StmtCache struct {
 	evictions atomic.Uint64
 }
 
+// stmtShard is one LRU partition of a StmtCache.
+type stmtShard struct {
+	mu       sync.RWMutex
+	capacity int
+	items    map[string]*list.Element
+	lruList  *list.List
+	pinned   int // Number of pinned entries (guarded by mu).
+}
+
 // cacheEntry represents a single cached prepared statement.
 type cacheEntry struct {
-	key    string
-	stmt   *sql.Stmt
-	pinned bool // If true, entry won't be evicted by LRU
+	key      string
+	stmt     *sql.Stmt
+	pinned   bool      // If true, entry won't be evicted by LRU
+	hits     uint64    // Number of cache hits for this entry (guarded by mu)
+	lastUsed time.Time // Time of the last Get hit or Set (guarded by mu)
 }
 
 // NewStmtCache creates a new prepared statement cache with default capacity.
@@ -39,108 +65,257 @@ func NewStmtCache() *StmtCache {
 }
 
 // NewStmtCacheWithCapacity creates a new prepared statement cache with specified capacity.
+// The number of shards is chosen from the capacity.
 func NewStmtCacheWithCapacity(capacity int) *StmtCache {
+	return NewStmtCacheWithShards(capacity, 0)
+}
+
+// NewStmtCacheWithShards creates a new prepared statement cache with the
+// specified capacity split into shards shards. shards is rounded up to a power
+// of two, without exceeding capacity. Zero or less chooses the number from the
+// capacity: the most shards, up to MaxStmtCacheShards, that leave 64
+// statements to each.
+func NewStmtCacheWithShards(capacity, shards int) *StmtCache {
 	if capacity <= 0 {
 		capacity = DefaultStmtCacheCapacity
 	}
-	return &StmtCache{
+	n := 1
+	if shards <= 0 {
+		// Largest power of two leaving each shard minShardCapacity statements.
+		for n*2 <= MaxStmtCacheShards && n*2*minShardCapacity <= capacity {
+			n *= 2
+		}
+	} else {
+		for n < shards {
+			n *= 2
+		}
+		for n > capacity {
+			n /= 2
+		}
+	}
+
+	sc := &StmtCache{
 		capacity: capacity,
-		items:    make(map[string]*list.Element, capacity),
-		lruList:  list.New(),
+		shards:   make([]*stmtShard, n),
+		mask:     uint32(n - 1),
+	}
+	for i := range sc.shards {
+		// Spread the capacity so the shard capacities add up to capacity.
+		shardCap := capacity / n
+		if i < capacity%n {
+			shardCap++
+		}
+		sc.shards[i] = &stmtShard{
+			capacity: shardCap,
+			items:    make(map[string]*list.Element, shardCap),
+			lruList:  list.New(),
+		}
+	}
+	return sc
+}
+
+// shard returns the shard holding key.
+func (sc *StmtCache) shard(key string) *stmtShard {
+	if sc.mask == 0 {
+		return sc.shards[0]
 	}
+	// FNV-1a, inlined to avoid allocating a hash.Hash per lookup.
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return sc.shards[h&sc.mask]
 }
 
 // Get retrieves a prepared statement from cache by SQL query string.
 // Returns the statement and true if found, nil and false otherwise.
 // Accessing a statement moves it to the front of the LRU list.
 func (sc *StmtCache) Get(key string) (*sql.Stmt, bool) {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	s := sc.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	elem, exists := sc.items[key]
+	elem, exists := s.items[key]
 	if !exists {
 		sc.misses.Add(1)
 		return nil, false
 	}
 
 	// Move to front (most recently used).
-	sc.lruList.MoveToFront(elem)
+	s.lruList.MoveToFront(elem)
 	sc.hits.Add(1)
 
 	entry := elem.Value.(*cacheEntry)
+	entry.hits++
+	entry.lastUsed = time.Now()
 	return entry.stmt, true
 }
 
 // Set stores a prepared statement in cache with SQL query string as key.
-// If the cache is at capacity, the least recently used statement is evicted and closed.
+// If the key's shard is at capacity, its least recently used statement is evicted and closed.
 func (sc *StmtCache) Set(key string, stmt *sql.Stmt) {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	s := sc.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	// Check if key already exists.
-	if elem, exists := sc.items[key]; exists {
+	if elem, exists := s.items[key]; exists {
 		// Update existing entry and move to front.
-		sc.lruList.MoveToFront(elem)
+		s.lruList.MoveToFront(elem)
 		entry := elem.Value.(*cacheEntry)
 		// Close old statement before replacing.
 		_ = entry.stmt.Close() // Best effort close.
 		entry.stmt = stmt
+		entry.lastUsed = time.Now()
 		return
 	}
 
 	// Evict if at capacity.
-	if sc.lruList.Len() >= sc.capacity {
-		sc.evictOldest()
+	if s.lruList.Len() >= s.capacity {
+		if s.evictOldest() {
+			sc.evictions.Add(1)
+		}
 	}
 
 	// Add new entry to front.
 	entry := &cacheEntry{
-		key:  key,
-		stmt: stmt,
+		key:      key,
+		stmt:     stmt,
+		lastUsed: time.Now(),
+	}
+	elem := s.lruList.PushFront(entry)
+	s.items[key] = elem
+}
+
+// SetIfAbsent stores stmt unless key is already cached, and returns the
+// statement to use for key: the cached one if present, otherwise stmt. A
+// stmt that is not stored is closed. Unlike Set, it never closes a cached
+// statement that another goroutine may be executing, so concurrent misses
+// on the same query keep the first statement prepared.
+func (sc *StmtCache) SetIfAbsent(key string, stmt *sql.Stmt) *sql.Stmt {
+	s := sc.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, exists := s.items[key]; exists {
+		s.lruList.MoveToFront(elem)
+		entry := elem.Value.(*cacheEntry)
+		entry.lastUsed = time.Now()
+		_ = stmt.Close() // Best effort close.
+		return entry.stmt
+	}
+
+	if s.lruList.Len() >= s.capacity {
+		if s.evictOldest() {
+			sc.evictions.Add(1)
+		}
+	}
+	elem := s.lruList.PushFront(&cacheEntry{
+		key:      key,
+		stmt:     stmt,
+		lastUsed: time.Now(),
+	})
+	s.items[key] = elem
+	return stmt
+}
+
+// Invalidate closes and removes the cached statement of key if it is stmt,
+// including a pinned one, and reports whether it was removed. A statement
+// that was already replaced by a newer one is left alone. Invalidations are
+// not counted as evictions.
+func (sc *StmtCache) Invalidate(key string, stmt *sql.Stmt) bool {
+	s := sc.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, exists := s.items[key]
+	if !exists {
+		return false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if entry.stmt != stmt {
+		return false
 	}
-	elem := sc.lruList.PushFront(entry)
-	sc.items[key] = elem
+	s.lruList.Remove(elem)
+	delete(s.items, key)
+	if entry.pinned {
+		s.pinned--
+	}
+	_ = entry.stmt.Close() // Best effort close.
+	return true
 }
 
 // evictOldest removes and closes the least recently used statement.
 // Pinned statements are skipped during eviction.
+// Returns false if all entries are pinned.
 // Must be called with lock held.
-func (sc *StmtCache) evictOldest() {
+func (s *stmtShard) evictOldest() bool {
 	// Find the oldest unpinned entry
-	for elem := sc.lruList.Back(); elem != nil; elem = elem.Prev() {
+	for elem := s.lruList.Back(); elem != nil; elem = elem.Prev() {
 		entry := elem.Value.(*cacheEntry)
 		if entry.pinned {
 			continue // Skip pinned entries
 		}
 
 		// Found unpinned entry, evict it
-		sc.lruList.Remove(elem)
-		delete(sc.items, entry.key)
+		s.lruList.Remove(elem)
+		delete(s.items, entry.key)
 
 		// Close the evicted statement (best effort).
 		_ = entry.stmt.Close()
-		sc.evictions.Add(1)
-		return
+		return true
 	}
 
 	// All entries are pinned - this should not normally happen
 	// as callers should ensure unpinned space is available
+	return false
 }
 
 // Clear closes and removes all cached prepared statements.
 func (sc *StmtCache) Clear() {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	for _, s := range sc.shards {
+		s.mu.Lock()
 
-	// Close all statements.
-	for elem := sc.lruList.Front(); elem != nil; elem = elem.Next() {
-		entry := elem.Value.(*cacheEntry)
-		_ = entry.stmt.Close() // Best effort close.
+		// Close all statements.
+		for elem := s.lruList.Front(); elem != nil; elem = elem.Next() {
+			entry := elem.Value.(*cacheEntry)
+			_ = entry.stmt.Close() // Best effort close.
+		}
+
+		// Reset shard state.
+		s.items = make(map[string]*list.Element, s.capacity)
+		s.lruList.Init()
+		s.pinned = 0
+
+		s.mu.Unlock()
 	}
+}
 
-	// Reset cache state.
-	sc.items = make(map[string]*list.Element, sc.capacity)
-	sc.lruList.Init()
+// RemoveIf closes and removes every cached statement whose key matches,
+// including pinned statements. Returns the number of removed statements.
+// Removals are not counted as evictions.
+func (sc *StmtCache) RemoveIf(match func(key string) bool) int {
+	removed := 0
+	for _, s := range sc.shards {
+		s.mu.Lock()
+		for elem := s.lruList.Front(); elem != nil; {
+			next := elem.Next()
+			entry := elem.Value.(*cacheEntry)
+			if match(entry.key) {
+				s.lruList.Remove(elem)
+				delete(s.items, entry.key)
+				if entry.pinned {
+					s.pinned--
+				}
+				_ = entry.stmt.Close() // Best effort close.
+				removed++
+			}
+			elem = next
+		}
+		s.mu.Unlock()
+	}
+	return removed
 }
 
 // Stats holds cache performance metrics.
@@ -150,48 +325,67 @@ type Stats struct {
 	Hits      uint64  // Number of successful cache lookups.
 	Misses    uint64  // Number of cache misses.
 	Evictions uint64  // Number of evicted statements.
+	Pinned    int     // Number of pinned statements.
 	HitRate   float64 // Cache hit rate (hits / total requests).
+	Shards    int     // Number of shards.
+}
+
+// Entry describes a single cached statement.
+type Entry struct {
+	SQL      string    // Cache key (SQL query string).
+	Hits     uint64    // Number of cache hits for this statement.
+	LastUsed time.Time // Time of the last cache hit, or when the statement was cached.
+	Pinned   bool      // Whether the statement is pinned.
 }
 
 // Pin marks a cached statement as pinned, preventing it from being evicted.
 // Pinned statements remain in cache until explicitly unpinned or cleared.
 // Returns true if the key was found and pinned, false otherwise.
 func (sc *StmtCache) Pin(key string) bool {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	s := sc.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	elem, exists := sc.items[key]
+	elem, exists := s.items[key]
 	if !exists {
 		return false
 	}
 
 	entry := elem.Value.(*cacheEntry)
-	entry.pinned = true
+	if !entry.pinned {
+		entry.pinned = true
+		s.pinned++
+	}
 	return true
 }
 
 // Unpin removes the pin from a cached statement, allowing it to be evicted normally.
 // Returns true if the key was found and unpinned, false otherwise.
 func (sc *StmtCache) Unpin(key string) bool {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	s := sc.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	elem, exists := sc.items[key]
+	elem, exists := s.items[key]
 	if !exists {
 		return false
 	}
 
 	entry := elem.Value.(*cacheEntry)
-	entry.pinned = false
+	if entry.pinned {
+		entry.pinned = false
+		s.pinned--
+	}
 	return true
 }
 
 // IsPinned returns true if the given key is pinned.
 func (sc *StmtCache) IsPinned(key string) bool {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	s := sc.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	elem, exists := sc.items[key]
+	elem, exists := s.items[key]
 	if !exists {
 		return false
 	}
@@ -202,9 +396,13 @@ func (sc *StmtCache) IsPinned(key string) bool {
 
 // Stats returns cache statistics.
 func (sc *StmtCache) Stats() Stats {
-	sc.mu.RLock()
-	size := sc.lruList.Len()
-	sc.mu.RUnlock()
+	size, pinned := 0, 0
+	for _, s := range sc.shards {
+		s.mu.RLock()
+		size += s.lruList.Len()
+		pinned += s.pinned
+		s.mu.RUnlock()
+	}
 
 	hits := sc.hits.Load()
 	misses := sc.misses.Load()
@@ -222,6 +420,37 @@ func (sc *StmtCache) Stats() Stats {
 		Hits:      hits,
 		Misses:    misses,
 		Evictions: evictions,
+		Pinned:    pinned,
 		HitRate:   hitRate,
+		Shards:    len(sc.shards),
+	}
+}
+
+// Entries returns a snapshot of the cached statements,
+// ordered from most to least recently used.
+func (sc *StmtCache) Entries() []Entry {
+	var entries []Entry
+	for _, s := range sc.shards {
+		s.mu.RLock()
+		for elem := s.lruList.Front(); elem != nil; elem = elem.Next() {
+			entry := elem.Value.(*cacheEntry)
+			entries = append(entries, Entry{
+				SQL:      entry.key,
+				Hits:     entry.hits,
+				LastUsed: entry.lastUsed,
+				Pinned:   entry.pinned,
+			})
+		}
+		s.mu.RUnlock()
+	}
+	if len(sc.shards) > 1 {
+		// Each shard is in LRU order already; interleave them by last use.
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].LastUsed.After(entries[j].LastUsed)
+		})
+	}
+	if entries == nil {
+		entries = []Entry{}
 	}
+	return entries
 }
@@ -243,3 +243,41 @@ func BenchmarkStmtCache_Stats(b *testing.B) {
 		_ = cache.Stats()
 	}
 }
+
+// BenchmarkStmtCache_Shards measures lock contention of parallel lookups
+// (90% Get hits, 10% Set) with one shard versus sharded caches. Run with
+// -cpu 8 or more: with one CPU there is no contention and sharding only adds
+// the cost of hashing the key.
+func BenchmarkStmtCache_Shards(b *testing.B) {
+	const keys = 512
+	for _, shards := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("shards_%d", shards), func(b *testing.B) {
+			db := setupBenchDB(b)
+			cache := NewStmtCacheWithShards(1024, shards)
+			stmt := createBenchStmt(b, db, "SELECT 1")
+
+			names := make([]string, keys)
+			for i := range names {
+				names[i] = fmt.Sprintf("SELECT * FROM t%d WHERE id = ?", i)
+				cache.Set(names[i], stmt)
+			}
+
+			b.SetParallelism(4) // 4 goroutines per CPU, as busy servers have
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					key := names[i%keys]
+					if i%10 == 0 {
+						cache.Set(key, stmt)
+					} else {
+						_, _ = cache.Get(key)
+					}
+					i += 7
+				}
+			})
+		})
+	}
+}
@@ -3,8 +3,10 @@ package cache
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,8 +35,8 @@ func TestNewStmtCache(t *testing.T) {
 	cache := NewStmtCache()
 	require.NotNil(t, cache)
 	assert.Equal(t, DefaultStmtCacheCapacity, cache.capacity)
-	assert.Equal(t, 0, cache.lruList.Len())
-	assert.Equal(t, 0, len(cache.items))
+	assert.Equal(t, 0, cache.Stats().Size)
+	assert.Len(t, cache.shards, 8) // 1000 / 64, rounded down to a power of two
 }
 
 func TestNewStmtCacheWithCapacity(t *testing.T) {
@@ -259,6 +261,119 @@ func TestStmtCache_Stats(t *testing.T) {
 	assert.Equal(t, uint64(1), stats.Evictions)
 }
 
+func TestStmtCache_PinnedStats(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewStmtCache()
+
+	cache.Set("query1", createTestStmt(t, db, "SELECT 1"))
+	cache.Set("query2", createTestStmt(t, db, "SELECT 2"))
+
+	cache.Pin("query1")
+	cache.Pin("query1") // Pinning twice counts once.
+	cache.Pin("query2")
+	assert.Equal(t, 2, cache.Stats().Pinned)
+
+	cache.Unpin("query2")
+	cache.Unpin("query2")
+	assert.Equal(t, 1, cache.Stats().Pinned)
+
+	cache.Clear()
+	assert.Equal(t, 0, cache.Stats().Pinned)
+}
+
+func TestStmtCache_Entries(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewStmtCache()
+
+	assert.Empty(t, cache.Entries())
+
+	before := time.Now()
+	cache.Set("query1", createTestStmt(t, db, "SELECT 1"))
+	cache.Set("query2", createTestStmt(t, db, "SELECT 2"))
+	cache.Pin("query2")
+
+	cache.Get("query1")
+	cache.Get("query1")
+
+	entries := cache.Entries()
+	require.Len(t, entries, 2)
+
+	// Most recently used first.
+	assert.Equal(t, "query1", entries[0].SQL)
+	assert.Equal(t, uint64(2), entries[0].Hits)
+	assert.False(t, entries[0].Pinned)
+	assert.False(t, entries[0].LastUsed.Before(before))
+
+	assert.Equal(t, "query2", entries[1].SQL)
+	assert.Equal(t, uint64(0), entries[1].Hits)
+	assert.True(t, entries[1].Pinned)
+	assert.False(t, entries[1].LastUsed.IsZero())
+}
+
+func TestStmtCache_RemoveIf(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewStmtCache()
+
+	cache.Set("SELECT * FROM users", createTestStmt(t, db, "SELECT 1"))
+	cache.Set("SELECT * FROM orders", createTestStmt(t, db, "SELECT 2"))
+	cache.Set("DELETE FROM users", createTestStmt(t, db, "SELECT 3"))
+	cache.Pin("DELETE FROM users")
+
+	removed := cache.RemoveIf(func(key string) bool { return strings.HasSuffix(key, "users") })
+	assert.Equal(t, 2, removed)
+
+	stats := cache.Stats()
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, 0, stats.Pinned)
+	assert.Equal(t, uint64(0), stats.Evictions)
+
+	_, found := cache.Get("SELECT * FROM orders")
+	assert.True(t, found)
+	_, found = cache.Get("SELECT * FROM users")
+	assert.False(t, found)
+}
+
+func TestStmtCache_SetIfAbsent(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewStmtCache()
+
+	first := createTestStmt(t, db, "SELECT 1")
+	assert.Same(t, first, cache.SetIfAbsent("SELECT 1", first))
+
+	second := createTestStmt(t, db, "SELECT 1")
+	assert.Same(t, first, cache.SetIfAbsent("SELECT 1", second), "the cached statement is kept")
+	_, err := second.Exec()
+	assert.EqualError(t, err, "sql: statement is closed", "the statement that lost is closed")
+	_, err = first.Exec()
+	assert.NotEqual(t, "sql: statement is closed", err.Error())
+	assert.Equal(t, 1, cache.Stats().Size)
+}
+
+func TestStmtCache_Invalidate(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewStmtCache()
+
+	stale := createTestStmt(t, db, "SELECT 1")
+	cache.Set("SELECT 1", stale)
+	cache.Pin("SELECT 1")
+	assert.True(t, cache.Invalidate("SELECT 1", stale))
+	assert.False(t, cache.Invalidate("SELECT 1", stale), "already removed")
+	_, err := stale.Exec()
+	assert.EqualError(t, err, "sql: statement is closed")
+
+	stats := cache.Stats()
+	assert.Equal(t, 0, stats.Size)
+	assert.Equal(t, 0, stats.Pinned)
+	assert.Equal(t, uint64(0), stats.Evictions)
+
+	fresh := createTestStmt(t, db, "SELECT 1")
+	cache.Set("SELECT 1", fresh)
+	assert.False(t, cache.Invalidate("SELECT 1", stale), "a newer statement is left alone")
+	got, found := cache.Get("SELECT 1")
+	assert.True(t, found)
+	assert.Same(t, fresh, got)
+}
+
 func TestStmtCache_Concurrent(t *testing.T) {
 	db := setupTestDB(t)
 	cache := NewStmtCacheWithCapacity(100)
@@ -367,3 +482,92 @@ func TestStmtCache_SingleItemCache(t *testing.T) {
 	_, found = cache.Get("query2")
 	assert.True(t, found)
 }
+
+func TestNewStmtCacheWithShards(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		shards   int
+		expected int
+	}{
+		{"small cache uses one shard", 100, 0, 1},
+		{"default capacity", 1000, 0, 8},
+		{"large cache capped", 100000, 0, MaxStmtCacheShards},
+		{"explicit", 1000, 4, 4},
+		{"rounded up to power of two", 1000, 5, 8},
+		{"explicit above default max", 1000, 64, 64},
+		{"capped at capacity", 3, 16, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewStmtCacheWithShards(tt.capacity, tt.shards)
+			assert.Equal(t, tt.expected, cache.Stats().Shards)
+
+			total := 0
+			for _, s := range cache.shards {
+				total += s.capacity
+			}
+			assert.Equal(t, tt.capacity, total)
+		})
+	}
+}
+
+func TestStmtCache_Sharded(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewStmtCacheWithShards(64, 4)
+
+	for i := 0; i < 40; i++ {
+		cache.Set(fmt.Sprintf("query_%d", i), createTestStmt(t, db, "SELECT 1"))
+	}
+	used := 0
+	for _, s := range cache.shards {
+		if s.lruList.Len() > 0 {
+			used++
+		}
+	}
+	assert.Greater(t, used, 1, "keys should spread over shards")
+
+	cache.Pin("query_3")
+	cache.Get("query_0")
+
+	stats := cache.Stats()
+	assert.Equal(t, 40, stats.Size)
+	assert.Equal(t, 1, stats.Pinned)
+	assert.True(t, cache.IsPinned("query_3"))
+
+	entries := cache.Entries()
+	require.Len(t, entries, 40)
+	assert.Equal(t, "query_0", entries[0].SQL)
+	assert.Equal(t, "query_39", entries[1].SQL)
+	for i := 1; i < len(entries); i++ {
+		assert.False(t, entries[i].LastUsed.After(entries[i-1].LastUsed))
+	}
+
+	removed := cache.RemoveIf(func(key string) bool { return strings.HasSuffix(key, "3") })
+	assert.Equal(t, 4, removed) // query_3, 13, 23, 33
+	assert.Equal(t, 0, cache.Stats().Pinned)
+
+	cache.Clear()
+	assert.Equal(t, 0, cache.Stats().Size)
+}
+
+func TestStmtCache_ShardedEviction(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewStmtCacheWithShards(16, 4)
+
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("query_%d", i), createTestStmt(t, db, "SELECT 1"))
+	}
+
+	stats := cache.Stats()
+	assert.Equal(t, 16, stats.Size)
+	assert.Equal(t, uint64(84), stats.Evictions)
+	for _, s := range cache.shards {
+		assert.LessOrEqual(t, s.lruList.Len(), s.capacity)
+	}
+
+	// The most recent key is always kept.
+	_, found := cache.Get("query_99")
+	assert.True(t, found)
+}
@@ -480,3 +480,100 @@ func TestSelectQuery_Having_WithWhere(t *testing.T) {
 	assert.Less(t, whereIdx, groupIdx, "WHERE before GROUP BY")
 	assert.Less(t, groupIdx, havingIdx, "GROUP BY before HAVING")
 }
+
+// TestSelectQuery_OrHaving tests HAVING conditions combined with OR, with WHERE params renumbered first
+func TestSelectQuery_OrHaving(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	query := qb.Select("user_id", "COUNT(*)").
+		From("messages").
+		Where("status = ?", 1).
+		OrWhere(Eq("status", 2)).
+		GroupBy("user_id").
+		Having("COUNT(*) > ?", 100).
+		OrHaving(GreaterThan("user_id", 50))
+
+	q := query.Build()
+	require.NotNil(t, q)
+	require.NoError(t, q.prepErr)
+
+	assert.Contains(t, q.sql, `WHERE (status = $1) OR ("status" = $2)`)
+	assert.Contains(t, q.sql, `HAVING (COUNT(*) > $3) OR ("user_id" > $4)`)
+	assert.Equal(t, []interface{}{1, 2, 100, 50}, q.params)
+}
+
+// TestSelectQuery_OrHaving_ThenAndHaving tests that later AND conditions keep the OR group together
+func TestSelectQuery_OrHaving_ThenAndHaving(t *testing.T) {
+	db := mockDB("mysql")
+	qb := &QueryBuilder{db: db}
+
+	query := qb.Select("user_id").
+		From("messages").
+		GroupBy("user_id").
+		Having("COUNT(*) > ?", 100).
+		OrHaving("SUM(size) > {:size}", Params{"size": 5000}).
+		AndHaving("MAX(size) < ?", 900)
+
+	q := query.Build()
+	require.NotNil(t, q)
+	require.NoError(t, q.prepErr)
+
+	assert.Contains(t, q.sql, "HAVING ((COUNT(*) > ?) OR (SUM(size) > ?)) AND MAX(size) < ?")
+	assert.Equal(t, []interface{}{100, 5000, 900}, q.params)
+}
+
+// TestSelectQuery_OrHaving_First tests OrHaving without prior HAVING behaves like Having
+func TestSelectQuery_OrHaving_First(t *testing.T) {
+	db := mockDB("sqlite")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("user_id").
+		From("messages").
+		GroupBy("user_id").
+		OrHaving("COUNT(*) > ?", 1).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Contains(t, q.sql, "HAVING COUNT(*) > ?")
+	assert.NotContains(t, q.sql, "OR")
+}
+
+// TestSelectQuery_OrHaving_InvalidType tests that invalid conditions are reported at build time
+func TestSelectQuery_OrHaving_InvalidType(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("user_id").
+		From("messages").
+		GroupBy("user_id").
+		Having("COUNT(*) > ?", 1).
+		OrHaving(42).
+		Build()
+
+	require.Error(t, q.prepErr)
+	assert.Contains(t, q.prepErr.Error(), "OrHaving() expects string or Expression")
+}
+
+// TestSelectQuery_Having_InvalidExpression tests that an invalid HAVING expression
+// fails the build like an invalid WHERE expression does
+func TestSelectQuery_Having_InvalidExpression(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("user_id").
+		From("messages").
+		GroupBy("user_id").
+		Having(Cast("user_id", "bad;type").Eq(1)).
+		Build()
+	require.Error(t, q.prepErr, "an invalid expression must not drop the HAVING condition")
+	assert.ErrorContains(t, q.prepErr, "Cast: invalid type name")
+
+	q = qb.Select("user_id").
+		From("messages").
+		GroupBy("user_id").
+		Having("COUNT(*) > ?", 1).
+		OrHaving(Not(And(Eq("user_id", 1), DateTrunc("week", "created_at").Eq("2026-01-01")))).
+		Build()
+	assert.ErrorContains(t, q.prepErr, "DateTrunc: unsupported unit")
+}
@@ -0,0 +1,399 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/coregx/relica/internal/security"
+)
+
+// ============================================================================
+// Audit log table
+// ============================================================================
+//
+// WithAuditTable stores audit events in a relica-managed table of the audited
+// database itself, so the audit history can be queried with SQL without
+// shipping logs elsewhere. Events are buffered in memory and inserted in
+// batches by a background goroutine, so auditing adds no round trip to the
+// audited query. Close flushes what is left.
+//
+// The buffer is bounded: when the database cannot keep up (or is down),
+// events beyond the buffer size are dropped and a warning with their count is
+// logged on the next flush. The audit inserts themselves bypass the
+// validator and are not audited.
+
+// DefaultAuditTable is the default name of the audit log table.
+const DefaultAuditTable = "relica_audit_log"
+
+// Defaults of the audit log table options.
+const (
+	defaultAuditBatchSize     = 100
+	defaultAuditBufferSize    = 10000
+	defaultAuditFlushInterval = time.Second
+	auditPruneInterval        = time.Hour
+)
+
+// AuditRecord is a row of the audit log table.
+//
+// Example:
+//
+//	var records []relica.AuditRecord
+//	err := db.Select().From(relica.DefaultAuditTable).
+//	    Where(relica.Eq("table_name", "accounts")).
+//	    OrderBy("occurred_at DESC").Limit(50).
+//	    All(&records)
+type AuditRecord struct {
+	ID           int64     `db:"id"`
+	OccurredAt   time.Time `db:"occurred_at"`
+	User         string    `db:"user_name"`
+	Operation    string    `db:"operation"`
+	Table        string    `db:"table_name"`
+	AffectedRows int64     `db:"affected_rows"`
+	Statement    string    `db:"statement"`
+	ParamsHash   string    `db:"params_hash"`
+	ClientIP     string    `db:"client_ip"`
+	RequestID    string    `db:"request_id"`
+	Tag          string    `db:"tag"`
+	Success      bool      `db:"success"`
+	Error        string    `db:"error"`
+	DurationMs   int64     `db:"duration_ms"`
+}
+
+// auditColumns are the columns the audit log table is written with.
+var auditColumns = []string{
+	"occurred_at", "user_name", "operation", "table_name", "affected_rows", "statement",
+	"params_hash", "client_ip", "request_id", "tag", "success", "error", "duration_ms",
+}
+
+// AuditTableOption configures WithAuditTable.
+type AuditTableOption func(*auditTable)
+
+// AuditTableBatchSize sets the number of events inserted per statement; a
+// full batch is flushed without waiting for the flush interval. Default 100.
+func AuditTableBatchSize(n int) AuditTableOption {
+	return func(t *auditTable) {
+		if n > 0 {
+			t.batchSize = n
+		}
+	}
+}
+
+// AuditTableFlushInterval sets how often buffered events are written.
+// Default 1s.
+func AuditTableFlushInterval(d time.Duration) AuditTableOption {
+	return func(t *auditTable) {
+		if d > 0 {
+			t.interval = d
+		}
+	}
+}
+
+// AuditTableBufferSize sets the maximum number of events waiting to be
+// written; further events are dropped. Default 10000.
+func AuditTableBufferSize(n int) AuditTableOption {
+	return func(t *auditTable) {
+		if n > 0 {
+			t.bufferSize = n
+		}
+	}
+}
+
+// AuditTableRetention deletes records older than d once an hour (see
+// PruneAuditLog). Zero, the default, keeps records forever.
+func AuditTableRetention(d time.Duration) AuditTableOption {
+	return func(t *auditTable) {
+		t.retention = d
+	}
+}
+
+// AuditTableLogger also logs the audit events to logger, like an auditor
+// passed to WithAuditLog.
+func AuditTableLogger(logger *slog.Logger) AuditTableOption {
+	return func(t *auditTable) {
+		t.logger = logger
+	}
+}
+
+// WithAuditTable audits operations at level into the table (DefaultAuditTable
+// if table is ""). It replaces an auditor set with WithAuditLog. Create the
+// table with CreateAuditTable.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithAuditTable("", relica.AuditWrites, relica.AuditTableRetention(90*24*time.Hour)))
+//	if err := db.CreateAuditTable(ctx); err != nil {
+//	    return err
+//	}
+func WithAuditTable(table string, level security.AuditLevel, opts ...AuditTableOption) Option {
+	return optionFunc(func(db *DB) {
+		if table == "" {
+			table = DefaultAuditTable
+		}
+		t := &auditTable{
+			db:         db,
+			table:      table,
+			batchSize:  defaultAuditBatchSize,
+			bufferSize: defaultAuditBufferSize,
+			interval:   defaultAuditFlushInterval,
+			wake:       make(chan struct{}, 1),
+			stop:       make(chan struct{}),
+			done:       make(chan struct{}),
+		}
+		for _, opt := range opts {
+			opt(t)
+		}
+		db.auditTable = t
+		db.auditor = security.NewAuditor(t.logger, level, t)
+	})
+}
+
+// auditTable buffers audit events and writes them to the audit log table.
+type auditTable struct {
+	db         *DB
+	table      string
+	batchSize  int
+	bufferSize int
+	interval   time.Duration
+	retention  time.Duration
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	pending []security.AuditEvent
+	dropped int64
+
+	flushMu   sync.Mutex // serializes flushes
+	lastPrune time.Time  // guarded by flushMu
+
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	started   bool // guarded by mu
+}
+
+// WriteAuditEvent buffers event; it implements security.AuditSink.
+func (t *auditTable) WriteAuditEvent(event security.AuditEvent) {
+	if strings.EqualFold(event.Table, t.table) {
+		return // the table's own maintenance
+	}
+	t.startOnce.Do(t.start)
+
+	t.mu.Lock()
+	if len(t.pending) >= t.bufferSize {
+		t.dropped++
+		t.mu.Unlock()
+		return
+	}
+	t.pending = append(t.pending, event)
+	full := len(t.pending) >= t.batchSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// start starts the background writer.
+func (t *auditTable) start() {
+	t.mu.Lock()
+	t.started = true
+	t.mu.Unlock()
+	go t.run()
+}
+
+// run writes buffered events every interval, or earlier when a batch is
+// full, until shutdown.
+func (t *auditTable) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		case <-t.wake:
+		}
+		if err := t.flush(context.Background()); err != nil {
+			t.db.logger.Error("audit log flush failed", "error", err)
+		}
+	}
+}
+
+// shutdown stops the background writer, if it was started.
+func (t *auditTable) shutdown() {
+	t.mu.Lock()
+	started := t.started
+	t.started = false
+	t.mu.Unlock()
+	if started {
+		close(t.stop)
+		<-t.done
+	}
+}
+
+// flush writes the buffered events in batches and prunes expired records
+// when due. Events of a failed batch are put back for the next flush.
+func (t *auditTable) flush(ctx context.Context) error {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.mu.Lock()
+	events, dropped := t.pending, t.dropped
+	t.pending, t.dropped = nil, 0
+	t.mu.Unlock()
+	if dropped > 0 {
+		t.db.logger.Warn("audit events dropped; the audit log table is not keeping up",
+			"table", t.table,
+			"dropped", dropped,
+		)
+	}
+
+	w := t.writer()
+	for len(events) > 0 {
+		n := min(t.batchSize, len(events))
+		q := w.Builder().WithContext(ctx).BatchInsert(t.table, auditColumns)
+		for _, e := range events[:n] {
+			q.Values(e.Timestamp, e.User, e.Operation, e.Table, e.AffectedRows, e.SQL,
+				e.ParamsHash, e.ClientIP, e.RequestID, e.Tag, e.Success, e.Error, e.Duration)
+		}
+		if _, err := q.Execute(); err != nil {
+			t.requeue(events)
+			return fmt.Errorf("relica: write audit log: %w", err)
+		}
+		events = events[n:]
+	}
+
+	if t.retention > 0 && time.Since(t.lastPrune) >= auditPruneInterval {
+		t.lastPrune = time.Now()
+		if _, err := t.prune(ctx, time.Now().Add(-t.retention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requeue puts unwritten events back in front of the buffer, dropping the
+// newest ones beyond the buffer size.
+func (t *auditTable) requeue(events []security.AuditEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	merged := append(events, t.pending...)
+	if over := len(merged) - t.bufferSize; over > 0 {
+		merged = merged[:t.bufferSize]
+		t.dropped += int64(over)
+	}
+	t.pending = merged
+}
+
+// prune deletes the records that occurred before cutoff.
+func (t *auditTable) prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := t.writer().Builder().WithContext(ctx).Delete(t.table).
+		Where(LessThan("occurred_at", cutoff.UTC())).
+		Build().Execute()
+	if err != nil {
+		return 0, fmt.Errorf("relica: prune audit log: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// writer returns the DB the audit table is written with: the audited DB
+// without its validator, since audited statements may well contain what the
+// validator blocks.
+func (t *auditTable) writer() *DB {
+	w := *t.db
+	w.validator = nil
+	w.auditor = nil
+	return &w
+}
+
+// CreateAuditTable creates the audit log table of WithAuditTable and its
+// index on occurred_at if they do not exist.
+func (db *DB) CreateAuditTable(ctx context.Context) error {
+	t := db.auditTable
+	if t == nil {
+		return ErrNoAuditTable
+	}
+	d := db.dialect
+	ts, id := "DATETIME", "INTEGER PRIMARY KEY AUTOINCREMENT"
+	switch d.(type) {
+	case *dialects.PostgresDialect:
+		ts, id = "TIMESTAMPTZ", "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY"
+	case *dialects.MySQLDialect:
+		ts, id = "DATETIME(6)", "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	}
+	q := d.QuoteIdentifier
+
+	index := q(t.table + "_occurred_at")
+	columns := q("id") + " " + id + ", " +
+		q("occurred_at") + " " + ts + " NOT NULL, " +
+		q("user_name") + " VARCHAR(255) NOT NULL, " +
+		q("operation") + " VARCHAR(32) NOT NULL, " +
+		q("table_name") + " VARCHAR(255) NOT NULL, " +
+		q("affected_rows") + " BIGINT NOT NULL, " +
+		q("statement") + " TEXT NOT NULL, " +
+		q("params_hash") + " VARCHAR(64) NOT NULL, " +
+		q("client_ip") + " VARCHAR(64) NOT NULL, " +
+		q("request_id") + " VARCHAR(255) NOT NULL, " +
+		q("tag") + " VARCHAR(255) NOT NULL, " +
+		q("success") + " BOOLEAN NOT NULL, " +
+		q("error") + " TEXT NOT NULL, " +
+		q("duration_ms") + " BIGINT NOT NULL"
+
+	var ddl []string
+	if _, ok := d.(*dialects.MySQLDialect); ok {
+		// MySQL has no CREATE INDEX IF NOT EXISTS; declare the index with the table
+		ddl = []string{"CREATE TABLE IF NOT EXISTS " + q(t.table) + " (" + columns +
+			", INDEX " + index + " (" + q("occurred_at") + "))"}
+	} else {
+		ddl = []string{
+			"CREATE TABLE IF NOT EXISTS " + q(t.table) + " (" + columns + ")",
+			"CREATE INDEX IF NOT EXISTS " + index + " ON " + q(t.table) + " (" + q("occurred_at") + ")",
+		}
+	}
+
+	w := t.writer()
+	for _, stmt := range ddl {
+		if _, err := w.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("relica: create audit table: %w", err)
+		}
+	}
+	return nil
+}
+
+// FlushAuditLog writes the buffered audit events now instead of waiting for
+// the background writer.
+func (db *DB) FlushAuditLog(ctx context.Context) error {
+	if db.auditTable == nil {
+		return ErrNoAuditTable
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.auditTable.flush(ctx)
+}
+
+// PruneAuditLog deletes the audit records that occurred before cutoff and
+// returns their number. AuditTableRetention does this periodically.
+//
+// Example:
+//
+//	n, err := db.PruneAuditLog(ctx, time.Now().AddDate(0, 0, -90))
+func (db *DB) PruneAuditLog(ctx context.Context, cutoff time.Time) (int64, error) {
+	if db.auditTable == nil {
+		return 0, ErrNoAuditTable
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.auditTable.prune(ctx, cutoff)
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditTableDB(t *testing.T, dsn string, opts ...AuditTableOption) *DB {
+	t.Helper()
+	opts = append([]AuditTableOption{AuditTableFlushInterval(time.Hour)}, opts...)
+	db, err := Open("sqlite", dsn,
+		WithValidator(security.NewValidator()),
+		WithAuditTable("", security.AuditWrites, opts...))
+	require.NoError(t, err)
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	require.NoError(t, db.CreateAuditTable(ctx))
+	require.NoError(t, db.CreateAuditTable(ctx), "creating the table twice is harmless")
+	_, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS accounts (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	return db
+}
+
+func auditRecords(t *testing.T, db *DB) []AuditRecord {
+	t.Helper()
+	var records []AuditRecord
+	require.NoError(t, db.Builder().Select().From(DefaultAuditTable).OrderBy("id").All(&records))
+	return records
+}
+
+func TestAuditTable_WritesEvents(t *testing.T) {
+	db := setupAuditTableDB(t, ":memory:")
+	defer db.Close()
+	ctx := security.WithRequestID(security.WithUser(context.Background(), "alice"), "req-1")
+
+	_, err := db.ExecContext(ctx, "INSERT INTO accounts (id, name) VALUES (?, ?)", 1, "ann")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "SELECT * FROM accounts WHERE name = '' OR 1=1 --'")
+	require.Error(t, err, "blocked by the validator")
+	assert.Empty(t, auditRecords(t, db), "events are buffered")
+
+	require.NoError(t, db.FlushAuditLog(ctx))
+	records := auditRecords(t, db)
+	require.Len(t, records, 2)
+
+	insert := records[0]
+	assert.Equal(t, "INSERT", insert.Operation)
+	assert.Equal(t, "accounts", insert.Table)
+	assert.Equal(t, "alice", insert.User)
+	assert.Equal(t, "req-1", insert.RequestID)
+	assert.Equal(t, int64(1), insert.AffectedRows)
+	assert.NotEmpty(t, insert.ParamsHash)
+	assert.True(t, insert.Success)
+	assert.WithinDuration(t, time.Now(), insert.OccurredAt, time.Minute)
+
+	blocked := records[1]
+	assert.Equal(t, "query_blocked", blocked.Operation)
+	assert.Contains(t, blocked.Statement, "OR 1=1", "stored despite the validator")
+	assert.False(t, blocked.Success)
+	assert.NotEmpty(t, blocked.Error)
+}
+
+func TestAuditTable_BatchSizeWakesWriter(t *testing.T) {
+	db := setupAuditTableDB(t, ":memory:", AuditTableBatchSize(2))
+	defer db.Close()
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		_, err := db.ExecContext(ctx, "INSERT INTO accounts (id) VALUES (?)", i)
+		require.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		var n int
+		_ = db.Builder().Select("COUNT(*)").From(DefaultAuditTable).Row(&n)
+		return n == 2
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestAuditTable_BufferOverflowAndPrune(t *testing.T) {
+	db := setupAuditTableDB(t, ":memory:", AuditTableBufferSize(2))
+	defer db.Close()
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		_, err := db.ExecContext(ctx, "INSERT INTO accounts (id) VALUES (?)", i)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(1), db.auditTable.dropped)
+	require.NoError(t, db.FlushAuditLog(ctx))
+	require.Len(t, auditRecords(t, db), 2)
+
+	n, err := db.PruneAuditLog(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = db.PruneAuditLog(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Empty(t, auditRecords(t, db))
+}
+
+func TestAuditTable_CloseFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.db")
+	db := setupAuditTableDB(t, path)
+	_, err := db.ExecContext(context.Background(), "INSERT INTO accounts (id) VALUES (1)")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Len(t, auditRecords(t, db), 1)
+}
+
+func TestAuditTable_NotEnabled(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	assert.ErrorIs(t, db.CreateAuditTable(ctx), ErrNoAuditTable)
+	assert.ErrorIs(t, db.FlushAuditLog(ctx), ErrNoAuditTable)
+	_, err = db.PruneAuditLog(ctx, time.Now())
+	assert.ErrorIs(t, err, ErrNoAuditTable)
+}
@@ -281,6 +281,7 @@ func TestDetectOperation(t *testing.T) {
 		{"UPDATE users SET name = ?", "UPDATE"},
 		{"DELETE FROM users WHERE id = ?", "DELETE"},
 		{"SELECT * FROM users", "SELECT"},
+		{"MERGE INTO users USING staged ON users.id = staged.id WHEN MATCHED THEN DELETE", "MERGE"},
 		{"CREATE TABLE test (id INT)", "CREATE"},
 		{"DROP TABLE test", "DROP"},
 		{"ALTER TABLE users ADD COLUMN age INT", "ALTER"},
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ============================================================================
+// BatchDelete
+// ============================================================================
+//
+// Deleting millions of rows with one DELETE holds its locks, and grows the
+// undo log or WAL, until the last row is gone. BatchDelete deletes a key list
+// in chunks instead, one "DELETE ... WHERE key IN (...)" statement per chunk,
+// each committed on its own outside a transaction, with an optional pause
+// between chunks so replicas and concurrent writers keep up.
+
+// BatchDeleteQuery deletes rows by key in chunks (see QueryBuilder.BatchDelete).
+type BatchDeleteQuery struct {
+	builder   *QueryBuilder
+	table     string
+	keyColumn string
+	keys      []interface{}
+	chunkSize int
+	sleep     time.Duration
+	progress  func(BatchDeleteProgress)
+	ctx       context.Context // context for this specific query
+}
+
+// BatchDeleteProgress reports the progress of a BatchDelete after a chunk.
+type BatchDeleteProgress struct {
+	Deleted int64 // rows deleted so far
+	Keys    int   // keys processed so far
+	Total   int   // keys to process
+}
+
+// BatchDelete creates a query deleting the rows of table whose keyColumn is
+// one of the keys given to Keys, in chunks of ChunkSize keys (default
+// DefaultInChunkSize). Outside a transaction every chunk is committed on its
+// own, so the table is never locked for the whole cleanup.
+//
+// Example:
+//
+//	n, err := db.Builder().BatchDelete("events", "id").
+//	    Keys(ids...).
+//	    ChunkSize(5000).
+//	    Sleep(100 * time.Millisecond).
+//	    Execute()
+func (qb *QueryBuilder) BatchDelete(table, keyColumn string) *BatchDeleteQuery {
+	return &BatchDeleteQuery{builder: qb, table: table, keyColumn: keyColumn}
+}
+
+// WithContext sets the context for this query.
+// This overrides any context set on the QueryBuilder.
+func (bq *BatchDeleteQuery) WithContext(ctx context.Context) *BatchDeleteQuery {
+	bq.ctx = ctx
+	return bq
+}
+
+// Keys adds keys of the rows to delete. A slice argument (other than
+// []byte) adds its elements, so Keys(ids) works for an []int64.
+func (bq *BatchDeleteQuery) Keys(keys ...interface{}) *BatchDeleteQuery {
+	for _, key := range keys {
+		if v := reflect.ValueOf(key); v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			vals, _ := toInterfaceSlice(key)
+			bq.keys = append(bq.keys, vals...)
+			continue
+		}
+		bq.keys = append(bq.keys, key)
+	}
+	return bq
+}
+
+// ChunkSize sets the number of keys deleted per statement
+// (default DefaultInChunkSize).
+func (bq *BatchDeleteQuery) ChunkSize(n int) *BatchDeleteQuery {
+	if n > 0 {
+		bq.chunkSize = n
+	}
+	return bq
+}
+
+// Sleep pauses for d between chunks. The pause ends early, with the
+// context's error, when the context is canceled.
+func (bq *BatchDeleteQuery) Sleep(d time.Duration) *BatchDeleteQuery {
+	bq.sleep = d
+	return bq
+}
+
+// Progress calls fn after every chunk with the totals so far.
+func (bq *BatchDeleteQuery) Progress(fn func(BatchDeleteProgress)) *BatchDeleteQuery {
+	bq.progress = fn
+	return bq
+}
+
+// Execute deletes the rows and returns the number of rows deleted. Without
+// keys nothing is executed. If a chunk fails, the rows deleted by the
+// previous chunks stay deleted and their count is returned with the error,
+// so the cleanup can be resumed.
+func (bq *BatchDeleteQuery) Execute() (int64, error) {
+	ctx := bq.ctx
+	if ctx == nil {
+		ctx = bq.builder.ctx
+	}
+	if bq.keyColumn == "" {
+		return 0, fmt.Errorf("relica: BatchDelete %s requires a key column", bq.table)
+	}
+
+	size := bq.chunkSize
+	if size <= 0 {
+		size = DefaultInChunkSize
+	}
+
+	var deleted int64
+	for start := 0; start < len(bq.keys); start += size {
+		if start > 0 && bq.sleep > 0 {
+			if err := sleepContext(ctx, bq.sleep); err != nil {
+				return deleted, err
+			}
+		}
+
+		end := min(start+size, len(bq.keys))
+		res, err := bq.builder.Delete(bq.table).
+			Where(In(bq.keyColumn, bq.keys[start:end]...)).
+			WithContext(ctx).
+			Build().Execute()
+		if err != nil {
+			return deleted, fmt.Errorf("relica: BatchDelete %s: %w", bq.table, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("relica: BatchDelete %s: %w", bq.table, err)
+		}
+		deleted += n
+
+		if bq.progress != nil {
+			bq.progress(BatchDeleteProgress{Deleted: deleted, Keys: end, Total: len(bq.keys)})
+		}
+	}
+	return deleted, nil
+}
+
+// sleepContext waits for d or until ctx (which may be nil) is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if ctx == nil {
+		time.Sleep(d)
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openBatchDeleteDB returns a SQLite DB with an events table of ids 1 to n.
+func openBatchDeleteDB(t *testing.T, n int) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE events (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	bq := db.Builder().BatchInsert("events", []string{"id"})
+	for i := 1; i <= n; i++ {
+		bq.Values(i)
+	}
+	_, err = bq.Execute()
+	require.NoError(t, err)
+	return db
+}
+
+func countEvents(t *testing.T, db *DB) int {
+	t.Helper()
+	var n int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM events").Row(&n))
+	return n
+}
+
+func TestBatchDelete_Execute(t *testing.T) {
+	db := openBatchDeleteDB(t, 100)
+
+	ids := make([]int64, 0, 60)
+	for i := int64(1); i <= 60; i++ {
+		ids = append(ids, i)
+	}
+	var progress []BatchDeleteProgress
+	n, err := db.Builder().BatchDelete("events", "id").
+		Keys(ids, 1000). // 1000 does not exist
+		ChunkSize(25).
+		Progress(func(p BatchDeleteProgress) { progress = append(progress, p) }).
+		Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), n)
+	assert.Equal(t, 40, countEvents(t, db))
+	assert.Equal(t, []BatchDeleteProgress{
+		{Deleted: 25, Keys: 25, Total: 61},
+		{Deleted: 50, Keys: 50, Total: 61},
+		{Deleted: 60, Keys: 61, Total: 61},
+	}, progress)
+}
+
+func TestBatchDelete_NoKeys(t *testing.T) {
+	db := openBatchDeleteDB(t, 3)
+
+	n, err := db.Builder().BatchDelete("events", "id").Execute()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, 3, countEvents(t, db))
+
+	_, err = db.Builder().BatchDelete("events", "").Keys(1).Execute()
+	assert.Error(t, err)
+}
+
+func TestBatchDelete_SleepCanceled(t *testing.T) {
+	db := openBatchDeleteDB(t, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n, err := db.Builder().BatchDelete("events", "id").
+		Keys(1, 2, 3, 4).
+		ChunkSize(2).
+		Sleep(time.Hour).
+		Progress(func(BatchDeleteProgress) { cancel() }).
+		WithContext(ctx).
+		Execute()
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(2), n, "rows of the first chunk stay deleted")
+	assert.Equal(t, 8, countEvents(t, db))
+}
+
+func TestBatchDelete_InTransaction(t *testing.T) {
+	db := openBatchDeleteDB(t, 10)
+
+	err := db.Transactional(context.Background(), func(tx *Tx) error {
+		n, err := tx.Builder().BatchDelete("events", "id").Keys(1, 2, 3).ChunkSize(1).Execute()
+		assert.Equal(t, int64(3), n)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, countEvents(t, db))
+}
@@ -464,3 +464,39 @@ func TestBatchInsertIntegration_MixedOperations(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 2, productCount)
 }
+
+// TestBatchUpdateIntegration_LockOrdered tests that LockOrdered batches update
+// the rows with and without an enclosing transaction.
+func TestBatchUpdateIntegration_LockOrdered(t *testing.T) {
+	db := setupBatchTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `INSERT INTO users (name, status) VALUES ('Alice', 'pending'), ('Bob', 'pending'), ('Carol', 'pending')`)
+	require.NoError(t, err)
+
+	result, err := db.Builder().BatchUpdate("users", "id").
+		Set(3, map[string]interface{}{"status": "closed"}).
+		Set(1, map[string]interface{}{"status": "active"}).
+		LockOrdered().
+		Execute()
+	require.NoError(t, err)
+	rows, err := result.(sql.Result).RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.Builder().BatchUpdate("users", "id").
+			Set(2, map[string]interface{}{"status": "active"}).
+			LockOrdered().
+			Execute()
+		return err
+	})
+	require.NoError(t, err)
+
+	var statuses []string
+	require.NoError(t, db.NewQuery("SELECT status FROM users ORDER BY id").Column(&statuses))
+	assert.Equal(t, []string{"active", "active", "closed"}, statuses)
+
+	_, err = db.Builder().BatchUpdate("users", "id").LockOrdered().Execute()
+	assert.ErrorContains(t, err, "no updates to apply")
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -532,3 +533,43 @@ func findIndex(s, substr string) int {
 	}
 	return -1
 }
+
+// TestBatchUpdate_LockOrdered tests that LockOrdered sorts the batch by key.
+func TestBatchUpdate_LockOrdered(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.BatchUpdate("accounts", "id").
+		Set(7, map[string]interface{}{"balance": 70}).
+		Set(3, map[string]interface{}{"balance": 30}).
+		Set(5, map[string]interface{}{"balance": 50}).
+		LockOrdered().
+		Build()
+	require.NoError(t, q.prepErr)
+
+	expected := `UPDATE "accounts" SET "balance" = CASE "id" WHEN $1 THEN $2 WHEN $3 THEN $4 WHEN $5 THEN $6 ELSE "balance" END WHERE "id" IN ($7, $8, $9)`
+	assert.Equal(t, expected, q.sql)
+	assert.Equal(t, []interface{}{3, 30, 5, 50, 7, 70, 3, 5, 7}, q.params)
+}
+
+// TestCompareKeys tests the ordering of batch key values.
+func TestCompareKeys(t *testing.T) {
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		a, b interface{}
+		want int
+	}{
+		{2, 10, -1},
+		{int64(10), 2, 1},
+		{uint(3), uint64(3), 0},
+		{1.5, float32(0.5), 1},
+		{"b", "a", 1},
+		{"10", "9", -1}, // strings compare lexically
+		{t1, t1.Add(time.Second), -1},
+		{[]byte{1, 2}, []byte{1, 3}, -1},
+		{1, "1", 0}, // mixed types fall back to formatted text
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareKeys(tt.a, tt.b), "compareKeys(%v, %v)", tt.a, tt.b)
+	}
+}
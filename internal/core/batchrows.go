@@ -0,0 +1,235 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coregx/relica/internal/util"
+)
+
+// ============================================================================
+// Batch processing
+// ============================================================================
+//
+// BatchRows (and TypedQuery.Batch) walk a large result in batches using keyset
+// pagination: each batch is a separate query
+//
+//	SELECT ... WHERE <conditions> AND key > <last key> ORDER BY key LIMIT size
+//
+// so no transaction or server-side cursor is held open between batches, the
+// callback may take as long as it needs, and every batch query runs with its
+// own deadline (BatchTimeout). The key must be unique and is usually the
+// primary key; rows inserted behind the current position are not visited.
+
+// BatchOption configures batch processing.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	key     string
+	timeout time.Duration
+}
+
+// BatchKey sets the unique, ordered column to paginate by (default: the
+// primary key of T, or "id"). Qualify it ("u.id") for joined queries.
+func BatchKey(col string) BatchOption {
+	return func(c *batchConfig) {
+		c.key = col
+	}
+}
+
+// BatchTimeout gives each batch query its own deadline of d, derived from
+// the query's context. The callback is not subject to it.
+func BatchTimeout(d time.Duration) BatchOption {
+	return func(c *batchConfig) {
+		c.timeout = d
+	}
+}
+
+// BatchRows executes sq in batches of up to size rows, scanning each batch
+// into []T and passing it to fn. Processing stops at the first error returned
+// by fn or a query, which is returned. sq must not have ORDER BY, LIMIT,
+// OFFSET or set operations; batches are ordered by the key column.
+//
+// Example:
+//
+//	err := relica.BatchRows(db.Select().From("events").Where("archived = ?", false), 1000,
+//	    func(batch []Event) error {
+//	        return archive(batch)
+//	    }, relica.BatchTimeout(30*time.Second))
+func BatchRows[T any](sq *SelectQuery, size int, fn func(batch []T) error, opts ...BatchOption) error {
+	return batchRows(sq, size, fn, nil, opts)
+}
+
+// Batch processes the matching rows in batches of up to size rows. See BatchRows.
+//
+// Example:
+//
+//	err := relica.Q[User](db).Where("active = ?", true).
+//	    Batch(ctx, 500, func(users []User) error {
+//	        return reindex(users)
+//	    })
+func (q *TypedQuery[T]) Batch(ctx context.Context, size int, fn func(batch []T) error, opts ...BatchOption) error {
+	return batchRows(q.sq.WithContext(ctx), size, fn, q.mapper, opts)
+}
+
+// batchRows implements BatchRows; mapper, if set, builds rows instead of the scanner.
+//
+//nolint:gocognit,cyclop // Validation and the pagination loop read best together.
+func batchRows[T any](sq *SelectQuery, size int, fn func(batch []T) error, mapper RowMapper[T], opts []BatchOption) error {
+	if sq.buildErr != nil {
+		return sq.buildErr
+	}
+	if size <= 0 {
+		return fmt.Errorf("relica: Batch size must be positive, got %d", size)
+	}
+	if len(sq.orderBy) > 0 || len(sq.orderByExprs) > 0 || len(sq.subOrderByExprs) > 0 ||
+		sq.limitValue != nil || sq.offsetValue != nil || len(sq.unions) > 0 || sq.chunkedIn != nil {
+		return errors.New("relica: Batch orders and limits by the key column; " +
+			"remove OrderBy, Limit, Offset, set operations and WhereInChunked")
+	}
+
+	cfg := batchConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.key == "" {
+		cfg.key = defaultBatchKey[T]()
+	}
+	keyName := cfg.key
+	if i := strings.LastIndex(keyName, "."); i >= 0 {
+		keyName = keyName[i+1:]
+	}
+
+	// keyOf returns the key of the last row of a batch.
+	var keyOf func(batch []T) (interface{}, error)
+	var lastRaw interface{}
+	if mapper != nil {
+		inner := mapper
+		mapper = func(raw RowData) (T, error) {
+			v, ok := raw.Value(keyName)
+			if !ok {
+				var zero T
+				return zero, fmt.Errorf("relica: Batch key column %q is not selected", keyName)
+			}
+			lastRaw = v
+			return inner(raw)
+		}
+		keyOf = func([]T) (interface{}, error) { return lastRaw, nil }
+	} else {
+		index, err := batchKeyField[T](keyName)
+		if err != nil {
+			return err
+		}
+		keyOf = func(batch []T) (interface{}, error) {
+			v := reflect.Indirect(reflect.ValueOf(&batch[len(batch)-1]).Elem())
+			if !v.IsValid() {
+				return nil, errors.New("relica: Batch cannot read the key of a nil row")
+			}
+			return v.FieldByIndex(index).Interface(), nil
+		}
+	}
+
+	parent := sq.ctx
+	if parent == nil {
+		parent = sq.builder.ctx
+	}
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	var last interface{}
+	for first := true; ; first = false {
+		if err := parent.Err(); err != nil {
+			return err
+		}
+
+		page := sq.batchQuery(cfg.key, last, first, size)
+		ctx, cancel := parent, context.CancelFunc(func() {})
+		if cfg.timeout > 0 {
+			ctx, cancel = context.WithTimeout(parent, cfg.timeout)
+		}
+
+		var batch []T
+		var err error
+		if mapper != nil {
+			sink := &mapSink[T]{fn: mapper}
+			err = page.WithContext(ctx).All(sink)
+			batch = sink.out
+		} else {
+			err = page.WithContext(ctx).All(&batch)
+		}
+		cancel()
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		next, err := keyOf(batch)
+		if err != nil {
+			return err
+		}
+		if !first && reflect.DeepEqual(next, last) {
+			return fmt.Errorf("relica: Batch key %q did not advance; select it and make sure it is unique", cfg.key)
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < size {
+			return nil
+		}
+		last = next
+	}
+}
+
+// batchQuery returns a copy of sq restricted to the page after last.
+func (sq *SelectQuery) batchQuery(key string, last interface{}, first bool, size int) *SelectQuery {
+	c := *sq
+	c.where = append([]string(nil), sq.where...)
+	c.params = append([]interface{}(nil), sq.params...)
+	if !first {
+		c.Where(GreaterThan(key, last))
+	}
+	c.orderBy = []string{key}
+	return c.Limit(int64(size))
+}
+
+// defaultBatchKey returns the single primary key column of T, or "id".
+func defaultBatchKey[T any]() string {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Struct {
+		if pk, err := util.FindPrimaryKeyFields(reflect.New(typ)); err == nil && pk.IsSingle() {
+			return pk.Columns[0]
+		}
+	}
+	return "id"
+}
+
+// batchKeyField returns the field index of the key column in T.
+func batchKeyField[T any](key string) ([]int, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("relica: Batch requires a struct type or a row mapper, got %s", typ)
+	}
+	info, err := globalScanner.getStructInfo(typ)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range info.fields {
+		if f.dbName == strings.ToLower(key) {
+			return f.index, nil
+		}
+	}
+	return nil, fmt.Errorf("relica: Batch key column %q has no field in %s (see BatchKey)", key, typ)
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchRows(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 25, &queries)
+	queries = 0
+
+	var sizes []int
+	var ids []int64
+	err := BatchRows(db.Builder().Select().From("products").Where("price = ?", 1), 5,
+		func(batch []chunkedProduct) error {
+			sizes = append(sizes, len(batch))
+			for _, p := range batch {
+				ids = append(ids, p.ID)
+			}
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 5, 3}, sizes)
+	assert.Len(t, ids, 13)
+	assert.Equal(t, int64(1), ids[0])
+	assert.Equal(t, int64(25), ids[12])
+	assert.Equal(t, 3, queries, "a short batch ends processing without an extra query")
+}
+
+func TestBatchRows_SQL(t *testing.T) {
+	db := mockDB("postgres")
+	sq := db.Builder().Select("id").From("products").Where("price > ?", 10)
+
+	page, _ := sq.batchQuery("id", int64(40), false, 20).ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "products" WHERE price > $1 AND "id" > $2 ORDER BY "id" LIMIT 20`, page)
+
+	orig, params := sq.ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "products" WHERE price > $1`, orig, "the original query is not modified")
+	assert.Equal(t, []interface{}{10}, params)
+}
+
+func TestTypedQuery_Batch(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 10, &queries)
+	ctx := context.Background()
+
+	var seen int
+	err := NewTypedQuery[chunkedProduct](db.Builder()).Batch(ctx, 4, func(batch []chunkedProduct) error {
+		seen += len(batch)
+		return nil
+	}, BatchTimeout(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 10, seen)
+
+	// Exactly divisible result: the final empty page ends processing.
+	var batches int
+	err = NewTypedQuery[chunkedProduct](db.Builder()).Batch(ctx, 5, func([]chunkedProduct) error {
+		batches++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, batches)
+
+	// Row mappers read the key from the raw row.
+	var titles []string
+	err = NewTypedQuery[chunkedProduct](db.Builder()).
+		Map(func(raw RowData) (chunkedProduct, error) {
+			return chunkedProduct{Title: raw.String("title")}, nil
+		}).
+		Batch(ctx, 3, func(batch []chunkedProduct) error {
+			for _, p := range batch {
+				titles = append(titles, p.Title)
+			}
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8", "p9", "p10"}, titles)
+}
+
+func TestBatchRows_Errors(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 10, &queries)
+	noop := func([]chunkedProduct) error { return nil }
+
+	errStop := errors.New("stop")
+	var calls int
+	err := BatchRows(db.Builder().Select().From("products"), 2, func([]chunkedProduct) error {
+		calls++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+
+	err = BatchRows(db.Builder().Select().From("products").OrderBy("title"), 2, noop)
+	assert.ErrorContains(t, err, "remove OrderBy")
+
+	err = BatchRows(db.Builder().Select().From("products"), 0, noop)
+	assert.ErrorContains(t, err, "size must be positive")
+
+	err = BatchRows(db.Builder().Select().From("products"), 2, noop, BatchKey("sku"))
+	assert.ErrorContains(t, err, `key column "sku" has no field`)
+
+	err = BatchRows(db.Builder().Select("title").From("products"), 2, noop)
+	assert.ErrorContains(t, err, "did not advance")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = BatchRows(db.Builder().Select().From("products").WithContext(ctx), 2, noop)
+	assert.ErrorIs(t, err, context.Canceled)
+}
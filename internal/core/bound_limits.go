@@ -0,0 +1,77 @@
+package core
+
+import (
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Bound LIMIT and OFFSET
+// ============================================================================
+//
+// LIMIT and OFFSET are written into the SQL as literals by default, so every
+// page size and page number is a distinct statement: a paginated endpoint
+// fills the statement cache with one entry per page and evicts the statements
+// that are actually reused. With bound limits they are sent as parameters
+// instead, and one prepared statement serves every page:
+//
+//	SELECT * FROM "users" ORDER BY "id" LIMIT $1 OFFSET $2   -- [20, 40]
+
+// maxLimitValue is the LIMIT written when only OFFSET is set, as MySQL
+// requires LIMIT before OFFSET.
+const maxLimitValue = "9223372036854775807"
+
+// WithBoundLimits makes SELECT queries built through the DB send their LIMIT
+// and OFFSET values as parameters instead of literals, so queries that only
+// differ by page share one prepared statement (see SelectQuery.BindLimits).
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithBoundLimits())
+//
+//	db.Select("*").From("users").OrderBy("id").Limit(20).Offset(40).All(&users)
+//	// SELECT * FROM "users" ORDER BY "id" LIMIT $1 OFFSET $2
+func WithBoundLimits() Option {
+	return optionFunc(func(db *DB) {
+		db.boundLimits = true
+	})
+}
+
+// BindLimits sends the LIMIT and OFFSET values of the query, and the
+// UnionLimit and UnionOffset of its set operations, as parameters instead of
+// literals, so the same statement is reused for every page size and page.
+// WithBoundLimits does this for every query of a DB.
+//
+// Example:
+//
+//	db.Select("*").From("events").OrderBy("id").
+//	    Limit(pageSize).Offset(page * pageSize).BindLimits().All(&events)
+func (sq *SelectQuery) BindLimits() *SelectQuery {
+	sq = sq.own()
+	sq.boundLimits = true
+	return sq
+}
+
+// writeQueryLimitOffset writes LIMIT/OFFSET for the given values (nil = not
+// set), as literals or, with bound limits, as placeholders whose values are
+// appended to params.
+func (sq *SelectQuery) writeQueryLimitOffset(b *sqlBuffer, limit, offset *int64, dialect dialects.Dialect, params *[]interface{}) {
+	if !sq.boundLimits && !sq.builder.db.boundLimits {
+		b.writeLimitOffset(limit, offset)
+		return
+	}
+
+	if limit != nil {
+		b.WriteString(" LIMIT ")
+		b.writePlaceholder(len(*params)+1, dialect)
+		*params = append(*params, *limit)
+	} else if offset != nil {
+		b.WriteString(" LIMIT ")
+		b.WriteString(maxLimitValue)
+	}
+
+	if offset != nil {
+		b.WriteString(" OFFSET ")
+		b.writePlaceholder(len(*params)+1, dialect)
+		*params = append(*params, *offset)
+	}
+}
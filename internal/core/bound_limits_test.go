@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectQuery_BindLimits(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{"postgres", `SELECT * FROM "users" WHERE status = $1 ORDER BY "id" LIMIT $2 OFFSET $3`},
+		{"mysql", "SELECT * FROM `users` WHERE status = ? ORDER BY `id` LIMIT ? OFFSET ?"},
+		{"sqlite", `SELECT * FROM "users" WHERE status = ? ORDER BY "id" LIMIT ? OFFSET ?`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			qb := &QueryBuilder{db: mockDB(tt.dialect)}
+			q := qb.Select("*").From("users").Where("status = ?", "active").
+				OrderBy("id").Limit(20).Offset(40).BindLimits().Build()
+			require.NoError(t, q.prepErr)
+			assert.Equal(t, tt.want, q.sql)
+			assert.Equal(t, []interface{}{"active", int64(20), int64(40)}, q.params)
+		})
+	}
+}
+
+func TestSelectQuery_BindLimits_SameSQLForEveryPage(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	page := func(limit, offset int64) *Query {
+		return qb.Select("id").From("users").Limit(limit).Offset(offset).BindLimits().Build()
+	}
+
+	first, second := page(10, 0), page(50, 100)
+	assert.Equal(t, first.sql, second.sql)
+	assert.Equal(t, []interface{}{int64(50), int64(100)}, second.params)
+
+	literal := qb.Select("id").From("users").Limit(10).Offset(0).Build()
+	assert.Equal(t, `SELECT "id" FROM "users" LIMIT 10 OFFSET 0`, literal.sql)
+	assert.Empty(t, literal.params)
+}
+
+func TestSelectQuery_BindLimits_OffsetOnly(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("mysql")}
+	q := qb.Select("id").From("users").Offset(5).BindLimits().Build()
+	assert.Equal(t, "SELECT `id` FROM `users` LIMIT 9223372036854775807 OFFSET ?", q.sql)
+	assert.Equal(t, []interface{}{int64(5)}, q.params)
+}
+
+func TestSelectQuery_BindLimits_Union(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	recent := qb.Select("id").From("archive").Where("year = ?", 2024).Limit(5).BindLimits()
+	q := qb.Select("id").From("users").Where("active = ?", true).
+		Union(recent).UnionLimit(10).BindLimits().Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `(SELECT "id" FROM "users" WHERE active = $1) UNION `+
+		`(SELECT "id" FROM "archive" WHERE year = $2 LIMIT $3) LIMIT $4`, q.sql)
+	assert.Equal(t, []interface{}{true, 2024, int64(5), int64(10)}, q.params)
+}
+
+func TestWithBoundLimits(t *testing.T) {
+	db := mockDB("postgres")
+	WithBoundLimits().apply(db)
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("id").From("users").Limit(3).Build()
+	assert.Equal(t, `SELECT "id" FROM "users" LIMIT $1`, q.sql)
+	assert.Equal(t, []interface{}{int64(3)}, q.params)
+}
+
+func TestWithBoundLimits_ReusesStatement(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithBoundLimits())
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = db.Builder().Insert("items", map[string]interface{}{"id": i + 1}).Execute()
+		require.NoError(t, err)
+	}
+
+	before := db.StmtCacheStats().Size
+	for page := int64(0); page < 4; page++ {
+		var ids []int64
+		err := db.Builder().Select("id").From("items").OrderBy("id").
+			Limit(3).Offset(page * 3).Column(&ids)
+		require.NoError(t, err)
+		if page < 3 {
+			assert.Equal(t, []int64{page*3 + 1, page*3 + 2, page*3 + 3}, ids)
+		} else {
+			assert.Equal(t, []int64{10}, ids)
+		}
+	}
+	assert.Equal(t, before+1, db.StmtCacheStats().Size, "one statement for every page")
+}
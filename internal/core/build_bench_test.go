@@ -0,0 +1,93 @@
+package core
+
+import (
+	"testing"
+)
+
+// Allocation benchmarks for SQL generation. Run with -benchmem.
+
+func BenchmarkBuild_SimpleSelect(b *testing.B) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = qb.Select("id", "name", "email").
+			From("users").
+			Where("status = ?", 1).
+			OrderBy("name").
+			Limit(10).
+			Build()
+	}
+}
+
+func BenchmarkBuild_ComplexSelect(b *testing.B) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = qb.Select("u.id", "u.name", "COUNT(o.id) AS orders").
+			From("users u").
+			InnerJoin("orders o", "o.user_id = u.id").
+			Where("u.status = ?", 1).
+			AndWhere("o.created_at > ?", "2024-01-01").
+			AndWhere(In("u.role", "admin", "editor", "viewer")).
+			GroupBy("u.id", "u.name").
+			Having("COUNT(o.id) > ?", 5).
+			OrderBy("orders DESC").
+			Limit(20).
+			Offset(40).
+			Build()
+	}
+}
+
+func BenchmarkBuild_Insert(b *testing.B) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	values := map[string]interface{}{"name": "Alice", "email": "alice@example.com", "age": 30}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = qb.Insert("users", values)
+	}
+}
+
+func BenchmarkBuild_Update(b *testing.B) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	values := map[string]interface{}{"name": "Alice", "email": "alice@example.com"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = qb.Update("users").Set(values).Where("id = ?", 7).Build()
+	}
+}
+
+func BenchmarkBuildSQL_ComplexSelect(b *testing.B) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	sq := qb.Select("u.id", "u.name", "COUNT(o.id) AS orders").
+		From("users u").
+		InnerJoin("orders o", "o.user_id = u.id").
+		Where("u.status = ?", 1).
+		AndWhere("o.created_at > ?", "2024-01-01").
+		AndWhere(In("u.role", "admin", "editor", "viewer")).
+		GroupBy("u.id", "u.name").
+		Having("COUNT(o.id) > ?", 5).
+		OrderBy("orders DESC").
+		Limit(20).
+		Offset(40)
+	dialect := sq.builder.db.dialect
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = sq.buildSQL(dialect)
+	}
+}
+
+// BenchmarkBuildSQL_LargeIn numbers 1000 placeholders, which took time
+// quadratic in their count when each was replaced with strings.Replace.
+func BenchmarkBuildSQL_LargeIn(b *testing.B) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	ids := make([]interface{}, 1000)
+	for i := range ids {
+		ids[i] = i
+	}
+	sq := qb.Select("id").From("users").Where(In("id", ids...))
+	dialect := sq.builder.db.dialect
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = sq.buildSQL(dialect)
+	}
+}
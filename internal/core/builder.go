@@ -1,20 +1,62 @@
 package core
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"database/sql"
 	"fmt"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/coregx/relica/internal/analyzer"
 	"github.com/coregx/relica/internal/dialects"
 )
 
-// selectAliasRegex matches explicit "AS alias" in SELECT columns.
-// Only matches explicit AS keyword to avoid false positives with expressions like "level + 1".
-var selectAliasRegex = regexp.MustCompile(`(?i)\s+AS\s+([\w\-.]+)$`)
+// selectAliasRegex matches explicit "AS alias" in SELECT columns; the alias may be
+// quoted ("order", `order`). Only matches explicit AS keyword to avoid false positives
+// with expressions like "level + 1".
+var selectAliasRegex = regexp.MustCompile("(?i)\\s+AS\\s+(\"(?:[^\"]|\"\")+\"|`(?:[^`]|``)+`|[\\w\\-.]+)$")
+
+// lowerIdentifierRegex matches an alias the database keeps as written without quoting.
+var lowerIdentifierRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// selectExprChars are characters that make a SELECT column an expression rather than a name.
+const selectExprChars = " \t\n()\"`'*+/:|,=<>%!"
+
+// reservedAliases are SQL keywords that must be quoted to be used as a column alias.
+var reservedAliases = map[string]bool{
+	"all": true, "and": true, "any": true, "as": true, "asc": true, "between": true,
+	"by": true, "case": true, "check": true, "column": true, "constraint": true,
+	"create": true, "cross": true, "current_date": true, "current_time": true,
+	"current_timestamp": true, "current_user": true, "default": true, "delete": true,
+	"desc": true, "distinct": true, "drop": true, "else": true, "end": true,
+	"except": true, "exists": true, "false": true, "fetch": true, "for": true,
+	"foreign": true, "from": true, "full": true, "grant": true, "group": true,
+	"having": true, "in": true, "index": true, "inner": true, "insert": true,
+	"intersect": true, "interval": true, "into": true, "is": true, "join": true,
+	"key": true, "left": true, "like": true, "limit": true, "natural": true,
+	"not": true, "null": true, "offset": true, "on": true, "or": true, "order": true,
+	"outer": true, "over": true, "primary": true, "range": true, "references": true,
+	"right": true, "rows": true, "select": true, "session_user": true, "set": true,
+	"table": true, "then": true, "to": true, "true": true, "union": true,
+	"unique": true, "update": true, "user": true, "using": true, "values": true,
+	"when": true, "where": true, "window": true, "with": true,
+}
+
+// plainIdentifierRegex matches a bare (optionally table-qualified) identifier such as
+// "status" or "u.created_at". Anything else in GROUP BY is treated as an expression.
+var plainIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][\w$]*(\.[A-Za-z_][\w$]*)*$`)
+
+// orderTermRegex matches an ORDER BY term on a plain column: "u.name [ASC|DESC] [NULLS FIRST|LAST]".
+var orderTermRegex = regexp.MustCompile(`(?i)^([A-Za-z_][\w$]*(?:\.[A-Za-z_][\w$]*)*)(?:\s+(ASC|DESC))?(?:\s+NULLS\s+(FIRST|LAST))?$`)
+
+// unsafeTermRegex matches statement separators and comments, which never belong in a clause term.
+var unsafeTermRegex = regexp.MustCompile(`;|--|/\*|\*/`)
 
 // resolveNamedParams checks if the SQL condition contains named placeholders {:name}
 // and resolves them to positional ? placeholders using the provided Params map.
@@ -75,9 +117,11 @@ func newAnalyzerForDB(db *DB) (analyzer.Analyzer, error) {
 // QueryBuilder constructs type-safe queries.
 // When tx is not nil, all queries execute within that transaction.
 type QueryBuilder struct {
-	db  *DB
-	tx  *sql.Tx         // nil for non-transactional queries
-	ctx context.Context // context for all queries built by this builder
+	db   *DB
+	tx   *sql.Tx         // nil for non-transactional queries
+	ctx  context.Context // context for all queries built by this builder
+	ctes []cteInfo       // CTEs prepended to statements built by this builder (see With)
+	tag  string          // observability tag for built queries (see Tag)
 }
 
 // WithContext sets the context for all queries built by this builder.
@@ -92,6 +136,8 @@ type JoinInfo struct {
 	JoinType string      // "INNER JOIN", "LEFT JOIN", "RIGHT JOIN", "FULL OUTER JOIN", "CROSS JOIN"
 	Table    string      // Table name with optional alias: "users u", "messages m"
 	On       interface{} // string | Expression | nil
+
+	subquery *SelectQuery // derived table joined as Table (its alias), see JoinSelect
 }
 
 // unionInfo represents a set operation (UNION, INTERSECT, EXCEPT) between queries.
@@ -115,15 +161,61 @@ type fromSource struct {
 	alias      string       // alias for subquery (required for subqueries)
 }
 
+// CTEQuery is a query that can be used as the body of a Common Table Expression:
+// *SelectQuery, or a data-modifying *UpdateQuery, *DeleteQuery or *InsertSelectQuery
+// (PostgreSQL only), usually combined with Returning().
+type CTEQuery interface {
+	buildCTE(dialect dialects.Dialect) (string, []interface{}, error)
+}
+
 // cteInfo represents a Common Table Expression (CTE).
 type cteInfo struct {
-	name      string       // CTE name (e.g., "sales_summary")
-	query     *SelectQuery // The CTE query
-	recursive bool         // true for WITH RECURSIVE
+	name      string   // CTE name (e.g., "sales_summary")
+	query     CTEQuery // The CTE query
+	recursive bool     // true for WITH RECURSIVE
+	hint      string   // "MATERIALIZED", "NOT MATERIALIZED" or "" (see CTEOption)
+}
+
+// CTEOption configures a Common Table Expression added via With or WithRecursive.
+type CTEOption func(*cteInfo)
+
+// Materialized forces the CTE to be computed once and stored (AS MATERIALIZED).
+//
+// The hint is emitted for PostgreSQL 12+ and SQLite 3.35+, and silently dropped
+// on MySQL, which has no equivalent syntax.
+//
+// Example:
+//
+//	db.Builder().Select("*").With("totals", cte, relica.Materialized()).From("totals")
+//	// WITH "totals" AS MATERIALIZED (...) SELECT * FROM "totals"
+func Materialized() CTEOption {
+	return func(c *cteInfo) { c.hint = "MATERIALIZED" }
+}
+
+// NotMaterialized allows the planner to inline the CTE into the outer query (AS NOT MATERIALIZED).
+//
+// Like Materialized, the hint is emitted for PostgreSQL 12+ and SQLite 3.35+ and
+// silently dropped on MySQL.
+func NotMaterialized() CTEOption {
+	return func(c *cteInfo) { c.hint = "NOT MATERIALIZED" }
+}
+
+// groupingElem represents an advanced GROUP BY element (ROLLUP, CUBE, GROUPING SETS).
+type groupingElem struct {
+	kind string     // "ROLLUP", "CUBE", "GROUPING SETS"
+	sets [][]string // ROLLUP/CUBE: a single set of columns; GROUPING SETS: one entry per set
+}
+
+// havingClause is a single HAVING condition with its parameters.
+type havingClause struct {
+	condition string
+	args      []interface{}
+	orGroup   bool // condition is an OR combination built by OrHaving
 }
 
 // subExprEntry holds a type-safe SELECT expression (e.g. a correlated subquery)
 // together with the alias it will be given in the SELECT clause.
+// An empty alias means the expression is emitted as-is (see SelectExp).
 type subExprEntry struct {
 	exp   Expression
 	alias string
@@ -131,43 +223,56 @@ type subExprEntry struct {
 
 // SelectQuery represents a SELECT query being built.
 type SelectQuery struct {
-	builder       *QueryBuilder
-	columns       []string
-	table         string         // DEPRECATED: use fromSrc instead (kept for backward compatibility)
-	fromSrc       *fromSource    // FROM source (table or subquery)
-	selectExprs   []RawExp       // Raw SELECT expressions (for scalar subqueries, etc.)
-	subExprs      []subExprEntry // Type-safe SELECT expressions (subqueries, computed columns)
-	joins         []JoinInfo
-	where         []string
-	params        []interface{}
-	groupBy       []string // GROUP BY columns: ["user_id", "status"]
-	groupByExprs  []RawExp // Raw GROUP BY expressions (EXTRACT, DATE, CASE)
-	havingClauses []struct {
-		condition string
-		args      []interface{}
-	} // HAVING clauses (WHERE for aggregates)
+	builder         *QueryBuilder
+	columns         []string
+	table           string         // DEPRECATED: use fromSrc instead (kept for backward compatibility)
+	fromSrc         *fromSource    // FROM source (table or subquery)
+	selectExprs     []RawExp       // Raw SELECT expressions (for scalar subqueries, etc.)
+	subExprs        []subExprEntry // Type-safe SELECT expressions (subqueries, computed columns)
+	joins           []JoinInfo
+	where           []string
+	params          []interface{}
+	groupBy         []string        // GROUP BY columns: ["user_id", "status"]
+	groupByExprs    []RawExp        // Raw GROUP BY expressions (EXTRACT, DATE, CASE)
+	havingClauses   []havingClause  // HAVING clauses (WHERE for aggregates)
 	orderBy         []string        // ORDER BY clauses: ["age DESC", "name ASC", "created_at"]
 	orderByExprs    []RawExp        // Raw ORDER BY expressions (CASE WHEN, functions with params)
 	subOrderByExprs []Expression    // Type-safe ORDER BY expressions (CaseWhen, etc.)
 	subGroupByExprs []Expression    // Type-safe GROUP BY expressions
+	groupingElems   []groupingElem  // ROLLUP / CUBE / GROUPING SETS
 	limitValue      *int64          // LIMIT value (nil = not set)
 	offsetValue     *int64          // OFFSET value (nil = not set)
 	unions          []unionInfo     // Set operations: UNION, INTERSECT, EXCEPT
+	unionOrderBy    []string        // ORDER BY applied to the combined set-operation result
+	unionLimit      *int64          // LIMIT applied to the combined set-operation result
+	unionOffset     *int64          // OFFSET applied to the combined set-operation result
 	ctes            []cteInfo       // Common Table Expressions (CTEs)
 	distinct        bool            // SELECT DISTINCT flag
+	indexHints      []indexHint     // MySQL USE/FORCE/IGNORE INDEX hints for the FROM table
+	hints           []string        // Planner hint comments (/*+ ... */)
+	chunkedIn       *chunkedIn      // IN list split across several queries (see WhereInChunked)
+	cursorFetch     int             // rows per FETCH from a server-side cursor (see WithCursor)
 	ctx             context.Context // context for this specific query
 	buildErr        error           // stored programming error (replaces panic in fluent chain)
+	immutable       bool            // chained calls return a modified copy (see Immutable)
+	readOnly        bool            // Build rejects statements that may change data (see ReadOnly)
+	boundLimits     bool            // LIMIT/OFFSET values are sent as parameters (see BindLimits)
+	asOf            *time.Time      // read the FROM table as of this time (see AsOf); nil = current rows
+	samplePct       *float64        // percentage of FROM rows to read (see Sample); nil = all rows
+	freshness       *time.Duration  // maximum replica lag (see RequireFresh); nil = any serving replica
 }
 
 // WithContext sets the context for this SELECT query.
 // This overrides any context set on the QueryBuilder.
 func (sq *SelectQuery) WithContext(ctx context.Context) *SelectQuery {
+	sq = sq.own()
 	sq.ctx = ctx
 	return sq
 }
 
 // From specifies the table to select from.
 func (sq *SelectQuery) From(table string) *SelectQuery {
+	sq = sq.own()
 	sq.table = table
 	sq.fromSrc = &fromSource{
 		isSubquery: false,
@@ -193,6 +298,7 @@ func (sq *SelectQuery) From(table string) *SelectQuery {
 //	FROM (SELECT user_id, COUNT(*) as cnt FROM orders GROUP BY user_id) AS order_counts
 //	WHERE cnt > 10
 func (sq *SelectQuery) FromSelect(subquery *SelectQuery, alias string) *SelectQuery {
+	sq = sq.own()
 	if alias == "" {
 		sq.buildErr = fmt.Errorf("relica: FromSelect requires a non-empty alias for the subquery")
 		return sq
@@ -210,6 +316,7 @@ func (sq *SelectQuery) FromSelect(subquery *SelectQuery, alias string) *SelectQu
 // AndSelect appends additional columns to the SELECT clause.
 // Useful for conditional column building where columns are added based on runtime conditions.
 func (sq *SelectQuery) AndSelect(cols ...string) *SelectQuery {
+	sq = sq.own()
 	sq.columns = append(sq.columns, cols...)
 	return sq
 }
@@ -232,6 +339,7 @@ func (sq *SelectQuery) AndSelect(cols ...string) *SelectQuery {
 //
 // Note: The SQL expression is used as-is. You're responsible for proper quoting and SQL injection prevention.
 func (sq *SelectQuery) SelectExpr(expr string, args ...interface{}) *SelectQuery {
+	sq = sq.own()
 	sq.selectExprs = append(sq.selectExprs, RawExp{
 		SQL:  expr,
 		Args: args,
@@ -252,14 +360,52 @@ func (sq *SelectQuery) SelectExpr(expr string, args ...interface{}) *SelectQuery
 //
 //	SELECT "id", "name", (SELECT COUNT(*) FROM "orders" WHERE "orders"."user_id" = "users"."id") AS "order_count" FROM "users"
 func (sq *SelectQuery) SelectSub(exp Expression, alias string) *SelectQuery {
+	sq = sq.own()
 	if alias == "" {
 		sq.buildErr = fmt.Errorf("relica: SelectSub requires a non-empty alias")
 		return sq
 	}
+	if err := checkExpression(exp, sq.builder.db.dialect); err != nil {
+		sq.buildErr = err
+		return sq
+	}
 	sq.subExprs = append(sq.subExprs, subExprEntry{exp: exp, alias: alias})
 	return sq
 }
 
+// SelectExp adds type-safe expressions to the SELECT clause as-is.
+// Unlike SelectSub, the expression is not wrapped in parentheses and no alias is added,
+// so expressions that carry their own alias (CASE, COALESCE, etc.) can be selected directly.
+// Parameters are collected in order and placeholders are renumbered for the dialect.
+//
+// Example:
+//
+//	db.Builder().Select("id").
+//	    SelectExp(relica.Case().
+//	        When(relica.Eq("status", 1), "active").
+//	        Else("unknown").
+//	        As("status_label")).
+//	    From("users")
+//
+// Generates (PostgreSQL):
+//
+//	SELECT "id", CASE WHEN "status" = $1 THEN $2 ELSE $3 END AS "status_label" FROM "users"
+func (sq *SelectQuery) SelectExp(exps ...Expression) *SelectQuery {
+	sq = sq.own()
+	for _, exp := range exps {
+		if exp == nil {
+			sq.buildErr = fmt.Errorf("relica: SelectExp requires a non-nil expression")
+			return sq
+		}
+		if err := checkExpression(exp, sq.builder.db.dialect); err != nil {
+			sq.buildErr = err
+			return sq
+		}
+		sq.subExprs = append(sq.subExprs, subExprEntry{exp: exp})
+	}
+	return sq
+}
+
 // Where adds a WHERE condition.
 // Accepts either a string with placeholders or an Expression.
 //
@@ -274,6 +420,7 @@ func (sq *SelectQuery) SelectSub(exp Expression, alias string) *SelectQuery {
 //	    relica.GreaterThan("age", 18),
 //	))
 func (sq *SelectQuery) Where(condition interface{}, params ...interface{}) *SelectQuery {
+	sq = sq.own()
 	switch cond := condition.(type) {
 	case string:
 		resolved, resolvedArgs, err := resolveNamedParams(cond, params)
@@ -286,6 +433,10 @@ func (sq *SelectQuery) Where(condition interface{}, params ...interface{}) *Sele
 
 	case Expression:
 		// New Expression-based WHERE
+		if err := checkExpressionTree(cond, sq.builder.db.dialect); err != nil {
+			sq.buildErr = err
+			return sq
+		}
 		sqlStr, args := cond.Build(sq.builder.db.dialect)
 		if sqlStr != "" {
 			sq.where = append(sq.where, sqlStr)
@@ -329,6 +480,7 @@ func (sq *SelectQuery) AndWhere(condition interface{}, params ...interface{}) *S
 //
 //nolint:dupl // OrWhere on SelectQuery/UpdateQuery/DeleteQuery share structure but operate on different receiver types; a generic helper would require interface{} gymnastics.
 func (sq *SelectQuery) OrWhere(condition interface{}, params ...interface{}) *SelectQuery {
+	sq = sq.own()
 	if len(sq.where) == 0 {
 		// No existing WHERE clause - just add it.
 		return sq.Where(condition, params...)
@@ -348,6 +500,10 @@ func (sq *SelectQuery) OrWhere(condition interface{}, params ...interface{}) *Se
 		}
 
 	case Expression:
+		if err := checkExpressionTree(cond, sq.builder.db.dialect); err != nil {
+			sq.buildErr = err
+			return sq
+		}
 		newSQL, newArgs = cond.Build(sq.builder.db.dialect)
 		if newSQL == "" {
 			return sq
@@ -382,6 +538,11 @@ func (sq *SelectQuery) OrWhere(condition interface{}, params ...interface{}) *Se
 //	Join("INNER JOIN", "users u", "m.user_id = u.id")
 //	Join("LEFT JOIN", "attachments a", relica.Eq("m.id", relica.Raw("a.message_id")))
 func (sq *SelectQuery) Join(joinType, table string, on interface{}) *SelectQuery {
+	sq = sq.own()
+	if err := checkJoinOn(on, sq.builder.db.dialect); err != nil {
+		sq.buildErr = err
+		return sq
+	}
 	sq.joins = append(sq.joins, JoinInfo{
 		JoinType: joinType,
 		Table:    table,
@@ -390,6 +551,15 @@ func (sq *SelectQuery) Join(joinType, table string, on interface{}) *SelectQuery
 	return sq
 }
 
+// checkJoinOn returns the error of an Expression ON condition that dialect
+// does not support.
+func checkJoinOn(on interface{}, dialect dialects.Dialect) error {
+	if exp, ok := on.(Expression); ok {
+		return checkExpressionTree(exp, dialect)
+	}
+	return nil
+}
+
 // InnerJoin adds an INNER JOIN clause to the SELECT query.
 // table is the table name with optional alias (e.g., "users u").
 // on can be a string or Expression specifying the join condition.
@@ -447,16 +617,85 @@ func (sq *SelectQuery) CrossJoin(table string) *SelectQuery {
 	return sq.Join("CROSS JOIN", table, nil)
 }
 
+// JoinSelect adds a JOIN of a subquery (derived table) to the SELECT query.
+// The alias is required and names the subquery in the outer query; on can
+// be a string, Expression, or nil. The subquery's parameters are bound
+// after those of the FROM source and earlier joins, and before WHERE.
+//
+// Example:
+//
+//	totals := db.Builder().Select("user_id", "SUM(total) AS spent").From("orders").
+//	    Where("status = ?", "paid").GroupBy("user_id")
+//	db.Builder().Select("u.name", "t.spent").From("users u").
+//	    JoinSelect("INNER JOIN", totals, "t", "t.user_id = u.id").
+//	    Where("u.active = ?", true)
+func (sq *SelectQuery) JoinSelect(joinType string, subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	sq = sq.own()
+	switch {
+	case subquery == nil:
+		sq.buildErr = fmt.Errorf("relica: %s subquery is nil", joinType)
+		return sq
+	case alias == "":
+		sq.buildErr = fmt.Errorf("relica: %s of a subquery requires a non-empty alias", joinType)
+		return sq
+	}
+	if err := checkJoinOn(on, sq.builder.db.dialect); err != nil {
+		sq.buildErr = err
+		return sq
+	}
+	sq.joins = append(sq.joins, JoinInfo{
+		JoinType: joinType,
+		Table:    alias,
+		On:       on,
+		subquery: subquery,
+	})
+	return sq
+}
+
+// InnerJoinSelect adds an INNER JOIN of a subquery with the given alias.
+//
+// Example:
+//
+//	InnerJoinSelect(totals, "t", "t.user_id = u.id")
+func (sq *SelectQuery) InnerJoinSelect(subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	return sq.JoinSelect("INNER JOIN", subquery, alias, on)
+}
+
+// LeftJoinSelect adds a LEFT JOIN of a subquery with the given alias.
+//
+// Example:
+//
+//	LeftJoinSelect(lastLogins, "l", relica.EqCol("l.user_id", "u.id"))
+func (sq *SelectQuery) LeftJoinSelect(subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	return sq.JoinSelect("LEFT JOIN", subquery, alias, on)
+}
+
+// RightJoinSelect adds a RIGHT JOIN of a subquery with the given alias.
+//
+// Example:
+//
+//	RightJoinSelect(totals, "t", "t.user_id = u.id")
+func (sq *SelectQuery) RightJoinSelect(subquery *SelectQuery, alias string, on interface{}) *SelectQuery {
+	return sq.JoinSelect("RIGHT JOIN", subquery, alias, on)
+}
+
 // OrderBy adds ORDER BY clause with optional direction (ASC/DESC).
 // Supports multiple columns with optional direction specification.
 // Chainable: multiple OrderBy() calls append to the same clause.
 //
+// Plain columns ("age DESC", "u.name NULLS LAST") are quoted; any other term
+// ("LOWER(name) DESC", "COALESCE(nickname, name)", "created_at::date") is
+// emitted as-is. Terms containing ";" or a comment fail the query. Never pass
+// user input here; use OrderBySafe for that.
+//
 // Examples:
 //
 //	OrderBy("age DESC")                    // Single column descending
 //	OrderBy("status ASC", "created_at")    // Multiple columns (created_at defaults to ASC)
 //	OrderBy("name").OrderBy("age DESC")    // Chained calls
+//	OrderBy("LOWER(name) DESC")            // Expression, not quoted
 func (sq *SelectQuery) OrderBy(columns ...string) *SelectQuery {
+	sq = sq.own()
 	sq.orderBy = append(sq.orderBy, columns...)
 	return sq
 }
@@ -470,6 +709,7 @@ func (sq *SelectQuery) OrderBy(columns ...string) *SelectQuery {
 //	OrderByExpr("CASE WHEN status = ? THEN 0 ELSE 1 END", "active")
 //	OrderByExpr("FIELD(id, ?, ?, ?)", 3, 1, 2)
 func (sq *SelectQuery) OrderByExpr(expr string, args ...interface{}) *SelectQuery {
+	sq = sq.own()
 	sq.orderByExprs = append(sq.orderByExprs, RawExp{SQL: expr, Args: args})
 	return sq
 }
@@ -484,6 +724,11 @@ func (sq *SelectQuery) OrderByExpr(expr string, args ...interface{}) *SelectQuer
 //	    When("t.due_date IS NULL", 3).
 //	    Else(1))
 func (sq *SelectQuery) OrderBySub(exp Expression) *SelectQuery {
+	sq = sq.own()
+	if err := checkExpression(exp, sq.builder.db.dialect); err != nil {
+		sq.buildErr = err
+		return sq
+	}
 	sq.subOrderByExprs = append(sq.subOrderByExprs, exp)
 	return sq
 }
@@ -495,6 +740,7 @@ func (sq *SelectQuery) OrderBySub(exp Expression) *SelectQuery {
 //
 //	Limit(100)  // Return at most 100 rows
 func (sq *SelectQuery) Limit(limit int64) *SelectQuery {
+	sq = sq.own()
 	sq.limitValue = &limit
 	return sq
 }
@@ -506,6 +752,7 @@ func (sq *SelectQuery) Limit(limit int64) *SelectQuery {
 //
 //	Offset(200)  // Skip first 200 rows
 func (sq *SelectQuery) Offset(offset int64) *SelectQuery {
+	sq = sq.own()
 	sq.offsetValue = &offset
 	return sq
 }
@@ -525,6 +772,7 @@ func (sq *SelectQuery) Offset(offset int64) *SelectQuery {
 //
 // Note: Column count and types must match between queries.
 func (sq *SelectQuery) Union(other *SelectQuery) *SelectQuery {
+	sq = sq.own()
 	if other != nil {
 		sq.unions = append(sq.unions, unionInfo{query: other, all: false, op: "UNION"})
 	}
@@ -544,6 +792,7 @@ func (sq *SelectQuery) Union(other *SelectQuery) *SelectQuery {
 //
 //	(SELECT id FROM orders_2023) UNION ALL (SELECT id FROM orders_2024)
 func (sq *SelectQuery) UnionAll(other *SelectQuery) *SelectQuery {
+	sq = sq.own()
 	if other != nil {
 		sq.unions = append(sq.unions, unionInfo{query: other, all: true, op: "UNION"})
 	}
@@ -568,6 +817,7 @@ func (sq *SelectQuery) UnionAll(other *SelectQuery) *SelectQuery {
 //   - MySQL 8.0.31+: ✓ (earlier versions will return error)
 //   - SQLite 3.25+: ✓
 func (sq *SelectQuery) Intersect(other *SelectQuery) *SelectQuery {
+	sq = sq.own()
 	if other != nil {
 		sq.unions = append(sq.unions, unionInfo{query: other, all: false, op: "INTERSECT"})
 	}
@@ -593,13 +843,49 @@ func (sq *SelectQuery) Intersect(other *SelectQuery) *SelectQuery {
 //   - MySQL 8.0.31+: ✓ (earlier versions will return error)
 //   - SQLite 3.25+: ✓
 func (sq *SelectQuery) Except(other *SelectQuery) *SelectQuery {
+	sq = sq.own()
 	if other != nil {
 		sq.unions = append(sq.unions, unionInfo{query: other, all: false, op: "EXCEPT"})
 	}
 	return sq
 }
 
+// UnionOrderBy sets ORDER BY for the combined result of UNION/INTERSECT/EXCEPT.
+// Plain OrderBy() on a query applies only to that query's own rows; use UnionOrderBy
+// to sort the whole result. Columns refer to the result columns of the first query.
+// Has no effect unless a set operation is added.
+//
+// Example:
+//
+//	q1.UnionAll(q2).UnionOrderBy("created_at DESC").UnionLimit(20)
+//
+// Generates:
+//
+//	(SELECT ...) UNION ALL (SELECT ...) ORDER BY "created_at" DESC LIMIT 20
+func (sq *SelectQuery) UnionOrderBy(columns ...string) *SelectQuery {
+	sq = sq.own()
+	sq.unionOrderBy = append(sq.unionOrderBy, columns...)
+	return sq
+}
+
+// UnionLimit sets LIMIT for the combined result of UNION/INTERSECT/EXCEPT.
+// Has no effect unless a set operation is added.
+func (sq *SelectQuery) UnionLimit(limit int64) *SelectQuery {
+	sq = sq.own()
+	sq.unionLimit = &limit
+	return sq
+}
+
+// UnionOffset sets OFFSET for the combined result of UNION/INTERSECT/EXCEPT.
+// Has no effect unless a set operation is added.
+func (sq *SelectQuery) UnionOffset(offset int64) *SelectQuery {
+	sq = sq.own()
+	sq.unionOffset = &offset
+	return sq
+}
+
 // With adds a Common Table Expression (CTE) to the query.
+// Options such as Materialized() or NotMaterialized() control planner hints.
 //
 // Example:
 //
@@ -617,20 +903,17 @@ func (sq *SelectQuery) Except(other *SelectQuery) *SelectQuery {
 //
 //	WITH "order_totals" AS (SELECT user_id, SUM(total) as total FROM "orders" GROUP BY user_id)
 //	SELECT * FROM "order_totals" WHERE total > $1
-func (sq *SelectQuery) With(name string, query *SelectQuery) *SelectQuery {
-	if name == "" {
-		sq.buildErr = fmt.Errorf("relica: With() requires a non-empty CTE name")
+func (sq *SelectQuery) With(name string, query CTEQuery, opts ...CTEOption) *SelectQuery {
+	sq = sq.own()
+	if err := validateCTE("With", name, query); err != nil {
+		sq.buildErr = err
 		return sq
 	}
-	if query == nil {
-		sq.buildErr = fmt.Errorf("relica: With() requires a non-nil CTE query")
-		return sq
-	}
-	sq.ctes = append(sq.ctes, cteInfo{
+	sq.addCTE(cteInfo{
 		name:      name,
 		query:     query,
 		recursive: false,
-	})
+	}, opts)
 	return sq
 }
 
@@ -668,13 +951,10 @@ func (sq *SelectQuery) With(name string, query *SelectQuery) *SelectQuery {
 //   - PostgreSQL: ✓ (all versions)
 //   - MySQL 8.0+: ✓ (added in MySQL 8.0.1)
 //   - SQLite 3.25+: ✓ (added in SQLite 3.25.0)
-func (sq *SelectQuery) WithRecursive(name string, query *SelectQuery) *SelectQuery {
-	if name == "" {
-		sq.buildErr = fmt.Errorf("relica: WithRecursive() requires a non-empty CTE name")
-		return sq
-	}
-	if query == nil {
-		sq.buildErr = fmt.Errorf("relica: WithRecursive() requires a non-nil CTE query")
+func (sq *SelectQuery) WithRecursive(name string, query *SelectQuery, opts ...CTEOption) *SelectQuery {
+	sq = sq.own()
+	if err := validateCTE("WithRecursive", name, query); err != nil {
+		sq.buildErr = err
 		return sq
 	}
 	// Validate that query contains UNION (required for recursive CTE)
@@ -682,14 +962,83 @@ func (sq *SelectQuery) WithRecursive(name string, query *SelectQuery) *SelectQue
 		sq.buildErr = fmt.Errorf("relica: WithRecursive() requires a query with UNION or UNION ALL (recursive CTE must have an anchor and recursive part)")
 		return sq
 	}
-	sq.ctes = append(sq.ctes, cteInfo{
+	sq.addCTE(cteInfo{
 		name:      name,
 		query:     query,
 		recursive: true,
-	})
+	}, opts)
 	return sq
 }
 
+// addCTE applies options to the CTE and appends it to the query.
+func (sq *SelectQuery) addCTE(cte cteInfo, opts []CTEOption) {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cte)
+		}
+	}
+	sq.ctes = append(sq.ctes, cte)
+}
+
+// validateCTE checks the CTE name and body passed to With/WithRecursive.
+func validateCTE(method, name string, query CTEQuery) error {
+	if name == "" {
+		return fmt.Errorf("relica: %s() requires a non-empty CTE name", method)
+	}
+	if isNilCTEQuery(query) {
+		return fmt.Errorf("relica: %s() requires a non-nil CTE query", method)
+	}
+	return nil
+}
+
+// isNilCTEQuery reports whether query is nil, including typed nil pointers.
+func isNilCTEQuery(query CTEQuery) bool {
+	switch q := query.(type) {
+	case nil:
+		return true
+	case *SelectQuery:
+		return q == nil
+	case *UpdateQuery:
+		return q == nil
+	case *DeleteQuery:
+		return q == nil
+	case *InsertSelectQuery:
+		return q == nil
+	}
+	return false
+}
+
+// With returns a new QueryBuilder that prepends the CTE to every statement it builds
+// (SELECT, UPDATE, DELETE and InsertFromSelect). The original builder is unchanged,
+// so nested queries built from it do not inherit the CTE.
+//
+// Combined with a data-modifying CTE body this enables move/archive patterns in a
+// single statement (PostgreSQL):
+//
+//	moved := db.Builder().Delete("orders").
+//	    Where("created_at < ?", cutoff).
+//	    Returning("*")
+//
+//	db.Builder().
+//	    With("moved", moved).
+//	    InsertFromSelect("orders_archive", nil, db.Builder().Select("*").From("moved")).
+//	    Execute()
+//
+// Generates:
+//
+//	WITH "moved" AS (DELETE FROM "orders" WHERE created_at < $1 RETURNING *)
+//	INSERT INTO "orders_archive" SELECT * FROM "moved"
+func (qb *QueryBuilder) With(name string, query CTEQuery, opts ...CTEOption) *QueryBuilder {
+	nb := *qb
+	nb.ctes = append(append([]cteInfo(nil), qb.ctes...), cteInfo{name: name, query: query})
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&nb.ctes[len(nb.ctes)-1])
+		}
+	}
+	return &nb
+}
+
 // Distinct adds the DISTINCT keyword to the SELECT clause, eliminating duplicate rows.
 //
 // Example:
@@ -697,6 +1046,7 @@ func (sq *SelectQuery) WithRecursive(name string, query *SelectQuery) *SelectQue
 //	db.Builder().Select("category").From("products").Distinct().All(&categories)
 //	// SELECT DISTINCT "category" FROM "products"
 func (sq *SelectQuery) Distinct() *SelectQuery {
+	sq = sq.own()
 	sq.distinct = true
 	return sq
 }
@@ -710,6 +1060,11 @@ func (sq *SelectQuery) Distinct() *SelectQuery {
 // Uses quoteColumn for the table part so schema.table identifiers are quoted per-part
 // rather than as a single string (which would produce "public.users" instead of "public"."users").
 func (sq *SelectQuery) buildTableWithAlias(table string, dialect dialects.Dialect) string {
+	return tableWithAlias(table, dialect)
+}
+
+// tableWithAlias quotes "table" or "table alias" as "table" AS "alias".
+func tableWithAlias(table string, dialect dialects.Dialect) string {
 	tableParts := strings.Fields(table)
 	if len(tableParts) == 2 {
 		// Table (possibly schema-qualified) with alias
@@ -721,122 +1076,197 @@ func (sq *SelectQuery) buildTableWithAlias(table string, dialect dialects.Dialec
 	return quoteColumn(table, dialect)
 }
 
-// buildFrom constructs the FROM clause, handling both tables and subqueries.
-// Returns the FROM SQL fragment and appends any subquery parameters to params.
-func (sq *SelectQuery) buildFrom(dialect dialects.Dialect, params *[]interface{}) string {
+// writeFrom writes the FROM clause, handling both tables and subqueries, and
+// appends any subquery parameters to params. Nothing is written without a table
+// (e.g., SELECT 1).
+func (sq *SelectQuery) writeFrom(b *sqlBuffer, dialect dialects.Dialect, params *[]interface{}) {
+	table := sq.table
 	// Prefer fromSrc if set (supports subqueries)
 	if sq.fromSrc != nil {
 		if sq.fromSrc.isSubquery {
+			if sq.asOf != nil {
+				b.fail(fmt.Errorf("relica: AsOf requires a FROM table, not a subquery"))
+			}
+			if sq.samplePct != nil {
+				b.fail(fmt.Errorf("relica: Sample requires a FROM table, not a subquery"))
+			}
 			// FROM (SELECT ...) AS alias
-			subSQL, subArgs := sq.fromSrc.subquery.buildSQL(dialect)
+			subSQL, subArgs, err := sq.fromSrc.subquery.buildSQL(dialect)
+			b.fail(err)
+			b.WriteString(" FROM (")
+			b.WriteString(renumberFragment(subSQL, len(*params)+1, len(subArgs), dialect))
+			b.WriteString(") AS ")
+			b.writeIdentifier(sq.fromSrc.alias, dialect)
 			*params = append(*params, subArgs...)
-			quotedAlias := dialect.QuoteIdentifier(sq.fromSrc.alias)
-			return " FROM (" + subSQL + ") AS " + quotedAlias
+			return
+		}
+		table = sq.fromSrc.table
+		if sq.asOf != nil {
+			derived, err := sq.buildAsOf(table, dialect, params)
+			b.fail(err)
+			_, err = sq.buildTableSample(dialect)
+			b.fail(err)
+			b.WriteString(" FROM ")
+			b.WriteString(derived)
+			return
 		}
-		// Regular table
-		return " FROM " + sq.buildTableWithAlias(sq.fromSrc.table, dialect)
 	}
-
-	// Fallback to legacy table field (backward compatibility)
-	if sq.table != "" {
-		return " FROM " + sq.buildTableWithAlias(sq.table, dialect)
+	if table == "" {
+		return
 	}
 
-	// No FROM clause (e.g., SELECT 1)
-	return ""
+	b.WriteString(" FROM ")
+	b.writeTable(table, dialect)
+	b.WriteString(sq.buildIndexHints(dialect))
+	sample, err := sq.buildTableSample(dialect)
+	b.fail(err)
+	b.WriteString(sample)
 }
 
-// buildJoins constructs the JOIN clause from the joins slice.
-// Returns empty string if no joins are specified.
-// On unsupported ON type, stores the error in sq.buildErr and returns empty string.
-func (sq *SelectQuery) buildJoins(dialect dialects.Dialect, params *[]interface{}) string {
-	if len(sq.joins) == 0 {
-		return ""
-	}
-
-	parts := make([]string, 0, len(sq.joins))
-
+// writeJoins writes the JOIN clauses and appends their parameters to params.
+// An unsupported ON type fails the statement (see sqlBuffer.fail).
+func (sq *SelectQuery) writeJoins(b *sqlBuffer, dialect dialects.Dialect, params *[]interface{}) {
 	for _, join := range sq.joins {
-		part := " " + join.JoinType + " "
-
-		// Build table with optional alias
-		part += sq.buildTableWithAlias(join.Table, dialect)
+		b.WriteByte(' ')
+		b.WriteString(join.JoinType)
+		b.WriteByte(' ')
+
+		if join.subquery != nil {
+			// JOIN (SELECT ...) AS alias
+			subSQL, subArgs, err := join.subquery.buildSQL(dialect)
+			if err != nil {
+				b.fail(err)
+				return
+			}
+			b.WriteByte('(')
+			b.WriteString(renumberFragment(subSQL, len(*params)+1, len(subArgs), dialect))
+			b.WriteString(") AS ")
+			b.writeIdentifier(join.Table, dialect)
+			*params = append(*params, subArgs...)
+		} else {
+			// Table with optional alias
+			b.writeTable(join.Table, dialect)
+		}
 
-		// Build ON condition
+		// ON condition
 		if join.On != nil {
-			part += " ON "
+			b.WriteString(" ON ")
 
 			switch on := join.On.(type) {
 			case string:
 				// String-based ON: use as-is
-				part += on
+				b.WriteString(on)
 
 			case Expression:
 				// Expression-based ON
 				sqlStr, args := on.Build(dialect)
-				part += sqlStr
+				b.writeNumbered(sqlStr, len(*params)+1, len(args), dialect)
 				*params = append(*params, args...)
 
 			default:
-				sq.buildErr = fmt.Errorf("relica: JOIN ON must be string, Expression, or nil, got %T", join.On)
-				return ""
+				b.fail(fmt.Errorf("relica: JOIN ON must be string, Expression, or nil, got %T", join.On))
+				return
 			}
 		}
-
-		parts = append(parts, part)
 	}
-
-	return strings.Join(parts, "")
 }
 
-// buildOrderBy constructs the ORDER BY clause from the orderBy slice.
-// Returns empty string if no ORDER BY is specified.
+// writeOrderBy writes the ORDER BY clause, if any.
 // Parses column direction (ASC/DESC) and quotes column names.
-//
-//nolint:cyclop // Three sources (columns, raw exprs, sub exprs) each need separate handling.
-func (sq *SelectQuery) buildOrderBy(dialect dialects.Dialect) string {
-	if len(sq.orderBy) == 0 && len(sq.orderByExprs) == 0 && len(sq.subOrderByExprs) == 0 {
-		return ""
+// Expression parameters are appended to params and their placeholders renumbered.
+func (sq *SelectQuery) writeOrderBy(b *sqlBuffer, dialect dialects.Dialect, params *[]interface{}) {
+	n, err := b.writeOrderByTerms(sq.orderBy, dialect)
+	if err != nil {
+		b.fail(err)
+		return
+	}
+
+	// Append raw ORDER BY expressions (CASE WHEN, complex functions)
+	for _, expr := range sq.orderByExprs {
+		b.writeListSeparator(" ORDER BY ", n)
+		b.writeNumbered(expr.SQL, len(*params)+1, len(expr.Args), dialect)
+		*params = append(*params, expr.Args...)
+		n++
 	}
 
-	parts := make([]string, 0, len(sq.orderBy)+len(sq.orderByExprs))
-	for _, col := range sq.orderBy {
-		// Parse "column [ASC|DESC]"
-		fields := strings.Fields(col)
-		if len(fields) == 0 {
+	// Append type-safe ORDER BY expressions (CaseWhen, etc.)
+	for _, exp := range sq.subOrderByExprs {
+		expSQL, expArgs := exp.Build(dialect)
+		b.writeListSeparator(" ORDER BY ", n)
+		b.writeNumbered(expSQL, len(*params)+1, len(expArgs), dialect)
+		*params = append(*params, expArgs...)
+		n++
+	}
+}
+
+// writeOrderByTerms writes the ORDER BY clause for columns, quoting
+// "column [ASC|DESC] [NULLS FIRST|LAST]" terms and passing expression terms
+// through unchanged. Returns the number of terms written.
+func (b *sqlBuffer) writeOrderByTerms(columns []string, dialect dialects.Dialect) (int, error) {
+	n := 0
+	for _, col := range columns {
+		term := strings.TrimSpace(col)
+		if term == "" {
 			continue
 		}
+		b.writeListSeparator(" ORDER BY ", n)
+		n++
 
-		// Quote column name (may include table prefix: "users.age" → "users"."age")
-		quoted := sq.quoteColumnName(fields[0], dialect)
-
-		// Add direction if specified
-		if len(fields) > 1 {
-			direction := strings.ToUpper(fields[1])
-			if direction == "ASC" || direction == "DESC" {
-				quoted += " " + direction
+		if plainIdentifierRegex.MatchString(term) {
+			b.writeColumn(term, dialect)
+			continue
+		}
+		m := orderTermRegex.FindStringSubmatch(term)
+		if m == nil {
+			if err := checkClauseTerm("ORDER BY", term); err != nil {
+				return n, err
 			}
+			b.WriteString(term)
+			continue
 		}
 
-		parts = append(parts, quoted)
+		// Quote column name (may include table prefix: "users.age" → "users"."age")
+		b.writeColumn(m[1], dialect)
+		if m[2] != "" {
+			b.WriteByte(' ')
+			b.WriteString(strings.ToUpper(m[2]))
+		}
+		if m[3] != "" {
+			b.WriteString(" NULLS ")
+			b.WriteString(strings.ToUpper(m[3]))
+		}
 	}
+	return n, nil
+}
 
-	// Append raw ORDER BY expressions (CASE WHEN, complex functions)
-	for _, expr := range sq.orderByExprs {
-		parts = append(parts, expr.SQL)
+// writeListSeparator writes clause before the first item of a list (n == 0)
+// and ", " before the others.
+func (b *sqlBuffer) writeListSeparator(clause string, n int) {
+	if n == 0 {
+		b.WriteString(clause)
+	} else {
+		b.WriteString(", ")
 	}
+}
 
-	// Append type-safe ORDER BY expressions (CaseWhen, etc.)
-	for _, exp := range sq.subOrderByExprs {
-		expSQL, _ := exp.Build(dialect)
-		parts = append(parts, expSQL)
+// formatOrderByTerms returns the ORDER BY clause for columns (see
+// writeOrderByTerms), or "" if there are no terms.
+func formatOrderByTerms(columns []string, dialect dialects.Dialect) (string, error) {
+	b := getSQLBuffer()
+	defer b.release()
+	if _, err := b.writeOrderByTerms(columns, dialect); err != nil {
+		return "", err
 	}
+	return b.String(), nil
+}
 
-	if len(parts) == 0 {
-		return ""
+// checkClauseTerm rejects an expression term of clause that contains a
+// statement separator or comment outside string literals.
+func checkClauseTerm(clause, term string) error {
+	if unsafeTermRegex.MatchString(stringLiteralRegex.ReplaceAllString(term, "''")) {
+		return fmt.Errorf("relica: %s term %q contains a statement separator or comment", clause, term)
 	}
-
-	return " ORDER BY " + strings.Join(parts, ", ")
+	return nil
 }
 
 // quoteColumnName quotes a column name, handling table prefixes.
@@ -845,58 +1275,106 @@ func (sq *SelectQuery) quoteColumnName(col string, dialect dialects.Dialect) str
 	return quoteColumn(col, dialect)
 }
 
-// buildLimitOffset constructs the LIMIT and OFFSET clauses.
-// Returns empty string if neither is set.
-func (sq *SelectQuery) buildLimitOffset() string {
-	var result string
+// limitOffsetSQL renders LIMIT/OFFSET for the given values (nil = not set).
+func limitOffsetSQL(limit, offset *int64) string {
+	if limit == nil && offset == nil {
+		return ""
+	}
+	b := getSQLBuffer()
+	defer b.release()
+	b.writeLimitOffset(limit, offset)
+	return b.String()
+}
 
-	if sq.limitValue != nil {
-		result += fmt.Sprintf(" LIMIT %d", *sq.limitValue)
-	} else if sq.offsetValue != nil {
+// writeLimitOffset writes LIMIT/OFFSET for the given values (nil = not set).
+func (b *sqlBuffer) writeLimitOffset(limit, offset *int64) {
+	if limit != nil {
+		b.WriteString(" LIMIT ")
+		b.writeInt(*limit)
+	} else if offset != nil {
 		// MySQL requires LIMIT before OFFSET; emit max value for compatibility
-		result += " LIMIT 9223372036854775807"
+		b.WriteString(" LIMIT ")
+		b.WriteString(maxLimitValue)
 	}
 
-	if sq.offsetValue != nil {
-		result += fmt.Sprintf(" OFFSET %d", *sq.offsetValue)
+	if offset != nil {
+		b.WriteString(" OFFSET ")
+		b.writeInt(*offset)
 	}
-
-	return result
 }
 
 // formatSelectColumn formats a single column token for the SELECT clause.
-// Aggregates, aliases, and wildcards are passed through as-is; plain identifiers are quoted.
+// Column names ("name", "u.name") are quoted; expressions, literals and
+// wildcards ("COUNT(*)", "price * qty", "1", "u.*") are passed through as-is.
+// An "AS alias" is quoted, except on a function call, whose alias is kept
+// as written unless it is quoted in col or the database would change or
+// reject it unquoted (mixed case, reserved words).
 func (sq *SelectQuery) formatSelectColumn(col string, dialect dialects.Dialect) string {
-	switch {
-	case col == "*":
+	if col == "*" {
 		return "*"
-	case strings.Contains(col, "("):
+	}
+	expr, alias, quoted := splitSelectAlias(col)
+	if expr == "" {
 		return col
-	default:
-		matches := selectAliasRegex.FindStringSubmatch(col)
-		if len(matches) > 0 {
-			colPart := col[:len(col)-len(matches[0])]
-			alias := matches[1]
-			return quoteColumn(colPart, dialect) + " AS " + dialect.QuoteIdentifier(alias)
-		}
-		return sq.quoteColumnName(col, dialect)
 	}
+	isName := !strings.ContainsAny(expr, selectExprChars) && !unicode.IsDigit(rune(expr[0]))
+	switch {
+	case isName:
+		expr = sq.quoteColumnName(expr, dialect)
+	case alias == "":
+		return col
+	case strings.Contains(expr, "(") && !quoted && lowerIdentifierRegex.MatchString(alias) && !reservedAliases[alias]:
+		return col
+	}
+	if alias == "" {
+		return expr
+	}
+	return expr + " AS " + dialect.QuoteIdentifier(alias)
 }
 
-// buildSelect constructs the SELECT clause, handling aggregate functions and raw expressions.
+// splitSelectAlias splits "expr AS alias" into the expression and the
+// unquoted alias; quoted reports whether the alias was quoted in col.
+// Without an alias, expr is col.
+func splitSelectAlias(col string) (expr, alias string, quoted bool) {
+	m := selectAliasRegex.FindStringSubmatch(col)
+	if m == nil {
+		return col, "", false
+	}
+	expr = strings.TrimSpace(col[:len(col)-len(m[0])])
+	alias = m[1]
+	if q := alias[:1]; q == `"` || q == "`" {
+		return expr, strings.ReplaceAll(alias[1:len(alias)-1], q+q, q), true
+	}
+	return expr, alias, false
+}
+
+// writeSelect writes the column list of the SELECT clause, handling aggregate
+// functions and raw expressions.
 // renderedSubExprs contains pre-built SQL strings for subExprs entries (dialect-specific,
 // with placeholders already renumbered); passed in from buildSQL to keep renumbering logic centralized.
-// Returns "*" if no columns, no selectExprs, and no subExprs specified.
+// Writes "*" if no columns, no selectExprs, and no subExprs specified.
 // Includes DISTINCT keyword if sq.distinct is true.
-func (sq *SelectQuery) buildSelect(dialect dialects.Dialect, renderedSubExprs []string) string {
-	parts := make([]string, 0, len(sq.columns)+len(sq.selectExprs)+len(renderedSubExprs))
+func (sq *SelectQuery) writeSelect(b *sqlBuffer, dialect dialects.Dialect, renderedSubExprs []string) {
+	if sq.distinct {
+		b.WriteString("DISTINCT ")
+	}
 
+	n := 0
 	for _, col := range sq.columns {
-		parts = append(parts, sq.formatSelectColumn(col, dialect))
+		b.writeListSeparator("", n)
+		n++
+		if col != "" && !strings.ContainsAny(col, selectExprChars) && !unicode.IsDigit(rune(col[0])) {
+			// Plain column name without alias.
+			b.writeColumn(col, dialect)
+			continue
+		}
+		b.WriteString(sq.formatSelectColumn(col, dialect))
 	}
 
 	for _, expr := range sq.selectExprs {
-		parts = append(parts, expr.SQL)
+		b.writeListSeparator("", n)
+		n++
+		b.WriteString(expr.SQL)
 	}
 
 	// Add type-safe subquery expressions (pre-built with dialect quoting and renumbered placeholders)
@@ -905,24 +1383,38 @@ func (sq *SelectQuery) buildSelect(dialect dialects.Dialect, renderedSubExprs []
 		if i < len(renderedSubExprs) {
 			sqlFrag = renderedSubExprs[i]
 		}
-		parts = append(parts, "("+sqlFrag+") AS "+dialect.QuoteIdentifier(sub.alias))
-	}
-
-	columns := "*"
-	if len(parts) > 0 {
-		columns = strings.Join(parts, ", ")
+		if sub.alias == "" {
+			// SelectExp: expression rendered as-is (may carry its own alias)
+			if sqlFrag != "" {
+				b.writeListSeparator("", n)
+				n++
+				b.WriteString(sqlFrag)
+			}
+			continue
+		}
+		b.writeListSeparator("", n)
+		n++
+		b.WriteByte('(')
+		b.WriteString(sqlFrag)
+		b.WriteString(") AS ")
+		b.writeIdentifier(sub.alias, dialect)
 	}
 
-	if sq.distinct {
-		return "DISTINCT " + columns
+	if n == 0 {
+		b.WriteByte('*')
 	}
-	return columns
 }
 
 // GroupBy adds GROUP BY clause.
 // Multiple columns supported: GroupBy("user_id", "status")
 // Chainable: GroupBy("a").GroupBy("b")
+//
+// Plain identifiers ("status", "u.id") are quoted; anything else
+// ("DATE(created_at)", "created_at::date", "price * qty") is emitted as-is.
+// Terms containing ";" or a comment fail the query.
+// Use GroupBySub for type-safe expressions.
 func (sq *SelectQuery) GroupBy(columns ...string) *SelectQuery {
+	sq = sq.own()
 	sq.groupBy = append(sq.groupBy, columns...)
 	return sq
 }
@@ -935,45 +1427,178 @@ func (sq *SelectQuery) GroupBy(columns ...string) *SelectQuery {
 //	GroupByExpr("DATE(created_at)")
 //	GroupByExpr("EXTRACT(YEAR FROM order_date)")
 func (sq *SelectQuery) GroupByExpr(expr string, args ...interface{}) *SelectQuery {
+	sq = sq.own()
 	sq.groupByExprs = append(sq.groupByExprs, RawExp{SQL: expr, Args: args})
 	return sq
 }
 
 // GroupBySub adds a type-safe expression to the GROUP BY clause.
+//
+// Example:
+//
+//	GroupBySub(relica.DateTrunc("day", "created_at"))
 func (sq *SelectQuery) GroupBySub(exp Expression) *SelectQuery {
+	sq = sq.own()
+	if err := checkExpression(exp, sq.builder.db.dialect); err != nil {
+		sq.buildErr = err
+		return sq
+	}
 	sq.subGroupByExprs = append(sq.subGroupByExprs, exp)
 	return sq
 }
 
-// buildGroupBy constructs the GROUP BY clause from the groupBy slice.
-// Returns empty string if no GROUP BY is specified.
-// Quotes column names using dialect.
-func (sq *SelectQuery) buildGroupBy(dialect dialects.Dialect) string {
-	if len(sq.groupBy) == 0 && len(sq.groupByExprs) == 0 && len(sq.subGroupByExprs) == 0 {
-		return ""
+// GroupByRollup adds a ROLLUP grouping producing subtotals for each prefix of columns
+// plus a grand total.
+//
+// Generated SQL:
+//   - PostgreSQL: GROUP BY ROLLUP ("region", "product")
+//   - MySQL:      GROUP BY `region`, `product` WITH ROLLUP
+//
+// SQLite does not support ROLLUP; building the query returns an error.
+// On MySQL, ROLLUP cannot be combined with other grouping elements.
+func (sq *SelectQuery) GroupByRollup(columns ...string) *SelectQuery {
+	return sq.addGroupingElem("ROLLUP", [][]string{columns})
+}
+
+// GroupByCube adds a CUBE grouping producing subtotals for every combination of columns.
+// Supported on PostgreSQL only: GROUP BY CUBE ("region", "product").
+func (sq *SelectQuery) GroupByCube(columns ...string) *SelectQuery {
+	return sq.addGroupingElem("CUBE", [][]string{columns})
+}
+
+// GroupingSets adds explicit GROUPING SETS. An empty set produces the grand total row.
+// Supported on PostgreSQL only.
+//
+// Example:
+//
+//	GroupingSets([]string{"region", "product"}, []string{"region"}, []string{})
+//	// GROUP BY GROUPING SETS (("region", "product"), ("region"), ())
+func (sq *SelectQuery) GroupingSets(sets ...[]string) *SelectQuery {
+	return sq.addGroupingElem("GROUPING SETS", sets)
+}
+
+// addGroupingElem validates and stores an advanced grouping element.
+func (sq *SelectQuery) addGroupingElem(kind string, sets [][]string) *SelectQuery {
+	sq = sq.own()
+	if len(sets) == 0 || (kind != "GROUPING SETS" && len(sets[0]) == 0) {
+		sq.buildErr = fmt.Errorf("relica: %s requires at least one column", kind)
+		return sq
+	}
+	sq.groupingElems = append(sq.groupingElems, groupingElem{kind: kind, sets: sets})
+	return sq
+}
+
+// quoteGroupByTerm quotes plain identifiers and passes expressions through
+// unchanged, returning the error of an unsafe expression.
+func (sq *SelectQuery) quoteGroupByTerm(term string, dialect dialects.Dialect) (string, error) {
+	term = strings.TrimSpace(term)
+	if plainIdentifierRegex.MatchString(term) {
+		return sq.quoteColumnName(term, dialect), nil
+	}
+	return term, checkClauseTerm("GROUP BY", term)
+}
+
+// buildGroupingElems renders ROLLUP / CUBE / GROUPING SETS for the dialect.
+// Returns the GROUP BY parts and an optional clause suffix (MySQL "WITH ROLLUP"),
+// or the error of a feature the dialect does not support.
+func (sq *SelectQuery) buildGroupingElems(dialect dialects.Dialect, hasOtherParts bool) ([]string, string, error) {
+	var err error
+	quoteSet := func(set []string) string {
+		quoted := make([]string, len(set))
+		for i, col := range set {
+			var termErr error
+			quoted[i], termErr = sq.quoteGroupByTerm(col, dialect)
+			if err == nil {
+				err = termErr
+			}
+		}
+		return strings.Join(quoted, ", ")
 	}
 
-	parts := make([]string, 0, len(sq.groupBy)+len(sq.groupByExprs))
+	switch dialect.(type) {
+	case *dialects.SQLiteDialect:
+		return nil, "", fmt.Errorf("relica: %s is not supported by SQLite", sq.groupingElems[0].kind)
+	case *dialects.MySQLDialect:
+		// MySQL only supports a single trailing WITH ROLLUP modifier.
+		elem := sq.groupingElems[0]
+		if elem.kind != "ROLLUP" {
+			return nil, "", fmt.Errorf("relica: %s is not supported by MySQL", elem.kind)
+		}
+		if len(sq.groupingElems) > 1 || hasOtherParts {
+			return nil, "", fmt.Errorf("relica: MySQL WITH ROLLUP cannot be combined with other GROUP BY elements")
+		}
+		parts := []string{quoteSet(elem.sets[0])}
+		return parts, " WITH ROLLUP", err
+	}
+
+	parts := make([]string, 0, len(sq.groupingElems))
+	for _, elem := range sq.groupingElems {
+		if elem.kind != "GROUPING SETS" {
+			parts = append(parts, elem.kind+" ("+quoteSet(elem.sets[0])+")")
+			continue
+		}
+		sets := make([]string, len(elem.sets))
+		for i, set := range elem.sets {
+			sets[i] = "(" + quoteSet(set) + ")"
+		}
+		parts = append(parts, "GROUPING SETS ("+strings.Join(sets, ", ")+")")
+	}
+	return parts, "", err
+}
+
+// writeGroupBy writes the GROUP BY clause, if any, quoting column names using
+// dialect. Expression parameters are appended to params and their placeholders
+// renumbered.
+func (sq *SelectQuery) writeGroupBy(b *sqlBuffer, dialect dialects.Dialect, params *[]interface{}) {
+	n := 0
 	for _, col := range sq.groupBy {
-		parts = append(parts, sq.quoteColumnName(col, dialect))
+		b.writeListSeparator(" GROUP BY ", n)
+		n++
+		if term := strings.TrimSpace(col); plainIdentifierRegex.MatchString(term) {
+			b.writeColumn(term, dialect)
+			continue
+		}
+		term, err := sq.quoteGroupByTerm(col, dialect)
+		b.fail(err)
+		b.WriteString(term)
 	}
 
 	// Append raw GROUP BY expressions (DATE, EXTRACT, CASE)
 	for _, expr := range sq.groupByExprs {
-		parts = append(parts, expr.SQL)
+		b.writeListSeparator(" GROUP BY ", n)
+		n++
+		b.writeNumbered(expr.SQL, len(*params)+1, len(expr.Args), dialect)
+		*params = append(*params, expr.Args...)
 	}
 
 	// Append type-safe GROUP BY expressions
 	for _, exp := range sq.subGroupByExprs {
-		expSQL, _ := exp.Build(dialect)
-		parts = append(parts, expSQL)
+		expSQL, expArgs := exp.Build(dialect)
+		b.writeListSeparator(" GROUP BY ", n)
+		n++
+		b.writeNumbered(expSQL, len(*params)+1, len(expArgs), dialect)
+		*params = append(*params, expArgs...)
+	}
+
+	// Append ROLLUP / CUBE / GROUPING SETS
+	if len(sq.groupingElems) > 0 {
+		elemParts, suffix, err := sq.buildGroupingElems(dialect, n > 0)
+		b.fail(err)
+		for _, part := range elemParts {
+			b.writeListSeparator(" GROUP BY ", n)
+			n++
+			b.WriteString(part)
+		}
+		if n == 0 {
+			// Unsupported grouping (the statement failed): keep the clause keyword.
+			b.WriteString(" GROUP BY ")
+		}
+		b.WriteString(suffix)
 	}
-
-	return " GROUP BY " + strings.Join(parts, ", ")
 }
 
 // Having adds HAVING clause (WHERE for aggregates).
-// Accepts string or Expression (same as Where).
+// Accepts string or Expression (same as Where), including named {:name} placeholders.
 // Multiple calls are combined with AND.
 //
 // String example:
@@ -984,113 +1609,157 @@ func (sq *SelectQuery) buildGroupBy(dialect dialects.Dialect) string {
 //
 //	Having(relica.GreaterThan("COUNT(*)", 100))
 func (sq *SelectQuery) Having(condition interface{}, args ...interface{}) *SelectQuery {
-	switch cond := condition.(type) {
-	case string:
-		// String-based HAVING
-		sq.havingClauses = append(sq.havingClauses, struct {
-			condition string
-			args      []interface{}
-		}{
-			condition: cond,
-			args:      args,
-		})
-
-	case Expression:
-		// Expression-based HAVING
-		sqlStr, exprArgs := cond.Build(sq.builder.db.dialect)
-		if sqlStr != "" {
-			sq.havingClauses = append(sq.havingClauses, struct {
-				condition string
-				args      []interface{}
-			}{
-				condition: sqlStr,
-				args:      exprArgs,
-			})
-		}
-
-	default:
-		sq.buildErr = fmt.Errorf("relica: Having() expects string or Expression, got %T", condition)
+	sq = sq.own()
+	condSQL, condArgs, ok := sq.buildHavingCondition("Having", condition, args)
+	if ok {
+		sq.havingClauses = append(sq.havingClauses, havingClause{condition: condSQL, args: condArgs})
 	}
-
 	return sq
 }
 
-// buildHaving constructs the HAVING clause from the havingClauses slice.
-// Returns empty string if no HAVING is specified.
-// Multiple clauses are combined with AND.
-// Appends parameters to params slice.
-func (sq *SelectQuery) buildHaving(params *[]interface{}) string {
+// AndHaving adds a HAVING condition with AND logic.
+// If no existing HAVING clause exists, behaves like Having().
+//
+// Example:
+//
+//	Having("COUNT(*) > ?", 10).AndHaving("SUM(total) < ?", 1000)
+func (sq *SelectQuery) AndHaving(condition interface{}, args ...interface{}) *SelectQuery {
+	// Simply delegate to Having() which already uses AND logic for multiple calls.
+	return sq.Having(condition, args...)
+}
+
+// OrHaving adds a HAVING condition with OR logic.
+// If no existing HAVING clause exists, behaves like Having().
+// The new condition is combined with existing conditions using OR, mirroring OrWhere.
+// Conditions added afterwards are ANDed with the whole OR group.
+//
+// Example:
+//
+//	Having("COUNT(*) > ?", 100).OrHaving(relica.GreaterThan("SUM(total)", 5000))
+//	// HAVING (COUNT(*) > ?) OR (SUM(total) > ?)
+func (sq *SelectQuery) OrHaving(condition interface{}, args ...interface{}) *SelectQuery {
+	sq = sq.own()
 	if len(sq.havingClauses) == 0 {
-		return ""
+		// No existing HAVING clause - just add it.
+		return sq.Having(condition, args...)
 	}
 
-	parts := make([]string, 0, len(sq.havingClauses))
-	for _, clause := range sq.havingClauses {
-		parts = append(parts, clause.condition)
-		*params = append(*params, clause.args...)
+	newSQL, newArgs, ok := sq.buildHavingCondition("OrHaving", condition, args)
+	if !ok {
+		return sq
 	}
 
-	return " HAVING " + strings.Join(parts, " AND ")
+	// Combine existing HAVING with new condition using OR.
+	// Wrap both sides in parentheses for correct precedence.
+	existing := make([]string, len(sq.havingClauses))
+	var combinedArgs []interface{}
+	for i, c := range sq.havingClauses {
+		existing[i] = c.condition
+		combinedArgs = append(combinedArgs, c.args...)
+	}
+	combinedArgs = append(combinedArgs, newArgs...)
+
+	sq.havingClauses = []havingClause{{
+		condition: "(" + strings.Join(existing, " AND ") + ") OR (" + newSQL + ")",
+		args:      combinedArgs,
+		orGroup:   true,
+	}}
+
+	return sq
 }
 
-// renumberHavingPlaceholders renumbers placeholders in HAVING clause for PostgreSQL.
-// For databases using positional placeholders ($1, $2), replaces ? with numbered placeholders.
-func (sq *SelectQuery) renumberHavingPlaceholders(havingClause string, totalParams int, dialect dialects.Dialect) string {
-	if dialect.Placeholder(1) == "?" || len(sq.havingClauses) == 0 {
-		return havingClause
-	}
+// buildHavingCondition converts a Having/OrHaving argument into SQL and parameters.
+// Returns ok=false if the condition is empty or invalid (the error is stored in buildErr).
+func (sq *SelectQuery) buildHavingCondition(method string, condition interface{}, args []interface{}) (string, []interface{}, bool) {
+	switch cond := condition.(type) {
+	case string:
+		// String-based HAVING (positional or named placeholders)
+		resolved, resolvedArgs, err := resolveNamedParams(cond, args)
+		if err != nil {
+			sq.buildErr = err
+			return "", nil, false
+		}
+		return resolved, resolvedArgs, true
 
-	// Count HAVING arg count to determine starting placeholder index.
-	// totalParams already includes HAVING args (appended by buildHaving via pointer),
-	// so subtract the total number of HAVING args to get the pre-HAVING param count.
-	havingArgCount := 0
-	for _, c := range sq.havingClauses {
-		havingArgCount += len(c.args)
-	}
-	currentParamCount := totalParams - havingArgCount
-	for i := 0; i < len(sq.havingClauses); i++ {
-		for range sq.havingClauses[i].args {
-			currentParamCount++
-			placeholder := dialect.Placeholder(currentParamCount)
-			havingClause = strings.Replace(havingClause, "?", placeholder, 1)
+	case Expression:
+		// Expression-based HAVING
+		if err := checkExpressionTree(cond, sq.builder.db.dialect); err != nil {
+			sq.buildErr = err
+			return "", nil, false
 		}
+		sqlStr, exprArgs := cond.Build(sq.builder.db.dialect)
+		return sqlStr, exprArgs, sqlStr != ""
+
+	default:
+		sq.buildErr = fmt.Errorf("relica: %s() expects string or Expression, got %T", method, condition)
+		return "", nil, false
 	}
+}
 
-	return havingClause
+// writeHaving writes the HAVING clause, if any, combining multiple clauses
+// with AND. Appends parameters to params and numbers their placeholders for
+// PostgreSQL.
+func (sq *SelectQuery) writeHaving(b *sqlBuffer, dialect dialects.Dialect, params *[]interface{}) {
+	for i, clause := range sq.havingClauses {
+		if i == 0 {
+			b.WriteString(" HAVING ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		if clause.orGroup && len(sq.havingClauses) > 1 {
+			// Keep the OR group together when ANDed with later conditions.
+			b.WriteByte('(')
+			b.writeNumberedCount(clause.condition, len(*params)+1, len(clause.args), dialect)
+			b.WriteByte(')')
+		} else {
+			b.writeNumberedCount(clause.condition, len(*params)+1, len(clause.args), dialect)
+		}
+		*params = append(*params, clause.args...)
+	}
 }
 
-// buildWhere constructs the WHERE clause from the where slice.
-// Returns empty string if no WHERE is specified.
-// Multiple clauses are combined with AND.
-// Appends parameters to params slice and handles placeholder renumbering for PostgreSQL.
-func (sq *SelectQuery) buildWhere(dialect dialects.Dialect, params *[]interface{}) string {
-	if len(sq.where) == 0 {
-		return ""
+// fragmentSQL builds sq for embedding in an expression. Its placeholders are
+// "?" like those of other expressions, so the enclosing query renumbers them
+// together with its own parameters.
+func (sq *SelectQuery) fragmentSQL(dialect dialects.Dialect) (string, []interface{}) {
+	// Expression.Build cannot fail; expressions that check their subquery
+	// (see checkedExpression) report its stored error instead.
+	sqlStr, args, _ := sq.buildSQL(dialect)
+	if len(args) == 0 || !numberedPlaceholders(dialect) {
+		return sqlStr, args
 	}
+	b := getSQLBuffer()
+	defer b.release()
+	b.writeUnnumbered(sqlStr, len(args))
+	return b.String(), args
+}
 
-	whereParams := sq.params
-	whereClause := " WHERE " + strings.Join(sq.where, " AND ")
+// writeWhere writes the WHERE clause, if any, combining multiple conditions
+// with AND. Appends parameters to params and numbers their placeholders for
+// PostgreSQL ($1, $2, etc.) after the preceding parameters.
+func (sq *SelectQuery) writeWhere(b *sqlBuffer, dialect dialects.Dialect, params *[]interface{}) {
+	pred := sq.samplePredicate(dialect)
+	if len(sq.where) == 0 && pred == "" {
+		return
+	}
 
-	// Renumber WHERE placeholders for PostgreSQL ($1, $2, etc.)
-	if dialect.Placeholder(1) != "?" {
-		// Start numbering after CTE + SelectExpr + FROM + JOIN params
-		startIndex := len(*params) + 1
-		for i := range whereParams {
-			placeholder := dialect.Placeholder(startIndex + i)
-			whereClause = strings.Replace(whereClause, "?", placeholder, 1)
+	b.WriteString(" WHERE ")
+	b.writeConditions(sq.where, len(*params)+1, len(sq.params), dialect)
+	if pred != "" {
+		if len(sq.where) > 0 {
+			b.WriteString(" AND ")
 		}
+		b.WriteString(pred)
 	}
 
-	// Append WHERE params after CTE + SelectExpr + FROM + JOIN params
-	*params = append(*params, whereParams...)
-
-	return whereClause
+	*params = append(*params, sq.params...)
 }
 
 // buildWithClause generates the WITH clause for CTEs.
-func (sq *SelectQuery) buildWithClause(dialect dialects.Dialect) (string, []interface{}) {
-	if len(sq.ctes) == 0 {
-		return "", nil
+// Placeholders of each CTE body are renumbered to follow the preceding CTEs.
+func buildWithClause(ctes []cteInfo, dialect dialects.Dialect) (string, []interface{}, error) {
+	if len(ctes) == 0 {
+		return "", nil, nil
 	}
 
 	var parts []string
@@ -1098,7 +1767,7 @@ func (sq *SelectQuery) buildWithClause(dialect dialects.Dialect) (string, []inte
 
 	// Check if any CTE is recursive
 	hasRecursive := false
-	for _, cte := range sq.ctes {
+	for _, cte := range ctes {
 		if cte.recursive {
 			hasRecursive = true
 			break
@@ -1113,15 +1782,26 @@ func (sq *SelectQuery) buildWithClause(dialect dialects.Dialect) (string, []inte
 	}
 
 	// Build each CTE
-	cteStrings := make([]string, 0, len(sq.ctes))
-	for _, cte := range sq.ctes {
-		cteSQL, cteArgs := cte.query.buildSQL(dialect)
+	cteStrings := make([]string, 0, len(ctes))
+	for _, cte := range ctes {
+		if err := validateCTE("With", cte.name, cte.query); err != nil {
+			return "", nil, err
+		}
+		cteSQL, cteArgs, err := cte.query.buildCTE(dialect)
+		if err != nil {
+			return "", nil, err
+		}
+		cteSQL = renumberFragment(cteSQL, len(allArgs)+1, len(cteArgs), dialect)
 
 		// Quote CTE name
 		quotedName := dialect.QuoteIdentifier(cte.name)
 
-		// Format: cte_name AS (cte_query)
-		cteString := quotedName + " AS (" + cteSQL + ")"
+		// Format: cte_name AS [[NOT] MATERIALIZED] (cte_query)
+		cteString := quotedName + " AS "
+		if hint := cteHint(cte.hint, dialect); hint != "" {
+			cteString += hint + " "
+		}
+		cteString += "(" + cteSQL + ")"
 		cteStrings = append(cteStrings, cteString)
 		allArgs = append(allArgs, cteArgs...)
 	}
@@ -1129,136 +1809,167 @@ func (sq *SelectQuery) buildWithClause(dialect dialects.Dialect) (string, []inte
 	// Join CTEs with commas
 	parts = append(parts, strings.Join(cteStrings, ", "))
 
-	return strings.Join(parts, " "), allArgs
+	return strings.Join(parts, " "), allArgs, nil
+}
+
+// prependWithClause prefixes a built statement with the given CTEs and shifts the
+// statement's placeholders past the CTE parameters.
+func prependWithClause(ctes []cteInfo, stmt string, params []interface{}, dialect dialects.Dialect) (string, []interface{}, error) {
+	if len(ctes) == 0 {
+		return stmt, params, nil
+	}
+	withClause, withArgs, err := buildWithClause(ctes, dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	stmt = renumberFragment(stmt, len(withArgs)+1, len(params), dialect)
+	return withClause + " " + stmt, append(withArgs, params...), nil
+}
+
+// requireDataModifyingCTE returns an error unless the dialect supports INSERT/UPDATE/DELETE
+// statements inside WITH. Only PostgreSQL does; MySQL and SQLite allow SELECT only.
+func requireDataModifyingCTE(dialect dialects.Dialect) error {
+	if _, ok := dialect.(*dialects.PostgresDialect); !ok {
+		return fmt.Errorf("relica: data-modifying CTEs (INSERT/UPDATE/DELETE inside WITH) are only supported by PostgreSQL")
+	}
+	return nil
+}
+
+// buildCTE implements CTEQuery.
+func (sq *SelectQuery) buildCTE(dialect dialects.Dialect) (string, []interface{}, error) {
+	if sq.buildErr != nil {
+		return "", nil, sq.buildErr
+	}
+	return sq.buildSQL(dialect)
+}
+
+// cteHint returns the materialization hint if the dialect supports it.
+// MySQL has no MATERIALIZED syntax, so the hint is dropped there.
+func cteHint(hint string, dialect dialects.Dialect) string {
+	if _, ok := dialect.(*dialects.MySQLDialect); ok {
+		return ""
+	}
+	return hint
 }
 
 // buildSQL constructs the SQL string and parameters for SelectQuery.
 // This is the core implementation shared by both Build() and the Expression interface.
-// Parameter ordering: CTEs → SelectExprs → SubExprs → FROM subquery → JOINs → WHERE → HAVING → GroupByExprs → OrderByExprs
+// Parameter ordering: CTEs → SelectExprs → SubExprs → FROM subquery → JOINs → WHERE → GroupByExprs → HAVING → OrderByExprs
 //
-//nolint:cyclop // Central query assembly requires sequential clause building; splitting would reduce clarity.
-func (sq *SelectQuery) buildSQL(dialect dialects.Dialect) (string, []interface{}) {
+// The statement is rendered in a single pass into a pooled buffer; the
+// parameter order is the order of the clauses in the SQL text. The error is
+// the stored build error of sq or the first error met while rendering; sq
+// itself is not modified, so shared Immutable queries can be built
+// concurrently.
+func (sq *SelectQuery) buildSQL(dialect dialects.Dialect) (string, []interface{}, error) {
+	b := getSQLBuffer()
+	defer b.release()
+
 	// Collect all parameters in correct order
 	var allParams []interface{}
-	var parts []string
+	if n := sq.paramCountHint(); n > 0 {
+		allParams = make([]interface{}, 0, n)
+	}
 
-	// 1. Build WITH clause if CTEs exist
+	// Planner hint comments: before the statement (PostgreSQL) or after SELECT (MySQL, SQLite)
+	statementHint, selectHint := sq.hintPrefixes(dialect)
+	b.WriteString(statementHint)
+
+	// 1. WITH clause if CTEs exist
 	if len(sq.ctes) > 0 {
-		withClause, withArgs := sq.buildWithClause(dialect)
-		parts = append(parts, withClause)
+		withClause, withArgs, err := buildWithClause(sq.ctes, dialect)
+		b.fail(err)
+		b.WriteString(withClause)
+		b.WriteByte(' ')
 		allParams = append(allParams, withArgs...)
 	}
 
-	// 2. Add params from SelectExpr (raw scalar subqueries in SELECT clause)
+	// Set operations wrap the first member like the others (see wrapSetMember)
+	closeMember := ""
+	if len(sq.unions) > 0 {
+		closeMember = b.openSetMember(sq.hasOwnOrderOrLimit(), dialect)
+	}
+
+	// 2. Params from SelectExpr (raw scalar subqueries in SELECT clause)
 	for _, expr := range sq.selectExprs {
 		allParams = append(allParams, expr.Args...)
 	}
 
-	// 3. Build type-safe subquery SELECT expressions (SelectSub).
+	// 3. Type-safe subquery SELECT expressions (SelectSub).
 	// Each expression is built with the correct dialect, then its placeholders are renumbered
 	// so they continue from the current allParams count (for PostgreSQL $N style).
-	renderedSubExprs := make([]string, len(sq.subExprs))
-	for i, sub := range sq.subExprs {
-		subSQL, subArgs := sub.exp.Build(dialect)
-		if dialect.Placeholder(1) != "?" && len(subArgs) > 0 {
-			// Renumber placeholders from $1 to the correct offset.
-			startIndex := len(allParams) + 1
-			for j := range subArgs {
-				oldPlaceholder := dialect.Placeholder(j + 1)
-				newPlaceholder := dialect.Placeholder(startIndex + j)
-				subSQL = strings.Replace(subSQL, oldPlaceholder, newPlaceholder, 1)
-			}
+	var renderedSubExprs []string
+	if len(sq.subExprs) > 0 {
+		renderedSubExprs = make([]string, len(sq.subExprs))
+		for i, sub := range sq.subExprs {
+			subSQL, subArgs := sub.exp.Build(dialect)
+			renderedSubExprs[i] = renumberFragment(subSQL, len(allParams)+1, len(subArgs), dialect)
+			allParams = append(allParams, subArgs...)
 		}
-		renderedSubExprs[i] = subSQL
-		allParams = append(allParams, subArgs...)
 	}
 
-	// 4. Build SELECT clause (handles aggregates, raw expressions, and subExprs)
-	cols := sq.buildSelect(dialect, renderedSubExprs)
-
-	// 5. Build FROM clause (may be table or subquery)
-	fromClause := sq.buildFrom(dialect, &allParams)
-
-	// 6. Build JOIN clause (adds params via pointer)
-	joinClause := sq.buildJoins(dialect, &allParams)
+	// 4. SELECT clause (handles aggregates, raw expressions, and subExprs)
+	b.WriteString("SELECT ")
+	b.WriteString(selectHint)
+	sq.writeSelect(b, dialect, renderedSubExprs)
 
-	// 7. Build WHERE clause (adds params via pointer)
-	whereClause := sq.buildWhere(dialect, &allParams)
+	// 5-10. FROM (table or subquery), JOIN, WHERE, GROUP BY, HAVING and
+	// ORDER BY, each appending its params
+	sq.writeFrom(b, dialect, &allParams)
+	sq.writeJoins(b, dialect, &allParams)
+	sq.writeWhere(b, dialect, &allParams)
+	sq.writeGroupBy(b, dialect, &allParams)
+	sq.writeHaving(b, dialect, &allParams)
+	sq.writeOrderBy(b, dialect, &allParams)
 
-	// 9. Build GROUP BY clause
-	groupByClause := sq.buildGroupBy(dialect)
+	// 11. LIMIT/OFFSET
+	sq.writeQueryLimitOffset(b, sq.limitValue, sq.offsetValue, dialect, &allParams)
 
-	// 10. Build HAVING clause (adds params via pointer)
-	havingClause := sq.buildHaving(&allParams)
-
-	// Renumber HAVING placeholders if needed (PostgreSQL)
-	havingClause = sq.renumberHavingPlaceholders(havingClause, len(allParams), dialect)
-
-	// 10a. Collect GROUP BY expression params
-	for _, expr := range sq.groupByExprs {
-		allParams = append(allParams, expr.Args...)
-	}
-	for _, exp := range sq.subGroupByExprs {
-		_, subArgs := exp.Build(dialect)
-		allParams = append(allParams, subArgs...)
+	// 12. Set operations (UNION, INTERSECT, EXCEPT)
+	if len(sq.unions) > 0 {
+		b.WriteString(closeMember)
+		allParams = sq.writeSetOperations(b, allParams, dialect)
 	}
 
-	// 11. Build ORDER BY clause
-	orderByClause := sq.buildOrderBy(dialect)
+	err := sq.buildErr
+	if err == nil {
+		err = b.err
+	}
+	return b.String(), allParams, err
+}
 
-	// 11a. Collect ORDER BY expression params
-	for _, expr := range sq.orderByExprs {
-		allParams = append(allParams, expr.Args...)
+// paramCountHint returns the number of parameters of sq's own clauses, to
+// size the parameter slice of buildSQL.
+func (sq *SelectQuery) paramCountHint() int {
+	n := len(sq.params)
+	for _, expr := range sq.selectExprs {
+		n += len(expr.Args)
 	}
-	for _, exp := range sq.subOrderByExprs {
-		_, subArgs := exp.Build(dialect)
-		allParams = append(allParams, subArgs...)
+	for _, expr := range sq.groupByExprs {
+		n += len(expr.Args)
 	}
-
-	// 12. Build LIMIT/OFFSET clause
-	limitOffsetClause := sq.buildLimitOffset()
-
-	// Construct SQL: SELECT ... FROM ... JOIN ... WHERE ... GROUP BY ... HAVING ... ORDER BY ... LIMIT ... OFFSET
-	query := "SELECT " + cols + fromClause + joinClause + whereClause + groupByClause + havingClause + orderByClause + limitOffsetClause
-
-	// 12. Handle set operations (UNION, INTERSECT, EXCEPT)
-	if len(sq.unions) > 0 {
-		mainSQL, finalParams := sq.buildSetOperations(query, allParams, dialect)
-		// Prepend WITH clause if exists
-		if len(parts) > 0 {
-			mainSQL = strings.Join(parts, " ") + " " + mainSQL
-		}
-		return mainSQL, finalParams
+	for _, c := range sq.havingClauses {
+		n += len(c.args)
 	}
-
-	// Prepend WITH clause if exists
-	if len(parts) > 0 {
-		query = strings.Join(parts, " ") + " " + query
+	for _, expr := range sq.orderByExprs {
+		n += len(expr.Args)
 	}
-
-	return query, allParams
+	return n
 }
 
-// buildSetOperations handles UNION, INTERSECT, EXCEPT operations.
-// This method is extracted from buildSQL to reduce cognitive complexity.
-func (sq *SelectQuery) buildSetOperations(mainQuery string, allParams []interface{}, dialect dialects.Dialect) (string, []interface{}) {
-	// Wrap main query in parentheses
-	mainSQL := "(" + mainQuery + ")"
-
+// writeSetOperations writes the UNION, INTERSECT and EXCEPT members following
+// the first member and returns allParams with their parameters appended.
+//
+// PostgreSQL and MySQL wrap each member in parentheses. SQLite does not accept
+// parenthesized members, so members are emitted bare, and members with their own
+// ORDER BY/LIMIT (or nested set operations) are wrapped as SELECT * FROM (...).
+// UnionOrderBy/UnionLimit/UnionOffset are appended after the last member and
+// apply to the combined result.
+func (sq *SelectQuery) writeSetOperations(b *sqlBuffer, allParams []interface{}, dialect dialects.Dialect) []interface{} {
 	for _, u := range sq.unions {
 		// Build union query SQL
-		unionSQL, unionArgs := u.query.buildSQL(dialect)
-
-		// Renumber placeholders if needed (PostgreSQL)
-		if dialect.Placeholder(1) != "?" {
-			// Renumber placeholders to continue from current parameter count
-			startIndex := len(allParams) + 1
-			for i := 0; i < len(unionArgs); i++ {
-				oldPlaceholder := dialect.Placeholder(i + 1)
-				newPlaceholder := dialect.Placeholder(startIndex + i)
-				unionSQL = strings.Replace(unionSQL, oldPlaceholder, newPlaceholder, 1)
-			}
-		}
+		unionSQL, unionArgs, err := u.query.buildSQL(dialect)
+		b.fail(err)
 
 		// Determine operation keyword
 		op := u.op
@@ -1270,13 +1981,51 @@ func (sq *SelectQuery) buildSetOperations(mainQuery string, allParams []interfac
 		}
 
 		// Append set operation: (query1) UNION (query2)
-		mainSQL += " " + op + " (" + unionSQL + ")"
+		nested := u.query.hasOwnOrderOrLimit() || len(u.query.unions) > 0 || len(u.query.ctes) > 0
+		b.WriteByte(' ')
+		b.WriteString(op)
+		b.WriteByte(' ')
+		closeMember := b.openSetMember(nested, dialect)
+		if numberedPlaceholders(dialect) {
+			// Shift placeholders to continue from current parameter count (PostgreSQL)
+			b.writeShifted(unionSQL, len(allParams)+1, len(unionArgs), dialect)
+		} else {
+			b.WriteString(unionSQL)
+		}
+		b.WriteString(closeMember)
 
 		// Merge parameters in order
 		allParams = append(allParams, unionArgs...)
 	}
 
-	return mainSQL, allParams
+	// ORDER BY / LIMIT / OFFSET for the combined result
+	if _, err := b.writeOrderByTerms(sq.unionOrderBy, dialect); err != nil {
+		b.fail(err)
+	}
+	sq.writeQueryLimitOffset(b, sq.unionLimit, sq.unionOffset, dialect, &allParams)
+
+	return allParams
+}
+
+// hasOwnOrderOrLimit reports whether the query has ORDER BY, LIMIT or OFFSET of its own.
+func (sq *SelectQuery) hasOwnOrderOrLimit() bool {
+	return len(sq.orderBy) > 0 || len(sq.orderByExprs) > 0 || len(sq.subOrderByExprs) > 0 ||
+		sq.limitValue != nil || sq.offsetValue != nil
+}
+
+// openSetMember writes the opening of one member of a set operation for the
+// dialect and returns its closing. needsSubquery marks members that SQLite can
+// only accept as a derived table.
+func (b *sqlBuffer) openSetMember(needsSubquery bool, dialect dialects.Dialect) string {
+	if _, ok := dialect.(*dialects.SQLiteDialect); ok {
+		if needsSubquery {
+			b.WriteString("SELECT * FROM (")
+			return ")"
+		}
+		return ""
+	}
+	b.WriteByte('(')
+	return ")"
 }
 
 // Build constructs the Query object from SelectQuery.
@@ -1291,34 +2040,49 @@ func (sq *SelectQuery) Build() *Query {
 	}
 
 	// Return a Query that carries the build error; all execution methods check prepErr.
-	if sq.buildErr != nil {
+	// Chunked queries run several statements, which only All/Column can merge.
+	if sq.buildErr != nil || sq.chunkedIn != nil {
+		err := sq.buildErr
+		if err == nil {
+			err = errChunkedExecution
+		}
 		return &Query{
-			prepErr: sq.buildErr,
+			prepErr: err,
 			db:      sq.builder.db,
 			tx:      sq.builder.tx,
+			tag:     sq.builder.tag,
 			ctx:     ctx,
 		}
 	}
 
-	query, allParams := sq.buildSQL(sq.builder.db.dialect)
-
-	// buildJoins / buildSQL may set buildErr when encountering bad JOIN ON type.
-	if sq.buildErr != nil {
+	query, allParams, err := sq.buildSQL(sq.builder.db.dialect)
+	if err != nil {
 		return &Query{
-			prepErr: sq.buildErr,
+			prepErr: err,
 			db:      sq.builder.db,
 			tx:      sq.builder.tx,
+			tag:     sq.builder.tag,
 			ctx:     ctx,
 		}
 	}
 
-	return &Query{
+	db := sq.builder.db
+	if sq.builder.tx == nil {
+		db = db.readDB(query, sq.freshness)
+	}
+	q := &Query{
 		sql:    query,
 		params: allParams,
-		db:     sq.builder.db,
+		db:     db,
 		tx:     sq.builder.tx,
+		tag:    sq.builder.tag,
 		ctx:    ctx,
+		refs:   sq.selectSchemaRefs(),
+	}
+	if sq.readOnly {
+		q.prepErr = readOnlyViolation(query)
 	}
+	return sq.builder.db.guard(q)
 }
 
 // One scans a single row into dest.
@@ -1326,9 +2090,22 @@ func (sq *SelectQuery) One(dest interface{}) error {
 	return sq.Build().One(dest)
 }
 
-// All scans all rows into dest slice.
+// All scans all rows into dest slice, appending them to its contents.
+// When no rows match, a nil dest stays nil (see WithEmptySlices).
 func (sq *SelectQuery) All(dest interface{}) error {
-	return sq.Build().All(dest)
+	var err error
+	switch {
+	case sq.chunkedIn != nil:
+		err = sq.allChunked(dest, false)
+	case sq.usesCursor():
+		err = sq.allCursor(dest)
+	default:
+		return sq.Build().All(dest)
+	}
+	if err == nil {
+		sq.builder.db.fillEmptySlice(dest)
+	}
+	return err
 }
 
 // Row scans a single row into individual variables.
@@ -1351,6 +2128,13 @@ func (sq *SelectQuery) Row(dest ...interface{}) error {
 //	var ids []int
 //	err := db.Select("id").From("users").Where("status = ?", "active").Column(&ids)
 func (sq *SelectQuery) Column(slice interface{}) error {
+	if sq.chunkedIn != nil {
+		err := sq.allChunked(slice, true)
+		if err == nil {
+			sq.builder.db.fillEmptySlice(slice)
+		}
+		return err
+	}
 	return sq.Build().Column(slice)
 }
 
@@ -1361,23 +2145,11 @@ func (sq *SelectQuery) Column(slice interface{}) error {
 //
 //	count, err := db.Select().From("users").Where(relica.Eq("status", 1)).Count()
 func (sq *SelectQuery) Count() (int64, error) {
-	// Build a copy of this query that uses COUNT(*) instead of the specified columns.
-	// We create a new SelectQuery with the same conditions but with columns replaced.
-	countQuery := &SelectQuery{
-		builder:       sq.builder,
-		columns:       []string{"COUNT(*)"},
-		fromSrc:       sq.fromSrc,
-		table:         sq.table,
-		joins:         sq.joins,
-		where:         sq.where,
-		params:        sq.params,
-		groupBy:       sq.groupBy,
-		havingClauses: sq.havingClauses,
-		ctx:           sq.ctx,
+	if sq.chunkedIn != nil {
+		return 0, errChunkedExecution
 	}
-
 	var count int64
-	if err := countQuery.Build().Row(&count); err != nil {
+	if err := sq.countQuery().Build().Row(&count); err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -1389,37 +2161,13 @@ func (sq *SelectQuery) Count() (int64, error) {
 //
 //	exists, err := db.Select().From("users").Where(relica.Eq("email", email)).Exists()
 func (sq *SelectQuery) Exists() (bool, error) {
-	// Build the inner query as SELECT 1 FROM ... WHERE ...
-	// Use selectExprs to emit raw "1" without quoting.
-	innerQuery := &SelectQuery{
-		builder:     sq.builder,
-		selectExprs: []RawExp{{SQL: "1"}},
-		fromSrc:     sq.fromSrc,
-		table:       sq.table,
-		joins:       sq.joins,
-		where:       sq.where,
-		params:      sq.params,
-		ctx:         sq.ctx,
-	}
-
-	innerSQL, innerParams := innerQuery.buildSQL(sq.builder.db.dialect)
-
-	// Wrap in SELECT EXISTS(...)
-	existsSQL := "SELECT EXISTS(" + innerSQL + ")"
-
-	// Renumber placeholders for PostgreSQL: inner query uses $1, $2... but the outer
-	// query has no preceding params, so numbering is already correct.
-	ctx := sq.ctx
-	if ctx == nil {
-		ctx = sq.builder.ctx
+	if sq.chunkedIn != nil {
+		return false, errChunkedExecution
 	}
-
-	q := &Query{
-		sql:    existsSQL,
-		params: innerParams,
-		db:     sq.builder.db,
-		tx:     sq.builder.tx,
-		ctx:    ctx,
+	q := sq.existsQuery().Build()
+	if q.prepErr == nil {
+		// The inner query keeps its placeholder numbering, as nothing precedes it.
+		q.sql = "SELECT EXISTS(" + q.sql + ")"
 	}
 
 	var exists bool
@@ -1429,6 +2177,36 @@ func (sq *SelectQuery) Exists() (bool, error) {
 	return exists, nil
 }
 
+// countQuery returns the query Count runs: a copy of sq selecting COUNT(*).
+func (sq *SelectQuery) countQuery() *SelectQuery {
+	c := sq.matchQuery()
+	c.columns = []string{"COUNT(*)"}
+	return c
+}
+
+// existsQuery returns the query Exists wraps in SELECT EXISTS(...): a copy of
+// sq selecting 1.
+func (sq *SelectQuery) existsQuery() *SelectQuery {
+	c := sq.matchQuery()
+	c.selectExprs = []RawExp{{SQL: "1"}}
+	return c
+}
+
+// matchQuery returns a copy of sq without its select list, DISTINCT, ORDER
+// BY, LIMIT, OFFSET and set operations. Everything deciding which rows match
+// carries over, including AsOf, Sample, CTEs, RequireFresh and a stored
+// build error.
+func (sq *SelectQuery) matchQuery() *SelectQuery {
+	c := sq.Clone()
+	c.columns, c.selectExprs, c.subExprs = nil, nil, nil
+	c.distinct = false
+	c.orderBy, c.orderByExprs, c.subOrderByExprs = nil, nil, nil
+	c.limitValue, c.offsetValue = nil, nil
+	c.unions, c.unionOrderBy, c.unionLimit, c.unionOffset = nil, nil, nil, nil
+	c.cursorFetch = 0
+	return c
+}
+
 // ToSQL returns the SQL string and parameters without executing the query.
 // This is useful for debugging, logging, or passing the query to another layer.
 //
@@ -1436,7 +2214,8 @@ func (sq *SelectQuery) Exists() (bool, error) {
 //
 //	sql, params := db.Select().From("users").Where(relica.Eq("id", 1)).ToSQL()
 func (sq *SelectQuery) ToSQL() (string, []interface{}) {
-	return sq.buildSQL(sq.builder.db.dialect)
+	sqlStr, params, _ := sq.buildSQL(sq.builder.db.dialect)
+	return sqlStr, params
 }
 
 // Explain analyzes the query execution plan without executing the query.
@@ -1485,7 +2264,10 @@ func (sq *SelectQuery) ExplainAnalyze() (*QueryPlan, error) {
 // explainQuery implements query analysis using database EXPLAIN functionality.
 func (sq *SelectQuery) explainQuery(withAnalyze bool) (*QueryPlan, error) {
 	// Build the SELECT query
-	sqlQuery, params := sq.buildSQL(sq.builder.db.dialect)
+	sqlQuery, params, err := sq.buildSQL(sq.builder.db.dialect)
+	if err != nil {
+		return nil, err
+	}
 
 	// Context priority: query ctx > builder ctx > background
 	ctx := sq.ctx
@@ -1518,7 +2300,7 @@ type selectQueryExpression struct {
 // Build implements the Expression interface for SelectQuery.
 // This allows SelectQuery to be used in subquery contexts (IN, EXISTS, FROM).
 func (sqe *selectQueryExpression) Build(dialect dialects.Dialect) (string, []interface{}) {
-	return sqe.query.buildSQL(dialect)
+	return sqe.query.fragmentSQL(dialect)
 }
 
 // AsExpression converts a SelectQuery to an Expression, allowing it to be used as a subquery.
@@ -1540,6 +2322,7 @@ func (qb *QueryBuilder) Select(cols ...string) *SelectQuery {
 	return &SelectQuery{
 		builder: qb,
 		columns: cols,
+		ctes:    append([]cteInfo(nil), qb.ctes...),
 	}
 }
 
@@ -1552,6 +2335,7 @@ func (qb *QueryBuilder) Insert(table string, values map[string]interface{}) *Que
 			prepErr: fmt.Errorf("relica: Insert requires a non-empty values map"),
 			db:      qb.db,
 			tx:      qb.tx,
+			tag:     qb.tag,
 			ctx:     qb.ctx,
 		}
 	}
@@ -1576,13 +2360,21 @@ func (qb *QueryBuilder) Insert(table string, values map[string]interface{}) *Que
 		` (` + strings.Join(quotedKeys, ", ") + `) ` +
 		`VALUES (` + strings.Join(placeholders, ", ") + `)`
 
-	return &Query{
+	change := qb.db.newChange(table, opInsert, keys, []string{defaultChangeKey}, mapRow(keys, values))
+	if change != nil {
+		change.autoKey = true
+	}
+
+	return qb.db.guard(&Query{
 		sql:    query,
 		params: params,
 		db:     qb.db,
 		tx:     qb.tx,
+		tag:    qb.tag,
 		ctx:    qb.ctx,
-	}
+		change: change,
+		refs:   qb.db.newSchemaRefs(table, keys...),
+	})
 }
 
 // getKeys returns sorted map keys for deterministic SQL generation.
@@ -1601,9 +2393,14 @@ type UpsertQuery struct {
 	table           string
 	values          map[string]interface{}
 	conflictColumns []string
+	constraint      string // ON CONFLICT ON CONSTRAINT name (PostgreSQL)
 	updateColumns   []string
+	updateExprs     []upsertExpr // DO UPDATE SET col = expression
+	where           []string     // DO UPDATE ... WHERE conditions
+	whereParams     []interface{}
 	doNothing       bool
 	ctx             context.Context // context for this specific query
+	buildErr        error           // stored programming error (replaces panic in fluent chain)
 }
 
 // WithContext sets the context for this UPSERT query.
@@ -1644,11 +2441,18 @@ func (uq *UpsertQuery) DoUpdate(columns ...string) *UpsertQuery {
 func (uq *UpsertQuery) DoNothing() *UpsertQuery {
 	uq.doNothing = true
 	uq.updateColumns = nil
+	uq.updateExprs = nil
 	return uq
 }
 
 // Build constructs the Query object from UpsertQuery.
 func (uq *UpsertQuery) Build() *Query {
+	// Context priority: query ctx > builder ctx > nil
+	ctx := uq.ctx
+	if ctx == nil {
+		ctx = uq.builder.ctx
+	}
+
 	keys := getKeys(uq.values)
 	placeholders := make([]string, 0, len(keys))
 	params := make([]interface{}, 0, len(keys))
@@ -1678,9 +2482,23 @@ func (uq *UpsertQuery) Build() *Query {
 	}
 
 	// Add conflict resolution if specified
-	if uq.doNothing {
+	switch {
+	case uq.buildErr != nil || uq.advancedConflict():
+		clause, clauseParams, err := uq.buildConflictClause(keys, len(params))
+		if err != nil {
+			return &Query{
+				prepErr: err,
+				db:      uq.builder.db,
+				tx:      uq.builder.tx,
+				tag:     uq.builder.tag,
+				ctx:     ctx,
+			}
+		}
+		query += clause
+		params = append(params, clauseParams...)
+	case uq.doNothing:
 		query += uq.builder.db.dialect.UpsertSQL(uq.table, quoteSlice(uq.conflictColumns), nil)
-	} else if len(uq.conflictColumns) > 0 || len(uq.updateColumns) > 0 {
+	case len(uq.conflictColumns) > 0 || len(uq.updateColumns) > 0:
 		updateCols := uq.updateColumns
 		if len(updateCols) == 0 {
 			updateCols = filterKeys(keys, uq.conflictColumns)
@@ -1688,19 +2506,21 @@ func (uq *UpsertQuery) Build() *Query {
 		query += uq.builder.db.dialect.UpsertSQL(uq.table, quoteSlice(uq.conflictColumns), quoteSlice(updateCols))
 	}
 
-	// Context priority: query ctx > builder ctx > nil
-	ctx := uq.ctx
-	if ctx == nil {
-		ctx = uq.builder.ctx
+	keyCols := uq.conflictColumns
+	if len(keyCols) == 0 {
+		keyCols = []string{defaultChangeKey}
 	}
 
-	return &Query{
+	return uq.builder.db.guard(&Query{
 		sql:    query,
 		params: params,
 		db:     uq.builder.db,
 		tx:     uq.builder.tx,
+		tag:    uq.builder.tag,
 		ctx:    ctx,
-	}
+		change: uq.builder.db.newChange(uq.table, opUpsert, keys, keyCols, mapRow(keys, uq.values)),
+		refs:   uq.builder.db.newSchemaRefs(uq.table, keys...),
+	})
 }
 
 // Execute executes the UPSERT query and returns the result.
@@ -1726,13 +2546,17 @@ func filterKeys(keys, exclude []string) []string {
 
 // UpdateQuery represents an UPDATE query being built.
 type UpdateQuery struct {
-	builder  *QueryBuilder
-	table    string
-	values   map[string]interface{}
-	where    []string
-	params   []interface{}
-	ctx      context.Context // context for this specific query
-	buildErr error           // stored programming error (replaces panic in fluent chain)
+	builder    *QueryBuilder
+	table      string
+	values     map[string]interface{}
+	where      []string
+	params     []interface{}
+	returning  []string        // RETURNING columns (PostgreSQL, SQLite 3.35+)
+	orderBy    []string        // ORDER BY terms deciding the rows a Limit keeps
+	limitValue *int64          // maximum number of rows changed (nil = no limit)
+	allowAll   bool            // no WHERE allowed with WithSafeWrites (see AllowFullTableWrite)
+	ctx        context.Context // context for this specific query
+	buildErr   error           // stored programming error (replaces panic in fluent chain)
 }
 
 // WithContext sets the context for this UPDATE query.
@@ -1752,6 +2576,16 @@ func (qb *QueryBuilder) Update(table string) *UpdateQuery {
 
 // Set specifies the columns and values to update.
 // Values should be a map of column names to new values.
+// A value may also be an Expression (e.g. a CASE expression), which is rendered
+// inline with its parameters bound in order.
+//
+// Example:
+//
+//	db.Update("users").Set(map[string]interface{}{
+//	    "tier": relica.Case().
+//	        When(relica.GreaterThan("points", 1000), "gold").
+//	        Else("silver"),
+//	})
 func (uq *UpdateQuery) Set(values map[string]interface{}) *UpdateQuery {
 	uq.values = values
 	return uq
@@ -1780,6 +2614,10 @@ func (uq *UpdateQuery) Where(condition interface{}, params ...interface{}) *Upda
 		uq.params = append(uq.params, resolvedArgs...)
 
 	case Expression:
+		if err := checkExpressionTree(cond, uq.builder.db.dialect); err != nil {
+			uq.buildErr = err
+			return uq
+		}
 		sqlStr, args := cond.Build(uq.builder.db.dialect)
 		if sqlStr != "" {
 			uq.where = append(uq.where, sqlStr)
@@ -1842,6 +2680,10 @@ func (uq *UpdateQuery) OrWhere(condition interface{}, params ...interface{}) *Up
 		}
 
 	case Expression:
+		if err := checkExpressionTree(cond, uq.builder.db.dialect); err != nil {
+			uq.buildErr = err
+			return uq
+		}
 		newSQL, newArgs = cond.Build(uq.builder.db.dialect)
 		if newSQL == "" {
 			return uq
@@ -1864,6 +2706,22 @@ func (uq *UpdateQuery) OrWhere(condition interface{}, params ...interface{}) *Up
 	return uq
 }
 
+// Returning adds a RETURNING clause with the given columns ("*" for all columns).
+// Supported by PostgreSQL and SQLite 3.35+; MySQL returns an error at build time.
+// Use Build().All(&rows) to scan the returned rows, or pass the query to With()
+// as a data-modifying CTE (PostgreSQL).
+//
+// Example:
+//
+//	db.Update("users").Set(map[string]interface{}{"status": 0}).
+//	    Where("last_login < ?", cutoff).
+//	    Returning("id", "email").
+//	    Build().All(&deactivated)
+func (uq *UpdateQuery) Returning(cols ...string) *UpdateQuery {
+	uq.returning = append(uq.returning, cols...)
+	return uq
+}
+
 // Build constructs the Query object from UpdateQuery.
 // If a programming error was stored during query construction, it is propagated
 // through the Query and returned by Execute at call time instead of panicking.
@@ -1874,24 +2732,57 @@ func (uq *UpdateQuery) Build() *Query {
 		ctx = uq.builder.ctx
 	}
 
-	if uq.buildErr != nil {
+	dialect := uq.builder.db.dialect
+	query, params, err := uq.buildStatement(dialect)
+	if err == nil {
+		query, params, err = prependWithClause(uq.builder.ctes, query, params, dialect)
+	}
+	if err != nil {
 		return &Query{
-			prepErr: uq.buildErr,
+			prepErr: err,
 			db:      uq.builder.db,
 			tx:      uq.builder.tx,
+			tag:     uq.builder.tag,
 			ctx:     ctx,
 		}
 	}
 
+	return uq.builder.db.guard(&Query{
+		sql:    query,
+		params: params,
+		db:     uq.builder.db,
+		tx:     uq.builder.tx,
+		tag:    uq.builder.tag,
+		ctx:    ctx,
+		change: uq.builder.db.newChange(uq.table, opUpdate, getKeys(uq.values), []string{defaultChangeKey}, nil),
+		refs:   uq.builder.db.newSchemaRefs(uq.table, getKeys(uq.values)...),
+	})
+}
+
+// buildCTE implements CTEQuery for data-modifying CTEs (PostgreSQL only).
+func (uq *UpdateQuery) buildCTE(dialect dialects.Dialect) (string, []interface{}, error) {
+	if err := requireDataModifyingCTE(dialect); err != nil {
+		return "", nil, err
+	}
+	return uq.buildStatement(dialect)
+}
+
+// buildStatement constructs the UPDATE statement (without builder CTEs).
+func (uq *UpdateQuery) buildStatement(dialect dialects.Dialect) (string, []interface{}, error) {
+	if uq.buildErr != nil {
+		return "", nil, uq.buildErr
+	}
+
 	// UPDATE with no values produces invalid SQL ("UPDATE t SET WHERE ...").
 	// Return a clean error rather than a malformed query.
 	if len(uq.values) == 0 {
-		return &Query{
-			prepErr: fmt.Errorf("relica: Update requires values, call Set() before Build()"),
-			db:      uq.builder.db,
-			tx:      uq.builder.tx,
-			ctx:     ctx,
-		}
+		return "", nil, fmt.Errorf("relica: Update requires values, call Set() before Build()")
+	}
+	if err := checkValueExpressions(uq.values, dialect); err != nil {
+		return "", nil, err
+	}
+	if err := uq.builder.db.requireWhere(opUpdate, uq.table, uq.where, uq.allowAll); err != nil {
+		return "", nil, err
 	}
 
 	// Get sorted keys for deterministic SQL generation
@@ -1901,41 +2792,42 @@ func (uq *UpdateQuery) Build() *Query {
 	setClauses := make([]string, 0, len(keys))
 	setParams := make([]interface{}, 0, len(keys))
 
-	for i, col := range keys {
-		setClauses = append(setClauses, uq.builder.db.dialect.QuoteIdentifier(col)+" = "+uq.builder.db.dialect.Placeholder(i+1))
+	for _, col := range keys {
+		quoted := dialect.QuoteIdentifier(col)
+		if exp, ok := uq.values[col].(Expression); ok {
+			// Expression value: render inline and renumber its placeholders
+			expSQL, expArgs := exp.Build(dialect)
+			expSQL = renumberFragment(expSQL, len(setParams)+1, len(expArgs), dialect)
+			setClauses = append(setClauses, quoted+" = "+expSQL)
+			setParams = append(setParams, expArgs...)
+			continue
+		}
+		setClauses = append(setClauses, quoted+" = "+dialect.Placeholder(len(setParams)+1))
 		setParams = append(setParams, uq.values[col])
 	}
 
 	// Build WHERE clause
-	whereClause := ""
+	// WHERE placeholders are numbered after the SET params for PostgreSQL ($1, $2, etc.)
 	whereParams := uq.params
-	if len(uq.where) > 0 {
-		whereClause = " WHERE " + strings.Join(uq.where, " AND ")
-
-		// Renumber WHERE placeholders for PostgreSQL ($1, $2, etc.)
-		if uq.builder.db.dialect.Placeholder(1) != "?" {
-			startIndex := len(setParams) + 1
-			for i := range whereParams {
-				placeholder := uq.builder.db.dialect.Placeholder(startIndex + i)
-				whereClause = strings.Replace(whereClause, "?", placeholder, 1)
-			}
-		}
+	whereClause := whereSQL(uq.where, len(setParams)+1, len(whereParams), dialect)
+
+	whereClause, limitClause, err := limitedWrite("UPDATE", uq.table, whereClause, uq.orderBy, uq.limitValue, dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	returningClause, err := buildReturningClause(uq.returning, dialect)
+	if err != nil {
+		return "", nil, err
 	}
 
 	// Construct SQL
-	query := "UPDATE " + uq.builder.db.dialect.QuoteIdentifier(uq.table) +
-		" SET " + strings.Join(setClauses, ", ") + whereClause
+	query := "UPDATE " + dialect.QuoteIdentifier(uq.table) +
+		" SET " + strings.Join(setClauses, ", ") + whereClause + limitClause + returningClause
 
 	// Combine SET and WHERE parameters
 	setParams = append(setParams, whereParams...)
 
-	return &Query{
-		sql:    query,
-		params: setParams,
-		db:     uq.builder.db,
-		tx:     uq.builder.tx,
-		ctx:    ctx,
-	}
+	return query, setParams, nil
 }
 
 // Execute executes the UPDATE query and returns the result.
@@ -1956,12 +2848,16 @@ func (uq *UpdateQuery) ToSQL() (string, []interface{}) {
 
 // DeleteQuery represents a DELETE query being built.
 type DeleteQuery struct {
-	builder  *QueryBuilder
-	table    string
-	where    []string
-	params   []interface{}
-	ctx      context.Context // context for this specific query
-	buildErr error           // stored programming error (replaces panic in fluent chain)
+	builder    *QueryBuilder
+	table      string
+	where      []string
+	params     []interface{}
+	returning  []string        // RETURNING columns (PostgreSQL, SQLite 3.35+)
+	orderBy    []string        // ORDER BY terms deciding the rows a Limit keeps
+	limitValue *int64          // maximum number of rows changed (nil = no limit)
+	allowAll   bool            // no WHERE allowed with WithSafeWrites (see AllowFullTableWrite)
+	ctx        context.Context // context for this specific query
+	buildErr   error           // stored programming error (replaces panic in fluent chain)
 }
 
 // WithContext sets the context for this DELETE query.
@@ -2002,6 +2898,10 @@ func (dq *DeleteQuery) Where(condition interface{}, params ...interface{}) *Dele
 		dq.params = append(dq.params, resolvedArgs...)
 
 	case Expression:
+		if err := checkExpressionTree(cond, dq.builder.db.dialect); err != nil {
+			dq.buildErr = err
+			return dq
+		}
 		sqlStr, args := cond.Build(dq.builder.db.dialect)
 		if sqlStr != "" {
 			dq.where = append(dq.where, sqlStr)
@@ -2064,6 +2964,10 @@ func (dq *DeleteQuery) OrWhere(condition interface{}, params ...interface{}) *De
 		}
 
 	case Expression:
+		if err := checkExpressionTree(cond, dq.builder.db.dialect); err != nil {
+			dq.buildErr = err
+			return dq
+		}
 		newSQL, newArgs = cond.Build(dq.builder.db.dialect)
 		if newSQL == "" {
 			return dq
@@ -2086,6 +2990,19 @@ func (dq *DeleteQuery) OrWhere(condition interface{}, params ...interface{}) *De
 	return dq
 }
 
+// Returning adds a RETURNING clause with the given columns ("*" for all columns).
+// Supported by PostgreSQL and SQLite 3.35+; MySQL returns an error at build time.
+// Use Build().All(&rows) to scan the deleted rows, or pass the query to With()
+// as a data-modifying CTE (PostgreSQL).
+//
+// Example:
+//
+//	db.Delete("sessions").Where("expires_at < ?", now).Returning("id").Build().Column(&ids)
+func (dq *DeleteQuery) Returning(cols ...string) *DeleteQuery {
+	dq.returning = append(dq.returning, cols...)
+	return dq
+}
+
 // Build constructs the Query object from DeleteQuery.
 // If a programming error was stored during query construction, it is propagated
 // through the Query and returned by Execute at call time instead of panicking.
@@ -2096,40 +3013,89 @@ func (dq *DeleteQuery) Build() *Query {
 		ctx = dq.builder.ctx
 	}
 
-	if dq.buildErr != nil {
+	dialect := dq.builder.db.dialect
+	query, params, err := dq.buildStatement(dialect)
+	if err == nil {
+		query, params, err = prependWithClause(dq.builder.ctes, query, params, dialect)
+	}
+	if err != nil {
 		return &Query{
-			prepErr: dq.buildErr,
+			prepErr: err,
 			db:      dq.builder.db,
 			tx:      dq.builder.tx,
+			tag:     dq.builder.tag,
 			ctx:     ctx,
 		}
 	}
 
+	return dq.builder.db.guard(&Query{
+		sql:    query,
+		params: params,
+		db:     dq.builder.db,
+		tx:     dq.builder.tx,
+		tag:    dq.builder.tag,
+		ctx:    ctx,
+		change: dq.builder.db.newChange(dq.table, opDelete, nil, []string{defaultChangeKey}, nil),
+		refs:   dq.builder.db.newSchemaRefs(dq.table),
+	})
+}
+
+// buildCTE implements CTEQuery for data-modifying CTEs (PostgreSQL only).
+func (dq *DeleteQuery) buildCTE(dialect dialects.Dialect) (string, []interface{}, error) {
+	if err := requireDataModifyingCTE(dialect); err != nil {
+		return "", nil, err
+	}
+	return dq.buildStatement(dialect)
+}
+
+// buildStatement constructs the DELETE statement (without builder CTEs).
+func (dq *DeleteQuery) buildStatement(dialect dialects.Dialect) (string, []interface{}, error) {
+	if dq.buildErr != nil {
+		return "", nil, dq.buildErr
+	}
+	if err := dq.builder.db.requireWhere(opDelete, dq.table, dq.where, dq.allowAll); err != nil {
+		return "", nil, err
+	}
+
 	// Build WHERE clause
-	whereClause := ""
+	// WHERE placeholders are numbered for PostgreSQL ($1, $2, etc.)
 	whereParams := dq.params
-	if len(dq.where) > 0 {
-		whereClause = " WHERE " + strings.Join(dq.where, " AND ")
-
-		// Renumber WHERE placeholders for PostgreSQL ($1, $2, etc.)
-		if dq.builder.db.dialect.Placeholder(1) != "?" {
-			for i := range whereParams {
-				placeholder := dq.builder.db.dialect.Placeholder(i + 1)
-				whereClause = strings.Replace(whereClause, "?", placeholder, 1)
-			}
-		}
+	whereClause := whereSQL(dq.where, 1, len(whereParams), dialect)
+
+	whereClause, limitClause, err := limitedWrite("DELETE", dq.table, whereClause, dq.orderBy, dq.limitValue, dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	returningClause, err := buildReturningClause(dq.returning, dialect)
+	if err != nil {
+		return "", nil, err
 	}
 
 	// Construct SQL
-	query := "DELETE FROM " + dq.builder.db.dialect.QuoteIdentifier(dq.table) + whereClause
+	query := "DELETE FROM " + dialect.QuoteIdentifier(dq.table) + whereClause + limitClause + returningClause
 
-	return &Query{
-		sql:    query,
-		params: whereParams,
-		db:     dq.builder.db,
-		tx:     dq.builder.tx,
-		ctx:    ctx,
+	return query, whereParams, nil
+}
+
+// buildReturningClause renders " RETURNING ..." for the given columns.
+// Plain identifiers are quoted; "*" and expressions are passed through unchanged.
+func buildReturningClause(cols []string, dialect dialects.Dialect) (string, error) {
+	if len(cols) == 0 {
+		return "", nil
+	}
+	if _, ok := dialect.(*dialects.MySQLDialect); ok {
+		return "", fmt.Errorf("relica: RETURNING is not supported by MySQL")
 	}
+
+	parts := make([]string, len(cols))
+	for i, col := range cols {
+		col = strings.TrimSpace(col)
+		if plainIdentifierRegex.MatchString(col) {
+			col = quoteColumn(col, dialect)
+		}
+		parts[i] = col
+	}
+	return " RETURNING " + strings.Join(parts, ", "), nil
 }
 
 // Execute executes the DELETE query and returns the result.
@@ -2222,6 +3188,7 @@ func (biq *BatchInsertQuery) Build() *Query {
 			prepErr: biq.buildErr,
 			db:      biq.builder.db,
 			tx:      biq.builder.tx,
+			tag:     biq.builder.tag,
 			ctx:     ctx,
 		}
 	}
@@ -2231,6 +3198,7 @@ func (biq *BatchInsertQuery) Build() *Query {
 			prepErr: fmt.Errorf("relica: BatchInsert.Build called with no rows to insert"),
 			db:      biq.builder.db,
 			tx:      biq.builder.tx,
+			tag:     biq.builder.tag,
 			ctx:     ctx,
 		}
 	}
@@ -2260,13 +3228,16 @@ func (biq *BatchInsertQuery) Build() *Query {
 		" (" + strings.Join(quotedColumns, ", ") + ") VALUES " +
 		strings.Join(valueClauses, ", ")
 
-	return &Query{
+	return biq.builder.db.guard(&Query{
 		sql:    query,
 		params: params,
 		db:     biq.builder.db,
 		tx:     biq.builder.tx,
+		tag:    biq.builder.tag,
 		ctx:    ctx,
-	}
+		change: biq.builder.db.newChange(biq.table, opInsert, biq.columns, []string{defaultChangeKey}, biq.rows),
+		refs:   biq.builder.db.newSchemaRefs(biq.table, biq.columns...),
+	})
 }
 
 // Execute executes the batch INSERT query and returns the result.
@@ -2282,6 +3253,7 @@ type BatchUpdateQuery struct {
 	keyColumn     string
 	updates       []batchUpdateRow
 	updateColumns []string        // Cached list of columns to update
+	lockOrdered   bool            // sort keys and lock rows in key order before updating
 	ctx           context.Context // context for this specific query
 }
 
@@ -2292,6 +3264,27 @@ func (buq *BatchUpdateQuery) WithContext(ctx context.Context) *BatchUpdateQuery
 	return buq
 }
 
+// LockOrdered makes the batch safe to run concurrently with other batches
+// touching overlapping keys. The rows are sorted by key, and Execute first
+// locks them in key order with SELECT ... ORDER BY key FOR UPDATE, then
+// updates them in the same transaction. Workers acquiring row locks in one
+// global order cannot deadlock on each other.
+//
+// Execute runs in the builder's transaction, or opens one if there is none.
+// On SQLite, which locks the whole database for writes, only the keys are sorted.
+//
+// Example:
+//
+//	db.Builder().BatchUpdate("accounts", "id").
+//	    Set(7, map[string]interface{}{"balance": 120}).
+//	    Set(3, map[string]interface{}{"balance": 80}).
+//	    LockOrdered().
+//	    Execute()
+func (buq *BatchUpdateQuery) LockOrdered() *BatchUpdateQuery {
+	buq.lockOrdered = true
+	return buq
+}
+
 // batchUpdateRow represents a single row update in a batch.
 type batchUpdateRow struct {
 	keyValue interface{}
@@ -2368,10 +3361,17 @@ func (buq *BatchUpdateQuery) Build() *Query {
 			prepErr: fmt.Errorf("relica: BatchUpdate.Build called with no updates to apply"),
 			db:      buq.builder.db,
 			tx:      buq.builder.tx,
+			tag:     buq.builder.tag,
 			ctx:     ctx,
 		}
 	}
 
+	if buq.lockOrdered {
+		sort.SliceStable(buq.updates, func(i, j int) bool {
+			return compareKeys(buq.updates[i].keyValue, buq.updates[j].keyValue) < 0
+		})
+	}
+
 	// Collect all key values for WHERE IN clause
 	keyValues := make([]interface{}, len(buq.updates))
 	for i, update := range buq.updates {
@@ -2417,16 +3417,108 @@ func (buq *BatchUpdateQuery) Build() *Query {
 		" WHERE " + buq.builder.db.dialect.QuoteIdentifier(buq.keyColumn) +
 		" IN (" + strings.Join(whereInPlaceholders, ", ") + ")"
 
-	return &Query{
+	change := buq.builder.db.newChange(buq.table, opUpdate, buq.updateColumns, []string{buq.keyColumn}, nil)
+	if change != nil {
+		change.keys = make([]map[string]interface{}, len(keyValues))
+		for i, key := range keyValues {
+			change.keys[i] = map[string]interface{}{buq.keyColumn: key}
+		}
+	}
+
+	return buq.builder.db.guard(&Query{
 		sql:    query,
 		params: params,
 		db:     buq.builder.db,
 		tx:     buq.builder.tx,
+		tag:    buq.builder.tag,
 		ctx:    ctx,
-	}
+		change: change,
+		refs:   buq.builder.db.newSchemaRefs(buq.table, append([]string{buq.keyColumn}, buq.updateColumns...)...),
+	})
 }
 
 // Execute executes the batch UPDATE query and returns the result.
+// With LockOrdered the rows are locked in key order first.
 func (buq *BatchUpdateQuery) Execute() (interface{}, error) {
-	return buq.Build().Execute()
+	q := buq.Build()
+	if !buq.lockOrdered || q.prepErr != nil {
+		return q.Execute()
+	}
+	if _, ok := buq.builder.db.dialect.(*dialects.SQLiteDialect); ok {
+		return q.Execute()
+	}
+	if q.tx != nil {
+		return buq.lockAndExecute(q)
+	}
+
+	ctx := q.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var result sql.Result
+	err := buq.builder.db.Transactional(ctx, func(tx *Tx) error {
+		q.tx = tx.tx
+		var err error
+		result, err = buq.lockAndExecute(q)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockAndExecute locks the rows of the batch in key order, then runs the
+// UPDATE q, in q's transaction.
+func (buq *BatchUpdateQuery) lockAndExecute(q *Query) (sql.Result, error) {
+	d := buq.builder.db.dialect
+	key := d.QuoteIdentifier(buq.keyColumn)
+	placeholders := make([]string, len(buq.updates))
+	keys := make([]interface{}, len(buq.updates))
+	for i, update := range buq.updates {
+		placeholders[i] = d.Placeholder(i + 1)
+		keys[i] = update.keyValue
+	}
+	lock := &Query{
+		sql: "SELECT " + key + " FROM " + d.QuoteIdentifier(buq.table) +
+			" WHERE " + key + " IN (" + strings.Join(placeholders, ", ") + ")" +
+			" ORDER BY " + key + " FOR UPDATE",
+		params: keys,
+		db:     q.db,
+		tx:     q.tx,
+		tag:    q.tag,
+		ctx:    q.ctx,
+	}
+	if _, err := lock.Execute(); err != nil {
+		return nil, err
+	}
+	return q.Execute()
+}
+
+// compareKeys orders two batch key values: numbers numerically, strings and
+// byte slices lexically, times chronologically. Values of other or mixed
+// types are compared by their formatted text.
+func compareKeys(a, b interface{}) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case va.CanInt() && vb.CanInt():
+		return cmp.Compare(va.Int(), vb.Int())
+	case va.CanUint() && vb.CanUint():
+		return cmp.Compare(va.Uint(), vb.Uint())
+	case va.CanFloat() && vb.CanFloat():
+		return cmp.Compare(va.Float(), vb.Float())
+	case va.Kind() == reflect.String && vb.Kind() == reflect.String:
+		return strings.Compare(va.String(), vb.String())
+	}
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
 }
@@ -107,3 +107,36 @@ func TestDB_UnpinQuery(t *testing.T) {
 		t.Error("Query should not be pinned after UnpinQuery")
 	}
 }
+
+func TestWithStmtCacheShards(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithStmtCacheShards(4), WithStmtCacheCapacity(256))
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer db.Close()
+
+	stats := db.StmtCacheStats()
+	if stats.Shards != 4 || stats.Capacity != 256 {
+		t.Errorf("Expected 4 shards of capacity 256, got %d shards of capacity %d", stats.Shards, stats.Capacity)
+	}
+
+	if _, err := db.WarmCache([]string{"SELECT 1", "SELECT 2", "SELECT 3"}); err != nil {
+		t.Fatalf("WarmCache failed: %v", err)
+	}
+	if size := db.StmtCacheStats().Size; size != 3 {
+		t.Errorf("Expected 3 cached statements, got %d", size)
+	}
+	if entries := db.StmtCacheEntries(); len(entries) != 3 {
+		t.Errorf("Expected 3 entries, got %d", len(entries))
+	}
+
+	// By default the shard count follows the capacity.
+	small, err := Open("sqlite", ":memory:", WithStmtCacheCapacity(10))
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer small.Close()
+	if shards := small.StmtCacheStats().Shards; shards != 1 {
+		t.Errorf("Expected 1 shard, got %d", shards)
+	}
+}
@@ -0,0 +1,296 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+
+	"github.com/coregx/relica/internal/util"
+)
+
+// opUpsert is the ChangeEvent operation of UPSERT statements, which may
+// insert or update.
+const opUpsert = "UPSERT"
+
+// defaultChangeKey is the key column assumed for builder statements.
+const defaultChangeKey = "id"
+
+// ChangeEvent describes the rows changed by one write statement executed
+// through the query builder or Model (see WithChangeSink).
+type ChangeEvent struct {
+	// Table is the table that was written to.
+	Table string
+	// Operation is INSERT, UPDATE, DELETE, UPSERT or MERGE.
+	Operation string
+	// Keys holds the primary key of each changed row, when it is known:
+	// from the model for Model operations, from the key column of BatchUpdate,
+	// from the inserted values, or from RETURNING rows. Builder statements
+	// identify rows by an "id" column (or the OnConflict columns of an upsert).
+	// Keys is nil when the rows are not known, e.g. for an UPDATE with an
+	// arbitrary WHERE clause; treat such events as affecting the whole table.
+	Keys []map[string]interface{}
+	// Columns lists the columns written by INSERT, UPDATE, UPSERT and MERGE statements.
+	Columns []string
+	// RowsAffected is the number of rows reported by the database, or the
+	// number of RETURNING rows read.
+	RowsAffected int64
+	// Tag is the query tag set with Tag ("" for untagged queries).
+	Tag string
+}
+
+// ChangeSink receives a ChangeEvent for every successful write statement.
+// It is called synchronously; hand events off to a channel or queue if
+// processing is slow.
+type ChangeSink func(ctx context.Context, event ChangeEvent)
+
+// WithChangeSink enables change data capture: every INSERT, UPDATE, DELETE and
+// UPSERT executed through the query builder or Model emits a ChangeEvent to sink.
+// Raw SQL (NewQuery, ExecContext) is not parsed and emits no events.
+//
+// Events of statements inside a transaction are delivered when the transaction
+// commits and discarded when it rolls back, so a sink never sees changes that
+// did not persist. Savepoints of a transaction-bound DB (Tx.DB) do not affect
+// delivery; only the outer transaction does.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithChangeSink(func(ctx context.Context, e relica.ChangeEvent) {
+//	        for _, key := range e.Keys {
+//	            cache.Delete(e.Table, key["id"])
+//	        }
+//	    }))
+func WithChangeSink(sink ChangeSink) Option {
+	return optionFunc(func(db *DB) {
+		db.changes = &changeFeed{sink: sink, pending: make(map[*sql.Tx][]pendingChange)}
+	})
+}
+
+// changeFeed delivers change events, holding those of open transactions until commit.
+type changeFeed struct {
+	sink    ChangeSink
+	mu      sync.Mutex
+	pending map[*sql.Tx][]pendingChange
+}
+
+// pendingChange is an event waiting for its transaction to commit.
+type pendingChange struct {
+	ctx   context.Context
+	event ChangeEvent
+}
+
+// publish delivers event now, or at commit of tx.
+func (f *changeFeed) publish(ctx context.Context, tx *sql.Tx, event ChangeEvent) {
+	if tx == nil {
+		f.sink(ctx, event)
+		return
+	}
+	f.mu.Lock()
+	f.pending[tx] = append(f.pending[tx], pendingChange{ctx: ctx, event: event})
+	f.mu.Unlock()
+}
+
+// finish delivers (committed) or discards the events held for tx.
+// It is safe to call on a nil feed.
+func (f *changeFeed) finish(tx *sql.Tx, committed bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	events := f.pending[tx]
+	delete(f.pending, tx)
+	f.mu.Unlock()
+	if !committed {
+		return
+	}
+	for _, p := range events {
+		f.sink(p.ctx, p.event)
+	}
+}
+
+// changeInfo describes the change a write Query makes; set by the builders
+// only when a change sink is configured.
+type changeInfo struct {
+	table   string
+	op      string
+	columns []string
+	keyCols []string                 // primary key columns of the table
+	keys    []map[string]interface{} // keys known from the statement itself
+	autoKey bool                     // single generated key reported by LastInsertId
+}
+
+// newChange returns the change a statement makes, or nil if change capture is off.
+// keys are taken from rows when every key column is among columns.
+func (db *DB) newChange(table, op string, columns, keyCols []string, rows [][]interface{}) *changeInfo {
+	if db.changes == nil {
+		return nil
+	}
+	return &changeInfo{
+		table:   table,
+		op:      op,
+		columns: columns,
+		keyCols: keyCols,
+		keys:    rowKeys(keyCols, columns, rows),
+	}
+}
+
+// rowKeys extracts the keyCols values of rows (whose values are in columns order).
+func rowKeys(keyCols, columns []string, rows [][]interface{}) []map[string]interface{} {
+	if len(keyCols) == 0 || len(rows) == 0 {
+		return nil
+	}
+	idx := make([]int, len(keyCols))
+	for i, key := range keyCols {
+		idx[i] = -1
+		for j, col := range columns {
+			if col == key {
+				idx[i] = j
+			}
+		}
+		if idx[i] < 0 {
+			return nil
+		}
+	}
+
+	keys := make([]map[string]interface{}, len(rows))
+	for r, row := range rows {
+		key := make(map[string]interface{}, len(keyCols))
+		for i, col := range keyCols {
+			key[col] = row[idx[i]]
+		}
+		keys[r] = key
+	}
+	return keys
+}
+
+// mapRow returns the values of m in columns order.
+func mapRow(columns []string, m map[string]interface{}) [][]interface{} {
+	row := make([]interface{}, len(columns))
+	for i, col := range columns {
+		row[i] = m[col]
+	}
+	return [][]interface{}{row}
+}
+
+// setModelChange identifies the changed row of a Model statement by the
+// model's primary key. A zero single key is generated by the database and
+// read back from the result (LastInsertId or RETURNING).
+func (mq *ModelQuery) setModelChange(q *Query) {
+	if q.change == nil {
+		return
+	}
+	v := reflect.ValueOf(mq.model)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	pkInfo, err := util.FindPrimaryKeyFields(v)
+	if err != nil {
+		q.change.keyCols, q.change.keys = nil, nil
+		return
+	}
+
+	q.change.keyCols = pkInfo.Columns
+	if pkInfo.IsSingle() && util.IsPrimaryKeyZero(pkInfo.Values[0]) {
+		q.change.keys = nil
+		q.change.autoKey = true
+		return
+	}
+	key := make(map[string]interface{}, len(pkInfo.Columns))
+	for i, col := range pkInfo.Columns {
+		key[col] = pkInfo.Values[i].Interface()
+	}
+	q.change.keys = []map[string]interface{}{key}
+	q.change.autoKey = false
+}
+
+// publishChange emits the change of a successfully executed statement.
+func (q *Query) publishChange(ctx context.Context, result sql.Result) {
+	c := q.change
+	event := ChangeEvent{Table: c.table, Operation: c.op, Keys: c.keys, Columns: c.columns, Tag: q.tag}
+	if result != nil {
+		event.RowsAffected, _ = result.RowsAffected()
+		if c.autoKey && event.Keys == nil && len(c.keyCols) == 1 {
+			if id, err := result.LastInsertId(); err == nil {
+				event.Keys = []map[string]interface{}{{c.keyCols[0]: id}}
+			}
+		}
+	}
+	q.db.changes.publish(ctx, q.tx, event)
+}
+
+// publishReturning emits the change of a statement whose RETURNING rows were
+// read into dest by One, Row, Column or All; keys are taken from dest when it
+// holds them.
+func (q *Query) publishReturning(ctx context.Context, dest interface{}) {
+	c := q.change
+	event := ChangeEvent{Table: c.table, Operation: c.op, Keys: c.keys, Columns: c.columns, RowsAffected: 1, Tag: q.tag}
+	if v := reflect.ValueOf(dest); v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		event.RowsAffected = int64(v.Elem().Len())
+	}
+	if keys := returnedKeys(dest, c.keyCols); keys != nil {
+		event.Keys = keys
+	}
+	q.db.changes.publish(ctx, q.tx, event)
+}
+
+// returnedKeys reads keyCols from a scanned destination: a pointer to a
+// struct or a slice of structs, or (for a single key column) a pointer to a
+// scalar or a slice of scalars.
+func returnedKeys(dest interface{}, keyCols []string) []map[string]interface{} {
+	v := reflect.ValueOf(dest)
+	if len(keyCols) == 0 || v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil
+	}
+	v = v.Elem()
+
+	switch {
+	case v.Kind() == reflect.Slice:
+		keys := make([]map[string]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := reflect.Indirect(v.Index(i))
+			key := structKey(elem, keyCols)
+			if key == nil && elem.Kind() != reflect.Struct && len(keyCols) == 1 {
+				key = map[string]interface{}{keyCols[0]: elem.Interface()}
+			}
+			if key == nil {
+				return nil
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	case v.Kind() == reflect.Struct && v.Type() != timeType:
+		if key := structKey(v, keyCols); key != nil {
+			return []map[string]interface{}{key}
+		}
+		return nil
+	case len(keyCols) == 1:
+		return []map[string]interface{}{{keyCols[0]: v.Interface()}}
+	default:
+		return nil
+	}
+}
+
+// structKey reads keyCols from the fields of struct value v.
+func structKey(v reflect.Value, keyCols []string) map[string]interface{} {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	info, err := globalScanner.getStructInfo(v.Type())
+	if err != nil {
+		return nil
+	}
+	key := make(map[string]interface{}, len(keyCols))
+	for _, col := range keyCols {
+		for _, f := range info.fields {
+			if f.dbName == col {
+				key[col] = v.FieldByIndex(f.index).Interface()
+				break
+			}
+		}
+		if _, ok := key[col]; !ok {
+			return nil
+		}
+	}
+	return key
+}
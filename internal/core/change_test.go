@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type changedItem struct {
+	ID    int64  `db:"id"`
+	Name  string `db:"name"`
+	Price int    `db:"price"`
+}
+
+func (changedItem) TableName() string { return "items" }
+
+func setupChangeDB(t *testing.T) (*DB, *[]ChangeEvent) {
+	t.Helper()
+	var events []ChangeEvent
+	db, err := Open("sqlite", ":memory:", WithChangeSink(func(_ context.Context, e ChangeEvent) {
+		events = append(events, e)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, price INTEGER)`)
+	require.NoError(t, err)
+	return db, &events
+}
+
+func TestChangeSink_Builder(t *testing.T) {
+	db, events := setupChangeDB(t)
+	qb := db.Builder()
+
+	_, err := qb.Insert("items", map[string]interface{}{"name": "pen", "price": 2}).Execute()
+	require.NoError(t, err)
+	_, err = qb.BatchInsert("items", []string{"id", "name", "price"}).
+		Values(10, "ink", 5).
+		Values(11, "pad", 3).
+		Execute()
+	require.NoError(t, err)
+	_, err = qb.Update("items").Set(map[string]interface{}{"price": 1}).Where("price > ?", 2).Execute()
+	require.NoError(t, err)
+	_, err = qb.BatchUpdate("items", "id").
+		Set(10, map[string]interface{}{"name": "blue ink"}).
+		Execute()
+	require.NoError(t, err)
+	_, err = qb.Upsert("items", map[string]interface{}{"id": 11, "name": "notepad"}).
+		OnConflict("id").DoUpdate("name").Execute()
+	require.NoError(t, err)
+	_, err = qb.Delete("items").Where("id = ?", 10).Execute()
+	require.NoError(t, err)
+
+	require.Len(t, *events, 6)
+	assert.Equal(t, ChangeEvent{
+		Table: "items", Operation: "INSERT", Columns: []string{"name", "price"},
+		Keys: []map[string]interface{}{{"id": int64(1)}}, RowsAffected: 1,
+	}, (*events)[0], "the generated key is read from LastInsertId")
+	assert.Equal(t, []map[string]interface{}{{"id": 10}, {"id": 11}}, (*events)[1].Keys)
+	assert.Equal(t, int64(2), (*events)[1].RowsAffected)
+
+	update := (*events)[2]
+	assert.Equal(t, "UPDATE", update.Operation)
+	assert.Nil(t, update.Keys, "rows matched by an arbitrary WHERE are unknown")
+	assert.Equal(t, []string{"price"}, update.Columns)
+	assert.Equal(t, int64(2), update.RowsAffected)
+
+	assert.Equal(t, []map[string]interface{}{{"id": 10}}, (*events)[3].Keys)
+	assert.Equal(t, []string{"name"}, (*events)[3].Columns)
+	assert.Equal(t, "UPSERT", (*events)[4].Operation)
+	assert.Equal(t, []map[string]interface{}{{"id": 11}}, (*events)[4].Keys)
+	assert.Equal(t, ChangeEvent{Table: "items", Operation: "DELETE", RowsAffected: 1}, (*events)[5])
+
+	// Reads and raw SQL emit nothing; failed statements emit nothing.
+	var items []changedItem
+	require.NoError(t, qb.Select().From("items").All(&items))
+	_, err = db.ExecContext(context.Background(), "DELETE FROM items")
+	require.NoError(t, err)
+	_, err = qb.Insert("missing", map[string]interface{}{"id": 1}).Execute()
+	require.Error(t, err)
+	assert.Len(t, *events, 6)
+}
+
+func TestChangeSink_Returning(t *testing.T) {
+	db, events := setupChangeDB(t)
+	qb := db.Builder()
+	_, err := qb.BatchInsert("items", []string{"name", "price"}).Values("a", 1).Values("b", 2).Values("c", 3).Execute()
+	require.NoError(t, err)
+	*events = nil
+
+	var deleted []changedItem
+	require.NoError(t, qb.Delete("items").Where("price < ?", 3).Returning("id", "name").Build().Tag("cleanup").All(&deleted))
+	require.Len(t, *events, 1)
+	assert.Equal(t, []map[string]interface{}{{"id": int64(1)}, {"id": int64(2)}}, (*events)[0].Keys)
+	assert.Equal(t, int64(2), (*events)[0].RowsAffected)
+	assert.Equal(t, "cleanup", (*events)[0].Tag)
+
+	var id int64
+	require.NoError(t, qb.Update("items").Set(map[string]interface{}{"price": 9}).Where("name = ?", "c").Returning("id").Build().Row(&id))
+	assert.Equal(t, []map[string]interface{}{{"id": int64(3)}}, (*events)[1].Keys)
+
+	var ids []int64
+	require.NoError(t, qb.Update("items").Set(map[string]interface{}{"price": 1}).Returning("id").Build().Column(&ids))
+	assert.Equal(t, []map[string]interface{}{{"id": int64(3)}}, (*events)[2].Keys)
+	assert.Equal(t, int64(1), (*events)[2].RowsAffected)
+}
+
+func TestChangeSink_Model(t *testing.T) {
+	db, events := setupChangeDB(t)
+
+	item := changedItem{Name: "cup", Price: 4}
+	require.NoError(t, db.Model(&item).Insert())
+	original := item
+	item.Price = 5
+	require.NoError(t, db.Model(&item).UpdateChanged(original))
+	require.NoError(t, db.Model(&item).Update("name"))
+	require.NoError(t, db.Model(&item).Delete())
+
+	require.Len(t, *events, 4)
+	key := []map[string]interface{}{{"id": item.ID}}
+	for i, op := range []string{"INSERT", "UPDATE", "UPDATE", "DELETE"} {
+		assert.Equal(t, op, (*events)[i].Operation)
+		assert.Equal(t, key, (*events)[i].Keys)
+		assert.Equal(t, int64(1), (*events)[i].RowsAffected)
+	}
+	assert.Equal(t, []string{"price"}, (*events)[1].Columns)
+	assert.Equal(t, []string{"name"}, (*events)[2].Columns)
+}
+
+func TestChangeSink_Transactions(t *testing.T) {
+	db, events := setupChangeDB(t)
+	ctx := context.Background()
+
+	errAbort := errors.New("abort")
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.Builder().Insert("items", map[string]interface{}{"id": 1, "name": "a"}).Execute()
+		require.NoError(t, err)
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	assert.Empty(t, *events, "rolled back changes are discarded")
+
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.Builder().Insert("items", map[string]interface{}{"id": 2, "name": "b"}).Execute()
+		require.NoError(t, err)
+		// Savepoints of a bound DB do not release events early.
+		return tx.DB().Transactional(ctx, func(sp *Tx) error {
+			_, err := sp.Builder().Delete("items").Where("id = ?", 2).Execute()
+			require.NoError(t, err)
+			assert.Empty(t, *events, "events are held until commit")
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	require.Len(t, *events, 2)
+	assert.Equal(t, "INSERT", (*events)[0].Operation)
+	assert.Equal(t, "DELETE", (*events)[1].Operation)
+	assert.Empty(t, db.changes.pending)
+}
+
+func TestChangeSink_Disabled(t *testing.T) {
+	db := mockDB("postgres")
+	q := db.Builder().Insert("items", map[string]interface{}{"id": 1})
+	assert.Nil(t, q.change)
+}
@@ -0,0 +1,237 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// Chunked IN queries
+// ============================================================================
+//
+// Databases cap the number of bind parameters per statement (SQLite: 999 on
+// older builds, PostgreSQL and MySQL: 65535), so a single IN list with tens of
+// thousands of IDs fails with ErrTooManyParams when built. WhereInChunked
+// splits the list into several queries and merges their results.
+
+// DefaultInChunkSize is the chunk size used by WhereInChunked when chunkSize <= 0
+// and by ModelQuery.FindByIDs. It stays below SQLite's historical 999-parameter limit.
+const DefaultInChunkSize = 500
+
+// errChunkedExecution is returned when a WhereInChunked query is executed with
+// a method that cannot merge results from several statements.
+var errChunkedExecution = errors.New("relica: WhereInChunked queries can only be executed with All() or Column()")
+
+// chunkedIn holds the IN list of a WhereInChunked query.
+type chunkedIn struct {
+	col           string
+	values        []interface{}
+	size          int
+	preserveOrder bool
+}
+
+// WhereInChunked adds "col IN (values...)" and, at execution, splits values into
+// chunks of chunkSize, runs one query per chunk and merges the results.
+// values must be a slice (e.g. []int64, []string or []interface{}).
+// If chunkSize <= 0, DefaultInChunkSize is used.
+//
+// Only All() and Column() can execute chunked queries. ORDER BY applies within each
+// chunk; results are concatenated in chunk order unless PreserveOrder() is set.
+// Limit(), Offset() and set operations are not supported.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Builder().Select().From("users").
+//	    Where("status = ?", "active").
+//	    WhereInChunked("id", ids, 1000).
+//	    PreserveOrder().
+//	    All(&users)
+func (sq *SelectQuery) WhereInChunked(col string, values interface{}, chunkSize int) *SelectQuery {
+	sq = sq.own()
+	vals, err := toInterfaceSlice(values)
+	if err != nil {
+		sq.buildErr = fmt.Errorf("relica: WhereInChunked() %w", err)
+		return sq
+	}
+	if strings.TrimSpace(col) == "" {
+		sq.buildErr = fmt.Errorf("relica: WhereInChunked() requires a column name")
+		return sq
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultInChunkSize
+	}
+	sq.chunkedIn = &chunkedIn{col: col, values: vals, size: chunkSize}
+	return sq
+}
+
+// PreserveOrder sorts the results of a WhereInChunked query in the order of the
+// given values. The destination must be a slice of structs (or struct pointers)
+// with a field mapped to the chunked column. Rows are matched by formatted value,
+// so an int64 column matches int IDs.
+func (sq *SelectQuery) PreserveOrder() *SelectQuery {
+	sq = sq.own()
+	if sq.chunkedIn == nil {
+		sq.buildErr = fmt.Errorf("relica: PreserveOrder() requires WhereInChunked()")
+		return sq
+	}
+	sq.chunkedIn.preserveOrder = true
+	return sq
+}
+
+// allChunked executes a WhereInChunked query into dest (a pointer to a slice).
+func (sq *SelectQuery) allChunked(dest interface{}, column bool) error {
+	if sq.buildErr != nil {
+		return sq.buildErr
+	}
+	if sq.limitValue != nil || sq.offsetValue != nil || len(sq.unions) > 0 {
+		return fmt.Errorf("relica: WhereInChunked() cannot be combined with Limit(), Offset() or set operations")
+	}
+	if column && sq.chunkedIn.preserveOrder {
+		return fmt.Errorf("relica: PreserveOrder() requires All() with a struct slice")
+	}
+
+	if sink, ok := dest.(rowSink); ok {
+		return sq.allChunkedSink(sink)
+	}
+
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Pointer || destVal.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("relica: destination must be a pointer to a slice, got %T", dest)
+	}
+	sliceType := destVal.Elem().Type()
+	merged := reflect.MakeSlice(sliceType, 0, len(sq.chunkedIn.values))
+
+	ci := sq.chunkedIn
+	for start := 0; start < len(ci.values); start += ci.size {
+		end := start + ci.size
+		if end > len(ci.values) {
+			end = len(ci.values)
+		}
+
+		chunk := sq.chunkQuery(ci.values[start:end])
+		part := reflect.New(sliceType)
+		var err error
+		if column {
+			err = chunk.Build().Column(part.Interface())
+		} else {
+			err = chunk.Build().All(part.Interface())
+		}
+		if err != nil {
+			return err
+		}
+		merged = reflect.AppendSlice(merged, part.Elem())
+	}
+
+	if ci.preserveOrder {
+		if err := sortByInputOrder(merged, ci.col, ci.values); err != nil {
+			return err
+		}
+	}
+	destVal.Elem().Set(merged)
+	return nil
+}
+
+// allChunkedSink streams the rows of every chunk into a row mapper.
+func (sq *SelectQuery) allChunkedSink(sink rowSink) error {
+	ci := sq.chunkedIn
+	if ci.preserveOrder {
+		return fmt.Errorf("relica: PreserveOrder() requires All() with a struct slice")
+	}
+	for start := 0; start < len(ci.values); start += ci.size {
+		end := min(start+ci.size, len(ci.values))
+		if err := sq.chunkQuery(ci.values[start:end]).Build().All(sink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkQuery returns a copy of sq restricted to "col IN (values...)".
+func (sq *SelectQuery) chunkQuery(values []interface{}) *SelectQuery {
+	c := *sq
+	c.chunkedIn = nil
+	c.where = append([]string(nil), sq.where...)
+	c.params = append([]interface{}(nil), sq.params...)
+	return c.Where(In(sq.chunkedIn.col, values...))
+}
+
+// sortByInputOrder stably sorts rows (a slice of structs or struct pointers) by the
+// position of their col value in values. Rows with unknown values go last.
+func sortByInputOrder(rows reflect.Value, col string, values []interface{}) error {
+	if rows.Len() == 0 {
+		return nil
+	}
+	if i := strings.LastIndex(col, "."); i >= 0 {
+		col = col[i+1:]
+	}
+
+	elemType := rows.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("relica: PreserveOrder() requires a slice of structs, got %s", rows.Type())
+	}
+	fieldIndex := -1
+	for i := 0; i < elemType.NumField(); i++ {
+		if name, skip := columnFromField(elemType.Field(i)); !skip && strings.EqualFold(name, col) {
+			fieldIndex = i
+			break
+		}
+	}
+	if fieldIndex < 0 {
+		return fmt.Errorf("relica: PreserveOrder() found no field for column %q in %s", col, elemType)
+	}
+
+	position := make(map[string]int, len(values))
+	for i, v := range values {
+		key := fmt.Sprint(v)
+		if _, seen := position[key]; !seen {
+			position[key] = i
+		}
+	}
+
+	keys := make([]int, rows.Len())
+	for i := range keys {
+		row := reflect.Indirect(rows.Index(i))
+		keys[i] = len(values)
+		if row.IsValid() {
+			if pos, ok := position[fmt.Sprint(reflect.Indirect(row.Field(fieldIndex)))]; ok {
+				keys[i] = pos
+			}
+		}
+	}
+
+	order := make([]int, rows.Len())
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return keys[order[a]] < keys[order[b]] })
+
+	sorted := reflect.MakeSlice(rows.Type(), rows.Len(), rows.Len())
+	for i, idx := range order {
+		sorted.Index(i).Set(rows.Index(idx))
+	}
+	reflect.Copy(rows, sorted)
+	return nil
+}
+
+// toInterfaceSlice converts any slice (or array) to []interface{}.
+func toInterfaceSlice(values interface{}) ([]interface{}, error) {
+	if vals, ok := values.([]interface{}); ok {
+		return vals, nil
+	}
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expects a slice of values, got %T", values)
+	}
+	vals := make([]interface{}, v.Len())
+	for i := range vals {
+		vals[i] = v.Index(i).Interface()
+	}
+	return vals, nil
+}
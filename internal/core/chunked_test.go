@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type chunkedProduct struct {
+	ID    int64  `db:"id"`
+	Title string `db:"title"`
+	Price int    `db:"price"`
+}
+
+func (chunkedProduct) TableName() string { return "products" }
+
+// setupChunkedTestDB creates a products table with ids 1..n, counting executed statements.
+func setupChunkedTestDB(t *testing.T, n int, queries *int) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:", WithQueryHook(func(_ context.Context, e QueryEvent) {
+		*queries++
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE products (id INTEGER PRIMARY KEY, title TEXT, price INTEGER)`)
+	require.NoError(t, err)
+	for i := 1; i <= n; i++ {
+		_, err = db.ExecContext(context.Background(), `INSERT INTO products (id, title, price) VALUES (?, ?, ?)`,
+			i, fmt.Sprintf("p%d", i), i%2)
+		require.NoError(t, err)
+	}
+	return db
+}
+
+func TestWhereInChunked_All(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 10, &queries)
+
+	var products []chunkedProduct
+	err := db.Builder().Select().From("products").
+		Where("price = ?", 1).
+		WhereInChunked("id", []int{9, 1, 3, 4, 7, 42}, 2).
+		All(&products)
+	require.NoError(t, err)
+	assert.Equal(t, 3, queries) // 6 ids in chunks of 2
+
+	ids := make([]int64, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	assert.ElementsMatch(t, []int64{9, 1, 3, 7}, ids) // price = 1 filter still applies
+}
+
+func TestWhereInChunked_PreserveOrder(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 10, &queries)
+
+	var products []*chunkedProduct
+	err := db.Builder().Select("id", "title").From("products").
+		OrderBy("id").
+		WhereInChunked("products.id", []interface{}{8, 2, 5, 10, 1}, 2).
+		PreserveOrder().
+		All(&products)
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []int64{8, 2, 5, 10, 1}, ids)
+}
+
+func TestWhereInChunked_Column(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 5, &queries)
+
+	var titles []string
+	err := db.Builder().Select("title").From("products").
+		WhereInChunked("id", []int64{1, 2, 3, 4, 5}, 0).
+		Column(&titles)
+	require.NoError(t, err)
+	assert.Len(t, titles, 5)
+	assert.Equal(t, 1, queries) // default chunk size covers all ids
+}
+
+func TestWhereInChunked_EmptyValues(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 3, &queries)
+
+	products := []chunkedProduct{{ID: 99}}
+	err := db.Builder().Select().From("products").WhereInChunked("id", []int{}, 10).All(&products)
+	require.NoError(t, err)
+	assert.Empty(t, products)
+	assert.Equal(t, 0, queries)
+}
+
+func TestWhereInChunked_Errors(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 3, &queries)
+	var products []chunkedProduct
+
+	err := db.Builder().Select().From("products").WhereInChunked("id", 42, 10).All(&products)
+	assert.ErrorContains(t, err, "expects a slice")
+
+	err = db.Builder().Select().From("products").PreserveOrder().All(&products)
+	assert.ErrorContains(t, err, "requires WhereInChunked")
+
+	err = db.Builder().Select().From("products").WhereInChunked("id", []int{1}, 10).Limit(1).All(&products)
+	assert.ErrorContains(t, err, "cannot be combined")
+
+	var p chunkedProduct
+	err = db.Builder().Select().From("products").WhereInChunked("id", []int{1}, 10).One(&p)
+	assert.ErrorIs(t, err, errChunkedExecution)
+
+	_, err = db.Builder().Select().From("products").WhereInChunked("id", []int{1}, 10).Count()
+	assert.ErrorIs(t, err, errChunkedExecution)
+
+	var titles []string
+	err = db.Builder().Select("title").From("products").WhereInChunked("id", []int{1}, 10).PreserveOrder().Column(&titles)
+	assert.ErrorContains(t, err, "requires All()")
+
+	var names []struct{ Name string }
+	err = db.Builder().Select("title").From("products").WhereInChunked("id", []int{1}, 10).PreserveOrder().All(&names)
+	assert.ErrorContains(t, err, `no field for column "id"`)
+}
+
+func TestModelQuery_FindByIDs(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 1200, &queries)
+
+	ids := make([]int64, 0, 1100)
+	for i := int64(1100); i >= 1; i-- {
+		ids = append(ids, i)
+	}
+
+	var products []chunkedProduct
+	require.NoError(t, db.Model(&chunkedProduct{}).FindByIDs(ids, &products))
+	require.Len(t, products, 1100)
+	assert.Equal(t, int64(1100), products[0].ID)
+	assert.Equal(t, int64(1), products[1099].ID)
+	assert.Equal(t, "p1100", products[0].Title)
+	assert.Equal(t, 3, queries) // 1100 ids / 500 per chunk
+
+	err := db.Model(&TestOrderItem{}).FindByIDs([]int{1}, &products)
+	assert.ErrorContains(t, err, "single-column primary key")
+}
@@ -0,0 +1,79 @@
+package core
+
+import "slices"
+
+// ============================================================================
+// Cloning and immutable queries
+// ============================================================================
+//
+// Chained SelectQuery calls modify the query in place and return it, so a
+// base query shared between requests accumulates their conditions. Clone
+// copies a query; Immutable makes every chained call return a modified copy,
+// so the base query can serve as a template.
+
+// Clone returns an independent copy of the query: chained calls on the copy
+// do not affect the original and vice versa. Subqueries, CTE queries and set
+// operation members are shared, not copied.
+//
+// Example:
+//
+//	base := db.Select().From("orders").Where("tenant_id = ?", tenant)
+//	open := base.Clone().Where("status = ?", "open")
+//	late := base.Clone().Where("due_at < ?", now)
+func (sq *SelectQuery) Clone() *SelectQuery {
+	c := *sq
+	c.columns = slices.Clone(sq.columns)
+	c.selectExprs = slices.Clone(sq.selectExprs)
+	c.subExprs = slices.Clone(sq.subExprs)
+	c.joins = slices.Clone(sq.joins)
+	c.where = slices.Clone(sq.where)
+	c.params = slices.Clone(sq.params)
+	c.groupBy = slices.Clone(sq.groupBy)
+	c.groupByExprs = slices.Clone(sq.groupByExprs)
+	c.havingClauses = slices.Clone(sq.havingClauses)
+	c.orderBy = slices.Clone(sq.orderBy)
+	c.orderByExprs = slices.Clone(sq.orderByExprs)
+	c.subOrderByExprs = slices.Clone(sq.subOrderByExprs)
+	c.subGroupByExprs = slices.Clone(sq.subGroupByExprs)
+	c.groupingElems = slices.Clone(sq.groupingElems)
+	c.unions = slices.Clone(sq.unions)
+	c.unionOrderBy = slices.Clone(sq.unionOrderBy)
+	c.ctes = slices.Clone(sq.ctes)
+	c.indexHints = slices.Clone(sq.indexHints)
+	c.hints = slices.Clone(sq.hints)
+	if sq.fromSrc != nil {
+		from := *sq.fromSrc
+		c.fromSrc = &from
+	}
+	if sq.chunkedIn != nil {
+		chunked := *sq.chunkedIn
+		c.chunkedIn = &chunked
+	}
+	return &c
+}
+
+// Immutable returns a copy of the query on which every chained call returns
+// a modified copy instead of changing the query itself. Use it for base
+// queries that are shared between requests or goroutines.
+//
+// Example:
+//
+//	var activeUsers = db.Select("id", "name").From("users").Where("active = ?", true).Immutable()
+//
+//	func byTeam(team int) *relica.SelectQuery {
+//	    return activeUsers.Where("team_id = ?", team) // activeUsers is unchanged
+//	}
+func (sq *SelectQuery) Immutable() *SelectQuery {
+	c := sq.Clone()
+	c.immutable = true
+	return c
+}
+
+// own returns the query a chained call may modify: a copy for immutable
+// queries, the query itself otherwise.
+func (sq *SelectQuery) own() *SelectQuery {
+	if sq.immutable {
+		return sq.Clone()
+	}
+	return sq
+}
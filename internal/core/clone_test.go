@@ -0,0 +1,95 @@
+package core
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectQuery_Clone(t *testing.T) {
+	db := mockDB("postgres")
+	base := db.Builder().Select("id").From("orders").Where("tenant_id = ?", 7).WhereInChunked("id", []int{1, 2}, 10)
+
+	open := base.Clone().Where("status = ?", "open").OrWhere("priority = ?", 1).OrderBy("id").PreserveOrder()
+	late := base.Clone().Where("due_at < ?", "2026-01-01").Limit(5)
+
+	sql, params := base.ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "orders" WHERE tenant_id = $1`, sql)
+	assert.Equal(t, []interface{}{7}, params)
+	assert.False(t, base.chunkedIn.preserveOrder)
+
+	sql, params = open.ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "orders" WHERE (tenant_id = $1 AND status = $2) OR (priority = $3) ORDER BY "id"`, sql)
+	assert.Equal(t, []interface{}{7, "open", 1}, params)
+
+	sql, params = late.ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "orders" WHERE tenant_id = $1 AND due_at < $2 LIMIT 5`, sql)
+	assert.Equal(t, []interface{}{7, "2026-01-01"}, params)
+}
+
+func TestSelectQuery_Immutable(t *testing.T) {
+	db := mockDB("postgres")
+	base := db.Builder().Select("id").From("users").Where("active = ?", true).Immutable()
+
+	team := base.Where("team_id = ?", 3).OrderBy("name").Limit(10)
+	admins := base.AndWhere("role = ?", "admin").Tag("users.admins")
+	assert.NotSame(t, base, team)
+
+	sql, params := base.ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE active = $1`, sql)
+	assert.Equal(t, []interface{}{true}, params)
+
+	sql, params = team.ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE active = $1 AND team_id = $2 ORDER BY "name" LIMIT 10`, sql)
+	assert.Equal(t, []interface{}{true, 3}, params)
+
+	sql, _ = admins.ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE active = $1 AND role = $2`, sql)
+	assert.Equal(t, "users.admins", admins.Build().tag)
+	assert.Empty(t, base.Build().tag)
+
+	// Copies stay immutable
+	step := team.Offset(20)
+	sql, _ = team.ToSQL()
+	assert.NotContains(t, sql, "OFFSET")
+	sql, _ = step.ToSQL()
+	assert.Contains(t, sql, "OFFSET 20")
+}
+
+func TestSelectQuery_ImmutableFilters(t *testing.T) {
+	db := mockDB("postgres")
+	spec := NewFilterSpec().Allow("status", "status", FilterEq).SortBy("sort", AllowedColumns("name"))
+	base := db.Builder().Select().From("users").Immutable()
+
+	filtered := base.ApplyFilters(spec, map[string][]string{"status": {"active"}, "sort": {"-name"}})
+	sql, params := filtered.ToSQL()
+	assert.Equal(t, `SELECT * FROM "users" WHERE "status" = $1 ORDER BY "name" DESC`, sql)
+	assert.Equal(t, []interface{}{"active"}, params)
+
+	sql, _ = base.ToSQL()
+	assert.Equal(t, `SELECT * FROM "users"`, sql)
+}
+
+func TestSelectQuery_ImmutableConcurrent(t *testing.T) {
+	db := mockDB("postgres")
+	base := db.Builder().Select("id").From("users").Where("active = ?", true).Immutable()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, params := base.Where("team_id = ?", i).OrWhere("owner = ?", j).ToSQL()
+				require.Len(t, params, 3)
+				assert.Equal(t, i, params[1])
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	_, params := base.ToSQL()
+	assert.Len(t, params, 1)
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// ============================================================================
+// NULL handling of Column
+// ============================================================================
+//
+// Column scans into the element type of the destination slice. Pointer
+// elements ([]*string) and sql.Scanner elements ([]sql.NullString) hold NULL
+// themselves. For other element types ([]string, []int64), a NULL cannot be
+// stored: by default the scan fails, and WithColumnNulls makes Column skip
+// such rows or store the zero value instead. ColumnNullable stores the zero
+// value and reports which rows were NULL.
+//
+// NULL-tolerant scans go through a **T holder, which database/sql sets to nil
+// for NULL and otherwise converts like a plain *T scan. Whether an element
+// type needs the holder is decided once per call, not per row.
+
+// ColumnNulls selects what Column does with a NULL that the destination
+// element type cannot hold (see WithColumnNulls).
+type ColumnNulls int
+
+const (
+	// ColumnNullsError fails the scan (the default).
+	ColumnNullsError ColumnNulls = iota
+	// ColumnNullsSkip leaves the row out of the slice.
+	ColumnNullsSkip
+	// ColumnNullsZero stores the zero value of the element type.
+	ColumnNullsZero
+)
+
+// scannerType is the reflect type of sql.Scanner.
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// WithColumnNulls sets how Column handles NULLs scanned into element types
+// that cannot hold them, such as []string or []int. Pointer and sql.Scanner
+// element types ([]*string, []sql.NullString) always receive NULLs as such.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithColumnNulls(relica.ColumnNullsSkip))
+//	var emails []string
+//	db.Select("email").From("users").Column(&emails) // users without email are left out
+func WithColumnNulls(mode ColumnNulls) Option {
+	return optionFunc(func(db *DB) {
+		db.columnNulls = mode
+	})
+}
+
+// ColumnNullable scans the first column of all rows into slice like Column,
+// storing the zero value for NULLs the element type cannot hold. valid, if not
+// nil, receives one entry per row: false where the column was NULL.
+//
+// Example:
+//
+//	var scores []int
+//	var known []bool
+//	err := db.Select("score").From("players").OrderBy("id").ColumnNullable(&scores, &known)
+func (q *Query) ColumnNullable(slice interface{}, valid *[]bool) error {
+	ctx, span := q.startSpan()
+	ctx, finish := q.watch(ctx)
+	err := classifyError(finish(q.column(ctx, slice, ColumnNullsZero, valid)))
+	if err == nil {
+		q.db.fillEmptySlice(slice)
+	}
+	span.end(err, nil)
+	return err
+}
+
+// ColumnNullable scans the first column of all rows into slice, storing the
+// zero value for NULLs, and records in valid which rows were not NULL (see
+// Query.ColumnNullable).
+func (sq *SelectQuery) ColumnNullable(slice interface{}, valid *[]bool) error {
+	if sq.chunkedIn != nil {
+		return fmt.Errorf("relica: ColumnNullable() cannot be combined with WhereInChunked()")
+	}
+	return sq.Build().ColumnNullable(slice, valid)
+}
+
+// columnScan describes how column scans one row: into a plain element, or
+// into a **T holder that tolerates NULL.
+type columnScan struct {
+	elemType reflect.Type
+	holder   bool        // scan through **T
+	nulls    ColumnNulls // handling of NULL when holder is set
+	valid    *[]bool     // receives per-row validity (ColumnNullable); nil if not wanted
+}
+
+// newColumnScan decides how rows are scanned into elements of elemType.
+func newColumnScan(elemType reflect.Type, nulls ColumnNulls, valid *[]bool) columnScan {
+	holdsNull := elemType.Kind() == reflect.Pointer || elemType.Kind() == reflect.Interface ||
+		reflect.PointerTo(elemType).Implements(scannerType)
+	return columnScan{
+		elemType: elemType,
+		holder:   valid != nil || (!holdsNull && nulls != ColumnNullsError),
+		nulls:    nulls,
+		valid:    valid,
+	}
+}
+
+// scan scans the current row and appends its value to sliceVal, unless the
+// value is a NULL to skip.
+func (cs columnScan) scan(rows *sql.Rows, sliceVal reflect.Value) error {
+	target := cs.target()
+	if err := rows.Scan(target.Interface()); err != nil {
+		return err
+	}
+	if !cs.skips(target) {
+		cs.store(target, sliceVal)
+	}
+	return nil
+}
+
+// target returns a new scan destination for one value.
+func (cs columnScan) target() reflect.Value {
+	if cs.holder {
+		return reflect.New(reflect.PointerTo(cs.elemType))
+	}
+	return reflect.New(cs.elemType)
+}
+
+// skips reports whether the value scanned into target is a NULL to skip.
+func (cs columnScan) skips(target reflect.Value) bool {
+	return cs.holder && cs.valid == nil && cs.nulls == ColumnNullsSkip && target.Elem().IsNil()
+}
+
+// value returns the element value scanned into target and whether it was
+// not NULL.
+func (cs columnScan) value(target reflect.Value) (reflect.Value, bool) {
+	if !cs.holder {
+		return target.Elem(), true
+	}
+	if target.Elem().IsNil() {
+		return reflect.Zero(cs.elemType), false
+	}
+	return target.Elem().Elem(), true
+}
+
+// store appends the value scanned into target to sliceVal.
+func (cs columnScan) store(target, sliceVal reflect.Value) {
+	v, ok := cs.value(target)
+	sliceVal.Set(reflect.Append(sliceVal, v))
+	if cs.valid != nil {
+		*cs.valid = append(*cs.valid, ok)
+	}
+}
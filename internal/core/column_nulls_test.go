@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupNullColumnDB(t *testing.T, opts ...Option) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE players (id INTEGER PRIMARY KEY, name TEXT, score INTEGER);
+		INSERT INTO players (id, name, score) VALUES (1, 'ann', 10), (2, NULL, NULL), (3, 'cy', 30)`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestColumn_NullElements(t *testing.T) {
+	db := setupNullColumnDB(t)
+	scores := func() *SelectQuery { return db.Builder().Select("score").From("players").OrderBy("id") }
+
+	var plain []int
+	assert.Error(t, scores().Column(&plain), "NULL into int fails by default")
+
+	var ptrs []*int
+	require.NoError(t, scores().Column(&ptrs))
+	require.Len(t, ptrs, 3)
+	assert.Nil(t, ptrs[1])
+	assert.Equal(t, 30, *ptrs[2])
+
+	var nulls []sql.NullString
+	require.NoError(t, db.Builder().Select("name").From("players").OrderBy("id").Column(&nulls))
+	assert.Equal(t, []sql.NullString{{String: "ann", Valid: true}, {}, {String: "cy", Valid: true}}, nulls)
+}
+
+func TestWithColumnNulls(t *testing.T) {
+	var scores []int
+	db := setupNullColumnDB(t, WithColumnNulls(ColumnNullsSkip))
+	require.NoError(t, db.Builder().Select("score").From("players").OrderBy("id").Column(&scores))
+	assert.Equal(t, []int{10, 30}, scores)
+
+	scores = nil
+	db = setupNullColumnDB(t, WithColumnNulls(ColumnNullsZero))
+	require.NoError(t, db.Builder().Select("score").From("players").OrderBy("id").Column(&scores))
+	assert.Equal(t, []int{10, 0, 30}, scores)
+
+	var ptrs []*int
+	require.NoError(t, db.Builder().Select("score").From("players").OrderBy("id").Column(&ptrs))
+	assert.Nil(t, ptrs[1], "pointer elements keep NULL")
+}
+
+func TestColumnNullable(t *testing.T) {
+	db := setupNullColumnDB(t)
+
+	var names []string
+	var valid []bool
+	require.NoError(t, db.Builder().Select("name").From("players").OrderBy("id").ColumnNullable(&names, &valid))
+	assert.Equal(t, []string{"ann", "", "cy"}, names)
+	assert.Equal(t, []bool{true, false, true}, valid)
+
+	var scores []sql.NullInt64
+	valid = nil
+	require.NoError(t, db.NewQuery("SELECT score FROM players ORDER BY id").ColumnNullable(&scores, &valid))
+	assert.Equal(t, []bool{true, false, true}, valid)
+	assert.Equal(t, int64(30), scores[2].Int64)
+
+	var ids []int
+	require.NoError(t, db.Builder().Select("score").From("players").OrderBy("id").ColumnNullable(&ids, nil))
+	assert.Equal(t, []int{10, 0, 30}, ids)
+
+	err := db.Builder().Select("name").From("players").WhereInChunked("id", []interface{}{1, 2}, 1).ColumnNullable(&names, nil)
+	assert.ErrorContains(t, err, "cannot be combined with WhereInChunked()")
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// ConditionBuilder
+// ============================================================================
+//
+// A ConditionBuilder assembles a WHERE condition apart from any query, so the
+// same filter can be applied to a list query and its count query, or to a
+// SELECT and the DELETE that follows it. Builders are immutable: And and Or
+// return a new builder and leave the receiver unchanged, so a shared base
+// filter can be extended per query without affecting the others.
+
+// ConditionBuilder is a reusable WHERE condition (see NewCondition). It is an
+// Expression, so it can also be nested in And, Or and Not.
+type ConditionBuilder struct {
+	exp Expression
+	err error // stored programming error, reported by the query using the builder
+}
+
+// NewCondition returns a condition builder combining exps with AND. With no
+// arguments the condition is empty and adds nothing to a query.
+//
+// Example:
+//
+//	cb := relica.NewCondition().
+//	    And("tenant_id = ?", tenantID).
+//	    And(relica.In("status", "active", "trial"))
+//	if search != "" {
+//	    cb = cb.And(relica.Like("name", search))
+//	}
+//	db.Select().From("accounts").WhereCond(cb).Limit(20).All(&page)
+//	db.Select().From("accounts").WhereCond(cb).Count()
+func NewCondition(exps ...Expression) *ConditionBuilder {
+	cb := &ConditionBuilder{}
+	if len(exps) > 0 {
+		cb.exp = And(exps...)
+	}
+	return cb
+}
+
+// And returns a builder requiring both the current condition and condition,
+// which is a string with placeholders or an Expression, as for Where.
+func (cb *ConditionBuilder) And(condition interface{}, params ...interface{}) *ConditionBuilder {
+	return cb.combine("And", "AND", condition, params)
+}
+
+// Or returns a builder accepting rows that match the current condition or
+// condition, which is a string with placeholders or an Expression, as for
+// Where. On an empty builder, Or behaves like And.
+//
+// Example:
+//
+//	cb := relica.NewCondition(relica.Eq("owner_id", userID)).Or("shared = ?", true)
+//	// (owner_id = ?) OR (shared = ?)
+func (cb *ConditionBuilder) Or(condition interface{}, params ...interface{}) *ConditionBuilder {
+	return cb.combine("Or", "OR", condition, params)
+}
+
+// combine returns a new builder joining the current condition and condition
+// with op.
+func (cb *ConditionBuilder) combine(method, op string, condition interface{}, params []interface{}) *ConditionBuilder {
+	next := &ConditionBuilder{exp: cb.exp, err: cb.err}
+	if next.err != nil {
+		return next
+	}
+
+	var exp Expression
+	switch cond := condition.(type) {
+	case string:
+		resolved, args, err := resolveNamedParams(cond, params)
+		if err != nil {
+			next.err = err
+			return next
+		}
+		exp = NewExp(resolved, args...)
+	case Expression:
+		exp = cond
+	default:
+		next.err = fmt.Errorf("relica: ConditionBuilder.%s() expects string or Expression, got %T", method, condition)
+		return next
+	}
+
+	prev, ok := next.exp.(*AndOrExp)
+	switch {
+	case next.exp == nil:
+		next.exp = exp
+	case ok && prev.Op == op:
+		// Extend a chain of the same operator instead of nesting it. The
+		// slice is copied, since other builders may share it.
+		next.exp = &AndOrExp{Exps: append(slices.Clip(prev.Exps), exp), Op: op}
+	default:
+		next.exp = &AndOrExp{Exps: []Expression{next.exp, exp}, Op: op}
+	}
+	return next
+}
+
+// Build converts the condition into a SQL fragment. An empty builder builds
+// to an empty fragment.
+func (cb *ConditionBuilder) Build(dialect dialects.Dialect) (string, []interface{}) {
+	if cb == nil || cb.exp == nil || cb.err != nil {
+		return "", nil
+	}
+	return cb.exp.Build(dialect)
+}
+
+// checkDialect reports a stored programming error, or an expression of the
+// condition that does not support dialect.
+func (cb *ConditionBuilder) checkDialect(dialect dialects.Dialect) error {
+	if cb == nil {
+		return nil
+	}
+	if cb.err != nil {
+		return cb.err
+	}
+	return checkExpressionTree(cb.exp, dialect)
+}
+
+// checkExpressionTree is checkExpression for exp and the expressions it
+// combines with And, Or and Not.
+func checkExpressionTree(exp Expression, dialect dialects.Dialect) error {
+	switch e := exp.(type) {
+	case *AndOrExp:
+		for _, sub := range e.Exps {
+			if err := checkExpressionTree(sub, dialect); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *NotExp:
+		return checkExpressionTree(e.Exp, dialect)
+	case nil:
+		return nil
+	}
+	return checkExpression(exp, dialect)
+}
+
+// WhereCond adds the condition of cb to the WHERE clause, combined with AND
+// like Where. A nil or empty builder adds nothing.
+//
+// Example:
+//
+//	filter := relica.NewCondition(relica.Eq("status", "active"))
+//	total, err := db.Select().From("users").WhereCond(filter).Count()
+func (sq *SelectQuery) WhereCond(cb *ConditionBuilder) *SelectQuery {
+	if cb == nil {
+		return sq.own()
+	}
+	return sq.Where(cb)
+}
+
+// WhereCond adds the condition of cb to the WHERE clause, combined with AND
+// like Where. A nil or empty builder adds nothing.
+func (uq *UpdateQuery) WhereCond(cb *ConditionBuilder) *UpdateQuery {
+	if cb == nil {
+		return uq
+	}
+	return uq.Where(cb)
+}
+
+// WhereCond adds the condition of cb to the WHERE clause, combined with AND
+// like Where. A nil or empty builder adds nothing.
+func (dq *DeleteQuery) WhereCond(cb *ConditionBuilder) *DeleteQuery {
+	if cb == nil {
+		return dq
+	}
+	return dq.Where(cb)
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionBuilder_SharedAcrossQueries(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	cb := NewCondition(Eq("tenant_id", 7)).And("status IN (?, ?)", "active", "trial")
+
+	list := qb.Select().From("accounts").WhereCond(cb).Where("id > ?", 100).Limit(20).Build()
+	assert.Equal(t, `SELECT * FROM "accounts" WHERE ("tenant_id" = $1) AND (status IN ($2, $3)) AND id > $4 LIMIT 20`, list.SQL())
+	assert.Equal(t, []interface{}{7, "active", "trial", 100}, list.Params())
+
+	upd := qb.Update("accounts").Set(map[string]interface{}{"flag": true}).WhereCond(cb).Build()
+	assert.Equal(t, `UPDATE "accounts" SET "flag" = $1 WHERE ("tenant_id" = $2) AND (status IN ($3, $4))`, upd.SQL())
+
+	del := qb.Delete("accounts").WhereCond(cb).Build()
+	assert.Equal(t, `DELETE FROM "accounts" WHERE ("tenant_id" = $1) AND (status IN ($2, $3))`, del.SQL())
+	assert.Equal(t, []interface{}{7, "active", "trial"}, del.Params())
+}
+
+func TestConditionBuilder_Immutable(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+	base := NewCondition().And(Eq("a", 1))
+	withOr := base.Or(Eq("b", 2))
+	withAnd := base.And(Eq("c", 3))
+
+	sql, args := base.Build(qb.db.dialect)
+	assert.Equal(t, `"a" = ?`, sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _ = withOr.Build(qb.db.dialect)
+	assert.Equal(t, `("a" = ?) OR ("b" = ?)`, sql)
+	sql, _ = withAnd.Or(Eq("d", 4)).Build(qb.db.dialect)
+	assert.Equal(t, `(("a" = ?) AND ("c" = ?)) OR ("d" = ?)`, sql)
+
+	assert.Equal(t, `SELECT * FROM "t" WHERE ("a" = ?) OR ("b" = ?) OR (x = 1)`,
+		qb.Select().From("t").WhereCond(withOr.Or("x = 1")).Build().SQL())
+	sql, _ = base.Build(qb.db.dialect)
+	assert.Equal(t, `"a" = ?`, sql, "extending a builder leaves it unchanged")
+}
+
+func TestConditionBuilder_EmptyAndErrors(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+	assert.Equal(t, `SELECT * FROM "t"`, qb.Select().From("t").WhereCond(NewCondition()).WhereCond(nil).Build().SQL())
+
+	bad := NewCondition().And(42).And(Eq("a", 1))
+	var n int
+	err := qb.Select().From("t").WhereCond(bad).Row(&n)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ConditionBuilder.And() expects string or Expression, got int")
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// Conn returns a DB handle whose queries all run on a single connection
+// taken from the pool, so session state persists from one query to the next:
+// advisory locks, temporary tables, session variables and prepared
+// statements. Transactions begun on the handle run on that connection too.
+// Close the handle to return the connection to the pool; closing it does not
+// close the DB.
+//
+// A connection runs one statement at a time: do not run queries on the
+// handle concurrently, and close Rows before issuing the next query.
+//
+// Example:
+//
+//	conn, err := db.Conn(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer conn.Close()
+//
+//	conn.ExecContext(ctx, "CREATE TEMPORARY TABLE import (id INT, name TEXT)")
+//	conn.Builder().Insert("import", row).Execute()
+//	conn.ExecContext(ctx, "INSERT INTO users SELECT * FROM import")
+func (db *DB) Conn(ctx context.Context) (*DB, error) {
+	if db.bound != nil {
+		return nil, errors.New("relica: Conn() on a DB bound to a transaction")
+	}
+	if db.pinned != nil {
+		return nil, errors.New("relica: Conn() on a DB already bound to a connection")
+	}
+	if db.poolErr != nil {
+		return nil, db.poolErr
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := db.drain.enter(); err != nil {
+		return nil, err
+	}
+	conn, err := db.sqlDB.Conn(ctx)
+	db.drain.leave()
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	v := *db
+	v.pinned = conn
+	v.healthChecker = nil
+	return &v, nil
+}
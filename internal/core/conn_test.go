@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConn_SessionStatePersists(t *testing.T) {
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "conn.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+
+	// A temporary table exists only in the session that created it.
+	_, err = conn.ExecContext(ctx, "CREATE TEMP TABLE scratch (id INTEGER PRIMARY KEY, note TEXT)")
+	require.NoError(t, err)
+	for _, note := range []string{"a", "b", "c"} {
+		_, err = conn.Builder().Insert("scratch", map[string]interface{}{"note": note}).Execute()
+		require.NoError(t, err)
+	}
+
+	var n int
+	require.NoError(t, conn.Builder().Select("COUNT(*)").From("scratch").Row(&n))
+	assert.Equal(t, 3, n)
+	require.NoError(t, conn.NewQuery("SELECT COUNT(*) FROM scratch").Prepare().Row(&n))
+	require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM scratch").Scan(&n))
+
+	require.NoError(t, conn.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.Builder().Delete("scratch").Where(Eq("note", "a")).Execute()
+		return err
+	}))
+	require.NoError(t, conn.Builder().Select("COUNT(*)").From("scratch").Row(&n))
+	assert.Equal(t, 2, n, "transactions run on the pinned connection")
+
+	err = db.Builder().Select("COUNT(*)").From("scratch").Row(&n)
+	assert.ErrorContains(t, err, "no such table", "other connections do not see the session")
+
+	require.NoError(t, conn.Close())
+	assert.Zero(t, db.sqlDB.Stats().InUse, "Close returns the connection to the pool")
+	require.NoError(t, db.sqlDB.PingContext(ctx), "closing the handle leaves the DB open")
+}
+
+func TestConn_Errors(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Conn(ctx)
+	assert.ErrorContains(t, err, "already bound to a connection")
+
+	tx, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+	_, err = tx.DB().Conn(ctx)
+	assert.ErrorContains(t, err, "bound to a transaction")
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Deferred constraints and foreign key checks
+// ============================================================================
+//
+// Bulk loads and migrations that reorder rows often pass through states that
+// violate foreign keys until the transaction is complete. DeferConstraints
+// moves the checks to COMMIT; DisableForeignKeys skips them.
+//
+// Deferral ends with the transaction on its own. Disabling foreign key checks
+// on MySQL changes a session variable of the connection, so the Tx restores
+// it right before Commit or Rollback. A transaction that is never ended with
+// Commit or Rollback (e.g. abandoned after its context was canceled) returns
+// the connection to the pool with checks still disabled.
+
+// DeferConstraints defers constraint checks to the end of the transaction:
+// SET CONSTRAINTS ALL DEFERRED on PostgreSQL (for constraints declared
+// DEFERRABLE) and PRAGMA defer_foreign_keys on SQLite. Both revert on Commit
+// or Rollback; on SQLite, Commit checks the deferred foreign keys first and
+// rolls back on a violation. MySQL has no deferred constraints; use
+// DisableForeignKeys.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    if err := tx.DeferConstraints(); err != nil {
+//	        return err
+//	    }
+//	    // insert children before their parents ...
+//	    return nil
+//	})
+func (tx *Tx) DeferConstraints() error {
+	ctx := tx.context()
+	switch tx.builder.db.dialect.(type) {
+	case *dialects.PostgresDialect:
+		_, err := tx.tx.ExecContext(ctx, "SET CONSTRAINTS ALL DEFERRED")
+		return err
+	case *dialects.SQLiteDialect:
+		if tx.savepoint != "" {
+			return fmt.Errorf("relica: DeferConstraints() on SQLite must be called on the outermost transaction")
+		}
+		if _, err := tx.tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+			return err
+		}
+		tx.checkFKs = true
+		return nil
+	default:
+		return fmt.Errorf("relica: DeferConstraints() is not supported by MySQL; use DisableForeignKeys()")
+	}
+}
+
+// DisableForeignKeys turns off foreign key checks for the rest of the
+// transaction on MySQL (SET foreign_key_checks = 0), restoring them before
+// Commit or Rollback. Rows written meanwhile are not checked afterwards.
+//
+// SQLite only changes foreign key enforcement outside transactions: if it is
+// already off, DisableForeignKeys does nothing, otherwise it fails and
+// DeferConstraints is the alternative. On PostgreSQL use DeferConstraints.
+// A nested transaction (see Tx.DB) must leave it to the outermost one.
+func (tx *Tx) DisableForeignKeys() error {
+	if tx.savepoint != "" {
+		return fmt.Errorf("relica: DisableForeignKeys() must be called on the outermost transaction")
+	}
+	ctx := tx.context()
+	switch tx.builder.db.dialect.(type) {
+	case *dialects.MySQLDialect:
+		var enabled int
+		if err := tx.tx.QueryRowContext(ctx, "SELECT @@SESSION.foreign_key_checks").Scan(&enabled); err != nil {
+			return err
+		}
+		if enabled == 0 {
+			return nil
+		}
+		if _, err := tx.tx.ExecContext(ctx, "SET foreign_key_checks = 0"); err != nil {
+			return err
+		}
+		tx.restore = append(tx.restore, "SET foreign_key_checks = 1")
+		return nil
+	case *dialects.SQLiteDialect:
+		var enabled int
+		if err := tx.tx.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+			return err
+		}
+		if enabled != 0 {
+			return fmt.Errorf("relica: SQLite cannot disable foreign keys inside a transaction; use DeferConstraints() or PRAGMA foreign_keys = OFF before Begin")
+		}
+		return nil
+	default:
+		return fmt.Errorf("relica: DisableForeignKeys() is not supported by PostgreSQL; use DeferConstraints()")
+	}
+}
+
+// beforeCommit verifies deferred SQLite foreign keys and undoes
+// DisableForeignKeys. SQLite keeps the transaction open when COMMIT fails on
+// a deferred violation, which database/sql does not expect, so violations are
+// found first and the transaction is rolled back instead.
+func (tx *Tx) beforeCommit() error {
+	if tx.checkFKs {
+		rows, err := tx.tx.QueryContext(tx.context(), "PRAGMA foreign_key_check")
+		if err != nil {
+			return err
+		}
+		var table string
+		violated := rows.Next()
+		if violated {
+			var rowid, parent, fkid interface{}
+			err = rows.Scan(&table, &rowid, &parent, &fkid)
+		}
+		if closeErr := rows.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		if violated {
+			return fmt.Errorf("%w: deferred check failed for table %q", ErrForeignKeyViolation, table)
+		}
+	}
+	return tx.restoreSettings()
+}
+
+// restoreSettings runs the statements undoing DisableForeignKeys, newest
+// first, and returns the first error.
+func (tx *Tx) restoreSettings() error {
+	var first error
+	for i := len(tx.restore) - 1; i >= 0; i-- {
+		if _, err := tx.tx.ExecContext(tx.context(), tx.restore[i]); err != nil && first == nil {
+			first = fmt.Errorf("relica: restoring %q: %w", tx.restore[i], err)
+		}
+	}
+	tx.restore = nil
+	return first
+}
+
+// context returns the transaction's context, or context.Background.
+func (tx *Tx) context() context.Context {
+	if tx.ctx == nil {
+		return context.Background()
+	}
+	return tx.ctx
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupForeignKeyDB(t *testing.T, enforce bool) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+	pragma := "PRAGMA foreign_keys = OFF"
+	if enforce {
+		pragma = "PRAGMA foreign_keys = ON"
+	}
+	_, err = db.ExecContext(context.Background(), pragma+`;
+		CREATE TABLE parents (id INTEGER PRIMARY KEY);
+		CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER NOT NULL REFERENCES parents (id))`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestTx_DeferConstraints(t *testing.T) {
+	db := setupForeignKeyDB(t, true)
+	ctx := context.Background()
+	insertChildFirst := func(tx *Tx) error {
+		if _, err := tx.NewQuery("INSERT INTO children (id, parent_id) VALUES (1, 1)").Execute(); err != nil {
+			return err
+		}
+		_, err := tx.NewQuery("INSERT INTO parents (id) VALUES (1)").Execute()
+		return err
+	}
+
+	err := db.Transactional(ctx, insertChildFirst)
+	assert.ErrorIs(t, err, ErrForeignKeyViolation, "checked immediately by default")
+
+	require.NoError(t, db.Transactional(ctx, func(tx *Tx) error {
+		if err := tx.DeferConstraints(); err != nil {
+			return err
+		}
+		return insertChildFirst(tx)
+	}))
+
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		if err := tx.DeferConstraints(); err != nil {
+			return err
+		}
+		_, err := tx.NewQuery("INSERT INTO children (id, parent_id) VALUES (2, 99)").Execute()
+		return err
+	})
+	assert.ErrorIs(t, err, ErrForeignKeyViolation, "deferred checks still run at commit")
+
+	var deferred int
+	require.NoError(t, db.NewQuery("PRAGMA defer_foreign_keys").Row(&deferred))
+	assert.Equal(t, 0, deferred, "deferral ends with the transaction")
+}
+
+func TestTx_DisableForeignKeys_SQLite(t *testing.T) {
+	ctx := context.Background()
+
+	err := setupForeignKeyDB(t, true).Transactional(ctx, func(tx *Tx) error {
+		return tx.DisableForeignKeys()
+	})
+	assert.ErrorContains(t, err, "SQLite cannot disable foreign keys inside a transaction")
+
+	require.NoError(t, setupForeignKeyDB(t, false).Transactional(ctx, func(tx *Tx) error {
+		return tx.DisableForeignKeys()
+	}), "nothing to do when enforcement is already off")
+}
+
+func TestTx_ConstraintToggles_Unsupported(t *testing.T) {
+	db := setupForeignKeyDB(t, false)
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	inner, err := tx.DB().Begin(context.Background())
+	require.NoError(t, err)
+	assert.ErrorContains(t, inner.DisableForeignKeys(), "outermost transaction")
+	assert.ErrorContains(t, inner.DeferConstraints(), "outermost transaction")
+	require.NoError(t, inner.Rollback())
+
+	tx.builder.db = mockDB("mysql")
+	assert.ErrorContains(t, tx.DeferConstraints(), "not supported by MySQL")
+	tx.builder.db = mockDB("postgres")
+	assert.ErrorContains(t, tx.DisableForeignKeys(), "not supported by PostgreSQL")
+}
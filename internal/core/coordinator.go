@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Multi-database transactions
+// ============================================================================
+//
+// A TxCoordinator runs one function over transactions on several databases
+// and commits them together on a best-effort basis:
+//
+//  1. PostgreSQL participants with prepared transactions enabled
+//     (max_prepared_transactions > 0) are prepared (PREPARE TRANSACTION).
+//     A failed prepare rolls everything back.
+//  2. The other participants are committed in order. If one fails, the
+//     remaining ones and the prepared transactions are rolled back, and the
+//     compensation hooks of the participants already committed are run.
+//  3. The prepared transactions are committed (COMMIT PREPARED).
+//
+// With at most one participant that cannot be prepared, the outcome is
+// atomic except for failures during step 3. MySQL XA transactions cannot be
+// driven through database/sql transactions, so MySQL participants are
+// committed in step 2 like SQLite ones.
+
+// ErrPartialCommit is returned when a coordinated transaction was committed
+// on some databases but not others, and compensation did not undo it.
+var ErrPartialCommit = errors.New("relica: coordinated transaction partially committed")
+
+// CompensateFunc undoes the committed changes of a coordinated transaction
+// participant (see CoordinatedTx.OnCompensate).
+type CompensateFunc func(ctx context.Context) error
+
+// TxCoordinator coordinates transactions over several databases.
+type TxCoordinator struct {
+	dbs []*DB
+}
+
+// NewTxCoordinator returns a coordinator for transactions spanning dbs.
+func NewTxCoordinator(dbs ...*DB) *TxCoordinator {
+	return &TxCoordinator{dbs: dbs}
+}
+
+// CoordinatedTx holds the transactions of a coordinated transaction, one
+// per database in the order given to the coordinator.
+type CoordinatedTx struct {
+	txs        []*Tx
+	compensate [][]CompensateFunc
+}
+
+// Tx returns the transaction on the i-th database of the coordinator.
+func (ct *CoordinatedTx) Tx(i int) *Tx {
+	return ct.txs[i]
+}
+
+// OnCompensate registers hook to undo the changes made on the i-th database
+// if it commits but a later participant fails to. Hooks run outside any
+// transaction, most recently registered first.
+func (ct *CoordinatedTx) OnCompensate(i int, hook CompensateFunc) {
+	ct.compensate[i] = append(ct.compensate[i], hook)
+}
+
+// Transactional begins a transaction on every database, runs fn and commits
+// the transactions: prepared transactions where available, then the others in
+// order, compensating committed participants if one fails. If fn returns an
+// error or panics, all transactions are rolled back.
+//
+// Example:
+//
+//	err := core.NewTxCoordinator(orders, billing).Transactional(ctx, func(ct *core.CoordinatedTx) error {
+//	    if err := ct.Tx(0).Model(&order).Insert(); err != nil {
+//	        return err
+//	    }
+//	    ct.OnCompensate(0, func(ctx context.Context) error {
+//	        return orders.Model(&order).Delete()
+//	    })
+//	    return ct.Tx(1).Model(&invoice).Insert()
+//	})
+func (c *TxCoordinator) Transactional(ctx context.Context, fn func(*CoordinatedTx) error) error {
+	if len(c.dbs) == 0 {
+		return errors.New("relica: coordinated transaction requires at least one database")
+	}
+
+	ct := &CoordinatedTx{
+		txs:        make([]*Tx, 0, len(c.dbs)),
+		compensate: make([][]CompensateFunc, len(c.dbs)),
+	}
+	for _, db := range c.dbs {
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			ct.rollback()
+			return err
+		}
+		ct.txs = append(ct.txs, tx)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			ct.rollback()
+			panic(p) // Re-panic after rollback
+		}
+	}()
+
+	if err := fn(ct); err != nil {
+		ct.rollback()
+		return err
+	}
+	return ct.commit(ctx)
+}
+
+// rollback rolls back every open transaction.
+func (ct *CoordinatedTx) rollback() {
+	for _, tx := range ct.txs {
+		tx.Rollback() //nolint:errcheck,gosec
+	}
+}
+
+// commit runs the commit protocol.
+func (ct *CoordinatedTx) commit(ctx context.Context) error {
+	// The outcome must be settled even if ctx is canceled meanwhile.
+	finishCtx := context.WithoutCancel(ctx)
+
+	gids := make([]string, len(ct.txs)) // prepared transaction ids; "" if not prepared
+	base := "relica_" + NewUUIDv7() + "_"
+	for i, tx := range ct.txs {
+		if !tx.canPrepare(ctx) {
+			continue
+		}
+		gid := base + strconv.Itoa(i)
+		if err := tx.prepare(ctx, gid); err != nil {
+			ct.abort(finishCtx, gids, 0)
+			return fmt.Errorf("relica: coordinated transaction: prepare participant %d: %w", i, err)
+		}
+		gids[i] = gid
+	}
+
+	for i, tx := range ct.txs {
+		if gids[i] != "" {
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			ct.abort(finishCtx, gids, i+1)
+			return ct.undoCommitted(finishCtx, gids, i, fmt.Errorf("relica: coordinated transaction: commit participant %d: %w", i, err))
+		}
+	}
+
+	var inDoubt []error
+	for i, tx := range ct.txs {
+		if gids[i] == "" {
+			continue
+		}
+		if err := tx.finishPrepared(finishCtx, gids[i], true); err != nil {
+			inDoubt = append(inDoubt, fmt.Errorf("participant %d (prepared transaction %s): %w", i, gids[i], err))
+		}
+	}
+	if len(inDoubt) > 0 {
+		return fmt.Errorf("%w: COMMIT PREPARED failed: %w", ErrPartialCommit, errors.Join(inDoubt...))
+	}
+	return nil
+}
+
+// abort rolls back the prepared transactions and the other participants
+// from index from on, which are not committed yet.
+func (ct *CoordinatedTx) abort(ctx context.Context, gids []string, from int) {
+	for i, tx := range ct.txs {
+		switch {
+		case gids[i] != "":
+			tx.finishPrepared(ctx, gids[i], false) //nolint:errcheck,gosec
+		case i >= from:
+			tx.Rollback() //nolint:errcheck,gosec
+		}
+	}
+}
+
+// undoCommitted runs the compensation hooks of the participants committed
+// before failed, in reverse order, and returns cause, wrapped in
+// ErrPartialCommit if a committed participant could not be compensated.
+func (ct *CoordinatedTx) undoCommitted(ctx context.Context, gids []string, failed int, cause error) error {
+	var errs []error
+	for i := failed - 1; i >= 0; i-- {
+		if gids[i] != "" {
+			continue // rolled back by abort
+		}
+		hooks := ct.compensate[i]
+		if len(hooks) == 0 {
+			errs = append(errs, fmt.Errorf("participant %d committed without compensation", i))
+			continue
+		}
+		for j := len(hooks) - 1; j >= 0; j-- {
+			if err := hooks[j](ctx); err != nil {
+				errs = append(errs, fmt.Errorf("participant %d compensation: %w", i, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w; %w", ErrPartialCommit, cause, errors.Join(errs...))
+	}
+	return cause
+}
+
+// canPrepare reports whether tx can be prepared for two-phase commit:
+// a PostgreSQL transaction (not a savepoint) on a server with prepared
+// transactions enabled.
+func (tx *Tx) canPrepare(ctx context.Context) bool {
+	if _, ok := tx.builder.db.dialect.(*dialects.PostgresDialect); !ok || tx.savepoint != "" {
+		return false
+	}
+	var maxPrepared int
+	err := tx.tx.QueryRowContext(ctx, "SELECT current_setting('max_prepared_transactions')::int").Scan(&maxPrepared)
+	return err == nil && maxPrepared > 0
+}
+
+// prepare runs PREPARE TRANSACTION, which detaches the transaction from the
+// session, and releases the connection. Change events are held until the
+// prepared transaction is finished.
+func (tx *Tx) prepare(ctx context.Context, gid string) error {
+	if _, err := tx.tx.ExecContext(ctx, "PREPARE TRANSACTION '"+gid+"'"); err != nil {
+		tx.Rollback() //nolint:errcheck,gosec // PostgreSQL has already rolled back
+		return classifyError(err)
+	}
+	err := tx.tx.Commit() // no transaction is left in the session
+	tx.endSpan("prepare", err)
+	if tx.finish != nil {
+		tx.finish()
+	}
+	return nil
+}
+
+// finishPrepared commits or rolls back the prepared transaction gid.
+func (tx *Tx) finishPrepared(ctx context.Context, gid string, commit bool) error {
+	stmt := "ROLLBACK PREPARED '" + gid + "'"
+	if commit {
+		stmt = "COMMIT PREPARED '" + gid + "'"
+	}
+	db := tx.builder.db
+	_, err := db.sqlDB.ExecContext(ctx, stmt)
+	db.changes.finish(tx.tx, commit && err == nil)
+	return classifyError(err)
+}
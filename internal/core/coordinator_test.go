@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCoordinatorDBs(t *testing.T, n int) []*DB {
+	t.Helper()
+	dbs := make([]*DB, n)
+	for i := range dbs {
+		db, err := Open("sqlite", ":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		db.sqlDB.SetMaxOpenConns(1)
+		_, err = db.ExecContext(context.Background(), `CREATE TABLE entries (id INTEGER PRIMARY KEY, note TEXT)`)
+		require.NoError(t, err)
+		dbs[i] = db
+	}
+	return dbs
+}
+
+func countEntries(t *testing.T, db *DB) int {
+	t.Helper()
+	var n int
+	require.NoError(t, db.Builder().Select("COUNT(*)").From("entries").Row(&n))
+	return n
+}
+
+func insertEntry(tx *Tx, note string) error {
+	_, err := tx.Builder().Insert("entries", map[string]interface{}{"note": note}).Execute()
+	return err
+}
+
+func TestTxCoordinator_Commit(t *testing.T) {
+	dbs := setupCoordinatorDBs(t, 2)
+
+	err := NewTxCoordinator(dbs...).Transactional(context.Background(), func(ct *CoordinatedTx) error {
+		require.NoError(t, insertEntry(ct.Tx(0), "order"))
+		return insertEntry(ct.Tx(1), "invoice")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, countEntries(t, dbs[0]))
+	assert.Equal(t, 1, countEntries(t, dbs[1]))
+}
+
+func TestTxCoordinator_RollbackOnError(t *testing.T) {
+	dbs := setupCoordinatorDBs(t, 2)
+	errAbort := errors.New("abort")
+
+	err := NewTxCoordinator(dbs...).Transactional(context.Background(), func(ct *CoordinatedTx) error {
+		require.NoError(t, insertEntry(ct.Tx(0), "order"))
+		require.NoError(t, insertEntry(ct.Tx(1), "invoice"))
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	assert.Zero(t, countEntries(t, dbs[0]))
+	assert.Zero(t, countEntries(t, dbs[1]))
+
+	assert.Panics(t, func() {
+		_ = NewTxCoordinator(dbs...).Transactional(context.Background(), func(ct *CoordinatedTx) error {
+			require.NoError(t, insertEntry(ct.Tx(0), "order"))
+			panic("boom")
+		})
+	})
+	assert.Zero(t, countEntries(t, dbs[0]), "a panic rolls back every participant")
+}
+
+func TestTxCoordinator_CommitFailureCompensates(t *testing.T) {
+	dbs := setupCoordinatorDBs(t, 3)
+	var compensated []string
+
+	err := NewTxCoordinator(dbs...).Transactional(context.Background(), func(ct *CoordinatedTx) error {
+		require.NoError(t, insertEntry(ct.Tx(0), "order"))
+		ct.OnCompensate(0, func(ctx context.Context) error {
+			compensated = append(compensated, "first")
+			_, err := dbs[0].Builder().Delete("entries").Where(Eq("note", "order")).Execute()
+			return err
+		})
+		ct.OnCompensate(0, func(ctx context.Context) error {
+			compensated = append(compensated, "second")
+			return nil
+		})
+		require.NoError(t, insertEntry(ct.Tx(2), "audit"))
+		// Make the second participant's commit fail.
+		return ct.Tx(1).tx.Rollback()
+	})
+	require.ErrorIs(t, err, sql.ErrTxDone)
+	assert.NotErrorIs(t, err, ErrPartialCommit, "compensation undid the committed participant")
+	assert.ErrorContains(t, err, "relica: coordinated transaction: commit participant 1")
+	assert.Equal(t, []string{"second", "first"}, compensated, "hooks run most recent first")
+	assert.Zero(t, countEntries(t, dbs[0]))
+	assert.Zero(t, countEntries(t, dbs[2]), "participants after the failure are rolled back")
+}
+
+func TestTxCoordinator_PartialCommit(t *testing.T) {
+	dbs := setupCoordinatorDBs(t, 2)
+
+	err := NewTxCoordinator(dbs...).Transactional(context.Background(), func(ct *CoordinatedTx) error {
+		require.NoError(t, insertEntry(ct.Tx(0), "order"))
+		return ct.Tx(1).tx.Rollback()
+	})
+	require.ErrorIs(t, err, ErrPartialCommit)
+	assert.ErrorContains(t, err, "participant 0 committed without compensation")
+	assert.Equal(t, 1, countEntries(t, dbs[0]))
+
+	errUndo := errors.New("undo failed")
+	err = NewTxCoordinator(dbs...).Transactional(context.Background(), func(ct *CoordinatedTx) error {
+		ct.OnCompensate(0, func(context.Context) error { return errUndo })
+		return ct.Tx(1).tx.Rollback()
+	})
+	require.ErrorIs(t, err, ErrPartialCommit)
+	assert.ErrorIs(t, err, errUndo)
+}
+
+func TestTxCoordinator_NoDatabases(t *testing.T) {
+	err := NewTxCoordinator().Transactional(context.Background(), func(*CoordinatedTx) error { return nil })
+	assert.ErrorContains(t, err, "requires at least one database")
+}
@@ -29,7 +29,8 @@ func TestHavingPlaceholderRenumber_MultiArgClauses(t *testing.T) {
 		Having("COUNT(*) > ? AND COUNT(*) < ?", 5, 100).
 		Having("SUM(amount) > ?", 1000)
 
-	sql, params := sq.buildSQL(dialects.GetDialect("postgres"))
+	sql, params, err := sq.buildSQL(dialects.GetDialect("postgres"))
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, "$1") // WHERE arg
 	assert.Contains(t, sql, "$2") // first HAVING, first arg
@@ -64,7 +65,8 @@ func TestHavingPlaceholderRenumber_SingleArgEach(t *testing.T) {
 		Having("COUNT(*) > ?", 5).
 		Having("COUNT(*) < ?", 100)
 
-	sql, params := sq.buildSQL(dialects.GetDialect("postgres"))
+	sql, params, err := sq.buildSQL(dialects.GetDialect("postgres"))
+	require.NoError(t, err)
 
 	// No WHERE, so HAVING args start at $1.
 	havingStart := strings.Index(sql, "HAVING")
@@ -347,7 +349,8 @@ func TestFrom_SchemaQualifiedTable(t *testing.T) {
 	db := mockDB("postgres")
 	qb := &QueryBuilder{db: db}
 
-	sql, _ := qb.Select("id", "name").From("public.users").buildSQL(db.dialect)
+	sql, _, err := qb.Select("id", "name").From("public.users").buildSQL(db.dialect)
+	require.NoError(t, err)
 
 	// Should produce "public"."users" not "public.users"
 	assert.Contains(t, sql, `"public"."users"`)
@@ -360,7 +363,8 @@ func TestFrom_SchemaQualifiedTableWithAlias(t *testing.T) {
 	db := mockDB("postgres")
 	qb := &QueryBuilder{db: db}
 
-	sql, _ := qb.Select("u.id", "u.name").From("public.users u").buildSQL(db.dialect)
+	sql, _, err := qb.Select("u.id", "u.name").From("public.users u").buildSQL(db.dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `"public"."users" AS "u"`)
 	assert.NotContains(t, sql, `"public.users u"`)
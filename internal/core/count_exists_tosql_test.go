@@ -276,7 +276,8 @@ func TestSelectQuery_Count_SQL_Postgres(t *testing.T) {
 		params:  []interface{}{1},
 	}
 
-	sql, params := countQuery.buildSQL(db.dialect)
+	sql, params, err := countQuery.buildSQL(db.dialect)
+	require.NoError(t, err)
 
 	assert.Equal(t, `SELECT COUNT(*) FROM "users" WHERE "status"=$1`, sql)
 	assert.Equal(t, []interface{}{1}, params)
@@ -295,7 +296,8 @@ func TestSelectQuery_Count_SQL_MySQL(t *testing.T) {
 		params:  []interface{}{42},
 	}
 
-	sql, params := countQuery.buildSQL(db.dialect)
+	sql, params, err := countQuery.buildSQL(db.dialect)
+	require.NoError(t, err)
 
 	assert.Equal(t, "SELECT COUNT(*) FROM `orders` WHERE `user_id`=?", sql)
 	assert.Equal(t, []interface{}{42}, params)
@@ -313,21 +315,8 @@ func TestSelectQuery_Count_IgnoresOriginalColumns(t *testing.T) {
 	// Verify the original query has columns
 	assert.Equal(t, []string{"id", "name", "email"}, sq.columns)
 
-	// Build the count query that Count() would construct — same as internal logic
-	countQuery := &SelectQuery{
-		builder:       sq.builder,
-		columns:       []string{"COUNT(*)"},
-		fromSrc:       sq.fromSrc,
-		table:         sq.table,
-		joins:         sq.joins,
-		where:         sq.where,
-		params:        sq.params,
-		groupBy:       sq.groupBy,
-		havingClauses: sq.havingClauses,
-		ctx:           sq.ctx,
-	}
-
-	sql, _ := countQuery.buildSQL(db.dialect)
+	sql, _, err := sq.countQuery().buildSQL(db.dialect)
+	require.NoError(t, err)
 	assert.Contains(t, sql, "SELECT COUNT(*)")
 	assert.NotContains(t, sql, `"id"`)
 	assert.NotContains(t, sql, `"name"`)
@@ -346,7 +335,8 @@ func TestSelectQuery_Count_SQL_WithGroupBy(t *testing.T) {
 		groupBy: []string{"user_id"},
 	}
 
-	sql, _ := countQuery.buildSQL(db.dialect)
+	sql, _, err := countQuery.buildSQL(db.dialect)
+	require.NoError(t, err)
 	assert.Contains(t, sql, "SELECT COUNT(*)")
 	assert.Contains(t, sql, `GROUP BY "user_id"`)
 }
@@ -361,17 +351,8 @@ func TestSelectQuery_Exists_SQL_Postgres(t *testing.T) {
 
 	sq := qb.Select().From("users").Where(Eq("email", "alice@example.com"))
 
-	// Use selectExprs with raw "1" (same as Exists() implementation)
-	innerQuery := &SelectQuery{
-		builder:     sq.builder,
-		selectExprs: []RawExp{{SQL: "1"}},
-		fromSrc:     sq.fromSrc,
-		table:       sq.table,
-		where:       sq.where,
-		params:      sq.params,
-	}
-
-	innerSQL, innerParams := innerQuery.buildSQL(db.dialect)
+	innerSQL, innerParams, err := sq.existsQuery().buildSQL(db.dialect)
+	require.NoError(t, err)
 	existsSQL := "SELECT EXISTS(" + innerSQL + ")"
 
 	assert.Equal(t, `SELECT EXISTS(SELECT 1 FROM "users" WHERE "email" = $1)`, existsSQL)
@@ -384,16 +365,8 @@ func TestSelectQuery_Exists_SQL_MySQL(t *testing.T) {
 
 	sq := qb.Select().From("users").Where(Eq("id", 7))
 
-	innerQuery := &SelectQuery{
-		builder:     sq.builder,
-		selectExprs: []RawExp{{SQL: "1"}},
-		fromSrc:     sq.fromSrc,
-		table:       sq.table,
-		where:       sq.where,
-		params:      sq.params,
-	}
-
-	innerSQL, innerParams := innerQuery.buildSQL(db.dialect)
+	innerSQL, innerParams, err := sq.existsQuery().buildSQL(db.dialect)
+	require.NoError(t, err)
 	existsSQL := "SELECT EXISTS(" + innerSQL + ")"
 
 	assert.Equal(t, "SELECT EXISTS(SELECT 1 FROM `users` WHERE `id` = ?)", existsSQL)
@@ -409,17 +382,8 @@ func TestSelectQuery_Exists_SQL_WithJoin(t *testing.T) {
 		InnerJoin("orders o", "o.user_id = u.id").
 		Where(Eq("status", "active"))
 
-	innerQuery := &SelectQuery{
-		builder:     sq.builder,
-		selectExprs: []RawExp{{SQL: "1"}},
-		fromSrc:     sq.fromSrc,
-		table:       sq.table,
-		joins:       sq.joins,
-		where:       sq.where,
-		params:      sq.params,
-	}
-
-	innerSQL, _ := innerQuery.buildSQL(db.dialect)
+	innerSQL, _, err := sq.existsQuery().buildSQL(db.dialect)
+	require.NoError(t, err)
 	existsSQL := "SELECT EXISTS(" + innerSQL + ")"
 
 	assert.Contains(t, existsSQL, "SELECT EXISTS(")
@@ -433,16 +397,8 @@ func TestSelectQuery_Exists_SQL_SQLite(t *testing.T) {
 
 	sq := qb.Select().From("products").Where(Eq("sku", "ABC-123"))
 
-	innerQuery := &SelectQuery{
-		builder:     sq.builder,
-		selectExprs: []RawExp{{SQL: "1"}},
-		fromSrc:     sq.fromSrc,
-		table:       sq.table,
-		where:       sq.where,
-		params:      sq.params,
-	}
-
-	innerSQL, innerParams := innerQuery.buildSQL(db.dialect)
+	innerSQL, innerParams, err := sq.existsQuery().buildSQL(db.dialect)
+	require.NoError(t, err)
 	existsSQL := "SELECT EXISTS(" + innerSQL + ")"
 
 	assert.Equal(t, `SELECT EXISTS(SELECT 1 FROM "products" WHERE "sku" = ?)`, existsSQL)
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -286,9 +287,9 @@ func TestWithRecursive_UnionAll(t *testing.T) {
 	require.NotNil(t, query)
 
 	assert.Contains(t, query.sql, `WITH RECURSIVE "numbers" AS`)
-	assert.Contains(t, query.sql, `SELECT "1" AS "n"`)
+	assert.Contains(t, query.sql, `SELECT 1 AS "n"`)
 	assert.Contains(t, query.sql, `UNION ALL`)
-	assert.Contains(t, query.sql, `SELECT "n + 1" FROM "numbers" WHERE n < $1`)
+	assert.Contains(t, query.sql, `SELECT n + 1 FROM "numbers" WHERE n < $1`)
 	assert.Len(t, query.params, 1)
 	assert.Equal(t, 10, query.params[0])
 }
@@ -545,3 +546,178 @@ func TestCTE_ComplexRecursive(t *testing.T) {
 	assert.Equal(t, 10, query.params[1])
 	assert.Equal(t, 1, query.params[2])
 }
+
+// ============================================================================
+// Materialization Hints
+// ============================================================================
+
+// TestWith_MaterializedHints tests MATERIALIZED / NOT MATERIALIZED per dialect
+func TestWith_MaterializedHints(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialectName string
+		opt         CTEOption
+		wantPrefix  string
+	}{
+		{"PostgreSQL materialized", "postgres", Materialized(), `WITH "totals" AS MATERIALIZED (SELECT`},
+		{"PostgreSQL not materialized", "postgres", NotMaterialized(), `WITH "totals" AS NOT MATERIALIZED (SELECT`},
+		{"SQLite materialized", "sqlite", Materialized(), `WITH "totals" AS MATERIALIZED (SELECT`},
+		{"MySQL drops hint", "mysql", Materialized(), "WITH `totals` AS (SELECT"},
+		{"No hint", "postgres", nil, `WITH "totals" AS (SELECT`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := mockDB(tt.dialectName)
+			qb := &QueryBuilder{db: db}
+
+			cte := qb.Select("user_id", "SUM(total) as total").From("orders").Where("total > ?", 10).GroupBy("user_id")
+			query := qb.Select("*").With("totals", cte, tt.opt).From("totals").Build()
+
+			require.NoError(t, query.prepErr)
+			assert.True(t, strings.HasPrefix(query.sql, tt.wantPrefix), query.sql)
+			assert.Equal(t, []interface{}{10}, query.params)
+		})
+	}
+}
+
+// TestWithRecursive_Materialized tests hints on recursive CTEs
+func TestWithRecursive_Materialized(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	anchor := qb.Select("id").From("nodes").Where("parent_id IS NULL")
+	recursive := qb.Select("n.id").From("nodes n").InnerJoin("tree t", "n.parent_id = t.id")
+
+	query := qb.Select("*").
+		WithRecursive("tree", anchor.UnionAll(recursive), NotMaterialized()).
+		From("tree").
+		Build()
+
+	require.NoError(t, query.prepErr)
+	assert.Contains(t, query.sql, `WITH RECURSIVE "tree" AS NOT MATERIALIZED (`)
+}
+
+// ============================================================================
+// Data-modifying CTEs
+// ============================================================================
+
+// TestWith_DataModifyingDelete tests moving rows with DELETE ... RETURNING inside WITH
+func TestWith_DataModifyingDelete(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	moved := qb.Delete("orders").Where("created_at < ?", "2024-01-01").Returning("*")
+
+	q := qb.With("moved", moved).
+		InsertFromSelect("orders_archive", []string{"id", "total"},
+			qb.Select("id", "total").From("moved").Where("total > ?", 10)).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t,
+		`WITH "moved" AS (DELETE FROM "orders" WHERE created_at < $1 RETURNING *) `+
+			`INSERT INTO "orders_archive" ("id", "total") SELECT "id", "total" FROM "moved" WHERE total > $2`,
+		q.sql)
+	assert.Equal(t, []interface{}{"2024-01-01", 10}, q.params)
+}
+
+// TestWith_DataModifyingUpdateInSelect tests UPDATE ... RETURNING as a CTE of a SELECT
+func TestWith_DataModifyingUpdateInSelect(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	updated := qb.Update("users").
+		Set(map[string]interface{}{"status": "inactive"}).
+		Where("last_login < ?", "2023-01-01").
+		Returning("id", "email")
+
+	q := qb.Select("*").
+		With("deactivated", updated).
+		From("deactivated").
+		Where("email LIKE ?", "%@example.com").
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t,
+		`WITH "deactivated" AS (UPDATE "users" SET "status" = $1 WHERE last_login < $2 RETURNING "id", "email") `+
+			`SELECT * FROM "deactivated" WHERE email LIKE $3`,
+		q.sql)
+	assert.Equal(t, []interface{}{"inactive", "2023-01-01", "%@example.com"}, q.params)
+}
+
+// TestWith_BuilderCTEOnUpdate tests builder-level CTEs prepended to UPDATE and DELETE
+func TestWith_BuilderCTEOnUpdate(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	stale := qb.Select("id").From("users").Where("last_login < ?", "2023-01-01")
+	withStale := qb.With("stale", stale)
+
+	q := withStale.Update("users").
+		Set(map[string]interface{}{"status": 0}).
+		Where("id IN (SELECT id FROM stale) AND role = ?", "user").
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t,
+		`WITH "stale" AS (SELECT "id" FROM "users" WHERE last_login < $1) `+
+			`UPDATE "users" SET "status" = $2 WHERE id IN (SELECT id FROM stale) AND role = $3`,
+		q.sql)
+	assert.Equal(t, []interface{}{"2023-01-01", 0, "user"}, q.params)
+
+	q = withStale.Delete("sessions").Where("user_id IN (SELECT id FROM stale)").Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `WITH "stale" AS (SELECT "id" FROM "users" WHERE last_login < $1) DELETE FROM "sessions" WHERE user_id IN (SELECT id FROM stale)`, q.sql)
+
+	// The original builder is not affected.
+	q = qb.Delete("sessions").Build()
+	assert.Equal(t, `DELETE FROM "sessions"`, q.sql)
+}
+
+// TestWith_MultipleCTEsRenumbered tests that each CTE body continues placeholder numbering
+func TestWith_MultipleCTEsRenumbered(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("*").
+		With("a", qb.Select("id").From("users").Where("age > ?", 18)).
+		With("b", qb.Select("id").From("orders").Where("total > ?", 100)).
+		From("a").
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Contains(t, q.sql, `WHERE age > $1`)
+	assert.Contains(t, q.sql, `WHERE total > $2`)
+	assert.Equal(t, []interface{}{18, 100}, q.params)
+}
+
+// TestWith_DataModifyingUnsupportedDialect tests that non-PostgreSQL dialects reject DML CTEs
+func TestWith_DataModifyingUnsupportedDialect(t *testing.T) {
+	for _, name := range []string{"mysql", "sqlite"} {
+		t.Run(name, func(t *testing.T) {
+			db := mockDB(name)
+			qb := &QueryBuilder{db: db}
+
+			moved := qb.Delete("orders").Where("id = ?", 1)
+			q := qb.Select("*").With("moved", moved).From("moved").Build()
+
+			require.Error(t, q.prepErr)
+			assert.Contains(t, q.prepErr.Error(), "only supported by PostgreSQL")
+		})
+	}
+}
+
+// TestWith_NilDataModifyingQuery tests typed nil CTE bodies
+func TestWith_NilDataModifyingQuery(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	var dq *DeleteQuery
+	q := qb.Select("*").With("moved", dq).From("moved").Build()
+	require.Error(t, q.prepErr)
+	assert.Contains(t, q.prepErr.Error(), "non-nil CTE query")
+
+	q = qb.With("", qb.Select("id").From("users")).Delete("users").Build()
+	require.Error(t, q.prepErr)
+	assert.Contains(t, q.prepErr.Error(), "non-empty CTE name")
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// cursorSeq numbers the server-side cursors declared by WithCursor queries.
+var cursorSeq atomic.Uint64
+
+// WithCursor makes All and Each read the result through a server-side cursor,
+// fetchSize rows per round trip, so the database session never has to hold
+// the whole result set at once.
+//
+// On PostgreSQL the query is run as DECLARE ... NO SCROLL CURSOR followed by
+// repeated FETCH FORWARD fetchSize statements. Cursors only exist inside a
+// transaction: queries built on a Tx use it, other queries run in a
+// read-only transaction that is committed when all rows have been read.
+//
+// MySQL and SQLite drivers already stream rows to database/sql as they are
+// read, so WithCursor has no effect there; driver-level buffering, where a
+// driver offers it, is configured in the DSN.
+//
+// Combine WithCursor with Each to keep client memory bounded as well:
+//
+//	var ev Event
+//	err := db.Select().From("events").WithCursor(1000).Each(&ev, func() error {
+//	    return enc.Encode(ev)
+//	})
+func (sq *SelectQuery) WithCursor(fetchSize int) *SelectQuery {
+	sq = sq.own()
+	if fetchSize <= 0 {
+		sq.buildErr = fmt.Errorf("relica: WithCursor fetch size must be positive, got %d", fetchSize)
+		return sq
+	}
+	sq.cursorFetch = fetchSize
+	return sq
+}
+
+// Each scans the matching rows one at a time into dest (a pointer to a struct
+// or a *NullStringMap) and calls fn after each row, without collecting the
+// rows in memory. dest is overwritten by every row; copy it if fn keeps it.
+// An error returned by fn stops the iteration and is returned by Each.
+//
+// Example:
+//
+//	var u User
+//	err := db.Select().From("users").Each(&u, func() error {
+//	    return w.Write([]string{u.Name, u.Email})
+//	})
+func (sq *SelectQuery) Each(dest interface{}, fn func() error) error {
+	return sq.All(&eachSink{dest: dest, fn: fn})
+}
+
+// WithCursor reads the result through a server-side cursor. See SelectQuery.WithCursor.
+func (q *TypedQuery[T]) WithCursor(fetchSize int) *TypedQuery[T] {
+	q.sq = q.sq.WithCursor(fetchSize)
+	return q
+}
+
+// Each calls fn with every matching row in turn, without collecting the rows
+// in memory. An error returned by fn stops the iteration and is returned by Each.
+//
+// Example:
+//
+//	err := relica.Q[Event](db).WithCursor(1000).Each(ctx, func(ev Event) error {
+//	    return enc.Encode(ev)
+//	})
+func (q *TypedQuery[T]) Each(ctx context.Context, fn func(T) error) error {
+	if q.mapper != nil {
+		sink := &mapSink[T]{fn: q.mapper}
+		return q.sq.WithContext(ctx).All(&eachSink{dest: sink, fn: func() error {
+			row := sink.out[0]
+			sink.out = sink.out[:0]
+			return fn(row)
+		}})
+	}
+	var row T
+	return q.sq.WithContext(ctx).Each(&row, func() error {
+		return fn(row)
+	})
+}
+
+// eachSink scans each row into dest and calls fn.
+type eachSink struct {
+	dest interface{}
+	fn   func() error
+}
+
+func (s *eachSink) scanRow(rows *sql.Rows) error {
+	var err error
+	switch dest := s.dest.(type) {
+	case rowSink:
+		err = dest.scanRow(rows)
+	case *NullStringMap:
+		err = globalScanner.scanMapRow(rows, dest)
+	default:
+		err = globalScanner.scanRow(rows, dest)
+	}
+	if err != nil {
+		return err
+	}
+	return s.fn()
+}
+
+// countSink counts the rows passed to a rowSink.
+type countSink struct {
+	rowSink
+	n int
+}
+
+func (s *countSink) scanRow(rows *sql.Rows) error {
+	s.n++
+	return s.rowSink.scanRow(rows)
+}
+
+func (s *countSink) setColumns(cols []*sql.ColumnType) error {
+	if cs, ok := s.rowSink.(columnSink); ok {
+		return cs.setColumns(cols)
+	}
+	return nil
+}
+
+// usesCursor reports whether All should read the result through a cursor.
+func (sq *SelectQuery) usesCursor() bool {
+	if sq.cursorFetch == 0 {
+		return false
+	}
+	_, ok := sq.builder.db.dialect.(*dialects.PostgresDialect)
+	return ok
+}
+
+// allCursor executes the query through a PostgreSQL cursor into dest
+// (a pointer to a slice or a rowSink).
+func (sq *SelectQuery) allCursor(dest interface{}) error {
+	q := sq.Build()
+	if q.prepErr != nil {
+		return q.prepErr
+	}
+	if _, ok := dest.(rowSink); !ok {
+		destVal := reflect.ValueOf(dest)
+		if destVal.Kind() != reflect.Pointer || destVal.Elem().Kind() != reflect.Slice {
+			return fmt.Errorf("relica: destination must be a pointer to a slice, got %T", dest)
+		}
+	}
+	if q.tx != nil {
+		return q.fetchCursor(dest, sq.cursorFetch)
+	}
+
+	if err := q.db.drain.enter(); err != nil {
+		return err
+	}
+	defer q.db.drain.leave()
+	var tx *sql.Tx
+	var err error
+	if q.db.pinned != nil {
+		tx, err = q.db.pinned.BeginTx(q.getContext(), &sql.TxOptions{ReadOnly: true})
+	} else {
+		tx, err = q.db.sqlDB.BeginTx(q.getContext(), &sql.TxOptions{ReadOnly: true})
+	}
+	if err != nil {
+		return err
+	}
+	q.tx = tx
+	if err := q.fetchCursor(dest, sq.cursorFetch); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// fetchCursor declares a cursor for q inside q.tx and fetches it into dest
+// fetchSize rows at a time until a short batch signals the end of the result.
+func (q *Query) fetchCursor(dest interface{}, fetchSize int) error {
+	name := "relica_cursor_" + strconv.FormatUint(cursorSeq.Add(1), 10)
+	if _, err := q.derive("DECLARE "+name+" NO SCROLL CURSOR FOR "+q.sql, q.params).Execute(); err != nil {
+		return err
+	}
+
+	fetchSQL := "FETCH FORWARD " + strconv.Itoa(fetchSize) + " FROM " + name
+	for {
+		n, err := q.derive(fetchSQL, nil).fetchInto(dest)
+		if err != nil {
+			return err
+		}
+		if n < fetchSize {
+			break
+		}
+	}
+
+	_, err := q.derive("CLOSE "+name, nil).Execute()
+	return err
+}
+
+// fetchInto runs q and appends its rows to dest, returning the number of rows read.
+func (q *Query) fetchInto(dest interface{}) (int, error) {
+	if sink, ok := dest.(rowSink); ok {
+		counter := &countSink{rowSink: sink}
+		err := q.All(counter)
+		return counter.n, err
+	}
+
+	destVal := reflect.ValueOf(dest).Elem()
+	part := reflect.New(destVal.Type())
+	if err := q.All(part.Interface()); err != nil {
+		return 0, err
+	}
+	destVal.Set(reflect.AppendSlice(destVal, part.Elem()))
+	return part.Elem().Len(), nil
+}
+
+// derive returns a query with the given SQL that shares q's connection, context and tag.
+func (q *Query) derive(query string, params []interface{}) *Query {
+	return &Query{
+		sql:    query,
+		params: params,
+		db:     q.db,
+		tx:     q.tx,
+		tag:    q.tag,
+		ctx:    q.ctx,
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectQuery_Each(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 6, &queries)
+
+	var p chunkedProduct
+	var titles []string
+	err := db.Builder().Select().From("products").OrderBy("id").Each(&p, func() error {
+		titles = append(titles, p.Title)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p1", "p2", "p3", "p4", "p5", "p6"}, titles)
+
+	var row NullStringMap
+	var prices []string
+	err = db.Builder().Select("price").From("products").Where("id <= ?", 2).OrderBy("id").Each(&row, func() error {
+		prices = append(prices, row["price"].String)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "0"}, prices)
+
+	errStop := errors.New("stop")
+	var calls int
+	err = db.Builder().Select().From("products").Each(&p, func() error {
+		calls++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestTypedQuery_Each(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 4, &queries)
+	ctx := context.Background()
+
+	var ids []int64
+	err := NewTypedQuery[chunkedProduct](db.Builder()).OrderBy("id").Each(ctx, func(p chunkedProduct) error {
+		ids = append(ids, p.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3, 4}, ids)
+
+	var titles []string
+	err = NewTypedQuery[chunkedProduct](db.Builder()).OrderBy("id").
+		Map(func(raw RowData) (chunkedProduct, error) {
+			return chunkedProduct{Title: "#" + raw.String("title")}, nil
+		}).
+		Each(ctx, func(p chunkedProduct) error {
+			titles = append(titles, p.Title)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"#p1", "#p2", "#p3", "#p4"}, titles)
+}
+
+func TestSelectQuery_WithCursor(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 5, &queries)
+	queries = 0
+
+	// Dialects other than PostgreSQL stream rows already; the query runs unchanged.
+	var products []chunkedProduct
+	require.NoError(t, db.Builder().Select().From("products").WithCursor(2).All(&products))
+	assert.Len(t, products, 5)
+	assert.Equal(t, 1, queries)
+
+	err := db.Builder().Select().From("products").WithCursor(0).All(&products)
+	assert.ErrorContains(t, err, "WithCursor fetch size must be positive")
+
+	assert.True(t, mockDB("postgres").Builder().Select().From("products").WithCursor(10).usesCursor())
+	assert.False(t, mockDB("postgres").Builder().Select().From("products").usesCursor())
+	assert.False(t, mockDB("mysql").Builder().Select().From("products").WithCursor(10).usesCursor())
+}
+
+func TestQuery_FetchInto(t *testing.T) {
+	var queries int
+	db := setupChunkedTestDB(t, 5, &queries)
+
+	// Successive fetches append to the destination and report the batch size.
+	var products []chunkedProduct
+	q := db.NewQuery("SELECT * FROM products WHERE id <= 3")
+	n, err := q.derive(q.sql, nil).fetchInto(&products)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = q.derive("SELECT * FROM products WHERE id > 3", nil).fetchInto(&products)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, products, 5)
+
+	sink := &mapSink[string]{fn: func(raw RowData) (string, error) { return raw.String("title"), nil }}
+	n, err = q.derive(q.sql, nil).fetchInto(sink)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{"p1", "p2", "p3"}, sink.out)
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coregx/relica/internal/cache"
@@ -26,22 +27,64 @@ type DB struct {
 	sqlDB         *sql.DB
 	driverName    string
 	stmtCache     *cache.StmtCache
+	stmtShards    int // statement cache shards (WithStmtCacheShards, 0 = by capacity)
 	dialect       dialects.Dialect
 	logger        logger.Logger       // Structured logger for query logging
 	queryHook     QueryHook           // Query hook for logging/metrics/tracing
+	logExpanded   bool                // log expanded SQL of failed queries (WithExpandedSQLLogging)
+	utcTimes      bool                // convert time parameters to UTC (WithUTCTimes)
+	emptySlices   bool                // nil All/Column destinations become empty slices (WithEmptySlices)
+	columnNulls   ColumnNulls         // NULL handling of Column for element types without NULL (WithColumnNulls)
+	safeWrites    bool                // UPDATE/DELETE without WHERE fail (WithSafeWrites)
+	readOnly      bool                // only SELECT statements are allowed (WithReadOnly)
+	boundLimits   bool                // SELECT LIMIT/OFFSET values are sent as parameters (WithBoundLimits)
+	maxRuntime    time.Duration       // queries are canceled after this runtime (WithQueryWatchdog, 0 = disabled)
+	modelValidate ModelValidator      // validates models before Model writes (nil = Validate method only)
 	sanitizer     *logger.Sanitizer   // Sanitizes sensitive data in logs
 	optimizer     Optimizer           // Query optimizer (nil = disabled)
+	analyzed      *analyzedQueries    // Queries recently analyzed by the optimizer
 	healthChecker *healthChecker      // Health checker for connection monitoring (nil = disabled)
 	validator     *security.Validator // SQL injection validator (nil = disabled)
 	auditor       *security.Auditor   // Audit logger for security compliance (nil = disabled)
+	auditTable    *auditTable         // Audit log table writer (nil = disabled, see WithAuditTable)
+	commenter     *sqlCommenter       // sqlcommenter tags appended to statements (nil = disabled)
+	dsn           string              // DSN used to open named pools ("" for WrapDB)
+	pools         *poolRegistry       // Named connection pools (see Pool)
+	root          *DB                 // Primary DB for pool views (nil for the primary DB)
+	poolErr       error               // Unknown pool selected via OnPool; returned on execution
+	limiter       *queryLimiter       // Concurrency limiter (nil = unlimited)
+	tagStats      *tagStatsRegistry   // Per-tag statistics (see QueryStats)
+	queries       *queryRegistry      // Named queries (see RegisterQuery)
+	catalog       *schemaCatalog      // Table columns checked in strict schema mode (nil = disabled)
+	linter        *queryLinter        // Query lint checks (nil = disabled)
+	bound         *boundTx            // Transaction all queries run in (see Tx.DB); nil for regular DBs
+	pinned        *sql.Conn           // Connection all queries run on (see Conn); nil for regular DBs
+	changes       *changeFeed         // Change data capture sink (nil = disabled)
+	history       map[string][]string // History tables by table, with their key columns (see WithHistory)
+	replicas      *replicaSet         // Read replicas (see WithReplicas); nil = none
+	session       *sessionSettings    // Settings applied to new connections (see WithSessionSettings)
+	snowflake     *Snowflake          // Snowflake ID generator (nil = node 0, see WithSnowflakeNode)
+	nplusone      *nplusOneDetector   // N+1 query detection (nil = disabled)
+	optStore      *optimizerStore     // Persistent optimizer statistics (nil = disabled)
+	retry         *RetryPolicy        // Statement retry on transient errors (nil = disabled)
+	drain         *drainGroup         // In-flight work tracking for Shutdown (shared by copies)
+	settings      *runtimeSettings    // Settings changeable with Reconfigure (shared by copies)
+	tracing       *tracing            // Query and transaction spans (nil = disabled)
+	metrics       *queryMetrics       // Query metrics instruments (nil = disabled)
 	ctx           context.Context
 }
 
 // Tx represents a database transaction.
 type Tx struct {
-	tx      *sql.Tx
-	builder *QueryBuilder
-	ctx     context.Context
+	tx        *sql.Tx
+	builder   *QueryBuilder
+	ctx       context.Context
+	savepoint string   // savepoint name for nested transactions of a bound DB
+	done      bool     // savepoint released or rolled back
+	finish    func()   // ends the transaction's in-flight tracking (see Shutdown); nil for savepoints
+	span      Span     // transaction span (see WithTracer); nil when not traced or ended
+	restore   []string // statements undoing DisableForeignKeys before Commit/Rollback
+	checkFKs  bool     // verify deferred SQLite foreign keys before Commit (see DeferConstraints)
 }
 
 // TxOptions represents transaction options including isolation level.
@@ -53,74 +96,108 @@ type TxOptions struct {
 }
 
 // Option is a functional option for configuring DB.
-type Option func(*DB)
+type Option interface {
+	apply(db *DB)
+}
+
+// optionFunc is an Option applied by calling a function. It is only applied
+// when a DB is opened, never by DB.Reconfigure.
+type optionFunc func(*DB)
+
+func (f optionFunc) apply(db *DB) { f(db) }
 
 // WithMaxOpenConns sets the maximum number of open connections.
 func WithMaxOpenConns(n int) Option {
-	return func(db *DB) {
+	return runtimeOption(func(db *DB) {
 		db.sqlDB.SetMaxOpenConns(n)
-	}
+	})
 }
 
 // WithMaxIdleConns sets the maximum number of idle connections.
 func WithMaxIdleConns(n int) Option {
-	return func(db *DB) {
+	return runtimeOption(func(db *DB) {
 		db.sqlDB.SetMaxIdleConns(n)
-	}
+	})
 }
 
 // WithConnMaxLifetime sets the maximum amount of time a connection may be reused.
 // Expired connections may be closed lazily before reuse.
 // If duration <= 0, connections are not closed due to a connection's age.
 func WithConnMaxLifetime(d time.Duration) Option {
-	return func(db *DB) {
+	return runtimeOption(func(db *DB) {
 		db.sqlDB.SetConnMaxLifetime(d)
-	}
+	})
 }
 
 // WithConnMaxIdleTime sets the maximum amount of time a connection may be idle.
 // Expired connections may be closed lazily before reuse.
 // If duration <= 0, connections are not closed due to a connection's idle time.
 func WithConnMaxIdleTime(d time.Duration) Option {
-	return func(db *DB) {
+	return runtimeOption(func(db *DB) {
 		db.sqlDB.SetConnMaxIdleTime(d)
-	}
+	})
 }
 
 // WithHealthCheck enables periodic health checks on database connections.
 // The health checker pings the database at the specified interval to detect dead connections.
-// If interval <= 0, health checks are disabled.
-func WithHealthCheck(interval time.Duration) Option {
-	return func(db *DB) {
+// Options set a probe query, a latency threshold for the degraded state and
+// a callback on state changes. If interval <= 0, health checks are disabled.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithHealthCheck(10*time.Second,
+//	    relica.HealthCheckQuery("SELECT 1 FROM accounts LIMIT 1"),
+//	    relica.HealthCheckDegradedAfter(200*time.Millisecond),
+//	    relica.HealthCheckOnChange(func(c relica.HealthChange) {
+//	        ready.Store(c.To != relica.HealthStateUnhealthy)
+//	    })))
+func WithHealthCheck(interval time.Duration, opts ...HealthCheckOption) Option {
+	return optionFunc(func(db *DB) {
 		if interval > 0 {
 			db.healthChecker = newHealthChecker(db.sqlDB, db.logger, interval)
+			for _, opt := range opts {
+				opt(db.healthChecker)
+			}
 			db.healthChecker.start()
 		}
-	}
+	})
 }
 
 // WithStmtCacheCapacity sets the prepared statement cache capacity.
 func WithStmtCacheCapacity(capacity int) Option {
-	return func(db *DB) {
-		db.stmtCache = cache.NewStmtCacheWithCapacity(capacity)
-	}
+	return optionFunc(func(db *DB) {
+		db.stmtCache = cache.NewStmtCacheWithShards(capacity, db.stmtShards)
+	})
+}
+
+// WithStmtCacheShards splits the prepared statement cache into n shards, each
+// with its own lock, to reduce lock contention under high concurrency. n is
+// rounded up to a power of two. By default the cache has up to 16 shards of at
+// least 64 statements; statements are evicted LRU within their shard.
+func WithStmtCacheShards(n int) Option {
+	return optionFunc(func(db *DB) {
+		db.stmtShards = n
+		db.stmtCache = cache.NewStmtCacheWithShards(db.stmtCache.Stats().Capacity, n)
+	})
 }
 
 // WithOptimizer enables query optimization analysis with the given optimizer.
 // The optimizer will analyze query execution plans and provide suggestions for improvements.
+// Each query is analyzed once per fingerprint (see Fingerprint) every 10 minutes.
 func WithOptimizer(optimizer Optimizer) Option {
-	return func(db *DB) {
+	return optionFunc(func(db *DB) {
 		db.optimizer = optimizer
-	}
+		db.analyzed = &analyzedQueries{last: make(map[string]time.Time)}
+	})
 }
 
 // WithValidator enables SQL injection prevention with the given validator.
 // If not set, no SQL validation is performed (queries execute as-is).
 // Use security.NewValidator() for default validation or security.NewValidator(security.WithStrict(true)) for strict mode.
 func WithValidator(validator *security.Validator) Option {
-	return func(db *DB) {
+	return optionFunc(func(db *DB) {
 		db.validator = validator
-	}
+	})
 }
 
 // WithAuditLog enables audit logging with the given auditor.
@@ -128,17 +205,21 @@ func WithValidator(validator *security.Validator) Option {
 // Use security.NewAuditor(logger, security.AuditWrites) for write-only auditing,
 // or security.NewAuditor(logger, security.AuditAll) for complete audit trail.
 func WithAuditLog(auditor *security.Auditor) Option {
-	return func(db *DB) {
+	return optionFunc(func(db *DB) {
 		db.auditor = auditor
-	}
+	})
 }
 
 // WithLogger sets the logger for the database.
 // If not set, a NoopLogger is used (zero overhead when logging is disabled).
+// Messages below the level set with WithLogLevel are dropped.
 func WithLogger(l logger.Logger) Option {
-	return func(db *DB) {
+	return optionFunc(func(db *DB) {
+		if db.settings != nil && l != nil {
+			l = logger.NewLevelFilter(l, &db.settings.logLevel)
+		}
 		db.logger = l
-	}
+	})
 }
 
 // WithQueryHook sets a callback function that is invoked after each query execution.
@@ -152,27 +233,30 @@ func WithLogger(l logger.Logger) Option {
 //	        slog.Info("query", "sql", e.SQL, "duration", e.Duration)
 //	    }))
 func WithQueryHook(hook QueryHook) Option {
-	return func(db *DB) {
+	return optionFunc(func(db *DB) {
 		db.queryHook = hook
-	}
+	})
 }
 
 // WithSensitiveFields sets the list of sensitive field names for parameter masking.
 // If not set, default sensitive field patterns are used (password, token, api_key, etc.).
 func WithSensitiveFields(fields []string) Option {
-	return func(db *DB) {
+	return optionFunc(func(db *DB) {
 		db.sanitizer = logger.NewSanitizer(fields)
-	}
+	})
 }
 
 // NewDB creates a new DB instance.
 func NewDB(driverName, dsn string) (*DB, error) {
-	sqlDB, err := sql.Open(driverName, dsn)
+	connector, err := sessionBaseConnector(driverName, dsn)
 	if err != nil {
 		return nil, err
 	}
 
 	dialect := dialects.GetDialect(driverName)
+	session := &sessionSettings{}
+	sqlDB := sql.OpenDB(&sessionConnector{base: connector, dialect: dialect, session: session})
+
 	return &DB{
 		sqlDB:      sqlDB,
 		driverName: driverName,
@@ -180,6 +264,13 @@ func NewDB(driverName, dsn string) (*DB, error) {
 		dialect:    dialect,
 		logger:     &logger.NoopLogger{},
 		sanitizer:  logger.NewSanitizer(nil),
+		dsn:        dsn,
+		pools:      &poolRegistry{pools: make(map[string]*Pool)},
+		tagStats:   &tagStatsRegistry{tags: make(map[string]*tagStat)},
+		queries:    &queryRegistry{queries: make(map[string]*registeredQuery)},
+		drain:      newDrainGroup(),
+		settings:   newRuntimeSettings(),
+		session:    session,
 	}, nil
 }
 
@@ -191,7 +282,7 @@ func Open(driverName, dsn string, opts ...Option) (*DB, error) {
 	}
 
 	for _, opt := range opts {
-		opt(db)
+		opt.apply(db)
 	}
 
 	return db, nil
@@ -226,18 +317,52 @@ func WrapDB(sqlDB *sql.DB, driverName string) *DB {
 		dialect:    dialect,
 		logger:     &logger.NoopLogger{},
 		sanitizer:  logger.NewSanitizer(nil),
+		pools:      &poolRegistry{pools: make(map[string]*Pool)},
+		tagStats:   &tagStatsRegistry{tags: make(map[string]*tagStat)},
+		queries:    &queryRegistry{queries: make(map[string]*registeredQuery)},
+		drain:      newDrainGroup(),
+		settings:   newRuntimeSettings(),
 	}
 }
 
-// Close releases all database resources.
+// Close releases all database resources, including named pools.
+// Closing a DB bound to a transaction (see Tx.DB) is a no-op; closing a DB
+// bound to a connection (see Conn) returns the connection to the pool.
 func (db *DB) Close() error {
+	if db.bound != nil {
+		return nil
+	}
+	if db.pinned != nil {
+		return db.pinned.Close()
+	}
+
 	// Stop health checker if running
 	if db.healthChecker != nil {
 		db.healthChecker.shutdown()
 	}
+	db.replicas.shutdown()
 
+	// Write the audit events still buffered
+	if db.auditTable != nil {
+		db.auditTable.shutdown()
+		if err := db.auditTable.flush(context.Background()); err != nil {
+			db.logger.Error("audit log flush failed", "error", err)
+		}
+	}
+
+	// Persist the optimizer statistics gathered since the last flush
+	if db.optStore != nil {
+		if err := db.FlushOptimizerStore(context.Background()); err != nil {
+			db.logger.Error("optimizer store flush failed", "error", err)
+		}
+	}
+
+	poolErr := db.closePools()
 	db.stmtCache.Clear()
-	return db.sqlDB.Close()
+	if err := db.sqlDB.Close(); err != nil {
+		return err
+	}
+	return poolErr
 }
 
 // DriverName returns the name of the DB driver.
@@ -254,7 +379,7 @@ func (db *DB) WithContext(ctx context.Context) *DB {
 
 // Builder returns a query builder for this database.
 func (db *DB) Builder() *QueryBuilder {
-	return &QueryBuilder{db: db}
+	return &QueryBuilder{db: db, tx: db.boundSQLTx()}
 }
 
 // NewQuery creates a raw SQL query for execution.
@@ -267,12 +392,14 @@ func (db *DB) Builder() *QueryBuilder {
 //
 //	// With parameters
 //	var user User
-//	err := db.NewQuery("SELECT * FROM users WHERE id = ?").Bind(1).One(&user)
-func (db *DB) NewQuery(query string) *Query {
-	return &Query{
-		sql: query,
-		db:  db,
-	}
+//	err := db.NewQuery("SELECT * FROM users WHERE id = ?", 1).One(&user)
+func (db *DB) NewQuery(query string, params ...interface{}) *Query {
+	return db.guard(&Query{
+		sql:    query,
+		params: params,
+		db:     db,
+		tx:     db.boundSQLTx(),
+	})
 }
 
 // NewQueryBuilder creates a new query builder with optional transaction support.
@@ -288,7 +415,13 @@ func (db *DB) Begin(ctx context.Context) (*Tx, error) {
 
 // BeginTx starts a transaction with specified options.
 // Options can specify isolation level and read-only mode.
+// On a DB bound to a transaction (see Tx.DB), a savepoint is created instead
+// and opts are ignored.
 func (db *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	if db.bound != nil {
+		return db.beginSavepoint(ctx)
+	}
+
 	var sqlOpts *sql.TxOptions
 	if opts != nil {
 		sqlOpts = &sql.TxOptions{
@@ -297,15 +430,35 @@ func (db *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
 		}
 	}
 
-	tx, err := db.sqlDB.BeginTx(ctx, sqlOpts)
+	if err := db.drain.enter(); err != nil {
+		return nil, err
+	}
+	ctx, span := db.startTxSpan(ctx)
+	var tx *sql.Tx
+	var err error
+	if db.pinned != nil {
+		tx, err = db.pinned.BeginTx(ctx, sqlOpts)
+	} else {
+		tx, err = db.sqlDB.BeginTx(ctx, sqlOpts)
+	}
 	if err != nil {
+		db.drain.leave()
+		if span != nil {
+			span.RecordError(err)
+			span.End()
+		}
 		return nil, err
 	}
+	if span != nil {
+		db.tracing.txs.Store(tx, ctx)
+	}
 
 	return &Tx{
 		tx:      tx,
 		builder: NewQueryBuilder(db, tx),
 		ctx:     ctx,
+		finish:  sync.OnceFunc(db.drain.leave),
+		span:    span,
 	}, nil
 }
 
@@ -321,24 +474,48 @@ func (tx *Tx) Builder() *QueryBuilder {
 	}
 }
 
-// Commit commits the transaction.
+// Commit commits the transaction, or releases the savepoint of a nested transaction.
 func (tx *Tx) Commit() error {
-	return tx.tx.Commit()
+	if tx.savepoint != "" {
+		return tx.endSavepoint("RELEASE SAVEPOINT")
+	}
+	if err := tx.beforeCommit(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	err := classifyError(tx.tx.Commit())
+	tx.builder.db.changes.finish(tx.tx, err == nil)
+	tx.endSpan("commit", err)
+	if tx.finish != nil {
+		tx.finish()
+	}
+	return err
 }
 
-// Rollback rolls back the transaction.
+// Rollback rolls back the transaction, or rolls back to the savepoint of a nested transaction.
 func (tx *Tx) Rollback() error {
-	return tx.tx.Rollback()
+	if tx.savepoint != "" {
+		return tx.endSavepoint("ROLLBACK TO SAVEPOINT", "RELEASE SAVEPOINT")
+	}
+	_ = tx.restoreSettings()
+	tx.builder.db.changes.finish(tx.tx, false)
+	err := tx.tx.Rollback()
+	tx.endSpan("rollback", err)
+	if tx.finish != nil {
+		tx.finish()
+	}
+	return err
 }
 
 // NewQuery creates a raw SQL query that executes within the transaction.
-func (tx *Tx) NewQuery(query string) *Query {
-	return &Query{
-		sql: query,
-		db:  tx.builder.db,
-		tx:  tx.tx,
-		ctx: tx.ctx,
-	}
+func (tx *Tx) NewQuery(query string, params ...interface{}) *Query {
+	return tx.builder.db.guard(&Query{
+		sql:    query,
+		params: params,
+		db:     tx.builder.db,
+		tx:     tx.tx,
+		ctx:    tx.ctx,
+	})
 }
 
 // Transactional executes f within a transaction with automatic commit/rollback.
@@ -428,6 +605,13 @@ type PoolStats struct {
 	// LastHealthCheck is the time of the most recent health check.
 	// Zero if health checks are disabled.
 	LastHealthCheck time.Time
+
+	// HealthState is the state of the most recent health check.
+	// Always HealthStateHealthy if health checks are disabled.
+	HealthState HealthState
+
+	// HealthCheckLatency is the duration of the most recent health check probe.
+	HealthCheckLatency time.Duration
 }
 
 // Stats returns database connection pool statistics.
@@ -450,6 +634,7 @@ func (db *DB) Stats() PoolStats {
 	if db.healthChecker != nil {
 		poolStats.Healthy = db.healthChecker.isHealthy()
 		poolStats.LastHealthCheck = db.healthChecker.lastCheck()
+		poolStats.HealthState, poolStats.HealthCheckLatency = db.healthChecker.status()
 	}
 
 	return poolStats
@@ -498,29 +683,119 @@ func (db *DB) UnpinQuery(query string) bool {
 	return db.stmtCache.Unpin(query)
 }
 
+// StmtCacheStats represents prepared statement cache statistics.
+type StmtCacheStats struct {
+	// Size is the number of statements currently cached.
+	Size int
+
+	// Capacity is the maximum number of cached statements (see WithStmtCacheCapacity).
+	Capacity int
+
+	// Hits is the number of lookups served from the cache.
+	Hits uint64
+
+	// Misses is the number of lookups that required a new prepare.
+	Misses uint64
+
+	// Evictions is the number of statements evicted by the LRU policy.
+	Evictions uint64
+
+	// Pinned is the number of statements pinned via PinQuery.
+	Pinned int
+
+	// HitRate is Hits / (Hits + Misses), or 0 if there were no lookups.
+	HitRate float64
+
+	// Shards is the number of cache shards (see WithStmtCacheShards).
+	Shards int
+}
+
+// StmtCacheEntry describes a single cached prepared statement.
+type StmtCacheEntry struct {
+	// SQL is the cached query string.
+	SQL string
+
+	// Hits is the number of times the statement was served from the cache.
+	Hits uint64
+
+	// LastUsed is the time of the last cache hit, or when the statement was cached.
+	LastUsed time.Time
+
+	// Pinned reports whether the statement is pinned via PinQuery.
+	Pinned bool
+}
+
+// StmtCacheStats returns prepared statement cache statistics.
+// Use it to size WithStmtCacheCapacity: a low hit rate with frequent evictions
+// means the working set does not fit in the cache.
+func (db *DB) StmtCacheStats() StmtCacheStats {
+	stats := db.stmtCache.Stats()
+	return StmtCacheStats{
+		Size:      stats.Size,
+		Capacity:  stats.Capacity,
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		Pinned:    stats.Pinned,
+		HitRate:   stats.HitRate,
+		Shards:    stats.Shards,
+	}
+}
+
+// StmtCacheEntries returns a snapshot of the cached statements,
+// ordered from most to least recently used.
+func (db *DB) StmtCacheEntries() []StmtCacheEntry {
+	entries := db.stmtCache.Entries()
+	result := make([]StmtCacheEntry, len(entries))
+	for i, e := range entries {
+		result[i] = StmtCacheEntry{
+			SQL:      e.SQL,
+			Hits:     e.Hits,
+			LastUsed: e.LastUsed,
+			Pinned:   e.Pinned,
+		}
+	}
+	return result
+}
+
 // validateQueryAndParams validates query and parameters if validator is enabled.
 // Logs security events if auditor is enabled.
-// Returns error if validation fails.
+// Returns error if validation fails, unless the validator is report-only.
 func (db *DB) validateQueryAndParams(ctx context.Context, query string, args []interface{}) error {
 	if db.validator == nil {
 		return nil
 	}
 
 	if err := db.validator.ValidateQuery(query); err != nil {
-		if db.auditor != nil {
-			db.auditor.LogSecurityEvent(ctx, "query_blocked", query, err)
-		}
-		return err
+		return db.securityViolation(ctx, "query", query, err)
 	}
 
 	if err := db.validator.ValidateParams(args); err != nil {
+		return db.securityViolation(ctx, "params", query, err)
+	}
+
+	return nil
+}
+
+// securityViolation records a validator violation of the given kind ("query"
+// or "params") and returns the error to fail the operation with. Report-only
+// validators log the violation and let the query run.
+func (db *DB) securityViolation(ctx context.Context, kind, query string, err error) error {
+	if db.validator.ReportOnly() {
+		db.logger.Warn("security policy violation (report only)",
+			"sql", query,
+			"error", err,
+		)
 		if db.auditor != nil {
-			db.auditor.LogSecurityEvent(ctx, "params_blocked", query, err)
+			db.auditor.LogSecurityEvent(ctx, kind+"_reported", query, err)
 		}
-		return err
+		return nil
 	}
 
-	return nil
+	if db.auditor != nil {
+		db.auditor.LogSecurityEvent(ctx, kind+"_blocked", query, err)
+	}
+	return err
 }
 
 // ExecContext executes a raw SQL query (INSERT/UPDATE/DELETE).
@@ -530,14 +805,30 @@ func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}
 	// Track execution time for audit log
 	start := time.Now()
 
+	if err := db.checkReadOnly(query); err != nil {
+		return nil, err
+	}
+
 	// Validate query and parameters if validator is enabled
 	if err := db.validateQueryAndParams(ctx, query, args); err != nil {
 		return nil, err
 	}
 
-	// Execute query
-	result, err := db.sqlDB.ExecContext(ctx, query, args...)
+	if db.bound == nil {
+		if err := db.drain.enter(); err != nil {
+			return nil, err
+		}
+		defer db.drain.leave()
+	}
+
+	// Execute query, bounded by the query watchdog (see WithQueryWatchdog)
+	ctx, finish := db.watchdog(ctx, query)
+	result, err := db.conn().ExecContext(ctx, query, args...)
+	err = finish(err)
 	duration := time.Since(start)
+	if err == nil {
+		db.invalidateAfterDDL(query)
+	}
 
 	// Audit log if enabled
 	if db.auditor != nil {
@@ -546,7 +837,7 @@ func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}
 		db.auditor.LogOperation(ctx, operation, query, args, result, err, duration)
 	}
 
-	return result, err
+	return result, classifyError(err)
 }
 
 // QueryContext executes a raw SQL query and returns rows.
@@ -556,13 +847,34 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{
 	// Track execution time for audit log
 	start := time.Now()
 
+	if err := db.checkReadOnly(query); err != nil {
+		return nil, err
+	}
+
 	// Validate query and parameters if validator is enabled
 	if err := db.validateQueryAndParams(ctx, query, args); err != nil {
 		return nil, err
 	}
 
-	// Execute query
-	rows, err := db.sqlDB.QueryContext(ctx, query, args...)
+	if db.bound == nil {
+		if err := db.drain.enter(); err != nil {
+			return nil, err
+		}
+		defer db.drain.leave()
+	}
+
+	// Execute query, retrying read-only statements outside a transaction (see WithRetry)
+	var rows *sql.Rows
+	run := func() (err error) {
+		rows, err = db.conn().QueryContext(ctx, query, args...)
+		return err
+	}
+	var err error
+	if db.retry != nil && db.bound == nil && db.pinned == nil && readOnlyStatement(query) {
+		err = db.retry.run(ctx, run)
+	} else {
+		err = run()
+	}
 	duration := time.Since(start)
 
 	// Audit log if enabled
@@ -571,7 +883,7 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{
 		db.auditor.LogOperation(ctx, "SELECT", query, args, nil, err, duration)
 	}
 
-	return rows, err
+	return rows, classifyError(err)
 }
 
 // QueryRowContext executes a raw SQL query expected to return at most one row.
@@ -580,6 +892,12 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{
 func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	// Note: We cannot validate here because QueryRowContext cannot return errors.
 	// Validation must be done at a higher level (QueryBuilder) or users should use QueryContext.
+	if tx := db.boundSQLTx(); tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	if db.pinned != nil {
+		return db.pinned.QueryRowContext(ctx, query, args...)
+	}
 	return db.sqlDB.QueryRowContext(ctx, query, args...)
 }
 
@@ -602,6 +920,9 @@ func detectOperation(query string) string {
 	if strings.HasPrefix(upper, opSelect) {
 		return opSelect
 	}
+	if strings.HasPrefix(upper, opMerge) {
+		return opMerge
+	}
 	if strings.HasPrefix(upper, "CREATE") {
 		return "CREATE"
 	}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ============================================================================
+// Enums
+// ============================================================================
+//
+// RegisterEnum declares the allowed values of a named Go type (e.g.
+// `type Status string`). Query parameters of a registered type are validated
+// before execution, so writing an unknown value fails with ErrInvalidEnumValue
+// instead of reaching the database. Scanning into a registered type works like
+// any other string or integer type.
+
+// EnumValue is the set of underlying types an enum type may have.
+type EnumValue interface {
+	~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Enum is the set of allowed values of the enum type E.
+type Enum[E EnumValue] struct {
+	values []E
+	set    map[E]struct{}
+}
+
+// enumRegistry maps registered enum types to their validators.
+var enumRegistry struct {
+	mu    sync.RWMutex
+	types map[reflect.Type]func(v interface{}) bool
+}
+
+// RegisterEnum declares the allowed values of E and enables validation of
+// query parameters of type E. Registering E again replaces its values.
+// Call it during initialization, typically in a package-level var.
+//
+// Example:
+//
+//	type Status string
+//
+//	const (
+//	    StatusActive Status = "active"
+//	    StatusBanned Status = "banned"
+//	)
+//
+//	var Statuses = relica.RegisterEnum(StatusActive, StatusBanned)
+//
+//	db.Model(&User{Status: "actve"}).Insert() // ErrInvalidEnumValue
+func RegisterEnum[E EnumValue](values ...E) *Enum[E] {
+	e := &Enum[E]{
+		values: append([]E(nil), values...),
+		set:    make(map[E]struct{}, len(values)),
+	}
+	for _, v := range values {
+		e.set[v] = struct{}{}
+	}
+
+	enumRegistry.mu.Lock()
+	defer enumRegistry.mu.Unlock()
+	if enumRegistry.types == nil {
+		enumRegistry.types = make(map[reflect.Type]func(v interface{}) bool)
+	}
+	enumRegistry.types[reflect.TypeOf((*E)(nil)).Elem()] = func(v interface{}) bool {
+		return e.Valid(v.(E))
+	}
+	return e
+}
+
+// Values returns the allowed values in registration order.
+func (e *Enum[E]) Values() []E {
+	return append([]E(nil), e.values...)
+}
+
+// Valid reports whether v is an allowed value.
+func (e *Enum[E]) Valid(v E) bool {
+	_, ok := e.set[v]
+	return ok
+}
+
+// Parse returns the allowed value whose string form is s, or ErrInvalidEnumValue.
+func (e *Enum[E]) Parse(s string) (E, error) {
+	for _, v := range e.values {
+		if fmt.Sprint(v) == s {
+			return v, nil
+		}
+	}
+	var zero E
+	return zero, fmt.Errorf("%w: %q is not a valid %T", ErrInvalidEnumValue, s, zero)
+}
+
+// InEnum generates "col IN (values...)" for typed enum values.
+// Unlike In, the values must share the enum type, and unregistered values
+// are rejected when the query executes.
+//
+// Example:
+//
+//	db.Builder().Select().From("users").
+//	    Where(relica.InEnum("status", StatusActive, StatusBanned)).
+//	    All(&users)
+func InEnum[E EnumValue](col string, values ...E) Expression {
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return In(col, vals...)
+}
+
+// validateEnumParams returns ErrInvalidEnumValue if a parameter of a registered
+// enum type (or a non-nil pointer to one) holds a value that is not allowed.
+func validateEnumParams(params []interface{}) error {
+	enumRegistry.mu.RLock()
+	defer enumRegistry.mu.RUnlock()
+	if len(enumRegistry.types) == 0 {
+		return nil
+	}
+
+	for _, p := range params {
+		if p == nil {
+			continue
+		}
+		rv := reflect.ValueOf(p)
+		if rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				continue
+			}
+			rv = rv.Elem()
+		}
+		valid, ok := enumRegistry.types[rv.Type()]
+		if ok && !valid(rv.Interface()) {
+			return fmt.Errorf("%w: %v is not a valid %s", ErrInvalidEnumValue, rv.Interface(), rv.Type())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type enumStatus string
+
+const (
+	enumStatusActive enumStatus = "active"
+	enumStatusBanned enumStatus = "banned"
+)
+
+type enumPriority int
+
+var (
+	enumStatuses   = RegisterEnum(enumStatusActive, enumStatusBanned)
+	enumPriorities = RegisterEnum[enumPriority](1, 2, 3)
+)
+
+type enumTicket struct {
+	ID       int          `db:"id"`
+	Status   enumStatus   `db:"status"`
+	Priority enumPriority `db:"priority"`
+}
+
+func (enumTicket) TableName() string { return "tickets" }
+
+func TestEnum_ValuesValidParse(t *testing.T) {
+	assert.Equal(t, []enumStatus{"active", "banned"}, enumStatuses.Values())
+	assert.True(t, enumStatuses.Valid(enumStatusBanned))
+	assert.False(t, enumStatuses.Valid("deleted"))
+
+	s, err := enumStatuses.Parse("banned")
+	require.NoError(t, err)
+	assert.Equal(t, enumStatusBanned, s)
+
+	_, err = enumStatuses.Parse("deleted")
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+
+	p, err := enumPriorities.Parse("2")
+	require.NoError(t, err)
+	assert.Equal(t, enumPriority(2), p)
+}
+
+func TestEnum_WriteValidation(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE tickets (id INTEGER PRIMARY KEY, status TEXT, priority INTEGER)`)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(&enumTicket{Status: enumStatusActive, Priority: 1}).Insert())
+
+	err = db.Model(&enumTicket{Status: "actve", Priority: 1}).Insert()
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+	assert.ErrorContains(t, err, "actve")
+
+	err = db.Model(&enumTicket{ID: 1, Status: enumStatusBanned, Priority: 9}).Update()
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+
+	bad := enumStatus("gone")
+	_, err = db.Builder().Update("tickets").Set(map[string]interface{}{"status": &bad}).Execute()
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+
+	// Plain strings are not validated.
+	_, err = db.Builder().Update("tickets").Set(map[string]interface{}{"status": "legacy"}).Where("id = ?", 1).Execute()
+	require.NoError(t, err)
+
+	var rows []enumTicket
+	require.NoError(t, db.Builder().Select().From("tickets").All(&rows))
+	require.Len(t, rows, 1)
+	assert.Equal(t, enumStatus("legacy"), rows[0].Status) // scanning does not validate
+	assert.Equal(t, enumPriority(1), rows[0].Priority)
+}
+
+func TestInEnum(t *testing.T) {
+	db := mockDB("postgres")
+
+	sql, params := db.Builder().Select().From("tickets").
+		Where(InEnum("status", enumStatusActive, enumStatusBanned)).ToSQL()
+	assert.Equal(t, `SELECT * FROM "tickets" WHERE "status" IN ($1, $2)`, sql)
+	assert.Equal(t, []interface{}{enumStatusActive, enumStatusBanned}, params)
+
+	var rows []enumTicket
+	err := db.Builder().Select().From("tickets").Where(InEnum[enumStatus]("status", "bogus")).All(&rows)
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+}
@@ -1,9 +1,12 @@
 package core
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -32,6 +35,101 @@ var (
 	//	    // handle not found
 	//	}
 	ErrNotFound = errors.New("relica: record not found")
+
+	// ErrColumnNotAllowed is returned when user-supplied sort input references a
+	// column outside the whitelist (see OrderBySafe) or is malformed.
+	ErrColumnNotAllowed = errors.New("relica: column not allowed")
+
+	// ErrFilterNotAllowed is returned when a FilterSpec parameter is used with
+	// an operator that was not declared for it.
+	ErrFilterNotAllowed = errors.New("relica: filter not allowed")
+
+	// ErrQueueFull is returned when the concurrency limiter's wait queue is full
+	// (see WithMaxConcurrentQueries and QueuePolicy.MaxQueueLength).
+	ErrQueueFull = errors.New("relica: query queue is full")
+
+	// ErrQueueTimeout is returned when a query waited longer than
+	// QueuePolicy.Timeout for a concurrency slot.
+	ErrQueueTimeout = errors.New("relica: timed out waiting for a query slot")
+
+	// ErrInvalidEnumValue is returned when a query parameter of a registered
+	// enum type (see RegisterEnum) holds a value that is not allowed.
+	ErrInvalidEnumValue = errors.New("relica: invalid enum value")
+
+	// ErrLockHeld is returned by TryAdvisoryLock when another session holds the lock.
+	ErrLockHeld = errors.New("relica: lock is held by another session")
+
+	// ErrNoAuditTable is returned by the audit log table methods of a DB
+	// opened without WithAuditTable.
+	ErrNoAuditTable = errors.New("relica: audit log table not enabled (see WithAuditTable)")
+
+	// ErrNoOptimizerStore is returned by the optimizer store methods of a DB
+	// opened without WithOptimizerStore.
+	ErrNoOptimizerStore = errors.New("relica: optimizer store not enabled (see WithOptimizerStore)")
+
+	// ErrShuttingDown is returned for queries and transactions started after
+	// DB.Shutdown was called.
+	ErrShuttingDown = errors.New("relica: database is shutting down")
+
+	// ErrNotReconfigurable is returned by DB.Reconfigure for options that
+	// cannot change while the database is in use.
+	ErrNotReconfigurable = errors.New("relica: option cannot be changed at runtime")
+
+	// ErrQueryNotRegistered is returned when executing a DB.Query name that
+	// was not registered with RegisterQuery.
+	ErrQueryNotRegistered = errors.New("relica: query not registered")
+
+	// ErrUnknownTable is returned in strict schema mode when a query
+	// references a table that does not exist (see WithStrictSchema).
+	ErrUnknownTable = errors.New("relica: unknown table")
+
+	// ErrUnknownColumn is returned in strict schema mode when a query
+	// references a column that does not exist (see WithStrictSchema).
+	ErrUnknownColumn = errors.New("relica: unknown column")
+
+	// ErrLintViolation is returned when a query breaks a lint rule that the
+	// lint policy blocks (see WithQueryLint).
+	ErrLintViolation = errors.New("relica: query blocked by lint policy")
+
+	// ErrMissingWhere is returned in safe-writes mode for an UPDATE or DELETE
+	// without a WHERE condition (see WithSafeWrites).
+	ErrMissingWhere = errors.New("relica: missing WHERE condition")
+
+	// ErrReadOnly is returned when a statement that may change data is built
+	// or executed through a read-only handle or query (see WithReadOnly).
+	ErrReadOnly = errors.New("relica: statement not allowed on read-only handle")
+
+	// ErrTooManyParams is returned when a built statement has more bind
+	// parameters than the database allows. Split it into several statements,
+	// for example with WhereInChunked or smaller batches.
+	ErrTooManyParams = errors.New("relica: too many query parameters")
+
+	// ErrQueryWatchdog is returned when a query is canceled for running
+	// longer than the maximum runtime set with WithQueryWatchdog.
+	ErrQueryWatchdog = errors.New("relica: query exceeded maximum runtime")
+)
+
+// Database error kinds. Query and transaction errors of these kinds are
+// returned as a *DBError, so errors.Is(err, ErrDeadlock) works the same on
+// PostgreSQL, MySQL and SQLite.
+var (
+	// ErrUniqueViolation is the kind of unique constraint violations.
+	ErrUniqueViolation = errors.New("relica: unique constraint violation")
+	// ErrForeignKeyViolation is the kind of foreign key constraint violations.
+	ErrForeignKeyViolation = errors.New("relica: foreign key constraint violation")
+	// ErrNotNullViolation is the kind of NOT NULL constraint violations.
+	ErrNotNullViolation = errors.New("relica: not-null constraint violation")
+	// ErrCheckViolation is the kind of CHECK constraint violations.
+	ErrCheckViolation = errors.New("relica: check constraint violation")
+	// ErrSerializationFailure is the kind of serialization failures of
+	// concurrent transactions; retry the whole transaction.
+	ErrSerializationFailure = errors.New("relica: serialization failure")
+	// ErrDeadlock is the kind of deadlocks detected by the database; retry
+	// the whole transaction.
+	ErrDeadlock = errors.New("relica: deadlock detected")
+	// ErrTimeout is the kind of statement and lock timeouts, including an
+	// expired context deadline and SQLite's busy timeout.
+	ErrTimeout = errors.New("relica: timeout")
 )
 
 // wrapErrNotFound returns an error that satisfies both:
@@ -177,3 +275,203 @@ func IsCheckViolation(err error) bool {
 		strings.Contains(msg, "CHECK constraint failed") ||
 		strings.Contains(msg, "Error 3819")
 }
+
+// ============================================================================
+// Structured database errors
+// ============================================================================
+//
+// classifyError wraps driver errors of a known kind into a *DBError. The kind
+// comes from the SQLSTATE code when the driver error has one (pgx, lib/pq),
+// otherwise from the message patterns of the Is* helpers above. Constraint,
+// table and column are read from the driver error fields when present, else
+// parsed from the message.
+
+// DBError is a database error of a known kind (ErrUniqueViolation,
+// ErrDeadlock, ...). errors.Is matches both its Kind and the driver error,
+// and Error returns the driver message.
+//
+// Example:
+//
+//	_, err := db.Model(&user).Insert()
+//	var dbErr *relica.DBError
+//	if errors.As(err, &dbErr) && dbErr.Kind == relica.ErrUniqueViolation {
+//	    return fmt.Errorf("%s is already taken", dbErr.Column)
+//	}
+type DBError struct {
+	// Kind is the sentinel error of the kind, e.g. ErrUniqueViolation.
+	Kind error
+	// Constraint is the violated constraint or index, if known.
+	Constraint string
+	// Table is the table of the violated constraint, if known.
+	Table string
+	// Column is the column of the violated constraint, if known.
+	Column string
+	// Err is the driver error.
+	Err error
+}
+
+func (e *DBError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the driver error.
+func (e *DBError) Unwrap() error { return e.Err }
+
+// Is reports whether target is the kind of e.
+func (e *DBError) Is(target error) bool { return target == e.Kind }
+
+// sqlStateKinds maps SQLSTATE codes to error kinds.
+var sqlStateKinds = map[string]error{
+	"23505": ErrUniqueViolation,
+	"23503": ErrForeignKeyViolation,
+	"23502": ErrNotNullViolation,
+	"23514": ErrCheckViolation,
+	"40001": ErrSerializationFailure,
+	"40P01": ErrDeadlock,
+	"55P03": ErrTimeout, // lock_not_available (lock_timeout)
+}
+
+// Message patterns of the kinds without an Is* helper.
+var (
+	serializationMessages = []string{"could not serialize access"}
+	deadlockMessages      = []string{"deadlock detected", "Deadlock found", "Error 1213"}
+	timeoutMessages       = []string{
+		"canceling statement due to statement timeout",
+		"canceling statement due to lock timeout",
+		"Lock wait timeout exceeded", "Error 1205",
+		"maximum statement execution time exceeded", "Error 3024",
+		"database is locked", "database table is locked",
+	}
+)
+
+// constraintDetailRegexes extract the constraint, table and column of a
+// constraint violation from driver messages.
+var constraintDetailRegexes = []*regexp.Regexp{
+	// SQLite: UNIQUE constraint failed: users.email
+	regexp.MustCompile(`constraint failed: (?P<table>\w+)\.(?P<column>\w+)`),
+	// SQLite: CHECK constraint failed: price_positive
+	regexp.MustCompile(`CHECK constraint failed: (?P<constraint>\w+)`),
+	// PostgreSQL: violates unique constraint "users_email_key"
+	regexp.MustCompile(`violates (?:unique|foreign key|check) constraint "(?P<constraint>[^"]+)"`),
+	// PostgreSQL: null value in column "email" of relation "users"
+	regexp.MustCompile(`column "(?P<column>[^"]+)"(?: of relation "(?P<table>[^"]+)")?`),
+	// PostgreSQL: on table "orders", new row for relation "products"
+	regexp.MustCompile(`(?:on table|for relation) "(?P<table>[^"]+)"`),
+	// PostgreSQL detail: Key (email)=(a@example.com) already exists.
+	regexp.MustCompile(`Key \((?P<column>[^),]+)\)=`),
+	// MySQL: Duplicate entry 'a@example.com' for key 'users.email'
+	regexp.MustCompile(`for key '(?:(?P<table>\w+)\.)?(?P<constraint>[^']+)'`),
+	// MySQL: Column 'email' cannot be null
+	regexp.MustCompile(`Column '(?P<column>[^']+)' cannot be null`),
+	// MySQL: (`shop`.`orders`, CONSTRAINT `orders_ibfk_1` FOREIGN KEY (`user_id`)
+	regexp.MustCompile("`(?P<table>\\w+)`, CONSTRAINT `(?P<constraint>\\w+)` FOREIGN KEY \\(`(?P<column>\\w+)`"),
+	// MySQL: Check constraint 'price_positive' is violated.
+	regexp.MustCompile(`Check constraint '(?P<constraint>[^']+)'`),
+}
+
+// classifyError returns err as a *DBError if it is of a known kind, and err
+// unchanged otherwise.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var dbErr *DBError
+	if errors.As(err, &dbErr) {
+		return err
+	}
+	kind := errorKind(err)
+	if kind == nil {
+		return err
+	}
+	e := &DBError{Kind: kind, Err: err}
+	if kind == ErrUniqueViolation || kind == ErrForeignKeyViolation ||
+		kind == ErrNotNullViolation || kind == ErrCheckViolation {
+		e.Constraint = driverErrorField(err, "ConstraintName", "Constraint")
+		e.Table = driverErrorField(err, "TableName", "Table")
+		e.Column = driverErrorField(err, "ColumnName", "Column")
+		e.fillFromMessage(err.Error() + "\n" + driverErrorField(err, "Detail"))
+	}
+	return e
+}
+
+// errorKind returns the kind of err, or nil.
+func errorKind(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	if kind, ok := sqlStateKinds[sqlState(err)]; ok {
+		return kind
+	}
+	msg := err.Error()
+	switch {
+	case IsUniqueViolation(err):
+		return ErrUniqueViolation
+	case IsForeignKeyViolation(err):
+		return ErrForeignKeyViolation
+	case IsNotNullViolation(err):
+		return ErrNotNullViolation
+	case IsCheckViolation(err):
+		return ErrCheckViolation
+	case containsAny(msg, serializationMessages):
+		return ErrSerializationFailure
+	case containsAny(msg, deadlockMessages):
+		return ErrDeadlock
+	case containsAny(msg, timeoutMessages):
+		return ErrTimeout
+	}
+	return nil
+}
+
+// sqlState returns the SQLSTATE code of a driver error, or "".
+func sqlState(err error) string {
+	var coded interface{ SQLState() string }
+	if errors.As(err, &coded) {
+		return coded.SQLState()
+	}
+	return ""
+}
+
+// driverErrorField returns the first non-empty string field with one of the
+// given names of an error in the chain of err, or "". Driver error structs
+// (pgconn.PgError, pq.Error) are read without importing the drivers.
+func driverErrorField(err error, names ...string) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		for _, name := range names {
+			if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+				return f.String()
+			}
+		}
+	}
+	return ""
+}
+
+// fillFromMessage fills the empty constraint, table and column from msg.
+func (e *DBError) fillFromMessage(msg string) {
+	fields := map[string]*string{"constraint": &e.Constraint, "table": &e.Table, "column": &e.Column}
+	for _, re := range constraintDetailRegexes {
+		m := re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			if field, ok := fields[name]; ok && *field == "" && m[i] != "" {
+				*field = m[i]
+			}
+		}
+	}
+}
+
+// containsAny reports whether s contains one of substrs.
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
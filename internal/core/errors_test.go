@@ -1,12 +1,14 @@
 package core
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // ============================================================================
@@ -295,3 +297,162 @@ func TestWrapError_WrapsMessage(t *testing.T) {
 	assert.EqualError(t, wrapped, "operation failed: base error")
 	assert.True(t, errors.Is(wrapped, base))
 }
+
+// ============================================================================
+// DBError classification tests
+// ============================================================================
+
+// pgError mimics the exported fields and SQLState method of pgconn.PgError.
+type pgError struct {
+	Code           string
+	Message        string
+	Detail         string
+	TableName      string
+	ConstraintName string
+}
+
+func (e *pgError) Error() string    { return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")" }
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want DBError
+	}{
+		{
+			name: "sqlite unique",
+			err:  errors.New("UNIQUE constraint failed: users.email"),
+			want: DBError{Kind: ErrUniqueViolation, Table: "users", Column: "email"},
+		},
+		{
+			name: "postgres unique message",
+			err:  errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`),
+			want: DBError{Kind: ErrUniqueViolation, Constraint: "users_email_key"},
+		},
+		{
+			name: "pgx unique fields",
+			err: &pgError{Code: "23505", Message: `duplicate key value violates unique constraint "users_email_key"`,
+				Detail: "Key (email)=(a@example.com) already exists.", TableName: "users", ConstraintName: "users_email_key"},
+			want: DBError{Kind: ErrUniqueViolation, Constraint: "users_email_key", Table: "users", Column: "email"},
+		},
+		{
+			name: "mysql unique",
+			err:  errors.New("Error 1062 (23000): Duplicate entry 'a@example.com' for key 'users.email'"),
+			want: DBError{Kind: ErrUniqueViolation, Constraint: "email", Table: "users"},
+		},
+		{
+			name: "postgres foreign key",
+			err:  errors.New(`pq: insert or update on table "orders" violates foreign key constraint "orders_user_id_fkey"`),
+			want: DBError{Kind: ErrForeignKeyViolation, Constraint: "orders_user_id_fkey", Table: "orders"},
+		},
+		{
+			name: "mysql foreign key",
+			err: errors.New("Error 1452 (23000): Cannot add or update a child row: a foreign key constraint fails " +
+				"(`shop`.`orders`, CONSTRAINT `orders_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"),
+			want: DBError{Kind: ErrForeignKeyViolation, Constraint: "orders_ibfk_1", Table: "orders", Column: "user_id"},
+		},
+		{
+			name: "postgres not null",
+			err:  errors.New(`pq: null value in column "email" of relation "users" violates not-null constraint`),
+			want: DBError{Kind: ErrNotNullViolation, Table: "users", Column: "email"},
+		},
+		{
+			name: "mysql not null",
+			err:  errors.New("Error 1048 (23000): Column 'email' cannot be null"),
+			want: DBError{Kind: ErrNotNullViolation, Column: "email"},
+		},
+		{
+			name: "sqlite check",
+			err:  errors.New("CHECK constraint failed: price_positive"),
+			want: DBError{Kind: ErrCheckViolation, Constraint: "price_positive"},
+		},
+		{
+			name: "postgres serialization",
+			err:  &pgError{Code: "40001", Message: "could not serialize access due to concurrent update"},
+			want: DBError{Kind: ErrSerializationFailure},
+		},
+		{
+			name: "postgres deadlock",
+			err:  errors.New("pq: deadlock detected"),
+			want: DBError{Kind: ErrDeadlock},
+		},
+		{
+			name: "mysql deadlock",
+			err:  errors.New("Error 1213 (40001): Deadlock found when trying to get lock; try restarting transaction"),
+			want: DBError{Kind: ErrDeadlock},
+		},
+		{
+			name: "postgres statement timeout",
+			err:  errors.New("pq: canceling statement due to statement timeout"),
+			want: DBError{Kind: ErrTimeout},
+		},
+		{
+			name: "mysql lock wait timeout",
+			err:  errors.New("Error 1205 (HY000): Lock wait timeout exceeded; try restarting transaction"),
+			want: DBError{Kind: ErrTimeout},
+		},
+		{
+			name: "sqlite busy",
+			err:  errors.New("database is locked (5) (SQLITE_BUSY)"),
+			want: DBError{Kind: ErrTimeout},
+		},
+		{
+			name: "context deadline",
+			err:  fmt.Errorf("query: %w", context.DeadlineExceeded),
+			want: DBError{Kind: ErrTimeout},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+			var dbErr *DBError
+			require.ErrorAs(t, err, &dbErr)
+			assert.ErrorIs(t, err, tt.want.Kind)
+			assert.ErrorIs(t, err, tt.err, "the driver error stays in the chain")
+			assert.Equal(t, tt.err.Error(), err.Error())
+			assert.Equal(t, tt.want.Constraint, dbErr.Constraint)
+			assert.Equal(t, tt.want.Table, dbErr.Table)
+			assert.Equal(t, tt.want.Column, dbErr.Column)
+		})
+	}
+}
+
+func TestClassifyError_Unclassified(t *testing.T) {
+	assert.Nil(t, classifyError(nil))
+	syntax := errors.New("near \"SELEC\": syntax error")
+	assert.Same(t, syntax, classifyError(syntax))
+	assert.Equal(t, context.Canceled, classifyError(context.Canceled))
+
+	once := classifyError(errors.New("deadlock detected"))
+	assert.Same(t, once, classifyError(once), "classified errors are not wrapped twice")
+}
+
+func TestClassifyError_SQLite(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	_, err = db.NewQuery("CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE)").Execute()
+	require.NoError(t, err)
+	_, err = db.Builder().Insert("users", map[string]interface{}{"id": 1, "email": "a@example.com"}).Execute()
+	require.NoError(t, err)
+
+	_, err = db.Builder().Insert("users", map[string]interface{}{"id": 2, "email": "a@example.com"}).Execute()
+	var dbErr *DBError
+	require.ErrorAs(t, err, &dbErr)
+	assert.ErrorIs(t, err, ErrUniqueViolation)
+	assert.Equal(t, "email", dbErr.Column)
+	assert.True(t, IsUniqueViolation(err))
+
+	_, err = db.ExecContext(context.Background(), "INSERT INTO users (id) VALUES (3)")
+	assert.ErrorIs(t, err, ErrNotNullViolation)
+
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	_, err = tx.NewQuery("INSERT INTO users (id, email) VALUES (1, 'b@example.com')").Execute()
+	assert.ErrorIs(t, err, ErrUniqueViolation)
+	require.NoError(t, tx.Rollback())
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Expanded SQL
+// ============================================================================
+//
+// ExpandedSQL renders a query with its parameters inlined as SQL literals, so
+// it can be pasted into psql/mysql/sqlite3 while debugging. Placeholders inside
+// string literals, quoted identifiers and comments are left untouched.
+//
+// The output is for humans only: never execute it. Parameter binding remains
+// the only safe way to pass values to the database.
+
+// WithExpandedSQLLogging makes the logger record the expanded SQL (see
+// Query.ExpandedSQL) of failed queries under the "expanded_sql" key.
+// Sensitive parameters (see WithSensitiveFields) are masked before expansion.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithLogger(logger),
+//	    relica.WithExpandedSQLLogging())
+func WithExpandedSQLLogging() Option {
+	return optionFunc(func(db *DB) {
+		db.logExpanded = true
+	})
+}
+
+// ExpandedSQL returns the query with its parameters interpolated as escaped
+// SQL literals, for debugging and logging only.
+//
+// Example:
+//
+//	q := db.NewQuery("SELECT * FROM users WHERE id = $1 AND name = $2", 1, "O'Hara")
+//	q.ExpandedSQL() // SELECT * FROM users WHERE id = 1 AND name = 'O''Hara'
+func (q *Query) ExpandedSQL() string {
+	return expandSQL(q.db.dialect, q.sql, q.params)
+}
+
+// ExpandedSQL returns the built query with its parameters interpolated as
+// escaped SQL literals, for debugging and logging only.
+func (sq *SelectQuery) ExpandedSQL() string {
+	query, params := sq.ToSQL()
+	return expandSQL(sq.builder.db.dialect, query, params)
+}
+
+// logFailure logs a failed query with its masked parameters, the extra
+// key-value pairs in attrs and, if enabled, the expanded SQL.
+func (q *Query) logFailure(msg string, err error, attrs ...interface{}) {
+	masked := q.db.sanitizer.MaskParams(q.sql, q.params)
+	args := make([]interface{}, 0, 8+len(attrs))
+	args = append(args, "sql", q.sql, "params", q.db.sanitizer.FormatParams(masked))
+	if q.db.logExpanded {
+		args = append(args, "expanded_sql", expandSQL(q.db.dialect, q.sql, masked))
+	}
+	args = append(args, attrs...)
+	if q.tag != "" {
+		args = append(args, "tag", q.tag)
+	}
+	args = append(args, "error", err)
+	q.db.logger.Error(msg, args...)
+}
+
+// expandSQL replaces the placeholders of query with literals for params.
+// PostgreSQL-style dialects use $N placeholders; others use positional ?.
+func expandSQL(dialect dialects.Dialect, query string, params []interface{}) string {
+	if len(params) == 0 {
+		return query
+	}
+	numbered := numberedPlaceholders(dialect)
+	_, mysql := dialect.(*dialects.MySQLDialect)
+
+	var b strings.Builder
+	b.Grow(len(query) + 16*len(params))
+	scanner := placeholderScanner{query: query, mysql: mysql}
+	last, next := 0, 0
+	for {
+		start, end, n, ok := scanner.next()
+		if !ok {
+			break
+		}
+		var param int
+		switch {
+		case n == 0 && !numbered && next < len(params):
+			param = next
+			next++
+		case n >= 1 && numbered && n <= len(params):
+			param = n - 1
+		default:
+			continue
+		}
+		b.WriteString(query[last:start])
+		b.WriteString(sqlLiteral(params[param], mysql))
+		last = end
+	}
+	b.WriteString(query[last:])
+	return b.String()
+}
+
+// quotedEnd returns the index just past the quoted section starting at start.
+// Doubled quotes are escapes; backslash escapes apply when backslashEscapes is set.
+func quotedEnd(query string, start int, backslashEscapes bool) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// sqlLiteral formats v as a SQL literal.
+func sqlLiteral(v interface{}, mysql bool) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "NULL"
+		}
+		val, err := valuer.Value()
+		if err != nil {
+			return quoteString(fmt.Sprint(v), mysql)
+		}
+		v = val
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteString(x, mysql)
+	case []byte:
+		if x == nil {
+			return "NULL"
+		}
+		if mysql {
+			return "X'" + hex.EncodeToString(x) + "'"
+		}
+		return "'\\x" + hex.EncodeToString(x) + "'"
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		if mysql {
+			return "'" + x.Format("2006-01-02 15:04:05.999999") + "'"
+		}
+		return "'" + x.Format("2006-01-02 15:04:05.999999Z07:00") + "'"
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return sqlLiteral(rv.Elem().Interface(), mysql)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+	case reflect.String:
+		return quoteString(rv.String(), mysql)
+	case reflect.Bool:
+		return sqlLiteral(rv.Bool(), mysql)
+	default:
+		return quoteString(fmt.Sprint(v), mysql)
+	}
+}
+
+// quoteString quotes s as a string literal. MySQL also treats backslash as
+// an escape character inside strings.
+func quoteString(s string, mysql bool) string {
+	if mysql {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"bytes"
+	"database/sql"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/coregx/relica/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestExpandSQL_Postgres(t *testing.T) {
+	d := dialects.GetDialect("postgres")
+
+	got := expandSQL(d, `SELECT * FROM "users" WHERE id = $1 AND name = $2 AND note = '$1' AND tag = $2`,
+		[]interface{}{42, "O'Hara"})
+	assert.Equal(t, `SELECT * FROM "users" WHERE id = 42 AND name = 'O''Hara' AND note = '$1' AND tag = 'O''Hara'`, got)
+
+	// ? is a jsonb operator in PostgreSQL and must be left alone.
+	got = expandSQL(d, `SELECT data ? 'k' FROM t WHERE id = $1`, []interface{}{1})
+	assert.Equal(t, `SELECT data ? 'k' FROM t WHERE id = 1`, got)
+
+	// Out-of-range placeholders are kept.
+	assert.Equal(t, `SELECT $3`, expandSQL(d, `SELECT $3`, []interface{}{1}))
+}
+
+func TestExpandSQL_QuestionMark(t *testing.T) {
+	d := dialects.GetDialect("sqlite")
+
+	got := expandSQL(d, "SELECT '?', \"a?\" -- where ?\nFROM t /* ? */ WHERE a = ? AND b = ?",
+		[]interface{}{1, "x"})
+	assert.Equal(t, "SELECT '?', \"a?\" -- where ?\nFROM t /* ? */ WHERE a = 1 AND b = 'x'", got)
+
+	// More placeholders than params: the rest stay as ?.
+	assert.Equal(t, `a = 1 AND b = ?`, expandSQL(d, `a = ? AND b = ?`, []interface{}{1}))
+}
+
+func TestExpandSQL_MySQLEscapes(t *testing.T) {
+	d := dialects.GetDialect("mysql")
+
+	got := expandSQL(d, "SELECT 'it\\'s ?' FROM `t?` WHERE a = ? AND b = ?",
+		[]interface{}{`back\slash`, []byte{0xde, 0xad}})
+	assert.Equal(t, "SELECT 'it\\'s ?' FROM `t?` WHERE a = 'back\\\\slash' AND b = X'dead'", got)
+}
+
+func TestSQLLiteral(t *testing.T) {
+	s := "ptr"
+	var nilPtr *string
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 600000000, time.UTC)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		mysql bool
+		want  string
+	}{
+		{"nil", nil, false, "NULL"},
+		{"bool", true, false, "TRUE"},
+		{"int", int64(-7), false, "-7"},
+		{"uint", uint8(7), false, "7"},
+		{"float", 1.5, false, "1.5"},
+		{"bytes", []byte{1, 255}, false, `'\x01ff'`},
+		{"time", ts, false, "'2026-01-02 03:04:05.6Z'"},
+		{"time mysql", ts, true, "'2026-01-02 03:04:05.6'"},
+		{"pointer", &s, false, "'ptr'"},
+		{"nil pointer", nilPtr, false, "NULL"},
+		{"valuer", sql.NullString{String: "v", Valid: true}, false, "'v'"},
+		{"null valuer", sql.NullInt64{}, false, "NULL"},
+		{"named string", enumStatusActive, false, "'active'"},
+		{"other", struct{ A int }{1}, false, "'{1}'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sqlLiteral(tt.value, tt.mysql))
+		})
+	}
+}
+
+func TestQuery_ExpandedSQL(t *testing.T) {
+	db := mockDB("postgres")
+
+	q := db.NewQuery(`SELECT * FROM users WHERE id = $1`, 5)
+	assert.Equal(t, `SELECT * FROM users WHERE id = 5`, q.ExpandedSQL())
+
+	sq := db.Builder().Select("id").From("users").Where("name = ? AND age > ?", "Ann", 30)
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE name = 'Ann' AND age > 30`, sq.ExpandedSQL())
+}
+
+func TestWithExpandedSQLLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewSlogAdapter(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	db, err := Open("sqlite", ":memory:", WithLogger(log), WithExpandedSQLLogging())
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery(`INSERT INTO missing (name) VALUES (?)`, "Ann").Execute()
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), `expanded_sql="INSERT INTO missing (name) VALUES ('Ann')"`)
+
+	// Sensitive parameters are masked before expansion.
+	buf.Reset()
+	_, err = db.NewQuery(`UPDATE missing SET password = ?`, "hunter2-secret").Execute()
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), `expanded_sql="UPDATE missing SET password = '***REDACTED***'"`)
+	assert.NotContains(t, buf.String(), "hunter2")
+
+	// Disabled by default.
+	buf.Reset()
+	plain, err := Open("sqlite", ":memory:", WithLogger(log))
+	require.NoError(t, err)
+	defer plain.Close()
+	_, err = plain.NewQuery(`INSERT INTO missing (name) VALUES (?)`, "Ann").Execute()
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "query execution failed")
+	assert.NotContains(t, buf.String(), "expanded_sql")
+}
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Export
+// ============================================================================
+//
+// The Export methods stream query results straight from the driver to an
+// io.Writer. Rows are not scanned into structs: each value is formatted from
+// its driver type, with the column's database type deciding how raw bytes are
+// written (text, number or base64 for binary columns).
+
+// CSVOptions configures SelectQuery.ExportCSV. The zero value writes a header
+// row, comma-separated fields, empty strings for NULL and RFC 3339 timestamps.
+type CSVOptions struct {
+	// Comma is the field delimiter (default ',').
+	Comma rune
+	// NoHeader omits the header row of column names.
+	NoHeader bool
+	// Null is written for NULL values (default "").
+	Null string
+	// TimeFormat is the time.Format layout for time values (default time.RFC3339Nano).
+	TimeFormat string
+	// UseCRLF ends lines with \r\n instead of \n.
+	UseCRLF bool
+}
+
+// ExportCSV writes the query result to w as CSV, one line per row.
+// A nil opts uses the defaults described on CSVOptions. The header row is
+// written even when the result is empty.
+//
+// Example:
+//
+//	w.Header().Set("Content-Type", "text/csv")
+//	err := db.Select("id", "email", "created_at").From("users").
+//	    WithCursor(1000).
+//	    ExportCSV(w, &relica.CSVOptions{Comma: ';'})
+func (sq *SelectQuery) ExportCSV(w io.Writer, opts *CSVOptions) error {
+	var o CSVOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.TimeFormat == "" {
+		o.TimeFormat = time.RFC3339Nano
+	}
+
+	cw := csv.NewWriter(w)
+	if o.Comma != 0 {
+		cw.Comma = o.Comma
+	}
+	cw.UseCRLF = o.UseCRLF
+
+	sink := &csvSink{w: cw, opts: o}
+	if err := sq.All(sink); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON writes the query result to w as a JSON array of objects keyed by
+// column name, in column order. An empty result is written as [].
+func (sq *SelectQuery) ExportJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	sink := &jsonSink{w: bw, array: true}
+	if err := sq.All(sink); err != nil {
+		return err
+	}
+	if sink.rows == 0 {
+		_ = bw.WriteByte('[')
+	}
+	_, _ = bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+// ExportNDJSON writes the query result to w as newline-delimited JSON: one
+// object per row, keyed by column name in column order.
+//
+// Example:
+//
+//	w.Header().Set("Content-Type", "application/x-ndjson")
+//	err := db.Select().From("events").Where("day = ?", day).ExportNDJSON(w)
+func (sq *SelectQuery) ExportNDJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := sq.All(&jsonSink{w: bw}); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// exportColumns holds the result columns shared by the export sinks.
+type exportColumns struct {
+	cols   []*sql.ColumnType
+	names  []string
+	values []interface{}
+	dests  []interface{}
+}
+
+// setColumns records the result columns on the first call; later calls
+// (one per chunk or cursor fetch) describe the same result and are ignored.
+func (e *exportColumns) setColumns(cols []*sql.ColumnType) error {
+	if e.cols != nil {
+		return nil
+	}
+	e.cols = cols
+	e.names = make([]string, len(cols))
+	e.values = make([]interface{}, len(cols))
+	e.dests = make([]interface{}, len(cols))
+	for i, c := range cols {
+		e.names[i] = c.Name()
+		e.dests[i] = &e.values[i]
+	}
+	return nil
+}
+
+// scan reads the current row into e.values.
+func (e *exportColumns) scan(rows *sql.Rows) error {
+	if err := rows.Scan(e.dests...); err != nil {
+		return fmt.Errorf("scanner: scan failed: %w", err)
+	}
+	return nil
+}
+
+// csvSink writes each row as a CSV record.
+type csvSink struct {
+	exportColumns
+	w      *csv.Writer
+	opts   CSVOptions
+	record []string
+}
+
+func (s *csvSink) setColumns(cols []*sql.ColumnType) error {
+	first := s.cols == nil
+	_ = s.exportColumns.setColumns(cols)
+	if first && !s.opts.NoHeader {
+		return s.w.Write(s.names)
+	}
+	return nil
+}
+
+func (s *csvSink) scanRow(rows *sql.Rows) error {
+	if err := s.scan(rows); err != nil {
+		return err
+	}
+	if s.record == nil {
+		s.record = make([]string, len(s.values))
+	}
+	for i, v := range s.values {
+		s.record[i] = formatCSVValue(v, s.cols[i], &s.opts)
+	}
+	return s.w.Write(s.record)
+}
+
+// formatCSVValue formats a driver value for a CSV field.
+func formatCSVValue(v interface{}, col *sql.ColumnType, opts *CSVOptions) string {
+	switch v := v.(type) {
+	case nil:
+		return opts.Null
+	case string:
+		return v
+	case []byte:
+		if isBinaryColumn(col) {
+			return base64.StdEncoding.EncodeToString(v)
+		}
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(opts.TimeFormat)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsonSink writes each row as a JSON object, either one per line (NDJSON)
+// or as the elements of an array.
+type jsonSink struct {
+	exportColumns
+	w     *bufio.Writer
+	array bool // write the objects as elements of a JSON array
+	rows  int  // objects written so far
+	keys  [][]byte
+}
+
+func (s *jsonSink) scanRow(rows *sql.Rows) error {
+	if err := s.scan(rows); err != nil {
+		return err
+	}
+	if s.keys == nil {
+		s.keys = make([][]byte, len(s.names))
+		for i, name := range s.names {
+			key, _ := json.Marshal(name)
+			s.keys[i] = append(key, ':')
+		}
+	}
+
+	switch {
+	case !s.array:
+	case s.rows == 0:
+		_ = s.w.WriteByte('[')
+	default:
+		_ = s.w.WriteByte(',')
+	}
+	s.rows++
+
+	_ = s.w.WriteByte('{')
+	for i, v := range s.values {
+		if i > 0 {
+			_ = s.w.WriteByte(',')
+		}
+		_, _ = s.w.Write(s.keys[i])
+		b, err := jsonValue(v, s.cols[i])
+		if err != nil {
+			return fmt.Errorf("relica: export column %s: %w", s.names[i], err)
+		}
+		_, _ = s.w.Write(b)
+	}
+	if !s.array {
+		_ = s.w.WriteByte('}')
+		// bufio.Writer keeps the first write error and returns it from every later call.
+		return s.w.WriteByte('\n')
+	}
+	return s.w.WriteByte('}')
+}
+
+// jsonValue encodes a driver value as JSON. Raw bytes of numeric columns
+// (as returned by MySQL's text protocol) are written as numbers.
+func jsonValue(v interface{}, col *sql.ColumnType) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		if isBinaryColumn(col) {
+			return json.Marshal(v) // base64 string
+		}
+		if isNumericColumn(col) && json.Valid(v) {
+			return v, nil
+		}
+		return json.Marshal(string(v))
+	case time.Time:
+		return json.Marshal(v.Format(time.RFC3339Nano))
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// isBinaryColumn reports whether col holds binary data rather than text.
+func isBinaryColumn(col *sql.ColumnType) bool {
+	t := strings.ToUpper(col.DatabaseTypeName())
+	return t == "BYTEA" || strings.Contains(t, "BLOB") || strings.Contains(t, "BINARY")
+}
+
+// isNumericColumn reports whether col holds numbers.
+func isNumericColumn(col *sql.ColumnType) bool {
+	switch t := strings.ToUpper(col.DatabaseTypeName()); {
+	case strings.Contains(t, "INT"), strings.Contains(t, "DEC"), strings.Contains(t, "NUMERIC"),
+		t == "FLOAT", t == "DOUBLE", t == "REAL", t == "FLOAT4", t == "FLOAT8":
+		return true
+	default:
+		return false
+	}
+}
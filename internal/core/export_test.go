@@ -0,0 +1,98 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExportDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE reports (id INTEGER PRIMARY KEY, title TEXT, amount REAL, data BLOB, created_at DATETIME)`)
+	require.NoError(t, err)
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	_, err = db.ExecContext(ctx, `INSERT INTO reports VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)`,
+		1, `Q1, "final"`, 12.5, []byte{0xff, 0x00}, created,
+		2, "draft", nil, nil, nil)
+	require.NoError(t, err)
+	return db
+}
+
+func TestSelectQuery_ExportCSV(t *testing.T) {
+	db := setupExportDB(t)
+
+	var buf bytes.Buffer
+	err := db.Builder().Select().From("reports").OrderBy("id").ExportCSV(&buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "id,title,amount,data,created_at\n"+
+		"1,\"Q1, \"\"final\"\"\",12.5,/wA=,2026-03-01T09:30:00Z\n"+
+		"2,draft,,,\n", buf.String())
+
+	buf.Reset()
+	err = db.Builder().Select("id", "amount", "created_at").From("reports").OrderBy("id").ExportCSV(&buf, &CSVOptions{
+		Comma:      ';',
+		NoHeader:   true,
+		Null:       `\N`,
+		TimeFormat: time.DateOnly,
+		UseCRLF:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1;12.5;2026-03-01\r\n2;\\N;\\N\r\n", buf.String())
+
+	// The header is written for an empty result.
+	buf.Reset()
+	err = db.Builder().Select("id", "title").From("reports").Where("id > ?", 99).ExportCSV(&buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "id,title\n", buf.String())
+}
+
+func TestSelectQuery_ExportJSON(t *testing.T) {
+	db := setupExportDB(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, db.Builder().Select().From("reports").OrderBy("id").ExportNDJSON(&buf))
+	assert.Equal(t,
+		`{"id":1,"title":"Q1, \"final\"","amount":12.5,"data":"/wA=","created_at":"2026-03-01T09:30:00Z"}`+"\n"+
+			`{"id":2,"title":"draft","amount":null,"data":null,"created_at":null}`+"\n",
+		buf.String())
+
+	buf.Reset()
+	require.NoError(t, db.Builder().Select("id", "title").From("reports").OrderBy("id").ExportJSON(&buf))
+	assert.Equal(t, `[{"id":1,"title":"Q1, \"final\""},{"id":2,"title":"draft"}]`+"\n", buf.String())
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Len(t, decoded, 2)
+
+	buf.Reset()
+	require.NoError(t, db.Builder().Select().From("reports").Where("id > ?", 99).ExportJSON(&buf))
+	assert.Equal(t, "[]\n", buf.String())
+
+	// Chunked queries export every chunk.
+	buf.Reset()
+	require.NoError(t, db.Builder().Select("id").From("reports").WhereInChunked("id", []int{1, 2}, 1).ExportNDJSON(&buf))
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestSelectQuery_ExportErrors(t *testing.T) {
+	db := setupExportDB(t)
+
+	assert.ErrorContains(t, db.Builder().Select().From("reports").ExportCSV(failingWriter{}, nil), "disk full")
+	assert.ErrorContains(t, db.Builder().Select().From("reports").ExportNDJSON(failingWriter{}), "disk full")
+	assert.ErrorContains(t, db.Builder().Select().From("missing").ExportJSON(&bytes.Buffer{}), "no such table")
+}
@@ -58,6 +58,34 @@ type Expression interface {
 	Build(dialect dialects.Dialect) (sql string, args []interface{})
 }
 
+// checkedExpression is implemented by expressions that only support some
+// dialects (e.g. geospatial functions).
+type checkedExpression interface {
+	checkDialect(dialect dialects.Dialect) error
+}
+
+// checkExpression returns the error of an expression that does not support dialect.
+// Builder methods report it as a build error instead of generating invalid SQL.
+func checkExpression(exp Expression, dialect dialects.Dialect) error {
+	if c, ok := exp.(checkedExpression); ok {
+		return c.checkDialect(dialect)
+	}
+	return nil
+}
+
+// checkValueExpressions returns the first error, by column, of the
+// Expression values of a SET or VALUES list that do not support dialect.
+func checkValueExpressions(values map[string]interface{}, dialect dialects.Dialect) error {
+	for _, col := range getKeys(values) {
+		if exp, ok := values[col].(Expression); ok {
+			if err := checkExpression(exp, dialect); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // RawExp represents a raw SQL expression with optional parameter bindings.
 // Use this when you need to embed custom SQL that isn't covered by other expression types.
 //
@@ -79,6 +107,18 @@ func NewExp(sql string, args ...interface{}) Expression {
 	}
 }
 
+// RawOrder returns a raw ORDER BY or GROUP BY term for OrderBySub and
+// GroupBySub. The SQL, including any ASC/DESC, is emitted as-is without
+// quoting; ? placeholders are bound to args.
+//
+// Example:
+//
+//	db.Select().From("users").OrderBySub(relica.RawOrder("LOWER(name) DESC"))
+//	db.Select("DATE(created_at)", "COUNT(*)").From("orders").GroupBySub(relica.RawOrder("DATE(created_at)"))
+func RawOrder(sql string, args ...interface{}) Expression {
+	return NewExp(sql, args...)
+}
+
 // Build converts the raw expression into a SQL fragment.
 // The SQL string is returned as-is, with args passed through unchanged.
 // Placeholder conversion (? → $1, $2, etc.) happens at the query builder level.
@@ -223,6 +263,12 @@ func (e *CompareExp) Build(dialect dialects.Dialect) (string, []interface{}) {
 		}
 	}
 
+	// Handle scalar subqueries: col > (SELECT ...)
+	if sub, ok := e.Value.(*ScalarSubqueryExp); ok {
+		sql, args := sub.operand(dialect)
+		return col + " " + e.Operator + " " + sql, args
+	}
+
 	// Handle Expression values
 	if expr, ok := e.Value.(Expression); ok {
 		sql, args := expr.Build(dialect)
@@ -233,6 +279,64 @@ func (e *CompareExp) Build(dialect dialects.Dialect) (string, []interface{}) {
 	return col + " " + e.Operator + " ?", []interface{}{e.Value}
 }
 
+// checkDialect implements checkedExpression for comparisons against an
+// expression value, such as a scalar subquery.
+func (e *CompareExp) checkDialect(dialect dialects.Dialect) error {
+	if expr, ok := e.Value.(Expression); ok {
+		return checkExpression(expr, dialect)
+	}
+	return nil
+}
+
+// DistinctExp is a NULL-safe comparison: NULL is treated as an ordinary value,
+// so two NULLs are not distinct and NULL is distinct from any non-NULL value.
+//
+// Example:
+//
+//	IsDistinctFrom("manager_id", nil)     → "manager_id" IS DISTINCT FROM ?     (PostgreSQL, SQLite)
+//	IsNotDistinctFrom("manager_id", 5)    → `manager_id` <=> ?                  (MySQL)
+//	IsDistinctFrom("manager_id", 5)       → NOT (`manager_id` <=> ?)            (MySQL)
+type DistinctExp struct {
+	Col   string
+	Value interface{}
+	Not   bool // true for IS NOT DISTINCT FROM (NULL-safe equality)
+}
+
+// IsDistinctFrom generates a NULL-safe inequality expression
+// (column IS DISTINCT FROM value; NOT (column <=> value) on MySQL).
+func IsDistinctFrom(col string, value interface{}) Expression {
+	return &DistinctExp{Col: col, Value: value}
+}
+
+// IsNotDistinctFrom generates a NULL-safe equality expression
+// (column IS NOT DISTINCT FROM value; column <=> value on MySQL).
+func IsNotDistinctFrom(col string, value interface{}) Expression {
+	return &DistinctExp{Col: col, Value: value, Not: true}
+}
+
+// Build converts a DistinctExp into a SQL fragment for the dialect.
+func (e *DistinctExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	col := quoteColumn(e.Col, dialect)
+
+	value, args := "?", []interface{}{e.Value}
+	if expr, ok := e.Value.(Expression); ok {
+		var sql string
+		sql, args = expr.Build(dialect)
+		value = "(" + sql + ")"
+	}
+
+	if _, ok := dialect.(*dialects.MySQLDialect); ok {
+		if e.Not {
+			return col + " <=> " + value, args
+		}
+		return "NOT (" + col + " <=> " + value + ")", args
+	}
+	if e.Not {
+		return col + " IS NOT DISTINCT FROM " + value, args
+	}
+	return col + " IS DISTINCT FROM " + value, args
+}
+
 // ColumnCompareExp compares two column identifiers with an operator.
 // Both sides are quoted using the dialect's identifier quoting (dots split per part).
 //
@@ -297,7 +401,7 @@ func NotIn(col string, values ...interface{}) Expression {
 // selectQueryBuilder is an interface to avoid circular imports.
 // It represents types that can build SQL queries (like SelectQuery).
 type selectQueryBuilder interface {
-	buildSQL(dialect dialects.Dialect) (string, []interface{})
+	fragmentSQL(dialect dialects.Dialect) (string, []interface{})
 }
 
 // buildSubqueryIN builds an IN/NOT IN clause with a subquery.
@@ -323,7 +427,7 @@ func buildSubqueryIN(col, subSQL string, subArgs []interface{}, not bool) (strin
 func buildInExpSingleValue(col string, val interface{}, not bool, dialect dialects.Dialect) (string, []interface{}, bool) {
 	// Check if value is a SelectQuery (most common subquery case)
 	if sq, ok := val.(selectQueryBuilder); ok {
-		subSQL, subArgs := sq.buildSQL(dialect)
+		subSQL, subArgs := sq.fragmentSQL(dialect)
 		return buildSubqueryIN(col, subSQL, subArgs, not)
 	}
 
@@ -5,6 +5,8 @@ import (
 
 	"github.com/coregx/relica/internal/dialects"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
 )
 
 // Helper to create dialects for testing
@@ -1032,3 +1034,51 @@ func TestHashExp_AllDialects(t *testing.T) {
 		})
 	}
 }
+
+func TestDistinctExp_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		exp     Expression
+		want    string
+		args    []interface{}
+	}{
+		{"distinct postgres", "postgres", IsDistinctFrom("manager_id", 5), `"manager_id" IS DISTINCT FROM ?`, []interface{}{5}},
+		{"not distinct postgres", "postgres", IsNotDistinctFrom("u.manager_id", nil), `"u"."manager_id" IS NOT DISTINCT FROM ?`, []interface{}{nil}},
+		{"distinct sqlite", "sqlite", IsDistinctFrom("manager_id", nil), `"manager_id" IS DISTINCT FROM ?`, []interface{}{nil}},
+		{"distinct mysql", "mysql", IsDistinctFrom("manager_id", 5), "NOT (`manager_id` <=> ?)", []interface{}{5}},
+		{"not distinct mysql", "mysql", IsNotDistinctFrom("manager_id", 5), "`manager_id` <=> ?", []interface{}{5}},
+		{
+			"expression value", "postgres",
+			IsNotDistinctFrom("total", NewExp("SELECT MAX(total) FROM orders WHERE user_id = ?", 1)),
+			`"total" IS NOT DISTINCT FROM (SELECT MAX(total) FROM orders WHERE user_id = ?)`, []interface{}{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.exp.Build(dialects.GetDialect(tt.dialect))
+			assert.Equal(t, tt.want, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestDistinctExp_SQLite(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("CREATE TABLE emp (id INTEGER PRIMARY KEY, manager_id INTEGER)").Execute()
+	require.NoError(t, err)
+	_, err = db.NewQuery("INSERT INTO emp (id, manager_id) VALUES (1, NULL), (2, 1), (3, 2)").Execute()
+	require.NoError(t, err)
+
+	var ids []int
+	require.NoError(t, db.Builder().Select("id").From("emp").Where(IsNotDistinctFrom("manager_id", nil)).Column(&ids))
+	assert.Equal(t, []int{1}, ids)
+
+	ids = nil
+	require.NoError(t, db.Builder().Select("id").From("emp").Where(IsDistinctFrom("manager_id", 1)).OrderBy("id").Column(&ids))
+	assert.Equal(t, []int{1, 3}, ids)
+}
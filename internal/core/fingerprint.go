@@ -0,0 +1,103 @@
+package core
+
+import (
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ============================================================================
+// Query fingerprints
+// ============================================================================
+//
+// A fingerprint identifies the logical query behind a SQL string: literals
+// and placeholders become ?, IN lists and multi-row VALUES collapse to one
+// element, comments (including sqlcommenter tags) are dropped and whitespace
+// and case are folded. The same query with other values, list sizes or
+// formatting gets the same fingerprint. Relica uses it to group the
+// statements of the optimizer store, slow query logs and query metrics.
+
+var (
+	// fingerprintStringRegex matches SQL string literals.
+	fingerprintStringRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+	// fingerprintCommentRegex matches block and line comments.
+	fingerprintCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/|--[^\n]*`)
+
+	// fingerprintNumberRegex matches numeric literals and $n placeholders
+	// (not digits inside identifiers).
+	fingerprintNumberRegex = regexp.MustCompile(`(^|[^\w.])\$?\d+(?:\.\d+)?\b`)
+
+	// fingerprintListRegex matches lists of placeholders such as IN (?, ?, ?).
+	fingerprintListRegex = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+
+	// fingerprintRowsRegex matches lists of rows such as VALUES (?), (?).
+	fingerprintRowsRegex = regexp.MustCompile(`\(\?\)(?:\s*,\s*\(\?\))+`)
+
+	// fingerprintSpaceRegex matches runs of whitespace.
+	fingerprintSpaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// maxFingerprintCache bounds the number of SQL strings whose fingerprint is cached.
+const maxFingerprintCache = 10000
+
+var (
+	fingerprintCache     sync.Map // SQL -> [2]string{fingerprint, normalized SQL}
+	fingerprintCacheSize atomic.Int64
+)
+
+// Fingerprint returns a stable identifier of the logical query of sql: a
+// hexadecimal hash of NormalizeSQL(sql). Slow query logs carry it as the
+// fingerprint field, so log lines of the same query can be grouped.
+//
+// Example:
+//
+//	Fingerprint("SELECT * FROM users WHERE id IN (1, 2, 3)") ==
+//	    Fingerprint("select * from users where id in ($1, $2)")
+func Fingerprint(sql string) string {
+	fp, _ := queryFingerprint(sql)
+	return fp
+}
+
+// NormalizeSQL returns sql with literals and placeholders replaced by ?,
+// IN lists and multi-row VALUES collapsed, comments removed, and whitespace
+// and case folded, the text Fingerprint hashes.
+//
+// Example:
+//
+//	NormalizeSQL("SELECT * FROM users /* app='api' */ WHERE name = 'ann' AND id IN (?, ?)")
+//	// "select * from users where name = ? and id in (?)"
+func NormalizeSQL(sql string) string {
+	_, normalized := queryFingerprint(sql)
+	return normalized
+}
+
+// queryFingerprint returns the fingerprint and normalized SQL of query.
+// Results are cached for the first maxFingerprintCache distinct statements.
+func queryFingerprint(query string) (string, string) {
+	if fp, ok := fingerprintCache.Load(query); ok {
+		pair := fp.([2]string)
+		return pair[0], pair[1]
+	}
+
+	normalized := fingerprintStringRegex.ReplaceAllString(query, "?")
+	normalized = fingerprintCommentRegex.ReplaceAllString(normalized, " ")
+	normalized = fingerprintNumberRegex.ReplaceAllString(normalized, "${1}?")
+	normalized = fingerprintListRegex.ReplaceAllString(normalized, "(?)")
+	normalized = fingerprintRowsRegex.ReplaceAllString(normalized, "(?)")
+	normalized = strings.ToLower(strings.TrimSpace(fingerprintSpaceRegex.ReplaceAllString(normalized, " ")))
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(normalized))
+	fp := strconv.FormatUint(h.Sum64(), 16)
+
+	if fingerprintCacheSize.Load() < maxFingerprintCache {
+		if _, loaded := fingerprintCache.LoadOrStore(query, [2]string{fp, normalized}); !loaded {
+			fingerprintCacheSize.Add(1)
+		}
+	}
+	return fp, normalized
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFingerprint(t *testing.T) {
+	fp1, normalized := queryFingerprint("SELECT * FROM users WHERE id = 42 AND name = 'O''Brien'")
+	assert.Equal(t, "select * from users where id = ? and name = ?", normalized)
+
+	fp2, _ := queryFingerprint("select *  from users\n WHERE id = $1 AND name = $2")
+	assert.Equal(t, fp1, fp2, "literals, placeholders, case and spacing are folded")
+
+	_, normalized = queryFingerprint("SELECT * FROM t1 WHERE id IN (?, ?, ?)")
+	assert.Equal(t, "select * from t1 where id in (?)", normalized, "placeholder lists collapse; digits in names stay")
+
+	fp3, _ := queryFingerprint("SELECT * FROM orders WHERE id = 1")
+	assert.NotEqual(t, fp1, fp3)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t,
+		Fingerprint("SELECT * FROM users WHERE id IN (1, 2, 3)"),
+		Fingerprint("select * from users where id in ($1, $2)"))
+	assert.Equal(t,
+		Fingerprint(`INSERT INTO "logs" ("msg") VALUES (?), (?), (?)`),
+		Fingerprint(`INSERT INTO "logs" ("msg") VALUES ('a')`),
+		"multi-row VALUES collapse")
+	assert.Equal(t,
+		Fingerprint("SELECT 1 /*application='api',route='%2Fusers'*/"),
+		Fingerprint("SELECT 2 -- warm-up"),
+		"comments are dropped")
+	assert.NotEqual(t, Fingerprint("SELECT * FROM users"), Fingerprint("SELECT * FROM orders"))
+
+	assert.Equal(t, "select * from users where name = ? and id in (?)",
+		NormalizeSQL("SELECT * FROM users /* app='api' */ WHERE name = 'ann' AND id IN (?, ?)"))
+}
+
+// countingOptimizer counts Analyze calls.
+type countingOptimizer struct{ calls atomic.Int32 }
+
+func (o *countingOptimizer) Analyze(context.Context, string, []interface{}, time.Duration) (interface{}, error) {
+	o.calls.Add(1)
+	return nil, nil
+}
+
+func (o *countingOptimizer) Suggest(interface{}) []interface{} { return nil }
+
+func TestOptimizer_AnalyzesFingerprintOnce(t *testing.T) {
+	opt := &countingOptimizer{}
+	db, err := Open("sqlite", ":memory:", WithOptimizer(opt))
+	require.NoError(t, err)
+	db.sqlDB.SetMaxOpenConns(1)
+
+	type row struct {
+		ID int `db:"id"`
+	}
+	var rows []row
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.NewQuery("SELECT ? AS id", i).All(&rows))
+	}
+	require.NoError(t, db.NewQuery("SELECT   ? AS id -- again", 9).All(&rows))
+	var r row
+	require.NoError(t, db.NewQuery("SELECT 1 + ? AS id", 1).One(&r))
+	require.NoError(t, db.Shutdown(context.Background()), "waits for the analyses")
+
+	assert.Equal(t, int32(2), opt.calls.Load(), "one analysis per fingerprint")
+}
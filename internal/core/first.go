@@ -0,0 +1,175 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coregx/relica/internal/util"
+)
+
+// ============================================================================
+// First, Last and Take
+// ============================================================================
+//
+// First, Last and Take fetch a single row without spelling out
+// OrderBy/Limit(1)/One. They run on a copy, so the query can be reused.
+//
+// Without an ORDER BY of its own, First orders by the primary key of dest
+// (found like in Model: pk tags, then the ID field) and Last by the same key
+// descending. With an ORDER BY, First keeps it and Last reverses it. Take adds
+// no ordering at all. Like One, all three return sql.ErrNoRows when nothing
+// matches.
+
+// First scans the first row into dest: by the query's ORDER BY, or else by
+// the primary key of dest ascending.
+//
+// Example:
+//
+//	var u User
+//	err := db.Select().From("users").Where("status = ?", "active").First(&u)
+//	// SELECT * FROM "users" WHERE status = $1 ORDER BY "id" LIMIT 1
+func (sq *SelectQuery) First(dest interface{}) error {
+	c, err := sq.singleRow("First", dest, false)
+	if err != nil {
+		return err
+	}
+	return c.One(dest)
+}
+
+// Last scans the last row into dest: by the query's ORDER BY reversed, or
+// else by the primary key of dest descending. Only plain column terms
+// ("created_at", "age DESC NULLS LAST") can be reversed.
+//
+// Example:
+//
+//	var e Event
+//	err := db.Select().From("events").Where("user_id = ?", 7).Last(&e)
+//	// SELECT * FROM "events" WHERE user_id = $1 ORDER BY "id" DESC LIMIT 1
+func (sq *SelectQuery) Last(dest interface{}) error {
+	c, err := sq.singleRow("Last", dest, true)
+	if err != nil {
+		return err
+	}
+	return c.One(dest)
+}
+
+// Take scans any one row into dest, with LIMIT 1 but no implicit ordering.
+//
+// Example:
+//
+//	var u User
+//	err := db.Select().From("users").Where("email = ?", email).Take(&u)
+func (sq *SelectQuery) Take(dest interface{}) error {
+	c := sq.Clone()
+	one := int64(1)
+	if len(c.unions) > 0 {
+		c.unionLimit = &one
+	} else {
+		c.limitValue = &one
+	}
+	return c.One(dest)
+}
+
+// singleRow returns a copy of sq ordered for First (last = false) or Last
+// (last = true) and limited to one row.
+func (sq *SelectQuery) singleRow(method string, dest interface{}, last bool) (*SelectQuery, error) {
+	if len(sq.unions) > 0 {
+		return nil, fmt.Errorf("relica: %s() cannot be combined with set operations; use OrderBy and Take", method)
+	}
+	c := sq.Clone()
+	one := int64(1)
+	c.limitValue = &one
+
+	if len(c.orderBy) == 0 && len(c.orderByExprs) == 0 && len(c.subOrderByExprs) == 0 {
+		columns, err := primaryKeyColumns(dest)
+		if err != nil {
+			return nil, fmt.Errorf("relica: %s() without OrderBy requires a struct with a primary key: %w", method, err)
+		}
+		qualifier := ""
+		if len(c.joins) > 0 {
+			qualifier = c.fromQualifier()
+		}
+		for _, col := range columns {
+			if qualifier != "" && !strings.Contains(col, ".") {
+				col = qualifier + "." + col
+			}
+			if last {
+				col += " DESC"
+			}
+			c.orderBy = append(c.orderBy, col)
+		}
+		return c, nil
+	}
+
+	if !last {
+		return c, nil
+	}
+	if len(c.orderByExprs) > 0 || len(c.subOrderByExprs) > 0 {
+		return nil, fmt.Errorf("relica: Last() cannot reverse ORDER BY expressions; use OrderBy and Take")
+	}
+	for i, term := range c.orderBy {
+		reversed, ok := reverseOrderTerm(term)
+		if !ok {
+			return nil, fmt.Errorf("relica: Last() cannot reverse ORDER BY term %q; use OrderBy and Take", term)
+		}
+		c.orderBy[i] = reversed
+	}
+	return c, nil
+}
+
+// primaryKeyColumns returns the primary key columns of the struct dest
+// points to.
+func primaryKeyColumns(dest interface{}) ([]string, error) {
+	t := reflect.TypeOf(dest)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("got %T", dest)
+	}
+	pk, err := util.FindPrimaryKeyFields(reflect.New(t).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return pk.Columns, nil
+}
+
+// fromQualifier returns the name that qualifies columns of the FROM table:
+// its alias if it has one, else the table name.
+func (sq *SelectQuery) fromQualifier() string {
+	table := sq.table
+	if sq.fromSrc != nil {
+		if sq.fromSrc.isSubquery {
+			return sq.fromSrc.alias
+		}
+		table = sq.fromSrc.table
+	}
+	parts := strings.Fields(table)
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
+
+// reverseOrderTerm flips the direction of a plain ORDER BY term, including
+// the placement of NULLs, so the reversed order is the exact mirror image.
+func reverseOrderTerm(term string) (string, bool) {
+	m := orderTermRegex.FindStringSubmatch(strings.TrimSpace(term))
+	if m == nil {
+		return "", false
+	}
+	reversed := m[1]
+	if strings.EqualFold(m[2], "DESC") {
+		reversed += " ASC"
+	} else {
+		reversed += " DESC"
+	}
+	switch strings.ToUpper(m[3]) {
+	case "FIRST":
+		reversed += " NULLS LAST"
+	case "LAST":
+		reversed += " NULLS FIRST"
+	}
+	return reversed, true
+}
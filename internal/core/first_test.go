@@ -0,0 +1,71 @@
+package core
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type firstPlayer struct {
+	ID    int     `db:"id"`
+	Name  *string `db:"name"`
+	Score *int    `db:"score"`
+}
+
+type firstMembership struct {
+	GroupID int `db:"group_id,pk"`
+	UserID  int `db:"user_id,pk"`
+}
+
+func TestFirstLastTake(t *testing.T) {
+	db := setupNullColumnDB(t)
+	players := func() *SelectQuery { return db.Builder().Select().From("players") }
+
+	var p firstPlayer
+	require.NoError(t, players().First(&p))
+	assert.Equal(t, 1, p.ID)
+	require.NoError(t, players().Last(&p))
+	assert.Equal(t, 3, p.ID)
+
+	require.NoError(t, players().OrderBy("name DESC NULLS LAST").First(&p))
+	assert.Equal(t, "cy", *p.Name)
+	require.NoError(t, players().OrderBy("name DESC NULLS LAST").Last(&p))
+	assert.Equal(t, 2, p.ID, "NULL placement is reversed too")
+
+	require.NoError(t, players().Where(Eq("id", 2)).Take(&p))
+	assert.Equal(t, 2, p.ID)
+	assert.ErrorIs(t, players().Where(Eq("id", 9)).First(&p), sql.ErrNoRows)
+
+	q := players().Where("score > ?", 5)
+	require.NoError(t, q.Last(&p))
+	var all []firstPlayer
+	require.NoError(t, q.All(&all))
+	assert.Len(t, all, 2, "the query is not changed")
+}
+
+func TestFirstLast_SQL(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+	build := func(sq *SelectQuery, dest interface{}, last bool) string {
+		c, err := sq.singleRow("First", dest, last)
+		require.NoError(t, err)
+		return c.Build().SQL()
+	}
+
+	assert.Equal(t, `SELECT * FROM "players" ORDER BY "id" LIMIT 1`,
+		build(qb.Select().From("players"), &firstPlayer{}, false))
+	assert.Equal(t, `SELECT * FROM "memberships" ORDER BY "group_id" DESC, "user_id" DESC LIMIT 1`,
+		build(qb.Select().From("memberships"), &firstMembership{}, true))
+	assert.Equal(t, `SELECT p.* FROM "players" AS "p" INNER JOIN "teams" AS "t" ON t.id = p.team_id ORDER BY "p"."id" LIMIT 1`,
+		build(qb.Select("p.*").From("players p").InnerJoin("teams t", "t.id = p.team_id"), &firstPlayer{}, false))
+	assert.Equal(t, `SELECT * FROM "players" ORDER BY "score" ASC, "name" DESC LIMIT 1`,
+		build(qb.Select().From("players").OrderBy("score DESC", "name"), &firstPlayer{}, true))
+
+	var n int
+	assert.ErrorContains(t, qb.Select().From("players").First(&n), "First() without OrderBy requires a struct with a primary key")
+	assert.ErrorContains(t, qb.Select().From("players").OrderBy("LOWER(name)").Last(&n), `cannot reverse ORDER BY term "LOWER(name)"`)
+	assert.ErrorContains(t, qb.Select().From("players").OrderByExpr("RANDOM()").Last(&n), "cannot reverse ORDER BY expressions")
+	assert.ErrorContains(t, qb.Select().From("a").Union(qb.Select().From("b")).First(&n), "cannot be combined with set operations")
+}
@@ -0,0 +1,217 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// =============================================================================
+// Full-text search
+// =============================================================================
+//
+// Match and MatchRank render each dialect's native full-text search:
+//
+//   - PostgreSQL: to_tsvector(...) @@ plainto_tsquery(?), ranked with ts_rank
+//   - MySQL:      MATCH (cols) AGAINST (? IN NATURAL LANGUAGE MODE); requires a
+//     FULLTEXT index covering exactly the listed columns
+//   - SQLite:     FTS5 "col" MATCH ?; the columns must belong to an FTS5 table,
+//     and with several columns all words must occur in the same column
+//
+// The search string is treated as plain words (all of which must match), never
+// as query syntax, so user input can be passed directly.
+
+// ftsConfigRegex validates PostgreSQL text search configuration names.
+var ftsConfigRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// MatchExp is a full-text search condition or relevance expression.
+type MatchExp struct {
+	columns  []string
+	query    string
+	rank     bool
+	language string // PostgreSQL text search configuration (e.g. "english")
+	alias    string
+	desc     bool
+	err      error
+}
+
+// Match creates a full-text search condition over the comma-separated columns.
+//
+// Example:
+//
+//	db.Builder().Select().From("articles").
+//	    Where(relica.Match("title,body", "connection pooling")).
+//	    OrderBySub(relica.MatchRank("title,body", "connection pooling").Desc()).
+//	    All(&articles)
+//
+// Generates:
+//
+//	PostgreSQL: to_tsvector(coalesce("title", '') || ' ' || coalesce("body", '')) @@ plainto_tsquery($1)
+//	MySQL:      MATCH (`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)
+//	SQLite:     ("title" MATCH ? OR "body" MATCH ?)
+func Match(columns, query string) *MatchExp {
+	m := &MatchExp{query: query}
+	for _, col := range strings.Split(columns, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			m.columns = append(m.columns, col)
+		}
+	}
+	if len(m.columns) == 0 {
+		m.err = fmt.Errorf("relica: Match requires at least one column")
+	}
+	return m
+}
+
+// MatchRank creates a relevance expression for ORDER BY or SELECT lists;
+// higher values are more relevant.
+//
+// On SQLite it renders the negated FTS5 rank of the table the columns belong
+// to, which is only meaningful together with a Match condition on the same
+// query; query is not used there.
+//
+// Generates:
+//
+//	PostgreSQL: ts_rank(to_tsvector(...), plainto_tsquery($1))
+//	MySQL:      MATCH (`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)
+//	SQLite:     -"rank"
+func MatchRank(columns, query string) *MatchExp {
+	m := Match(columns, query)
+	m.rank = true
+	return m
+}
+
+// Language sets the PostgreSQL text search configuration (e.g. "english").
+// Other dialects use the configuration of the full-text index.
+func (m *MatchExp) Language(config string) *MatchExp {
+	if !ftsConfigRegex.MatchString(config) {
+		m.err = fmt.Errorf("relica: Match: invalid text search configuration %q", config)
+	}
+	m.language = config
+	return m
+}
+
+// As sets an alias for the relevance expression (SELECT lists only).
+func (m *MatchExp) As(alias string) *MatchExp {
+	m.alias = alias
+	return m
+}
+
+// Desc orders by the relevance expression in descending order, most relevant
+// first (ORDER BY lists only).
+func (m *MatchExp) Desc() *MatchExp {
+	m.desc = true
+	return m
+}
+
+// Err returns any programming error stored during construction.
+func (m *MatchExp) Err() error {
+	return m.err
+}
+
+// checkDialect implements checkedExpression: it reports the programming error
+// stored during construction, so a builder fails instead of dropping a
+// condition that builds to empty SQL.
+func (m *MatchExp) checkDialect(dialects.Dialect) error {
+	return m.err
+}
+
+// Build implements the Expression interface.
+// Returns empty SQL and nil args if a programming error was stored during construction.
+// A search string without words matches no rows.
+func (m *MatchExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	if m.err != nil {
+		return "", nil
+	}
+
+	var sql string
+	var args []interface{}
+	switch dialect.(type) {
+	case *dialects.MySQLDialect:
+		sql, args = m.buildMySQL(dialect)
+	case *dialects.SQLiteDialect:
+		sql, args = m.buildSQLite(dialect)
+	default:
+		sql, args = m.buildPostgres(dialect)
+	}
+	if len(strings.Fields(m.query)) == 0 {
+		sql, args = "1 = 0", nil
+		if m.rank {
+			sql = "0"
+		}
+	}
+
+	if m.alias != "" {
+		sql += " AS " + dialect.QuoteIdentifier(m.alias)
+	}
+	if m.desc {
+		sql += " DESC"
+	}
+	return sql, args
+}
+
+func (m *MatchExp) buildPostgres(dialect dialects.Dialect) (string, []interface{}) {
+	var doc string
+	if len(m.columns) == 1 {
+		doc = quoteColumn(m.columns[0], dialect)
+	} else {
+		parts := make([]string, len(m.columns))
+		for i, col := range m.columns {
+			parts[i] = "coalesce(" + quoteColumn(col, dialect) + ", '')"
+		}
+		doc = strings.Join(parts, " || ' ' || ")
+	}
+
+	config := ""
+	if m.language != "" {
+		config = "'" + m.language + "', "
+	}
+	vector := "to_tsvector(" + config + doc + ")"
+	query := "plainto_tsquery(" + config + "?)"
+
+	if m.rank {
+		return "ts_rank(" + vector + ", " + query + ")", []interface{}{m.query}
+	}
+	return vector + " @@ " + query, []interface{}{m.query}
+}
+
+func (m *MatchExp) buildMySQL(dialect dialects.Dialect) (string, []interface{}) {
+	cols := make([]string, len(m.columns))
+	for i, col := range m.columns {
+		cols[i] = quoteColumn(col, dialect)
+	}
+	return "MATCH (" + strings.Join(cols, ", ") + ") AGAINST (? IN NATURAL LANGUAGE MODE)", []interface{}{m.query}
+}
+
+func (m *MatchExp) buildSQLite(dialect dialects.Dialect) (string, []interface{}) {
+	if m.rank {
+		col := "rank"
+		if i := strings.LastIndex(m.columns[0], "."); i >= 0 {
+			col = m.columns[0][:i+1] + col
+		}
+		return "-" + quoteColumn(col, dialect), nil
+	}
+
+	query := fts5Phrases(m.query)
+	parts := make([]string, len(m.columns))
+	args := make([]interface{}, len(m.columns))
+	for i, col := range m.columns {
+		parts[i] = quoteColumn(col, dialect) + " MATCH ?"
+		args[i] = query
+	}
+	if len(parts) == 1 {
+		return parts[0], args
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// fts5Phrases quotes every word of s as an FTS5 string, so that operators and
+// punctuation in user input are matched literally: `a-b "c"` → `"a-b" """c"""`.
+func fts5Phrases(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
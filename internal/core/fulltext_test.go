@@ -0,0 +1,132 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestMatchExp_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		exp     Expression
+		want    string
+		args    []interface{}
+	}{
+		{
+			"postgres single", "postgres", Match("title", "go sql"),
+			`to_tsvector("title") @@ plainto_tsquery(?)`, []interface{}{"go sql"},
+		},
+		{
+			"postgres multi with language", "postgres", Match("a.title, a.body", "go").Language("english"),
+			`to_tsvector('english', coalesce("a"."title", '') || ' ' || coalesce("a"."body", '')) @@ plainto_tsquery('english', ?)`,
+			[]interface{}{"go"},
+		},
+		{
+			"postgres rank", "postgres", MatchRank("title,body", "go").As("score"),
+			`ts_rank(to_tsvector(coalesce("title", '') || ' ' || coalesce("body", '')), plainto_tsquery(?)) AS "score"`,
+			[]interface{}{"go"},
+		},
+		{
+			"mysql", "mysql", Match("title,body", "go"),
+			"MATCH (`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)", []interface{}{"go"},
+		},
+		{
+			"mysql rank desc", "mysql", MatchRank("title,body", "go").Desc(),
+			"MATCH (`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE) DESC", []interface{}{"go"},
+		},
+		{
+			"sqlite single", "sqlite", Match("title", `go "fast"`),
+			`"title" MATCH ?`, []interface{}{`"go" """fast"""`},
+		},
+		{
+			"sqlite multi", "sqlite", Match("title,body", "go-lang"),
+			`("title" MATCH ? OR "body" MATCH ?)`, []interface{}{`"go-lang"`, `"go-lang"`},
+		},
+		{"sqlite rank", "sqlite", MatchRank("d.title", "go").Desc(), `-"d"."rank" DESC`, nil},
+		{"empty query", "postgres", Match("title", "  "), "1 = 0", nil},
+		{"empty query rank", "mysql", MatchRank("title", ""), "0", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.exp.Build(dialects.GetDialect(tt.dialect))
+			assert.Equal(t, tt.want, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestMatchExp_Errors(t *testing.T) {
+	d := dialects.GetDialect("postgres")
+
+	m := Match(" , ", "go")
+	require.Error(t, m.Err())
+	sql, args := m.Build(d)
+	assert.Empty(t, sql)
+	assert.Nil(t, args)
+
+	m = Match("title", "go").Language("english'); DROP TABLE x; --")
+	require.Error(t, m.Err())
+	sql, _ = m.Build(d)
+	assert.Empty(t, sql)
+
+	assert.NoError(t, Match("title", "go").Language("pg_catalog.simple").Err())
+}
+
+func TestMatchExp_InvalidInWhere(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Delete("posts").Where(Match("title", "spam").Language("english'); --")).Build()
+	require.Error(t, q.prepErr, "an invalid Match must not drop the DELETE condition")
+	assert.ErrorContains(t, q.prepErr, "invalid text search configuration")
+
+	q = qb.Select().From("posts").Where(Match(" , ", "spam")).Build()
+	assert.Error(t, q.prepErr)
+}
+
+func TestMatchExp_SQLiteFTS5(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("CREATE VIRTUAL TABLE docs USING fts5(title, body)").Execute()
+	require.NoError(t, err)
+	_, err = db.NewQuery(`INSERT INTO docs (title, body) VALUES
+		('go sql', 'query builder for go'),
+		('rust', 'go fast'),
+		('cats', 'dogs and "quotes"')`).Execute()
+	require.NoError(t, err)
+
+	var titles []string
+	err = db.Builder().Select("title").From("docs").
+		Where(Match("title,body", "go")).
+		OrderBySub(MatchRank("title,body", "go").Desc()).
+		Column(&titles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go sql", "rust"}, titles)
+
+	// Query syntax in user input is matched literally.
+	titles = nil
+	err = db.Builder().Select("title").From("docs").Where(Match("body", `quotes OR fast`)).Column(&titles)
+	require.NoError(t, err)
+	assert.Empty(t, titles)
+
+	titles = nil
+	err = db.Builder().Select("title").From("docs").Where(Match("body", `"quotes"`)).Column(&titles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cats"}, titles)
+
+	// An invalid Match fails Count and Exists instead of matching every row.
+	invalid := db.Builder().Select().From("docs").Where(Match(" , ", "go"))
+	n, err := invalid.Count()
+	require.Error(t, err)
+	assert.Zero(t, n)
+	exists, err := invalid.Exists()
+	require.Error(t, err)
+	assert.False(t, exists)
+}
@@ -6,6 +6,7 @@ package core
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/coregx/relica/internal/dialects"
@@ -26,13 +27,15 @@ type CaseExp struct {
 
 // whenClause represents a single WHEN clause in a CASE expression.
 type whenClause struct {
-	condition interface{} // For simple CASE: value to match; for searched: condition string
+	condition interface{} // For simple CASE: value to match; for searched: condition string or Expression
 	result    interface{} // THEN result
 }
 
-// Case creates a simple CASE expression.
+// Case creates a CASE expression.
+// With a column argument it creates a simple CASE; without arguments it creates
+// a searched CASE whose conditions are typically Expressions.
 //
-// Example:
+// Simple CASE example:
 //
 //	relica.Case("status").
 //	    When("active", 1).
@@ -41,8 +44,21 @@ type whenClause struct {
 //	    As("status_code")
 //
 // Generates: CASE "status" WHEN 'active' THEN 1 WHEN 'inactive' THEN 0 ELSE -1 END AS "status_code"
-func Case(column string) *CaseExp {
-	return &CaseExp{column: column}
+//
+// Searched CASE example:
+//
+//	relica.Case().
+//	    When(relica.Eq("status", 1), "active").
+//	    When(relica.Eq("status", 2), "banned").
+//	    Else("unknown").
+//	    As("status_label")
+//
+// Generates: CASE WHEN "status" = ? THEN ? WHEN "status" = ? THEN ? ELSE ? END AS "status_label"
+func Case(column ...string) *CaseExp {
+	if len(column) == 0 {
+		return &CaseExp{}
+	}
+	return &CaseExp{column: column[0]}
 }
 
 // CaseWhen creates a searched CASE expression (without column).
@@ -64,6 +80,9 @@ func CaseWhen() *CaseExp {
 }
 
 // When adds a WHEN clause to the CASE expression.
+// For a searched CASE the condition may be an Expression (recommended) or a raw SQL string.
+// Expression conditions and results are built with the query dialect and their
+// parameters are collected in order; any other result value is bound as a parameter.
 func (c *CaseExp) When(condition, result interface{}) *CaseExp {
 	c.whens = append(c.whens, whenClause{condition: condition, result: result})
 	return c
@@ -76,6 +95,8 @@ func (c *CaseExp) Else(value interface{}) *CaseExp {
 }
 
 // As sets an alias for the CASE expression.
+// The alias is only meaningful in a SELECT list; leave it unset when the
+// expression is used in ORDER BY, GROUP BY or an UPDATE SET clause.
 func (c *CaseExp) As(alias string) *CaseExp {
 	c.alias = alias
 	return c
@@ -105,23 +126,31 @@ func (c *CaseExp) Build(dialect dialects.Dialect) (string, []interface{}) {
 	for _, when := range c.whens {
 		sql.WriteString(" WHEN ")
 
-		if c.column != "" {
-			// Simple CASE: WHEN value
-			sql.WriteString("?")
-			args = append(args, when.condition)
-		} else {
-			// Searched CASE: WHEN condition (raw SQL)
-			fmt.Fprint(&sql, when.condition)
+		switch cond := when.condition.(type) {
+		case Expression:
+			// Type-safe condition (searched CASE) or computed match value (simple CASE)
+			condSQL, condArgs := cond.Build(dialect)
+			sql.WriteString(condSQL)
+			args = append(args, condArgs...)
+		default:
+			if c.column != "" {
+				// Simple CASE: WHEN value
+				sql.WriteString("?")
+				args = append(args, when.condition)
+			} else {
+				// Searched CASE: WHEN condition (raw SQL)
+				fmt.Fprint(&sql, when.condition)
+			}
 		}
 
-		sql.WriteString(" THEN ?")
-		args = append(args, when.result)
+		sql.WriteString(" THEN ")
+		args = writeCaseValue(&sql, when.result, args, dialect)
 	}
 
 	// ELSE clause
 	if c.elseValue != nil {
-		sql.WriteString(" ELSE ?")
-		args = append(args, c.elseValue)
+		sql.WriteString(" ELSE ")
+		args = writeCaseValue(&sql, c.elseValue, args, dialect)
 	}
 
 	sql.WriteString(" END")
@@ -135,6 +164,18 @@ func (c *CaseExp) Build(dialect dialects.Dialect) (string, []interface{}) {
 	return sql.String(), args
 }
 
+// writeCaseValue writes a THEN/ELSE value of a CASE expression.
+// Expressions are built inline; any other value is bound as a parameter.
+func writeCaseValue(sql *strings.Builder, value interface{}, args []interface{}, dialect dialects.Dialect) []interface{} {
+	if exp, ok := value.(Expression); ok {
+		expSQL, expArgs := exp.Build(dialect)
+		sql.WriteString(expSQL)
+		return append(args, expArgs...)
+	}
+	sql.WriteString("?")
+	return append(args, value)
+}
+
 // =============================================================================
 // COALESCE Expression
 // =============================================================================
@@ -163,6 +204,36 @@ func (c *CoalesceExp) As(alias string) *CoalesceExp {
 	return c
 }
 
+// Eq generates "COALESCE(...) = value" for use in WHERE/HAVING.
+func (c *CoalesceExp) Eq(value interface{}) Expression {
+	return &exprCompareExp{left: c, operator: "=", value: value}
+}
+
+// NotEq generates "COALESCE(...) <> value".
+func (c *CoalesceExp) NotEq(value interface{}) Expression {
+	return &exprCompareExp{left: c, operator: "<>", value: value}
+}
+
+// GreaterThan generates "COALESCE(...) > value".
+func (c *CoalesceExp) GreaterThan(value interface{}) Expression {
+	return &exprCompareExp{left: c, operator: ">", value: value}
+}
+
+// LessThan generates "COALESCE(...) < value".
+func (c *CoalesceExp) LessThan(value interface{}) Expression {
+	return &exprCompareExp{left: c, operator: "<", value: value}
+}
+
+// GreaterOrEqual generates "COALESCE(...) >= value".
+func (c *CoalesceExp) GreaterOrEqual(value interface{}) Expression {
+	return &exprCompareExp{left: c, operator: ">=", value: value}
+}
+
+// LessOrEqual generates "COALESCE(...) <= value".
+func (c *CoalesceExp) LessOrEqual(value interface{}) Expression {
+	return &exprCompareExp{left: c, operator: "<=", value: value}
+}
+
 // Build implements the Expression interface.
 func (c *CoalesceExp) Build(dialect dialects.Dialect) (string, []interface{}) {
 	if len(c.values) == 0 {
@@ -408,3 +479,252 @@ func (c *ConcatExp) Build(dialect dialects.Dialect) (string, []interface{}) {
 
 	return sql, args
 }
+
+// =============================================================================
+// Scalar function expressions (LOWER, UPPER, CAST, DATE_TRUNC)
+// =============================================================================
+
+// castTypeRegex validates CAST target types, which are interpolated into SQL.
+// Accepts names like "bigint", "double precision", "varchar(255)", "numeric(10, 2)", "text[]".
+var castTypeRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\(\s*\d+\s*(,\s*\d+\s*)?\))?(\[\])?$`)
+
+// dateTruncFormats maps DATE_TRUNC units to strftime-style formats used to emulate
+// truncation on MySQL (DATE_FORMAT) and SQLite (strftime).
+var dateTruncFormats = map[string]struct{ mysql, sqlite string }{
+	"year":   {"%Y-01-01 00:00:00", "%Y-01-01 00:00:00"},
+	"month":  {"%Y-%m-01 00:00:00", "%Y-%m-01 00:00:00"},
+	"day":    {"%Y-%m-%d 00:00:00", "%Y-%m-%d 00:00:00"},
+	"hour":   {"%Y-%m-%d %H:00:00", "%Y-%m-%d %H:00:00"},
+	"minute": {"%Y-%m-%d %H:%i:00", "%Y-%m-%d %H:%M:00"},
+	"second": {"%Y-%m-%d %H:%i:%s", "%Y-%m-%d %H:%M:%S"},
+}
+
+// FuncExp represents a scalar SQL function applied to a column or expression.
+// Function expressions compose: the argument may itself be an Expression
+// (e.g. Lower(Coalesce("nickname", "name"))). They can be used in SelectExp,
+// GroupBySub, OrderBySub, as UPDATE SET values, and in WHERE via the comparison methods.
+type FuncExp struct {
+	fn       string      // LOWER, UPPER, CAST, DATE_TRUNC
+	arg      interface{} // column name, quoted literal, Expression, or bound value
+	castType string      // target type for CAST
+	unit     string      // truncation unit for DATE_TRUNC
+	alias    string
+	err      error // stored programming error (invalid type or unit)
+}
+
+// Lower creates a LOWER(x) expression.
+// A string argument is treated as a column name; pass an Expression to compose.
+//
+// Example:
+//
+//	relica.Lower("email").Eq("alice@example.com")
+//
+// Generates: LOWER("email") = ?
+func Lower(arg interface{}) *FuncExp {
+	return &FuncExp{fn: "LOWER", arg: arg}
+}
+
+// Upper creates an UPPER(x) expression.
+// A string argument is treated as a column name; pass an Expression to compose.
+func Upper(arg interface{}) *FuncExp {
+	return &FuncExp{fn: "UPPER", arg: arg}
+}
+
+// Cast creates a CAST(x AS type) expression.
+// The type name is validated (letters, digits, spaces, optional precision and []);
+// an invalid type makes Build return an empty fragment and Err return the error.
+//
+// On MySQL, integer and text types are mapped to the types CAST accepts there
+// (bigint → SIGNED, text/varchar(n) → CHAR/CHAR(n)).
+//
+// Example:
+//
+//	relica.Cast("amount", "bigint").As("amount_int")
+//
+// PostgreSQL: CAST("amount" AS bigint) AS "amount_int"
+// MySQL:      CAST(`amount` AS SIGNED) AS `amount_int`
+func Cast(arg interface{}, typeName string) *FuncExp {
+	f := &FuncExp{fn: "CAST", arg: arg, castType: strings.TrimSpace(typeName)}
+	if !castTypeRegex.MatchString(f.castType) {
+		f.err = fmt.Errorf("relica: Cast: invalid type name %q", typeName)
+	}
+	return f
+}
+
+// DateTrunc creates a date truncation expression.
+// Supported units: year, month, day, hour, minute, second.
+//
+// Generated SQL per dialect:
+//   - PostgreSQL: DATE_TRUNC('day', "created_at")
+//   - MySQL:      DATE_FORMAT(`created_at`, '%Y-%m-%d 00:00:00')
+//   - SQLite:     strftime('%Y-%m-%d 00:00:00', "created_at")
+//
+// An unsupported unit makes Build return an empty fragment and Err return the error.
+//
+// Example:
+//
+//	db.Select("COUNT(*) AS cnt").
+//	    SelectExp(relica.DateTrunc("day", "created_at").As("day")).
+//	    From("orders").
+//	    GroupBySub(relica.DateTrunc("day", "created_at"))
+func DateTrunc(unit string, arg interface{}) *FuncExp {
+	f := &FuncExp{fn: "DATE_TRUNC", arg: arg, unit: strings.ToLower(strings.TrimSpace(unit))}
+	if _, ok := dateTruncFormats[f.unit]; !ok {
+		f.err = fmt.Errorf("relica: DateTrunc: unsupported unit %q", unit)
+	}
+	return f
+}
+
+// As sets an alias for the function expression (SELECT lists only).
+func (f *FuncExp) As(alias string) *FuncExp {
+	f.alias = alias
+	return f
+}
+
+// Err returns any programming error stored during construction (e.g. an invalid CAST type).
+func (f *FuncExp) Err() error {
+	return f.err
+}
+
+// Eq generates "fn(x) = value" (IS NULL for a nil value).
+func (f *FuncExp) Eq(value interface{}) Expression {
+	return &exprCompareExp{left: f, operator: "=", value: value}
+}
+
+// NotEq generates "fn(x) <> value" (IS NOT NULL for a nil value).
+func (f *FuncExp) NotEq(value interface{}) Expression {
+	return &exprCompareExp{left: f, operator: "<>", value: value}
+}
+
+// GreaterThan generates "fn(x) > value".
+func (f *FuncExp) GreaterThan(value interface{}) Expression {
+	return &exprCompareExp{left: f, operator: ">", value: value}
+}
+
+// LessThan generates "fn(x) < value".
+func (f *FuncExp) LessThan(value interface{}) Expression {
+	return &exprCompareExp{left: f, operator: "<", value: value}
+}
+
+// GreaterOrEqual generates "fn(x) >= value".
+func (f *FuncExp) GreaterOrEqual(value interface{}) Expression {
+	return &exprCompareExp{left: f, operator: ">=", value: value}
+}
+
+// LessOrEqual generates "fn(x) <= value".
+func (f *FuncExp) LessOrEqual(value interface{}) Expression {
+	return &exprCompareExp{left: f, operator: "<=", value: value}
+}
+
+// Build implements the Expression interface.
+// Returns empty SQL and nil args if a programming error was stored during construction.
+func (f *FuncExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	if f.err != nil {
+		return "", nil
+	}
+
+	argSQL, args := buildExprValue(f.arg, dialect)
+
+	var sql string
+	switch f.fn {
+	case "CAST":
+		sql = "CAST(" + argSQL + " AS " + castTypeFor(f.castType, dialect) + ")"
+	case "DATE_TRUNC":
+		formats := dateTruncFormats[f.unit]
+		switch dialect.(type) {
+		case *dialects.MySQLDialect:
+			sql = "DATE_FORMAT(" + argSQL + ", '" + formats.mysql + "')"
+		case *dialects.SQLiteDialect:
+			sql = "strftime('" + formats.sqlite + "', " + argSQL + ")"
+		default:
+			sql = "DATE_TRUNC('" + f.unit + "', " + argSQL + ")"
+		}
+	default:
+		sql = f.fn + "(" + argSQL + ")"
+	}
+
+	if f.alias != "" {
+		sql += " AS " + dialect.QuoteIdentifier(f.alias)
+	}
+
+	return sql, args
+}
+
+// checkDialect implements checkedExpression: it reports the invalid type or
+// unit stored by Cast or DateTrunc, so a builder fails instead of dropping a
+// condition that builds to empty SQL.
+func (f *FuncExp) checkDialect(dialect dialects.Dialect) error {
+	if f.err != nil {
+		return f.err
+	}
+	if arg, ok := f.arg.(Expression); ok {
+		return checkExpression(arg, dialect)
+	}
+	return nil
+}
+
+// castTypeFor returns the CAST target type for the dialect.
+// MySQL only accepts a restricted set of CAST types, so common integer and
+// text types are mapped; everything else is passed through unchanged.
+func castTypeFor(typeName string, dialect dialects.Dialect) string {
+	if _, ok := dialect.(*dialects.MySQLDialect); !ok {
+		return typeName
+	}
+
+	lower := strings.ToLower(typeName)
+	switch {
+	case lower == "bigint" || lower == "int" || lower == "integer" || lower == "smallint":
+		return "SIGNED"
+	case lower == "text":
+		return "CHAR"
+	case strings.HasPrefix(lower, "varchar"):
+		return "CHAR" + typeName[len("varchar"):]
+	default:
+		return typeName
+	}
+}
+
+// exprCompareExp compares an expression (left side) with a value.
+// It mirrors CompareExp, which only accepts a column name on the left.
+type exprCompareExp struct {
+	left     Expression
+	operator string
+	value    interface{}
+}
+
+// checkDialect implements checkedExpression for both sides of the comparison.
+func (e *exprCompareExp) checkDialect(dialect dialects.Dialect) error {
+	if err := checkExpression(e.left, dialect); err != nil {
+		return err
+	}
+	if expr, ok := e.value.(Expression); ok {
+		return checkExpression(expr, dialect)
+	}
+	return nil
+}
+
+// Build implements the Expression interface.
+func (e *exprCompareExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	leftSQL, args := e.left.Build(dialect)
+	if leftSQL == "" {
+		return "", nil
+	}
+
+	// Handle NULL comparison
+	if e.value == nil {
+		if e.operator == "=" {
+			return leftSQL + " IS NULL", args
+		}
+		if e.operator == "<>" {
+			return leftSQL + " IS NOT NULL", args
+		}
+	}
+
+	// Handle Expression values
+	if expr, ok := e.value.(Expression); ok {
+		sql, subArgs := expr.Build(dialect)
+		return leftSQL + " " + e.operator + " (" + sql + ")", append(args, subArgs...)
+	}
+
+	return leftSQL + " " + e.operator + " ?", append(args, e.value)
+}
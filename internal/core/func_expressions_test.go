@@ -5,10 +5,12 @@
 package core
 
 import (
+	"context"
 	"testing"
 
 	"github.com/coregx/relica/internal/dialects"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCase_Simple(t *testing.T) {
@@ -431,3 +433,249 @@ func TestConcat_TableAlias(t *testing.T) {
 		})
 	}
 }
+
+func TestCase_SearchedWithExpressions(t *testing.T) {
+	dialect := dialects.GetDialect("postgres")
+
+	expr := Case().
+		When(Eq("status", 1), "active").
+		When(Eq("status", 2), "banned").
+		Else("unknown").
+		As("status_label")
+
+	sql, args := expr.Build(dialect)
+
+	assert.Equal(t, `CASE WHEN "status" = ? THEN ? WHEN "status" = ? THEN ? ELSE ? END AS "status_label"`, sql)
+	assert.Equal(t, []interface{}{1, "active", 2, "banned", "unknown"}, args)
+}
+
+func TestCase_ExpressionResult(t *testing.T) {
+	dialect := dialects.GetDialect("mysql")
+
+	expr := Case().
+		When(GreaterThan("score", 90), Coalesce("nickname", "name")).
+		Else(NullIf("name", "''"))
+
+	sql, args := expr.Build(dialect)
+
+	assert.Equal(t, "CASE WHEN `score` > ? THEN COALESCE(`nickname`, `name`) ELSE NULLIF(`name`, '') END", sql)
+	assert.Equal(t, []interface{}{90}, args)
+}
+
+func TestCase_InSelectOrderByAndSet_PostgreSQL(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	label := Case().
+		When(Eq("status", 1), "active").
+		Else("unknown").
+		As("status_label")
+
+	q := qb.Select("id").
+		SelectExp(label).
+		From("users").
+		Where("age > ?", 18).
+		OrderBySub(Case().When(Eq("role", "admin"), 0).Else(1)).
+		Build()
+
+	assert.Equal(t,
+		`SELECT "id", CASE WHEN "status" = $1 THEN $2 ELSE $3 END AS "status_label" FROM "users" WHERE age > $4 ORDER BY CASE WHEN "role" = $5 THEN $6 ELSE $7 END`,
+		q.sql)
+	assert.Equal(t, []interface{}{1, "active", "unknown", 18, "admin", 0, 1}, q.params)
+
+	uq := qb.Update("users").Set(map[string]interface{}{
+		"name": "Alice",
+		"tier": Case().When(GreaterThan("points", 1000), "gold").Else("silver"),
+	}).Where(Eq("id", 7)).Build()
+
+	assert.Equal(t,
+		`UPDATE "users" SET "name" = $1, "tier" = CASE WHEN "points" > $2 THEN $3 ELSE $4 END WHERE "id" = $5`,
+		uq.sql)
+	assert.Equal(t, []interface{}{"Alice", 1000, "gold", "silver", 7}, uq.params)
+}
+
+func TestCase_GroupByPlaceholderOrder_PostgreSQL(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("COUNT(*)").From("orders").
+		Where("status = ?", "paid").
+		GroupBySub(Case().When(GreaterThan("total", 100), "big").Else("small")).
+		Having("COUNT(*) > ?", 5).
+		Build()
+
+	assert.Equal(t,
+		`SELECT COUNT(*) FROM "orders" WHERE status = $1 GROUP BY CASE WHEN "total" > $2 THEN $3 ELSE $4 END HAVING COUNT(*) > $5`,
+		q.sql)
+	assert.Equal(t, []interface{}{"paid", 100, "big", "small", 5}, q.params)
+}
+
+func TestFuncExp_LowerUpper(t *testing.T) {
+	dialect := dialects.GetDialect("postgres")
+
+	sql, args := Lower("u.email").As("email_lc").Build(dialect)
+	assert.Equal(t, `LOWER("u"."email") AS "email_lc"`, sql)
+	assert.Empty(t, args)
+
+	sql, args = Upper(Coalesce("nickname", "name")).Build(dialect)
+	assert.Equal(t, `UPPER(COALESCE("nickname", "name"))`, sql)
+	assert.Empty(t, args)
+}
+
+func TestFuncExp_Cast(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		typeName string
+		expected string
+	}{
+		{"postgres", "bigint", `CAST("amount" AS bigint)`},
+		{"sqlite", "integer", `CAST("amount" AS integer)`},
+		{"mysql", "bigint", "CAST(`amount` AS SIGNED)"},
+		{"mysql", "varchar(20)", "CAST(`amount` AS CHAR(20))"},
+		{"mysql", "decimal(10, 2)", "CAST(`amount` AS decimal(10, 2))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect+"_"+tt.typeName, func(t *testing.T) {
+			sql, _ := Cast("amount", tt.typeName).Build(dialects.GetDialect(tt.dialect))
+			assert.Equal(t, tt.expected, sql)
+		})
+	}
+}
+
+func TestFuncExp_CastInvalidType(t *testing.T) {
+	exp := Cast("amount", "int); DROP TABLE users; --")
+
+	sql, args := exp.Build(dialects.GetDialect("postgres"))
+	assert.Empty(t, sql)
+	assert.Nil(t, args)
+	assert.Error(t, exp.Err())
+}
+
+func TestFuncExp_DateTrunc(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		expected string
+	}{
+		{"postgres", `DATE_TRUNC('day', "created_at")`},
+		{"mysql", "DATE_FORMAT(`created_at`, '%Y-%m-%d 00:00:00')"},
+		{"sqlite", `strftime('%Y-%m-%d 00:00:00', "created_at")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			sql, args := DateTrunc("DAY", "created_at").Build(dialects.GetDialect(tt.dialect))
+			assert.Equal(t, tt.expected, sql)
+			assert.Empty(t, args)
+		})
+	}
+
+	exp := DateTrunc("fortnight", "created_at")
+	assert.Error(t, exp.Err())
+}
+
+func TestFuncExp_InvalidInWhere(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Delete("users").Where(Cast("x", "bad;type").Eq(1)).Build()
+	require.Error(t, q.prepErr, "an invalid Cast must not drop the DELETE condition")
+	assert.ErrorContains(t, q.prepErr, "Cast: invalid type name")
+
+	q = qb.Update("users").Set(map[string]interface{}{"a": 1}).
+		Where(DateTrunc("week", "created_at").Eq(1)).
+		Build()
+	require.Error(t, q.prepErr, "an invalid DateTrunc must not drop the UPDATE condition")
+	assert.ErrorContains(t, q.prepErr, "DateTrunc: unsupported unit")
+
+	q = qb.Delete("users").Where(And(Eq("tenant", 1), Not(Cast("x", "bad;type").Eq(1)))).Build()
+	assert.Error(t, q.prepErr, "nested invalid expressions fail as well")
+
+	q = qb.Select().From("users").Where(Lower(Cast("x", "bad;type")).Eq("a")).Build()
+	assert.Error(t, q.prepErr)
+}
+
+func TestFuncExp_InvalidOutsideWhere(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	bad := Cast("x", "bad;type")
+
+	tests := []struct {
+		name string
+		q    *Query
+	}{
+		{"having", qb.Select("id").From("t").GroupBy("id").Having(bad.Eq(1)).Build()},
+		{"group by", qb.Select("id").From("t").GroupBySub(DateTrunc("week", "x")).Build()},
+		{"order by", qb.Select("id").From("t").OrderBySub(bad).Build()},
+		{"select", qb.Select("id").SelectExp(bad).From("t").Build()},
+		{"select sub", qb.Select("id").SelectSub(bad, "c").From("t").Build()},
+		{"join on", qb.Select("id").From("t").InnerJoin("u", And(EqCol("u.id", "t.id"), bad.Eq(1))).Build()},
+		{"join select on", qb.Select("id").From("t").InnerJoinSelect(qb.Select("id").From("u"), "s", bad.Eq(1)).Build()},
+		{"update set", qb.Update("t").Set(map[string]interface{}{"a": bad}).Where("id = ?", 1).Build()},
+		{"merge update", qb.Merge("t").UsingTable("s", "s", "t.id = s.id").
+			WhenMatchedUpdate(map[string]interface{}{"a": bad}).Build()},
+		{"merge insert", qb.Merge("t").UsingTable("s", "s", "t.id = s.id").
+			WhenNotMatchedInsert(map[string]interface{}{"a": bad}).Build()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.q.prepErr, "an invalid expression must not be rendered as empty SQL")
+			assert.Empty(t, tt.q.sql)
+		})
+	}
+}
+
+func TestFuncExp_InvalidInCountAndExists(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE players (id INTEGER PRIMARY KEY, score TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), "INSERT INTO players (score) VALUES ('1'), ('2'), ('3')")
+	require.NoError(t, err)
+
+	sq := db.Builder().Select().From("players").Where(Cast("score", "bad;type").Eq(1))
+	n, err := sq.Count()
+	require.Error(t, err, "an invalid filter must not count the whole table")
+	assert.ErrorContains(t, err, "Cast: invalid type name")
+	assert.Zero(t, n)
+
+	exists, err := sq.Exists()
+	require.Error(t, err)
+	assert.False(t, exists)
+}
+
+func TestFuncExp_Comparisons(t *testing.T) {
+	dialect := dialects.GetDialect("postgres")
+
+	sql, args := Lower("email").Eq("alice@example.com").Build(dialect)
+	assert.Equal(t, `LOWER("email") = ?`, sql)
+	assert.Equal(t, []interface{}{"alice@example.com"}, args)
+
+	sql, args = Coalesce("discount", 0).GreaterThan(10).Build(dialect)
+	assert.Equal(t, `COALESCE("discount", ?) > ?`, sql)
+	assert.Equal(t, []interface{}{0, 10}, args)
+
+	sql, args = Cast("score", "integer").Eq(nil).Build(dialect)
+	assert.Equal(t, `CAST("score" AS integer) IS NULL`, sql)
+	assert.Empty(t, args)
+}
+
+func TestFuncExp_InQuery_PostgreSQL(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select().
+		SelectExp(DateTrunc("month", "created_at").As("month"), Coalesce("region", "'n/a'").As("region")).
+		From("orders").
+		Where(Lower("status").Eq("paid")).
+		GroupBySub(DateTrunc("month", "created_at")).
+		GroupBySub(Coalesce("region", "'n/a'")).
+		OrderBySub(Cast(Coalesce("priority", 0), "integer")).
+		Build()
+
+	assert.Equal(t,
+		`SELECT DATE_TRUNC('month', "created_at") AS "month", COALESCE("region", 'n/a') AS "region" FROM "orders" `+
+			`WHERE LOWER("status") = $1 GROUP BY DATE_TRUNC('month', "created_at"), COALESCE("region", 'n/a') `+
+			`ORDER BY CAST(COALESCE("priority", $2) AS integer)`,
+		q.sql)
+	assert.Equal(t, []interface{}{"paid", 0}, q.params)
+}
@@ -0,0 +1,418 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// =============================================================================
+// Geospatial expressions
+// =============================================================================
+//
+// STDWithin, STContains, STWithin, STIntersects and STDistance render PostGIS
+// functions on PostgreSQL and SpatiaLite functions on SQLite. Coordinates are
+// WGS 84 longitude/latitude (SRID 4326) and distances are in meters. Other
+// dialects are rejected with a build error by Where, OrWhere, OrderBySub and
+// SelectExp.
+//
+// Point and BBox scan geometry columns returned as WKB, hex-encoded (E)WKB
+// (the PostGIS text format), (E)WKT or SpatiaLite geometry blobs.
+
+// geoSRID is the spatial reference of Point and BBox values (WGS 84).
+const geoSRID = "4326"
+
+// Point is a WGS 84 longitude/latitude position.
+type Point struct {
+	Lng float64
+	Lat float64
+}
+
+// BBox is a WGS 84 bounding box.
+type BBox struct {
+	MinLng float64
+	MinLat float64
+	MaxLng float64
+	MaxLat float64
+}
+
+// GeoExp is a geospatial predicate or distance expression.
+type GeoExp struct {
+	fn     string // ST_DWithin, ST_Contains, ST_Within, ST_Intersects, ST_Distance
+	col    string
+	geom   interface{} // Point or BBox
+	meters float64     // ST_DWithin only
+	alias  string
+}
+
+// STDWithin generates a condition that col is within meters of p.
+//
+// Example:
+//
+//	db.Builder().Select().From("shops").
+//	    Where(relica.STDWithin("location", relica.Point{Lng: 13.40, Lat: 52.52}, 500)).
+//	    OrderBySub(relica.STDistance("location", relica.Point{Lng: 13.40, Lat: 52.52})).
+//	    All(&shops)
+//
+// Generates:
+//
+//	PostgreSQL: ST_DWithin("location"::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)
+//	SQLite:     PtDistWithin("location", MakePoint(?, ?, 4326), ?)
+func STDWithin(col string, p Point, meters float64) *GeoExp {
+	return &GeoExp{fn: "ST_DWithin", col: col, geom: p, meters: meters}
+}
+
+// STContains generates a condition that the geometry in col contains geom
+// (a Point or BBox).
+func STContains(col string, geom interface{}) *GeoExp {
+	return &GeoExp{fn: "ST_Contains", col: col, geom: geom}
+}
+
+// STWithin generates a condition that the geometry in col lies within geom
+// (a Point or BBox).
+func STWithin(col string, geom interface{}) *GeoExp {
+	return &GeoExp{fn: "ST_Within", col: col, geom: geom}
+}
+
+// STIntersects generates a condition that the geometry in col intersects geom
+// (a Point or BBox).
+func STIntersects(col string, geom interface{}) *GeoExp {
+	return &GeoExp{fn: "ST_Intersects", col: col, geom: geom}
+}
+
+// STDistance generates the distance in meters between col and p, for ORDER BY
+// or SELECT lists.
+func STDistance(col string, p Point) *GeoExp {
+	return &GeoExp{fn: "ST_Distance", col: col, geom: p}
+}
+
+// As sets an alias for the expression (SELECT lists only).
+func (g *GeoExp) As(alias string) *GeoExp {
+	g.alias = alias
+	return g
+}
+
+// checkDialect implements checkedExpression.
+func (g *GeoExp) checkDialect(dialect dialects.Dialect) error {
+	switch dialect.(type) {
+	case *dialects.PostgresDialect, *dialects.SQLiteDialect:
+	default:
+		return fmt.Errorf("relica: %s requires PostGIS (postgres) or SpatiaLite (sqlite)", g.fn)
+	}
+	switch g.geom.(type) {
+	case Point, BBox:
+		return nil
+	default:
+		return fmt.Errorf("relica: %s: geometry must be a Point or BBox, got %T", g.fn, g.geom)
+	}
+}
+
+// Build implements the Expression interface.
+// Unsupported dialects get the PostGIS form; builder methods reject them first.
+func (g *GeoExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	col := quoteColumn(g.col, dialect)
+	_, spatialite := dialect.(*dialects.SQLiteDialect)
+	geomSQL, args := buildGeometry(g.geom, spatialite)
+
+	var sql string
+	switch {
+	case g.fn == "ST_DWithin" && spatialite:
+		sql = "PtDistWithin(" + col + ", " + geomSQL + ", ?)"
+		args = append(args, g.meters)
+	case g.fn == "ST_DWithin":
+		sql = "ST_DWithin(" + col + "::geography, " + geomSQL + "::geography, ?)"
+		args = append(args, g.meters)
+	case g.fn == "ST_Distance" && spatialite:
+		sql = "ST_Distance(" + col + ", " + geomSQL + ", 1)"
+	case g.fn == "ST_Distance":
+		sql = "ST_Distance(" + col + "::geography, " + geomSQL + "::geography)"
+	default:
+		sql = g.fn + "(" + col + ", " + geomSQL + ")"
+	}
+
+	if g.alias != "" {
+		sql += " AS " + dialect.QuoteIdentifier(g.alias)
+	}
+	return sql, args
+}
+
+// buildGeometry renders a Point or BBox constructor with bound coordinates.
+func buildGeometry(geom interface{}, spatialite bool) (string, []interface{}) {
+	switch v := geom.(type) {
+	case Point:
+		if spatialite {
+			return "MakePoint(?, ?, " + geoSRID + ")", []interface{}{v.Lng, v.Lat}
+		}
+		return "ST_SetSRID(ST_MakePoint(?, ?), " + geoSRID + ")", []interface{}{v.Lng, v.Lat}
+	case BBox:
+		args := []interface{}{v.MinLng, v.MinLat, v.MaxLng, v.MaxLat}
+		if spatialite {
+			return "BuildMbr(?, ?, ?, ?, " + geoSRID + ")", args
+		}
+		return "ST_MakeEnvelope(?, ?, ?, ?, " + geoSRID + ")", args
+	default:
+		return "NULL", nil
+	}
+}
+
+// Scan implements sql.Scanner for point geometry columns.
+func (p *Point) Scan(src interface{}) error {
+	coords, err := parseGeometry(src)
+	if err != nil {
+		return fmt.Errorf("relica: scan Point: %w", err)
+	}
+	if coords.kind != geomPoint || len(coords.xy) != 1 {
+		return fmt.Errorf("relica: scan Point: geometry is not a point")
+	}
+	p.Lng, p.Lat = coords.xy[0][0], coords.xy[0][1]
+	return nil
+}
+
+// Scan implements sql.Scanner: the bounding box of any geometry column.
+func (b *BBox) Scan(src interface{}) error {
+	coords, err := parseGeometry(src)
+	if err != nil {
+		return fmt.Errorf("relica: scan BBox: %w", err)
+	}
+	if len(coords.xy) == 0 {
+		return fmt.Errorf("relica: scan BBox: empty geometry")
+	}
+	*b = BBox{MinLng: math.Inf(1), MinLat: math.Inf(1), MaxLng: math.Inf(-1), MaxLat: math.Inf(-1)}
+	for _, c := range coords.xy {
+		b.MinLng, b.MaxLng = math.Min(b.MinLng, c[0]), math.Max(b.MaxLng, c[0])
+		b.MinLat, b.MaxLat = math.Min(b.MinLat, c[1]), math.Max(b.MaxLat, c[1])
+	}
+	return nil
+}
+
+// WKB geometry types and EWKB flags.
+const (
+	geomPoint           = 1
+	geomLineString      = 2
+	geomPolygon         = 3
+	geomMultiPoint      = 4
+	geomMultiLineString = 5
+	geomMultiPolygon    = 6
+	geomCollection      = 7
+	ewkbFlagZ           = 0x80000000
+	ewkbFlagM           = 0x40000000
+	ewkbFlagSRID        = 0x20000000
+	spatialiteMBREnd    = 0x7C // marks the end of the SpatiaLite blob header
+)
+
+// geometry is the outer type of a parsed geometry and all of its vertices.
+// A SpatiaLite blob contributes its MBR corners unless it is a point.
+type geometry struct {
+	kind uint32
+	xy   [][2]float64
+}
+
+// parseGeometry decodes a scanned geometry value.
+func parseGeometry(src interface{}) (geometry, error) {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		return geometry{}, fmt.Errorf("NULL geometry")
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return geometry{}, fmt.Errorf("unsupported source type %T", src)
+	}
+
+	switch {
+	case len(b) == 0:
+		return geometry{}, fmt.Errorf("empty value")
+	case b[0] == 0x00 && len(b) > 43 && b[38] == spatialiteMBREnd:
+		return parseSpatiaLite(b)
+	case b[0] == 0x00 || b[0] == 0x01:
+		return parseWKB(b)
+	case isHexString(b):
+		raw := make([]byte, hex.DecodedLen(len(b)))
+		if _, err := hex.Decode(raw, b); err != nil {
+			return geometry{}, err
+		}
+		return parseWKB(raw)
+	default:
+		return parseWKT(string(b))
+	}
+}
+
+func isHexString(b []byte) bool {
+	if len(b)%2 != 0 {
+		return false
+	}
+	for _, c := range b {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// wkbReader reads (E)WKB geometries.
+type wkbReader struct {
+	b   []byte
+	pos int
+	out [][2]float64
+}
+
+func parseWKB(b []byte) (geometry, error) {
+	r := &wkbReader{b: b}
+	kind, err := r.geometry()
+	if err != nil {
+		return geometry{}, err
+	}
+	return geometry{kind: kind, xy: r.out}, nil
+}
+
+// geometry reads one geometry and returns its base type.
+func (r *wkbReader) geometry() (uint32, error) {
+	if r.pos+5 > len(r.b) {
+		return 0, fmt.Errorf("truncated WKB")
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if r.b[r.pos] == 1 {
+		order = binary.LittleEndian
+	}
+	typ := order.Uint32(r.b[r.pos+1:])
+	r.pos += 5
+
+	dims := 2
+	if typ&ewkbFlagZ != 0 {
+		dims++
+	}
+	if typ&ewkbFlagM != 0 {
+		dims++
+	}
+	if typ&ewkbFlagSRID != 0 {
+		r.pos += 4
+	}
+	typ &^= ewkbFlagZ | ewkbFlagM | ewkbFlagSRID
+	switch typ / 1000 { // ISO WKB: 1000 = Z, 2000 = M, 3000 = ZM
+	case 1, 2:
+		dims = 3
+	case 3:
+		dims = 4
+	}
+	base := typ % 1000
+
+	switch base {
+	case geomPoint:
+		return base, r.points(order, dims, 1)
+	case geomLineString:
+		n, err := r.count(order)
+		if err != nil {
+			return 0, err
+		}
+		return base, r.points(order, dims, n)
+	case geomPolygon:
+		rings, err := r.count(order)
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < rings; i++ {
+			n, err := r.count(order)
+			if err != nil {
+				return 0, err
+			}
+			if err := r.points(order, dims, n); err != nil {
+				return 0, err
+			}
+		}
+		return base, nil
+	case geomMultiPoint, geomMultiLineString, geomMultiPolygon, geomCollection:
+		n, err := r.count(order)
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < n; i++ {
+			if _, err := r.geometry(); err != nil {
+				return 0, err
+			}
+		}
+		return base, nil
+	default:
+		return 0, fmt.Errorf("unsupported WKB geometry type %d", typ)
+	}
+}
+
+func (r *wkbReader) count(order binary.ByteOrder) (int, error) {
+	if r.pos+4 > len(r.b) {
+		return 0, fmt.Errorf("truncated WKB")
+	}
+	n := int(order.Uint32(r.b[r.pos:]))
+	r.pos += 4
+	return n, nil
+}
+
+func (r *wkbReader) points(order binary.ByteOrder, dims, n int) error {
+	if n < 0 || r.pos+n*dims*8 > len(r.b) {
+		return fmt.Errorf("truncated WKB")
+	}
+	for i := 0; i < n; i++ {
+		x := math.Float64frombits(order.Uint64(r.b[r.pos:]))
+		y := math.Float64frombits(order.Uint64(r.b[r.pos+8:]))
+		r.pos += dims * 8
+		if !math.IsNaN(x) { // POINT EMPTY is encoded as NaN coordinates
+			r.out = append(r.out, [2]float64{x, y})
+		}
+	}
+	return nil
+}
+
+// parseSpatiaLite decodes a SpatiaLite geometry blob header: the MBR and,
+// for points, the coordinates.
+func parseSpatiaLite(b []byte) (geometry, error) {
+	var order binary.ByteOrder = binary.BigEndian
+	if b[1] == 1 {
+		order = binary.LittleEndian
+	}
+	f := func(off int) float64 { return math.Float64frombits(order.Uint64(b[off:])) }
+
+	kind := order.Uint32(b[39:]) % 1000
+	if kind == geomPoint {
+		if len(b) < 43+16 {
+			return geometry{}, fmt.Errorf("truncated SpatiaLite blob")
+		}
+		return geometry{kind: kind, xy: [][2]float64{{f(43), f(51)}}}, nil
+	}
+	return geometry{kind: kind, xy: [][2]float64{{f(6), f(14)}, {f(22), f(30)}}}, nil
+}
+
+// parseWKT decodes (E)WKT such as "SRID=4326;POINT(13.4 52.5)".
+func parseWKT(s string) (geometry, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, ';'); i >= 0 && strings.HasPrefix(strings.ToUpper(s), "SRID=") {
+		s = s[i+1:]
+	}
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return geometry{}, fmt.Errorf("invalid WKT %q", s)
+	}
+
+	var g geometry
+	if name := strings.Fields(s[:open]); len(name) > 0 && strings.EqualFold(name[0], "POINT") {
+		g.kind = geomPoint
+	}
+	body := strings.NewReplacer("(", " ", ")", " ").Replace(s[open:])
+	for _, part := range strings.Split(body, ",") {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			return geometry{}, fmt.Errorf("invalid WKT coordinate %q", part)
+		}
+		x, errX := strconv.ParseFloat(fields[0], 64)
+		y, errY := strconv.ParseFloat(fields[1], 64)
+		if errX != nil || errY != nil {
+			return geometry{}, fmt.Errorf("invalid WKT coordinate %q", part)
+		}
+		g.xy = append(g.xy, [2]float64{x, y})
+	}
+	return g, nil
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var geoBerlin = Point{Lng: 13.4, Lat: 52.52}
+
+func TestGeoExp_Build(t *testing.T) {
+	box := BBox{MinLng: 13, MinLat: 52, MaxLng: 14, MaxLat: 53}
+
+	tests := []struct {
+		name    string
+		dialect string
+		exp     Expression
+		want    string
+		args    []interface{}
+	}{
+		{
+			"dwithin postgis", "postgres", STDWithin("s.location", geoBerlin, 500),
+			`ST_DWithin("s"."location"::geography, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)`,
+			[]interface{}{13.4, 52.52, 500.0},
+		},
+		{
+			"dwithin spatialite", "sqlite", STDWithin("location", geoBerlin, 500),
+			`PtDistWithin("location", MakePoint(?, ?, 4326), ?)`,
+			[]interface{}{13.4, 52.52, 500.0},
+		},
+		{
+			"contains point postgis", "postgres", STContains("area", geoBerlin),
+			`ST_Contains("area", ST_SetSRID(ST_MakePoint(?, ?), 4326))`, []interface{}{13.4, 52.52},
+		},
+		{
+			"within bbox postgis", "postgres", STWithin("location", box),
+			`ST_Within("location", ST_MakeEnvelope(?, ?, ?, ?, 4326))`, []interface{}{13.0, 52.0, 14.0, 53.0},
+		},
+		{
+			"intersects bbox spatialite", "sqlite", STIntersects("area", box),
+			`ST_Intersects("area", BuildMbr(?, ?, ?, ?, 4326))`, []interface{}{13.0, 52.0, 14.0, 53.0},
+		},
+		{
+			"distance postgis", "postgres", STDistance("location", geoBerlin).As("meters"),
+			`ST_Distance("location"::geography, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography) AS "meters"`,
+			[]interface{}{13.4, 52.52},
+		},
+		{
+			"distance spatialite", "sqlite", STDistance("location", geoBerlin),
+			`ST_Distance("location", MakePoint(?, ?, 4326), 1)`, []interface{}{13.4, 52.52},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.exp.Build(dialects.GetDialect(tt.dialect))
+			assert.Equal(t, tt.want, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestGeoExp_Builder(t *testing.T) {
+	db := mockDB("postgres")
+
+	sql, params := db.Builder().Select("id").From("shops").
+		Where("open = ?", true).
+		Where(STDWithin("location", geoBerlin, 500)).
+		OrderBySub(STDistance("location", geoBerlin)).
+		Limit(10).
+		ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "shops" WHERE open = $1 AND `+
+		`ST_DWithin("location"::geography, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography, $4) `+
+		`ORDER BY ST_Distance("location"::geography, ST_SetSRID(ST_MakePoint($5, $6), 4326)::geography) LIMIT 10`, sql)
+	assert.Equal(t, []interface{}{true, 13.4, 52.52, 500.0, 13.4, 52.52}, params)
+}
+
+func TestGeoExp_UnsupportedDialect(t *testing.T) {
+	db := mockDB("mysql")
+
+	err := db.Builder().Select().From("shops").Where(STDWithin("location", geoBerlin, 500)).All(&[]struct{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires PostGIS")
+
+	_, err = db.Builder().Delete("shops").OrWhere(STContains("area", geoBerlin)).Execute()
+	require.Error(t, err)
+
+	err = mockDB("postgres").Builder().Select().From("shops").
+		OrderBySub(STContains("area", "POINT(1 2)")).All(&[]struct{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be a Point or BBox")
+}
+
+// wkbPoint encodes an (E)WKB point with an optional SRID.
+func wkbPoint(order binary.AppendByteOrder, x, y float64, srid uint32) []byte {
+	b := []byte{1}
+	if order.String() == binary.BigEndian.String() {
+		b[0] = 0
+	}
+	typ := uint32(geomPoint)
+	if srid != 0 {
+		typ |= ewkbFlagSRID
+	}
+	b = order.AppendUint32(b, typ)
+	if srid != 0 {
+		b = order.AppendUint32(b, srid)
+	}
+	b = order.AppendUint64(b, math.Float64bits(x))
+	return order.AppendUint64(b, math.Float64bits(y))
+}
+
+func TestPoint_Scan(t *testing.T) {
+	ewkb := wkbPoint(binary.LittleEndian, 13.4, 52.52, 4326)
+
+	tests := []struct {
+		name string
+		src  interface{}
+	}{
+		{"wkb little endian", wkbPoint(binary.LittleEndian, 13.4, 52.52, 0)},
+		{"wkb big endian", wkbPoint(binary.BigEndian, 13.4, 52.52, 0)},
+		{"ewkb", ewkb},
+		{"hex ewkb string", hex.EncodeToString(ewkb)},
+		{"hex ewkb bytes", []byte(strings.ToUpper(hex.EncodeToString(ewkb)))},
+		{"wkt", "POINT(13.4 52.52)"},
+		{"ewkt", "SRID=4326;POINT (13.4 52.52)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Point
+			require.NoError(t, p.Scan(tt.src))
+			assert.Equal(t, geoBerlin, p)
+		})
+	}
+
+	var p Point
+	assert.Error(t, p.Scan(nil))
+	assert.Error(t, p.Scan(42))
+	assert.Error(t, p.Scan("LINESTRING(0 0, 1 1)"))
+	assert.Error(t, p.Scan("POINT(abc)"))
+	assert.Error(t, p.Scan([]byte{1, 1, 0}))
+}
+
+func TestPoint_ScanSpatiaLite(t *testing.T) {
+	// SpatiaLite blob: start, byte order, SRID, MBR, MBR end, class, coordinates, end.
+	le := binary.LittleEndian
+	b := []byte{0x00, 0x01}
+	b = le.AppendUint32(b, 4326)
+	for _, v := range []float64{13.4, 52.52, 13.4, 52.52} {
+		b = le.AppendUint64(b, math.Float64bits(v))
+	}
+	b = append(b, spatialiteMBREnd)
+	b = le.AppendUint32(b, geomPoint)
+	b = le.AppendUint64(b, math.Float64bits(13.4))
+	b = le.AppendUint64(b, math.Float64bits(52.52))
+	b = append(b, 0xFE)
+
+	var p Point
+	require.NoError(t, p.Scan(b))
+	assert.Equal(t, geoBerlin, p)
+
+	var box BBox
+	require.NoError(t, box.Scan(b))
+	assert.Equal(t, BBox{MinLng: 13.4, MinLat: 52.52, MaxLng: 13.4, MaxLat: 52.52}, box)
+}
+
+func TestBBox_Scan(t *testing.T) {
+	var box BBox
+	require.NoError(t, box.Scan("POLYGON((13 52, 14 52, 14 53.5, 13 53.5, 13 52))"))
+	assert.Equal(t, BBox{MinLng: 13, MinLat: 52, MaxLng: 14, MaxLat: 53.5}, box)
+
+	// Multi-geometry WKB with a Z polygon ring.
+	le := binary.LittleEndian
+	b := []byte{1}
+	b = le.AppendUint32(b, geomMultiPoint)
+	b = le.AppendUint32(b, 2)
+	b = append(b, wkbPoint(le, -1, 2, 0)...)
+	b = append(b, 1)
+	b = le.AppendUint32(b, geomPolygon|ewkbFlagZ)
+	b = le.AppendUint32(b, 1) // rings
+	b = le.AppendUint32(b, 2) // points
+	for _, v := range []float64{5, -3, 100, 0, 0, 100} {
+		b = le.AppendUint64(b, math.Float64bits(v))
+	}
+	require.NoError(t, box.Scan(b))
+	assert.Equal(t, BBox{MinLng: -1, MinLat: -3, MaxLng: 5, MaxLat: 2}, box)
+
+	assert.Error(t, box.Scan("POLYGON EMPTY"))
+}
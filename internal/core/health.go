@@ -9,17 +9,90 @@ import (
 	"github.com/coregx/relica/internal/logger"
 )
 
+// HealthState is the state reported by the health checker (see WithHealthCheck).
+type HealthState int
+
+const (
+	// HealthStateHealthy means the last probe succeeded in time.
+	HealthStateHealthy HealthState = iota
+	// HealthStateDegraded means the last probe succeeded but took longer than
+	// the HealthCheckDegradedAfter threshold.
+	HealthStateDegraded
+	// HealthStateUnhealthy means the last probe failed.
+	HealthStateUnhealthy
+)
+
+// String returns the lowercase name of the state.
+func (s HealthState) String() string {
+	switch s {
+	case HealthStateHealthy:
+		return "healthy"
+	case HealthStateDegraded:
+		return "degraded"
+	case HealthStateUnhealthy:
+		return "unhealthy"
+	default:
+		return "unknown"
+	}
+}
+
+// HealthChange describes a transition of the health state, passed to the
+// HealthCheckOnChange callback.
+type HealthChange struct {
+	From    HealthState
+	To      HealthState
+	Latency time.Duration // duration of the probe that caused the change
+	Err     error         // probe error; nil unless To is HealthStateUnhealthy
+}
+
+// HealthCheckOption configures WithHealthCheck.
+type HealthCheckOption func(*healthChecker)
+
+// HealthCheckQuery probes with query instead of a ping, e.g. to check that a
+// table is readable or a replica is connected. The query fails the check if
+// it returns an error; its rows are read and discarded.
+func HealthCheckQuery(query string) HealthCheckOption {
+	return func(h *healthChecker) {
+		h.query = query
+	}
+}
+
+// HealthCheckDegradedAfter reports the database as degraded when a probe
+// succeeds but takes longer than latency. Degraded databases still count as
+// healthy for IsHealthy.
+func HealthCheckDegradedAfter(latency time.Duration) HealthCheckOption {
+	return func(h *healthChecker) {
+		h.degradedAfter = latency
+	}
+}
+
+// HealthCheckOnChange calls fn from the health check goroutine whenever the
+// state changes, e.g. to flip a readiness probe. The state starts out
+// healthy, so the first failed check reports healthy → unhealthy. fn should
+// return quickly; the next check waits for it.
+func HealthCheckOnChange(fn func(HealthChange)) HealthCheckOption {
+	return func(h *healthChecker) {
+		h.onChange = fn
+	}
+}
+
 // healthChecker performs periodic health checks on database connections.
-// It pings the database at regular intervals to detect dead connections early.
+// It pings the database (or runs the probe query) at regular intervals to
+// detect dead connections early.
 type healthChecker struct {
-	db       *sql.DB
-	logger   logger.Logger
-	interval time.Duration
-	stop     chan struct{}
-	wg       sync.WaitGroup
-	mu       sync.RWMutex
-	lastErr  error
-	lastPing time.Time
+	db            *sql.DB
+	logger        logger.Logger
+	interval      time.Duration
+	query         string             // probe query; "" = ping
+	degradedAfter time.Duration      // latency above which a passing check is degraded; 0 = never
+	onChange      func(HealthChange) // state transition callback; nil = none
+	stop          chan struct{}
+	wg            sync.WaitGroup
+	mu            sync.RWMutex
+	lastErr       error
+	lastPing      time.Time
+	latency       time.Duration
+	state         HealthState
 }
 
 // newHealthChecker creates a new health checker that pings the database at the specified interval.
@@ -60,21 +133,62 @@ func (h *healthChecker) ping() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := h.db.PingContext(ctx)
+	start := time.Now()
+	err := h.probe(ctx)
+	latency := time.Since(start)
+
+	state := HealthStateHealthy
+	switch {
+	case err != nil:
+		state = HealthStateUnhealthy
+	case h.degradedAfter > 0 && latency > h.degradedAfter:
+		state = HealthStateDegraded
+	}
 
 	h.mu.Lock()
+	prev := h.state
 	h.lastErr = err
 	h.lastPing = time.Now()
+	h.latency = latency
+	h.state = state
 	h.mu.Unlock()
 
-	if err != nil {
+	switch state {
+	case HealthStateUnhealthy:
 		h.logger.Warn("database health check failed",
 			"error", err,
 			"interval", h.interval)
-	} else {
+	case HealthStateDegraded:
+		h.logger.Warn("database health check slow",
+			"latency", latency,
+			"threshold", h.degradedAfter)
+	default:
 		h.logger.Debug("database health check passed",
 			"interval", h.interval)
 	}
+
+	if state != prev && h.onChange != nil {
+		h.onChange(HealthChange{From: prev, To: state, Latency: latency, Err: err})
+	}
+}
+
+// probe runs the probe query, or pings the database if there is none.
+func (h *healthChecker) probe(ctx context.Context) error {
+	if h.query == "" {
+		return h.db.PingContext(ctx)
+	}
+	rows, err := h.db.QueryContext(ctx, h.query)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		// Rows are discarded; reading them runs the query to completion.
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return err
+	}
+	return rows.Close()
 }
 
 // shutdown halts the health checker and waits for it to finish.
@@ -90,6 +204,13 @@ func (h *healthChecker) isHealthy() bool {
 	return h.lastErr == nil
 }
 
+// status returns the state and probe latency of the most recent health check.
+func (h *healthChecker) status() (HealthState, time.Duration) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.state, h.latency
+}
+
 // lastError returns the error from the most recent health check.
 //
 //nolint:unused // May be used for debugging/monitoring
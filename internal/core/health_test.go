@@ -145,3 +145,62 @@ func TestDB_ConnectionPoolOptions(t *testing.T) {
 		t.Errorf("Expected MaxOpenConnections=10, got %d", stats.MaxOpenConnections)
 	}
 }
+
+func TestHealthChecker_ProbeQueryAndTransitions(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	var changes []HealthChange
+	hc := newHealthChecker(db, &logger.NoopLogger{}, time.Hour)
+	HealthCheckQuery("SELECT COUNT(*) FROM probe")(hc)
+	HealthCheckOnChange(func(c HealthChange) { changes = append(changes, c) })(hc)
+
+	hc.ping()
+	if hc.isHealthy() {
+		t.Error("probe query on a missing table should fail the check")
+	}
+	if _, err := db.Exec("CREATE TABLE probe (id INTEGER)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	hc.ping()
+	hc.ping()
+
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 state changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].From != HealthStateHealthy || changes[0].To != HealthStateUnhealthy || changes[0].Err == nil {
+		t.Errorf("Unexpected first change: %+v", changes[0])
+	}
+	if changes[1].From != HealthStateUnhealthy || changes[1].To != HealthStateHealthy || changes[1].Err != nil {
+		t.Errorf("Unexpected second change: %+v", changes[1])
+	}
+}
+
+func TestHealthChecker_Degraded(t *testing.T) {
+	coreDB, err := Open("sqlite", ":memory:",
+		WithHealthCheck(time.Hour, HealthCheckDegradedAfter(time.Nanosecond)))
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer coreDB.Close()
+
+	coreDB.healthChecker.ping()
+
+	stats := coreDB.Stats()
+	if stats.HealthState != HealthStateDegraded {
+		t.Errorf("Expected degraded state, got %v", stats.HealthState)
+	}
+	if stats.HealthCheckLatency <= 0 {
+		t.Error("HealthCheckLatency should be set after a check")
+	}
+	if !coreDB.IsHealthy() {
+		t.Error("A degraded DB still counts as healthy")
+	}
+	if HealthStateDegraded.String() != "degraded" {
+		t.Errorf("Unexpected state name %q", HealthStateDegraded.String())
+	}
+}
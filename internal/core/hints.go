@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Index hints and planner hints
+// ============================================================================
+//
+// Hints override the query planner in hot paths. Index hints are MySQL syntax
+// and are dropped on other dialects; planner hint comments (/*+ ... */) are
+// emitted for every dialect, since databases without a hint extension treat
+// them as ordinary comments.
+
+// indexHint is a MySQL index hint attached to the FROM table.
+type indexHint struct {
+	kind    string   // "USE", "FORCE" or "IGNORE"
+	indexes []string // index names
+}
+
+// UseIndex adds a MySQL USE INDEX hint for the FROM table.
+// The hint is ignored on PostgreSQL and SQLite.
+//
+// Example:
+//
+//	db.Builder().Select("*").From("users").UseIndex("idx_users_email").Where("email = ?", email)
+//	// SELECT * FROM `users` USE INDEX (`idx_users_email`) WHERE email = ?
+func (sq *SelectQuery) UseIndex(indexes ...string) *SelectQuery {
+	return sq.addIndexHint("UseIndex", "USE", indexes)
+}
+
+// ForceIndex adds a MySQL FORCE INDEX hint for the FROM table.
+// The hint is ignored on PostgreSQL and SQLite.
+func (sq *SelectQuery) ForceIndex(indexes ...string) *SelectQuery {
+	return sq.addIndexHint("ForceIndex", "FORCE", indexes)
+}
+
+// IgnoreIndex adds a MySQL IGNORE INDEX hint for the FROM table.
+// The hint is ignored on PostgreSQL and SQLite.
+func (sq *SelectQuery) IgnoreIndex(indexes ...string) *SelectQuery {
+	return sq.addIndexHint("IgnoreIndex", "IGNORE", indexes)
+}
+
+// addIndexHint validates and stores an index hint.
+func (sq *SelectQuery) addIndexHint(method, kind string, indexes []string) *SelectQuery {
+	sq = sq.own()
+	if len(indexes) == 0 {
+		sq.buildErr = fmt.Errorf("relica: %s() requires at least one index name", method)
+		return sq
+	}
+	for _, idx := range indexes {
+		if strings.TrimSpace(idx) == "" {
+			sq.buildErr = fmt.Errorf("relica: %s() requires non-empty index names", method)
+			return sq
+		}
+	}
+	sq.indexHints = append(sq.indexHints, indexHint{kind: kind, indexes: indexes})
+	return sq
+}
+
+// Hint adds an optimizer hint comment such as "/*+ IndexScan(users idx_users_email) */".
+// Text without comment delimiters is wrapped automatically ("IndexScan(users idx)").
+//
+// Placement follows each database's hint convention:
+//   - PostgreSQL (pg_hint_plan): before the statement
+//   - MySQL optimizer hints (and SQLite, as a plain comment): right after SELECT
+//
+// Example:
+//
+//	db.Builder().Select("*").From("users").Hint("IndexScan(users idx_users_email)")
+//	// PostgreSQL: /*+ IndexScan(users idx_users_email) */ SELECT * FROM "users"
+func (sq *SelectQuery) Hint(hint string) *SelectQuery {
+	sq = sq.own()
+	hint = strings.TrimSpace(hint)
+	if hint == "" {
+		sq.buildErr = fmt.Errorf("relica: Hint() requires a non-empty hint")
+		return sq
+	}
+	if !strings.HasPrefix(hint, "/*") {
+		hint = "/*+ " + hint + " */"
+	}
+	// Exactly one comment, closed at the end: prevents breaking out of the comment
+	// (PostgreSQL nests block comments, so an inner "/*" would also swallow the query).
+	if !strings.HasSuffix(hint, "*/") || strings.Count(hint, "*/") != 1 || strings.Count(hint, "/*") != 1 {
+		sq.buildErr = fmt.Errorf("relica: Hint() must be a single /* ... */ comment, got %q", hint)
+		return sq
+	}
+	sq.hints = append(sq.hints, hint)
+	return sq
+}
+
+// buildIndexHints renders MySQL index hints for the FROM table.
+// Returns an empty string for other dialects.
+func (sq *SelectQuery) buildIndexHints(dialect dialects.Dialect) string {
+	if len(sq.indexHints) == 0 {
+		return ""
+	}
+	if _, ok := dialect.(*dialects.MySQLDialect); !ok {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, h := range sq.indexHints {
+		quoted := make([]string, len(h.indexes))
+		for i, idx := range h.indexes {
+			quoted[i] = dialect.QuoteIdentifier(strings.TrimSpace(idx))
+		}
+		b.WriteString(" " + h.kind + " INDEX (" + strings.Join(quoted, ", ") + ")")
+	}
+	return b.String()
+}
+
+// hintPrefixes returns the planner hint comment to place before the statement
+// (PostgreSQL) and right after the SELECT keyword (other dialects), each with a
+// trailing space, or empty strings if no hints are set.
+func (sq *SelectQuery) hintPrefixes(dialect dialects.Dialect) (statement, afterSelect string) {
+	if len(sq.hints) == 0 {
+		return "", ""
+	}
+	comment := strings.Join(sq.hints, " ") + " "
+	if _, ok := dialect.(*dialects.PostgresDialect); ok {
+		return comment, ""
+	}
+	return "", comment
+}
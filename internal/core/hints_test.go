@@ -0,0 +1,105 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSelectQuery_IndexHints_MySQL tests USE/FORCE/IGNORE INDEX rendering
+func TestSelectQuery_IndexHints_MySQL(t *testing.T) {
+	db := mockDB("mysql")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("*").
+		From("users u").
+		UseIndex("idx_users_email").
+		IgnoreIndex("idx_a", "idx_b").
+		InnerJoin("orders o", "o.user_id = u.id").
+		Where("u.email = ?", "a@example.com").
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t,
+		"SELECT * FROM `users` AS `u` USE INDEX (`idx_users_email`) IGNORE INDEX (`idx_a`, `idx_b`) "+
+			"INNER JOIN `orders` AS `o` ON o.user_id = u.id WHERE u.email = ?",
+		q.sql)
+
+	q = qb.Select("id").From("users").ForceIndex("PRIMARY").Build()
+	assert.Equal(t, "SELECT `id` FROM `users` FORCE INDEX (`PRIMARY`)", q.sql)
+}
+
+// TestSelectQuery_IndexHints_IgnoredOnOtherDialects tests that index hints are dropped outside MySQL
+func TestSelectQuery_IndexHints_IgnoredOnOtherDialects(t *testing.T) {
+	for _, name := range []string{"postgres", "sqlite"} {
+		t.Run(name, func(t *testing.T) {
+			qb := &QueryBuilder{db: mockDB(name)}
+			q := qb.Select("id").From("users").UseIndex("idx_users_email").Build()
+			require.NoError(t, q.prepErr)
+			assert.Equal(t, `SELECT "id" FROM "users"`, q.sql)
+		})
+	}
+}
+
+// TestSelectQuery_IndexHints_Empty tests validation of index names
+func TestSelectQuery_IndexHints_Empty(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("mysql")}
+
+	q := qb.Select("id").From("users").UseIndex().Build()
+	require.Error(t, q.prepErr)
+
+	q = qb.Select("id").From("users").ForceIndex(" ").Build()
+	require.Error(t, q.prepErr)
+}
+
+// TestSelectQuery_Hint tests planner hint placement per dialect
+func TestSelectQuery_Hint(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		hint    string
+		want    string
+	}{
+		{"PostgreSQL before statement", "postgres", "/*+ IndexScan(users idx_users_email) */",
+			`/*+ IndexScan(users idx_users_email) */ SELECT "id" FROM "users" WHERE email = $1`},
+		{"PostgreSQL wraps bare hint", "postgres", "SeqScan(users)",
+			`/*+ SeqScan(users) */ SELECT "id" FROM "users" WHERE email = $1`},
+		{"MySQL after SELECT", "mysql", "/*+ MAX_EXECUTION_TIME(1000) */",
+			"SELECT /*+ MAX_EXECUTION_TIME(1000) */ `id` FROM `users` WHERE email = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := &QueryBuilder{db: mockDB(tt.dialect)}
+			q := qb.Select("id").From("users").Where("email = ?", "a@example.com").Hint(tt.hint).Build()
+			require.NoError(t, q.prepErr)
+			assert.Equal(t, tt.want, q.sql)
+		})
+	}
+}
+
+// TestSelectQuery_Hint_WithCTE tests that PostgreSQL hints precede the WITH clause
+func TestSelectQuery_Hint_WithCTE(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("mysql")}
+
+	cte := qb.Select("id").From("users")
+	q := qb.Select("*").With("u", cte).From("u").Hint("NO_ICP(u)").Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "WITH `u` AS (SELECT `id` FROM `users`) SELECT /*+ NO_ICP(u) */ * FROM `u`", q.sql)
+
+	pg := &QueryBuilder{db: mockDB("postgres")}
+	q = pg.Select("*").With("u", pg.Select("id").From("users")).From("u").Hint("SeqScan(users)").Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `/*+ SeqScan(users) */ WITH "u" AS (SELECT "id" FROM "users") SELECT * FROM "u"`, q.sql)
+}
+
+// TestSelectQuery_Hint_Invalid tests that hints cannot break out of the comment
+func TestSelectQuery_Hint_Invalid(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	for _, hint := range []string{"", "x */ DROP TABLE users; /*", "/*+ a */ /*+ b */", "/*+ a /* b */"} {
+		q := qb.Select("id").From("users").Hint(hint).Build()
+		assert.Error(t, q.prepErr, hint)
+	}
+}
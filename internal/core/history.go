@@ -0,0 +1,307 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// History tables
+// ============================================================================
+//
+// A table registered with WithHistory has a shadow table <table>_history with
+// the columns valid_from and valid_to followed by the table's own columns.
+// Model().Update, UpdateChanged and Delete first copy the current row into
+// the history table, in the same transaction as the write, with valid_to set
+// to the time of the change and valid_from to the end of the row's previous
+// version. Model().Insert records the new row as a version that starts and
+// ends at the time of the insert: it is never valid itself, but it dates the
+// start of the row's first version.
+//
+// AsOf reads a table as it was at a point in time: the history versions valid
+// at that time, and the current rows that have not changed since. Rows
+// inserted after that time have a later version and are excluded. Rows with
+// no history at all, written before the table was registered or by other
+// means, have an unknown start (NULL valid_from) and count as always present.
+//
+// Only Model writes are recorded; INSERT, UPDATE and DELETE statements built
+// with the query builder, and Model().Upsert, are not. Schema changes to the
+// table must be mirrored on the history table, appending new columns to both.
+
+// HistoryTableSuffix is appended to a table name to form its history table.
+const HistoryTableSuffix = "_history"
+
+// History table columns holding the validity period of a row version.
+const (
+	historyValidFrom = "valid_from"
+	historyValidTo   = "valid_to"
+)
+
+// WithHistory records the previous version of every row of table changed or
+// deleted through Model() in the history table <table>_history, and the time
+// every row was inserted through Model(). keys are the primary key columns
+// used to match versions in AsOf queries (default "id"). Use the option once
+// per table; create the history table with CreateHistoryTable or a migration.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn,
+//	    relica.WithHistory("users"),
+//	    relica.WithHistory("order_items", "order_id", "line"))
+func WithHistory(table string, keys ...string) Option {
+	return optionFunc(func(db *DB) {
+		if len(keys) == 0 {
+			keys = []string{defaultChangeKey}
+		}
+		if db.history == nil {
+			db.history = make(map[string][]string)
+		}
+		db.history[table] = keys
+	})
+}
+
+// historyTable returns the name of the history table of table.
+func historyTable(table string) string {
+	return table + HistoryTableSuffix
+}
+
+// CreateHistoryTable creates the history table of table if it does not
+// exist: valid_from and valid_to timestamps followed by a copy of the
+// table's columns, without constraints or defaults. The table itself must
+// already exist.
+func (db *DB) CreateHistoryTable(ctx context.Context, table string) error {
+	if table == "" {
+		return errors.New("relica: CreateHistoryTable: table name is empty")
+	}
+	d := db.dialect
+	hist := quoteColumn(historyTable(table), d)
+	validFrom, validTo := d.QuoteIdentifier(historyValidFrom), d.QuoteIdentifier(historyValidTo)
+
+	var ddl string
+	switch d.(type) {
+	case *dialects.PostgresDialect:
+		ddl = "CREATE TABLE IF NOT EXISTS " + hist + " AS SELECT CAST(NULL AS TIMESTAMPTZ) AS " + validFrom +
+			", CAST(NULL AS TIMESTAMPTZ) AS " + validTo + ", t.* FROM " + quoteColumn(table, d) + " t WHERE 1 = 0"
+	case *dialects.MySQLDialect:
+		ddl = "CREATE TABLE IF NOT EXISTS " + hist + " AS SELECT CAST(NULL AS DATETIME(6)) AS " + validFrom +
+			", CAST(NULL AS DATETIME(6)) AS " + validTo + ", t.* FROM " + quoteColumn(table, d) + " t WHERE 1 = 0"
+	default:
+		// CREATE TABLE AS loses SQLite's declared column types, which drivers
+		// use to decode times, so the columns are copied from the catalog.
+		cols, err := sqliteColumnDefs(ctx, db, table)
+		if err != nil {
+			return err
+		}
+		ddl = "CREATE TABLE IF NOT EXISTS " + hist + " (" + validFrom + " DATETIME, " + validTo + " DATETIME, " +
+			strings.Join(cols, ", ") + ")"
+	}
+	_, err := db.ExecContext(ctx, ddl)
+	return err
+}
+
+// sqliteColumnDefs returns the quoted names and declared types of the
+// columns of a SQLite table, in table order.
+func sqliteColumnDefs(ctx context.Context, db *DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, type FROM pragma_table_info(?) ORDER BY cid", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, err
+		}
+		cols = append(cols, strings.TrimSpace(db.dialect.QuoteIdentifier(name)+" "+typ))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("relica: CreateHistoryTable: table %s not found", table)
+	}
+	return cols, nil
+}
+
+// writeWithHistory runs write with a builder for the model's table. If the
+// table has a history table, the current version of the row identified by
+// pkCols and pkValues is recorded first, in the same transaction as write:
+// the model's transaction, or a new one.
+func (mq *ModelQuery) writeWithHistory(pkCols []string, pkValues []interface{}, write func(qb *QueryBuilder) error) error {
+	qb := &QueryBuilder{db: mq.db, tx: mq.tx, ctx: mq.ctx}
+	if _, ok := mq.db.history[mq.table]; !ok {
+		return write(qb)
+	}
+	if mq.tx != nil {
+		if err := recordHistory(qb, mq.table, pkCols, pkValues); err != nil {
+			return err
+		}
+		return write(qb)
+	}
+
+	ctx := mq.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return mq.db.Transactional(ctx, func(tx *Tx) error {
+		txqb := &QueryBuilder{db: mq.db, tx: tx.tx, ctx: ctx}
+		if err := recordHistory(txqb, mq.table, pkCols, pkValues); err != nil {
+			return err
+		}
+		return write(txqb)
+	})
+}
+
+// insertWithHistory runs insert with a builder for the model's table. If the
+// table has a history table, the inserted row, identified by the model's
+// primary key once insert has populated it, is then recorded as a version
+// valid from and until now, in the same transaction as insert.
+func (mq *ModelQuery) insertWithHistory(insert func(qb *QueryBuilder) error) error {
+	qb := &QueryBuilder{db: mq.db, tx: mq.tx, ctx: mq.ctx}
+	if _, ok := mq.db.history[mq.table]; !ok {
+		return insert(qb)
+	}
+	record := func(qb *QueryBuilder) error {
+		if err := insert(qb); err != nil {
+			return err
+		}
+		pkCols, pkValues, err := mq.getPrimaryKeys()
+		if err != nil {
+			return errors.New("model: primary key not found")
+		}
+		now := time.Now().UTC()
+		return recordVersion(qb, mq.table, pkCols, pkValues, now, now)
+	}
+	if mq.tx != nil {
+		return record(qb)
+	}
+
+	ctx := mq.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return mq.db.Transactional(ctx, func(tx *Tx) error {
+		return record(&QueryBuilder{db: mq.db, tx: tx.tx, ctx: ctx})
+	})
+}
+
+// recordHistory copies the row of table identified by pkCols and pkValues
+// into the history table, valid until now.
+func recordHistory(qb *QueryBuilder, table string, pkCols []string, pkValues []interface{}) error {
+	d := qb.db.dialect
+	hist := quoteColumn(historyTable(table), d)
+
+	// The new version starts where the previous one ended.
+	var validFrom interface{}
+	last := &Query{
+		db:     qb.db,
+		tx:     qb.tx,
+		ctx:    qb.ctx,
+		sql:    renumberFragment("SELECT MAX(t."+d.QuoteIdentifier(historyValidTo)+") FROM "+hist+" t WHERE "+historyKeyMatch(pkCols, d), 1, len(pkValues), d),
+		params: pkValues,
+	}
+	if err := last.Row(&validFrom); err != nil {
+		return fmt.Errorf("relica: history of %s: %w", table, err)
+	}
+	return recordVersion(qb, table, pkCols, pkValues, validFrom, time.Now().UTC())
+}
+
+// recordVersion copies the row of table identified by pkCols and pkValues
+// into the history table, valid from validFrom until validTo.
+func recordVersion(qb *QueryBuilder, table string, pkCols []string, pkValues []interface{}, validFrom interface{}, validTo time.Time) error {
+	db := qb.db
+	d := db.dialect
+	ts := historyTimeParam(d)
+	params := append([]interface{}{validFrom, validTo}, pkValues...)
+	insert := db.guard(&Query{
+		db:  db,
+		tx:  qb.tx,
+		ctx: qb.ctx,
+		sql: renumberFragment("INSERT INTO "+quoteColumn(historyTable(table), d)+" SELECT "+ts+", "+ts+", t.* FROM "+
+			quoteColumn(table, d)+" t WHERE "+historyKeyMatch(pkCols, d), 1, len(params), d),
+		params: params,
+	})
+	if _, err := insert.Execute(); err != nil {
+		return fmt.Errorf("relica: history of %s: %w", table, err)
+	}
+	return nil
+}
+
+// historyKeyMatch returns the condition matching the key columns of the
+// table aliased t to placeholders.
+func historyKeyMatch(pkCols []string, dialect dialects.Dialect) string {
+	conds := make([]string, len(pkCols))
+	for i, col := range pkCols {
+		conds[i] = "t." + dialect.QuoteIdentifier(col) + " = ?"
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// historyTimeParam returns a timestamp placeholder typed for dialect, as
+// PostgreSQL cannot infer the type of a parameter in a SELECT list.
+func historyTimeParam(dialect dialects.Dialect) string {
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		return "CAST(? AS TIMESTAMPTZ)"
+	case *dialects.MySQLDialect:
+		return "CAST(? AS DATETIME(6))"
+	default:
+		return "?"
+	}
+}
+
+// AsOf reads the FROM table as it was at the given time, using its history
+// table (see WithHistory). The table keeps its alias, or is aliased by its
+// name, so the rest of the query is unchanged. Rows inserted through Model()
+// after at are excluded. Result rows also carry the valid_from and valid_to
+// columns of their version (NULL valid_to for rows that are still current).
+//
+// Example:
+//
+//	var users []User
+//	err := db.Select().From("users").AsOf(lastMonth).
+//	    Where(relica.Eq("status", "active")).All(&users)
+func (sq *SelectQuery) AsOf(at time.Time) *SelectQuery {
+	sq = sq.own()
+	t := at.UTC()
+	sq.asOf = &t
+	return sq
+}
+
+// buildAsOf returns the derived table replacing table (with an optional
+// alias) in the FROM clause of an AsOf query, appending its parameters, or
+// the error of a table without history.
+func (sq *SelectQuery) buildAsOf(table string, dialect dialects.Dialect, params *[]interface{}) (string, error) {
+	parts := strings.Fields(table)
+	name, alias := parts[0], lastSegment(parts[0])
+	if len(parts) == 2 {
+		alias = parts[1]
+	}
+	keys, ok := sq.builder.db.history[name]
+	if !ok {
+		return quoteColumn(name, dialect), fmt.Errorf("relica: AsOf: table %s has no history table (see WithHistory)", name)
+	}
+
+	hist := quoteColumn(historyTable(name), dialect)
+	validFrom, validTo := dialect.QuoteIdentifier(historyValidFrom), dialect.QuoteIdentifier(historyValidTo)
+	match := make([]string, len(keys))
+	for i, k := range keys {
+		match[i] = "p." + dialect.QuoteIdentifier(k) + " = t." + dialect.QuoteIdentifier(k)
+	}
+	correlated := strings.Join(match, " AND ")
+
+	derived := "(SELECT h.* FROM " + hist + " h WHERE (h." + validFrom + " IS NULL OR h." + validFrom + " <= ?) AND h." + validTo + " > ?" +
+		" UNION ALL SELECT (SELECT MAX(p." + validTo + ") FROM " + hist + " p WHERE " + correlated + ") AS " + validFrom +
+		", NULL AS " + validTo + ", t.* FROM " + quoteColumn(name, dialect) + " t" +
+		" WHERE NOT EXISTS (SELECT 1 FROM " + hist + " p WHERE " + correlated + " AND p." + validTo + " > ?))"
+	derived = renumberFragment(derived, len(*params)+1, 3, dialect)
+	*params = append(*params, *sq.asOf, *sq.asOf, *sq.asOf)
+	return derived + " AS " + dialect.QuoteIdentifier(alias), nil
+}
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type historyItem struct {
+	ID    int    `db:"id,pk"`
+	Name  string `db:"name"`
+	Price int    `db:"price"`
+}
+
+func (historyItem) TableName() string { return "items" }
+
+func setupHistoryDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:", WithHistory("items"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL, price INTEGER NOT NULL)`)
+	require.NoError(t, err)
+	require.NoError(t, db.CreateHistoryTable(ctx, "items"))
+	require.NoError(t, db.CreateHistoryTable(ctx, "items"), "creating an existing table is a no-op")
+	return db
+}
+
+// historyTick returns a time strictly between the previous and the next change.
+func historyTick() time.Time {
+	time.Sleep(5 * time.Millisecond)
+	at := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	return at
+}
+
+func TestHistory_ModelWrites(t *testing.T) {
+	db := setupHistoryDB(t)
+
+	item := historyItem{ID: 1, Name: "pen", Price: 10}
+	require.NoError(t, db.Model(&item).Insert())
+	beforeUpdate := historyTick()
+
+	item.Price = 12
+	require.NoError(t, db.Model(&item).Update())
+	afterUpdate := historyTick()
+
+	original := item
+	item.Name = "blue pen"
+	require.NoError(t, db.Model(&item).UpdateChanged(&original))
+	afterRename := historyTick()
+
+	require.NoError(t, db.Model(&item).Delete())
+
+	var versions []historyItem
+	require.NoError(t, db.Builder().Select().From("items_history").OrderBy("valid_to").All(&versions))
+	assert.Equal(t, []historyItem{
+		{ID: 1, Name: "pen", Price: 10}, // the insert
+		{ID: 1, Name: "pen", Price: 10},
+		{ID: 1, Name: "pen", Price: 12},
+		{ID: 1, Name: "blue pen", Price: 12},
+	}, versions)
+
+	var nulls int
+	require.NoError(t, db.Builder().Select("COUNT(*)").From("items_history").
+		Where("valid_from IS NULL").Row(&nulls))
+	assert.Zero(t, nulls, "every version starts at the insert or a change")
+
+	asOf := func(at time.Time) []historyItem {
+		var got []historyItem
+		require.NoError(t, db.Builder().Select().From("items").AsOf(at).All(&got))
+		return got
+	}
+	assert.Equal(t, []historyItem{{ID: 1, Name: "pen", Price: 10}}, asOf(beforeUpdate))
+	assert.Equal(t, []historyItem{{ID: 1, Name: "pen", Price: 12}}, asOf(afterUpdate))
+	assert.Equal(t, []historyItem{{ID: 1, Name: "blue pen", Price: 12}}, asOf(afterRename))
+	assert.Empty(t, asOf(time.Now()), "deleted rows are gone")
+}
+
+func TestHistory_AsOfCurrentRows(t *testing.T) {
+	db := setupHistoryDB(t)
+
+	a := historyItem{ID: 1, Name: "a", Price: 1}
+	b := historyItem{ID: 2, Name: "b", Price: 2}
+	require.NoError(t, db.Model(&a).Insert())
+	require.NoError(t, db.Model(&b).Insert())
+	before := historyTick()
+
+	b.Price = 3
+	require.NoError(t, db.Model(&b).Update())
+
+	var got []historyItem
+	require.NoError(t, db.Builder().Select().From("items i").AsOf(before).
+		Where(GreaterOrEqual("i.price", 1)).OrderBy("i.id").All(&got))
+	assert.Equal(t, []historyItem{{ID: 1, Name: "a", Price: 1}, {ID: 2, Name: "b", Price: 2}}, got)
+
+	got = nil
+	require.NoError(t, db.Builder().Select().From("items").AsOf(time.Now()).OrderBy("id").All(&got))
+	assert.Equal(t, []historyItem{{ID: 1, Name: "a", Price: 1}, {ID: 2, Name: "b", Price: 3}}, got)
+}
+
+func TestHistory_Transaction(t *testing.T) {
+	db := setupHistoryDB(t)
+	ctx := context.Background()
+
+	item := historyItem{ID: 1, Name: "pen", Price: 10}
+	require.NoError(t, db.Model(&item).Insert())
+
+	errAbort := errors.New("abort")
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		item.Price = 20
+		require.NoError(t, tx.Model(&item).Update())
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	var count int
+	require.NoError(t, db.Builder().Select("COUNT(*)").From("items_history").Row(&count))
+	assert.Equal(t, 1, count, "only the committed insert is recorded, not the rolled back update")
+
+	require.NoError(t, db.Transactional(ctx, func(tx *Tx) error {
+		return tx.Model(&item).Update()
+	}))
+	require.NoError(t, db.Builder().Select("COUNT(*)").From("items_history").Row(&count))
+	assert.Equal(t, 2, count)
+}
+
+func TestHistory_AsOfExcludesLaterInserts(t *testing.T) {
+	db := setupHistoryDB(t)
+
+	a := historyItem{ID: 1, Name: "a", Price: 1}
+	require.NoError(t, db.Model(&a).Insert())
+	before := historyTick()
+
+	b := historyItem{Name: "b", Price: 2}
+	require.NoError(t, db.Model(&b).Insert())
+	require.NotZero(t, b.ID)
+	afterInsert := historyTick()
+
+	b.Price = 3
+	require.NoError(t, db.Model(&b).Update())
+
+	asOf := func(at time.Time) []historyItem {
+		var got []historyItem
+		require.NoError(t, db.Builder().Select().From("items").AsOf(at).OrderBy("id").All(&got))
+		return got
+	}
+	assert.Equal(t, []historyItem{a}, asOf(before), "rows inserted later are absent")
+	assert.Equal(t, []historyItem{a, {ID: b.ID, Name: "b", Price: 2}}, asOf(afterInsert))
+	assert.Equal(t, []historyItem{a, b}, asOf(time.Now()))
+
+	n, err := db.Builder().Select().From("items").AsOf(before).Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "Count reads the snapshot")
+	n, err = db.Builder().Select().From("items").AsOf(afterInsert).Where(Eq("price", 2)).Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	exists, err := db.Builder().Select().From("items").AsOf(before).Where(Eq("id", b.ID)).Exists()
+	require.NoError(t, err)
+	assert.False(t, exists, "Exists reads the snapshot")
+	exists, err = db.Builder().Select().From("items").AsOf(afterInsert).Where(Eq("id", b.ID)).Exists()
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Rows written without Model have no history and an unknown start
+	_, err = db.Builder().Insert("items", map[string]interface{}{"id": 10, "name": "c", "price": 4}).Execute()
+	require.NoError(t, err)
+	assert.Len(t, asOf(before), 2)
+
+	// A rolled back insert leaves no history
+	errAbort := errors.New("abort")
+	err = db.Transactional(context.Background(), func(tx *Tx) error {
+		require.NoError(t, tx.Model(&historyItem{ID: 20, Name: "d", Price: 5}).Insert())
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	var count int
+	require.NoError(t, db.Builder().Select("COUNT(*)").From("items_history").Where("id = 20").Row(&count))
+	assert.Zero(t, count)
+}
+
+func TestHistory_UnregisteredTable(t *testing.T) {
+	db := setupHistoryDB(t)
+	_, err := db.ExecContext(context.Background(), `CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+
+	_, err = db.Builder().Insert("tags", map[string]interface{}{"id": 1, "name": "x"}).Execute()
+	require.NoError(t, err)
+
+	err = db.Builder().Select().From("tags").AsOf(time.Now()).All(&[]historyItem{})
+	assert.ErrorContains(t, err, "relica: AsOf: table tags has no history table")
+
+	err = db.Builder().Select().FromSelect(db.Builder().Select().From("items"), "i").AsOf(time.Now()).All(&[]historyItem{})
+	assert.ErrorContains(t, err, "relica: AsOf requires a FROM table")
+
+	assert.ErrorContains(t, db.CreateHistoryTable(context.Background(), "missing"), "table missing not found")
+}
+
+func TestSelectQuery_AsOf_SQL(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	db := mockDB("postgres")
+	WithHistory("order_items", "order_id", "line").apply(db)
+	q := (&QueryBuilder{db: db}).Select("o.line").From("order_items o").AsOf(at).
+		Where(Eq("o.sku", "A1")).Build()
+	sql, args := q.SQL(), q.Params()
+
+	assert.Equal(t, `SELECT "o"."line" FROM (SELECT h.* FROM "order_items_history" h WHERE (h."valid_from" IS NULL OR h."valid_from" <= $1) AND h."valid_to" > $2`+
+		` UNION ALL SELECT (SELECT MAX(p."valid_to") FROM "order_items_history" p WHERE p."order_id" = t."order_id" AND p."line" = t."line") AS "valid_from",`+
+		` NULL AS "valid_to", t.* FROM "order_items" t WHERE NOT EXISTS (SELECT 1 FROM "order_items_history" p`+
+		` WHERE p."order_id" = t."order_id" AND p."line" = t."line" AND p."valid_to" > $3)) AS "o" WHERE "o"."sku" = $4`, sql)
+	require.Len(t, args, 4)
+	assert.Equal(t, at.UTC(), args[0], "times are compared in UTC")
+	assert.Equal(t, time.UTC, args[0].(time.Time).Location())
+}
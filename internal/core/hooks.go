@@ -31,6 +31,8 @@ type QueryEvent struct {
 	Error error
 	// Operation is the SQL operation type (SELECT, INSERT, UPDATE, DELETE, UNKNOWN)
 	Operation string
+	// Tag is the query tag set with Tag ("" for untagged queries)
+	Tag string
 }
 
 // QueryHook is a callback function invoked after each query execution.
@@ -45,7 +47,7 @@ type QueryEvent struct {
 type QueryHook func(ctx context.Context, event QueryEvent)
 
 // DetectOperation attempts to detect the SQL operation type from the query string.
-// Returns one of: SELECT, INSERT, UPDATE, DELETE, or UNKNOWN.
+// Returns one of: SELECT, INSERT, UPDATE, DELETE, MERGE, or UNKNOWN.
 func DetectOperation(sql string) string {
 	sql = strings.TrimSpace(strings.ToUpper(sql))
 	if strings.HasPrefix(sql, opSelect) || strings.HasPrefix(sql, "WITH") {
@@ -60,6 +62,9 @@ func DetectOperation(sql string) string {
 	if strings.HasPrefix(sql, opDelete) {
 		return opDelete
 	}
+	if strings.HasPrefix(sql, opMerge) {
+		return opMerge
+	}
 	return opUnknown
 }
 
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/coregx/relica/internal/util"
+)
+
+// ============================================================================
+// ID generators
+// ============================================================================
+//
+// ULIDs, Snowflake IDs and UUIDs are generated in the application, so a row's
+// key is known before it is inserted and no RETURNING or LastInsertId round
+// trip is needed. ULIDs, Snowflake IDs and UUIDv7 sort by creation time.
+// Model().Insert() fills zero fields tagged relica:"ulid" (string fields),
+// relica:"snowflake" (64-bit integer fields), relica:"uuid" or relica:"uuidv7"
+// (string or [16]byte fields such as uuid.UUID) before building the INSERT.
+
+// ulidAlphabet is Crockford's base32 alphabet used by ULIDs.
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidState makes ULIDs generated within the same millisecond monotonic.
+var ulidState struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+// NewULID returns a new ULID: 26 characters encoding a 48-bit millisecond
+// timestamp and 80 random bits. ULIDs generated by one process are strictly
+// increasing; within a millisecond the random part is incremented.
+func NewULID() string {
+	ms := uint64(time.Now().UnixMilli()) //nolint:gosec // Unix time is positive.
+
+	ulidState.mu.Lock()
+	if ms <= ulidState.lastMs {
+		ms = ulidState.lastMs
+		for i := len(ulidState.entropy) - 1; i >= 0; i-- {
+			ulidState.entropy[i]++
+			if ulidState.entropy[i] != 0 {
+				break
+			}
+		}
+	} else {
+		_, _ = rand.Read(ulidState.entropy[:])
+	}
+	ulidState.lastMs = ms
+
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], ulidState.entropy[:])
+	ulidState.mu.Unlock()
+
+	// Encode 128 bits as 26 base32 digits, least significant first.
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = ulidAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// NewUUID returns a random (version 4) UUID in its canonical 36-character form.
+func NewUUID() string {
+	return formatUUID(newUUIDv4())
+}
+
+// NewUUIDv7 returns a time-ordered (version 7) UUID in its canonical form:
+// a 48-bit millisecond timestamp followed by 74 random bits. Being roughly
+// sequential, UUIDv7 keys keep B-tree indexes compact.
+func NewUUIDv7() string {
+	return formatUUID(newUUIDv7())
+}
+
+func newUUIDv4() [16]byte {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	return u
+}
+
+func newUUIDv7() [16]byte {
+	var u [16]byte
+	ms := uint64(time.Now().UnixMilli()) //nolint:gosec // Unix time is positive.
+	binary.BigEndian.PutUint16(u[:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+	_, _ = rand.Read(u[6:])
+	u[6] = u[6]&0x0f | 0x70 // version 7
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	return u
+}
+
+// formatUUID formats u as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func formatUUID(u [16]byte) string {
+	var out [36]byte
+	hex.Encode(out[0:8], u[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], u[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], u[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], u[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], u[10:])
+	return string(out[:])
+}
+
+// snowflakeEpoch is the Snowflake time origin, 2024-01-01T00:00:00Z in Unix milliseconds.
+const snowflakeEpoch = 1704067200000
+
+// Snowflake ID layout: 41 bits of milliseconds, 10 bits of node, 12 bits of sequence.
+const (
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	snowflakeMaxNode  = 1<<snowflakeNodeBits - 1
+	snowflakeSeqMask  = 1<<snowflakeSeqBits - 1
+)
+
+// Snowflake generates 63-bit, time-ordered integer IDs that are unique across
+// up to 1024 nodes (processes) as long as every node uses its own node number.
+type Snowflake struct {
+	mu   sync.Mutex
+	node int64
+	last int64 // milliseconds since snowflakeEpoch of the last ID
+	seq  int64
+}
+
+// NewSnowflake returns a generator for node, which must be in [0, 1023].
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, fmt.Errorf("relica: snowflake node %d out of range [0, %d]", node, snowflakeMaxNode)
+	}
+	return &Snowflake{node: node}, nil
+}
+
+// Next returns the next ID. Up to 4096 IDs are generated per millisecond; when
+// they run out, or the clock moves backwards, the timestamp is advanced past the
+// last one so IDs stay unique and increasing.
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli() - snowflakeEpoch
+	if now <= s.last {
+		s.seq = (s.seq + 1) & snowflakeSeqMask
+		now = s.last
+		if s.seq == 0 {
+			now++
+		}
+	} else {
+		s.seq = 0
+	}
+	s.last = now
+	return now<<(snowflakeNodeBits+snowflakeSeqBits) | s.node<<snowflakeSeqBits | s.seq
+}
+
+// defaultSnowflake generates Snowflake IDs for DBs opened without WithSnowflakeNode.
+var defaultSnowflake = &Snowflake{}
+
+// WithSnowflakeNode sets the node number of the Snowflake IDs that Model().Insert()
+// generates for relica:"snowflake" fields (default 0). Give every process that
+// inserts into the same tables its own node in [0, 1023]; only the low 10 bits
+// of node are used.
+func WithSnowflakeNode(node int64) Option {
+	return optionFunc(func(db *DB) {
+		db.snowflake = &Snowflake{node: node & snowflakeMaxNode}
+	})
+}
+
+// generateIDs fills the zero fields of the model tagged with an ID generator
+// (relica:"ulid", "snowflake", "uuid" or "uuidv7").
+func (mq *ModelQuery) generateIDs() error {
+	v := reflect.ValueOf(mq.model)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		gen := util.RelicaIDGenerator(field)
+		if gen == "" || !field.IsExported() || !v.Field(i).IsZero() {
+			continue
+		}
+		fv := v.Field(i)
+		switch {
+		case gen == "ulid" && fv.Kind() == reflect.String:
+			fv.SetString(NewULID())
+		case gen == "snowflake" && fv.CanInt() && fv.Type().Bits() == 64:
+			fv.SetInt(mq.db.snowflakeGen().Next())
+		case gen == "snowflake" && fv.CanUint() && fv.Type().Bits() == 64:
+			fv.SetUint(uint64(mq.db.snowflakeGen().Next())) //nolint:gosec // Snowflake IDs are positive.
+		case gen == "uuid" || gen == "uuidv7":
+			u := newUUIDv4()
+			if gen == "uuidv7" {
+				u = newUUIDv7()
+			}
+			if !setUUID(fv, u) {
+				return fmt.Errorf("model: field %s: relica:%q is not supported for %s fields", field.Name, gen, fv.Type())
+			}
+		default:
+			return fmt.Errorf("model: field %s: relica:%q is not supported for %s fields", field.Name, gen, fv.Type())
+		}
+	}
+	return nil
+}
+
+// setUUID stores u in a string field (canonical form) or a [16]byte field.
+func setUUID(fv reflect.Value, u [16]byte) bool {
+	switch {
+	case fv.Kind() == reflect.String:
+		fv.SetString(formatUUID(u))
+	case fv.Kind() == reflect.Array && fv.Len() == 16 && fv.Type().Elem().Kind() == reflect.Uint8:
+		reflect.Copy(fv, reflect.ValueOf(u[:]))
+	default:
+		return false
+	}
+	return true
+}
+
+// snowflakeGen returns the Snowflake generator of db.
+func (db *DB) snowflakeGen() *Snowflake {
+	if db.snowflake != nil {
+		return db.snowflake
+	}
+	return defaultSnowflake
+}
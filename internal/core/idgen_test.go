@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewULID(t *testing.T) {
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = NewULID()
+	}
+	assert.True(t, sort.StringsAreSorted(ids), "ULIDs of one process are increasing")
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		require.Len(t, id, 26)
+		assert.Empty(t, strings.Trim(id, ulidAlphabet))
+		assert.False(t, seen[id], "duplicate ULID %s", id)
+		seen[id] = true
+	}
+
+	// The first 10 characters encode the millisecond timestamp.
+	var ms int64
+	for _, c := range ids[0][:10] {
+		ms = ms<<5 | int64(strings.IndexRune(ulidAlphabet, c))
+	}
+	assert.WithinDuration(t, time.Now(), time.UnixMilli(ms), time.Minute)
+}
+
+func TestSnowflake(t *testing.T) {
+	_, err := NewSnowflake(1024)
+	assert.ErrorContains(t, err, "out of range")
+
+	s, err := NewSnowflake(5)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var ids []int64
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				id := s.Next()
+				mu.Lock()
+				ids = append(ids, id)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		require.False(t, seen[id], "duplicate snowflake %d", id)
+		seen[id] = true
+		assert.Equal(t, int64(5), id>>snowflakeSeqBits&snowflakeMaxNode)
+	}
+	ms := ids[0]>>(snowflakeNodeBits+snowflakeSeqBits) + snowflakeEpoch
+	assert.WithinDuration(t, time.Now(), time.UnixMilli(ms), time.Minute)
+
+	first, second := s.Next(), s.Next()
+	assert.Less(t, first, second)
+}
+
+type ulidDoc struct {
+	ID    string `db:"id" relica:"pk,ulid"`
+	Title string `db:"title"`
+}
+
+func (ulidDoc) TableName() string { return "docs" }
+
+type snowflakeEvent struct {
+	ID   int64  `db:"id" relica:"snowflake"`
+	Name string `db:"name"`
+}
+
+func (snowflakeEvent) TableName() string { return "events" }
+
+func TestModelInsert_GeneratedIDs(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithSnowflakeNode(3))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE docs (id TEXT PRIMARY KEY, title TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	doc := ulidDoc{Title: "spec"}
+	require.NoError(t, db.Model(&doc).Insert())
+	require.Len(t, doc.ID, 26)
+	var stored string
+	require.NoError(t, db.NewQuery("SELECT id FROM docs").Row(&stored))
+	assert.Equal(t, doc.ID, stored)
+
+	preset := ulidDoc{ID: "preset", Title: "kept"}
+	require.NoError(t, db.Model(&preset).Insert())
+	assert.Equal(t, "preset", preset.ID, "non-zero IDs are kept")
+
+	ev := snowflakeEvent{Name: "signup"}
+	require.NoError(t, db.Model(&ev).Insert())
+	assert.Equal(t, int64(3), ev.ID>>snowflakeSeqBits&snowflakeMaxNode)
+	var storedID int64
+	require.NoError(t, db.NewQuery("SELECT id FROM events").Row(&storedID))
+	assert.Equal(t, ev.ID, storedID, "the generated ID is inserted, not replaced by LastInsertId")
+
+	type badModel struct {
+		ID int32 `db:"id" relica:"snowflake"`
+	}
+	err = db.Model(&badModel{}).Table("events").Insert()
+	assert.ErrorContains(t, err, `relica:"snowflake" is not supported for int32 fields`)
+}
+
+func TestNewUUID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[47][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	v4, v7 := NewUUID(), NewUUIDv7()
+	assert.Regexp(t, uuidPattern, v4)
+	assert.Regexp(t, uuidPattern, v7)
+	assert.Equal(t, byte('4'), v4[14], "version nibble")
+	assert.Equal(t, byte('7'), v7[14], "version nibble")
+	assert.NotEqual(t, NewUUID(), NewUUID())
+
+	ms, err := strconv.ParseInt(strings.ReplaceAll(v7[:13], "-", ""), 16, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.UnixMilli(ms), time.Minute)
+}
+
+// binaryUUID mimics uuid.UUID: a [16]byte stored in its canonical text form.
+type binaryUUID [16]byte
+
+func (u binaryUUID) Value() (driver.Value, error) { return formatUUID(u), nil }
+
+type uuidAccount struct {
+	ID   string `db:"id" relica:"pk,uuid"`
+	Name string `db:"name"`
+}
+
+func (uuidAccount) TableName() string { return "accounts" }
+
+type uuidSession struct {
+	ID    binaryUUID `db:"id" relica:"pk,uuidv7"`
+	Token string     `db:"token"`
+}
+
+func (uuidSession) TableName() string { return "sessions" }
+
+func TestModelInsert_UUID(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE accounts (id TEXT PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "CREATE TABLE sessions (id TEXT PRIMARY KEY, token TEXT)")
+	require.NoError(t, err)
+
+	acct := uuidAccount{Name: "acme"}
+	require.NoError(t, db.Model(&acct).Insert())
+	assert.Len(t, acct.ID, 36)
+	var stored uuidAccount
+	require.NoError(t, db.Builder().Select().From("accounts").Where(Eq("id", acct.ID)).One(&stored))
+	assert.Equal(t, "acme", stored.Name)
+
+	sess := uuidSession{Token: "t"}
+	require.NoError(t, db.Model(&sess).Insert())
+	assert.NotEqual(t, binaryUUID{}, sess.ID)
+	var storedID string
+	require.NoError(t, db.NewQuery("SELECT id FROM sessions").Row(&storedID))
+	assert.Equal(t, formatUUID(sess.ID), storedID)
+	assert.Equal(t, byte('7'), storedID[14])
+}
@@ -0,0 +1,292 @@
+package core
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// CSV import
+// ============================================================================
+//
+// ImportCSV is the counterpart of SelectQuery.ExportCSV: it streams a CSV
+// file into a table with multi-row INSERT statements, reading one batch at a
+// time. COPY is not used because it is only available through driver-specific
+// APIs; batched INSERTs work with every dialect and inside transactions.
+
+// defaultImportBatchSize is the number of rows per INSERT when ImportOptions.BatchSize is unset.
+const defaultImportBatchSize = 500
+
+// CoerceFunc converts a CSV field to the value inserted into a column.
+type CoerceFunc func(value string) (interface{}, error)
+
+// ImportOptions configures DB.ImportCSV.
+type ImportOptions struct {
+	// ColumnMap maps CSV header names to table columns. When set, only the
+	// mapped headers are imported; otherwise every header is used as a column name.
+	ColumnMap map[string]string
+	// Columns names the CSV fields when the input has no header row.
+	Columns []string
+	// Coerce converts the fields of the given table columns, e.g.
+	// {"age": CoerceInt}. Other fields are inserted as strings, which the
+	// database converts to the column type.
+	Coerce map[string]CoerceFunc
+	// Null is the field text imported as NULL (default "": empty fields are NULL).
+	Null string
+	// Comma is the field delimiter (default ',').
+	Comma rune
+	// BatchSize is the maximum number of rows per INSERT statement (default 500).
+	BatchSize int
+	// OnError is called for every row that cannot be imported. Returning nil
+	// skips the row; returning an error aborts the import. Without OnError the
+	// first bad row aborts the import.
+	OnError func(err *ImportError) error
+	// Progress is called after every inserted batch with the totals so far.
+	Progress func(ImportResult)
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Inserted int            // rows inserted
+	Skipped  int            // rows skipped by OnError
+	Errors   []*ImportError // errors of the skipped rows
+}
+
+// ImportError describes a CSV row that could not be imported.
+type ImportError struct {
+	Line   int    // line of the row in the input (1-based)
+	Column string // table column, empty for malformed rows
+	Value  string // field text that failed to convert
+	Err    error
+}
+
+func (e *ImportError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("relica: import line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("relica: import line %d, column %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportCSV streams CSV rows from r into table using batched multi-row INSERTs.
+// The first row is the header unless opts.Columns is set. All batches run in
+// one transaction, so an aborted import leaves the table unchanged; rows
+// reported by Progress are only visible once ImportCSV returns successfully.
+//
+// Example:
+//
+//	res, err := db.ImportCSV(ctx, "users", file, relica.ImportOptions{
+//	    ColumnMap: map[string]string{"E-mail": "email", "Age": "age"},
+//	    Coerce:    map[string]relica.CoerceFunc{"age": relica.CoerceInt},
+//	    OnError: func(err *relica.ImportError) error {
+//	        return nil // skip bad rows; they are listed in res.Errors
+//	    },
+//	})
+//
+//nolint:gocognit,cyclop // Header resolution, row conversion and batching read best together.
+func (db *DB) ImportCSV(ctx context.Context, table string, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	var res ImportResult
+
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.ReuseRecord = true
+
+	header := opts.Columns
+	if header == nil {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return res, fmt.Errorf("relica: import header: %w", err)
+		}
+		header = append([]string(nil), rec...)
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff") // UTF-8 byte order mark
+		}
+	}
+
+	var fields []int
+	var columns []string
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		col := name
+		if opts.ColumnMap != nil {
+			col = opts.ColumnMap[name]
+		}
+		if col == "" {
+			continue
+		}
+		fields = append(fields, i)
+		columns = append(columns, col)
+	}
+	if len(columns) == 0 {
+		return res, fmt.Errorf("relica: import into %s: no columns to import", table)
+	}
+	coerce := make([]CoerceFunc, len(columns))
+	for i, col := range columns {
+		coerce[i] = opts.Coerce[col]
+	}
+
+	batch := maxBatchParams / len(columns)
+	if batch == 0 {
+		batch = 1
+	}
+	size := opts.BatchSize
+	if size <= 0 {
+		size = defaultImportBatchSize
+	}
+	batch = min(batch, size)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		pending := make([][]interface{}, 0, batch)
+		flush := func() error {
+			if len(pending) == 0 {
+				return nil
+			}
+			q := tx.Builder().BatchInsert(table, columns)
+			for _, row := range pending {
+				q.Values(row...)
+			}
+			if _, err := q.Execute(); err != nil {
+				return fmt.Errorf("relica: import into %s: %w", table, err)
+			}
+			res.Inserted += len(pending)
+			pending = pending[:0]
+			if opts.Progress != nil {
+				opts.Progress(res)
+			}
+			return nil
+		}
+		reject := func(ierr *ImportError) error {
+			if opts.OnError == nil {
+				return ierr
+			}
+			if err := opts.OnError(ierr); err != nil {
+				return err
+			}
+			res.Skipped++
+			res.Errors = append(res.Errors, ierr)
+			return nil
+		}
+
+		for {
+			rec, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				var perr *csv.ParseError
+				if !errors.As(err, &perr) {
+					return fmt.Errorf("relica: import into %s: %w", table, err)
+				}
+				if err := reject(&ImportError{Line: perr.StartLine, Err: perr.Err}); err != nil {
+					return err
+				}
+				continue
+			}
+			line, _ := cr.FieldPos(0)
+
+			row, ierr := importRow(rec, fields, columns, coerce, opts.Null)
+			if ierr != nil {
+				ierr.Line = line
+				if err := reject(ierr); err != nil {
+					return err
+				}
+				continue
+			}
+			pending = append(pending, row)
+			if len(pending) == batch {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return flush()
+	})
+	if err != nil {
+		res.Inserted = 0 // rolled back
+		return res, err
+	}
+	return res, nil
+}
+
+// importRow converts the imported fields of rec to column values.
+func importRow(rec []string, fields []int, columns []string, coerce []CoerceFunc, null string) ([]interface{}, *ImportError) {
+	row := make([]interface{}, len(fields))
+	for i, f := range fields {
+		if f >= len(rec) {
+			return nil, &ImportError{Err: fmt.Errorf("row has %d fields, column %s is field %d", len(rec), columns[i], f+1)}
+		}
+		value := rec[f]
+		switch {
+		case value == null:
+			row[i] = nil
+		case coerce[i] != nil:
+			v, err := coerce[i](value)
+			if err != nil {
+				return nil, &ImportError{Column: columns[i], Value: value, Err: err}
+			}
+			row[i] = v
+		default:
+			row[i] = value
+		}
+	}
+	return row, nil
+}
+
+// CoerceInt parses a field as a base-10 integer.
+func CoerceInt(value string) (interface{}, error) {
+	return strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+}
+
+// CoerceFloat parses a field as a floating-point number.
+func CoerceFloat(value string) (interface{}, error) {
+	return strconv.ParseFloat(strings.TrimSpace(value), 64)
+}
+
+// CoerceBool parses a field as a boolean. In addition to the forms accepted
+// by strconv.ParseBool it accepts yes/no, y/n and on/off in any case.
+func CoerceBool(value string) (interface{}, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.TrimSpace(value))
+}
+
+// CoerceTime returns a CoerceFunc that parses a field with the first matching
+// layout. Without layouts it accepts RFC 3339, "2006-01-02 15:04:05" and
+// "2006-01-02". Times without a zone are UTC.
+func CoerceTime(layouts ...string) CoerceFunc {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+	}
+	return func(value string) (interface{}, error) {
+		value = strings.TrimSpace(value)
+		var err error
+		for _, layout := range layouts {
+			var t time.Time
+			if t, err = time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time %q: %w", value, err)
+	}
+}
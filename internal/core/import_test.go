@@ -0,0 +1,181 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupImportDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(context.Background(),
+		`CREATE TABLE members (id INTEGER PRIMARY KEY, email TEXT NOT NULL, age INTEGER, active BOOLEAN, joined DATETIME)`)
+	require.NoError(t, err)
+	return db
+}
+
+type importedMember struct {
+	ID     int64      `db:"id"`
+	Email  string     `db:"email"`
+	Age    *int64     `db:"age"`
+	Active bool       `db:"active"`
+	Joined *time.Time `db:"joined"`
+}
+
+func TestDB_ImportCSV(t *testing.T) {
+	db := setupImportDB(t)
+	ctx := context.Background()
+
+	input := "\ufeffE-mail,Age,Active,Joined,Notes\n" +
+		"a@x.io,31,yes,2026-01-02,first\n" +
+		"b@x.io,,no,,\n" +
+		"c@x.io,abc,y,2026-01-03,bad age\n" +
+		"d@x.io,40,off,2026-01-04 10:00:00,\n" +
+		"e@x.io,50\n"
+
+	var progress []int
+	res, err := db.ImportCSV(ctx, "members", strings.NewReader(input), ImportOptions{
+		ColumnMap: map[string]string{"E-mail": "email", "Age": "age", "Active": "active", "Joined": "joined"},
+		Coerce: map[string]CoerceFunc{
+			"age":    CoerceInt,
+			"active": CoerceBool,
+			"joined": CoerceTime(),
+		},
+		BatchSize: 2,
+		OnError:   func(*ImportError) error { return nil },
+		Progress:  func(r ImportResult) { progress = append(progress, r.Inserted) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Inserted)
+	assert.Equal(t, 2, res.Skipped)
+	assert.Equal(t, []int{2, 3}, progress)
+
+	require.Len(t, res.Errors, 2)
+	assert.Equal(t, 4, res.Errors[0].Line)
+	assert.Equal(t, "age", res.Errors[0].Column)
+	assert.Equal(t, "abc", res.Errors[0].Value)
+	assert.ErrorContains(t, res.Errors[0], "relica: import line 4, column age: strconv.ParseInt")
+	assert.Equal(t, 6, res.Errors[1].Line)
+	assert.ErrorContains(t, res.Errors[1], "wrong number of fields")
+
+	var members []importedMember
+	require.NoError(t, db.Builder().Select().From("members").OrderBy("id").All(&members))
+	require.Len(t, members, 3)
+	assert.Equal(t, "a@x.io", members[0].Email)
+	require.NotNil(t, members[0].Age)
+	assert.Equal(t, int64(31), *members[0].Age)
+	assert.True(t, members[0].Active)
+	require.NotNil(t, members[0].Joined)
+	assert.True(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC).Equal(*members[0].Joined))
+	assert.Nil(t, members[1].Age, "empty fields are NULL")
+	assert.Nil(t, members[1].Joined)
+	assert.False(t, members[2].Active)
+}
+
+func TestDB_ImportCSV_NoHeader(t *testing.T) {
+	db := setupImportDB(t)
+
+	res, err := db.ImportCSV(context.Background(), "members", strings.NewReader("x@x.io;\\N\ny@y.io;7\n"), ImportOptions{
+		Columns: []string{"email", "age"},
+		Comma:   ';',
+		Null:    `\N`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Inserted)
+
+	var ages []*int64
+	require.NoError(t, db.Builder().Select("age").From("members").OrderBy("id").Column(&ages))
+	require.Len(t, ages, 2)
+	assert.Nil(t, ages[0])
+	assert.Equal(t, int64(7), *ages[1])
+}
+
+func TestDB_ImportCSV_RoundTrip(t *testing.T) {
+	db := setupImportDB(t)
+	ctx := context.Background()
+
+	_, err := db.ImportCSV(ctx, "members", strings.NewReader("id,email,age\n1,a@x.io,30\n2,b@x.io,\n"), ImportOptions{})
+	require.NoError(t, err)
+
+	var exported bytes.Buffer
+	require.NoError(t, db.Builder().Select("id", "email", "age").From("members").OrderBy("id").ExportCSV(&exported, nil))
+
+	_, err = db.ExecContext(ctx, "DELETE FROM members")
+	require.NoError(t, err)
+	res, err := db.ImportCSV(ctx, "members", bytes.NewReader(exported.Bytes()), ImportOptions{
+		Coerce: map[string]CoerceFunc{"id": CoerceInt, "age": CoerceInt},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Inserted)
+
+	var again bytes.Buffer
+	require.NoError(t, db.Builder().Select("id", "email", "age").From("members").OrderBy("id").ExportCSV(&again, nil))
+	assert.Equal(t, exported.String(), again.String())
+}
+
+func TestDB_ImportCSV_Abort(t *testing.T) {
+	db := setupImportDB(t)
+	ctx := context.Background()
+	input := "email,age\na@x.io,1\nb@x.io,oops\n"
+
+	res, err := db.ImportCSV(ctx, "members", strings.NewReader(input), ImportOptions{
+		Coerce:    map[string]CoerceFunc{"age": CoerceInt},
+		BatchSize: 1,
+	})
+	var ierr *ImportError
+	require.ErrorAs(t, err, &ierr)
+	assert.Equal(t, 3, ierr.Line)
+	assert.Equal(t, 0, res.Inserted, "the import is rolled back")
+
+	var n int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM members").Row(&n))
+	assert.Equal(t, 0, n)
+
+	errTooMany := errors.New("too many errors")
+	_, err = db.ImportCSV(ctx, "members", strings.NewReader(input), ImportOptions{
+		Coerce:  map[string]CoerceFunc{"age": CoerceInt},
+		OnError: func(*ImportError) error { return errTooMany },
+	})
+	assert.ErrorIs(t, err, errTooMany)
+
+	_, err = db.ImportCSV(ctx, "members", strings.NewReader("email,age\n,5\n"), ImportOptions{})
+	assert.ErrorContains(t, err, "NOT NULL", "database errors are not row errors")
+	_, err = db.ImportCSV(ctx, "members", strings.NewReader("a,b\n"), ImportOptions{ColumnMap: map[string]string{"c": "email"}})
+	assert.ErrorContains(t, err, "no columns to import")
+
+	res, err = db.ImportCSV(ctx, "members", strings.NewReader(""), ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ImportResult{}, res)
+}
+
+func TestCoerce(t *testing.T) {
+	v, err := CoerceBool(" ON ")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+	v, err = CoerceBool("0")
+	require.NoError(t, err)
+	assert.Equal(t, false, v)
+	_, err = CoerceBool("maybe")
+	assert.Error(t, err)
+
+	v, err = CoerceFloat(" 2.5")
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, v)
+
+	v, err = CoerceTime("02/01/2006")(" 31/12/2025 ")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), v)
+	_, err = CoerceTime()("yesterday")
+	assert.ErrorContains(t, err, `invalid time "yesterday"`)
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/coregx/relica/internal/util"
+)
+
+// ============================================================================
+// InsertAll
+// ============================================================================
+//
+// InsertAll inserts a slice of structs with multi-row INSERT statements. The
+// struct type is inspected once; each element only contributes its values.
+// Rows are split into statements of at most maxBatchParams bind parameters
+// (see BatchSize), which run in one transaction.
+//
+// Like Model().Insert, generated IDs (relica:"ulid", "snowflake", "uuid",
+// "uuidv7") are filled first, models are validated (see WithModelValidator),
+// and a zero auto-increment primary key is populated after the insert:
+// PostgreSQL reads it with RETURNING, MySQL and SQLite derive it from
+// LastInsertId, as the IDs of one multi-row INSERT are consecutive (MySQL
+// with auto_increment_increment = 1).
+
+// InsertAllQuery inserts a slice of structs (see QueryBuilder.InsertAll).
+type InsertAllQuery struct {
+	builder   *QueryBuilder
+	table     string
+	rows      interface{}
+	batchSize int
+	ctx       context.Context // context for this specific query
+}
+
+// InsertAll creates a query inserting every element of rows, a slice of
+// structs or struct pointers, into table. If table is empty, it is inferred
+// from the element type like Model does.
+//
+// Example:
+//
+//	users := []User{{Name: "Alice"}, {Name: "Bob"}}
+//	n, err := db.Builder().InsertAll("users", users).Execute()
+//	// users[0].ID and users[1].ID are populated
+func (qb *QueryBuilder) InsertAll(table string, rows interface{}) *InsertAllQuery {
+	return &InsertAllQuery{builder: qb, table: table, rows: rows}
+}
+
+// WithContext sets the context for this query.
+// This overrides any context set on the QueryBuilder.
+func (iq *InsertAllQuery) WithContext(ctx context.Context) *InsertAllQuery {
+	iq.ctx = ctx
+	return iq
+}
+
+// BatchSize sets the maximum number of rows per INSERT statement
+// (default: as many as fit in 999 bind parameters).
+func (iq *InsertAllQuery) BatchSize(n int) *InsertAllQuery {
+	if n > 0 {
+		iq.batchSize = n
+	}
+	return iq
+}
+
+// insertAllColumn is a struct field inserted by InsertAll.
+type insertAllColumn struct {
+	index  int
+	column string
+}
+
+// Execute inserts the rows and returns the number of inserted rows. An empty
+// slice inserts nothing. When the rows need more than one statement and the
+// builder is not in a transaction, the statements run in a new transaction.
+func (iq *InsertAllQuery) Execute() (int64, error) {
+	db := iq.builder.db
+	ctx := iq.ctx
+	if ctx == nil {
+		ctx = iq.builder.ctx
+	}
+
+	elems, err := insertAllElems(iq.rows)
+	if err != nil || len(elems) == 0 {
+		return 0, err
+	}
+	elemType := elems[0].Type()
+	table := iq.table
+	if table == "" {
+		table = inferTableName(reflect.New(elemType).Interface())
+	}
+
+	// Fill generated IDs and validate, like Model().Insert.
+	for _, elem := range elems {
+		mq := &ModelQuery{db: db, tx: iq.builder.tx, model: elem.Addr().Interface(), ctx: ctx}
+		if err := mq.generateIDs(); err != nil {
+			return 0, err
+		}
+		if err := mq.validateModel(); err != nil {
+			return 0, err
+		}
+	}
+
+	// A single numeric primary key that is zero in every row is left to the
+	// database and populated after the insert.
+	pkIndex := -1
+	pkColumn := ""
+	if pk, err := util.FindPrimaryKeyFields(elems[0]); err == nil && pk.IsSingle() && isPKNumeric(pk.Values[0]) {
+		pkIndex, pkColumn = pk.Fields[0].Index[0], pk.Columns[0]
+		for _, elem := range elems {
+			if !util.IsPrimaryKeyZero(elem.Field(pkIndex)) {
+				pkIndex = -1
+				break
+			}
+		}
+	}
+
+	var cols []insertAllColumn
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		column := util.ColumnName(field)
+		if !field.IsExported() || column == "-" || i == pkIndex {
+			continue
+		}
+		cols = append(cols, insertAllColumn{index: i, column: column})
+	}
+	if len(cols) == 0 {
+		return 0, fmt.Errorf("relica: InsertAll: %s has no columns to insert", elemType)
+	}
+	columns := make([]string, len(cols))
+	for i, c := range cols {
+		columns[i] = c.column
+	}
+
+	batch := max(maxBatchParams/len(columns), 1)
+	if iq.batchSize > 0 && iq.batchSize < batch {
+		batch = iq.batchSize
+	}
+
+	run := func(qb *QueryBuilder) (int64, error) {
+		var total int64
+		for start := 0; start < len(elems); start += batch {
+			chunk := elems[start:min(start+batch, len(elems))]
+			bq := qb.BatchInsert(table, columns)
+			for _, elem := range chunk {
+				row := make([]interface{}, len(cols))
+				for j, c := range cols {
+					row[j] = elem.Field(c.index).Interface()
+				}
+				bq.Values(row...)
+			}
+			query := bq.WithContext(ctx).Build()
+			n, err := insertAllChunk(query, chunk, pkIndex, pkColumn)
+			if err != nil {
+				return total, fmt.Errorf("relica: InsertAll %s: %w", table, err)
+			}
+			total += n
+		}
+		return total, nil
+	}
+
+	if len(elems) <= batch || iq.builder.tx != nil {
+		return run(iq.builder)
+	}
+	txCtx := ctx
+	if txCtx == nil {
+		txCtx = context.Background()
+	}
+	var total int64
+	err = db.Transactional(txCtx, func(tx *Tx) error {
+		qb := tx.Builder()
+		qb.tag = iq.builder.tag
+		total, err = run(qb)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// insertAllChunk executes the INSERT of one chunk and populates the primary
+// key field pkIndex (-1 for none) of its rows.
+func insertAllChunk(query *Query, chunk []reflect.Value, pkIndex int, pkColumn string) (int64, error) {
+	db := query.db
+	if pkIndex >= 0 && (db.driverName == driverPostgres || db.driverName == driverPgx) {
+		query.appendSQL(" RETURNING " + db.dialect.QuoteIdentifier(pkColumn))
+		var ids []int64
+		if err := query.Column(&ids); err != nil {
+			return 0, err
+		}
+		for i, id := range ids {
+			if i < len(chunk) {
+				_ = util.SetPrimaryKeyValue(chunk[i].Field(pkIndex), id)
+			}
+		}
+		return int64(len(ids)), nil
+	}
+
+	result, err := query.Execute()
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	if pkIndex < 0 {
+		return n, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return n, nil //nolint:nilerr // Insert succeeded; the driver has no LastInsertId.
+	}
+	// MySQL reports the first ID of the statement, SQLite the last.
+	first := id
+	if db.driverName == "sqlite" || db.driverName == "sqlite3" {
+		first = id - int64(len(chunk)) + 1
+	}
+	for i, elem := range chunk {
+		_ = util.SetPrimaryKeyValue(elem.Field(pkIndex), first+int64(i))
+	}
+	return n, nil
+}
+
+// insertAllElems returns the addressable struct values of rows, a slice (or
+// pointer to a slice) of structs or non-nil struct pointers.
+func insertAllElems(rows interface{}) ([]reflect.Value, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("relica: InsertAll requires a slice of structs, got %T", rows)
+	}
+
+	elemType := v.Type().Elem()
+	ptr := elemType.Kind() == reflect.Pointer
+	if ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("relica: InsertAll requires a slice of structs, got %T", rows)
+	}
+
+	elems := make([]reflect.Value, v.Len())
+	for i := range elems {
+		elem := v.Index(i)
+		if ptr {
+			if elem.IsNil() {
+				return nil, fmt.Errorf("relica: InsertAll: row %d is nil", i)
+			}
+			elem = elem.Elem()
+		}
+		elems[i] = elem
+	}
+	return elems, nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type insertAllAuthor struct {
+	ID     int64  `db:"id"`
+	Name   string `db:"name" validate:"required"`
+	Active bool   `db:"active"`
+	Bio    string `db:"-"`
+}
+
+func (insertAllAuthor) TableName() string { return "authors" }
+
+func setupInsertAllDB(t *testing.T, opts ...Option) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+	_, err = db.ExecContext(context.Background(),
+		"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT NOT NULL, active BOOLEAN)")
+	require.NoError(t, err)
+	return db
+}
+
+func TestInsertAll(t *testing.T) {
+	db := setupInsertAllDB(t)
+
+	authors := []insertAllAuthor{{Name: "ann", Active: true}, {Name: "bob"}, {Name: "cid", Active: true}}
+	n, err := db.Builder().InsertAll("authors", authors).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, []int64{1, 2, 3}, []int64{authors[0].ID, authors[1].ID, authors[2].ID})
+
+	// Pointer elements, several statements, table inferred from the type
+	more := []*insertAllAuthor{{Name: "dan"}, {Name: "eve"}, {Name: "fay"}, {Name: "gus"}, {Name: "hal"}}
+	n, err = db.Builder().InsertAll("", &more).BatchSize(2).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	var stored []insertAllAuthor
+	require.NoError(t, db.Builder().Select().From("authors").OrderBy("id").All(&stored))
+	require.Len(t, stored, 8)
+	for i, a := range more {
+		assert.Equal(t, stored[3+i].ID, a.ID)
+		assert.Equal(t, stored[3+i].Name, a.Name)
+	}
+	assert.True(t, stored[2].Active)
+
+	// Explicit primary keys are inserted as given
+	n, err = db.Builder().InsertAll("authors", []insertAllAuthor{{ID: 100, Name: "ivy"}}).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	var id int64
+	require.NoError(t, db.NewQuery("SELECT id FROM authors WHERE name = 'ivy'").Row(&id))
+	assert.Equal(t, int64(100), id)
+
+	n, err = db.Builder().InsertAll("authors", []insertAllAuthor{}).Execute()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestInsertAll_Errors(t *testing.T) {
+	db := setupInsertAllDB(t, WithModelValidator(ValidateTags))
+	count := func() int64 {
+		n, err := db.Builder().Select().From("authors").Count()
+		require.NoError(t, err)
+		return n
+	}
+
+	_, err := db.Builder().InsertAll("authors", insertAllAuthor{Name: "ann"}).Execute()
+	assert.ErrorContains(t, err, "requires a slice of structs")
+	_, err = db.Builder().InsertAll("authors", []int{1}).Execute()
+	assert.ErrorContains(t, err, "requires a slice of structs")
+	_, err = db.Builder().InsertAll("authors", []*insertAllAuthor{{Name: "ann"}, nil}).Execute()
+	assert.ErrorContains(t, err, "row 1 is nil")
+
+	var verr *ValidationError
+	_, err = db.Builder().InsertAll("authors", []insertAllAuthor{{Name: "ann"}, {}}).Execute()
+	require.ErrorAs(t, err, &verr)
+	assert.Zero(t, count(), "nothing is inserted")
+
+	// A failing statement rolls back the earlier ones
+	_, err = db.Builder().InsertAll("authors", []insertAllAuthor{{ID: 1, Name: "ann"}, {ID: 2, Name: "bob"}, {ID: 1, Name: "dup"}}).
+		BatchSize(2).Execute()
+	assert.ErrorIs(t, err, ErrUniqueViolation)
+	assert.Zero(t, count())
+}
+
+func TestInsertAll_Transaction(t *testing.T) {
+	db := setupInsertAllDB(t)
+	ctx := context.Background()
+
+	authors := []insertAllAuthor{{Name: "ann"}, {Name: "bob"}, {Name: "cid"}}
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.Builder().InsertAll("authors", authors).BatchSize(1).Execute()
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), authors[2].ID)
+}
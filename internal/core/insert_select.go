@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// InsertSelectQuery represents an INSERT ... SELECT query being built.
+type InsertSelectQuery struct {
+	builder   *QueryBuilder
+	table     string
+	columns   []string
+	query     *SelectQuery
+	returning []string        // RETURNING columns (PostgreSQL, SQLite 3.35+)
+	ctx       context.Context // context for this specific query
+	buildErr  error           // stored programming error (replaces panic in fluent chain)
+}
+
+// InsertFromSelect creates an INSERT ... SELECT query copying the rows returned by query
+// into table. If columns is empty, the column list is omitted and the SELECT must
+// return values for every column of the table in order.
+//
+// Example:
+//
+//	db.Builder().InsertFromSelect("users_archive", []string{"id", "name"},
+//	    db.Builder().Select("id", "name").From("users").Where("deleted = ?", true),
+//	).Execute()
+//
+// Generates:
+//
+//	INSERT INTO "users_archive" ("id", "name") SELECT "id", "name" FROM "users" WHERE deleted = $1
+func (qb *QueryBuilder) InsertFromSelect(table string, columns []string, query *SelectQuery) *InsertSelectQuery {
+	isq := &InsertSelectQuery{
+		builder: qb,
+		table:   table,
+		columns: columns,
+		query:   query,
+	}
+	if query == nil {
+		isq.buildErr = fmt.Errorf("relica: InsertFromSelect requires a non-nil SELECT query")
+	}
+	return isq
+}
+
+// WithContext sets the context for this INSERT ... SELECT query.
+// This overrides any context set on the QueryBuilder.
+func (isq *InsertSelectQuery) WithContext(ctx context.Context) *InsertSelectQuery {
+	isq.ctx = ctx
+	return isq
+}
+
+// Returning adds a RETURNING clause with the given columns ("*" for all columns).
+// Supported by PostgreSQL and SQLite 3.35+; MySQL returns an error at build time.
+func (isq *InsertSelectQuery) Returning(cols ...string) *InsertSelectQuery {
+	isq.returning = append(isq.returning, cols...)
+	return isq
+}
+
+// Build constructs the Query object from InsertSelectQuery.
+// CTEs attached to the QueryBuilder via With() are prepended to the statement.
+func (isq *InsertSelectQuery) Build() *Query {
+	// Context priority: query ctx > builder ctx > nil
+	ctx := isq.ctx
+	if ctx == nil {
+		ctx = isq.builder.ctx
+	}
+
+	dialect := isq.builder.db.dialect
+	query, params, err := isq.buildStatement(dialect)
+	if err == nil {
+		query, params, err = prependWithClause(isq.builder.ctes, query, params, dialect)
+	}
+	if err != nil {
+		return &Query{
+			prepErr: err,
+			db:      isq.builder.db,
+			tx:      isq.builder.tx,
+			tag:     isq.builder.tag,
+			ctx:     ctx,
+		}
+	}
+
+	return isq.builder.db.guard(&Query{
+		sql:    query,
+		params: params,
+		db:     isq.builder.db,
+		tx:     isq.builder.tx,
+		tag:    isq.builder.tag,
+		ctx:    ctx,
+		change: isq.builder.db.newChange(isq.table, opInsert, isq.columns, []string{defaultChangeKey}, nil),
+		refs:   isq.builder.db.newSchemaRefs(isq.table, isq.columns...),
+	})
+}
+
+// buildCTE implements CTEQuery for data-modifying CTEs (PostgreSQL only).
+func (isq *InsertSelectQuery) buildCTE(dialect dialects.Dialect) (string, []interface{}, error) {
+	if err := requireDataModifyingCTE(dialect); err != nil {
+		return "", nil, err
+	}
+	return isq.buildStatement(dialect)
+}
+
+// buildStatement constructs the INSERT ... SELECT statement (without builder CTEs).
+func (isq *InsertSelectQuery) buildStatement(dialect dialects.Dialect) (string, []interface{}, error) {
+	if isq.buildErr != nil {
+		return "", nil, isq.buildErr
+	}
+
+	selectSQL, params, err := isq.query.buildCTE(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+
+	returningClause, err := buildReturningClause(isq.returning, dialect)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := "INSERT INTO " + dialect.QuoteIdentifier(isq.table)
+	if len(isq.columns) > 0 {
+		quoted := make([]string, len(isq.columns))
+		for i, col := range isq.columns {
+			quoted[i] = dialect.QuoteIdentifier(col)
+		}
+		query += " (" + strings.Join(quoted, ", ") + ")"
+	}
+	query += " " + selectSQL + returningClause
+
+	return query, params, nil
+}
+
+// Execute executes the INSERT ... SELECT query and returns the result.
+func (isq *InsertSelectQuery) Execute() (interface{}, error) {
+	return isq.Build().Execute()
+}
+
+// ToSQL returns the SQL string and parameters without executing the query.
+func (isq *InsertSelectQuery) ToSQL() (string, []interface{}) {
+	q := isq.Build()
+	return q.sql, q.params
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInsertFromSelect_Basic tests INSERT ... SELECT with a column list
+func TestInsertFromSelect_Basic(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.InsertFromSelect("users_archive", []string{"id", "name"},
+		qb.Select("id", "name").From("users").Where("deleted = ?", true),
+	).Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "users_archive" ("id", "name") SELECT "id", "name" FROM "users" WHERE deleted = $1`, q.sql)
+	assert.Equal(t, []interface{}{true}, q.params)
+}
+
+// TestInsertFromSelect_NoColumns tests INSERT ... SELECT without a column list
+func TestInsertFromSelect_NoColumns(t *testing.T) {
+	db := mockDB("mysql")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.InsertFromSelect("users_archive", nil, qb.Select("*").From("users")).Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "INSERT INTO `users_archive` SELECT * FROM `users`", q.sql)
+}
+
+// TestInsertFromSelect_Returning tests RETURNING support and MySQL rejection
+func TestInsertFromSelect_Returning(t *testing.T) {
+	db := mockDB("sqlite")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.InsertFromSelect("archive", []string{"id"}, qb.Select("id").From("users")).
+		Returning("id").
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "archive" ("id") SELECT "id" FROM "users" RETURNING "id"`, q.sql)
+
+	mysql := &QueryBuilder{db: mockDB("mysql")}
+	q = mysql.InsertFromSelect("archive", nil, mysql.Select("id").From("users")).Returning("id").Build()
+	require.Error(t, q.prepErr)
+	assert.Contains(t, q.prepErr.Error(), "RETURNING is not supported by MySQL")
+}
+
+// TestInsertFromSelect_NilQuery tests that a nil SELECT is reported at build time
+func TestInsertFromSelect_NilQuery(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.InsertFromSelect("archive", nil, nil).Build()
+	require.Error(t, q.prepErr)
+	assert.Contains(t, q.prepErr.Error(), "non-nil SELECT query")
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ============================================================================
+// Prepared statement invalidation on schema change
+// ============================================================================
+//
+// Some databases reject cached prepared statements once the tables they read
+// have changed (PostgreSQL: "cached plan must not change result type"). DDL
+// executed through relica evicts the affected statements automatically; DDL
+// applied by other processes (migration tools) needs InvalidateStatements.
+//
+// Matching is by identifier token, so it may over-invalidate (e.g. a column that
+// shares the table's name). That only costs a re-prepare on the next execution.
+
+// InvalidateStatements closes and evicts every cached prepared statement that
+// references table, including pinned statements. A schema qualifier and identifier
+// quotes are ignored ("public.users" matches statements on "users").
+// Statement caches of named pools are included.
+// Returns the number of evicted statements.
+//
+// Example:
+//
+//	// After running migrations with an external tool
+//	db.InvalidateStatements("users")
+func (db *DB) InvalidateStatements(table string) int {
+	name := normalizeTableName(table)
+	if name == "" {
+		return 0
+	}
+	removed := 0
+	for _, c := range db.stmtCaches() {
+		removed += c.RemoveIf(func(query string) bool {
+			return referencesTable(query, name)
+		})
+	}
+	return removed
+}
+
+// invalidateAfterDDL evicts cached statements affected by a successfully executed
+// DDL statement. If the affected tables cannot be determined (DROP SCHEMA,
+// ALTER TYPE, ...), the whole cache is cleared.
+func (db *DB) invalidateAfterDDL(query string) {
+	if (db.stmtCache == nil && db.catalog == nil) || !mayBeDDL(query) {
+		return
+	}
+	tables, isDDL := ddlTargets(query)
+	if !isDDL {
+		return
+	}
+	if db.catalog != nil {
+		db.catalog.forget(tables)
+	}
+	if db.stmtCache == nil {
+		return
+	}
+	for _, c := range db.stmtCaches() {
+		if len(tables) == 0 {
+			c.Clear()
+			continue
+		}
+		c.RemoveIf(func(cached string) bool {
+			for _, table := range tables {
+				if referencesTable(cached, table) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+}
+
+// ddlKeywords are the statement keywords that can change a table's shape.
+var ddlKeywords = []string{"ALTER", "DROP", "CREATE", "TRUNCATE", "RENAME"}
+
+// mayBeDDL is a cheap prefix check that avoids tokenizing ordinary DML.
+// Statements starting with a comment are always tokenized.
+func mayBeDDL(query string) bool {
+	query = strings.TrimLeftFunc(query, unicode.IsSpace)
+	if strings.HasPrefix(query, "/*") || strings.HasPrefix(query, "--") {
+		return true
+	}
+	for _, kw := range ddlKeywords {
+		if len(query) >= len(kw) && strings.EqualFold(query[:len(kw)], kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// ddlTargets returns the tables affected by a DDL statement.
+// isDDL is false for non-DDL statements; tables is empty if the statement is DDL
+// but its tables cannot be determined.
+func ddlTargets(query string) (tables []string, isDDL bool) {
+	toks := sqlTokens(query)
+	if len(toks) == 0 {
+		return nil, false
+	}
+
+	switch strings.ToUpper(toks[0]) {
+	case "ALTER", "DROP", "CREATE":
+		for i := 1; i < len(toks); i++ {
+			switch strings.ToUpper(toks[i]) {
+			case "TABLE", "VIEW":
+				return readNameList(toks, i+1), true
+			case "INDEX":
+				// CREATE INDEX idx ON tbl, DROP INDEX idx ON tbl (MySQL)
+				for j := i + 1; j < len(toks); j++ {
+					if strings.EqualFold(toks[j], "ON") {
+						return readNameList(toks, j+1), true
+					}
+				}
+				return nil, true
+			}
+		}
+		return nil, true
+	case "TRUNCATE":
+		return readNameList(toks, 1), true
+	case "RENAME":
+		// MySQL: RENAME TABLE a TO b, c TO d
+		i := skipWords(toks, 1, "TABLE")
+		for {
+			from, next, ok := readName(toks, i)
+			if !ok {
+				break
+			}
+			tables = append(tables, from)
+			i = skipWords(toks, next, "TO")
+			to, next, ok := readName(toks, i)
+			if !ok {
+				break
+			}
+			tables = append(tables, to)
+			i = next
+			if i >= len(toks) || toks[i] != "," {
+				break
+			}
+			i++
+		}
+		return tables, true
+	}
+	return nil, false
+}
+
+// readNameList reads a comma-separated list of (optionally qualified) table names
+// starting at toks[i], skipping TABLE/IF [NOT] EXISTS/ONLY modifiers.
+func readNameList(toks []string, i int) []string {
+	var names []string
+	i = skipWords(toks, i, "TABLE", "IF", "NOT", "EXISTS", "ONLY")
+	for {
+		name, next, ok := readName(toks, i)
+		if !ok {
+			return names
+		}
+		names = append(names, name)
+		i = next
+		if i >= len(toks) || toks[i] != "," {
+			return names
+		}
+		i++
+	}
+}
+
+// readName reads a possibly schema-qualified name at toks[i] and returns its
+// last segment and the index after it.
+func readName(toks []string, i int) (name string, next int, ok bool) {
+	if i >= len(toks) || !isIdentToken(toks[i]) {
+		return "", i, false
+	}
+	name = toks[i]
+	i++
+	for i+1 < len(toks) && toks[i] == "." && isIdentToken(toks[i+1]) {
+		name = toks[i+1]
+		i += 2
+	}
+	return name, i, true
+}
+
+// skipWords advances i past any of the given keywords (case-insensitive).
+func skipWords(toks []string, i int, words ...string) int {
+	for i < len(toks) {
+		matched := false
+		for _, w := range words {
+			if strings.EqualFold(toks[i], w) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// isIdentToken reports whether tok is a word or quoted identifier (not punctuation).
+func isIdentToken(tok string) bool {
+	if tok == "" {
+		return false
+	}
+	r := []rune(tok)[0]
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// referencesTable reports whether query contains table as an identifier token.
+func referencesTable(query, table string) bool {
+	for _, tok := range sqlTokens(query) {
+		if strings.EqualFold(tok, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTableName strips a schema qualifier and identifier quotes.
+func normalizeTableName(table string) string {
+	table = strings.TrimSpace(table)
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	return strings.Trim(table, "\"`[]")
+}
+
+// sqlTokens splits a statement into words, unquoted identifiers ("x", `x`, [x])
+// and single-character punctuation. String literals and comments are skipped.
+func sqlTokens(query string) []string {
+	var toks []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			end := strings.IndexByte(query[i+1:], '\'')
+			if end < 0 {
+				return toks
+			}
+			i += end + 2
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return toks
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return toks
+			}
+			i += end + 4
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(query[i+1:], closing)
+			if end < 0 {
+				return append(toks, query[i+1:])
+			}
+			toks = append(toks, query[i+1:i+1+end])
+			i += end + 2
+		case isWordByte(c):
+			start := i
+			for i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			toks = append(toks, query[start:i])
+		default:
+			toks = append(toks, query[i:i+1])
+			i++
+		}
+	}
+	return toks
+}
+
+// isWordByte reports whether c can be part of an unquoted identifier or keyword.
+// Bytes >= 0x80 (UTF-8 sequences) are treated as identifier characters.
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
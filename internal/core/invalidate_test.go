@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestDDLTargets(t *testing.T) {
+	tests := []struct {
+		query  string
+		tables []string
+		isDDL  bool
+	}{
+		{`ALTER TABLE users ADD COLUMN age INT`, []string{"users"}, true},
+		{`alter table if exists only "public"."users" drop column age`, []string{"users"}, true},
+		{"DROP TABLE IF EXISTS `a`, b, app.c CASCADE", []string{"a", "b", "c"}, true},
+		{`CREATE TABLE users_archive (id INT)`, []string{"users_archive"}, true},
+		{`CREATE OR REPLACE VIEW active_users AS SELECT * FROM users`, []string{"active_users"}, true},
+		{`CREATE UNIQUE INDEX idx_email ON users (email)`, []string{"users"}, true},
+		{"DROP INDEX idx_email ON `users`", []string{"users"}, true},
+		{`DROP INDEX idx_email`, nil, true},
+		{`TRUNCATE TABLE orders, items`, []string{"orders", "items"}, true},
+		{`TRUNCATE orders`, []string{"orders"}, true},
+		{`RENAME TABLE a TO b, c TO d`, []string{"a", "b", "c", "d"}, true},
+		{`/* migration 42 */ ALTER TABLE users ADD age INT`, []string{"users"}, true},
+		{`DROP SCHEMA reporting CASCADE`, nil, true},
+		{`SELECT * FROM users`, nil, false},
+		{`INSERT INTO users (name) VALUES ('ALTER TABLE users')`, nil, false},
+		{``, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			tables, isDDL := ddlTargets(tt.query)
+			assert.Equal(t, tt.isDDL, isDDL)
+			assert.Equal(t, tt.tables, tables)
+		})
+	}
+}
+
+func TestReferencesTable(t *testing.T) {
+	assert.True(t, referencesTable(`SELECT * FROM "users" WHERE id = $1`, "users"))
+	assert.True(t, referencesTable("SELECT * FROM `app`.`Users`", "users"))
+	assert.True(t, referencesTable(`SELECT u.id FROM orders o JOIN users u ON u.id = o.user_id`, "users"))
+	assert.False(t, referencesTable(`SELECT * FROM users_archive`, "users"))
+	assert.False(t, referencesTable(`SELECT 'users' FROM orders`, "users"))
+	assert.False(t, referencesTable(`SELECT 1 /*route='users'*/`, "users"))
+}
+
+func TestNormalizeTableName(t *testing.T) {
+	assert.Equal(t, "users", normalizeTableName("users"))
+	assert.Equal(t, "users", normalizeTableName(`"public"."users"`))
+	assert.Equal(t, "users", normalizeTableName("`users`"))
+	assert.Equal(t, "", normalizeTableName("  "))
+}
+
+func setupInvalidateTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := NewDB("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER)`)
+	require.NoError(t, err)
+
+	_, err = db.WarmCache([]string{
+		`SELECT * FROM users`,
+		`SELECT * FROM orders`,
+		`SELECT o.id FROM orders o JOIN users u ON u.id = o.user_id`,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func cachedSQL(db *DB) []string {
+	var keys []string
+	for _, e := range db.StmtCacheEntries() {
+		keys = append(keys, e.SQL)
+	}
+	return keys
+}
+
+func TestDB_InvalidateStatements(t *testing.T) {
+	db := setupInvalidateTestDB(t)
+	db.PinQuery(`SELECT * FROM users`)
+
+	n := db.InvalidateStatements(`"main"."users"`)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{`SELECT * FROM orders`}, cachedSQL(db))
+	assert.Equal(t, 0, db.StmtCacheStats().Pinned)
+
+	assert.Equal(t, 0, db.InvalidateStatements(""))
+}
+
+func TestDB_InvalidateAfterDDL(t *testing.T) {
+	t.Run("raw ExecContext", func(t *testing.T) {
+		db := setupInvalidateTestDB(t)
+		_, err := db.ExecContext(context.Background(), `ALTER TABLE users ADD COLUMN age INTEGER`)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`SELECT * FROM orders`}, cachedSQL(db))
+	})
+
+	t.Run("query Execute", func(t *testing.T) {
+		db := setupInvalidateTestDB(t)
+		_, err := db.NewQuery(`DROP TABLE orders`).Execute()
+		require.NoError(t, err)
+		for _, query := range cachedSQL(db) {
+			assert.False(t, referencesTable(query, "orders"), query)
+		}
+		assert.Contains(t, cachedSQL(db), `SELECT * FROM users`)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		db := setupInvalidateTestDB(t)
+		tx, err := db.Begin(context.Background())
+		require.NoError(t, err)
+		_, err = tx.NewQuery(`ALTER TABLE orders ADD COLUMN total INTEGER`).Execute()
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, []string{`SELECT * FROM users`}, cachedSQL(db))
+	})
+
+	t.Run("unknown target clears cache", func(t *testing.T) {
+		db := setupInvalidateTestDB(t)
+		_, err := db.ExecContext(context.Background(), `CREATE INDEX idx_orders_user ON orders (user_id)`)
+		require.NoError(t, err)
+		_, err = db.ExecContext(context.Background(), `DROP INDEX idx_orders_user`)
+		require.NoError(t, err)
+		assert.Empty(t, cachedSQL(db))
+	})
+
+	t.Run("failed DDL keeps cache", func(t *testing.T) {
+		db := setupInvalidateTestDB(t)
+		_, err := db.ExecContext(context.Background(), `ALTER TABLE missing ADD COLUMN x INTEGER`)
+		require.Error(t, err)
+		assert.Len(t, cachedSQL(db), 3)
+	})
+
+	t.Run("DML keeps cache", func(t *testing.T) {
+		db := setupInvalidateTestDB(t)
+		_, err := db.ExecContext(context.Background(), `INSERT INTO users (name) VALUES ('alter table users')`)
+		require.NoError(t, err)
+		assert.Len(t, cachedSQL(db), 3)
+	})
+}
@@ -253,3 +253,53 @@ func lastIndexOf(s, substr string) int {
 	}
 	return lastIdx
 }
+
+// TestSelectQuery_JoinSelect tests joining subqueries with placeholder renumbering
+func TestSelectQuery_JoinSelect(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	totals := qb.Select("user_id", "SUM(total) AS spent").From("orders").
+		Where("status = ?", "paid").GroupBy("user_id")
+	logins := qb.Select("user_id", "MAX(at) AS last_at").From("logins").
+		Where("at > ?", "2024-01-01").GroupBy("user_id")
+	recent := qb.Select("id", "name").From("users").Where("created_at > ?", "2023-01-01")
+
+	q := qb.Select("u.name", "t.spent", "l.last_at").
+		FromSelect(recent, "u").
+		InnerJoinSelect(totals, "t", And(EqCol("t.user_id", "u.id"), GreaterThan("t.spent", 100))).
+		LeftJoinSelect(logins, "l", "l.user_id = u.id").
+		Where("u.name <> ?", "bot").
+		Build()
+	require.NoError(t, q.prepErr)
+
+	assert.Equal(t, `SELECT "u"."name", "t"."spent", "l"."last_at" `+
+		`FROM (SELECT "id", "name" FROM "users" WHERE created_at > $1) AS "u" `+
+		`INNER JOIN (SELECT "user_id", SUM(total) AS spent FROM "orders" WHERE status = $2 GROUP BY "user_id") AS "t" `+
+		`ON ("t"."user_id" = "u"."id") AND ("t"."spent" > $3) `+
+		`LEFT JOIN (SELECT "user_id", MAX(at) AS last_at FROM "logins" WHERE at > $4 GROUP BY "user_id") AS "l" ON l.user_id = u.id `+
+		`WHERE u.name <> $5`, q.sql)
+	assert.Equal(t, []interface{}{"2023-01-01", "paid", 100, "2024-01-01", "bot"}, q.params)
+
+	q = qb.Select("*").From("users u").RightJoinSelect(totals, "t", "t.user_id = u.id").Build()
+	assert.Contains(t, q.sql, `RIGHT JOIN (SELECT "user_id", SUM(total) AS spent FROM "orders" WHERE status = $1 GROUP BY "user_id") AS "t"`)
+
+	mq := &QueryBuilder{db: mockDB("mysql")}
+	q = mq.Select("*").From("users u").InnerJoinSelect(mq.Select("id").From("t").Where("x = ?", 1), "s", "s.id = u.id").Where("y = ?", 2).Build()
+	assert.Equal(t, "SELECT * FROM `users` AS `u` INNER JOIN (SELECT `id` FROM `t` WHERE x = ?) AS `s` ON s.id = u.id WHERE y = ?", q.sql)
+	assert.Equal(t, []interface{}{1, 2}, q.params)
+}
+
+// TestSelectQuery_JoinSelect_Errors tests that a missing subquery or alias fails the query
+func TestSelectQuery_JoinSelect_Errors(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Select("*").From("users u").InnerJoinSelect(qb.Select("id").From("t"), "", "true").Build()
+	assert.EqualError(t, q.prepErr, "relica: INNER JOIN of a subquery requires a non-empty alias")
+
+	q = qb.Select("*").From("users u").LeftJoinSelect(nil, "t", "true").Build()
+	assert.EqualError(t, q.prepErr, "relica: LEFT JOIN subquery is nil")
+
+	bad := qb.Select("id").From("t").InnerJoin("x", 42)
+	q = qb.Select("*").From("users u").InnerJoinSelect(bad, "t", "t.id = u.id").Build()
+	assert.ErrorContains(t, q.prepErr, "JOIN ON must be string, Expression, or nil")
+}
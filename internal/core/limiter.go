@@ -0,0 +1,218 @@
+package core
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Concurrency limiter
+// ============================================================================
+//
+// WithMaxConcurrentQueries caps the number of queries executing at once. Excess
+// queries wait in a priority queue instead of piling onto the connection pool,
+// which protects the database during traffic spikes. Higher priorities are
+// served first; equal priorities are served in arrival order.
+//
+// The limit applies to Query execution (builder queries and DB.NewQuery) across
+// the primary and named pools. Queries inside a transaction are not limited:
+// they already hold a connection, and making them wait could deadlock with
+// queries waiting for that connection. Raw DB.ExecContext/QueryContext calls
+// are not limited either.
+
+// QueuePolicy configures how queries wait when the concurrency limit is reached.
+type QueuePolicy struct {
+	// MaxQueueLength is the maximum number of waiting queries.
+	// Further queries fail immediately with ErrQueueFull. Zero means unbounded.
+	MaxQueueLength int
+
+	// Timeout is the maximum time a query waits for a slot before failing with
+	// ErrQueueTimeout. Zero means wait until the query context is done.
+	Timeout time.Duration
+}
+
+// Query priorities for WithQueryPriority. Any int is valid; higher runs first.
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+// queryPriorityKey is the context key for query priorities.
+type queryPriorityKey struct{}
+
+// WithMaxConcurrentQueries limits the number of concurrently executing queries to n.
+// If n <= 0, no limit is applied.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithMaxConcurrentQueries(20, relica.QueuePolicy{
+//	        MaxQueueLength: 500,
+//	        Timeout:        2 * time.Second,
+//	    }))
+func WithMaxConcurrentQueries(n int, policy QueuePolicy) Option {
+	return optionFunc(func(db *DB) {
+		if n > 0 {
+			db.limiter = newQueryLimiter(n, policy)
+		}
+	})
+}
+
+// WithQueryPriority returns a context whose queries are dequeued before queries
+// with a lower priority when the concurrency limit is reached.
+//
+// Example:
+//
+//	ctx = relica.WithQueryPriority(ctx, relica.PriorityHigh)
+//	db.Builder().WithContext(ctx).Select("*").From("orders").All(&orders)
+func WithQueryPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, queryPriorityKey{}, priority)
+}
+
+// queryPriority returns the priority stored in ctx (PriorityNormal by default).
+func queryPriority(ctx context.Context) int {
+	if p, ok := ctx.Value(queryPriorityKey{}).(int); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// queryLimiter is a counting semaphore with a priority wait queue.
+type queryLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	seq     uint64
+	waiters waitQueue
+	policy  QueuePolicy
+}
+
+// waiter is a query waiting for a slot. ready is closed when the slot is granted.
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int
+	granted  bool
+	ready    chan struct{}
+}
+
+func newQueryLimiter(limit int, policy QueuePolicy) *queryLimiter {
+	return &queryLimiter{limit: limit, policy: policy}
+}
+
+// acquire blocks until a slot is available, ctx is done or the queue timeout
+// expires. On success the caller must call release exactly once.
+func (l *queryLimiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.active < l.limit && l.waiters.Len() == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	if l.policy.MaxQueueLength > 0 && l.waiters.Len() >= l.policy.MaxQueueLength {
+		l.mu.Unlock()
+		return ErrQueueFull
+	}
+	l.seq++
+	w := &waiter{priority: queryPriority(ctx), seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if l.policy.Timeout > 0 {
+		timer := time.NewTimer(l.policy.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = ErrQueueTimeout
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// The slot was handed over while giving up; keep it rather than leak it.
+		return nil
+	}
+	heap.Remove(&l.waiters, w.index)
+	return err
+}
+
+// release frees a slot, handing it to the highest-priority waiter if any.
+func (l *queryLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.waiters.Len() == 0 {
+		l.active--
+		return
+	}
+	w := heap.Pop(&l.waiters).(*waiter)
+	w.granted = true
+	close(w.ready)
+}
+
+// acquireSlot registers this query as in-flight work (see Shutdown) and waits
+// for a concurrency slot. Queries in a transaction are covered by their
+// transaction. The returned release function is always safe to call.
+func (q *Query) acquireSlot(ctx context.Context) (release func(), err error) {
+	if q.db == nil || q.tx != nil {
+		return func() {}, nil
+	}
+	if err := q.db.drain.enter(); err != nil {
+		return func() {}, err
+	}
+	if q.db.limiter == nil {
+		return q.db.drain.leave, nil
+	}
+	if err := q.db.limiter.acquire(ctx); err != nil {
+		q.db.drain.leave()
+		return func() {}, err
+	}
+	return func() {
+		q.db.limiter.release()
+		q.db.drain.leave()
+	}, nil
+}
+
+// waitQueue is a max-heap of waiters ordered by priority, then arrival.
+type waitQueue []*waiter
+
+func (wq waitQueue) Len() int { return len(wq) }
+
+func (wq waitQueue) Less(i, j int) bool {
+	if wq[i].priority != wq[j].priority {
+		return wq[i].priority > wq[j].priority
+	}
+	return wq[i].seq < wq[j].seq
+}
+
+func (wq waitQueue) Swap(i, j int) {
+	wq[i], wq[j] = wq[j], wq[i]
+	wq[i].index = i
+	wq[j].index = j
+}
+
+func (wq *waitQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*wq)
+	*wq = append(*wq, w)
+}
+
+func (wq *waitQueue) Pop() interface{} {
+	old := *wq
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*wq = old[:n-1]
+	return w
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// waitForQueue blocks until n queries are waiting in the limiter.
+func waitForQueue(t *testing.T, l *queryLimiter, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.waiters.Len() == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestQueryLimiter_AcquireRelease(t *testing.T) {
+	l := newQueryLimiter(1, QueuePolicy{})
+	require.NoError(t, l.acquire(context.Background()))
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = l.acquire(context.Background())
+		close(acquired)
+	}()
+
+	waitForQueue(t, l, 1)
+	select {
+	case <-acquired:
+		t.Fatal("second acquire should wait for release")
+	default:
+	}
+
+	l.release()
+	<-acquired
+	l.release()
+	assert.Equal(t, 0, l.active)
+}
+
+func TestQueryLimiter_Priority(t *testing.T) {
+	l := newQueryLimiter(1, QueuePolicy{})
+	require.NoError(t, l.acquire(context.Background()))
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	enqueue := func(name string, priority int, queued int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, l.acquire(WithQueryPriority(context.Background(), priority)))
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			l.release()
+		}()
+		waitForQueue(t, l, queued)
+	}
+
+	enqueue("low", PriorityLow, 1)
+	enqueue("normal-1", PriorityNormal, 2)
+	enqueue("high", PriorityHigh, 3)
+	enqueue("normal-2", PriorityNormal, 4)
+
+	l.release()
+	wg.Wait()
+	assert.Equal(t, []string{"high", "normal-1", "normal-2", "low"}, order)
+}
+
+func TestQueryLimiter_QueueFull(t *testing.T) {
+	l := newQueryLimiter(1, QueuePolicy{MaxQueueLength: 1})
+	require.NoError(t, l.acquire(context.Background()))
+
+	go func() { _ = l.acquire(context.Background()) }()
+	waitForQueue(t, l, 1)
+
+	err := l.acquire(context.Background())
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+func TestQueryLimiter_Timeout(t *testing.T) {
+	l := newQueryLimiter(1, QueuePolicy{Timeout: 10 * time.Millisecond})
+	require.NoError(t, l.acquire(context.Background()))
+
+	err := l.acquire(context.Background())
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+	assert.Equal(t, 0, l.waiters.Len())
+
+	l.release()
+	assert.Equal(t, 0, l.active)
+}
+
+func TestQueryLimiter_ContextCanceled(t *testing.T) {
+	l := newQueryLimiter(1, QueuePolicy{})
+	require.NoError(t, l.acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.acquire(ctx) }()
+	waitForQueue(t, l, 1)
+
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+	assert.Equal(t, 0, l.waiters.Len())
+}
+
+func TestWithMaxConcurrentQueries(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithMaxConcurrentQueries(1, QueuePolicy{Timeout: 20 * time.Millisecond}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var n int
+	require.NoError(t, db.NewQuery("SELECT 1").Row(&n))
+	assert.Equal(t, 0, db.limiter.active) // slot released after execution
+
+	// Hold the only slot: non-transactional queries time out in the queue.
+	require.NoError(t, db.limiter.acquire(context.Background()))
+	err = db.NewQuery("SELECT 1").Row(&n)
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+	_, err = db.NewQuery("SELECT 1").Execute()
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+
+	// Transactional queries are not limited.
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, tx.NewQuery("SELECT 2").Row(&n))
+	assert.Equal(t, 2, n)
+	require.NoError(t, tx.Rollback())
+
+	db.limiter.release()
+	require.NoError(t, db.NewQuery("SELECT 3").Row(&n))
+	assert.Equal(t, 3, n)
+}
+
+func TestWithMaxConcurrentQueries_Disabled(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithMaxConcurrentQueries(0, QueuePolicy{}))
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Nil(t, db.limiter)
+}
@@ -0,0 +1,485 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// Query linting
+// ============================================================================
+//
+// WithQueryLint inspects each query before it is executed for statements
+// that are valid SQL but rarely what was meant:
+//
+//   - LintWriteWithoutWhere: UPDATE or DELETE without a WHERE clause
+//   - LintSelectStarJoin: SELECT * over a JOIN (duplicate and unstable columns)
+//   - LintLeadingWildcard: LIKE pattern starting with % or _ (cannot use an index)
+//   - LintNonSargable: function applied to a column in a WHERE comparison,
+//     e.g. LOWER(email) = ? (cannot use an index on the column)
+//
+// The checks are static: they read the SQL text and, for LIKE patterns, the
+// bound parameters. The database is not consulted, so the index rules apply
+// to every column unless LintPolicy.IndexedColumns names the indexed ones.
+// Findings are reported as warnings, once per statement, or stop execution
+// with ErrLintViolation for the rules in LintPolicy.Block.
+
+// LintRule identifies a query lint check.
+type LintRule string
+
+// Query lint rules (see WithQueryLint).
+const (
+	LintWriteWithoutWhere LintRule = "write-without-where"
+	LintSelectStarJoin    LintRule = "select-star-join"
+	LintLeadingWildcard   LintRule = "leading-wildcard"
+	LintNonSargable       LintRule = "non-sargable"
+)
+
+// maxLintReported bounds the statements remembered as already warned about.
+const maxLintReported = 10000
+
+// LintWarning is a lint finding in a query.
+type LintWarning struct {
+	// Rule is the check that found the problem
+	Rule LintRule
+	// Message describes the problem
+	Message string
+	// SQL is the statement
+	SQL string
+	// Column is the column concerned ("" for statement-level rules)
+	Column string
+	// Tag is the query tag set with Tag ("" for untagged queries)
+	Tag string
+}
+
+// String returns a formatted string representation of the warning.
+func (w LintWarning) String() string {
+	return fmt.Sprintf("warning: lint %s: %s: %s", w.Rule, w.Message, w.SQL)
+}
+
+// LintHandler receives lint warnings. ctx is the context of the query.
+type LintHandler func(ctx context.Context, w LintWarning)
+
+// LintPolicy configures query linting (see WithQueryLint).
+type LintPolicy struct {
+	// Block lists the rules whose findings stop execution with
+	// ErrLintViolation instead of being reported as warnings.
+	Block []LintRule
+	// Disable lists the rules that are not checked.
+	Disable []LintRule
+	// IndexedColumns limits LintLeadingWildcard and LintNonSargable to these
+	// column names. Empty checks every column.
+	IndexedColumns []string
+	// Handler receives warnings. With a nil handler, warnings are written to
+	// stderr like optimizer suggestions.
+	Handler LintHandler
+}
+
+// WithQueryLint checks queries before execution for UPDATE and DELETE
+// without WHERE, SELECT * with joins, leading-wildcard LIKE patterns and
+// functions on columns in WHERE comparisons. Findings are warnings unless
+// their rule is in policy.Block.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithQueryLint(relica.LintPolicy{
+//	        Block:          []relica.LintRule{relica.LintWriteWithoutWhere},
+//	        IndexedColumns: []string{"email", "created_at"},
+//	        Handler: func(ctx context.Context, w relica.LintWarning) {
+//	            slog.WarnContext(ctx, "query lint", "rule", w.Rule, "msg", w.Message, "sql", w.SQL)
+//	        },
+//	    }))
+func WithQueryLint(policy LintPolicy) Option {
+	return optionFunc(func(db *DB) {
+		l := &queryLinter{
+			block:    make(map[LintRule]bool),
+			disable:  make(map[LintRule]bool),
+			handler:  policy.Handler,
+			reported: make(map[string]bool),
+		}
+		for _, rule := range policy.Block {
+			l.block[rule] = true
+		}
+		for _, rule := range policy.Disable {
+			l.disable[rule] = true
+		}
+		if len(policy.IndexedColumns) > 0 {
+			l.indexed = make(map[string]bool)
+			for _, col := range policy.IndexedColumns {
+				l.indexed[strings.ToLower(lastSegment(col))] = true
+			}
+		}
+		db.linter = l
+	})
+}
+
+// queryLinter is the lint configuration of a DB, shared by its copies.
+type queryLinter struct {
+	block    map[LintRule]bool
+	disable  map[LintRule]bool
+	indexed  map[string]bool // nil = every column
+	handler  LintHandler
+	mu       sync.Mutex
+	reported map[string]bool // rule + column + SQL already warned about
+}
+
+// check lints the query. It returns an error wrapping ErrLintViolation for a
+// finding of a blocked rule, and reports the other findings as warnings.
+func (l *queryLinter) check(ctx context.Context, q *Query) error {
+	findings := l.lint(q.sql, q.params)
+	if q.allRows {
+		findings = slices.DeleteFunc(findings, func(w LintWarning) bool { return w.Rule == LintWriteWithoutWhere })
+	}
+	for _, w := range findings {
+		if l.block[w.Rule] {
+			return fmt.Errorf("relica: lint %s: %s: %w", w.Rule, w.Message, ErrLintViolation)
+		}
+	}
+	for _, w := range findings {
+		w.SQL, w.Tag = q.sql, q.tag
+		if l.firstReport(w) {
+			l.report(ctx, w)
+		}
+	}
+	return nil
+}
+
+// firstReport reports whether w has not been warned about before.
+func (l *queryLinter) firstReport(w LintWarning) bool {
+	key := string(w.Rule) + "\x00" + w.Column + "\x00" + w.SQL
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.reported[key] {
+		return false
+	}
+	if len(l.reported) >= maxLintReported {
+		clear(l.reported)
+	}
+	l.reported[key] = true
+	return true
+}
+
+// report passes w to the lint handler, or writes it to stderr.
+func (l *queryLinter) report(ctx context.Context, w LintWarning) {
+	if l.handler != nil {
+		l.handler(ctx, w)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[RELICA LINT] %s\n", w)
+}
+
+// lint returns the findings of the enabled rules in query.
+func (l *queryLinter) lint(query string, params []interface{}) []LintWarning {
+	toks := lintTokens(query)
+	if len(toks) == 0 {
+		return nil
+	}
+	var findings []LintWarning
+	add := func(rule LintRule, column, message string) {
+		if l.disable[rule] {
+			return
+		}
+		if column != "" && l.indexed != nil && !l.indexed[strings.ToLower(lastSegment(column))] {
+			return
+		}
+		findings = append(findings, LintWarning{Rule: rule, Column: column, Message: message})
+	}
+
+	// Statement-level rules look at the outermost statement (depth 0).
+	verb := strings.ToUpper(toks[0].text)
+	depth, hasWhere, hasJoin, starSelect := 0, false, false, false
+	for i, t := range toks {
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case depth != 0 || t.kind != tokWord:
+		case strings.EqualFold(t.text, "WHERE"):
+			hasWhere = true
+		case strings.EqualFold(t.text, "JOIN"):
+			hasJoin = true
+		}
+		if depth == 0 && t.is("*") && i > 0 && isSelectListStart(toks[i-1]) {
+			starSelect = true
+		}
+	}
+	if (verb == opUpdate || verb == opDelete) && !hasWhere {
+		add(LintWriteWithoutWhere, "", verb+" without WHERE affects every row of the table")
+	}
+	if verb == opSelect && starSelect && hasJoin {
+		add(LintSelectStarJoin, "", "SELECT * with JOIN returns the columns of every joined table; name the columns")
+	}
+
+	// Column-level rules look at every comparison.
+	placeholder := 0
+	for i, t := range toks {
+		if t.kind == tokPlaceholder {
+			placeholder++
+		}
+		switch {
+		case t.kind == tokWord && (strings.EqualFold(t.text, "LIKE") || strings.EqualFold(t.text, "ILIKE")) && i+1 < len(toks):
+			col := columnBefore(toks, i)
+			if col == "" {
+				continue
+			}
+			pattern, ok := operandString(toks[i+1], params, placeholder+1)
+			if ok && (strings.HasPrefix(pattern, "%") || strings.HasPrefix(pattern, "_")) {
+				add(LintLeadingWildcard, col, fmt.Sprintf("LIKE pattern %q on %s starts with a wildcard and cannot use an index", pattern, col))
+			}
+		case t.kind == tokWord && i+1 < len(toks) && toks[i+1].is("(") && !lintNotFunctions[strings.ToUpper(t.text)]:
+			col, end := functionColumn(toks, i+1)
+			if col != "" && end+1 < len(toks) && isComparison(toks, end+1) && inWhere(toks, i) {
+				fn := strings.ToUpper(t.text)
+				add(LintNonSargable, col, fmt.Sprintf("%s(%s) in a comparison cannot use an index on %s; compare the column itself or index the expression", fn, col, col))
+			}
+		}
+	}
+	return findings
+}
+
+// lintNotFunctions are keywords that may be followed by "(" without being a
+// function applied to a column.
+var lintNotFunctions = map[string]bool{
+	"IN": true, "EXISTS": true, "NOT": true, "AND": true, "OR": true, "ANY": true, "ALL": true,
+	"SOME": true, "VALUES": true, "AS": true, "ON": true, "USING": true, "WHERE": true, "OVER": true,
+	"FROM": true, "JOIN": true, "SELECT": true, "INTO": true, "SET": true, "BETWEEN": true,
+	"LIKE": true, "ILIKE": true, "IS": true, "CASE": true, "WHEN": true, "THEN": true, "ELSE": true,
+}
+
+// isSelectListStart reports whether a "*" after t is a select-list item.
+func isSelectListStart(t lintToken) bool {
+	return t.kind == tokWord && (strings.EqualFold(t.text, "SELECT") || strings.EqualFold(t.text, "DISTINCT") || strings.EqualFold(t.text, "ALL")) ||
+		t.is(",")
+}
+
+// columnBefore returns the column compared by the operator at toks[i]
+// (skipping NOT), or "" if the left operand is not a plain column.
+func columnBefore(toks []lintToken, i int) string {
+	j := i - 1
+	if j >= 0 && toks[j].kind == tokWord && strings.EqualFold(toks[j].text, "NOT") {
+		j--
+	}
+	return columnEndingAt(toks, j)
+}
+
+// columnEndingAt returns the possibly qualified column name ending at toks[j].
+func columnEndingAt(toks []lintToken, j int) string {
+	if j < 0 || !isColumnToken(toks[j]) {
+		return ""
+	}
+	col := toks[j].text
+	for j >= 2 && toks[j-1].is(".") && isColumnToken(toks[j-2]) {
+		col = toks[j-2].text + "." + col
+		j -= 2
+	}
+	return col
+}
+
+// isColumnToken reports whether t can be (part of) a column name.
+func isColumnToken(t lintToken) bool {
+	if t.kind == tokQuoted {
+		return true
+	}
+	if t.kind != tokWord || t.text[0] >= '0' && t.text[0] <= '9' {
+		return false
+	}
+	return !lintNotFunctions[strings.ToUpper(t.text)] && !strings.EqualFold(t.text, "NULL")
+}
+
+// functionColumn returns the column that is the first argument of the call
+// whose "(" is at toks[open], and the index of the matching ")". col is ""
+// if the first argument is not a plain column.
+func functionColumn(toks []lintToken, open int) (col string, end int) {
+	depth := 0
+	end = -1
+	for k := open; k < len(toks); k++ {
+		switch {
+		case toks[k].is("("):
+			depth++
+		case toks[k].is(")"):
+			depth--
+		}
+		if depth == 0 {
+			end = k
+			break
+		}
+	}
+	if end < 0 {
+		return "", len(toks)
+	}
+	// The first argument ends at "," or ")" and must be a single column.
+	k := open + 1
+	for k < end && !toks[k].is(",") {
+		k++
+	}
+	col = columnEndingAt(toks, k-1)
+	if col == "" || strings.Count(col, ".")*2+1 != k-open-1 {
+		return "", end
+	}
+	return col, end
+}
+
+// isComparison reports whether toks[i] starts a comparison operator.
+func isComparison(toks []lintToken, i int) bool {
+	switch t := toks[i]; {
+	case t.is("=") || t.is("<") || t.is(">"):
+		return true
+	case t.is("!") && i+1 < len(toks) && toks[i+1].is("="):
+		return true
+	case t.kind == tokWord:
+		switch strings.ToUpper(t.text) {
+		case "LIKE", "ILIKE", "IN", "BETWEEN":
+			return true
+		case "NOT":
+			return i+1 < len(toks) && isComparison(toks, i+1)
+		}
+	}
+	return false
+}
+
+// inWhere reports whether toks[i] is in a WHERE or ON condition.
+func inWhere(toks []lintToken, i int) bool {
+	depth := 0
+	for j := i - 1; j >= 0; j-- {
+		switch t := toks[j]; {
+		case t.is(")"):
+			depth++
+		case t.is("("):
+			if depth > 0 {
+				depth--
+			}
+		case depth > 0 || t.kind != tokWord:
+		default:
+			switch strings.ToUpper(t.text) {
+			case "WHERE", "ON":
+				return true
+			case "SELECT", "SET", "GROUP", "ORDER", "HAVING", "VALUES", "RETURNING", "FROM":
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// operandString returns the string value of a LIKE operand: a string literal,
+// or the string parameter bound to a placeholder. n is the number of
+// positional placeholders up to and including t.
+func operandString(t lintToken, params []interface{}, n int) (string, bool) {
+	switch t.kind {
+	case tokString:
+		return t.text, true
+	case tokPlaceholder:
+		idx := n - 1
+		if strings.HasPrefix(t.text, "$") {
+			p, err := strconv.Atoi(t.text[1:])
+			if err != nil {
+				return "", false
+			}
+			idx = p - 1
+		}
+		if idx < 0 || idx >= len(params) {
+			return "", false
+		}
+		s, ok := params[idx].(string)
+		return s, ok
+	}
+	return "", false
+}
+
+// lintToken is a token of a statement being linted.
+type lintToken struct {
+	text string
+	kind tokenKind
+}
+
+// is reports whether t is the punctuation character p.
+func (t lintToken) is(p string) bool {
+	return t.kind == tokPunct && t.text == p
+}
+
+// tokenKind is the kind of a lintToken.
+type tokenKind int
+
+const (
+	tokWord        tokenKind = iota // keyword, identifier or number
+	tokQuoted                       // quoted identifier (text without quotes)
+	tokString                       // string literal (text without quotes)
+	tokPlaceholder                  // ? or $n
+	tokPunct                        // any other character
+)
+
+// lintTokens splits query into tokens, skipping comments. Unlike sqlTokens it
+// keeps string literals and placeholders, which the LIKE rule needs.
+func lintTokens(query string) []lintToken {
+	var toks []lintToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for j < len(query) {
+				if query[j] == '\'' {
+					if j+1 < len(query) && query[j+1] == '\'' {
+						b.WriteByte('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(query[j])
+				j++
+			}
+			toks = append(toks, lintToken{b.String(), tokString})
+			i = j + 1
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return toks
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return toks
+			}
+			i += end + 4
+		case c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return append(toks, lintToken{query[i+1:], tokQuoted})
+			}
+			toks = append(toks, lintToken{query[i+1 : i+1+end], tokQuoted})
+			i += end + 2
+		case c == '?':
+			toks = append(toks, lintToken{"?", tokPlaceholder})
+			i++
+		case c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			start := i
+			i++
+			for i < len(query) && query[i] >= '0' && query[i] <= '9' {
+				i++
+			}
+			toks = append(toks, lintToken{query[start:i], tokPlaceholder})
+		case isWordByte(c):
+			start := i
+			for i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			toks = append(toks, lintToken{query[start:i], tokWord})
+		default:
+			toks = append(toks, lintToken{query[i : i+1], tokPunct})
+			i++
+		}
+	}
+	return toks
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLinter(policy LintPolicy) *queryLinter {
+	db := &DB{}
+	WithQueryLint(policy).apply(db)
+	return db.linter
+}
+
+func TestQueryLinter_Rules(t *testing.T) {
+	l := newTestLinter(LintPolicy{})
+
+	tests := []struct {
+		name   string
+		sql    string
+		params []interface{}
+		rule   LintRule
+		column string
+	}{
+		{"delete without where", `DELETE FROM "users"`, nil, LintWriteWithoutWhere, ""},
+		{"update without where", `UPDATE "users" SET "active" = $1`, []interface{}{false}, LintWriteWithoutWhere, ""},
+		{"update with where only in subquery", `UPDATE users SET a = (SELECT b FROM t WHERE id = 1)`, nil, LintWriteWithoutWhere, ""},
+		{"select star join", `SELECT * FROM "users" INNER JOIN "posts" ON posts.user_id = users.id`, nil, LintSelectStarJoin, ""},
+		{"distinct star join", `SELECT DISTINCT * FROM a LEFT JOIN b ON a.id = b.a_id WHERE a.x = 1`, nil, LintSelectStarJoin, ""},
+		{"leading wildcard param", `SELECT id FROM users WHERE "name" LIKE ?`, []interface{}{"%ann%"}, LintLeadingWildcard, "name"},
+		{"leading wildcard numbered", `SELECT id FROM users WHERE id > $1 AND u.email NOT ILIKE $2`, []interface{}{1, "_x"}, LintLeadingWildcard, "u.email"},
+		{"leading wildcard literal", `SELECT id FROM users WHERE name LIKE '%it''s'`, nil, LintLeadingWildcard, "name"},
+		{"function on column", `SELECT id FROM users WHERE LOWER("email") = ?`, []interface{}{"a"}, LintNonSargable, "email"},
+		{"function in join", `SELECT u.id FROM users u JOIN logins l ON DATE(l.created_at) >= u.since`, nil, LintNonSargable, "l.created_at"},
+		{"function with more args", `SELECT id FROM t WHERE COALESCE(deleted_at, ?) != ?`, []interface{}{nil, nil}, LintNonSargable, "deleted_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := l.lint(tt.sql, tt.params)
+			require.Len(t, findings, 1, "%v", findings)
+			assert.Equal(t, tt.rule, findings[0].Rule)
+			assert.Equal(t, tt.column, findings[0].Column)
+		})
+	}
+}
+
+func TestQueryLinter_Clean(t *testing.T) {
+	l := newTestLinter(LintPolicy{})
+
+	clean := []struct {
+		sql    string
+		params []interface{}
+	}{
+		{`DELETE FROM "users" WHERE "id" = ?`, []interface{}{1}},
+		{`UPDATE users SET name = ? WHERE id = ?`, []interface{}{"a", 1}},
+		{`SELECT * FROM users WHERE id = 1`, nil},
+		{`SELECT COUNT(*) FROM a JOIN b ON a.id = b.a_id`, nil},
+		{`SELECT a.* FROM a JOIN b ON a.id = b.a_id`, nil},
+		{`SELECT id FROM users WHERE name LIKE ?`, []interface{}{"ann%"}},
+		{`SELECT id FROM users WHERE name LIKE 'ann%' AND note = '%'`, nil},
+		{`SELECT LOWER(email) FROM users WHERE id IN (?, ?)`, []interface{}{1, 2}},
+		{`SELECT id FROM users WHERE email = LOWER(?)`, []interface{}{"A"}},
+		{`SELECT id FROM users WHERE EXISTS (SELECT 1 FROM t WHERE t.id = users.id)`, nil},
+		{`SELECT id FROM users WHERE (age > 1) AND name = 'x(y) = z'`, nil},
+		{`SELECT id FROM users ORDER BY LOWER(name) = 'a'`, nil},
+		{`INSERT INTO users (name) VALUES (?)`, []interface{}{"%x"}},
+	}
+	for _, tt := range clean {
+		assert.Empty(t, l.lint(tt.sql, tt.params), tt.sql)
+	}
+}
+
+func TestQueryLinter_Policy(t *testing.T) {
+	l := newTestLinter(LintPolicy{
+		Disable:        []LintRule{LintSelectStarJoin},
+		IndexedColumns: []string{"users.email"},
+	})
+	assert.Empty(t, l.lint(`SELECT * FROM a JOIN b ON a.id = b.id`, nil))
+	assert.Empty(t, l.lint(`SELECT id FROM users WHERE LOWER(name) = ?`, []interface{}{"a"}))
+	assert.Len(t, l.lint(`SELECT id FROM users WHERE LOWER(u.email) = ?`, []interface{}{"a"}), 1)
+	assert.Len(t, l.lint(`DELETE FROM users`, nil), 1, "statement rules ignore IndexedColumns")
+}
+
+func TestWithQueryLint_Execute(t *testing.T) {
+	var warnings []LintWarning
+	db, err := Open("sqlite", ":memory:", WithQueryLint(LintPolicy{
+		Block: []LintRule{LintWriteWithoutWhere},
+		Handler: func(_ context.Context, w LintWarning) {
+			warnings = append(warnings, w)
+		},
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.Builder().Insert("users", map[string]interface{}{"name": "ann"}).Execute()
+	require.NoError(t, err)
+
+	_, err = db.Builder().Delete("users").Execute()
+	assert.ErrorIs(t, err, ErrLintViolation)
+	assert.EqualError(t, err, "relica: lint write-without-where: DELETE without WHERE affects every row of the table: relica: query blocked by lint policy")
+	n, err := db.Builder().Select().From("users").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// Truncate removes every row on purpose
+	require.NoError(t, db.Builder().Truncate("users").Execute())
+
+	// Warnings are reported once per statement
+	var ids []int64
+	for range 3 {
+		err = db.Builder().Select("id").From("users").Where(Like("name", "an")).Tag("search").Column(&ids)
+		require.NoError(t, err)
+	}
+	require.Len(t, warnings, 1)
+	assert.Equal(t, LintLeadingWildcard, warnings[0].Rule)
+	assert.Equal(t, "name", warnings[0].Column)
+	assert.Equal(t, "search", warnings[0].Tag)
+	assert.Contains(t, warnings[0].SQL, "LIKE")
+}
@@ -0,0 +1,237 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Advisory locks
+// ============================================================================
+//
+// PostgreSQL (pg_advisory_lock) and MySQL (GET_LOCK) locks belong to a
+// session, so an AdvisoryLock pins one pool connection until Unlock. If the
+// process dies, the database closes the session and the lock is released.
+//
+// SQLite has no lock functions; locks are rows of the relica_locks table,
+// created on first use. They are visible to every process using the database
+// file but survive a crash: remove stale rows by hand if a holder dies.
+
+// lockTable is the SQLite fallback table holding acquired locks.
+const lockTable = "relica_locks"
+
+// lockPollInterval is how often a blocked SQLite AdvisoryLock retries.
+const lockPollInterval = 50 * time.Millisecond
+
+// mysqlLockNameMax is the maximum length of a MySQL GET_LOCK name.
+const mysqlLockNameMax = 64
+
+// AdvisoryLock is an acquired advisory lock. Release it with Unlock.
+type AdvisoryLock struct {
+	db   *DB
+	key  string
+	conn *sql.Conn // session holding the lock; nil for the SQLite lock table
+
+	mu       sync.Mutex
+	released bool
+}
+
+// AdvisoryLock acquires the named lock, waiting until it is free or ctx is done.
+// Locks are cooperative: they only exclude other callers of AdvisoryLock and
+// TryAdvisoryLock with the same key, in any process sharing the database.
+//
+// Example:
+//
+//	lock, err := db.AdvisoryLock(ctx, "migrations")
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Unlock()
+func (db *DB) AdvisoryLock(ctx context.Context, key string) (*AdvisoryLock, error) {
+	return db.advisoryLock(ctx, key, true)
+}
+
+// TryAdvisoryLock acquires the named lock if it is free and returns ErrLockHeld
+// otherwise. Use it for leader election and to run a job on one instance only.
+//
+// Example:
+//
+//	lock, err := db.TryAdvisoryLock(ctx, "cron:daily-report")
+//	if errors.Is(err, relica.ErrLockHeld) {
+//	    return nil // another instance runs the job
+//	}
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Unlock()
+func (db *DB) TryAdvisoryLock(ctx context.Context, key string) (*AdvisoryLock, error) {
+	return db.advisoryLock(ctx, key, false)
+}
+
+func (db *DB) advisoryLock(ctx context.Context, key string, wait bool) (*AdvisoryLock, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	switch db.dialect.(type) {
+	case *dialects.PostgresDialect, *dialects.MySQLDialect:
+		conn, err := db.sqlDB.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("relica: lock %s: %w", key, err)
+		}
+		if err := db.lockSession(ctx, conn, key, wait); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return &AdvisoryLock{db: db, key: key, conn: conn}, nil
+	default:
+		if err := db.lockTableRow(ctx, key, wait); err != nil {
+			return nil, err
+		}
+		return &AdvisoryLock{db: db, key: key}, nil
+	}
+}
+
+// lockSession takes a session lock on conn.
+func (db *DB) lockSession(ctx context.Context, conn *sql.Conn, key string, wait bool) error {
+	var acquired bool
+	var err error
+	switch {
+	case isPostgres(db.dialect) && wait:
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID(key))
+		acquired = true
+	case isPostgres(db.dialect):
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockID(key)).Scan(&acquired)
+	default:
+		timeout := 0
+		if wait {
+			timeout = mysqlLockTimeout(ctx)
+		}
+		var res sql.NullInt64 // 1 acquired, 0 timed out, NULL on error
+		err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", mysqlLockName(key), timeout).Scan(&res)
+		if err == nil && !res.Valid {
+			err = errors.New("GET_LOCK failed")
+		}
+		acquired = res.Int64 == 1
+	}
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("relica: lock %s: %w", key, err)
+	case acquired:
+		return nil
+	case wait:
+		// GET_LOCK timed out at the context deadline.
+		return fmt.Errorf("relica: lock %s: %w", key, context.DeadlineExceeded)
+	default:
+		return ErrLockHeld
+	}
+}
+
+// lockTableRow takes a lock by inserting its row into the lock table.
+func (db *DB) lockTableRow(ctx context.Context, key string, wait bool) error {
+	d := db.dialect
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+d.QuoteIdentifier(lockTable)+" ("+
+		d.QuoteIdentifier("name")+" TEXT PRIMARY KEY, "+
+		d.QuoteIdentifier("acquired_at")+" DATETIME NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("relica: lock %s: %w", key, err)
+	}
+
+	insert := "INSERT OR IGNORE INTO " + d.QuoteIdentifier(lockTable) +
+		" (" + d.QuoteIdentifier("name") + ", " + d.QuoteIdentifier("acquired_at") + ") VALUES (?, ?)"
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		res, err := db.ExecContext(ctx, insert, key, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("relica: lock %s: %w", key, err)
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			return nil
+		}
+		if !wait {
+			return ErrLockHeld
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("relica: lock %s: %w", key, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unlock releases the lock. Calling Unlock more than once is a no-op.
+func (l *AdvisoryLock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		return nil
+	}
+	l.released = true
+	ctx := context.Background()
+
+	if l.conn == nil {
+		_, err := l.db.ExecContext(ctx, "DELETE FROM "+l.db.dialect.QuoteIdentifier(lockTable)+
+			" WHERE "+l.db.dialect.QuoteIdentifier("name")+" = ?", l.key)
+		if err != nil {
+			return fmt.Errorf("relica: unlock %s: %w", l.key, err)
+		}
+		return nil
+	}
+
+	var err error
+	if isPostgres(l.db.dialect) {
+		_, err = l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID(l.key))
+	} else {
+		_, err = l.conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", mysqlLockName(l.key))
+	}
+	if err != nil {
+		// Discard the session instead of returning it to the pool still holding the lock.
+		_ = l.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		_ = l.conn.Close()
+		return fmt.Errorf("relica: unlock %s: %w", l.key, err)
+	}
+	return l.conn.Close()
+}
+
+// isPostgres reports whether d is the PostgreSQL dialect.
+func isPostgres(d dialects.Dialect) bool {
+	_, ok := d.(*dialects.PostgresDialect)
+	return ok
+}
+
+// lockID maps a lock key to the bigint key of pg_advisory_lock.
+func lockID(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64()) //nolint:gosec // Wrapping to the signed range is intended.
+}
+
+// mysqlLockName returns key, or a hash of it if it exceeds the GET_LOCK name limit.
+func mysqlLockName(key string) string {
+	if len(key) <= mysqlLockNameMax {
+		return key
+	}
+	return "relica:" + strconv.FormatUint(uint64(lockID(key)), 16) //nolint:gosec // Same bits as lockID.
+}
+
+// mysqlLockTimeout returns the GET_LOCK timeout in seconds for ctx: the time
+// left until its deadline (rounded up), or -1 (wait forever) without one.
+func mysqlLockTimeout(ctx context.Context) int {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return -1
+	}
+	return max(0, int(math.Ceil(time.Until(deadline).Seconds()))) // negative means forever
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLockDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+func TestTryAdvisoryLock(t *testing.T) {
+	db := setupLockDB(t)
+	ctx := context.Background()
+
+	lock, err := db.TryAdvisoryLock(ctx, "jobs:report")
+	require.NoError(t, err)
+
+	_, err = db.TryAdvisoryLock(ctx, "jobs:report")
+	assert.ErrorIs(t, err, ErrLockHeld)
+	other, err := db.TryAdvisoryLock(ctx, "jobs:cleanup")
+	require.NoError(t, err, "locks with other keys are independent")
+	require.NoError(t, other.Unlock())
+
+	require.NoError(t, lock.Unlock())
+	require.NoError(t, lock.Unlock(), "a second Unlock is a no-op")
+
+	again, err := db.TryAdvisoryLock(ctx, "jobs:report")
+	require.NoError(t, err)
+	require.NoError(t, again.Unlock())
+}
+
+func TestAdvisoryLock_Waits(t *testing.T) {
+	db := setupLockDB(t)
+	ctx := context.Background()
+
+	lock, err := db.AdvisoryLock(ctx, "leader")
+	require.NoError(t, err)
+
+	timeout, cancel := context.WithTimeout(ctx, 2*lockPollInterval)
+	defer cancel()
+	_, err = db.AdvisoryLock(timeout, "leader")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(2 * lockPollInterval)
+		_ = lock.Unlock()
+	}()
+	next, err := db.AdvisoryLock(ctx, "leader")
+	require.NoError(t, err, "the lock is acquired once released")
+	require.NoError(t, next.Unlock())
+}
+
+func TestLockKeys(t *testing.T) {
+	assert.Equal(t, lockID("a"), lockID("a"))
+	assert.NotEqual(t, lockID("a"), lockID("b"))
+
+	assert.Equal(t, "short", mysqlLockName("short"))
+	long := mysqlLockName(strings.Repeat("x", 100))
+	assert.LessOrEqual(t, len(long), mysqlLockNameMax)
+	assert.True(t, strings.HasPrefix(long, "relica:"))
+
+	assert.Equal(t, -1, mysqlLockTimeout(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 2, mysqlLockTimeout(ctx))
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Maintenance commands
+// ============================================================================
+//
+// Maintenance runs ANALYZE, VACUUM and OPTIMIZE with the statement each
+// database expects:
+//
+//	            Analyze           Vacuum               OptimizeTable
+//	PostgreSQL: ANALYZE "t"       VACUUM [FULL] "t"    VACUUM (FULL, ANALYZE) "t"
+//	MySQL:      ANALYZE TABLE `t` OPTIMIZE TABLE `t`   OPTIMIZE TABLE `t`
+//	SQLite:     ANALYZE "t"       VACUUM               VACUUM; ANALYZE "t"
+//
+// MySQL has no VACUUM; OPTIMIZE TABLE rebuilds the table, which is what a
+// full vacuum does. SQLite vacuums the whole database file. The commands
+// cannot run inside a transaction on PostgreSQL and SQLite. Every statement
+// is written to the audit log with its operation (ANALYZE, VACUUM or
+// OPTIMIZE), also at the AuditWrites level.
+
+// Audit log operations of the maintenance commands.
+const (
+	opAnalyze  = "ANALYZE"
+	opVacuum   = "VACUUM"
+	opOptimize = "OPTIMIZE"
+)
+
+// Maintenance runs table maintenance commands (see DB.Maintenance).
+type Maintenance struct {
+	db *DB
+}
+
+// Maintenance returns the maintenance commands of the database.
+func (db *DB) Maintenance() *Maintenance {
+	return &Maintenance{db: db}
+}
+
+// MaintenanceOption configures a maintenance command.
+type MaintenanceOption func(*maintenanceOptions)
+
+type maintenanceOptions struct {
+	full bool
+}
+
+// Full makes Vacuum rewrite the table to return its free space to the
+// operating system (VACUUM FULL), which locks the table exclusively while it
+// runs. MySQL and SQLite always rebuild, so Full changes nothing there.
+func Full() MaintenanceOption {
+	return func(o *maintenanceOptions) {
+		o.full = true
+	}
+}
+
+// Analyze updates the planner statistics of table. On PostgreSQL and SQLite
+// an empty table analyzes the whole database.
+//
+// Example:
+//
+//	err := db.Maintenance().Analyze(ctx, "orders")
+func (m *Maintenance) Analyze(ctx context.Context, table string) error {
+	return m.run(ctx, opAnalyze, table, maintenanceOptions{})
+}
+
+// Vacuum reclaims the space of deleted and updated rows of table. On
+// PostgreSQL it makes the space reusable without blocking reads and writes,
+// or with Full also shrinks the table file. MySQL runs OPTIMIZE TABLE.
+// SQLite rebuilds the whole database file of the table's schema. On
+// PostgreSQL an empty table vacuums every table of the database.
+//
+// Example:
+//
+//	err := db.Maintenance().Vacuum(ctx, "orders", relica.Full())
+func (m *Maintenance) Vacuum(ctx context.Context, table string, opts ...MaintenanceOption) error {
+	var o maintenanceOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return m.run(ctx, opVacuum, table, o)
+}
+
+// OptimizeTable rebuilds table to reclaim its space and refreshes its
+// statistics: VACUUM (FULL, ANALYZE) on PostgreSQL, OPTIMIZE TABLE on MySQL,
+// and VACUUM followed by ANALYZE on SQLite. The table is locked while it is
+// rebuilt.
+//
+// Example:
+//
+//	err := db.Maintenance().OptimizeTable(ctx, "events")
+func (m *Maintenance) OptimizeTable(ctx context.Context, table string) error {
+	return m.run(ctx, opOptimize, table, maintenanceOptions{})
+}
+
+// maintenanceStatements returns the statements running the maintenance
+// operation on table for dialect.
+func maintenanceStatements(operation, table string, o maintenanceOptions, dialect dialects.Dialect) ([]string, error) {
+	switch dialect.(type) {
+	case *dialects.PostgresDialect, *dialects.MySQLDialect, *dialects.SQLiteDialect:
+	default:
+		return nil, ErrUnsupportedDialect
+	}
+	_, mysql := dialect.(*dialects.MySQLDialect)
+	if table == "" && (mysql || operation == opOptimize) {
+		return nil, errors.New("table name is empty")
+	}
+
+	switch operation {
+	case opAnalyze:
+		if mysql {
+			return []string{"ANALYZE TABLE " + quoteColumn(table, dialect)}, nil
+		}
+		return []string{appendTable(opAnalyze, table, dialect)}, nil
+	case opVacuum:
+		switch dialect.(type) {
+		case *dialects.PostgresDialect:
+			if o.full {
+				return []string{appendTable("VACUUM FULL", table, dialect)}, nil
+			}
+			return []string{appendTable(opVacuum, table, dialect)}, nil
+		case *dialects.MySQLDialect:
+			return []string{"OPTIMIZE TABLE " + quoteColumn(table, dialect)}, nil
+		default:
+			return []string{sqliteVacuum(table, dialect)}, nil
+		}
+	default:
+		switch dialect.(type) {
+		case *dialects.PostgresDialect:
+			return []string{"VACUUM (FULL, ANALYZE) " + quoteColumn(table, dialect)}, nil
+		case *dialects.MySQLDialect:
+			return []string{"OPTIMIZE TABLE " + quoteColumn(table, dialect)}, nil
+		default:
+			return []string{sqliteVacuum(table, dialect), "ANALYZE " + quoteColumn(table, dialect)}, nil
+		}
+	}
+}
+
+// appendTable returns stmt followed by the quoted table, if any.
+func appendTable(stmt, table string, dialect dialects.Dialect) string {
+	if table == "" {
+		return stmt
+	}
+	return stmt + " " + quoteColumn(table, dialect)
+}
+
+// sqliteVacuum returns the VACUUM statement of the schema of table: SQLite
+// vacuums whole database files.
+func sqliteVacuum(table string, dialect dialects.Dialect) string {
+	if schema, _ := splitQualifiedTable(table); schema != nil {
+		return "VACUUM " + dialect.QuoteIdentifier(*schema)
+	}
+	return opVacuum
+}
+
+// run executes the statements of a maintenance operation in order and
+// writes each to the audit log.
+func (m *Maintenance) run(ctx context.Context, operation, table string, o maintenanceOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	name := strings.ToLower(operation)
+	if table != "" {
+		name += " " + table
+	}
+	statements, err := maintenanceStatements(operation, table, o, m.db.dialect)
+	if err != nil {
+		return fmt.Errorf("relica: %s: %w", name, err)
+	}
+
+	for _, stmt := range statements {
+		start := time.Now()
+		q := m.db.NewQuery(stmt).WithContext(ctx)
+		var result sql.Result
+		if _, ok := m.db.dialect.(*dialects.MySQLDialect); ok {
+			err = mysqlAdminResult(q)
+		} else {
+			result, err = q.Execute()
+		}
+
+		if m.db.auditor != nil {
+			m.db.auditor.LogOperation(ctx, operation, stmt, nil, result, err, time.Since(start))
+		}
+		if err != nil {
+			return fmt.Errorf("relica: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// mysqlAdminRow is a result row of ANALYZE TABLE and OPTIMIZE TABLE.
+type mysqlAdminRow struct {
+	Table   string `db:"Table"`
+	Op      string `db:"Op"`
+	MsgType string `db:"Msg_type"`
+	MsgText string `db:"Msg_text"`
+}
+
+// mysqlAdminResult runs a MySQL table maintenance statement, which reports
+// failures such as a missing table as result rows rather than errors.
+func mysqlAdminResult(q *Query) error {
+	var rows []mysqlAdminRow
+	if err := q.All(&rows); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if strings.EqualFold(row.MsgType, "error") {
+			return errors.New(row.MsgText)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/coregx/relica/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceStatements(t *testing.T) {
+	full := maintenanceOptions{full: true}
+	tests := []struct {
+		dialect   string
+		operation string
+		table     string
+		opts      maintenanceOptions
+		want      []string
+	}{
+		{"postgres", opAnalyze, "orders", maintenanceOptions{}, []string{`ANALYZE "orders"`}},
+		{"postgres", opAnalyze, "", maintenanceOptions{}, []string{`ANALYZE`}},
+		{"postgres", opVacuum, "app.orders", maintenanceOptions{}, []string{`VACUUM "app"."orders"`}},
+		{"postgres", opVacuum, "orders", full, []string{`VACUUM FULL "orders"`}},
+		{"postgres", opVacuum, "", full, []string{`VACUUM FULL`}},
+		{"postgres", opOptimize, "orders", maintenanceOptions{}, []string{`VACUUM (FULL, ANALYZE) "orders"`}},
+		{"mysql", opAnalyze, "orders", maintenanceOptions{}, []string{"ANALYZE TABLE `orders`"}},
+		{"mysql", opVacuum, "orders", full, []string{"OPTIMIZE TABLE `orders`"}},
+		{"mysql", opOptimize, "shop.orders", maintenanceOptions{}, []string{"OPTIMIZE TABLE `shop`.`orders`"}},
+		{"sqlite", opAnalyze, "orders", maintenanceOptions{}, []string{`ANALYZE "orders"`}},
+		{"sqlite", opVacuum, "orders", full, []string{`VACUUM`}},
+		{"sqlite", opVacuum, "aux.orders", maintenanceOptions{}, []string{`VACUUM "aux"`}},
+		{"sqlite", opOptimize, "orders", maintenanceOptions{}, []string{`VACUUM`, `ANALYZE "orders"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect+" "+strings.Join(tt.want, "; "), func(t *testing.T) {
+			got, err := maintenanceStatements(tt.operation, tt.table, tt.opts, dialects.GetDialect(tt.dialect))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMaintenanceStatements_Errors(t *testing.T) {
+	_, err := maintenanceStatements(opAnalyze, "", maintenanceOptions{}, dialects.GetDialect("mysql"))
+	assert.EqualError(t, err, "table name is empty")
+	_, err = maintenanceStatements(opOptimize, "", maintenanceOptions{}, dialects.GetDialect("postgres"))
+	assert.EqualError(t, err, "table name is empty")
+
+	custom := customDialect{dialects.GetDialect("postgres").(*dialects.PostgresDialect)}
+	_, err = maintenanceStatements(opAnalyze, "orders", maintenanceOptions{}, custom)
+	assert.ErrorIs(t, err, ErrUnsupportedDialect)
+}
+
+func TestMaintenance_SQLite(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	var buf bytes.Buffer
+	db.auditor = security.NewAuditor(slog.New(slog.NewJSONHandler(&buf, nil)), security.AuditWrites)
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY, customer TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "CREATE INDEX orders_customer ON orders (customer)")
+	require.NoError(t, err)
+	_, err = db.Builder().Insert("orders", map[string]interface{}{"customer": "ann"}).Execute()
+	require.NoError(t, err)
+
+	m := db.Maintenance()
+	require.NoError(t, m.Analyze(ctx, "orders"))
+	require.NoError(t, m.Vacuum(ctx, "orders", Full()))
+	require.NoError(t, m.OptimizeTable(ctx, "orders"))
+
+	var analyzed int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM sqlite_stat1 WHERE tbl = ?", "orders").Row(&analyzed))
+	assert.Positive(t, analyzed)
+
+	log := buf.String()
+	assert.Contains(t, log, `"operation":"ANALYZE"`)
+	assert.Contains(t, log, `"operation":"VACUUM"`)
+	assert.Contains(t, log, `"operation":"OPTIMIZE"`)
+	assert.Equal(t, 4, strings.Count(log, `"success":true`), "one event per statement")
+
+	err = m.Analyze(ctx, "missing")
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "relica: analyze missing: "), err.Error())
+	assert.Contains(t, buf.String(), `"success":false`)
+}
+
+func TestMaintenance_InTransaction(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	err = db.Transactional(context.Background(), func(tx *Tx) error {
+		return tx.DB().Maintenance().Vacuum(context.Background(), "")
+	})
+	assert.Error(t, err, "VACUUM cannot run inside a transaction")
+}
@@ -0,0 +1,501 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// MERGE
+// ============================================================================
+//
+// MergeQuery synchronizes a target table with a source query in one
+// statement: rows matched by the ON condition are updated or deleted, the
+// others inserted. PostgreSQL 15+ runs it as a MERGE statement.
+//
+// MySQL and SQLite have no MERGE; there the statement is emulated with an
+// upsert (INSERT ... SELECT ... ON DUPLICATE KEY UPDATE / ON CONFLICT DO
+// UPDATE). The emulation decides matches by the target's unique key instead of
+// the ON condition, so ON must compare the Keys columns, and it supports one
+// unconditional WhenMatchedUpdate and one WhenNotMatchedInsert.
+
+// opMerge is the ChangeEvent operation of MERGE statements.
+const opMerge = "MERGE"
+
+// MergeQuery represents a MERGE query being built.
+type MergeQuery struct {
+	builder     *QueryBuilder
+	target      string // "table" or "table alias"
+	source      *SelectQuery
+	sourceTable string
+	alias       string
+	on          interface{} // string or Expression
+	onParams    []interface{}
+	keys        []string // unique key columns of the target (upsert emulation)
+	actions     []mergeAction
+	ctx         context.Context // context for this specific query
+	buildErr    error           // stored programming error (replaces panic in fluent chain)
+}
+
+// mergeAction is a WHEN [NOT] MATCHED clause.
+type mergeAction struct {
+	matched  bool
+	action   string // UPDATE, DELETE or INSERT
+	values   map[string]interface{}
+	cond     string
+	condArgs []interface{}
+}
+
+// mergeQualifierRegex matches a qualified column reference "x.col" or "x"."col".
+var mergeQualifierRegex = regexp.MustCompile("(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\b\\w+)\\.(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\w+)")
+
+// Merge creates a MERGE query into the target table ("table" or "table alias").
+//
+// Example:
+//
+//	staged := db.Builder().Select("id", "name", "email").From("staging_users")
+//	db.Builder().Merge("users u").
+//	    Using(staged, "s", "u.id = s.id").
+//	    WhenMatchedUpdate(map[string]interface{}{"name": relica.NewExp("s.name"), "email": relica.NewExp("s.email")}).
+//	    WhenNotMatchedInsert(map[string]interface{}{"id": relica.NewExp("s.id"), "name": relica.NewExp("s.name"), "email": relica.NewExp("s.email")}).
+//	    Keys("id").
+//	    Execute()
+//
+// Generates (PostgreSQL):
+//
+//	MERGE INTO "users" AS "u" USING (SELECT "id", "name", "email" FROM "staging_users") AS "s" ON u.id = s.id
+//	WHEN MATCHED THEN UPDATE SET "email" = s.email, "name" = s.name
+//	WHEN NOT MATCHED THEN INSERT ("email", "id", "name") VALUES (s.email, s.id, s.name)
+func (qb *QueryBuilder) Merge(target string) *MergeQuery {
+	mq := &MergeQuery{builder: qb, target: target}
+	if strings.TrimSpace(target) == "" {
+		mq.buildErr = errors.New("relica: Merge requires a target table")
+	}
+	return mq
+}
+
+// WithContext sets the context for this MERGE query.
+// This overrides any context set on the QueryBuilder.
+func (mq *MergeQuery) WithContext(ctx context.Context) *MergeQuery {
+	mq.ctx = ctx
+	return mq
+}
+
+// Using sets the source query, its alias and the match condition (a string
+// with "?" placeholders or an Expression).
+func (mq *MergeQuery) Using(source *SelectQuery, alias string, on interface{}, params ...interface{}) *MergeQuery {
+	if source == nil {
+		mq.buildErr = errors.New("relica: Merge source query is nil")
+		return mq
+	}
+	mq.source = source
+	mq.sourceTable = ""
+	return mq.using(alias, on, params)
+}
+
+// UsingTable sets a source table, its alias and the match condition.
+func (mq *MergeQuery) UsingTable(table, alias string, on interface{}, params ...interface{}) *MergeQuery {
+	if table == "" {
+		mq.buildErr = errors.New("relica: Merge source table is empty")
+		return mq
+	}
+	mq.source = nil
+	mq.sourceTable = table
+	return mq.using(alias, on, params)
+}
+
+func (mq *MergeQuery) using(alias string, on interface{}, params []interface{}) *MergeQuery {
+	if alias == "" {
+		mq.buildErr = errors.New("relica: Merge source requires a non-empty alias")
+		return mq
+	}
+	mq.alias = alias
+	switch cond := on.(type) {
+	case string:
+		resolved, args, err := resolveNamedParams(cond, params)
+		if err != nil {
+			mq.buildErr = err
+			return mq
+		}
+		mq.on, mq.onParams = resolved, args
+	case Expression:
+		if err := checkExpressionTree(cond, mq.builder.db.dialect); err != nil {
+			mq.buildErr = err
+			return mq
+		}
+		mq.on, mq.onParams = cond, nil
+	default:
+		mq.buildErr = fmt.Errorf("relica: Merge ON must be string or Expression, got %T", on)
+	}
+	return mq
+}
+
+// Keys sets the target's unique key columns that the ON condition compares.
+// Only the upsert emulation (MySQL, SQLite) uses them, as the conflict target.
+func (mq *MergeQuery) Keys(columns ...string) *MergeQuery {
+	mq.keys = columns
+	return mq
+}
+
+// WhenMatchedUpdate updates matched target rows. Values are bound as
+// parameters unless they are Expressions, e.g. relica.NewExp("s.name") for a
+// source column.
+func (mq *MergeQuery) WhenMatchedUpdate(values map[string]interface{}) *MergeQuery {
+	if len(values) == 0 {
+		mq.buildErr = errors.New("relica: WhenMatchedUpdate requires values")
+		return mq
+	}
+	if err := checkValueExpressions(values, mq.builder.db.dialect); err != nil {
+		mq.buildErr = err
+		return mq
+	}
+	mq.actions = append(mq.actions, mergeAction{matched: true, action: "UPDATE", values: values})
+	return mq
+}
+
+// WhenMatchedDelete deletes matched target rows (MERGE only).
+func (mq *MergeQuery) WhenMatchedDelete() *MergeQuery {
+	mq.actions = append(mq.actions, mergeAction{matched: true, action: "DELETE"})
+	return mq
+}
+
+// WhenNotMatchedInsert inserts source rows without a matching target row.
+// Values are bound as parameters unless they are Expressions.
+func (mq *MergeQuery) WhenNotMatchedInsert(values map[string]interface{}) *MergeQuery {
+	if len(values) == 0 {
+		mq.buildErr = errors.New("relica: WhenNotMatchedInsert requires values")
+		return mq
+	}
+	if err := checkValueExpressions(values, mq.builder.db.dialect); err != nil {
+		mq.buildErr = err
+		return mq
+	}
+	mq.actions = append(mq.actions, mergeAction{action: "INSERT", values: values})
+	return mq
+}
+
+// And adds a condition to the last WHEN clause (WHEN MATCHED AND cond THEN ...),
+// so several clauses can handle different rows (MERGE only).
+//
+// Example:
+//
+//	Merge("users u").Using(staged, "s", "u.id = s.id").
+//	    WhenMatchedDelete().And("s.deleted = ?", true).
+//	    WhenMatchedUpdate(map[string]interface{}{"name": relica.NewExp("s.name")})
+func (mq *MergeQuery) And(condition string, params ...interface{}) *MergeQuery {
+	if len(mq.actions) == 0 {
+		mq.buildErr = errors.New("relica: Merge And requires a preceding WHEN clause")
+		return mq
+	}
+	resolved, args, err := resolveNamedParams(condition, params)
+	if err != nil {
+		mq.buildErr = err
+		return mq
+	}
+	last := &mq.actions[len(mq.actions)-1]
+	last.cond, last.condArgs = resolved, args
+	return mq
+}
+
+// Build constructs the Query object from MergeQuery.
+func (mq *MergeQuery) Build() *Query {
+	// Context priority: query ctx > builder ctx > nil
+	ctx := mq.ctx
+	if ctx == nil {
+		ctx = mq.builder.ctx
+	}
+
+	dialect := mq.builder.db.dialect
+	query, params, err := mq.buildStatement(dialect)
+	if err != nil {
+		return &Query{
+			prepErr: err,
+			db:      mq.builder.db,
+			tx:      mq.builder.tx,
+			tag:     mq.builder.tag,
+			ctx:     ctx,
+		}
+	}
+
+	table := strings.Fields(mq.target)[0]
+	columns := mq.columns()
+	return mq.builder.db.guard(&Query{
+		sql:    query,
+		params: params,
+		db:     mq.builder.db,
+		tx:     mq.builder.tx,
+		tag:    mq.builder.tag,
+		ctx:    ctx,
+		change: mq.builder.db.newChange(table, opMerge, columns, nil, nil),
+		refs:   mq.builder.db.newSchemaRefs(table, columns...),
+	})
+}
+
+// Execute executes the MERGE query and returns the result.
+func (mq *MergeQuery) Execute() (interface{}, error) {
+	return mq.Build().Execute()
+}
+
+// ToSQL returns the SQL string and parameters without executing the query.
+func (mq *MergeQuery) ToSQL() (string, []interface{}) {
+	q := mq.Build()
+	return q.sql, q.params
+}
+
+// columns returns the target columns written by the WHEN clauses, sorted.
+func (mq *MergeQuery) columns() []string {
+	all := make(map[string]interface{})
+	for _, a := range mq.actions {
+		for col := range a.values {
+			all[col] = nil
+		}
+	}
+	return getKeys(all)
+}
+
+// buildStatement renders the MERGE statement, or its upsert emulation.
+func (mq *MergeQuery) buildStatement(dialect dialects.Dialect) (string, []interface{}, error) {
+	if mq.buildErr != nil {
+		return "", nil, mq.buildErr
+	}
+	if mq.source == nil && mq.sourceTable == "" {
+		return "", nil, errors.New("relica: Merge requires a source, call Using() or UsingTable()")
+	}
+	if len(mq.actions) == 0 {
+		return "", nil, errors.New("relica: Merge requires a WHEN clause")
+	}
+
+	var params []interface{}
+	source, err := mq.buildSource(dialect, &params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var query string
+	switch dialect.(type) {
+	case *dialects.MySQLDialect, *dialects.SQLiteDialect:
+		query, err = mq.buildUpsert(dialect, source, &params)
+	default:
+		query, err = mq.buildMerge(dialect, source, &params)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return renumberFragment(query, 1, len(params), dialect), params, nil
+}
+
+// buildSource renders the USING source with "?" placeholders.
+func (mq *MergeQuery) buildSource(dialect dialects.Dialect, params *[]interface{}) (string, error) {
+	alias := dialect.QuoteIdentifier(mq.alias)
+	if mq.source == nil {
+		return quoteColumn(mq.sourceTable, dialect) + " AS " + alias, nil
+	}
+	subSQL, subArgs := mq.source.fragmentSQL(dialect)
+	if mq.source.buildErr != nil {
+		return "", mq.source.buildErr
+	}
+	*params = append(*params, subArgs...)
+	return "(" + subSQL + ") AS " + alias, nil
+}
+
+// buildMerge renders a MERGE statement (PostgreSQL 15+).
+func (mq *MergeQuery) buildMerge(dialect dialects.Dialect, source string, params *[]interface{}) (string, error) {
+	var b strings.Builder
+	b.WriteString("MERGE INTO " + tableWithAlias(mq.target, dialect) + " USING " + source + " ON ")
+
+	switch on := mq.on.(type) {
+	case string:
+		b.WriteString(on)
+		*params = append(*params, mq.onParams...)
+	case Expression:
+		sqlStr, args := on.Build(dialect)
+		b.WriteString(sqlStr)
+		*params = append(*params, args...)
+	}
+
+	for _, a := range mq.actions {
+		if a.matched {
+			b.WriteString(" WHEN MATCHED")
+		} else {
+			b.WriteString(" WHEN NOT MATCHED")
+		}
+		if a.cond != "" {
+			b.WriteString(" AND " + a.cond)
+			*params = append(*params, a.condArgs...)
+		}
+		b.WriteString(" THEN ")
+
+		switch a.action {
+		case "UPDATE":
+			cols, vals := mergeValues(a.values, dialect, params)
+			sets := make([]string, len(cols))
+			for i := range cols {
+				sets[i] = cols[i] + " = " + vals[i]
+			}
+			b.WriteString("UPDATE SET " + strings.Join(sets, ", "))
+		case "INSERT":
+			cols, vals := mergeValues(a.values, dialect, params)
+			b.WriteString("INSERT (" + strings.Join(cols, ", ") + ") VALUES (" + strings.Join(vals, ", ") + ")")
+		default:
+			b.WriteString("DELETE")
+		}
+	}
+	return b.String(), nil
+}
+
+// buildUpsert emulates the MERGE with INSERT ... SELECT and a conflict clause.
+func (mq *MergeQuery) buildUpsert(dialect dialects.Dialect, source string, params *[]interface{}) (string, error) {
+	_, mysql := dialect.(*dialects.MySQLDialect)
+	name := "sqlite"
+	if mysql {
+		name = "mysql"
+	}
+
+	var update, insert *mergeAction
+	for i := range mq.actions {
+		a := &mq.actions[i]
+		switch {
+		case a.cond != "":
+			return "", fmt.Errorf("relica: Merge on %s: conditional WHEN clauses require MERGE (PostgreSQL 15+)", name)
+		case a.action == "DELETE":
+			return "", fmt.Errorf("relica: Merge on %s: WhenMatchedDelete requires MERGE (PostgreSQL 15+)", name)
+		case a.action == "UPDATE" && update == nil:
+			update = a
+		case a.action == "INSERT" && insert == nil:
+			insert = a
+		default:
+			return "", fmt.Errorf("relica: Merge on %s: only one WhenMatchedUpdate and one WhenNotMatchedInsert are supported", name)
+		}
+	}
+	if insert == nil {
+		return "", fmt.Errorf("relica: Merge on %s requires WhenNotMatchedInsert", name)
+	}
+	if !mysql && len(mq.keys) == 0 {
+		return "", fmt.Errorf("relica: Merge on %s requires Keys(columns...) matching a unique key of the target", name)
+	}
+
+	// The source is selected into the target; ON is decided by the unique key.
+	fields := strings.Fields(mq.target)
+	table := quoteColumn(fields[0], dialect)
+	cols, vals := mergeValues(insert.values, dialect, params)
+	query := "INSERT INTO " + table + " (" + strings.Join(cols, ", ") + ") SELECT " + strings.Join(vals, ", ") + " FROM " + source
+
+	if mysql {
+		if update == nil {
+			// Assigning a column to itself keeps the existing row.
+			col := cols[0]
+			return query + " ON DUPLICATE KEY UPDATE " + col + " = " + table + "." + col, nil
+		}
+		// Source columns stay in scope; the target alias becomes the table name.
+		sets, err := mq.emulatedSets(update, dialect, params, func(qualifier, col string) (string, error) {
+			if len(fields) == 2 && unquoteIdentifier(qualifier) == fields[1] {
+				return table + "." + col, nil
+			}
+			return qualifier + "." + col, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return query + " ON DUPLICATE KEY UPDATE " + sets, nil
+	}
+
+	keys := make([]string, len(mq.keys))
+	for i, k := range mq.keys {
+		keys[i] = dialect.QuoteIdentifier(k)
+	}
+	// SQLite needs WHERE to tell the ON CONFLICT clause from a join constraint.
+	query += " WHERE true ON CONFLICT (" + strings.Join(keys, ", ") + ")"
+	if update == nil {
+		return query + " DO NOTHING", nil
+	}
+
+	// Source columns are only reachable as the proposed (excluded) row, under
+	// the name of the target column they are inserted into.
+	inserted := make(map[string]string, len(insert.values))
+	for col, v := range insert.values {
+		if exp, ok := v.(Expression); ok {
+			sqlStr, _ := exp.Build(dialect)
+			if m := mergeQualifierRegex.FindStringSubmatch(strings.TrimSpace(sqlStr)); m != nil && m[0] == strings.TrimSpace(sqlStr) {
+				inserted[unquoteIdentifier(m[1])+"."+unquoteIdentifier(m[2])] = col
+			}
+		}
+	}
+	sets, err := mq.emulatedSets(update, dialect, params, func(qualifier, col string) (string, error) {
+		q := unquoteIdentifier(qualifier)
+		switch {
+		case q == mq.alias:
+			ref := q + "." + unquoteIdentifier(col)
+			target, ok := inserted[ref]
+			if !ok {
+				return "", fmt.Errorf("relica: Merge on sqlite: %s is not inserted by WhenNotMatchedInsert", ref)
+			}
+			return "excluded." + dialect.QuoteIdentifier(target), nil
+		case len(fields) == 2 && q == fields[1]:
+			return table + "." + col, nil
+		default:
+			return qualifier + "." + col, nil
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return query + " DO UPDATE SET " + sets, nil
+}
+
+// emulatedSets renders the SET list of the emulated update, rewriting
+// qualified column references with rewrite.
+func (mq *MergeQuery) emulatedSets(update *mergeAction, dialect dialects.Dialect, params *[]interface{},
+	rewrite func(qualifier, col string) (string, error)) (string, error) {
+	cols, vals := mergeValues(update.values, dialect, params)
+	sets := make([]string, len(cols))
+	var err error
+	for i := range cols {
+		val := mergeQualifierRegex.ReplaceAllStringFunc(vals[i], func(ref string) string {
+			m := mergeQualifierRegex.FindStringSubmatch(ref)
+			out, rerr := rewrite(m[1], m[2])
+			if rerr != nil && err == nil {
+				err = rerr
+			}
+			return out
+		})
+		sets[i] = cols[i] + " = " + val
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(sets, ", "), nil
+}
+
+// mergeValues renders values sorted by column: quoted columns and their values,
+// bound as "?" parameters or rendered inline for Expressions.
+func mergeValues(values map[string]interface{}, dialect dialects.Dialect, params *[]interface{}) (cols, vals []string) {
+	keys := getKeys(values)
+	cols = make([]string, len(keys))
+	vals = make([]string, len(keys))
+	for i, col := range keys {
+		cols[i] = dialect.QuoteIdentifier(col)
+		if exp, ok := values[col].(Expression); ok {
+			sqlStr, args := exp.Build(dialect)
+			vals[i] = sqlStr
+			*params = append(*params, args...)
+			continue
+		}
+		vals[i] = "?"
+		*params = append(*params, values[col])
+	}
+	return cols, vals
+}
+
+// unquoteIdentifier strips double quotes or backquotes from an identifier.
+func unquoteIdentifier(id string) string {
+	if len(id) >= 2 && (id[0] == '"' || id[0] == '`') && id[len(id)-1] == id[0] {
+		q := id[:1]
+		return strings.ReplaceAll(id[1:len(id)-1], q+q, q)
+	}
+	return id
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 COREGX. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeQuery_PostgreSQL(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	staged := qb.Select("id", "name", "deleted").From("staging_users").Where(Eq("batch", 7))
+	q := qb.Merge("users u").
+		Using(staged, "s", "u.id = s.id AND u.tenant = ?", "acme").
+		WhenMatchedDelete().And("s.deleted = ?", true).
+		WhenMatchedUpdate(map[string]interface{}{"name": NewExp("s.name"), "synced": true}).
+		WhenNotMatchedInsert(map[string]interface{}{"id": NewExp("s.id"), "name": NewExp("s.name"), "tenant": "acme"}).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `MERGE INTO "users" AS "u" USING (SELECT "id", "name", "deleted" FROM "staging_users" WHERE "batch" = $1) AS "s" ON u.id = s.id AND u.tenant = $2`+
+		` WHEN MATCHED AND s.deleted = $3 THEN DELETE`+
+		` WHEN MATCHED THEN UPDATE SET "name" = s.name, "synced" = $4`+
+		` WHEN NOT MATCHED THEN INSERT ("id", "name", "tenant") VALUES (s.id, s.name, $5)`, q.sql)
+	assert.Equal(t, []interface{}{7, "acme", true, true, "acme"}, q.params)
+}
+
+func TestMergeQuery_PostgreSQL_UsingTableAndExpressionOn(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Merge("inventory").
+		UsingTable("deliveries", "d", And(EqCol("inventory.sku", "d.sku"), Eq("d.day", "2026-10-15"))).
+		WhenMatchedUpdate(map[string]interface{}{"qty": NewExp("inventory.qty + d.qty")}).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `MERGE INTO "inventory" USING "deliveries" AS "d" ON ("inventory"."sku" = "d"."sku") AND ("d"."day" = $1) WHEN MATCHED THEN UPDATE SET "qty" = inventory.qty + d.qty`, q.sql)
+	assert.Equal(t, []interface{}{"2026-10-15"}, q.params)
+}
+
+func TestMergeQuery_SQLiteEmulation(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+
+	staged := qb.Select("id", "name", "visits").From("staging").Where(Eq("batch", 7))
+	q := qb.Merge("users u").
+		Using(staged, "s", "u.id = s.id").
+		Keys("id").
+		WhenMatchedUpdate(map[string]interface{}{"name": NewExp("s.name"), "visits": NewExp("u.visits + s.visits + ?", 1)}).
+		WhenNotMatchedInsert(map[string]interface{}{"id": NewExp("s.id"), "name": NewExp("s.name"), "visits": NewExp(`"s"."visits"`)}).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "users" ("id", "name", "visits") SELECT s.id, s.name, "s"."visits" FROM (SELECT "id", "name", "visits" FROM "staging" WHERE "batch" = ?) AS "s"`+
+		` WHERE true ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name", "visits" = "users".visits + excluded."visits" + ?`, q.sql)
+	assert.Equal(t, []interface{}{7, 1}, q.params)
+
+	q = qb.Merge("users").Using(staged, "s", "users.id = s.id").Keys("id").
+		WhenNotMatchedInsert(map[string]interface{}{"id": NewExp("s.id")}).
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "users" ("id") SELECT s.id FROM (SELECT "id", "name", "visits" FROM "staging" WHERE "batch" = ?) AS "s" WHERE true ON CONFLICT ("id") DO NOTHING`, q.sql)
+}
+
+func TestMergeQuery_MySQLEmulation(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("mysql")}
+
+	q := qb.Merge("users u").
+		UsingTable("staging", "s", "u.id = s.id").
+		WhenMatchedUpdate(map[string]interface{}{"visits": NewExp("u.visits + s.visits")}).
+		WhenNotMatchedInsert(map[string]interface{}{"id": NewExp("s.id"), "visits": NewExp("s.visits")}).
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "INSERT INTO `users` (`id`, `visits`) SELECT s.id, s.visits FROM `staging` AS `s` ON DUPLICATE KEY UPDATE `visits` = `users`.visits + s.visits", q.sql)
+
+	q = qb.Merge("users").UsingTable("staging", "s", "users.id = s.id").
+		WhenNotMatchedInsert(map[string]interface{}{"id": NewExp("s.id")}).
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "INSERT INTO `users` (`id`) SELECT s.id FROM `staging` AS `s` ON DUPLICATE KEY UPDATE `id` = `users`.`id`", q.sql)
+}
+
+func TestMergeQuery_Errors(t *testing.T) {
+	insert := map[string]interface{}{"id": NewExp("s.id")}
+
+	tests := []struct {
+		name    string
+		dialect string
+		build   func(qb *QueryBuilder) *MergeQuery
+		want    string
+	}{
+		{"empty target", "postgres", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge(" ").UsingTable("staging", "s", "true").WhenNotMatchedInsert(insert)
+		}, "relica: Merge requires a target table"},
+		{"no source", "postgres", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").WhenNotMatchedInsert(insert)
+		}, "relica: Merge requires a source, call Using() or UsingTable()"},
+		{"nil source", "postgres", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").Using(nil, "s", "true")
+		}, "relica: Merge source query is nil"},
+		{"no alias", "postgres", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "", "true")
+		}, "relica: Merge source requires a non-empty alias"},
+		{"bad on", "postgres", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", 1)
+		}, "relica: Merge ON must be string or Expression, got int"},
+		{"no when", "postgres", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", "true")
+		}, "relica: Merge requires a WHEN clause"},
+		{"and first", "postgres", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", "true").And("x")
+		}, "relica: Merge And requires a preceding WHEN clause"},
+		{"delete emulated", "sqlite", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", "true").Keys("id").WhenMatchedDelete().WhenNotMatchedInsert(insert)
+		}, "relica: Merge on sqlite: WhenMatchedDelete requires MERGE (PostgreSQL 15+)"},
+		{"condition emulated", "mysql", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", "true").WhenNotMatchedInsert(insert).And("s.ok")
+		}, "relica: Merge on mysql: conditional WHEN clauses require MERGE (PostgreSQL 15+)"},
+		{"no insert emulated", "mysql", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", "true").WhenMatchedUpdate(map[string]interface{}{"a": 1})
+		}, "relica: Merge on mysql requires WhenNotMatchedInsert"},
+		{"no keys on sqlite", "sqlite", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", "true").WhenNotMatchedInsert(insert)
+		}, "relica: Merge on sqlite requires Keys(columns...) matching a unique key of the target"},
+		{"source column not inserted", "sqlite", func(qb *QueryBuilder) *MergeQuery {
+			return qb.Merge("users").UsingTable("staging", "s", "true").Keys("id").
+				WhenMatchedUpdate(map[string]interface{}{"name": NewExp("s.name")}).
+				WhenNotMatchedInsert(insert)
+		}, "relica: Merge on sqlite: s.name is not inserted by WhenNotMatchedInsert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.build(&QueryBuilder{db: mockDB(tt.dialect)}).Build()
+			assert.EqualError(t, q.prepErr, tt.want)
+		})
+	}
+}
+
+func TestMergeQuery_ReadOnly(t *testing.T) {
+	db := mockDB("postgres")
+	db.readOnly = true
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Merge("users").UsingTable("staging", "s", "users.id = s.id").
+		WhenNotMatchedInsert(map[string]interface{}{"id": NewExp("s.id")}).
+		Build()
+	assert.ErrorIs(t, q.prepErr, ErrReadOnly)
+}
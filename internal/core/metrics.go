@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// ============================================================================
+// Metrics
+// ============================================================================
+//
+// WithMeterProvider records query and connection pool metrics through a
+// meter, typically OpenTelemetry's, so that teams on OpenTelemetry need no
+// separate Prometheus path. Like Tracer, MeterProvider is a small interface
+// that an adapter implements, which keeps relica free of dependencies.
+//
+// Instruments (OpenTelemetry database client conventions):
+//
+//   - db.client.operation.duration: histogram of query durations in seconds
+//   - db.client.operation.errors: counter of failed queries
+//   - db.client.connection.count: open connections, by state (idle, used)
+//   - db.client.connection.max: maximum open connections
+//   - db.client.connection.wait_count: connections waited for, in total
+//
+// Query metrics carry db.system, db.operation, relica.query.fingerprint (see
+// Fingerprint) and, for tagged queries, relica.tag; pool gauges carry
+// db.client.connection.pool.name.
+
+// meterName is the instrumentation scope name of relica's meter.
+const meterName = "github.com/coregx/relica"
+
+// Attribute is a metric attribute.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// MeterProvider provides the Meter relica records metrics with (see WithMeterProvider).
+type MeterProvider interface {
+	// Meter returns the meter of the named instrumentation scope.
+	Meter(name string) Meter
+}
+
+// Meter creates instruments.
+type Meter interface {
+	// Float64Histogram returns a histogram instrument.
+	Float64Histogram(name, unit, description string) Float64Histogram
+	// Int64Counter returns a monotonic counter instrument.
+	Int64Counter(name, unit, description string) Int64Counter
+	// Int64ObservableGauge registers a gauge whose values are reported by
+	// observe, called on every collection with a function recording one value.
+	Int64ObservableGauge(name, unit, description string, observe func(record func(value int64, attrs ...Attribute)))
+}
+
+// Float64Histogram records a distribution of values.
+type Float64Histogram interface {
+	Record(ctx context.Context, value float64, attrs ...Attribute)
+}
+
+// Int64Counter records increments of a sum.
+type Int64Counter interface {
+	Add(ctx context.Context, value int64, attrs ...Attribute)
+}
+
+// Metric and attribute names.
+const (
+	metricOperationDuration = "db.client.operation.duration"
+	metricOperationErrors   = "db.client.operation.errors"
+	metricConnectionCount   = "db.client.connection.count"
+	metricConnectionMax     = "db.client.connection.max"
+	metricConnectionWaits   = "db.client.connection.wait_count"
+	attrConnectionState     = "db.client.connection.state"
+	attrPoolName            = "db.client.connection.pool.name"
+	attrErrorType           = "error.type"
+	attrQueryFingerprint    = "relica.query.fingerprint"
+	primaryPoolName         = "default"
+)
+
+// WithMeterProvider records query durations, query errors and connection
+// pool gauges through the meter of mp.
+//
+// Example (OpenTelemetry adapter, see the logging guide for the full code):
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithMeterProvider(otelMeterProvider{otel.GetMeterProvider()}))
+func WithMeterProvider(mp MeterProvider) Option {
+	return optionFunc(func(db *DB) {
+		if mp == nil {
+			return
+		}
+		meter := mp.Meter(meterName)
+		db.metrics = &queryMetrics{
+			duration: meter.Float64Histogram(metricOperationDuration, "s", "Duration of database queries."),
+			errors:   meter.Int64Counter(metricOperationErrors, "{error}", "Number of failed database queries."),
+		}
+		db.registerPoolGauges(meter)
+	})
+}
+
+// queryMetrics are the query instruments of a DB.
+type queryMetrics struct {
+	duration Float64Histogram
+	errors   Int64Counter
+}
+
+// record records the duration and outcome of a query.
+func (m *queryMetrics) record(ctx context.Context, driver string, event QueryEvent) {
+	attrs := []Attribute{
+		{Key: attrDBSystem, Value: driver},
+		{Key: attrDBOperation, Value: event.Operation},
+		{Key: attrQueryFingerprint, Value: Fingerprint(event.SQL)},
+	}
+	if event.Tag != "" {
+		attrs = append(attrs, Attribute{Key: attrQueryTag, Value: event.Tag})
+	}
+	m.duration.Record(ctx, event.Duration.Seconds(), attrs...)
+	if event.Error != nil && !errors.Is(event.Error, ErrNotFound) {
+		m.errors.Add(ctx, 1, append(attrs, Attribute{Key: attrErrorType, Value: errorType(event.Error)})...)
+	}
+}
+
+// errorType classifies a query error for the error.type attribute.
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case IsUniqueViolation(err), IsForeignKeyViolation(err), IsNotNullViolation(err), IsCheckViolation(err):
+		return "constraint"
+	case IsTransientError(err):
+		return "connection"
+	}
+	return "other"
+}
+
+// registerPoolGauges registers the connection gauges of the primary pool and
+// of every named pool.
+func (db *DB) registerPoolGauges(meter Meter) {
+	each := func(record func(value int64, attrs ...Attribute), value func(PoolStats) int64, attrs ...Attribute) {
+		for _, p := range db.poolStats() {
+			record(value(p.stats), append([]Attribute{{Key: attrPoolName, Value: p.name}}, attrs...)...)
+		}
+	}
+
+	meter.Int64ObservableGauge(metricConnectionCount, "{connection}", "Number of open connections by state.",
+		func(record func(int64, ...Attribute)) {
+			each(record, func(s PoolStats) int64 { return int64(s.Idle) }, Attribute{Key: attrConnectionState, Value: "idle"})
+			each(record, func(s PoolStats) int64 { return int64(s.InUse) }, Attribute{Key: attrConnectionState, Value: "used"})
+		})
+	meter.Int64ObservableGauge(metricConnectionMax, "{connection}", "Maximum number of open connections allowed.",
+		func(record func(int64, ...Attribute)) {
+			each(record, func(s PoolStats) int64 { return int64(s.MaxOpenConnections) })
+		})
+	meter.Int64ObservableGauge(metricConnectionWaits, "{wait}", "Total number of connections waited for.",
+		func(record func(int64, ...Attribute)) {
+			each(record, func(s PoolStats) int64 { return s.WaitCount })
+		})
+}
+
+// namedPoolStats are the statistics of one pool.
+type namedPoolStats struct {
+	name  string
+	stats PoolStats
+}
+
+// poolStats returns the statistics of the primary pool and the named pools,
+// ordered by name after the primary pool.
+func (db *DB) poolStats() []namedPoolStats {
+	primary := db.primary()
+	all := []namedPoolStats{{name: primaryPoolName, stats: primary.Stats()}}
+	if primary.pools == nil {
+		return all
+	}
+
+	primary.pools.mu.Lock()
+	names := sortedKeys(primary.pools.pools)
+	pools := make([]*Pool, len(names))
+	for i, name := range names {
+		pools[i] = primary.pools.pools[name]
+	}
+	primary.pools.mu.Unlock()
+
+	for _, p := range pools {
+		all = append(all, namedPoolStats{name: p.name, stats: p.Stats()})
+	}
+	return all
+}
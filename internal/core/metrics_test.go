@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// measurement is one value recorded by recordingMeter.
+type measurement struct {
+	value float64
+	attrs map[string]string
+}
+
+// recordingMeter records measurements by instrument name, like an in-memory reader.
+type recordingMeter struct {
+	mu     sync.Mutex
+	values map[string][]measurement
+	gauges map[string]func(record func(int64, ...Attribute))
+	scope  string
+}
+
+func newRecordingMeter() *recordingMeter {
+	return &recordingMeter{values: make(map[string][]measurement), gauges: make(map[string]func(record func(int64, ...Attribute)))}
+}
+
+func (m *recordingMeter) Meter(name string) Meter {
+	m.scope = name
+	return m
+}
+
+func (m *recordingMeter) add(name string, value float64, attrs []Attribute) {
+	set := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		set[a.Key] = a.Value
+	}
+	m.mu.Lock()
+	m.values[name] = append(m.values[name], measurement{value: value, attrs: set})
+	m.mu.Unlock()
+}
+
+func (m *recordingMeter) Float64Histogram(name, _, _ string) Float64Histogram {
+	return instrument{m, name}
+}
+
+func (m *recordingMeter) Int64Counter(name, _, _ string) Int64Counter {
+	return instrument{m, name}
+}
+
+func (m *recordingMeter) Int64ObservableGauge(name, _, _ string, observe func(record func(int64, ...Attribute))) {
+	m.gauges[name] = observe
+}
+
+// collect observes a gauge and returns its values.
+func (m *recordingMeter) collect(name string) []measurement {
+	var out []measurement
+	m.gauges[name](func(v int64, attrs ...Attribute) {
+		set := make(map[string]string, len(attrs))
+		for _, a := range attrs {
+			set[a.Key] = a.Value
+		}
+		out = append(out, measurement{value: float64(v), attrs: set})
+	})
+	return out
+}
+
+type instrument struct {
+	m    *recordingMeter
+	name string
+}
+
+func (i instrument) Record(_ context.Context, v float64, attrs ...Attribute) {
+	i.m.add(i.name, v, attrs)
+}
+func (i instrument) Add(_ context.Context, v int64, attrs ...Attribute) {
+	i.m.add(i.name, float64(v), attrs)
+}
+
+func TestMeterProvider_QueryMetrics(t *testing.T) {
+	meter := newRecordingMeter()
+	db, err := Open("sqlite", ":memory:", WithMeterProvider(meter))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	assert.Equal(t, "github.com/coregx/relica", meter.scope)
+
+	_, err = db.NewQuery("CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT UNIQUE)").Execute()
+	require.NoError(t, err)
+	_, err = db.Builder().Insert("accounts", map[string]interface{}{"id": 1, "email": "a@example.com"}).Execute()
+	require.NoError(t, err)
+	_, err = db.Builder().Insert("accounts", map[string]interface{}{"id": 2, "email": "a@example.com"}).Execute()
+	require.Error(t, err)
+
+	var acc struct {
+		ID int `db:"id"`
+	}
+	err = db.Builder().Select("id").From("accounts").Where("id = ?", 9).Tag("accounts.find").One(&acc)
+	require.Error(t, err)
+
+	durations := meter.values[metricOperationDuration]
+	require.Len(t, durations, 4)
+	assert.Equal(t, "sqlite", durations[1].attrs[attrDBSystem])
+	assert.Equal(t, "INSERT", durations[1].attrs[attrDBOperation])
+	assert.Equal(t, "accounts.find", durations[3].attrs[attrQueryTag])
+	assert.GreaterOrEqual(t, durations[1].value, 0.0)
+
+	errs := meter.values[metricOperationErrors]
+	require.Len(t, errs, 1, "not-found is not an error")
+	assert.Equal(t, "constraint", errs[0].attrs[attrErrorType])
+	assert.Equal(t, 1.0, errs[0].value)
+}
+
+func TestMeterProvider_PoolGauges(t *testing.T) {
+	meter := newRecordingMeter()
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "pool.db"), WithMeterProvider(meter), WithMaxOpenConns(3))
+	require.NoError(t, err)
+	defer db.Close()
+	reporting, err := db.Pool("reporting")
+	require.NoError(t, err)
+	reporting.SetMaxOpenConns(2)
+
+	maxConns := meter.collect(metricConnectionMax)
+	require.Len(t, maxConns, 2)
+	assert.Equal(t, measurement{value: 3, attrs: map[string]string{attrPoolName: "default"}}, maxConns[0])
+	assert.Equal(t, measurement{value: 2, attrs: map[string]string{attrPoolName: "reporting"}}, maxConns[1])
+
+	require.NoError(t, db.sqlDB.Ping())
+	counts := meter.collect(metricConnectionCount)
+	require.Len(t, counts, 4)
+	assert.Equal(t, map[string]string{attrPoolName: "default", attrConnectionState: "idle"}, counts[0].attrs)
+	assert.Equal(t, 1.0, counts[0].value)
+	assert.Len(t, meter.collect(metricConnectionWaits), 2)
+}
+
+func TestErrorType(t *testing.T) {
+	assert.Equal(t, "timeout", errorType(context.DeadlineExceeded))
+	assert.Equal(t, "canceled", errorType(context.Canceled))
+	assert.Equal(t, "constraint", errorType(errors.New("UNIQUE constraint failed: users.email")))
+	assert.Equal(t, "connection", errorType(errors.New("read: connection reset by peer")))
+	assert.Equal(t, "other", errorType(errors.New("syntax error")))
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type findMember struct {
+	ID     int     `db:"id"`
+	Name   string  `db:"name"`
+	Status string  `db:"status"`
+	Age    int     `db:"age"`
+	Nick   *string `db:"nick"`
+}
+
+func (findMember) TableName() string { return "members" }
+
+func setupFindTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.ExecContext(context.Background(),
+		`CREATE TABLE members (id INTEGER PRIMARY KEY, name TEXT, status TEXT, age INTEGER, nick TEXT)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), `INSERT INTO members (name, status, age, nick) VALUES
+		('alice', 'active', 30, 'al'),
+		('albert', 'active', 17, NULL),
+		('bob', 'banned', 0, NULL),
+		('carol', 'active', 45, 'cc')`)
+	require.NoError(t, err)
+	return db
+}
+
+func findNames(members []findMember) []string {
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.Name
+	}
+	return names
+}
+
+func TestModelQuery_Find(t *testing.T) {
+	db := setupFindTestDB(t)
+
+	var members []findMember
+	require.NoError(t, db.Model(&findMember{Status: "active"}).Find(&members))
+	assert.ElementsMatch(t, []string{"alice", "albert", "carol"}, findNames(members))
+
+	members = nil
+	require.NoError(t, db.Model(&findMember{Status: "active", Age: 17}).Find(&members))
+	assert.Equal(t, []string{"albert"}, findNames(members))
+
+	// No non-zero fields: every row matches.
+	members = nil
+	require.NoError(t, db.Model(&findMember{}).Find(&members))
+	assert.Len(t, members, 4)
+
+	// Excluded fields are ignored.
+	members = nil
+	require.NoError(t, db.Model(&findMember{Status: "active", Name: "zed"}).Exclude("name").Find(&members))
+	assert.Len(t, members, 3)
+}
+
+func TestModelQuery_Find_One(t *testing.T) {
+	db := setupFindTestDB(t)
+
+	var m findMember
+	require.NoError(t, db.Model(&findMember{Name: "carol"}).Find(&m))
+	assert.Equal(t, 45, m.Age)
+	require.NotNil(t, m.Nick)
+	assert.Equal(t, "cc", *m.Nick)
+
+	err := db.Model(&findMember{Name: "nobody"}).Find(&m)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestModelQuery_Find_Match(t *testing.T) {
+	db := setupFindTestDB(t)
+
+	var members []findMember
+	err := db.Model(&findMember{Name: "al%", Age: 18}).
+		Match("name", "like").
+		Match("age", ">=").
+		Find(&members)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, findNames(members))
+
+	// Explicit operators include zero-valued fields.
+	members = nil
+	require.NoError(t, db.Model(&findMember{}).Match("age", ">").Find(&members))
+	assert.Len(t, members, 3)
+
+	// Nil pointers compare with IS [NOT] NULL.
+	members = nil
+	require.NoError(t, db.Model(&findMember{}).Match("nick", "=").Find(&members))
+	assert.ElementsMatch(t, []string{"albert", "bob"}, findNames(members))
+
+	members = nil
+	require.NoError(t, db.Model(&findMember{}).Match("nick", "!=").Find(&members))
+	assert.ElementsMatch(t, []string{"alice", "carol"}, findNames(members))
+}
+
+func TestModelQuery_Find_Errors(t *testing.T) {
+	db := setupFindTestDB(t)
+	var members []findMember
+
+	err := db.Model(&findMember{Name: "x"}).Match("name", "; DROP TABLE members").Find(&members)
+	assert.ErrorContains(t, err, "unsupported Match operator")
+
+	err = db.Model(&findMember{}).Match("missing", "=").Find(&members)
+	assert.ErrorContains(t, err, `Match column "missing"`)
+
+	err = db.Model(&findMember{}).Table("").Find(&members)
+	assert.ErrorContains(t, err, "table name not specified")
+}
+
+func TestModelQuery_Find_SQL(t *testing.T) {
+	db := setupFindTestDB(t)
+
+	var captured string
+	db.queryHook = func(_ context.Context, e QueryEvent) { captured = e.SQL }
+
+	var members []findMember
+	require.NoError(t, db.Model(&findMember{Status: "active", Age: 30}).Match("age", "<").Find(&members))
+	assert.Equal(t, `SELECT * FROM "members" WHERE ("age" < ?) AND ("status" = ?)`, captured)
+}
@@ -972,3 +972,38 @@ func TestModel_CompositePK_NoAutoPopulate(t *testing.T) {
 	assert.Equal(t, 0, result.OrderID)
 	assert.Equal(t, 0, result.ProductID)
 }
+
+func TestModel_CompositePK_UpdateChangedAndUpsert(t *testing.T) {
+	db := setupModelTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS order_items (
+			order_id INTEGER NOT NULL,
+			product_id INTEGER NOT NULL,
+			quantity INTEGER NOT NULL DEFAULT 1,
+			PRIMARY KEY (order_id, product_id)
+		)
+	`)
+	require.NoError(t, err)
+
+	// Two rows sharing order_id: the WHERE clause must use both key columns.
+	require.NoError(t, db.Model(&OrderItem{OrderID: 1, ProductID: 10, Quantity: 1}).Insert())
+	require.NoError(t, db.Model(&OrderItem{OrderID: 1, ProductID: 20, Quantity: 1}).Insert())
+
+	original := OrderItem{OrderID: 1, ProductID: 20, Quantity: 1}
+	item := original
+	item.Quantity = 7
+	require.NoError(t, db.Model(&item).UpdateChanged(&original))
+
+	// Upsert on an existing composite key updates the non-key columns.
+	require.NoError(t, db.Model(&OrderItem{OrderID: 1, ProductID: 10, Quantity: 3}).Upsert())
+
+	var rows []OrderItem
+	require.NoError(t, db.Builder().Select().From("order_items").OrderBy("product_id").All(&rows))
+	require.Len(t, rows, 2)
+	assert.Equal(t, 3, rows[0].Quantity)
+	assert.Equal(t, 7, rows[1].Quantity)
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Model row locks
+// ============================================================================
+//
+// ModelQuery.One reloads a model by primary key. With LockForUpdate or
+// LockForShare it also locks the row until the transaction ends, which makes
+// read-modify-write sequences safe:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    order := Order{ID: id}
+//	    if err := tx.Model(&order).LockForUpdate().One(); err != nil {
+//	        return err
+//	    }
+//	    order.Status = "paid"
+//	    return tx.Model(&order).Update("status")
+//	})
+//
+// SQLite has no row locks (a write transaction locks the database), so the
+// lock clause is omitted there.
+
+// rowLock is the row lock taken by ModelQuery.One.
+type rowLock int
+
+const (
+	lockNone rowLock = iota
+	lockUpdate
+	lockShare
+)
+
+// LockForUpdate makes One lock the row with SELECT ... FOR UPDATE, so no
+// other transaction can lock, update or delete it until the current one
+// ends. Requires a transaction (tx.Model, or db.Model on a Tx.DB).
+func (mq *ModelQuery) LockForUpdate() *ModelQuery {
+	mq.lock = lockUpdate
+	return mq
+}
+
+// LockForShare makes One take a shared row lock (FOR SHARE on PostgreSQL,
+// LOCK IN SHARE MODE on MySQL): other transactions can still read and
+// share-lock the row, but not update or delete it until the current one
+// ends. Requires a transaction.
+func (mq *ModelQuery) LockForShare() *ModelQuery {
+	mq.lock = lockShare
+	return mq
+}
+
+// One reloads the model from the row with its primary key, overwriting its
+// fields. Returns ErrNotFound if the row does not exist. Supports single and
+// composite primary keys.
+//
+// Example:
+//
+//	user := User{ID: 42}
+//	err := db.Model(&user).One()
+func (mq *ModelQuery) One() error {
+	if mq.table == "" {
+		return errors.New("model: table name not specified")
+	}
+	if v := reflect.ValueOf(mq.model); v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("model: One requires a pointer to a struct")
+	}
+	if mq.lock != lockNone && mq.tx == nil {
+		return errors.New("model: row locks require a transaction (use tx.Model)")
+	}
+
+	pkCols, pkValues, err := mq.getPrimaryKeys()
+	if err != nil {
+		return errors.New("model: primary key not found")
+	}
+
+	qb := &QueryBuilder{
+		db:  mq.db,
+		tx:  mq.tx,
+		ctx: mq.ctx,
+	}
+	conds := make([]Expression, len(pkCols))
+	for i, col := range pkCols {
+		conds[i] = Eq(col, pkValues[i])
+	}
+	q := qb.Select().From(mq.table).Where(And(conds...)).Build()
+	q.appendSQL(mq.lockClause())
+	return mq.db.guard(q).One(mq.model)
+}
+
+// lockClause returns the SQL suffix of the row lock, "" for none.
+func (mq *ModelQuery) lockClause() string {
+	if _, ok := mq.db.dialect.(*dialects.SQLiteDialect); ok {
+		return ""
+	}
+	switch mq.lock {
+	case lockUpdate:
+		return " FOR UPDATE"
+	case lockShare:
+		if _, ok := mq.db.dialect.(*dialects.MySQLDialect); ok {
+			return " LOCK IN SHARE MODE"
+		}
+		return " FOR SHARE"
+	}
+	return ""
+}
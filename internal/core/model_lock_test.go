@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lockedOrder struct {
+	ID     int64  `db:"id"`
+	Status string `db:"status"`
+	Total  int    `db:"total"`
+}
+
+func (lockedOrder) TableName() string { return "orders" }
+
+func TestModelQuery_LockClause(t *testing.T) {
+	tests := []struct {
+		dialect string
+		update  string
+		share   string
+	}{
+		{"postgres", " FOR UPDATE", " FOR SHARE"},
+		{"mysql", " FOR UPDATE", " LOCK IN SHARE MODE"},
+		{"sqlite", "", ""},
+	}
+	for _, tt := range tests {
+		db := mockDB(tt.dialect)
+		assert.Empty(t, db.Model(&lockedOrder{}).lockClause(), tt.dialect)
+		assert.Equal(t, tt.update, db.Model(&lockedOrder{}).LockForUpdate().lockClause(), tt.dialect)
+		assert.Equal(t, tt.share, db.Model(&lockedOrder{}).LockForShare().lockClause(), tt.dialect)
+	}
+}
+
+func TestModelQuery_One(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, total INTEGER)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO orders (id, status, total) VALUES (1, 'new', 10)")
+	require.NoError(t, err)
+
+	order := lockedOrder{ID: 1, Status: "stale"}
+	require.NoError(t, db.Model(&order).One())
+	assert.Equal(t, lockedOrder{ID: 1, Status: "new", Total: 10}, order)
+
+	missing := lockedOrder{ID: 2}
+	assert.ErrorIs(t, db.Model(&missing).One(), ErrNotFound)
+	assert.ErrorContains(t, db.Model(lockedOrder{ID: 1}).One(), "pointer")
+	assert.ErrorContains(t, db.Model(&order).LockForUpdate().One(), "require a transaction")
+
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		locked := lockedOrder{ID: 1}
+		if err := tx.Model(&locked).LockForUpdate().One(); err != nil {
+			return err
+		}
+		locked.Total += 5
+		return tx.Model(&locked).Update("total")
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&order).One())
+	assert.Equal(t, 15, order.Total)
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/coregx/relica/internal/util"
@@ -23,7 +25,9 @@ type ModelQuery struct {
 	model   interface{}
 	table   string
 	exclude map[string]bool
-	ctx     context.Context // nil means use background context
+	match   map[string]string // Find comparison operators by column (default "=")
+	lock    rowLock           // row lock taken by One (see LockForUpdate)
+	ctx     context.Context   // nil means use background context
 }
 
 // SetContext sets the context for this ModelQuery.
@@ -37,7 +41,7 @@ func (mq *ModelQuery) SetContext(ctx context.Context) *ModelQuery {
 func (db *DB) Model(model interface{}) *ModelQuery {
 	return &ModelQuery{
 		db:      db,
-		tx:      nil,
+		tx:      db.boundSQLTx(),
 		model:   model,
 		table:   inferTableName(model),
 		exclude: make(map[string]bool),
@@ -154,11 +158,23 @@ func (mq *ModelQuery) Exclude(attrs ...string) *ModelQuery {
 //
 // For composite primary keys (CPK), auto-populate is NOT supported.
 // All CPK values must be provided by the caller.
+//
+// For tables registered with WithHistory, the time of the insert is recorded
+// in the history table in the same transaction.
 func (mq *ModelQuery) Insert(attrs ...string) error {
 	if mq.table == "" {
 		return errors.New("model: table name not specified")
 	}
 
+	// Fill relica:"ulid" and relica:"snowflake" fields.
+	if err := mq.generateIDs(); err != nil {
+		return err
+	}
+
+	if err := mq.validateModel(); err != nil {
+		return err
+	}
+
 	// Convert struct to map.
 	dataMap, err := util.StructToMap(mq.model)
 	if err != nil {
@@ -186,37 +202,34 @@ func (mq *ModelQuery) Insert(attrs ...string) error {
 		}
 	}
 
-	// Create builder with transaction/query context if applicable.
-	qb := &QueryBuilder{
-		db:  mq.db,
-		tx:  mq.tx,
-		ctx: mq.ctx,
-	}
-
-	// Build INSERT query.
-	query := qb.Insert(mq.table, filtered)
+	// Builder with transaction/query context, recording history if enabled.
+	return mq.insertWithHistory(func(qb *QueryBuilder) error {
+		// Build INSERT query.
+		query := qb.Insert(mq.table, filtered)
+		mq.setModelChange(query)
 
-	// Check if we need PostgreSQL RETURNING clause for auto-ID.
-	// Only for single PK - composite PKs don't support auto-populate.
-	needsReturning, pkCol := mq.needsPostgresReturning()
-	if needsReturning {
-		// PostgreSQL: Use RETURNING clause (lib/pq doesn't support LastInsertId).
-		return mq.insertWithReturning(query, pkCol)
-	}
+		// Check if we need PostgreSQL RETURNING clause for auto-ID.
+		// Only for single PK - composite PKs don't support auto-populate.
+		needsReturning, pkCol := mq.needsPostgresReturning()
+		if needsReturning {
+			// PostgreSQL: Use RETURNING clause (lib/pq doesn't support LastInsertId).
+			return mq.insertWithReturning(query, pkCol)
+		}
 
-	// MySQL/SQLite: Use standard LastInsertId().
-	result, err := query.Execute()
-	if err != nil {
-		return err
-	}
+		// MySQL/SQLite: Use standard LastInsertId().
+		result, err := query.Execute()
+		if err != nil {
+			return err
+		}
 
-	// Auto-populate primary key (TASK-008).
-	// Only for single PK - composite PKs don't support auto-populate.
-	// Errors are silently ignored (backward compatibility) - insert succeeded,
-	// ID population failure is acceptable.
-	_ = mq.populatePrimaryKey(result)
+		// Auto-populate primary key (TASK-008).
+		// Only for single PK - composite PKs don't support auto-populate.
+		// Errors are silently ignored (backward compatibility) - insert succeeded,
+		// ID population failure is acceptable.
+		_ = mq.populatePrimaryKey(result)
 
-	return nil
+		return nil
+	})
 }
 
 // populatePrimaryKey auto-populates the primary key after INSERT.
@@ -361,11 +374,17 @@ func (mq *ModelQuery) insertWithReturning(query *Query, pkCol string) error {
 
 // Update updates the model in the table.
 // Supports both single PK and composite PK for WHERE clause.
+// For tables registered with WithHistory, the previous row version is
+// recorded in the history table in the same transaction.
 func (mq *ModelQuery) Update(attrs ...string) error {
 	if mq.table == "" {
 		return errors.New("model: table name not specified")
 	}
 
+	if err := mq.validateModel(); err != nil {
+		return err
+	}
+
 	// Convert struct to map.
 	dataMap, err := util.StructToMap(mq.model)
 	if err != nil {
@@ -386,26 +405,24 @@ func (mq *ModelQuery) Update(attrs ...string) error {
 		delete(filtered, col)
 	}
 
-	// Create builder with transaction/query context if applicable.
-	qb := &QueryBuilder{
-		db:  mq.db,
-		tx:  mq.tx,
-		ctx: mq.ctx,
-	}
-
-	// Build UPDATE query with WHERE clause for all PK columns.
-	updateQuery := qb.Update(mq.table).Set(filtered)
+	// Builder with transaction/query context, after recording history if enabled.
+	return mq.writeWithHistory(pkCols, pkValues, func(qb *QueryBuilder) error {
+		// Build UPDATE query with WHERE clause for all PK columns.
+		updateQuery := qb.Update(mq.table).Set(filtered)
 
-	for i, col := range pkCols {
-		if i == 0 {
-			updateQuery = updateQuery.Where(Eq(col, pkValues[i]))
-		} else {
-			updateQuery = updateQuery.AndWhere(Eq(col, pkValues[i]))
+		for i, col := range pkCols {
+			if i == 0 {
+				updateQuery = updateQuery.Where(Eq(col, pkValues[i]))
+			} else {
+				updateQuery = updateQuery.AndWhere(Eq(col, pkValues[i]))
+			}
 		}
-	}
 
-	_, err = updateQuery.Execute()
-	return err
+		q := updateQuery.Build()
+		mq.setModelChange(q)
+		_, err := q.Execute()
+		return err
+	})
 }
 
 // Upsert performs an INSERT ... ON CONFLICT DO UPDATE for the model.
@@ -435,6 +452,10 @@ func (mq *ModelQuery) Upsert(fields ...string) error {
 		return errors.New("model: table name not specified")
 	}
 
+	if err := mq.validateModel(); err != nil {
+		return err
+	}
+
 	// Convert struct to map.
 	dataMap, err := util.StructToMap(mq.model)
 	if err != nil {
@@ -462,6 +483,7 @@ func (mq *ModelQuery) Upsert(fields ...string) error {
 		DoUpdate(updateCols...)
 
 	q := upsertQuery.Build()
+	mq.setModelChange(q)
 
 	// PostgreSQL: use RETURNING to auto-populate single PK.
 	needsReturning, pkCol := mq.needsPostgresReturning()
@@ -540,9 +562,11 @@ func (mq *ModelQuery) scanReturningID(q *Query, _ string) error {
 //
 // It compares the current model against original field by field using reflection.
 // Only fields that have changed are included in the UPDATE SET clause.
-// Primary key fields are always excluded from the SET clause.
+// Primary key fields and fields excluded with Exclude are never included.
 //
 // If nothing has changed, no query is executed and nil is returned.
+// As with Update, the previous row version is recorded for tables registered
+// with WithHistory.
 //
 // The original parameter must be the same type as the model passed to Model().
 // It can be either a pointer or a value of the struct type.
@@ -563,6 +587,10 @@ func (mq *ModelQuery) UpdateChanged(original interface{}) error {
 		return errors.New("model: table name not specified")
 	}
 
+	if err := mq.validateModel(); err != nil {
+		return err
+	}
+
 	changed, err := mq.diffFields(original)
 	if err != nil {
 		return err
@@ -579,29 +607,27 @@ func (mq *ModelQuery) UpdateChanged(original interface{}) error {
 		return errors.New("model: primary key not found")
 	}
 
-	// Create builder with transaction/query context if applicable.
-	qb := &QueryBuilder{
-		db:  mq.db,
-		tx:  mq.tx,
-		ctx: mq.ctx,
-	}
+	// Builder with transaction/query context, after recording history if enabled.
+	return mq.writeWithHistory(pkCols, pkValues, func(qb *QueryBuilder) error {
+		updateQuery := qb.Update(mq.table).Set(changed)
 
-	updateQuery := qb.Update(mq.table).Set(changed)
-
-	for i, col := range pkCols {
-		if i == 0 {
-			updateQuery = updateQuery.Where(Eq(col, pkValues[i]))
-		} else {
-			updateQuery = updateQuery.AndWhere(Eq(col, pkValues[i]))
+		for i, col := range pkCols {
+			if i == 0 {
+				updateQuery = updateQuery.Where(Eq(col, pkValues[i]))
+			} else {
+				updateQuery = updateQuery.AndWhere(Eq(col, pkValues[i]))
+			}
 		}
-	}
 
-	_, err = updateQuery.Execute()
-	return err
+		q := updateQuery.Build()
+		mq.setModelChange(q)
+		_, err := q.Execute()
+		return err
+	})
 }
 
 // diffFields compares the current model with original and returns only the fields
-// whose values have changed, excluding primary key fields.
+// whose values have changed, excluding primary key fields and fields excluded with Exclude.
 //
 //nolint:cyclop // Acceptable complexity for field comparison across all reflect kinds.
 func (mq *ModelQuery) diffFields(original interface{}) (map[string]interface{}, error) {
@@ -653,8 +679,8 @@ func (mq *ModelQuery) diffFields(original interface{}) (map[string]interface{},
 			continue
 		}
 
-		// Skip PK columns.
-		if pkSet[col] {
+		// Skip PK and excluded columns.
+		if pkSet[col] || mq.exclude[col] {
 			continue
 		}
 
@@ -686,8 +712,8 @@ func buildPKSet(pkInfo *util.PrimaryKeyInfo) map[string]bool {
 func columnFromField(field reflect.StructField) (col string, skip bool) {
 	tag, hasTag := field.Tag.Lookup("db")
 	if !hasTag {
-		// No db tag: use field name as-is (consistent with StructToMap).
-		return field.Name, false
+		// No db tag: use the naming strategy (consistent with StructToMap).
+		return util.FieldColumn(field.Name), false
 	}
 
 	// Parse db tag: "column" or "column,pk" or "-".
@@ -701,6 +727,8 @@ func columnFromField(field reflect.StructField) (col string, skip bool) {
 
 // Delete deletes the model from the table.
 // Supports both single PK and composite PK for WHERE clause.
+// For tables registered with WithHistory, the previous row version is
+// recorded in the history table in the same transaction.
 func (mq *ModelQuery) Delete() error {
 	if mq.table == "" {
 		return errors.New("model: table name not specified")
@@ -712,24 +740,157 @@ func (mq *ModelQuery) Delete() error {
 		return errors.New("model: primary key not found")
 	}
 
-	// Create builder with transaction/query context if applicable.
+	// Builder with transaction/query context, after recording history if enabled.
+	return mq.writeWithHistory(pkCols, pkValues, func(qb *QueryBuilder) error {
+		// Build DELETE query with WHERE clause for all PK columns.
+		deleteQuery := qb.Delete(mq.table)
+
+		for i, col := range pkCols {
+			if i == 0 {
+				deleteQuery = deleteQuery.Where(Eq(col, pkValues[i]))
+			} else {
+				deleteQuery = deleteQuery.AndWhere(Eq(col, pkValues[i]))
+			}
+		}
+
+		q := deleteQuery.Build()
+		mq.setModelChange(q)
+		_, err := q.Execute()
+		return err
+	})
+}
+
+// FindByIDs loads the rows whose primary key is in ids into dest (a pointer to a
+// slice of the model type), ordered like ids. ids may be any slice; large lists
+// are split into chunks of DefaultInChunkSize to stay under placeholder limits.
+// Requires a single-column primary key.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Model(&User{}).FindByIDs([]int64{3, 1, 2}, &users)
+func (mq *ModelQuery) FindByIDs(ids interface{}, dest interface{}) error {
+	if mq.table == "" {
+		return errors.New("model: table name not specified")
+	}
+
+	pkCols, _, err := mq.getPrimaryKeys()
+	if err != nil {
+		return errors.New("model: primary key not found")
+	}
+	if len(pkCols) != 1 {
+		return errors.New("model: FindByIDs requires a single-column primary key")
+	}
+
 	qb := &QueryBuilder{
 		db:  mq.db,
 		tx:  mq.tx,
 		ctx: mq.ctx,
 	}
+	return qb.Select().From(mq.table).
+		WhereInChunked(pkCols[0], ids, DefaultInChunkSize).
+		PreserveOrder().
+		All(dest)
+}
 
-	// Build DELETE query with WHERE clause for all PK columns.
-	deleteQuery := qb.Delete(mq.table)
+// findOperators are the comparison operators accepted by Match.
+var findOperators = map[string]bool{
+	"=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true,
+	"LIKE": true, "NOT LIKE": true,
+}
 
-	for i, col := range pkCols {
-		if i == 0 {
-			deleteQuery = deleteQuery.Where(Eq(col, pkValues[i]))
-		} else {
-			deleteQuery = deleteQuery.AndWhere(Eq(col, pkValues[i]))
+// Match sets the comparison operator Find uses for column (default "=").
+// Supported operators: =, <>, !=, >, >=, <, <=, LIKE, NOT LIKE.
+// A column with an explicit operator is compared even if its field is zero.
+//
+// Example:
+//
+//	err := db.Model(&User{Name: "A%", Age: 18}).
+//	    Match("name", "LIKE").
+//	    Match("age", ">=").
+//	    Find(&users)
+//	// WHERE "age" >= ? AND "name" LIKE ?
+func (mq *ModelQuery) Match(column, op string) *ModelQuery {
+	if mq.match == nil {
+		mq.match = make(map[string]string)
+	}
+	op = strings.ToUpper(strings.TrimSpace(op))
+	if op == "!=" {
+		op = "<>"
+	}
+	mq.match[column] = op
+	return mq
+}
+
+// Find loads the rows matching the model's non-zero fields (query-by-example)
+// into dest, which is a pointer to a slice, or a pointer to a struct for the
+// first match (sql.ErrNoRows if none). Fields are compared with "=" unless
+// Match sets another operator; excluded fields are ignored. A model with no
+// non-zero fields matches every row.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Model(&User{Status: "active"}).Find(&users)
+//	// SELECT * FROM "users" WHERE "status" = ?
+func (mq *ModelQuery) Find(dest interface{}) error {
+	if mq.table == "" {
+		return errors.New("model: table name not specified")
+	}
+
+	v := reflect.ValueOf(mq.model)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	dataMap, err := util.StructToMap(mq.model)
+	if err != nil {
+		return err
+	}
+
+	cols := make([]string, 0, len(dataMap))
+	for col, val := range dataMap {
+		if mq.exclude[col] {
+			continue
+		}
+		if _, explicit := mq.match[col]; explicit || (val != nil && !reflect.ValueOf(val).IsZero()) {
+			cols = append(cols, col)
 		}
 	}
+	for col := range mq.match {
+		if _, ok := dataMap[col]; !ok {
+			return fmt.Errorf("model: Match column %q is not a field of %s", col, v.Type())
+		}
+	}
+	sort.Strings(cols)
+
+	conds := make([]Expression, 0, len(cols))
+	for _, col := range cols {
+		op := "="
+		if m, ok := mq.match[col]; ok {
+			op = m
+		}
+		if !findOperators[op] {
+			return fmt.Errorf("model: unsupported Match operator %q for column %q", op, col)
+		}
+		val := dataMap[col]
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			val = nil // compare nil pointers with IS [NOT] NULL
+		}
+		conds = append(conds, &CompareExp{Col: col, Operator: op, Value: val})
+	}
+
+	qb := &QueryBuilder{
+		db:  mq.db,
+		tx:  mq.tx,
+		ctx: mq.ctx,
+	}
+	sq := qb.Select().From(mq.table)
+	if len(conds) > 0 {
+		sq.Where(And(conds...))
+	}
 
-	_, err = deleteQuery.Execute()
-	return err
+	if d := reflect.ValueOf(dest); d.Kind() == reflect.Pointer && d.Elem().Kind() == reflect.Slice {
+		return sq.All(dest)
+	}
+	return sq.One(dest)
 }
@@ -323,6 +323,20 @@ func TestDiffFields_SomeFieldsChanged(t *testing.T) {
 	assert.NotContains(t, changed, "email") // Unchanged
 }
 
+func TestDiffFields_ExcludedFieldsSkipped(t *testing.T) {
+	db := upsertMockDB("postgres")
+	original := diffUser{ID: 1, Name: "Alice", Email: "alice@example.com", Status: "active"}
+	current := original
+	current.Name = "Alice Updated"
+	current.Status = "inactive"
+
+	mq := newTestMQ(db, &current, "users").Exclude("status")
+
+	changed, err := mq.diffFields(&original)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "Alice Updated"}, changed)
+}
+
 func TestDiffFields_NoFieldsChanged(t *testing.T) {
 	db := upsertMockDB("postgres")
 	original := diffUser{ID: 1, Name: "Alice", Email: "alice@example.com", Status: "active"}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/coregx/relica/internal/util"
+)
+
+// ============================================================================
+// Model validation
+// ============================================================================
+//
+// Model().Insert, Update, UpdateChanged and Upsert validate the model before
+// building SQL: first with the validator set by WithModelValidator, then with
+// the model's own Validate() error method, if it has one. The first failure
+// is returned and nothing is executed.
+//
+// ValidateTags is a built-in validator for the validate struct tag:
+//
+//	type User struct {
+//	    ID    int64  `db:"id"`
+//	    Name  string `db:"name" validate:"required,max=100"`
+//	    Email string `db:"email" validate:"required,email"`
+//	}
+
+// ModelValidator validates a model before Model().Insert, Update,
+// UpdateChanged and Upsert (see WithModelValidator).
+type ModelValidator func(ctx context.Context, model interface{}) error
+
+// FieldError is the validation failure of one field.
+type FieldError struct {
+	// Field is the column name of the field, or its Go name if it has no column.
+	Field string
+	// Message describes the failure, e.g. "is required".
+	Message string
+}
+
+// ValidationError is returned when a model fails validation. It lists the
+// failures of every invalid field.
+//
+// Example:
+//
+//	var verr *relica.ValidationError
+//	if errors.As(err, &verr) {
+//	    for _, f := range verr.Fields {
+//	        problems[f.Field] = f.Message
+//	    }
+//	}
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + " " + f.Message
+	}
+	return "relica: validation failed: " + strings.Join(msgs, "; ")
+}
+
+// WithModelValidator validates models with v before Model().Insert, Update,
+// UpdateChanged and Upsert. Use ValidateTags for the built-in validate tag
+// rules, or adapt a validation library.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithModelValidator(relica.ValidateTags))
+//
+//	// go-playground/validator
+//	v := validator.New()
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithModelValidator(func(ctx context.Context, model any) error {
+//	        return v.StructCtx(ctx, model)
+//	    }))
+func WithModelValidator(v ModelValidator) Option {
+	return optionFunc(func(db *DB) {
+		db.modelValidate = v
+	})
+}
+
+// emailRegex is a deliberately loose email address check.
+var emailRegex = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// ValidateTags is a ModelValidator checking the rules of the validate struct
+// tag of each exported field, separated by commas:
+//
+//   - required: the value is not the zero value
+//   - min=N, max=N: the length of a string (in characters), slice or map, or
+//     the value of a number, is at least or at most N
+//   - email: a non-empty string is an email address
+//
+// Failures are returned as a *ValidationError; an unknown rule is an error.
+func ValidateTags(_ context.Context, model interface{}) error {
+	v := reflect.ValueOf(model)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return errors.New("relica: cannot validate nil model")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("relica: cannot validate %s, expected struct", v.Kind())
+	}
+
+	var verr ValidationError
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || tag == "-" || !field.IsExported() {
+			continue
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			msg, err := checkRule(strings.TrimSpace(rule), v.Field(i))
+			if err != nil {
+				return fmt.Errorf("relica: field %s: %w", field.Name, err)
+			}
+			if msg != "" {
+				name := util.ColumnName(field)
+				if name == "-" {
+					name = field.Name
+				}
+				verr.Fields = append(verr.Fields, FieldError{Field: name, Message: msg})
+				break // one message per field
+			}
+		}
+	}
+	if len(verr.Fields) > 0 {
+		return &verr
+	}
+	return nil
+}
+
+// checkRule checks one validate tag rule against value and returns the
+// failure message, or "" if the rule holds.
+func checkRule(rule string, value reflect.Value) (string, error) {
+	name, arg, _ := strings.Cut(rule, "=")
+	for value.Kind() == reflect.Pointer && name != "required" {
+		if value.IsNil() {
+			return "", nil // optional and unset
+		}
+		value = value.Elem()
+	}
+
+	switch name {
+	case "":
+		return "", nil
+	case "required":
+		if value.IsZero() {
+			return "is required", nil
+		}
+		return "", nil
+	case "email":
+		if value.Kind() != reflect.String {
+			return "", fmt.Errorf("email rule on %s", value.Kind())
+		}
+		if s := value.String(); s != "" && !emailRegex.MatchString(s) {
+			return "must be a valid email address", nil
+		}
+		return "", nil
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s rule %q", name, rule)
+		}
+		return checkBound(name, limit, arg, value)
+	}
+	return "", fmt.Errorf("unknown validation rule %q", rule)
+}
+
+// checkBound checks a min or max rule.
+func checkBound(name string, limit float64, arg string, value reflect.Value) (string, error) {
+	var n float64
+	unit := " characters"
+	switch value.Kind() {
+	case reflect.String:
+		n = float64(utf8.RuneCountInString(value.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n, unit = float64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, unit = float64(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, unit = float64(value.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		n, unit = value.Float(), ""
+	default:
+		return "", fmt.Errorf("%s rule on %s", name, value.Kind())
+	}
+
+	switch {
+	case name == "min" && n < limit && unit == "":
+		return "must be at least " + arg, nil
+	case name == "min" && n < limit:
+		return "must have at least " + arg + unit, nil
+	case name == "max" && n > limit && unit == "":
+		return "must be at most " + arg, nil
+	case name == "max" && n > limit:
+		return "must have at most " + arg + unit, nil
+	}
+	return "", nil
+}
+
+// validateModel runs the configured model validator and the model's
+// Validate method.
+func (mq *ModelQuery) validateModel() error {
+	if mq.db.modelValidate != nil {
+		ctx := mq.ctx
+		if ctx == nil {
+			ctx = mq.db.ctx
+		}
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if err := mq.db.modelValidate(ctx, mq.model); err != nil {
+			return err
+		}
+	}
+	if v, ok := mq.model.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
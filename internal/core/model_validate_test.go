@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedMember struct {
+	ID    int64    `db:"id"`
+	Name  string   `db:"name" validate:"required,max=5"`
+	Email string   `db:"email" validate:"email"`
+	Age   int      `db:"age" validate:"min=18"`
+	Nick  *string  `db:"nick" validate:"min=2"`
+	Tags  []string `db:"-" validate:"max=2"`
+}
+
+func (validatedMember) TableName() string { return "members" }
+
+type selfValidatedMember struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func (selfValidatedMember) TableName() string { return "members" }
+
+func (m selfValidatedMember) Validate() error {
+	if m.Name == "root" {
+		return &ValidationError{Fields: []FieldError{{Field: "name", Message: "is reserved"}}}
+	}
+	return nil
+}
+
+func TestValidateTags(t *testing.T) {
+	ctx := context.Background()
+	short := "x"
+	valid := validatedMember{Name: "ann", Email: "ann@example.com", Age: 30}
+	require.NoError(t, ValidateTags(ctx, &valid))
+
+	err := ValidateTags(ctx, validatedMember{Name: "", Email: "not-an-email", Age: 12, Nick: &short, Tags: []string{"a", "b", "c"}})
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Message: "is required"},
+		{Field: "email", Message: "must be a valid email address"},
+		{Field: "age", Message: "must be at least 18"},
+		{Field: "nick", Message: "must have at least 2 characters"},
+		{Field: "Tags", Message: "must have at most 2 items"},
+	}, verr.Fields)
+	assert.Contains(t, err.Error(), "relica: validation failed: name is required; email must be")
+
+	err = ValidateTags(ctx, validatedMember{Name: "ännäbel", Age: 18})
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []FieldError{{Field: "name", Message: "must have at most 5 characters"}}, verr.Fields)
+
+	type badRule struct {
+		Name string `validate:"uuid"`
+	}
+	err = ValidateTags(ctx, badRule{})
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &verr))
+	assert.Contains(t, err.Error(), `unknown validation rule "uuid"`)
+}
+
+func TestModel_Validation(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithModelValidator(ValidateTags))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	_, err = db.ExecContext(context.Background(),
+		"CREATE TABLE members (id INTEGER PRIMARY KEY, name TEXT, email TEXT, age INTEGER, nick TEXT)")
+	require.NoError(t, err)
+	count := func() int64 {
+		n, err := db.Builder().Select().From("members").Count()
+		require.NoError(t, err)
+		return n
+	}
+
+	m := validatedMember{Name: "ann", Age: 30}
+	require.NoError(t, db.Model(&m).Insert())
+	assert.NotZero(t, m.ID)
+
+	var verr *ValidationError
+	bad := validatedMember{Name: "", Age: 30}
+	require.ErrorAs(t, db.Model(&bad).Insert(), &verr)
+	assert.Equal(t, int64(1), count(), "nothing is executed")
+
+	m.Age = 5
+	require.ErrorAs(t, db.Model(&m).Update(), &verr)
+	require.ErrorAs(t, db.Model(&m).Upsert(), &verr)
+	original := validatedMember{ID: m.ID, Name: "ann", Age: 30}
+	require.ErrorAs(t, db.Model(&m).UpdateChanged(&original), &verr)
+
+	// The Validate method runs with or without a configured validator
+	err = db.Model(&selfValidatedMember{Name: "root"}).Insert()
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "is reserved", verr.Fields[0].Message)
+
+	plain, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer plain.Close()
+	assert.Error(t, plain.Model(&selfValidatedMember{ID: 1, Name: "root"}).Update())
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// legacyCustomer maps to a legacy schema without db tags.
+type legacyCustomer struct {
+	CustomerNo int `relica:"pk"`
+	FullName   string
+	HomeCity   string
+}
+
+func (legacyCustomer) TableName() string { return "legacy_customers" }
+
+func TestSetNamingStrategy_Model(t *testing.T) {
+	SetNamingStrategy(SnakeCaseNaming)
+	t.Cleanup(func() { SetNamingStrategy(nil) })
+
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE legacy_customers (customer_no INTEGER PRIMARY KEY, full_name TEXT, home_city TEXT)`)
+	require.NoError(t, err)
+
+	c := legacyCustomer{FullName: "Ann Lee", HomeCity: "Oslo"}
+	require.NoError(t, db.Model(&c).Insert())
+	assert.Equal(t, 1, c.CustomerNo) // auto-populated via relica:"pk"
+
+	c.HomeCity = "Bergen"
+	require.NoError(t, db.Model(&c).Update())
+
+	got, err := NewRepository[legacyCustomer](db).Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, legacyCustomer{CustomerNo: 1, FullName: "Ann Lee", HomeCity: "Bergen"}, got)
+
+	var found []legacyCustomer
+	require.NoError(t, db.Model(&legacyCustomer{HomeCity: "Bergen"}).Find(&found))
+	assert.Len(t, found, 1)
+
+	require.NoError(t, db.Model(&c).Delete())
+	n, err := NewTypedQuery[legacyCustomer](db.Builder()).Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestSetNamingStrategy_ResetsScannerCache(t *testing.T) {
+	type camelRow struct {
+		CreatedBy string
+	}
+	t.Cleanup(func() { SetNamingStrategy(nil) })
+
+	SetNamingStrategy(CamelCaseNaming)
+	sql, _ := NewTypedQuery[camelRow](mockDB("postgres").Builder()).Table("rows").ToSQL()
+	assert.Equal(t, `SELECT "createdBy" FROM "rows"`, sql)
+
+	SetNamingStrategy(SnakeCaseNaming)
+	sql, _ = NewTypedQuery[camelRow](mockDB("postgres").Builder()).Table("rows").ToSQL()
+	assert.Equal(t, `SELECT "created_by" FROM "rows"`, sql)
+
+	SetNamingStrategy(FieldNameNaming)
+	sql, _ = NewTypedQuery[camelRow](mockDB("postgres").Builder()).Table("rows").ToSQL()
+	assert.Equal(t, `SELECT "CreatedBy" FROM "rows"`, sql)
+}
@@ -0,0 +1,243 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// ============================================================================
+// N+1 query detection
+// ============================================================================
+//
+// An N+1 pattern is a loop issuing the same SELECT once per item, e.g. loading
+// the author of each of 50 posts with 50 "WHERE id = ?" queries, instead of one
+// "WHERE id IN (...)" query. The detector counts the SELECTs of a query scope
+// (typically one HTTP request or job, see WithQueryScope) that have the same
+// SQL and differ in exactly one parameter, and reports a shape once when its
+// count reaches the threshold.
+
+// defaultNPlusOneThreshold is the burst size reported when WithNPlusOneDetector
+// is given a threshold below 2.
+const defaultNPlusOneThreshold = 10
+
+// suggestionNPlusOne is the suggestion type of N+1 warnings in the optimizer store.
+const suggestionNPlusOne = "n_plus_one"
+
+// NPlusOneWarning describes a burst of same-shape queries within one scope.
+type NPlusOneWarning struct {
+	// SQL is the repeated statement
+	SQL string
+	// Count is the number of queries seen when the pattern was reported
+	Count int
+	// ParamIndex is the zero-based index of the parameter that varies
+	ParamIndex int
+	// Table is the table read by the statement ("" if not recognized)
+	Table string
+	// Column is the column compared with the varying parameter ("" if not recognized)
+	Column string
+	// Tag is the query tag set with Tag ("" for untagged queries)
+	Tag string
+}
+
+// Suggestion returns how to replace the repeated queries.
+func (w NPlusOneWarning) Suggestion() string {
+	if w.Column == "" {
+		return "batch the lookups into one query with an IN condition, or load the related rows up front"
+	}
+	return fmt.Sprintf("load all rows in one query with %s IN (...), e.g. Where(relica.In(%q, values...))", w.Column, w.Column)
+}
+
+// String returns a formatted string representation of the warning.
+func (w NPlusOneWarning) String() string {
+	return fmt.Sprintf("warning: N+1 query pattern: %d queries differing only in parameter %d: %s\n  Fix: %s",
+		w.Count, w.ParamIndex+1, w.SQL, w.Suggestion())
+}
+
+// NPlusOneHandler receives N+1 warnings. ctx is the context of the query that
+// reached the threshold.
+type NPlusOneHandler func(ctx context.Context, w NPlusOneWarning)
+
+// WithNPlusOneDetector reports SELECTs repeated threshold times within one query
+// scope with only one parameter changing. A threshold below 2 defaults to 10.
+// With a nil handler, warnings are written to stderr like optimizer suggestions.
+//
+// Only queries run with a context from WithQueryScope are tracked.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithNPlusOneDetector(10, func(ctx context.Context, w relica.NPlusOneWarning) {
+//	        slog.WarnContext(ctx, "N+1 query", "sql", w.SQL, "count", w.Count, "fix", w.Suggestion())
+//	    }))
+func WithNPlusOneDetector(threshold int, handler NPlusOneHandler) Option {
+	return optionFunc(func(db *DB) {
+		if threshold < 2 {
+			threshold = defaultNPlusOneThreshold
+		}
+		db.nplusone = &nplusOneDetector{threshold: threshold, handler: handler}
+	})
+}
+
+// queryScopeKey is the context key of the current query scope.
+type queryScopeKey struct{}
+
+// WithQueryScope returns a context starting a new query scope, the unit of work
+// in which WithNPlusOneDetector counts repeated queries. Call it once per
+// request or job, e.g. in HTTP middleware, and run the queries with the
+// returned context.
+//
+// Example:
+//
+//	func middleware(next http.Handler) http.Handler {
+//	    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	        next.ServeHTTP(w, r.WithContext(relica.WithQueryScope(r.Context())))
+//	    })
+//	}
+func WithQueryScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryScopeKey{}, &queryScope{shapes: make(map[string]*queryShape)})
+}
+
+// queryScope holds the queries counted in one scope.
+type queryScope struct {
+	mu     sync.Mutex
+	shapes map[string]*queryShape
+}
+
+// queryShape tracks the executions of one SQL statement in a scope.
+type queryShape struct {
+	first    []interface{} // parameters of the first execution
+	param    int           // index of the varying parameter, -1 until known
+	count    int
+	reported bool
+}
+
+// nplusOneDetector is the N+1 configuration of a DB.
+type nplusOneDetector struct {
+	threshold int
+	handler   NPlusOneHandler
+}
+
+// observe counts a successful query of the scope in ctx and returns a warning
+// when its shape reaches the threshold, once per shape.
+func (d *nplusOneDetector) observe(ctx context.Context, event QueryEvent) (NPlusOneWarning, bool) {
+	scope, ok := ctx.Value(queryScopeKey{}).(*queryScope)
+	if !ok || event.Operation != opSelect || len(event.Args) == 0 {
+		return NPlusOneWarning{}, false
+	}
+
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	shape, seen := scope.shapes[event.SQL]
+	if !seen {
+		scope.shapes[event.SQL] = &queryShape{first: event.Args, param: -1, count: 1}
+		return NPlusOneWarning{}, false
+	}
+	idx := singleDifference(shape.first, event.Args)
+	if idx < 0 || (shape.param >= 0 && idx != shape.param) {
+		return NPlusOneWarning{}, false
+	}
+	shape.param = idx
+	shape.count++
+	if shape.count < d.threshold || shape.reported {
+		return NPlusOneWarning{}, false
+	}
+	shape.reported = true
+
+	return NPlusOneWarning{
+		SQL:        event.SQL,
+		Count:      shape.count,
+		ParamIndex: idx,
+		Table:      firstTable(event.SQL),
+		Column:     placeholderColumn(event.SQL, idx),
+		Tag:        event.Tag,
+	}, true
+}
+
+// reportNPlusOne passes w to the N+1 handler, or writes it to stderr, unless
+// the optimizer store has it dismissed.
+func (db *DB) reportNPlusOne(ctx context.Context, w NPlusOneWarning) {
+	if db.optStore != nil {
+		message := fmt.Sprintf("N+1 query pattern: queries differing only in parameter %d", w.ParamIndex+1)
+		if !db.optStore.recordSuggestion(w.SQL, suggestionNPlusOne, "warning", message, w.Suggestion()) {
+			return
+		}
+	}
+	if db.nplusone.handler != nil {
+		db.nplusone.handler(ctx, w)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[RELICA OPTIMIZER] %s\n", w)
+}
+
+// singleDifference returns the index of the only parameter in which a and b
+// differ, or -1 if they differ in none, several, or their number.
+func singleDifference(a, b []interface{}) int {
+	if len(a) != len(b) {
+		return -1
+	}
+	idx := -1
+	for i := range a {
+		if reflect.DeepEqual(a[i], b[i]) {
+			continue
+		}
+		if idx >= 0 {
+			return -1
+		}
+		idx = i
+	}
+	return idx
+}
+
+var (
+	// fromTableRegex captures the first table of a FROM clause.
+	fromTableRegex = regexp.MustCompile("(?i)\\bFROM\\s+[\"`\\[]?([A-Za-z_][\\w]*)")
+
+	// comparedColumnRegex captures the column compared with a placeholder at the end of a prefix.
+	comparedColumnRegex = regexp.MustCompile("([A-Za-z_][\\w]*)[\"`\\]]?\\s*=\\s*$")
+)
+
+// firstTable returns the first table read by a SELECT.
+func firstTable(query string) string {
+	if m := fromTableRegex.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// placeholderColumn returns the column compared by equality with the
+// parameter at index (zero-based), for ? and $n placeholders, or "".
+func placeholderColumn(query string, index int) string {
+	want := "$" + strconv.Itoa(index+1)
+	n := 0
+	inString := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inString = !inString
+		case inString:
+		case c == '?':
+			if n == index {
+				return comparedColumn(query[:i])
+			}
+			n++
+		case c == '$' && len(query) >= i+len(want) && query[i:i+len(want)] == want &&
+			(i+len(want) == len(query) || query[i+len(want)] < '0' || query[i+len(want)] > '9'):
+			return comparedColumn(query[:i])
+		}
+	}
+	return ""
+}
+
+// comparedColumn returns the column of a "col =" prefix.
+func comparedColumn(prefix string) string {
+	if m := comparedColumnRegex.FindStringSubmatch(prefix); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNPlusOneDetector(t *testing.T) {
+	var warnings []NPlusOneWarning
+	db, err := Open("sqlite", ":memory:", WithNPlusOneDetector(3, func(_ context.Context, w NPlusOneWarning) {
+		warnings = append(warnings, w)
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT, active INTEGER)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO authors (id, name, active) VALUES (1, 'a', 1), (2, 'b', 1), (3, 'c', 1), (4, 'd', 1)")
+	require.NoError(t, err)
+
+	load := func(ctx context.Context, id int) {
+		var a struct {
+			ID   int    `db:"id"`
+			Name string `db:"name"`
+		}
+		err := db.Builder().WithContext(ctx).Select("id", "name").From("authors").
+			Where(Eq("active", 1)).AndWhere(Eq("id", id)).Tag("authors.load").One(&a)
+		require.NoError(t, err)
+	}
+
+	for id := 1; id <= 4; id++ {
+		load(ctx, id)
+	}
+	assert.Empty(t, warnings, "queries outside a scope are not tracked")
+
+	scoped := WithQueryScope(ctx)
+	load(scoped, 1)
+	load(scoped, 1)
+	load(scoped, 2)
+	assert.Empty(t, warnings, "identical queries do not count")
+	load(scoped, 3)
+	load(scoped, 4)
+	require.Len(t, warnings, 1, "a shape is reported once")
+	w := warnings[0]
+	assert.Equal(t, 3, w.Count)
+	assert.Equal(t, 1, w.ParamIndex)
+	assert.Equal(t, "authors", w.Table)
+	assert.Equal(t, "id", w.Column)
+	assert.Equal(t, "authors.load", w.Tag)
+	assert.Contains(t, w.String(), `Where(relica.In("id", values...))`)
+
+	other := WithQueryScope(ctx)
+	for id := 1; id <= 2; id++ {
+		load(other, id)
+	}
+	assert.Len(t, warnings, 1, "each scope counts on its own")
+}
+
+func TestSingleDifference(t *testing.T) {
+	assert.Equal(t, 1, singleDifference([]interface{}{1, "a"}, []interface{}{1, "b"}))
+	assert.Equal(t, -1, singleDifference([]interface{}{1, "a"}, []interface{}{1, "a"}))
+	assert.Equal(t, -1, singleDifference([]interface{}{1, "a"}, []interface{}{2, "b"}))
+	assert.Equal(t, -1, singleDifference([]interface{}{1}, []interface{}{1, 2}))
+}
+
+func TestPlaceholderColumn(t *testing.T) {
+	assert.Equal(t, "user_id", placeholderColumn(`SELECT * FROM "posts" WHERE "status" = $1 AND "user_id" = $2`, 1))
+	assert.Equal(t, "status", placeholderColumn(`SELECT * FROM "posts" WHERE "status" = $1 AND "user_id" = $12`, 0))
+	assert.Equal(t, "id", placeholderColumn("SELECT * FROM `users` WHERE name <> '?' AND `id` = ?", 0))
+	assert.Equal(t, "", placeholderColumn("SELECT * FROM users WHERE id IN (?, ?)", 1))
+	assert.Equal(t, "users", firstTable(`SELECT "id" FROM "users" WHERE "id" = $1`))
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+// ============================================================================
+// Optional results
+// ============================================================================
+//
+// One treats "no rows" as an error (ErrNotFound). OneOrNil reports it as
+// found == false instead, for lookups where a missing row is an expected
+// outcome.
+//
+// All and Column append the rows to the destination slice. When no rows
+// match, a nil destination stays nil, which encodes as JSON null; with
+// WithEmptySlices it becomes an empty slice, which encodes as [].
+
+// WithEmptySlices makes All and Column set a nil destination slice to an
+// empty slice when no rows match, so results encode as [] rather than null.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithEmptySlices())
+func WithEmptySlices() Option {
+	return optionFunc(func(db *DB) {
+		db.emptySlices = true
+	})
+}
+
+// OneOrNil fetches a single row into dest like One, but reports a missing row
+// as found == false with a nil error instead of ErrNotFound. dest is left
+// unchanged when no row matches.
+//
+// Example:
+//
+//	var user User
+//	found, err := db.NewQuery("SELECT * FROM users WHERE email = ?", email).OneOrNil(&user)
+func (q *Query) OneOrNil(dest interface{}) (bool, error) {
+	return found(q.One(dest))
+}
+
+// OneOrNil scans a single row into dest like One, but reports a missing row
+// as found == false with a nil error instead of ErrNotFound.
+//
+// Example:
+//
+//	var user User
+//	found, err := db.Select().From("users").Where("email = ?", email).OneOrNil(&user)
+//	if err != nil {
+//	    return err
+//	}
+//	if !found {
+//	    // create the user
+//	}
+func (sq *SelectQuery) OneOrNil(dest interface{}) (bool, error) {
+	return found(sq.One(dest))
+}
+
+// OneOrNil returns the first matching row, or nil if there is none.
+func (q *TypedQuery[T]) OneOrNil(ctx context.Context) (*T, error) {
+	row, err := q.One(ctx)
+	if ok, err := found(err); !ok {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// found converts the error of a single-row fetch into (found, err).
+func found(err error) (bool, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// fillEmptySlice sets the slice dest points to to an empty slice if it is
+// nil and the DB was opened with WithEmptySlices.
+func (db *DB) fillEmptySlice(dest interface{}) {
+	if db == nil || !db.emptySlices {
+		return
+	}
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return
+	}
+	if s := v.Elem(); s.Kind() == reflect.Slice && s.IsNil() && s.CanSet() {
+		s.Set(reflect.MakeSlice(s.Type(), 0, 0))
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOneOrNil(t *testing.T) {
+	db := setupTypedTestDB(t)
+
+	var author typedAuthor
+	found, err := db.Builder().Select().From("authors").Where("name = ?", "bob").OneOrNil(&author)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(2), author.ID)
+
+	var missing typedAuthor
+	found, err = db.Builder().Select().From("authors").Where("name = ?", "zed").OneOrNil(&missing)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Zero(t, missing)
+
+	found, err = db.NewQuery("SELECT id, name FROM authors WHERE id = ?", 3).OneOrNil(&author)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "cid", author.Name)
+
+	found, err = db.NewQuery("SELECT * FROM no_such_table").OneOrNil(&author)
+	assert.Error(t, err, "other errors are still returned")
+	assert.False(t, found)
+}
+
+func TestTypedQuery_OneOrNil(t *testing.T) {
+	db := setupTypedTestDB(t)
+	ctx := context.Background()
+
+	author, err := NewTypedQuery[typedAuthor](db.Builder()).Where("name = ?", "ann").OneOrNil(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, author)
+	assert.Equal(t, int64(1), author.ID)
+
+	author, err = NewTypedQuery[typedAuthor](db.Builder()).Where("name = ?", "zed").OneOrNil(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, author)
+}
+
+func TestWithEmptySlices(t *testing.T) {
+	ctx := context.Background()
+	for _, empty := range []bool{false, true} {
+		var opts []Option
+		if empty {
+			opts = append(opts, WithEmptySlices())
+		}
+		db, err := Open("sqlite", ":memory:", opts...)
+		require.NoError(t, err)
+		db.sqlDB.SetMaxOpenConns(1)
+		_, err = db.ExecContext(ctx, "CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN)")
+		require.NoError(t, err)
+
+		var authors []typedAuthor
+		require.NoError(t, db.Builder().Select().From("authors").All(&authors))
+		var ids []int64
+		require.NoError(t, db.Builder().Select("id").From("authors").Column(&ids))
+		typed, err := NewTypedQuery[typedAuthor](db.Builder()).All(ctx)
+		require.NoError(t, err)
+
+		out, err := json.Marshal([]interface{}{authors, ids, typed})
+		require.NoError(t, err)
+		if empty {
+			assert.Equal(t, "[[],[],[]]", string(out))
+		} else {
+			assert.Equal(t, "[null,null,null]", string(out))
+		}
+		require.NoError(t, db.Close())
+	}
+}
@@ -0,0 +1,614 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Optimizer store
+// ============================================================================
+//
+// The optimizer store aggregates query fingerprints, call counts, latencies and
+// the suggestions of the optimizer and the N+1 detector in memory, and merges
+// them into two relica-managed tables on FlushOptimizerStore (and Close). The
+// history survives restarts: suggestions are ranked by the total time of their
+// query, and dismissed suggestions are not reported again.
+
+// DefaultOptimizerTable is the default name prefix of the optimizer store
+// tables, <prefix>_queries and <prefix>_suggestions.
+const DefaultOptimizerTable = "relica_optimizer"
+
+// SuggestionStatus is the review state of a stored suggestion.
+type SuggestionStatus string
+
+const (
+	// SuggestionOpen is a suggestion nobody has reviewed yet
+	SuggestionOpen SuggestionStatus = "open"
+
+	// SuggestionAccepted is a suggestion that was applied
+	SuggestionAccepted SuggestionStatus = "accepted"
+
+	// SuggestionDismissed is a suggestion that is no longer reported
+	SuggestionDismissed SuggestionStatus = "dismissed"
+)
+
+// QueryProfile is the aggregated history of one query fingerprint.
+type QueryProfile struct {
+	// Fingerprint identifies the normalized statement
+	Fingerprint string
+	// SQL is the normalized statement, literals replaced by ?
+	SQL string
+	// Calls is the number of executions
+	Calls int64
+	// Failures is the number of executions that returned an error
+	Failures int64
+	// TotalTime is the summed execution time
+	TotalTime time.Duration
+	// MaxTime is the slowest execution
+	MaxTime time.Duration
+	// FirstSeen and LastSeen bound the recorded executions
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// MeanTime returns the average execution time.
+func (p QueryProfile) MeanTime() time.Duration {
+	if p.Calls == 0 {
+		return 0
+	}
+	return p.TotalTime / time.Duration(p.Calls)
+}
+
+// StoredSuggestion is a suggestion recorded by the optimizer store.
+type StoredSuggestion struct {
+	// ID identifies the suggestion across restarts (see DismissSuggestion)
+	ID string
+	// Fingerprint is the fingerprint of the query the suggestion is about
+	Fingerprint string
+	// Type categorizes the suggestion (e.g. index_missing, n_plus_one)
+	Type string
+	// Severity is info, warning or error
+	Severity string
+	// Message is the latest description of the issue
+	Message string
+	// Fix is the SQL or change that addresses the issue ("" if none)
+	Fix string
+	// Status is the review state
+	Status SuggestionStatus
+	// Seen is the number of times the suggestion was made
+	Seen int64
+	// Impact is the total execution time of the query, used for ranking
+	Impact time.Duration
+	// FirstSeen and LastSeen bound the times the suggestion was made
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// WithOptimizerStore records query fingerprints, latencies and suggestions and
+// persists them in the tables <table>_queries and <table>_suggestions
+// (DefaultOptimizerTable if table is ""). Create the tables with
+// CreateOptimizerStore, and call FlushOptimizerStore periodically; Close
+// flushes what is left.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithOptimizerStore(""))
+//	if err := db.CreateOptimizerStore(ctx); err != nil {
+//	    return err
+//	}
+func WithOptimizerStore(table string) Option {
+	return optionFunc(func(db *DB) {
+		if table == "" {
+			table = DefaultOptimizerTable
+		}
+		db.optStore = newOptimizerStore(table)
+	})
+}
+
+// optimizerStore buffers the observations made since the last flush.
+type optimizerStore struct {
+	queriesTable     string
+	suggestionsTable string
+
+	mu          sync.Mutex
+	queries     map[string]*queryDelta
+	suggestions map[string]*suggestionDelta
+	dismissed   map[string]bool
+}
+
+// queryDelta is the activity of a fingerprint since the last flush.
+type queryDelta struct {
+	sql       string
+	calls     int64
+	failures  int64
+	total     time.Duration
+	max       time.Duration
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// suggestionDelta is a suggestion made since the last flush.
+type suggestionDelta struct {
+	fingerprint, kind, severity, message, fix string
+
+	seen      int64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+func newOptimizerStore(table string) *optimizerStore {
+	return &optimizerStore{
+		queriesTable:     table + "_queries",
+		suggestionsTable: table + "_suggestions",
+		queries:          make(map[string]*queryDelta),
+		suggestions:      make(map[string]*suggestionDelta),
+		dismissed:        make(map[string]bool),
+	}
+}
+
+// recordQuery counts an executed statement.
+func (s *optimizerStore) recordQuery(event QueryEvent) {
+	if strings.Contains(event.SQL, s.queriesTable) || strings.Contains(event.SQL, s.suggestionsTable) {
+		return // the store's own statements
+	}
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fp, normalized := queryFingerprint(event.SQL)
+	d, ok := s.queries[fp]
+	if !ok {
+		d = &queryDelta{sql: normalized, firstSeen: now}
+		s.queries[fp] = d
+	}
+	d.calls++
+	if event.Error != nil {
+		d.failures++
+	}
+	d.total += event.Duration
+	d.max = max(d.max, event.Duration)
+	d.lastSeen = now
+}
+
+// recordSuggestion records a suggestion about query and reports whether it
+// should be shown, i.e. has not been dismissed.
+func (s *optimizerStore) recordSuggestion(query, kind, severity, message, fix string) bool {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fp, _ := queryFingerprint(query)
+	id := suggestionID(fp, kind, fix)
+	if s.dismissed[id] {
+		return false
+	}
+	d, ok := s.suggestions[id]
+	if !ok {
+		d = &suggestionDelta{fingerprint: fp, kind: kind, firstSeen: now}
+		s.suggestions[id] = d
+	}
+	d.severity, d.message, d.fix = severity, message, fix
+	d.seen++
+	d.lastSeen = now
+	return true
+}
+
+// take removes and returns the buffered observations.
+func (s *optimizerStore) take() (map[string]*queryDelta, map[string]*suggestionDelta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queries, suggestions := s.queries, s.suggestions
+	s.queries = make(map[string]*queryDelta)
+	s.suggestions = make(map[string]*suggestionDelta)
+	return queries, suggestions
+}
+
+// restore puts back observations whose flush failed.
+func (s *optimizerStore) restore(queries map[string]*queryDelta, suggestions map[string]*suggestionDelta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for fp, d := range queries {
+		if cur, ok := s.queries[fp]; ok {
+			d.calls += cur.calls
+			d.failures += cur.failures
+			d.total += cur.total
+			d.max = max(d.max, cur.max)
+			d.lastSeen = cur.lastSeen
+		}
+		s.queries[fp] = d
+	}
+	for id, d := range suggestions {
+		if cur, ok := s.suggestions[id]; ok {
+			d.seen += cur.seen
+			d.severity, d.message = cur.severity, cur.message
+			d.lastSeen = cur.lastSeen
+		}
+		s.suggestions[id] = d
+	}
+}
+
+// CreateOptimizerStore creates the optimizer store tables if they do not exist
+// and loads the dismissed suggestions, so they stay silent after a restart.
+func (db *DB) CreateOptimizerStore(ctx context.Context) error {
+	s := db.optStore
+	if s == nil {
+		return ErrNoOptimizerStore
+	}
+	d := db.dialect
+	ts := "DATETIME"
+	switch d.(type) {
+	case *dialects.PostgresDialect:
+		ts = "TIMESTAMPTZ"
+	case *dialects.MySQLDialect:
+		ts = "DATETIME(6)"
+	}
+	q := d.QuoteIdentifier
+
+	ddl := []string{
+		"CREATE TABLE IF NOT EXISTS " + q(s.queriesTable) + " (" +
+			q("fingerprint") + " VARCHAR(32) PRIMARY KEY, " +
+			q("statement") + " TEXT NOT NULL, " +
+			q("calls") + " BIGINT NOT NULL, " +
+			q("failures") + " BIGINT NOT NULL, " +
+			q("total_us") + " BIGINT NOT NULL, " +
+			q("max_us") + " BIGINT NOT NULL, " +
+			q("first_seen") + " " + ts + " NOT NULL, " +
+			q("last_seen") + " " + ts + " NOT NULL)",
+		"CREATE TABLE IF NOT EXISTS " + q(s.suggestionsTable) + " (" +
+			q("id") + " VARCHAR(32) PRIMARY KEY, " +
+			q("fingerprint") + " VARCHAR(32) NOT NULL, " +
+			q("kind") + " VARCHAR(64) NOT NULL, " +
+			q("severity") + " VARCHAR(16) NOT NULL, " +
+			q("message") + " TEXT NOT NULL, " +
+			q("fix") + " TEXT NOT NULL, " +
+			q("status") + " VARCHAR(16) NOT NULL, " +
+			q("seen") + " BIGINT NOT NULL, " +
+			q("first_seen") + " " + ts + " NOT NULL, " +
+			q("last_seen") + " " + ts + " NOT NULL)",
+	}
+	for _, stmt := range ddl {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("relica: create optimizer store: %w", err)
+		}
+	}
+	return db.loadDismissed(ctx)
+}
+
+// loadDismissed reads the IDs of the dismissed suggestions.
+func (db *DB) loadDismissed(ctx context.Context) error {
+	var ids []string
+	err := db.Builder().WithContext(ctx).Select("id").From(db.optStore.suggestionsTable).
+		Where(Eq("status", string(SuggestionDismissed))).
+		Column(&ids)
+	if err != nil {
+		return fmt.Errorf("relica: load dismissed suggestions: %w", err)
+	}
+	s := db.optStore
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dismissed = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		s.dismissed[id] = true
+	}
+	return nil
+}
+
+// FlushOptimizerStore merges the observations made since the last flush into
+// the optimizer store tables. If the flush fails, they are kept for the next one.
+//
+// Example:
+//
+//	go func() {
+//	    for range time.Tick(time.Minute) {
+//	        _ = db.FlushOptimizerStore(ctx)
+//	    }
+//	}()
+func (db *DB) FlushOptimizerStore(ctx context.Context) error {
+	s := db.optStore
+	if s == nil {
+		return ErrNoOptimizerStore
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	queries, suggestions := s.take()
+	if len(queries) == 0 && len(suggestions) == 0 {
+		return nil
+	}
+
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		for _, fp := range sortedKeys(queries) {
+			if err := s.flushQuery(tx, fp, queries[fp]); err != nil {
+				return err
+			}
+		}
+		for _, id := range sortedKeys(suggestions) {
+			if err := s.flushSuggestion(tx, id, suggestions[id]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.restore(queries, suggestions)
+		return fmt.Errorf("relica: flush optimizer store: %w", err)
+	}
+	return nil
+}
+
+func (s *optimizerStore) flushQuery(tx *Tx, fp string, d *queryDelta) error {
+	q := tx.builder.db.dialect.QuoteIdentifier
+	maxUs := d.max.Microseconds()
+	res, err := tx.Builder().Update(s.queriesTable).
+		Set(map[string]interface{}{
+			"calls":     NewExp(q("calls")+" + ?", d.calls),
+			"failures":  NewExp(q("failures")+" + ?", d.failures),
+			"total_us":  NewExp(q("total_us")+" + ?", d.total.Microseconds()),
+			"max_us":    NewExp("CASE WHEN "+q("max_us")+" < ? THEN ? ELSE "+q("max_us")+" END", maxUs, maxUs),
+			"last_seen": d.lastSeen,
+		}).
+		Where(Eq("fingerprint", fp)).
+		Build().Execute()
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	_, err = tx.Builder().Insert(s.queriesTable, map[string]interface{}{
+		"fingerprint": fp,
+		"statement":   d.sql,
+		"calls":       d.calls,
+		"failures":    d.failures,
+		"total_us":    d.total.Microseconds(),
+		"max_us":      maxUs,
+		"first_seen":  d.firstSeen,
+		"last_seen":   d.lastSeen,
+	}).Execute()
+	return err
+}
+
+func (s *optimizerStore) flushSuggestion(tx *Tx, id string, d *suggestionDelta) error {
+	q := tx.builder.db.dialect.QuoteIdentifier
+	res, err := tx.Builder().Update(s.suggestionsTable).
+		Set(map[string]interface{}{
+			"seen":      NewExp(q("seen")+" + ?", d.seen),
+			"severity":  d.severity,
+			"message":   d.message,
+			"last_seen": d.lastSeen,
+		}).
+		Where(Eq("id", id)).
+		Build().Execute()
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	_, err = tx.Builder().Insert(s.suggestionsTable, map[string]interface{}{
+		"id":          id,
+		"fingerprint": d.fingerprint,
+		"kind":        d.kind,
+		"severity":    d.severity,
+		"message":     d.message,
+		"fix":         d.fix,
+		"status":      string(SuggestionOpen),
+		"seen":        d.seen,
+		"first_seen":  d.firstSeen,
+		"last_seen":   d.lastSeen,
+	}).Execute()
+	return err
+}
+
+// queryProfileRow is a row of the queries table.
+type queryProfileRow struct {
+	Fingerprint string    `db:"fingerprint"`
+	Statement   string    `db:"statement"`
+	Calls       int64     `db:"calls"`
+	Failures    int64     `db:"failures"`
+	TotalUs     int64     `db:"total_us"`
+	MaxUs       int64     `db:"max_us"`
+	FirstSeen   time.Time `db:"first_seen"`
+	LastSeen    time.Time `db:"last_seen"`
+}
+
+// QueryProfiles returns the persisted query history, the queries with the
+// highest total time first. limit <= 0 returns all of them. Observations not
+// flushed yet are not included.
+func (db *DB) QueryProfiles(ctx context.Context, limit int) ([]QueryProfile, error) {
+	if db.optStore == nil {
+		return nil, ErrNoOptimizerStore
+	}
+	sq := db.Builder().WithContext(ctx).Select().From(db.optStore.queriesTable).
+		OrderBy("total_us DESC", "fingerprint")
+	if limit > 0 {
+		sq = sq.Limit(int64(limit))
+	}
+	var rows []queryProfileRow
+	if err := sq.All(&rows); err != nil {
+		return nil, fmt.Errorf("relica: query profiles: %w", err)
+	}
+	profiles := make([]QueryProfile, len(rows))
+	for i, r := range rows {
+		profiles[i] = QueryProfile{
+			Fingerprint: r.Fingerprint,
+			SQL:         r.Statement,
+			Calls:       r.Calls,
+			Failures:    r.Failures,
+			TotalTime:   time.Duration(r.TotalUs) * time.Microsecond,
+			MaxTime:     time.Duration(r.MaxUs) * time.Microsecond,
+			FirstSeen:   r.FirstSeen,
+			LastSeen:    r.LastSeen,
+		}
+	}
+	return profiles, nil
+}
+
+// suggestionRow is a row of the suggestions table.
+type suggestionRow struct {
+	ID          string    `db:"id"`
+	Fingerprint string    `db:"fingerprint"`
+	Kind        string    `db:"kind"`
+	Severity    string    `db:"severity"`
+	Message     string    `db:"message"`
+	Fix         string    `db:"fix"`
+	Status      string    `db:"status"`
+	Seen        int64     `db:"seen"`
+	FirstSeen   time.Time `db:"first_seen"`
+	LastSeen    time.Time `db:"last_seen"`
+}
+
+// OptimizerSuggestions returns the persisted suggestions with the given status
+// ("" for all), ranked by the total time of their query, so the fixes that
+// save the most time come first.
+func (db *DB) OptimizerSuggestions(ctx context.Context, status SuggestionStatus) ([]StoredSuggestion, error) {
+	s := db.optStore
+	if s == nil {
+		return nil, ErrNoOptimizerStore
+	}
+	sq := db.Builder().WithContext(ctx).Select().From(s.suggestionsTable)
+	if status != "" {
+		sq = sq.Where(Eq("status", string(status)))
+	}
+	var rows []suggestionRow
+	if err := sq.All(&rows); err != nil {
+		return nil, fmt.Errorf("relica: optimizer suggestions: %w", err)
+	}
+
+	fingerprints := make([]interface{}, 0, len(rows))
+	for _, r := range rows {
+		fingerprints = append(fingerprints, r.Fingerprint)
+	}
+	impact := make(map[string]time.Duration, len(rows))
+	if len(fingerprints) > 0 {
+		var profiles []queryProfileRow
+		err := db.Builder().WithContext(ctx).Select("fingerprint", "total_us").From(s.queriesTable).
+			Where(In("fingerprint", fingerprints...)).
+			All(&profiles)
+		if err != nil {
+			return nil, fmt.Errorf("relica: optimizer suggestions: %w", err)
+		}
+		for _, p := range profiles {
+			impact[p.Fingerprint] = time.Duration(p.TotalUs) * time.Microsecond
+		}
+	}
+
+	suggestions := make([]StoredSuggestion, len(rows))
+	for i, r := range rows {
+		suggestions[i] = StoredSuggestion{
+			ID:          r.ID,
+			Fingerprint: r.Fingerprint,
+			Type:        r.Kind,
+			Severity:    r.Severity,
+			Message:     r.Message,
+			Fix:         r.Fix,
+			Status:      SuggestionStatus(r.Status),
+			Seen:        r.Seen,
+			Impact:      impact[r.Fingerprint],
+			FirstSeen:   r.FirstSeen,
+			LastSeen:    r.LastSeen,
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Impact != b.Impact {
+			return a.Impact > b.Impact
+		}
+		if a.Seen != b.Seen {
+			return a.Seen > b.Seen
+		}
+		return a.ID < b.ID
+	})
+	return suggestions, nil
+}
+
+// DismissSuggestion marks a stored suggestion as dismissed: it is no longer
+// reported, here and after restarts.
+func (db *DB) DismissSuggestion(ctx context.Context, id string) error {
+	return db.setSuggestionStatus(ctx, id, SuggestionDismissed)
+}
+
+// AcceptSuggestion marks a stored suggestion as applied. Accepted suggestions
+// are still reported and counted, which shows when a fix did not help.
+func (db *DB) AcceptSuggestion(ctx context.Context, id string) error {
+	return db.setSuggestionStatus(ctx, id, SuggestionAccepted)
+}
+
+func (db *DB) setSuggestionStatus(ctx context.Context, id string, status SuggestionStatus) error {
+	s := db.optStore
+	if s == nil {
+		return ErrNoOptimizerStore
+	}
+	res, err := db.Builder().WithContext(ctx).Update(s.suggestionsTable).
+		Set(map[string]interface{}{"status": string(status)}).
+		Where(Eq("id", id)).
+		Build().Execute()
+	if err != nil {
+		return fmt.Errorf("relica: update suggestion %s: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("relica: suggestion %s not found", id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status == SuggestionDismissed {
+		s.dismissed[id] = true
+	} else {
+		delete(s.dismissed, id)
+	}
+	return nil
+}
+
+// recordOptimizerSuggestion records a suggestion of the optimizer about query
+// and reports whether it should be shown. Structs with string fields Type,
+// Severity, Message and SQL (such as the optimizer's Suggestion) are stored
+// field by field, other values by their text.
+func (db *DB) recordOptimizerSuggestion(query string, suggestion interface{}) bool {
+	if db.optStore == nil {
+		return true
+	}
+	v := reflect.Indirect(reflect.ValueOf(suggestion))
+	field := func(name string) string {
+		if v.Kind() != reflect.Struct {
+			return ""
+		}
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+		return ""
+	}
+	kind, message := field("Type"), field("Message")
+	if kind == "" || message == "" {
+		kind, message = "unknown", fmt.Sprint(suggestion)
+	}
+	return db.optStore.recordSuggestion(query, kind, field("Severity"), message, field("SQL"))
+}
+
+// suggestionID identifies a suggestion by its query, type and fix, so it
+// keeps its ID when its message changes (e.g. an updated latency).
+func suggestionID(fingerprint, kind, fix string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(fingerprint + "\x00" + kind + "\x00" + fix))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// sortedKeys returns the keys of m in order, for deterministic flushes.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storeSuggestion mimics the optimizer's Suggestion.
+type storeSuggestion struct {
+	Type     string
+	Message  string
+	Severity string
+	SQL      string
+}
+
+func TestOptimizerStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	ctx := context.Background()
+	var warnings []NPlusOneWarning
+	open := func() *DB {
+		db, err := Open("sqlite", path, WithOptimizerStore(""),
+			WithNPlusOneDetector(2, func(_ context.Context, w NPlusOneWarning) { warnings = append(warnings, w) }))
+		require.NoError(t, err)
+		db.sqlDB.SetMaxOpenConns(1)
+		require.NoError(t, db.CreateOptimizerStore(ctx))
+		return db
+	}
+	loadTwice := func(db *DB) {
+		scoped := WithQueryScope(ctx)
+		for id := 1; id <= 2; id++ {
+			var names []string
+			require.NoError(t, db.Builder().WithContext(scoped).Select("name").From("items").Where(Eq("id", id)).Column(&names))
+		}
+	}
+
+	db := open()
+	_, err := db.ExecContext(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	loadTwice(db)
+	require.Len(t, warnings, 1)
+	assert.True(t, db.recordOptimizerSuggestion(db.Builder().Select("name").From("items").Where(Eq("id", 1)).Build().sql, storeSuggestion{
+		Type: "index_missing", Message: "Index recommended", Severity: "warning", SQL: "CREATE INDEX idx_items_name ON items(name);",
+	}))
+	require.NoError(t, db.Close(), "Close flushes the store")
+
+	db = open()
+	defer db.Close()
+	profiles, err := db.QueryProfiles(ctx, 0)
+	require.NoError(t, err)
+	var items *QueryProfile
+	for i := range profiles {
+		if profiles[i].SQL == `select "name" from "items" where "id" = ?` {
+			items = &profiles[i]
+		}
+	}
+	require.NotNil(t, items, "profiles: %+v", profiles)
+	assert.Equal(t, int64(2), items.Calls)
+	assert.Greater(t, items.TotalTime, time.Duration(0))
+	assert.GreaterOrEqual(t, items.TotalTime, items.MaxTime)
+
+	suggestions, err := db.OptimizerSuggestions(ctx, SuggestionOpen)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+	kinds := map[string]StoredSuggestion{}
+	for _, s := range suggestions {
+		kinds[s.Type] = s
+		assert.Equal(t, items.Fingerprint, s.Fingerprint)
+		assert.Equal(t, items.TotalTime, s.Impact)
+	}
+	nplus := kinds[suggestionNPlusOne]
+	assert.Contains(t, nplus.Fix, `relica.In("id"`)
+	assert.Equal(t, "CREATE INDEX idx_items_name ON items(name);", kinds["index_missing"].Fix)
+
+	require.NoError(t, db.DismissSuggestion(ctx, nplus.ID))
+	require.NoError(t, db.AcceptSuggestion(ctx, kinds["index_missing"].ID))
+	assert.ErrorContains(t, db.DismissSuggestion(ctx, "missing"), "not found")
+
+	loadTwice(db)
+	assert.Len(t, warnings, 1, "dismissed suggestions are not reported")
+	require.NoError(t, db.FlushOptimizerStore(ctx))
+
+	accepted, err := db.OptimizerSuggestions(ctx, SuggestionAccepted)
+	require.NoError(t, err)
+	require.Len(t, accepted, 1)
+	profiles, err = db.QueryProfiles(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, int64(4), profiles[0].Calls, "counts accumulate across restarts")
+}
+
+func TestOptimizerStore_Disabled(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	assert.ErrorIs(t, db.CreateOptimizerStore(ctx), ErrNoOptimizerStore)
+	assert.ErrorIs(t, db.FlushOptimizerStore(ctx), ErrNoOptimizerStore)
+	_, err = db.OptimizerSuggestions(ctx, "")
+	assert.ErrorIs(t, err, ErrNoOptimizerStore)
+}
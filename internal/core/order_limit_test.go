@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -410,3 +411,55 @@ func TestSelectQuery_OrderBy_EmptyString(t *testing.T) {
 	// Empty string should be ignored (no ORDER BY clause)
 	assert.NotContains(t, q.sql, "ORDER BY")
 }
+
+// TestSelectQuery_OrderBy_Expressions tests that expression terms pass through unquoted
+func TestSelectQuery_OrderBy_Expressions(t *testing.T) {
+	tests := []struct {
+		orderBy  string
+		expected string
+	}{
+		{"u.name desc nulls last", `ORDER BY "u"."name" DESC NULLS LAST`},
+		{"created_at NULLS FIRST", `ORDER BY "created_at" NULLS FIRST`},
+		{"LOWER(name) DESC", `ORDER BY LOWER(name) DESC`},
+		{"COALESCE(nickname, name) ASC", `ORDER BY COALESCE(nickname, name) ASC`},
+		{"created_at::date DESC", `ORDER BY created_at::date DESC`},
+		{"price * qty", `ORDER BY price * qty`},
+		{"name = 'a;b' DESC", `ORDER BY name = 'a;b' DESC`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.orderBy, func(t *testing.T) {
+			qb := &QueryBuilder{db: mockDB("postgres")}
+			q := qb.Select("id").From("users u").OrderBy(tt.orderBy).Build()
+			require.NoError(t, q.prepErr)
+			assert.Contains(t, q.sql, tt.expected)
+		})
+	}
+}
+
+// TestSelectQuery_OrderBy_UnsafeTerm tests that separators and comments fail the query
+func TestSelectQuery_OrderBy_UnsafeTerm(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	for _, term := range []string{"name; DROP TABLE users", "name -- x", "id /* x */"} {
+		q := qb.Select("id").From("users").OrderBy(term).Build()
+		assert.EqualError(t, q.prepErr, fmt.Sprintf("relica: ORDER BY term %q contains a statement separator or comment", term))
+	}
+
+	q := qb.Select("id").From("users").GroupBy("DATE(created_at); DROP TABLE users").Build()
+	assert.ErrorContains(t, q.prepErr, "relica: GROUP BY term")
+
+	q = qb.Select("id").From("a").Union(qb.Select("id").From("b")).UnionOrderBy("id; --").Build()
+	assert.ErrorContains(t, q.prepErr, "relica: ORDER BY term")
+}
+
+// TestRawOrder tests raw ORDER BY and GROUP BY terms with parameters
+func TestRawOrder(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	q := qb.Select("DATE(created_at)", "COUNT(*)").From("orders").
+		Where("status = ?", "paid").
+		GroupBySub(RawOrder("DATE(created_at)")).
+		OrderBySub(RawOrder("DATE(created_at) = ? DESC", "2024-01-01")).
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Contains(t, q.sql, `GROUP BY DATE(created_at) ORDER BY DATE(created_at) = $2 DESC`)
+	assert.Equal(t, []interface{}{"paid", "2024-01-01"}, q.params)
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -247,10 +248,10 @@ func TestOrderBySub_CaseWhen(t *testing.T) {
 		Build()
 
 	require.NotNil(t, q)
-	// CaseWhen: conditions are raw SQL, THEN results are parameterized
-	assert.Contains(t, q.sql, "CASE WHEN t.due_date < CURRENT_DATE THEN ?")
-	assert.Contains(t, q.sql, "WHEN t.due_date IS NULL THEN ?")
-	assert.Contains(t, q.sql, "ELSE ?")
+	// CaseWhen: conditions are raw SQL, THEN results are parameterized (renumbered for PostgreSQL)
+	assert.Contains(t, q.sql, "CASE WHEN t.due_date < CURRENT_DATE THEN $1")
+	assert.Contains(t, q.sql, "WHEN t.due_date IS NULL THEN $3")
+	assert.Contains(t, q.sql, "ELSE $4")
 	assert.Contains(t, q.sql, `"t"."due_date" ASC`)
 	assert.Contains(t, q.params, 0)
 	assert.Contains(t, q.params, 1)
@@ -325,3 +326,95 @@ func TestGroupBySub_CaseWhen(t *testing.T) {
 	require.NotNil(t, q)
 	assert.Contains(t, q.sql, "GROUP BY CASE")
 }
+
+// =============================================================================
+// GroupBy quoting, ROLLUP / CUBE / GROUPING SETS
+// =============================================================================
+
+func TestGroupBy_ExpressionsNotQuoted(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Select("COUNT(*)").From("orders").
+		GroupBy("u.region", "created_at::date", "price * qty").
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Contains(t, q.sql, `GROUP BY "u"."region", created_at::date, price * qty`)
+}
+
+func TestGroupByRollup(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		expected string
+	}{
+		{"postgres", `GROUP BY ROLLUP ("region", "product")`},
+		{"mysql", "GROUP BY `region`, `product` WITH ROLLUP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			qb := &QueryBuilder{db: mockDB(tt.dialect)}
+			q := qb.Select("region", "product", "SUM(amount)").From("sales").
+				GroupByRollup("region", "product").
+				OrderBy("region").
+				Build()
+
+			require.NoError(t, q.prepErr)
+			assert.Contains(t, q.sql, tt.expected)
+		})
+	}
+}
+
+func TestGroupByCubeAndGroupingSets_PostgreSQL(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Select("SUM(amount)").From("sales").
+		GroupBy("year").
+		GroupByCube("region", "product").
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Contains(t, q.sql, `GROUP BY "year", CUBE ("region", "product")`)
+
+	q = qb.Select("SUM(amount)").From("sales").
+		GroupingSets([]string{"region", "product"}, []string{"region"}, []string{}).
+		Having("SUM(amount) > ?", 100).
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Contains(t, q.sql, `GROUP BY GROUPING SETS (("region", "product"), ("region"), ()) HAVING SUM(amount) > $1`)
+}
+
+func TestGroupingElems_UnsupportedDialects(t *testing.T) {
+	q := (&QueryBuilder{db: mockDB("sqlite")}).Select("COUNT(*)").From("sales").
+		GroupByRollup("region").Build()
+	assert.Error(t, q.prepErr)
+
+	mysql := &QueryBuilder{db: mockDB("mysql")}
+	q = mysql.Select("COUNT(*)").From("sales").GroupByCube("region").Build()
+	assert.Error(t, q.prepErr)
+
+	q = mysql.Select("COUNT(*)").From("sales").GroupBy("year").GroupByRollup("region").Build()
+	assert.Error(t, q.prepErr)
+
+	q = mysql.Select("COUNT(*)").From("sales").GroupByRollup().Build()
+	assert.Error(t, q.prepErr)
+}
+
+// TestGroupingElems_ImmutableBuildConcurrent builds a shared query whose
+// grouping fails from several goroutines: Build reports the error without
+// storing it on the query (run with -race).
+func TestGroupingElems_ImmutableBuildConcurrent(t *testing.T) {
+	base := (&QueryBuilder{db: mockDB("sqlite")}).Select("COUNT(*)").From("sales").
+		GroupByRollup("region").Immutable()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.ErrorContains(t, base.Build().prepErr, "ROLLUP is not supported by SQLite")
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, base.buildErr)
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Transactional outbox
+// ============================================================================
+//
+// Publish stores a message in an outbox table inside the caller's transaction,
+// so the message exists exactly when the business change it describes does.
+// An OutboxRelay polls the table, hands messages to a handler (a message
+// broker publisher, typically) and deletes them once delivered. Delivery is
+// at-least-once: a crash between the handler and the commit delivers the
+// message again, so handlers must be idempotent.
+
+// DefaultOutboxTable is the outbox table used unless another is set with Table.
+const DefaultOutboxTable = "relica_outbox"
+
+// defaultOutboxBatch is the number of messages an OutboxRelay claims per poll.
+const defaultOutboxBatch = 100
+
+// OutboxMessage is a message stored in the outbox table.
+type OutboxMessage struct {
+	ID        int64     `db:"id"`
+	Topic     string    `db:"topic"`
+	Payload   []byte    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+	Attempts  int       `db:"attempts"` // failed deliveries so far
+}
+
+// Outbox publishes messages within a transaction.
+type Outbox struct {
+	tx    *Tx
+	table string
+}
+
+// Outbox returns the transactional outbox of tx. Messages published through it
+// are committed or rolled back together with the rest of the transaction.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    if err := tx.Model(&order).Insert(); err != nil {
+//	        return err
+//	    }
+//	    return tx.Outbox().Publish("orders.created", order)
+//	})
+func (tx *Tx) Outbox() *Outbox {
+	return &Outbox{tx: tx, table: DefaultOutboxTable}
+}
+
+// Table sets the outbox table (default DefaultOutboxTable).
+func (o *Outbox) Table(name string) *Outbox {
+	o.table = name
+	return o
+}
+
+// Publish writes a message for topic to the outbox. A []byte, string or
+// json.RawMessage payload is stored as is; any other value is encoded as JSON.
+func (o *Outbox) Publish(topic string, payload interface{}) error {
+	var body string
+	switch p := payload.(type) {
+	case []byte:
+		body = string(p)
+	case json.RawMessage:
+		body = string(p)
+	case string:
+		body = p
+	default:
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("relica: outbox payload for %s: %w", topic, err)
+		}
+		body = string(b)
+	}
+
+	_, err := o.tx.Builder().Insert(o.table, map[string]interface{}{
+		"topic":      topic,
+		"payload":    body,
+		"created_at": time.Now().UTC(),
+	}).Execute()
+	return err
+}
+
+// CreateOutboxTable creates the outbox table if it does not exist. An empty
+// table name uses DefaultOutboxTable. Applications with their own migrations
+// can create the table there instead: the relay needs the columns id
+// (auto-increment primary key), topic, payload (text), created_at, attempts
+// (integer, default 0) and last_error (nullable text).
+func (db *DB) CreateOutboxTable(ctx context.Context, table string) error {
+	if table == "" {
+		table = DefaultOutboxTable
+	}
+	d := db.dialect
+	var id, ts, text string
+	switch d.(type) {
+	case *dialects.PostgresDialect:
+		id, ts, text = "BIGSERIAL PRIMARY KEY", "TIMESTAMPTZ", "TEXT"
+	case *dialects.MySQLDialect:
+		id, ts, text = "BIGINT AUTO_INCREMENT PRIMARY KEY", "DATETIME(6)", "LONGTEXT"
+	default:
+		id, ts, text = "INTEGER PRIMARY KEY AUTOINCREMENT", "DATETIME", "TEXT"
+	}
+
+	ddl := "CREATE TABLE IF NOT EXISTS " + d.QuoteIdentifier(table) + " (" +
+		d.QuoteIdentifier("id") + " " + id + ", " +
+		d.QuoteIdentifier("topic") + " VARCHAR(255) NOT NULL, " +
+		d.QuoteIdentifier("payload") + " " + text + " NOT NULL, " +
+		d.QuoteIdentifier("created_at") + " " + ts + " NOT NULL, " +
+		d.QuoteIdentifier("attempts") + " INTEGER NOT NULL DEFAULT 0, " +
+		d.QuoteIdentifier("last_error") + " " + text + ")"
+	_, err := db.ExecContext(ctx, ddl)
+	return err
+}
+
+// OutboxHandler delivers an outbox message. Returning nil deletes the message;
+// an error keeps it for a later poll and records the error on the message.
+type OutboxHandler func(ctx context.Context, msg OutboxMessage) error
+
+// OutboxRelay dispatches outbox messages to a handler.
+type OutboxRelay struct {
+	db          *DB
+	handler     OutboxHandler
+	table       string
+	batchSize   int
+	maxAttempts int
+}
+
+// OutboxRelay returns a relay that dispatches the messages of the outbox table
+// to handler. Call Poll from a scheduler, or Run to poll in a loop.
+//
+// Several relays (in one process or many) can poll the same table: on
+// PostgreSQL and MySQL 8 messages are claimed with FOR UPDATE SKIP LOCKED, so
+// each message is handed to one relay at a time. SQLite serializes writers and
+// needs no row locks.
+//
+// Example:
+//
+//	relay := db.OutboxRelay(func(ctx context.Context, msg relica.OutboxMessage) error {
+//	    return broker.Publish(ctx, msg.Topic, msg.Payload)
+//	}).MaxAttempts(10)
+//	go relay.Run(ctx, time.Second)
+func (db *DB) OutboxRelay(handler OutboxHandler) *OutboxRelay {
+	return &OutboxRelay{db: db, handler: handler, table: DefaultOutboxTable, batchSize: defaultOutboxBatch}
+}
+
+// Table sets the outbox table (default DefaultOutboxTable).
+func (r *OutboxRelay) Table(name string) *OutboxRelay {
+	r.table = name
+	return r
+}
+
+// BatchSize sets the maximum number of messages claimed per poll (default 100).
+func (r *OutboxRelay) BatchSize(n int) *OutboxRelay {
+	if n > 0 {
+		r.batchSize = n
+	}
+	return r
+}
+
+// MaxAttempts stops retrying messages that failed n times; they stay in the
+// table for inspection. Zero (the default) retries forever.
+func (r *OutboxRelay) MaxAttempts(n int) *OutboxRelay {
+	r.maxAttempts = n
+	return r
+}
+
+// Poll claims one batch of messages in id order, dispatches them to the
+// handler and returns the number delivered. Claimed rows stay locked until the
+// batch is done, so keep handlers short. Handler errors are recorded on their
+// messages and do not stop the batch; only database errors are returned.
+func (r *OutboxRelay) Poll(ctx context.Context) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	delivered := 0
+	err := r.db.Transactional(ctx, func(tx *Tx) error {
+		sq := tx.Builder().Select("id", "topic", "payload", "created_at", "attempts").
+			From(r.table).
+			OrderBy("id").
+			Limit(int64(r.batchSize))
+		if r.maxAttempts > 0 {
+			sq = sq.Where(LessThan("attempts", r.maxAttempts))
+		}
+		q := sq.Build()
+		if _, ok := r.db.dialect.(*dialects.SQLiteDialect); !ok {
+			q.appendSQL(" FOR UPDATE SKIP LOCKED")
+		}
+
+		var msgs []OutboxMessage
+		if err := q.All(&msgs); err != nil {
+			return err
+		}
+
+		done := make([]interface{}, 0, len(msgs))
+		for _, msg := range msgs {
+			herr := r.handler(ctx, msg)
+			if herr == nil {
+				done = append(done, msg.ID)
+				continue
+			}
+			_, err := tx.Builder().Update(r.table).
+				Set(map[string]interface{}{"attempts": NewExp("attempts + 1"), "last_error": herr.Error()}).
+				Where(Eq("id", msg.ID)).
+				Execute()
+			if err != nil {
+				return err
+			}
+		}
+		if len(done) > 0 {
+			if _, err := tx.Builder().Delete(r.table).Where(In("id", done...)).Execute(); err != nil {
+				return err
+			}
+		}
+		delivered = len(done)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("relica: outbox relay %s: %w", r.table, err)
+	}
+	return delivered, nil
+}
+
+// Run polls until ctx is canceled, waiting interval between polls that find
+// no full batch. It returns ctx.Err() on cancellation or the first database error.
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		n, err := r.Poll(ctx)
+		if err != nil {
+			// A statement timeout or the query watchdog also reports a
+			// deadline; only the relay's own context ends the relay quietly
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+		if n == r.batchSize {
+			timer.Reset(0) // more messages are likely waiting
+		} else {
+			timer.Reset(interval)
+		}
+	}
+}
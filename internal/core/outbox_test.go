@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupOutboxDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	require.NoError(t, db.CreateOutboxTable(ctx, ""))
+	require.NoError(t, db.CreateOutboxTable(ctx, ""), "creating an existing table is a no-op")
+	return db
+}
+
+func TestOutbox_Publish(t *testing.T) {
+	db := setupOutboxDB(t)
+	ctx := context.Background()
+
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		require.NoError(t, tx.Outbox().Publish("orders.created", map[string]interface{}{"id": 7}))
+		return tx.Outbox().Publish("raw", []byte("bytes"))
+	})
+	require.NoError(t, err)
+
+	errAbort := errors.New("abort")
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		require.NoError(t, tx.Outbox().Publish("orders.created", "lost"))
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	var msgs []OutboxMessage
+	require.NoError(t, db.Builder().Select().From(DefaultOutboxTable).OrderBy("id").All(&msgs))
+	require.Len(t, msgs, 2, "messages of rolled back transactions are not stored")
+	assert.Equal(t, "orders.created", msgs[0].Topic)
+	assert.JSONEq(t, `{"id":7}`, string(msgs[0].Payload))
+	assert.Equal(t, "bytes", string(msgs[1].Payload))
+	assert.WithinDuration(t, time.Now(), msgs[0].CreatedAt, time.Minute)
+
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		return tx.Outbox().Publish("bad", func() {})
+	})
+	assert.ErrorContains(t, err, "relica: outbox payload for bad")
+}
+
+func TestOutboxRelay_Poll(t *testing.T) {
+	db := setupOutboxDB(t)
+	ctx := context.Background()
+
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		for _, topic := range []string{"a", "b", "c"} {
+			if err := tx.Outbox().Publish(topic, topic); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var seen []string
+	relay := db.OutboxRelay(func(_ context.Context, msg OutboxMessage) error {
+		seen = append(seen, msg.Topic)
+		if msg.Topic == "b" {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	}).BatchSize(2).MaxAttempts(2)
+
+	n, err := relay.Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"a", "b"}, seen, "messages are claimed in id order")
+
+	n, err = relay.Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"a", "b", "b", "c"}, seen)
+
+	// b failed twice and is no longer retried.
+	n, err = relay.Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Len(t, seen, 4)
+
+	var left struct {
+		Topic     string `db:"topic"`
+		Attempts  int    `db:"attempts"`
+		LastError string `db:"last_error"`
+	}
+	require.NoError(t, db.Builder().Select("topic", "attempts", "last_error").From(DefaultOutboxTable).One(&left))
+	assert.Equal(t, "b", left.Topic)
+	assert.Equal(t, 2, left.Attempts)
+	assert.Equal(t, "broker unavailable", left.LastError)
+
+	_, err = db.OutboxRelay(func(context.Context, OutboxMessage) error { return nil }).Table("missing").Poll(ctx)
+	assert.ErrorContains(t, err, "relica: outbox relay missing")
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	db := setupOutboxDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		for i := 0; i < 5; i++ {
+			if err := tx.Outbox().Publish("tick", i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var delivered int
+	relay := db.OutboxRelay(func(context.Context, OutboxMessage) error {
+		delivered++
+		if delivered == 5 {
+			cancel()
+		}
+		return nil
+	}).BatchSize(2)
+
+	err = relay.Run(ctx, time.Hour)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 5, delivered, "full batches are followed by an immediate poll")
+}
+
+func TestOutboxRelay_RunQueryTimeout(t *testing.T) {
+	db := setupOutboxDB(t)
+	db.maxRuntime = time.Nanosecond // every query exceeds the watchdog
+
+	relay := db.OutboxRelay(func(context.Context, OutboxMessage) error { return nil })
+	err := relay.Run(context.Background(), time.Hour)
+	require.Error(t, err, "a query deadline with a live context is not a clean stop")
+	assert.ErrorIs(t, err, ErrQueryWatchdog)
+}
@@ -92,6 +92,37 @@ func TestSelectAliasQuoting_FunctionWithAlias(t *testing.T) {
 	assert.Contains(t, q.sql, "COUNT(*) AS total")
 }
 
+func TestSelectAliasQuoting_ExpressionsAndReservedAliases(t *testing.T) {
+	tests := []struct {
+		dialect string
+		col     string
+		want    string
+	}{
+		{"postgres", `status AS "order"`, `"status" AS "order"`},
+		{"postgres", `u.status AS order`, `"u"."status" AS "order"`},
+		{"mysql", "u.status AS `order`", "`u`.`status` AS `order`"},
+		{"mysql", `status AS "Order"`, "`status` AS `Order`"},
+		{"postgres", `name AS "Say ""hi"""`, `"name" AS "Say ""hi"""`},
+		{"postgres", "COUNT(*) AS userCount", `COUNT(*) AS "userCount"`},
+		{"postgres", "COUNT(*) AS user", `COUNT(*) AS "user"`},
+		{"postgres", `MAX(created_at) AS "last"`, `MAX(created_at) AS "last"`},
+		{"postgres", "CAST(total AS INTEGER) AS amount", `CAST(total AS INTEGER) AS amount`},
+		{"postgres", "price * qty AS line_total", `price * qty AS "line_total"`},
+		{"postgres", "price * qty", `price * qty`},
+		{"postgres", "created_at::date AS day", `created_at::date AS "day"`},
+		{"postgres", "1 AS one", `1 AS "one"`},
+		{"postgres", "u.*", `u.*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.col, func(t *testing.T) {
+			qb := &QueryBuilder{db: mockDB(tt.dialect)}
+			q := qb.Select(tt.col).From("users u").Build()
+			assert.Contains(t, q.sql, "SELECT "+tt.want+" FROM ")
+		})
+	}
+}
+
 // =============================================================================
 // quoteColumn function-call guard
 // =============================================================================
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Parameter limits
+// ============================================================================
+//
+// Databases cap the number of bind parameters per statement. A statement over
+// the cap fails at execution with a driver protocol error that rarely names
+// the cause (PostgreSQL drivers report a malformed message, MySQL "Prepared
+// statement contains too many placeholders"). Built queries are checked
+// against the dialect's limit instead, and fail with ErrTooManyParams before
+// any SQL is sent.
+
+const (
+	// maxPostgresParams is the PostgreSQL limit: the protocol sends the
+	// parameter count as a 16-bit integer.
+	maxPostgresParams = 65535
+
+	// maxMySQLParams is the MySQL limit on placeholders of a prepared statement.
+	maxMySQLParams = 65535
+
+	// maxSQLiteParams is SQLite's default SQLITE_MAX_VARIABLE_NUMBER since
+	// 3.32.0 (999 on older builds).
+	maxSQLiteParams = 32766
+)
+
+// maxParams returns the maximum number of parameters of a statement and the
+// name of the dialect, or 0 if the limit is unknown (custom dialects).
+func maxParams(dialect dialects.Dialect) (int, string) {
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		return maxPostgresParams, "PostgreSQL"
+	case *dialects.MySQLDialect:
+		return maxMySQLParams, "MySQL"
+	case *dialects.SQLiteDialect:
+		return maxSQLiteParams, "SQLite"
+	default:
+		return 0, ""
+	}
+}
+
+// checkParamLimit returns ErrTooManyParams, with the count and the limit, if a
+// statement with count parameters exceeds the dialect's limit.
+func checkParamLimit(count int, dialect dialects.Dialect) error {
+	limit, name := maxParams(dialect)
+	if limit == 0 || count <= limit {
+		return nil
+	}
+	return fmt.Errorf("%w: statement has %d parameters, %s allows at most %d",
+		ErrTooManyParams, count, name, limit)
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// intRange returns the integers 0 to n-1 as IN values.
+func intRange(n int) []interface{} {
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = i
+	}
+	return values
+}
+
+func TestCheckParamLimit(t *testing.T) {
+	tests := []struct {
+		dialect string
+		limit   int
+		name    string
+	}{
+		{"postgres", 65535, "PostgreSQL"},
+		{"mysql", 65535, "MySQL"},
+		{"sqlite", 32766, "SQLite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			d := dialects.GetDialect(tt.dialect)
+			assert.NoError(t, checkParamLimit(tt.limit, d))
+
+			err := checkParamLimit(tt.limit+1, d)
+			require.ErrorIs(t, err, ErrTooManyParams)
+			assert.Contains(t, err.Error(), tt.name)
+			assert.Contains(t, err.Error(), fmt.Sprintf("has %d parameters", tt.limit+1))
+		})
+	}
+}
+
+func TestCheckParamLimit_CustomDialect(t *testing.T) {
+	assert.NoError(t, checkParamLimit(1_000_000, customDialect{dialects.GetDialect("postgres").(*dialects.PostgresDialect)}))
+}
+
+func TestBuild_TooManyParams(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+
+	q := qb.Select("*").From("users").Where(In("id", intRange(32767)...)).Build()
+	require.ErrorIs(t, q.prepErr, ErrTooManyParams)
+	assert.EqualError(t, q.prepErr,
+		"relica: too many query parameters: statement has 32767 parameters, SQLite allows at most 32766")
+
+	q = qb.Select("*").From("users").Where(In("id", intRange(32766)...)).Build()
+	assert.NoError(t, q.prepErr)
+
+	q = qb.Delete("users").Where(In("id", intRange(40000)...)).Build()
+	assert.ErrorIs(t, q.prepErr, ErrTooManyParams)
+
+	batch := qb.BatchInsert("users", []string{"id", "name"})
+	for i := 0; i < 16384; i++ {
+		batch.Values(i, "name")
+	}
+	assert.ErrorIs(t, batch.Build().prepErr, ErrTooManyParams)
+}
+
+func TestBuild_TooManyParams_ReturnedOnExecute(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	_, err := qb.Update("users").Set(map[string]interface{}{"active": false}).
+		Where(In("id", intRange(70000)...)).Execute()
+	require.ErrorIs(t, err, ErrTooManyParams)
+	assert.Contains(t, err.Error(), "statement has 70001 parameters, PostgreSQL allows at most 65535")
+}
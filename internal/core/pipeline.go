@@ -0,0 +1,175 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Statement pipelines
+// ============================================================================
+//
+// DB.Pipeline sends a group of independent write statements together. On
+// PostgreSQL, INSERT, UPDATE and DELETE statements are combined into one
+// statement of data-modifying CTEs, which runs in a single round trip with
+// every parameter still bound:
+//
+//	WITH relica_p1 AS (INSERT ... RETURNING 1),
+//	     relica_p2 AS (UPDATE ... RETURNING 1)
+//	SELECT (SELECT COUNT(*) FROM relica_p1), (SELECT COUNT(*) FROM relica_p2)
+//
+// Other statements, other databases and statements with change capture
+// (WithChangeSink) run one by one in a transaction on a single connection.
+// database/sql drivers accept several statements with bound parameters in one
+// call only with driver-specific DSN flags (multiStatements and
+// interpolateParams for MySQL), which relica cannot rely on.
+
+// returningRegex detects an existing RETURNING clause.
+var returningRegex = regexp.MustCompile(`(?i)\bRETURNING\b`)
+
+// Pipeline collects the statements of DB.Pipeline.
+type Pipeline struct {
+	queries []*Query
+}
+
+// Queue adds q to the pipeline. Statements run in the order queued.
+func (p *Pipeline) Queue(q *Query) {
+	p.queries = append(p.queries, q)
+}
+
+// Pipeline runs the statements queued by fn as a unit and returns the rows
+// affected by each, in queue order. Either all statements take effect or none.
+//
+// On PostgreSQL, INSERT, UPDATE and DELETE statements are combined into one
+// statement of data-modifying CTEs and sent in a single round trip. They run on the
+// same snapshot: a statement does not see the changes of the others, and a
+// row must not be modified by more than one of them. Queue only independent
+// statements. Elsewhere the statements run one by one in a transaction.
+//
+// Example:
+//
+//	affected, err := db.Pipeline(ctx, func(p *core.Pipeline) {
+//	    for _, e := range events {
+//	        p.Queue(db.Builder().Insert("events", e))
+//	    }
+//	    p.Queue(db.Builder().Update("counters").Set(counts).Where(Eq("id", 1)).Build())
+//	})
+func (db *DB) Pipeline(ctx context.Context, fn func(*Pipeline)) ([]int64, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := &Pipeline{}
+	fn(p)
+	if len(p.queries) == 0 {
+		return nil, nil
+	}
+
+	for i, q := range p.queries {
+		if q == nil {
+			return nil, fmt.Errorf("relica: pipeline statement %d is nil", i)
+		}
+		if err := q.validateBeforeExec(ctx); err != nil {
+			return nil, fmt.Errorf("relica: pipeline statement %d: %w", i, err)
+		}
+	}
+
+	if db.combinable(p.queries) {
+		return db.runCombined(ctx, p.queries)
+	}
+	return db.runSequential(ctx, p.queries)
+}
+
+// combinable reports whether queries can be sent as one PostgreSQL statement.
+func (db *DB) combinable(queries []*Query) bool {
+	if _, ok := db.dialect.(*dialects.PostgresDialect); !ok || len(queries) < 2 {
+		return false
+	}
+	for _, q := range queries {
+		if q.change != nil || q.prepared {
+			return false
+		}
+		switch DetectOperation(q.sql) {
+		case opInsert, opUpdate, opDelete:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// runCombined runs queries as one statement of data-modifying CTEs.
+func (db *DB) runCombined(ctx context.Context, queries []*Query) ([]int64, error) {
+	query, params := db.combinedSQL(queries)
+	affected := make([]int64, len(queries))
+	dest := make([]interface{}, len(queries))
+	for i := range affected {
+		dest[i] = &affected[i]
+	}
+	combined := &Query{
+		sql:    query,
+		params: params,
+		db:     db,
+		tx:     db.boundSQLTx(),
+		ctx:    ctx,
+		tag:    queries[0].tag,
+	}
+	if err := combined.Row(dest...); err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// combinedSQL builds the statement of runCombined, which selects the number
+// of rows returned by each data-modifying CTE.
+func (db *DB) combinedSQL(queries []*Query) (string, []interface{}) {
+	var b strings.Builder
+	var params []interface{}
+	counts := make([]string, len(queries))
+	for i, q := range queries {
+		name := "relica_p" + strconv.Itoa(i+1)
+		if i == 0 {
+			b.WriteString("WITH ")
+		} else {
+			b.WriteString(", ")
+		}
+		stmt := strings.TrimSuffix(strings.TrimSpace(q.sql), ";")
+		b.WriteString(name + " AS (")
+		b.WriteString(renumberFragment(stmt, len(params)+1, len(q.params), db.dialect))
+		if !returningRegex.MatchString(stringLiteralRegex.ReplaceAllString(stmt, "''")) {
+			b.WriteString(" RETURNING 1")
+		}
+		b.WriteString(")")
+		params = append(params, q.params...)
+		counts[i] = "(SELECT COUNT(*) FROM " + name + ")"
+	}
+	b.WriteString(" SELECT " + strings.Join(counts, ", "))
+	return b.String(), params
+}
+
+// runSequential runs queries one by one in a transaction.
+func (db *DB) runSequential(ctx context.Context, queries []*Query) ([]int64, error) {
+	affected := make([]int64, len(queries))
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		for i, q := range queries {
+			tq := *q
+			tq.tx = tx.tx
+			tq.stmt, tq.prepared = nil, false // a statement prepared on the pool runs outside tx
+			tq.ctx = ctx
+			result, err := tq.Execute()
+			if err != nil {
+				return fmt.Errorf("relica: pipeline statement %d: %w", i, err)
+			}
+			affected[i], _ = result.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
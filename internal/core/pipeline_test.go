@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline_CombinedSQL(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	queries := []*Query{
+		qb.Insert("events", map[string]interface{}{"name": "signup"}),
+		qb.Update("counters").Set(map[string]interface{}{"n": 2}).Where(Eq("id", 1)).Build(),
+		{sql: "DELETE FROM sessions WHERE id = $1 RETURNING id;", params: []interface{}{7}},
+	}
+	require.True(t, db.combinable(queries))
+
+	query, params := db.combinedSQL(queries)
+	assert.Equal(t, `WITH relica_p1 AS (INSERT INTO "events" ("name") VALUES ($1) RETURNING 1), `+
+		`relica_p2 AS (UPDATE "counters" SET "n" = $2 WHERE "id" = $3 RETURNING 1), `+
+		`relica_p3 AS (DELETE FROM sessions WHERE id = $4 RETURNING id) `+
+		`SELECT (SELECT COUNT(*) FROM relica_p1), (SELECT COUNT(*) FROM relica_p2), (SELECT COUNT(*) FROM relica_p3)`, query)
+	assert.Equal(t, []interface{}{"signup", 2, 1, 7}, params)
+
+	assert.False(t, db.combinable(queries[:1]), "a single statement gains nothing")
+	assert.False(t, db.combinable(append(queries, &Query{sql: "TRUNCATE sessions"})))
+	assert.False(t, mockDB("mysql").combinable(queries))
+}
+
+func TestPipeline_Sequential(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT UNIQUE)`)
+	require.NoError(t, err)
+
+	affected, err := db.Pipeline(ctx, func(p *Pipeline) {
+		p.Queue(db.Builder().Insert("events", map[string]interface{}{"name": "a"}))
+		p.Queue(db.Builder().Insert("events", map[string]interface{}{"name": "b"}))
+		p.Queue(db.Builder().Update("events").Set(map[string]interface{}{"name": "c"}).Where(Eq("name", "b")).Build())
+		p.Queue(db.Builder().Delete("events").Where(Eq("name", "missing")).Build())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 1, 1, 0}, affected)
+
+	_, err = db.Pipeline(ctx, func(p *Pipeline) {
+		p.Queue(db.Builder().Insert("events", map[string]interface{}{"name": "d"}))
+		p.Queue(db.Builder().Insert("events", map[string]interface{}{"name": "a"}))
+	})
+	require.ErrorIs(t, err, ErrUniqueViolation)
+	assert.ErrorContains(t, err, "relica: pipeline statement 1")
+
+	var names []string
+	require.NoError(t, db.Builder().Select("name").From("events").OrderBy("name").Column(&names))
+	assert.Equal(t, []string{"a", "c"}, names, "a failed pipeline changes nothing")
+
+	affected, err = db.Pipeline(ctx, func(*Pipeline) {})
+	require.NoError(t, err)
+	assert.Nil(t, affected)
+
+	_, err = db.Pipeline(ctx, func(p *Pipeline) { p.Queue(db.Builder().Insert("events", nil)) })
+	assert.ErrorContains(t, err, "relica: pipeline statement 0: relica: Insert requires a non-empty values map")
+}
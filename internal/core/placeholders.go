@@ -0,0 +1,229 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Placeholder numbering
+// ============================================================================
+//
+// Expressions and raw conditions use "?" placeholders, and statements built
+// for PostgreSQL are numbered from $1. When a fragment is embedded in a larger
+// statement its placeholders are numbered, or shifted, in a single pass over
+// the fragment while it is written into the statement buffer. The scan skips
+// string literals, quoted identifiers, dollar-quoted strings and comments, so
+// a '?' or "$1" inside them is kept as written.
+
+// placeholderScanner finds the placeholders of a SQL text outside string
+// literals, quoted identifiers, dollar-quoted strings and comments.
+type placeholderScanner struct {
+	query string
+	pos   int
+	mysql bool // backslash escapes in string literals
+}
+
+// next returns the next placeholder: its start and end offsets in the query,
+// and its number, 0 for "?". ok is false when there are no more.
+func (s *placeholderScanner) next() (start, end, n int, ok bool) {
+	q := s.query
+	for i := s.pos; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = quotedEnd(q, i, s.mysql && c != '`') - 1
+		case c == '-' && strings.HasPrefix(q[i:], "--"):
+			end := strings.IndexByte(q[i:], '\n')
+			if end < 0 {
+				end = len(q) - i
+			}
+			i += end - 1
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			end := strings.Index(q[i+2:], "*/")
+			if end < 0 {
+				end = len(q) - i - 4
+			}
+			i += end + 3
+		case c == '?':
+			s.pos = i + 1
+			return i, i + 1, 0, true
+		case c == '$' && (i == 0 || !isIdentifierByte(q[i-1])):
+			j := i + 1
+			for j < len(q) && isDigit(q[j]) {
+				if n < 1<<30 {
+					n = n*10 + int(q[j]-'0')
+				}
+				j++
+			}
+			if j > i+1 {
+				s.pos = j
+				return i, j, n, true
+			}
+			// $tag$ ... $tag$ (PostgreSQL dollar quoting)
+			for j < len(q) && isIdentifierByte(q[j]) && q[j] != '$' {
+				j++
+			}
+			if j < len(q) && q[j] == '$' {
+				tag := q[i : j+1]
+				if end := strings.Index(q[j+1:], tag); end >= 0 {
+					i = j + end + len(tag)
+				} else {
+					i = len(q) - 1
+				}
+			}
+		}
+	}
+	s.pos = len(q)
+	return 0, 0, 0, false
+}
+
+// isIdentifierByte reports whether c can be part of an unquoted identifier.
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+}
+
+// hasQuestionPlaceholder reports whether fragment has a "?" placeholder.
+func hasQuestionPlaceholder(fragment string) bool {
+	s := placeholderScanner{query: fragment}
+	for {
+		_, _, n, ok := s.next()
+		if !ok {
+			return false
+		}
+		if n == 0 {
+			return true
+		}
+	}
+}
+
+// writeNumbered writes fragment with its placeholders numbered from
+// startIndex: its "?" placeholders or, if it has none, its $N placeholders
+// numbered from $1 (a statement built for PostgreSQL). Dialects with "?"
+// placeholders get fragment unchanged.
+func (b *sqlBuffer) writeNumbered(fragment string, startIndex, argCount int, dialect dialects.Dialect) {
+	switch {
+	case argCount == 0 || !numberedPlaceholders(dialect):
+		b.WriteString(fragment)
+	case hasQuestionPlaceholder(fragment):
+		b.writeNumberedCount(fragment, startIndex, argCount, dialect)
+	default:
+		b.writeShifted(fragment, startIndex, argCount, dialect)
+	}
+}
+
+// writeNumberedCount writes fragment with up to argCount of its "?"
+// placeholders replaced by the dialect's placeholders numbered from
+// startIndex. Returns the number of placeholders replaced.
+func (b *sqlBuffer) writeNumberedCount(fragment string, startIndex, argCount int, dialect dialects.Dialect) int {
+	if argCount == 0 || !numberedPlaceholders(dialect) {
+		b.WriteString(fragment)
+		return 0
+	}
+	s := placeholderScanner{query: fragment}
+	last, count := 0, 0
+	for count < argCount {
+		start, end, n, ok := s.next()
+		if !ok {
+			break
+		}
+		if n != 0 {
+			continue
+		}
+		b.WriteString(fragment[last:start])
+		b.writePlaceholder(startIndex+count, dialect)
+		last = end
+		count++
+	}
+	b.WriteString(fragment[last:])
+	return count
+}
+
+// writeShifted writes fragment, numbered from $1, with its placeholders $1 to
+// $argCount renumbered to start at startIndex.
+func (b *sqlBuffer) writeShifted(fragment string, startIndex, argCount int, dialect dialects.Dialect) {
+	s := placeholderScanner{query: fragment}
+	last := 0
+	for {
+		start, end, n, ok := s.next()
+		if !ok {
+			break
+		}
+		if n < 1 || n > argCount {
+			continue
+		}
+		b.WriteString(fragment[last:start])
+		b.writePlaceholder(startIndex+n-1, dialect)
+		last = end
+	}
+	b.WriteString(fragment[last:])
+}
+
+// writeUnnumbered writes fragment, numbered from $1, with its placeholders $1
+// to $argCount replaced by "?".
+func (b *sqlBuffer) writeUnnumbered(fragment string, argCount int) {
+	s := placeholderScanner{query: fragment}
+	last := 0
+	for {
+		start, end, n, ok := s.next()
+		if !ok {
+			break
+		}
+		if n < 1 || n > argCount {
+			continue
+		}
+		b.WriteString(fragment[last:start])
+		b.WriteByte('?')
+		last = end
+	}
+	b.WriteString(fragment[last:])
+}
+
+// writeConditions writes conds joined by AND, with up to argCount of their
+// "?" placeholders numbered from startIndex.
+func (b *sqlBuffer) writeConditions(conds []string, startIndex, argCount int, dialect dialects.Dialect) {
+	for i, cond := range conds {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		n := b.writeNumberedCount(cond, startIndex, argCount, dialect)
+		startIndex += n
+		argCount -= n
+	}
+}
+
+// whereSQL returns " WHERE " and conds joined by AND, with up to argCount of
+// their "?" placeholders numbered from startIndex, or "" without conds.
+func whereSQL(conds []string, startIndex, argCount int, dialect dialects.Dialect) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	b := getSQLBuffer()
+	defer b.release()
+	b.WriteString(" WHERE ")
+	b.writeConditions(conds, startIndex, argCount, dialect)
+	return b.String()
+}
+
+// renumberFragment renumbers the placeholders of a separately built SQL fragment
+// so they continue from startIndex (PostgreSQL $N style).
+// Expressions emit "?" placeholders, while nested SelectQuery fragments are already
+// numbered from $1; both forms are handled. For "?" dialects the fragment is returned unchanged.
+func renumberFragment(fragment string, startIndex, argCount int, dialect dialects.Dialect) string {
+	if argCount == 0 || !numberedPlaceholders(dialect) {
+		return fragment
+	}
+	question := hasQuestionPlaceholder(fragment)
+	if !question && startIndex == 1 {
+		return fragment
+	}
+
+	b := getSQLBuffer()
+	defer b.release()
+	if question {
+		b.writeNumberedCount(fragment, startIndex, argCount, dialect)
+	} else {
+		b.writeShifted(fragment, startIndex, argCount, dialect)
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholderScanner(t *testing.T) {
+	type found struct {
+		text string
+		n    int
+	}
+	tests := []struct {
+		query string
+		want  []found
+	}{
+		{"a = ? AND b = $2", []found{{"?", 0}, {"$2", 2}}},
+		{"note = 'why?' AND id = ?", []found{{"?", 0}}},
+		{`"col?" = ? -- really?` + "\n AND x = $10", []found{{"?", 0}, {"$10", 10}}},
+		{"/* $1 ? */ a = ?", []found{{"?", 0}}},
+		{"body = $$costs $1?$$ AND id = $1", []found{{"$1", 1}}},
+		{"body = $tag$ ? $tag$ AND id = ?", []found{{"?", 0}}},
+		{"price$1 = ? AND `we``ird?` = ?", []found{{"?", 0}, {"?", 0}}},
+		{"x = 'it''s ?' AND y = ?", []found{{"?", 0}}},
+		{"cost = $ 5", nil},
+	}
+	for _, tt := range tests {
+		s := placeholderScanner{query: tt.query}
+		var got []found
+		for {
+			start, end, n, ok := s.next()
+			if !ok {
+				break
+			}
+			got = append(got, found{tt.query[start:end], n})
+		}
+		assert.Equal(t, tt.want, got, tt.query)
+	}
+
+	// MySQL backslash escapes
+	s := placeholderScanner{query: `a = 'x\' ?' AND b = ?`, mysql: true}
+	start, _, _, ok := s.next()
+	require.True(t, ok)
+	assert.Equal(t, len(`a = 'x\' ?' AND b = `), start)
+}
+
+func TestRenumberFragment(t *testing.T) {
+	pg := dialects.GetDialect("postgres")
+
+	assert.Equal(t, "note = 'why?' AND id = $3", renumberFragment("note = 'why?' AND id = ?", 3, 1, pg))
+	assert.Equal(t, "a = ?", renumberFragment("a = ?", 3, 1, dialects.GetDialect("mysql")))
+
+	// Shifting never renumbers a placeholder twice, or $10 as $1.
+	var fragment, want string
+	for i := 1; i <= 11; i++ {
+		fragment += " $" + strconv.Itoa(i)
+		want += " $" + strconv.Itoa(i+9)
+	}
+	assert.Equal(t, want, renumberFragment(fragment, 10, 11, pg))
+
+	// $N inside a literal of a numbered fragment is not a placeholder.
+	assert.Equal(t, "x = '$1' AND y = $5", renumberFragment("x = '$1' AND y = $1", 5, 1, pg))
+}
+
+func TestPlaceholders_StringLiterals(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Select("id").From("faq").
+		Where("question LIKE '%?' AND topic = ?", "billing").
+		AndWhere("id > ?", 10).
+		Build()
+	assert.Equal(t, `SELECT "id" FROM "faq" WHERE question LIKE '%?' AND topic = $1 AND id > $2`, q.SQL())
+	assert.Equal(t, []interface{}{"billing", 10}, q.Params())
+
+	q = qb.Update("faq").Set(map[string]interface{}{"answer": "yes"}).
+		Where("question = 'why?' AND id = ?", 3).
+		Build()
+	assert.Equal(t, `UPDATE "faq" SET "answer" = $1 WHERE question = 'why?' AND id = $2`, q.SQL())
+
+	q = qb.Delete("faq").Where(`"why?" = ? AND id = ?`, true, 3).Build()
+	assert.Equal(t, `DELETE FROM "faq" WHERE "why?" = $1 AND id = $2`, q.SQL())
+}
+
+func TestPlaceholders_UnionShift(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	other := qb.Select("id").From("archive").Where("a = ? AND b = ?", 2, 3)
+	q := qb.Select("id").From("users").Where("c = ?", 1).Union(other).Build()
+
+	assert.Equal(t, `(SELECT "id" FROM "users" WHERE c = $1) UNION (SELECT "id" FROM "archive" WHERE a = $2 AND b = $3)`, q.SQL())
+	assert.Equal(t, []interface{}{1, 2, 3}, q.Params())
+}
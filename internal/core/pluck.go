@@ -0,0 +1,230 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ============================================================================
+// Pluck and ToMap
+// ============================================================================
+//
+// Pluck and ToMap scan columns straight into slices or a map, for lookup
+// tables and ID lists that do not deserve a struct. Both follow the NULL
+// handling of Column (see WithColumnNulls): with ColumnNullsSkip, Pluck
+// leaves out the whole row so the slices stay parallel, and ToMap leaves out
+// the entry.
+
+// Pluck scans the first len(dests) columns of all rows into the slices dests
+// point to, one slice per column, appending in row order.
+//
+// Example:
+//
+//	var ids []int
+//	var names []string
+//	err := db.NewQuery("SELECT id, name FROM users ORDER BY id").Pluck(&ids, &names)
+func (q *Query) Pluck(dests ...interface{}) error {
+	sink, err := newPluckSink(q.db.columnNulls, dests)
+	if err != nil {
+		return err
+	}
+	if err := q.All(sink); err != nil {
+		return err
+	}
+	for _, dest := range dests {
+		q.db.fillEmptySlice(dest)
+	}
+	return nil
+}
+
+// ToMap builds a map from two result columns: keyCol becomes the key and
+// valueCol the value of each entry. dest must point to a map (a nil map is
+// allocated). A later row replaces the entry of an earlier one with the same
+// key.
+//
+// Example:
+//
+//	var names map[int]string
+//	err := db.NewQuery("SELECT id, name FROM users").ToMap(&names, "id", "name")
+func (q *Query) ToMap(dest interface{}, keyCol, valueCol string) error {
+	sink, err := newToMapSink(q.db.columnNulls, dest, keyCol, valueCol)
+	if err != nil {
+		return err
+	}
+	return q.All(sink)
+}
+
+// Pluck scans the first len(dests) selected columns of all rows into the
+// slices dests point to, one slice per column.
+//
+// Example:
+//
+//	var ids []int
+//	var names []string
+//	err := db.Select("id", "name").From("users").OrderBy("id").Pluck(&ids, &names)
+func (sq *SelectQuery) Pluck(dests ...interface{}) error {
+	db := sq.builder.db
+	sink, err := newPluckSink(db.columnNulls, dests)
+	if err != nil {
+		return err
+	}
+	if err := sq.All(sink); err != nil {
+		return err
+	}
+	for _, dest := range dests {
+		db.fillEmptySlice(dest)
+	}
+	return nil
+}
+
+// ToMap builds a map from the result columns keyCol and valueCol (see
+// Query.ToMap).
+//
+// Example:
+//
+//	var prices map[string]float64
+//	err := db.Select("sku", "price").From("products").ToMap(&prices, "sku", "price")
+func (sq *SelectQuery) ToMap(dest interface{}, keyCol, valueCol string) error {
+	sink, err := newToMapSink(sq.builder.db.columnNulls, dest, keyCol, valueCol)
+	if err != nil {
+		return err
+	}
+	return sq.All(sink)
+}
+
+// pluckSink scans the leading columns of each row into parallel slices.
+type pluckSink struct {
+	slices  []reflect.Value
+	scans   []columnScan
+	columns int // number of result columns
+}
+
+func newPluckSink(nulls ColumnNulls, dests []interface{}) (*pluckSink, error) {
+	if len(dests) == 0 {
+		return nil, fmt.Errorf("relica: Pluck() requires at least one destination slice")
+	}
+	s := &pluckSink{}
+	for i, dest := range dests {
+		v := reflect.ValueOf(dest)
+		if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+			return nil, fmt.Errorf("relica: Pluck() destination %d must be a non-nil pointer to a slice, got %T", i, dest)
+		}
+		s.slices = append(s.slices, v.Elem())
+		s.scans = append(s.scans, newColumnScan(v.Elem().Type().Elem(), nulls, nil))
+	}
+	return s, nil
+}
+
+func (s *pluckSink) setColumns(cols []*sql.ColumnType) error {
+	if len(cols) < len(s.slices) {
+		return fmt.Errorf("relica: Pluck() has %d destinations but the result has %d columns", len(s.slices), len(cols))
+	}
+	s.columns = len(cols)
+	return nil
+}
+
+func (s *pluckSink) scanRow(rows *sql.Rows) error {
+	targets := make([]reflect.Value, len(s.scans))
+	dests := make([]interface{}, s.columns)
+	for i := range dests {
+		if i < len(s.scans) {
+			targets[i] = s.scans[i].target()
+			dests[i] = targets[i].Interface()
+		} else {
+			dests[i] = new(sql.RawBytes)
+		}
+	}
+	if err := rows.Scan(dests...); err != nil {
+		return err
+	}
+	for i, cs := range s.scans {
+		if cs.skips(targets[i]) {
+			return nil
+		}
+	}
+	for i, cs := range s.scans {
+		cs.store(targets[i], s.slices[i])
+	}
+	return nil
+}
+
+// toMapSink adds one map entry per row from two columns.
+type toMapSink struct {
+	m                reflect.Value
+	keyCol, valueCol string
+	keyIdx, valueIdx int
+	value            columnScan
+	columns          int
+}
+
+func newToMapSink(nulls ColumnNulls, dest interface{}, keyCol, valueCol string) (*toMapSink, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Map {
+		return nil, fmt.Errorf("relica: ToMap() requires a non-nil pointer to a map, got %T", dest)
+	}
+	m := v.Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	return &toMapSink{
+		m:        m,
+		keyCol:   keyCol,
+		valueCol: valueCol,
+		value:    newColumnScan(m.Type().Elem(), nulls, nil),
+	}, nil
+}
+
+func (s *toMapSink) setColumns(cols []*sql.ColumnType) error {
+	s.columns = len(cols)
+	s.keyIdx, s.valueIdx = -1, -1
+	for i, col := range cols {
+		if s.keyIdx < 0 && resultColumnIs(col.Name(), s.keyCol) {
+			s.keyIdx = i
+		}
+		if s.valueIdx < 0 && resultColumnIs(col.Name(), s.valueCol) {
+			s.valueIdx = i
+		}
+	}
+	switch {
+	case s.keyIdx < 0:
+		return fmt.Errorf("relica: ToMap() key column %q is not in the result", s.keyCol)
+	case s.valueIdx < 0:
+		return fmt.Errorf("relica: ToMap() value column %q is not in the result", s.valueCol)
+	case s.keyIdx == s.valueIdx:
+		return fmt.Errorf("relica: ToMap() requires different key and value columns")
+	}
+	return nil
+}
+
+func (s *toMapSink) scanRow(rows *sql.Rows) error {
+	key := reflect.New(s.m.Type().Key())
+	value := s.value.target()
+	dests := make([]interface{}, s.columns)
+	for i := range dests {
+		switch i {
+		case s.keyIdx:
+			dests[i] = key.Interface()
+		case s.valueIdx:
+			dests[i] = value.Interface()
+		default:
+			dests[i] = new(sql.RawBytes)
+		}
+	}
+	if err := rows.Scan(dests...); err != nil {
+		return err
+	}
+	if s.value.skips(value) {
+		return nil
+	}
+	v, _ := s.value.value(value)
+	s.m.SetMapIndex(key.Elem(), v)
+	return nil
+}
+
+// resultColumnIs reports whether the result column name matches col, which
+// may be qualified ("u.id" matches "id").
+func resultColumnIs(name, col string) bool {
+	return strings.EqualFold(name, col) || strings.EqualFold(name, lastSegment(col))
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluck(t *testing.T) {
+	db := setupNullColumnDB(t)
+
+	var ids []int
+	var names []*string
+	require.NoError(t, db.Builder().Select("id", "name", "score").From("players").OrderBy("id").Pluck(&ids, &names))
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	require.Len(t, names, 3)
+	assert.Equal(t, "ann", *names[0])
+	assert.Nil(t, names[1])
+
+	ids = nil
+	err := db.NewQuery("SELECT id FROM players").Pluck(&ids, &names)
+	assert.ErrorContains(t, err, "Pluck() has 2 destinations but the result has 1 columns")
+	assert.ErrorContains(t, db.NewQuery("SELECT id FROM players").Pluck(ids), "destination 0 must be a non-nil pointer to a slice")
+	assert.ErrorContains(t, db.NewQuery("SELECT id FROM players").Pluck(), "at least one destination")
+
+	var empty []int
+	require.NoError(t, db.Builder().Select("id").From("players").Where(Eq("id", 0)).Pluck(&empty))
+	assert.Nil(t, empty)
+}
+
+func TestPluck_SkipsNullRows(t *testing.T) {
+	db := setupNullColumnDB(t, WithColumnNulls(ColumnNullsSkip))
+
+	var ids []int
+	var names []string
+	require.NoError(t, db.Builder().Select("id", "name").From("players").OrderBy("id").Pluck(&ids, &names))
+	assert.Equal(t, []int{1, 3}, ids, "slices stay parallel")
+	assert.Equal(t, []string{"ann", "cy"}, names)
+
+	var byID map[int]string
+	require.NoError(t, db.Builder().Select("id", "name").From("players").ToMap(&byID, "id", "name"))
+	assert.Equal(t, map[int]string{1: "ann", 3: "cy"}, byID)
+}
+
+func TestToMap(t *testing.T) {
+	db := setupNullColumnDB(t)
+
+	var scores map[string]int64
+	require.NoError(t, db.Builder().Select("p.score", "p.id", "p.name").From("players p").
+		Where(NotEq("id", 2)).ToMap(&scores, "p.name", "score"))
+	assert.Equal(t, map[string]int64{"ann": 10, "cy": 30}, scores)
+
+	byID := map[int]int{99: 1}
+	require.NoError(t, db.NewQuery("SELECT id, score FROM players WHERE score IS NOT NULL").ToMap(&byID, "id", "score"))
+	assert.Equal(t, map[int]int{99: 1, 1: 10, 3: 30}, byID, "entries are added to an existing map")
+
+	var m map[int]string
+	assert.ErrorContains(t, db.NewQuery("SELECT id FROM players").ToMap(&m, "id", "name"), `value column "name" is not in the result`)
+	assert.ErrorContains(t, db.NewQuery("SELECT id FROM players").ToMap(m, "id", "name"), "requires a non-nil pointer to a map")
+	assert.ErrorContains(t, db.NewQuery("SELECT id FROM players").ToMap(&m, "id", "id"), "different key and value columns")
+
+	_, err := db.ExecContext(context.Background(), "INSERT INTO players (id, name, score) VALUES (4, 'ann', 40)")
+	require.NoError(t, err)
+	var latest map[string]int
+	require.NoError(t, db.Builder().Select("name", "score").From("players").Where(NotEq("id", 2)).OrderBy("id").
+		ToMap(&latest, "name", "score"))
+	assert.Equal(t, 40, latest["ann"], "later rows replace earlier entries")
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coregx/relica/internal/cache"
+)
+
+// ============================================================================
+// Named connection pools
+// ============================================================================
+//
+// A named pool is a separate *sql.DB opened with the same driver and DSN, with
+// its own connection limits and statement cache. Routing heavy reporting
+// queries to their own pool keeps them from starving the transactional pool:
+//
+//	reporting, _ := db.Pool("reporting")
+//	reporting.SetMaxOpenConns(4)
+//
+//	db.Builder().Select("region", "SUM(total)").From("orders").
+//	    GroupBy("region").OnPool("reporting").All(&rows)
+
+// Pool is a named connection pool sharing the configuration of its parent DB.
+type Pool struct {
+	name      string
+	parent    *DB
+	sqlDB     *sql.DB
+	stmtCache *cache.StmtCache
+	session   *sessionSettings // inherits the DB's settings (see SetSessionSettings)
+}
+
+// poolRegistry holds the named pools of a DB. It is shared by DB copies
+// (WithContext, pool views), so pools are created once per opened database.
+type poolRegistry struct {
+	mu    sync.Mutex
+	pools map[string]*Pool
+}
+
+// Pool returns the named connection pool, creating it on first use.
+// New pools use database/sql defaults until configured with the Set* methods.
+//
+// Named pools require a DB created with Open or NewDB: the DSN of a *sql.DB
+// passed to WrapDB is unknown, so a second pool cannot be opened.
+func (db *DB) Pool(name string) (*Pool, error) {
+	primary := db.primary()
+	if name == "" {
+		return nil, fmt.Errorf("relica: Pool() requires a non-empty name")
+	}
+	if primary.pools == nil || primary.dsn == "" {
+		return nil, fmt.Errorf("relica: named pools require a DB created with Open")
+	}
+
+	primary.pools.mu.Lock()
+	defer primary.pools.mu.Unlock()
+
+	if p, ok := primary.pools.pools[name]; ok {
+		return p, nil
+	}
+
+	session := &sessionSettings{parent: primary.session}
+	sqlDB, err := openSessionPool(primary.driverName, primary.dsn, primary.dialect, session)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pool{
+		name:      name,
+		parent:    primary,
+		sqlDB:     sqlDB,
+		stmtCache: cache.NewStmtCacheWithShards(primary.stmtCache.Stats().Capacity, primary.stmtShards),
+		session:   session,
+	}
+	primary.pools.pools[name] = p
+	return p, nil
+}
+
+// Name returns the pool name.
+func (p *Pool) Name() string {
+	return p.name
+}
+
+// SetMaxOpenConns sets the maximum number of open connections in this pool.
+func (p *Pool) SetMaxOpenConns(n int) {
+	p.sqlDB.SetMaxOpenConns(n)
+}
+
+// SetMaxIdleConns sets the maximum number of idle connections in this pool.
+func (p *Pool) SetMaxIdleConns(n int) {
+	p.sqlDB.SetMaxIdleConns(n)
+}
+
+// SetConnMaxLifetime sets the maximum amount of time a connection in this pool may be reused.
+func (p *Pool) SetConnMaxLifetime(d time.Duration) {
+	p.sqlDB.SetConnMaxLifetime(d)
+}
+
+// SetConnMaxIdleTime sets the maximum amount of time a connection in this pool may be idle.
+func (p *Pool) SetConnMaxIdleTime(d time.Duration) {
+	p.sqlDB.SetConnMaxIdleTime(d)
+}
+
+// Stats returns connection statistics for this pool.
+// Healthy is always true: health checks run on the primary pool only.
+func (p *Pool) Stats() PoolStats {
+	return p.view(p.parent).Stats()
+}
+
+// view returns a copy of db that executes on this pool.
+func (p *Pool) view(db *DB) *DB {
+	v := *db
+	v.sqlDB = p.sqlDB
+	v.stmtCache = p.stmtCache
+	v.healthChecker = nil
+	v.root = p.parent
+	return &v
+}
+
+// onPool returns a copy of db that executes on the named pool, or the primary
+// pool for an empty name. An unknown name yields a DB whose queries fail with poolErr.
+func (db *DB) onPool(name string) *DB {
+	primary := db.primary()
+	if name == "" {
+		if db.root == nil {
+			return db
+		}
+		v := *primary
+		v.ctx = db.ctx
+		return &v
+	}
+
+	var p *Pool
+	if primary.pools != nil {
+		primary.pools.mu.Lock()
+		p = primary.pools.pools[name]
+		primary.pools.mu.Unlock()
+	}
+	if p == nil {
+		v := *db
+		v.poolErr = fmt.Errorf("relica: unknown connection pool %q (create it with DB.Pool first)", name)
+		return &v
+	}
+
+	v := p.view(primary)
+	v.ctx = db.ctx
+	return v
+}
+
+// primary returns the DB owning the primary pool (db itself unless db is a pool view).
+func (db *DB) primary() *DB {
+	if db.root != nil {
+		return db.root
+	}
+	return db
+}
+
+// stmtCaches returns the statement caches of the primary pool and every named pool.
+func (db *DB) stmtCaches() []*cache.StmtCache {
+	primary := db.primary()
+	caches := []*cache.StmtCache{primary.stmtCache}
+	if primary.pools == nil {
+		return caches
+	}
+	primary.pools.mu.Lock()
+	defer primary.pools.mu.Unlock()
+	for _, p := range primary.pools.pools {
+		caches = append(caches, p.stmtCache)
+	}
+	return caches
+}
+
+// closePools closes every named pool and its statement cache.
+func (db *DB) closePools() error {
+	if db.pools == nil {
+		return nil
+	}
+	db.pools.mu.Lock()
+	defer db.pools.mu.Unlock()
+
+	var firstErr error
+	for name, p := range db.pools.pools {
+		p.stmtCache.Clear()
+		if err := p.sqlDB.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(db.pools.pools, name)
+	}
+	return firstErr
+}
+
+// OnPool routes queries built by this builder to the named connection pool
+// (see DB.Pool). It returns a new builder; the receiver is unchanged.
+// Transactional builders keep using the transaction's connection.
+func (qb *QueryBuilder) OnPool(name string) *QueryBuilder {
+	nb := *qb
+	if qb.tx == nil {
+		nb.db = qb.db.onPool(name)
+	}
+	return &nb
+}
+
+// OnPool routes this query to the named connection pool (see DB.Pool).
+// Ignored inside transactions.
+//
+// Example:
+//
+//	db.Builder().Select("region", "SUM(total)").From("orders").
+//	    GroupBy("region").OnPool("reporting").All(&rows)
+func (sq *SelectQuery) OnPool(name string) *SelectQuery {
+	sq = sq.own()
+	sq.builder = sq.builder.OnPool(name)
+	return sq
+}
+
+// OnPool routes this query to the named connection pool (see DB.Pool).
+// Ignored inside transactions and for statements created with Prepare.
+func (q *Query) OnPool(name string) *Query {
+	if q.db != nil && q.tx == nil && !q.prepared {
+		q.db = q.db.onPool(name)
+	}
+	return q
+}
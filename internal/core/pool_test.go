@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// setupPoolTestDB opens a file-backed SQLite database so every pool sees the same data.
+func setupPoolTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "pool.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE orders (id INTEGER PRIMARY KEY, total INTEGER)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), `INSERT INTO orders (total) VALUES (10), (20)`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestDB_Pool(t *testing.T) {
+	db := setupPoolTestDB(t)
+
+	p, err := db.Pool("reporting")
+	require.NoError(t, err)
+	assert.Equal(t, "reporting", p.Name())
+
+	again, err := db.Pool("reporting")
+	require.NoError(t, err)
+	assert.Same(t, p, again)
+
+	p.SetMaxOpenConns(2)
+	p.SetMaxIdleConns(1)
+	p.SetConnMaxLifetime(time.Minute)
+	p.SetConnMaxIdleTime(time.Minute)
+	assert.Equal(t, 2, p.Stats().MaxOpenConnections)
+	assert.Equal(t, 0, db.Stats().MaxOpenConnections) // primary pool unchanged
+
+	_, err = db.Pool("")
+	assert.Error(t, err)
+}
+
+func TestDB_Pool_WrapDB(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	_, err = WrapDB(sqlDB, "sqlite").Pool("reporting")
+	assert.ErrorContains(t, err, "require a DB created with Open")
+}
+
+func TestOnPool_SelectQuery(t *testing.T) {
+	db := setupPoolTestDB(t)
+	p, err := db.Pool("reporting")
+	require.NoError(t, err)
+
+	var sum int
+	err = db.Builder().Select("SUM(total)").From("orders").OnPool("reporting").Row(&sum)
+	require.NoError(t, err)
+	assert.Equal(t, 30, sum)
+
+	// Statement was prepared on the reporting pool only.
+	assert.Equal(t, 1, p.stmtCache.Stats().Size)
+	assert.Equal(t, 0, db.StmtCacheStats().Size)
+	assert.Positive(t, p.Stats().OpenConnections)
+}
+
+func TestOnPool_QueryBuilderAndQuery(t *testing.T) {
+	db := setupPoolTestDB(t)
+	p, err := db.Pool("reporting")
+	require.NoError(t, err)
+
+	qb := db.Builder().OnPool("reporting")
+	var count int
+	require.NoError(t, qb.Select("COUNT(*)").From("orders").Row(&count))
+	assert.Equal(t, 2, count)
+
+	var total int
+	require.NoError(t, db.NewQuery(`SELECT total FROM orders WHERE id = 1`).OnPool("reporting").Row(&total))
+	assert.Equal(t, 10, total)
+	assert.Equal(t, 2, p.stmtCache.Stats().Size)
+
+	// The original builder still uses the primary pool.
+	require.NoError(t, db.Builder().Select("COUNT(*)").From("orders").Row(&count))
+	assert.Equal(t, 1, db.StmtCacheStats().Size)
+
+	// Empty name selects the primary pool.
+	require.NoError(t, qb.OnPool("").Select("COUNT(*)").From("orders").Row(&count))
+	assert.Equal(t, 2, p.stmtCache.Stats().Size)
+}
+
+func TestOnPool_UnknownPool(t *testing.T) {
+	db := setupPoolTestDB(t)
+
+	var count int
+	err := db.Builder().Select("COUNT(*)").From("orders").OnPool("missing").Row(&count)
+	assert.ErrorContains(t, err, `unknown connection pool "missing"`)
+
+	_, err = db.NewQuery(`DELETE FROM orders`).OnPool("missing").Execute()
+	assert.ErrorContains(t, err, `unknown connection pool "missing"`)
+}
+
+func TestOnPool_IgnoredInTransaction(t *testing.T) {
+	db := setupPoolTestDB(t)
+	p, err := db.Pool("reporting")
+	require.NoError(t, err)
+
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	var count int
+	require.NoError(t, tx.Builder().Select("COUNT(*)").From("orders").OnPool("reporting").Row(&count))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 0, p.stmtCache.Stats().Size)
+}
+
+func TestPool_InvalidateAndClose(t *testing.T) {
+	db := setupPoolTestDB(t)
+	p, err := db.Pool("reporting")
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, db.Builder().Select("COUNT(*)").From("orders").OnPool("reporting").Row(&count))
+	require.Equal(t, 1, p.stmtCache.Stats().Size)
+
+	// DDL on the primary pool invalidates statements cached by named pools.
+	_, err = db.ExecContext(context.Background(), `ALTER TABLE orders ADD COLUMN note TEXT`)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p.stmtCache.Stats().Size)
+
+	require.NoError(t, db.Close())
+	assert.Error(t, p.sqlDB.Ping())
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// =============================================================================
+// Quantified subquery comparisons (ANY / ALL)
+// =============================================================================
+//
+// EqAny, GtAll and the other helpers compare a column with every row of a
+// single-column subquery: "col > ALL (SELECT ...)" is true if col is greater
+// than each row, "col = ANY (SELECT ...)" if it equals at least one. SOME is
+// the SQL synonym of ANY and is rendered as ANY.
+//
+// PostgreSQL and MySQL support all operators. SQLite has no quantified
+// comparisons: "= ANY" is rendered as IN and "<> ALL" as NOT IN, which are
+// equivalent; the other combinations are rejected with a build error by
+// the Where and OrWhere methods of SELECT, UPDATE and DELETE queries.
+
+// QuantifiedExp compares a column with the rows of a subquery using ANY or ALL.
+type QuantifiedExp struct {
+	col        string
+	operator   string // =, <>, >, >=, <, <=
+	quantifier string // ANY or ALL
+	sub        *SelectQuery
+}
+
+// EqAny generates "col = ANY (subquery)", true if col equals any row.
+//
+// Example:
+//
+//	vip := db.Builder().Select("user_id").From("vip_members").Where(relica.Eq("tier", "gold"))
+//	db.Builder().Select().From("orders").Where(relica.EqAny("user_id", vip))
+//	// PostgreSQL: SELECT * FROM "orders" WHERE "user_id" = ANY (SELECT "user_id" FROM "vip_members" WHERE "tier" = $1)
+//	// SQLite:     SELECT * FROM "orders" WHERE "user_id" IN (SELECT "user_id" FROM "vip_members" WHERE "tier" = ?)
+func EqAny(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "=", "ANY", sub) }
+
+// NotEqAny generates "col <> ANY (subquery)", true if col differs from any row.
+func NotEqAny(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "<>", "ANY", sub) }
+
+// GtAny generates "col > ANY (subquery)", true if col is greater than any row.
+func GtAny(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, ">", "ANY", sub) }
+
+// GteAny generates "col >= ANY (subquery)".
+func GteAny(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, ">=", "ANY", sub) }
+
+// LtAny generates "col < ANY (subquery)", true if col is less than any row.
+func LtAny(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "<", "ANY", sub) }
+
+// LteAny generates "col <= ANY (subquery)".
+func LteAny(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "<=", "ANY", sub) }
+
+// EqAll generates "col = ALL (subquery)", true if col equals every row.
+func EqAll(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "=", "ALL", sub) }
+
+// NotEqAll generates "col <> ALL (subquery)", true if col differs from every
+// row (equivalent to NOT IN).
+func NotEqAll(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "<>", "ALL", sub) }
+
+// GtAll generates "col > ALL (subquery)", true if col is greater than every row.
+//
+// Example:
+//
+//	rivals := db.Builder().Select("score").From("players").Where(relica.Eq("team", "red"))
+//	db.Builder().Select("name").From("players").Where(relica.GtAll("score", rivals))
+//	// SELECT "name" FROM "players" WHERE "score" > ALL (SELECT "score" FROM "players" WHERE "team" = $1)
+func GtAll(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, ">", "ALL", sub) }
+
+// GteAll generates "col >= ALL (subquery)".
+func GteAll(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, ">=", "ALL", sub) }
+
+// LtAll generates "col < ALL (subquery)", true if col is less than every row.
+func LtAll(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "<", "ALL", sub) }
+
+// LteAll generates "col <= ALL (subquery)".
+func LteAll(col string, sub *SelectQuery) *QuantifiedExp { return quantified(col, "<=", "ALL", sub) }
+
+func quantified(col, operator, quantifier string, sub *SelectQuery) *QuantifiedExp {
+	return &QuantifiedExp{col: col, operator: operator, quantifier: quantifier, sub: sub}
+}
+
+// checkDialect implements checkedExpression.
+func (e *QuantifiedExp) checkDialect(dialect dialects.Dialect) error {
+	if e.sub == nil {
+		return fmt.Errorf("relica: %s %s subquery is nil", e.operator, e.quantifier)
+	}
+	if e.sub.buildErr != nil {
+		return e.sub.buildErr
+	}
+	if _, ok := dialect.(*dialects.SQLiteDialect); ok && e.sqliteOperator() == "" {
+		return fmt.Errorf("relica: %s %s (subquery) is not supported on sqlite; only = ANY and <> ALL are", e.operator, e.quantifier)
+	}
+	return nil
+}
+
+// sqliteOperator returns the SQLite equivalent of the comparison, if any.
+func (e *QuantifiedExp) sqliteOperator() string {
+	switch {
+	case e.operator == "=" && e.quantifier == "ANY":
+		return sqlIn
+	case e.operator == "<>" && e.quantifier == "ALL":
+		return sqlNotIn
+	default:
+		return ""
+	}
+}
+
+// Build implements the Expression interface.
+// Unsupported SQLite combinations get the standard form; builder methods reject them first.
+func (e *QuantifiedExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	col := quoteColumn(e.col, dialect)
+	if e.sub == nil {
+		return col + " " + e.operator + " " + e.quantifier + " (SELECT NULL)", nil
+	}
+	subSQL, args := e.sub.fragmentSQL(dialect)
+
+	op := e.operator + " " + e.quantifier
+	if _, ok := dialect.(*dialects.SQLiteDialect); ok {
+		if in := e.sqliteOperator(); in != "" {
+			op = in
+		}
+	}
+	return col + " " + op + " (" + subSQL + ")", args
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 COREGX. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantified_PostgreSQL(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	rivals := qb.Select("score").From("players").Where(Eq("team", "red"))
+	q := qb.Select("name").From("players").
+		Where(Eq("active", true)).
+		Where(GtAll("score", rivals)).
+		OrWhere(EqAny("id", qb.Select("player_id").From("awards").Where(Eq("year", 2024)))).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `SELECT "name" FROM "players" WHERE ("active" = $1 AND "score" > ALL (SELECT "score" FROM "players" WHERE "team" = $2)) OR ("id" = ANY (SELECT "player_id" FROM "awards" WHERE "year" = $3))`, q.sql)
+	assert.Equal(t, []interface{}{true, "red", 2024}, q.params)
+}
+
+func TestQuantified_Operators(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("mysql")}
+	sub := qb.Select("x").From("t")
+
+	tests := []struct {
+		exp  *QuantifiedExp
+		want string
+	}{
+		{EqAny("c", sub), "`c` = ANY (SELECT `x` FROM `t`)"},
+		{NotEqAny("c", sub), "`c` <> ANY (SELECT `x` FROM `t`)"},
+		{GtAny("c", sub), "`c` > ANY (SELECT `x` FROM `t`)"},
+		{GteAny("c", sub), "`c` >= ANY (SELECT `x` FROM `t`)"},
+		{LtAny("c", sub), "`c` < ANY (SELECT `x` FROM `t`)"},
+		{LteAny("c", sub), "`c` <= ANY (SELECT `x` FROM `t`)"},
+		{EqAll("c", sub), "`c` = ALL (SELECT `x` FROM `t`)"},
+		{NotEqAll("c", sub), "`c` <> ALL (SELECT `x` FROM `t`)"},
+		{GtAll("c", sub), "`c` > ALL (SELECT `x` FROM `t`)"},
+		{GteAll("c", sub), "`c` >= ALL (SELECT `x` FROM `t`)"},
+		{LtAll("c", sub), "`c` < ALL (SELECT `x` FROM `t`)"},
+		{LteAll("c", sub), "`c` <= ALL (SELECT `x` FROM `t`)"},
+	}
+	for _, tt := range tests {
+		sql, args := tt.exp.Build(qb.db.dialect)
+		assert.Equal(t, tt.want, sql)
+		assert.Empty(t, args)
+	}
+}
+
+func TestQuantified_SQLiteFallback(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+	sub := qb.Select("user_id").From("bans").Where(Eq("reason", "spam"))
+
+	q := qb.Select("id").From("users").Where(EqAny("id", sub)).Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE "id" IN (SELECT "user_id" FROM "bans" WHERE "reason" = ?)`, q.sql)
+	assert.Equal(t, []interface{}{"spam"}, q.params)
+
+	q = qb.Select("id").From("users").Where(NotEqAll("id", sub)).Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE "id" NOT IN (SELECT "user_id" FROM "bans" WHERE "reason" = ?)`, q.sql)
+
+	q = qb.Select("id").From("users").Where(GtAll("id", sub)).Build()
+	assert.EqualError(t, q.prepErr, "relica: > ALL (subquery) is not supported on sqlite; only = ANY and <> ALL are")
+}
+
+func TestQuantified_Errors(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Select("id").From("users").Where(EqAny("id", nil)).Build()
+	assert.EqualError(t, q.prepErr, "relica: = ANY subquery is nil")
+
+	bad := qb.Select("id").From("orders").SelectSub(Eq("a", 1), "")
+	q = qb.Select("id").From("users").Where(LtAll("id", bad)).Build()
+	assert.EqualError(t, q.prepErr, "relica: SelectSub requires a non-empty alias")
+}
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
+
+	"github.com/coregx/relica/internal/security"
 )
 
 // Query represents a database query.
@@ -16,9 +19,15 @@ type Query struct {
 	db       *DB
 	tx       *sql.Tx // nil for non-transactional queries
 	ctx      context.Context
-	stmt     *sql.Stmt // manually prepared statement (bypasses cache)
-	prepared bool      // true if Prepare() was called
-	prepErr  error     // error from Prepare() call
+	stmt     *sql.Stmt   // manually prepared statement (bypasses cache)
+	prepared bool        // true if Prepare() was called
+	prepErr  error       // error from Prepare() call
+	tag      string      // observability tag (see Tag)
+	change   *changeInfo // change data capture (see WithChangeSink); nil for reads and raw SQL
+	refs     *schemaRefs // referenced tables and columns (see WithStrictSchema); nil when disabled
+	allRows  bool        // affects every row on purpose (Truncate); exempt from LintWriteWithoutWhere
+
+	idempotent bool // safe to retry on transient errors (see Idempotent)
 }
 
 // appendSQL appends a suffix to the SQL query.
@@ -53,9 +62,12 @@ func (q *Query) Prepare() *Query {
 	var stmt *sql.Stmt
 	var err error
 
-	if q.tx != nil {
+	switch {
+	case q.tx != nil:
 		stmt, err = q.tx.PrepareContext(ctx, q.sql)
-	} else {
+	case q.db.pinned != nil:
+		stmt, err = q.db.pinned.PrepareContext(ctx, q.sql)
+	default:
 		stmt, err = q.db.sqlDB.PrepareContext(ctx, q.sql)
 	}
 
@@ -111,17 +123,78 @@ func (q *Query) prepareStatement(ctx context.Context) (*sql.Stmt, error) {
 		return q.stmt, nil
 	}
 
-	// Use statement cache for non-transactional queries
-	if stmt, ok := q.db.stmtCache.Get(q.sql); ok {
+	// Use statement cache for non-transactional queries.
+	// Only static comments reach this point, so the commented SQL is stable.
+	query, _ := q.commentedSQL(ctx)
+	if stmt, ok := q.db.stmtCache.Get(query); ok {
 		return stmt, nil
 	}
 
-	stmt, err := q.db.sqlDB.PrepareContext(ctx, q.sql)
+	stmt, err := q.db.sqlDB.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	q.db.stmtCache.Set(q.sql, stmt)
-	return stmt, nil
+	// A preparation that finished as its context was canceled is not cached
+	if err := ctx.Err(); err != nil {
+		_ = stmt.Close()
+		return nil, err
+	}
+	// Another query may have cached the statement meanwhile; keep that one
+	// rather than closing it under its feet
+	return q.db.stmtCache.SetIfAbsent(query, stmt), nil
+}
+
+// runStmt calls fn with stmt, a statement returned by prepareStatement, and
+// keeps broken statements out of the cache. A stale statement (closed by an
+// eviction while this query held it, or no longer known to the server) is
+// evicted and fn runs once more with a freshly prepared, uncached statement;
+// a stale statement never executed, so this is safe for writes. A statement
+// whose connection failed is evicted so the next query prepares it again.
+// Errors of a canceled context leave the cache alone: the statement itself
+// is fine.
+func (q *Query) runStmt(ctx context.Context, stmt *sql.Stmt, fn func(*sql.Stmt) error) error {
+	err := fn(stmt)
+	if err == nil || q.prepared || ctx.Err() != nil {
+		return err
+	}
+
+	query, _ := q.commentedSQL(ctx)
+	switch {
+	case isStaleStmt(err):
+		q.db.stmtCache.Invalidate(query, stmt)
+		// A statement of its own cannot be evicted again under heavy churn;
+		// closing it waits for rows still reading from it
+		fresh, prepErr := q.db.sqlDB.PrepareContext(ctx, query)
+		if prepErr != nil {
+			return err
+		}
+		defer fresh.Close()
+		return fn(fresh)
+	case IsTransientError(err):
+		q.db.stmtCache.Invalidate(query, stmt)
+	}
+	return err
+}
+
+// staleStmtMessages are driver error messages of statements that cannot
+// execute anymore and must be prepared again.
+var staleStmtMessages = []string{
+	"sql: statement is closed",           // database/sql: closed by a cache eviction
+	"unknown prepared statement handler", // MySQL 1243: server forgot the statement
+}
+
+// isStaleStmt reports whether err means the prepared statement is gone:
+// closed locally, or deallocated on the server (PostgreSQL SQLSTATE 26000,
+// for example after DISCARD ALL by a connection pooler).
+func isStaleStmt(err error) bool {
+	if sqlState(err) == "26000" {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "prepared statement") && strings.Contains(msg, "does not exist") {
+		return true
+	}
+	return containsAny(msg, staleStmtMessages)
 }
 
 // logExecutionResult logs query execution results if logger is enabled.
@@ -133,13 +206,7 @@ func (q *Query) logExecutionResult(result sql.Result, err error, elapsed time.Du
 	maskedParams := q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params))
 
 	if err != nil {
-		q.db.logger.Error("query execution failed",
-			"sql", q.sql,
-			"params", maskedParams,
-			"duration_ms", elapsed.Milliseconds(),
-			"database", q.db.driverName,
-			"error", err,
-		)
+		q.logFailure("query execution failed", err, "duration_ms", elapsed.Milliseconds(), "database", q.db.driverName)
 		return
 	}
 
@@ -147,35 +214,57 @@ func (q *Query) logExecutionResult(result sql.Result, err error, elapsed time.Du
 	if result != nil {
 		rowsAffected, _ = result.RowsAffected()
 	}
-	q.db.logger.Info("query executed",
+	args := []interface{}{
 		"sql", q.sql,
 		"params", maskedParams,
 		"duration_ms", elapsed.Milliseconds(),
 		"rows_affected", rowsAffected,
 		"database", q.db.driverName,
-	)
-}
-
-// useDirectTx returns true when the query should use direct tx.Exec/Query
-// instead of Prepare+Exec (saves 2 round-trips per query).
-func (q *Query) useDirectTx() bool {
-	return q.tx != nil && !q.prepared
+	}
+	if q.tag != "" {
+		args = append(args, "tag", q.tag)
+	}
+	q.db.logger.Info("query executed", args...)
 }
 
 // getContext returns the query context, defaulting to context.Background().
 func (q *Query) getContext() context.Context {
-	if q.ctx != nil {
-		return q.ctx
+	ctx := q.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if q.tag != "" {
+		ctx = security.WithQueryTag(ctx, q.tag)
 	}
-	return context.Background()
+	return ctx
 }
 
 // validateBeforeExec runs validator and checks for build errors.
+// It also converts time parameters to UTC if WithUTCTimes is enabled.
 // Returns error if validation fails, nil otherwise.
 func (q *Query) validateBeforeExec(ctx context.Context) error {
 	if q.prepErr != nil {
 		return q.prepErr
 	}
+	if q.db != nil && q.db.poolErr != nil {
+		return q.db.poolErr
+	}
+	if q.refs != nil && q.db.catalog != nil {
+		if err := q.checkSchema(ctx); err != nil {
+			return err
+		}
+	}
+	if q.db != nil && q.db.linter != nil {
+		if err := q.db.linter.check(ctx, q); err != nil {
+			return err
+		}
+	}
+	if q.db != nil && q.db.utcTimes {
+		q.params = utcTimeParams(q.params)
+	}
+	if err := validateEnumParams(q.params); err != nil {
+		return err
+	}
 	if q.db != nil && q.db.validator != nil {
 		return q.db.validateQueryAndParams(ctx, q.sql, q.params)
 	}
@@ -186,31 +275,51 @@ func (q *Query) validateBeforeExec(ctx context.Context) error {
 // For transactions, uses direct tx.ExecContext (1 round-trip).
 // For non-tx queries, uses prepared statement cache.
 func (q *Query) Execute() (sql.Result, error) {
-	ctx := q.getContext()
+	ctx, span := q.startSpan()
+	ctx, finish := q.watch(ctx)
+	result, err := q.execute(ctx)
+	err = classifyError(finish(err))
+	span.end(err, result)
+	return result, err
+}
+
+// execute implements Execute with the context of the query span.
+func (q *Query) execute(ctx context.Context) (sql.Result, error) {
 	start := time.Now()
 
 	// Validate
 	if err := q.validateBeforeExec(ctx); err != nil {
 		if q.db.logger != nil {
-			q.db.logger.Error("query preparation failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"error", err,
-			)
+			q.logFailure("query preparation failed", err)
 		}
 		return nil, err
 	}
 
-	// Direct execution for transactions (1 round-trip, no Prepare overhead)
-	if q.useDirectTx() {
-		result, err := q.tx.ExecContext(ctx, q.sql, q.params...)
+	// Wait for a concurrency slot (see WithMaxConcurrentQueries)
+	release, slotErr := q.acquireSlot(ctx)
+	if slotErr != nil {
+		return nil, slotErr
+	}
+	defer release()
+	start = time.Now() // exclude queue wait from query duration
+
+	// Direct execution for transactions and per-request SQL comments (no Prepare overhead)
+	if conn, query, ok := q.directConn(ctx); ok {
+		var result sql.Result
+		err := q.withRetry(ctx, func() (err error) {
+			result, err = conn.ExecContext(ctx, query, q.params...)
+			return err
+		})
 		elapsed := time.Since(start)
+		if err == nil {
+			q.db.invalidateAfterDDL(q.sql)
+		}
 		q.logExecutionResult(result, err, elapsed)
 		var rowsAffected int64
 		if result != nil {
 			rowsAffected, _ = result.RowsAffected()
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:          q.sql,
 			Args:         q.params,
 			Duration:     elapsed,
@@ -218,6 +327,9 @@ func (q *Query) Execute() (sql.Result, error) {
 			Error:        err,
 			Operation:    DetectOperation(q.sql),
 		})
+		if err == nil && q.change != nil {
+			q.publishChange(ctx, result)
+		}
 		return result, err
 	}
 
@@ -225,17 +337,22 @@ func (q *Query) Execute() (sql.Result, error) {
 	stmt, err := q.prepareStatement(ctx)
 	if err != nil {
 		if q.db.logger != nil {
-			q.db.logger.Error("query preparation failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"error", err,
-			)
+			q.logFailure("query preparation failed", err)
 		}
 		return nil, err
 	}
 
-	result, err := stmt.ExecContext(ctx, q.params...)
+	var result sql.Result
+	err = q.runStmt(ctx, stmt, func(stmt *sql.Stmt) error {
+		return q.withRetry(ctx, func() (err error) {
+			result, err = stmt.ExecContext(ctx, q.params...)
+			return err
+		})
+	})
 	elapsed := time.Since(start)
+	if err == nil {
+		q.db.invalidateAfterDDL(q.sql)
+	}
 
 	q.logExecutionResult(result, err, elapsed)
 
@@ -243,7 +360,7 @@ func (q *Query) Execute() (sql.Result, error) {
 	if result != nil {
 		rowsAffected, _ = result.RowsAffected()
 	}
-	q.db.invokeHook(ctx, QueryEvent{
+	q.emit(ctx, QueryEvent{
 		SQL:          q.sql,
 		Args:         q.params,
 		Duration:     elapsed,
@@ -251,60 +368,74 @@ func (q *Query) Execute() (sql.Result, error) {
 		Error:        err,
 		Operation:    DetectOperation(q.sql),
 	})
+	if err == nil && q.change != nil {
+		q.publishChange(ctx, result)
+	}
 
 	return result, err
 }
 
 // One fetches a single row into a struct.
 // If query is part of a transaction, uses transaction connection.
+func (q *Query) One(dest interface{}) error {
+	ctx, span := q.startSpan()
+	ctx, finish := q.watch(ctx)
+	err := classifyError(finish(q.one(ctx, dest)))
+	span.end(err, nil)
+	return err
+}
+
+// one implements One with the context of the query span.
 //
 //nolint:cyclop,funlen,gocognit,nestif // Query execution requires comprehensive error handling and logging
-func (q *Query) One(dest interface{}) error {
-	ctx := q.getContext()
+func (q *Query) one(ctx context.Context, dest interface{}) error {
 	start := time.Now()
 
 	if err := q.validateBeforeExec(ctx); err != nil {
 		if q.db.logger != nil {
-			q.db.logger.Error("query preparation failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"error", err,
-			)
+			q.logFailure("query preparation failed", err)
 		}
 		return err
 	}
 
+	// Wait for a concurrency slot (see WithMaxConcurrentQueries)
+	release, slotErr := q.acquireSlot(ctx)
+	if slotErr != nil {
+		return slotErr
+	}
+	defer release()
+	start = time.Now() // exclude queue wait from query duration
+
 	// Execute query — direct for tx, prepared for non-tx
 	var rows *sql.Rows
 	var err error
-	if q.useDirectTx() {
-		rows, err = q.tx.QueryContext(ctx, q.sql, q.params...)
+	if conn, query, ok := q.directConn(ctx); ok {
+		err = q.withRetry(ctx, func() (err error) {
+			rows, err = conn.QueryContext(ctx, query, q.params...)
+			return err
+		})
 	} else {
 		var stmt *sql.Stmt
 		stmt, err = q.prepareStatement(ctx)
 		if err != nil {
 			if q.db.logger != nil {
-				q.db.logger.Error("query preparation failed",
-					"sql", q.sql,
-					"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-					"error", err,
-				)
+				q.logFailure("query preparation failed", err)
 			}
 			return err
 		}
-		rows, err = stmt.QueryContext(ctx, q.params...)
+		err = q.runStmt(ctx, stmt, func(stmt *sql.Stmt) error {
+			return q.withRetry(ctx, func() (err error) {
+				rows, err = stmt.QueryContext(ctx, q.params...)
+				return err
+			})
+		})
 	}
 	if err != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("query execution failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", err,
-			)
+			q.logFailure("query execution failed", err, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -326,7 +457,7 @@ func (q *Query) One(dest interface{}) error {
 				"duration_ms", elapsed.Milliseconds(),
 			)
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -336,9 +467,11 @@ func (q *Query) One(dest interface{}) error {
 		return err
 	}
 
-	// Scan into dest - detect NullStringMap for dynamic scanning
+	// Scan into dest - detect row mappers and NullStringMap for dynamic scanning
 	var scanErr error
-	if destMap, ok := dest.(*NullStringMap); ok {
+	if sink, ok := dest.(rowSink); ok {
+		scanErr = sink.scanRow(rows)
+	} else if destMap, ok := dest.(*NullStringMap); ok {
 		scanErr = globalScanner.scanMapRow(rows, destMap)
 	} else {
 		scanErr = globalScanner.scanRow(rows, dest)
@@ -346,14 +479,9 @@ func (q *Query) One(dest interface{}) error {
 	if scanErr != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("row scanning failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", scanErr,
-			)
+			q.logFailure("row scanning failed", scanErr, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -377,16 +505,19 @@ func (q *Query) One(dest interface{}) error {
 	}
 
 	// Invoke query hook
-	q.db.invokeHook(ctx, QueryEvent{
+	q.emit(ctx, QueryEvent{
 		SQL:       q.sql,
 		Args:      q.params,
 		Duration:  elapsed,
 		Operation: DetectOperation(q.sql),
 	})
+	if q.change != nil {
+		q.publishReturning(ctx, dest)
+	}
 
 	// Analyze query performance if optimizer is enabled (async to not block)
-	if q.db.optimizer != nil {
-		go q.analyzeQuery(ctx, elapsed)
+	if q.db.optimizer != nil && q.db.analyzed.claim(q.sql) {
+		q.db.drain.goBackground(func() { q.analyzeQuery(ctx, elapsed) })
 	}
 
 	return nil
@@ -404,54 +535,65 @@ func (q *Query) One(dest interface{}) error {
 //	// For scalar queries
 //	var count int
 //	err := db.NewQuery("SELECT COUNT(*) FROM users").Row(&count)
+func (q *Query) Row(dest ...interface{}) error {
+	ctx, span := q.startSpan()
+	ctx, finish := q.watch(ctx)
+	err := classifyError(finish(q.row(ctx, dest...)))
+	span.end(err, nil)
+	return err
+}
+
+// row implements Row with the context of the query span.
 //
 //nolint:cyclop,funlen,nestif // Query execution requires comprehensive error handling and logging
-func (q *Query) Row(dest ...interface{}) error {
-	ctx := q.getContext()
+func (q *Query) row(ctx context.Context, dest ...interface{}) error {
 	start := time.Now()
 
 	if err := q.validateBeforeExec(ctx); err != nil {
 		if q.db.logger != nil {
-			q.db.logger.Error("query preparation failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"error", err,
-			)
+			q.logFailure("query preparation failed", err)
 		}
 		return err
 	}
 
+	// Wait for a concurrency slot (see WithMaxConcurrentQueries)
+	release, slotErr := q.acquireSlot(ctx)
+	if slotErr != nil {
+		return slotErr
+	}
+	defer release()
+	start = time.Now() // exclude queue wait from query duration
+
 	// Execute query — direct for tx, prepared for non-tx
 	var rows *sql.Rows
 	var err error
-	if q.useDirectTx() {
-		rows, err = q.tx.QueryContext(ctx, q.sql, q.params...)
+	if conn, query, ok := q.directConn(ctx); ok {
+		err = q.withRetry(ctx, func() (err error) {
+			rows, err = conn.QueryContext(ctx, query, q.params...)
+			return err
+		})
 	} else {
 		var stmt *sql.Stmt
 		stmt, err = q.prepareStatement(ctx)
 		if err != nil {
 			if q.db.logger != nil {
-				q.db.logger.Error("query preparation failed",
-					"sql", q.sql,
-					"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-					"error", err,
-				)
+				q.logFailure("query preparation failed", err)
 			}
 			return err
 		}
-		rows, err = stmt.QueryContext(ctx, q.params...)
+		err = q.runStmt(ctx, stmt, func(stmt *sql.Stmt) error {
+			return q.withRetry(ctx, func() (err error) {
+				rows, err = stmt.QueryContext(ctx, q.params...)
+				return err
+			})
+		})
 	}
 	if err != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("query execution failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", err,
-			)
+			q.logFailure("query execution failed", err, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -476,7 +618,7 @@ func (q *Query) Row(dest ...interface{}) error {
 				"duration_ms", elapsed.Milliseconds(),
 			)
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -490,14 +632,9 @@ func (q *Query) Row(dest ...interface{}) error {
 	if err := rows.Scan(dest...); err != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("row scanning failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", err,
-			)
+			q.logFailure("row scanning failed", err, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -521,43 +658,67 @@ func (q *Query) Row(dest ...interface{}) error {
 	}
 
 	// Invoke query hook
-	q.db.invokeHook(ctx, QueryEvent{
+	q.emit(ctx, QueryEvent{
 		SQL:       q.sql,
 		Args:      q.params,
 		Duration:  elapsed,
 		Operation: DetectOperation(q.sql),
 	})
+	if q.change != nil {
+		var first interface{}
+		if len(dest) == 1 {
+			first = dest[0]
+		}
+		q.publishReturning(ctx, first)
+	}
 
 	return nil
 }
 
 // Column scans the first column of all rows into a slice.
 // The slice parameter must be a pointer to a slice of the appropriate type.
+// Use pointer or sql.Null* elements for nullable columns, or configure NULL
+// handling for other element types with WithColumnNulls.
 //
 // Example:
 //
 //	var ids []int
 //	err := db.Select("id").From("users").Where("status = ?", "active").Column(&ids)
 //
-//	var emails []string
+//	var emails []sql.NullString
 //	err := db.Select("email").From("users").Column(&emails)
+func (q *Query) Column(slice interface{}) error {
+	ctx, span := q.startSpan()
+	ctx, finish := q.watch(ctx)
+	err := classifyError(finish(q.column(ctx, slice, q.db.columnNulls, nil)))
+	if err == nil {
+		q.db.fillEmptySlice(slice)
+	}
+	span.end(err, nil)
+	return err
+}
+
+// column implements Column with the context of the query span.
 //
 //nolint:gocognit,gocyclo,cyclop,funlen,nestif // Query execution requires comprehensive error handling and logging
-func (q *Query) Column(slice interface{}) error {
-	ctx := q.getContext()
+func (q *Query) column(ctx context.Context, slice interface{}, nulls ColumnNulls, valid *[]bool) error {
 	start := time.Now()
 
 	if err := q.validateBeforeExec(ctx); err != nil {
 		if q.db.logger != nil {
-			q.db.logger.Error("query preparation failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"error", err,
-			)
+			q.logFailure("query preparation failed", err)
 		}
 		return err
 	}
 
+	// Wait for a concurrency slot (see WithMaxConcurrentQueries)
+	release, slotErr := q.acquireSlot(ctx)
+	if slotErr != nil {
+		return slotErr
+	}
+	defer release()
+	start = time.Now() // exclude queue wait from query duration
+
 	// Validate slice parameter
 	sliceVal := reflect.ValueOf(slice)
 	if sliceVal.Kind() != reflect.Pointer || sliceVal.IsNil() {
@@ -569,39 +730,38 @@ func (q *Query) Column(slice interface{}) error {
 		return fmt.Errorf("relica: Column() requires a pointer to a slice, got pointer to %s", sliceVal.Kind())
 	}
 
-	elemType := sliceVal.Type().Elem()
+	cs := newColumnScan(sliceVal.Type().Elem(), nulls, valid)
 
 	// Execute query — direct for tx, prepared for non-tx
 	var rows *sql.Rows
 	var err error
-	if q.useDirectTx() {
-		rows, err = q.tx.QueryContext(ctx, q.sql, q.params...)
+	if conn, query, ok := q.directConn(ctx); ok {
+		err = q.withRetry(ctx, func() (err error) {
+			rows, err = conn.QueryContext(ctx, query, q.params...)
+			return err
+		})
 	} else {
 		var stmt *sql.Stmt
 		stmt, err = q.prepareStatement(ctx)
 		if err != nil {
 			if q.db.logger != nil {
-				q.db.logger.Error("query preparation failed",
-					"sql", q.sql,
-					"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-					"error", err,
-				)
+				q.logFailure("query preparation failed", err)
 			}
 			return err
 		}
-		rows, err = stmt.QueryContext(ctx, q.params...)
+		err = q.runStmt(ctx, stmt, func(stmt *sql.Stmt) error {
+			return q.withRetry(ctx, func() (err error) {
+				rows, err = stmt.QueryContext(ctx, q.params...)
+				return err
+			})
+		})
 	}
 	if err != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("query execution failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", err,
-			)
+			q.logFailure("query execution failed", err, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -615,22 +775,13 @@ func (q *Query) Column(slice interface{}) error {
 	// Scan all rows into slice
 	rowCount := 0
 	for rows.Next() {
-		// Create a new element for this row
-		elem := reflect.New(elemType)
-
-		// Scan first column into element
-		if err := rows.Scan(elem.Interface()); err != nil {
+		// Scan first column into a new element and append it to the slice
+		if err := cs.scan(rows, sliceVal); err != nil {
 			elapsed := time.Since(start)
 			if q.db.logger != nil {
-				q.db.logger.Error("column scanning failed",
-					"sql", q.sql,
-					"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-					"duration_ms", elapsed.Milliseconds(),
-					"row", rowCount,
-					"error", err,
-				)
+				q.logFailure("column scanning failed", err, "duration_ms", elapsed.Milliseconds(), "row", rowCount)
 			}
-			q.db.invokeHook(ctx, QueryEvent{
+			q.emit(ctx, QueryEvent{
 				SQL:       q.sql,
 				Args:      q.params,
 				Duration:  elapsed,
@@ -640,8 +791,6 @@ func (q *Query) Column(slice interface{}) error {
 			return err
 		}
 
-		// Append dereferenced value to slice
-		sliceVal.Set(reflect.Append(sliceVal, elem.Elem()))
 		rowCount++
 	}
 
@@ -649,14 +798,9 @@ func (q *Query) Column(slice interface{}) error {
 	if err := rows.Err(); err != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("row iteration failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", err,
-			)
+			q.logFailure("row iteration failed", err, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -680,66 +824,83 @@ func (q *Query) Column(slice interface{}) error {
 	}
 
 	// Invoke query hook
-	q.db.invokeHook(ctx, QueryEvent{
+	q.emit(ctx, QueryEvent{
 		SQL:       q.sql,
 		Args:      q.params,
 		Duration:  elapsed,
 		Operation: DetectOperation(q.sql),
 	})
+	if q.change != nil {
+		q.publishReturning(ctx, slice)
+	}
 
 	return nil
 }
 
 // All fetches all rows into a slice of structs.
 // If query is part of a transaction, uses transaction connection.
+func (q *Query) All(dest interface{}) error {
+	ctx, span := q.startSpan()
+	ctx, finish := q.watch(ctx)
+	err := classifyError(finish(q.all(ctx, dest)))
+	if err == nil {
+		q.db.fillEmptySlice(dest)
+	}
+	span.end(err, nil)
+	return err
+}
+
+// all implements All with the context of the query span.
 //
 //nolint:cyclop,funlen,nestif // Query execution requires comprehensive error handling and logging
-func (q *Query) All(dest interface{}) error {
-	ctx := q.getContext()
+func (q *Query) all(ctx context.Context, dest interface{}) error {
 	start := time.Now()
 
 	if err := q.validateBeforeExec(ctx); err != nil {
 		if q.db.logger != nil {
-			q.db.logger.Error("query preparation failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"error", err,
-			)
+			q.logFailure("query preparation failed", err)
 		}
 		return err
 	}
 
+	// Wait for a concurrency slot (see WithMaxConcurrentQueries)
+	release, slotErr := q.acquireSlot(ctx)
+	if slotErr != nil {
+		return slotErr
+	}
+	defer release()
+	start = time.Now() // exclude queue wait from query duration
+
 	// Execute query — direct for tx, prepared for non-tx
 	var rows *sql.Rows
 	var err error
-	if q.useDirectTx() {
-		rows, err = q.tx.QueryContext(ctx, q.sql, q.params...)
+	if conn, query, ok := q.directConn(ctx); ok {
+		err = q.withRetry(ctx, func() (err error) {
+			rows, err = conn.QueryContext(ctx, query, q.params...)
+			return err
+		})
 	} else {
 		var stmt *sql.Stmt
 		stmt, err = q.prepareStatement(ctx)
 		if err != nil {
 			if q.db.logger != nil {
-				q.db.logger.Error("query preparation failed",
-					"sql", q.sql,
-					"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-					"error", err,
-				)
+				q.logFailure("query preparation failed", err)
 			}
 			return err
 		}
-		rows, err = stmt.QueryContext(ctx, q.params...)
+		err = q.runStmt(ctx, stmt, func(stmt *sql.Stmt) error {
+			return q.withRetry(ctx, func() (err error) {
+				rows, err = stmt.QueryContext(ctx, q.params...)
+				return err
+			})
+		})
 	}
 	if err != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("query execution failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", err,
-			)
+			q.logFailure("query execution failed", err, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -750,9 +911,11 @@ func (q *Query) All(dest interface{}) error {
 	}
 	defer func() { _ = rows.Close() }()
 
-	// Scan all rows - detect []NullStringMap for dynamic scanning
+	// Scan all rows - detect row mappers and []NullStringMap for dynamic scanning
 	var scanErr error
-	if destSlice, ok := dest.(*[]NullStringMap); ok {
+	if sink, ok := dest.(rowSink); ok {
+		scanErr = scanSinkRows(rows, sink)
+	} else if destSlice, ok := dest.(*[]NullStringMap); ok {
 		scanErr = globalScanner.scanMapRows(rows, destSlice)
 	} else {
 		scanErr = globalScanner.scanRows(rows, dest)
@@ -760,14 +923,9 @@ func (q *Query) All(dest interface{}) error {
 	if scanErr != nil {
 		elapsed := time.Since(start)
 		if q.db.logger != nil {
-			q.db.logger.Error("row scanning failed",
-				"sql", q.sql,
-				"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
-				"duration_ms", elapsed.Milliseconds(),
-				"error", scanErr,
-			)
+			q.logFailure("row scanning failed", scanErr, "duration_ms", elapsed.Milliseconds())
 		}
-		q.db.invokeHook(ctx, QueryEvent{
+		q.emit(ctx, QueryEvent{
 			SQL:       q.sql,
 			Args:      q.params,
 			Duration:  elapsed,
@@ -790,16 +948,19 @@ func (q *Query) All(dest interface{}) error {
 	}
 
 	// Invoke query hook
-	q.db.invokeHook(ctx, QueryEvent{
+	q.emit(ctx, QueryEvent{
 		SQL:       q.sql,
 		Args:      q.params,
 		Duration:  elapsed,
 		Operation: DetectOperation(q.sql),
 	})
+	if q.change != nil {
+		q.publishReturning(ctx, dest)
+	}
 
 	// Analyze query performance if optimizer is enabled (async to not block)
-	if q.db.optimizer != nil {
-		go q.analyzeQuery(ctx, elapsed)
+	if q.db.optimizer != nil && q.db.analyzed.claim(q.sql) {
+		q.db.drain.goBackground(func() { q.analyzeQuery(ctx, elapsed) })
 	}
 
 	return nil
@@ -843,6 +1004,13 @@ func (q *Query) BindParams(params Params) *Query {
 	return q
 }
 
+// WithContext sets the context used to execute the query.
+// It overrides the context inherited from the DB or transaction.
+func (q *Query) WithContext(ctx context.Context) *Query {
+	q.ctx = ctx
+	return q
+}
+
 // SQL returns the SQL query string.
 func (q *Query) SQL() string {
 	return q.sql
@@ -4,9 +4,45 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 )
 
+// optimizerReanalyzeAfter is how long the optimizer skips a query after
+// analyzing it.
+const optimizerReanalyzeAfter = 10 * time.Minute
+
+// analyzedQueries records when each query fingerprint was last analyzed, so
+// the optimizer explains a logical query once rather than on every execution.
+type analyzedQueries struct {
+	mu   sync.Mutex
+	last map[string]time.Time // fingerprint -> time of the last analysis
+}
+
+// claim reports whether query is due for analysis and, if so, records it as
+// analyzed now. A nil receiver analyzes every query.
+func (a *analyzedQueries) claim(query string) bool {
+	if a == nil {
+		return true
+	}
+	fp, now := Fingerprint(query), time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if last, ok := a.last[fp]; ok && now.Sub(last) < optimizerReanalyzeAfter {
+		return false
+	}
+	if len(a.last) >= maxFingerprintCache {
+		for k, last := range a.last {
+			if now.Sub(last) >= optimizerReanalyzeAfter {
+				delete(a.last, k)
+			}
+		}
+	}
+	a.last[fp] = now
+	return true
+}
+
 // analyzeQuery performs query optimization analysis asynchronously.
 // This method is called in a goroutine to avoid blocking query execution.
 // The ctx parameter is intentionally unused as we create a new timeout context.
@@ -32,6 +68,11 @@ func (q *Query) analyzeQuery(_ context.Context, executionTime time.Duration) {
 	// Output suggestions (in production, this would use a proper logger)
 	// We use reflection-free type switches to extract suggestion fields
 	for _, s := range suggestions {
+		// Record the suggestion; dismissed ones are not shown again
+		if !q.db.recordOptimizerSuggestion(q.sql, s) {
+			continue
+		}
+
 		// Extract fields using struct field accessors
 		// Format: [RELICA OPTIMIZER] severity: message
 		msg := fmt.Sprintf("%v", s)
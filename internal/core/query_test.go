@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestNewQuery_Params(t *testing.T) {
+	var events []QueryEvent
+	db, err := Open("sqlite", ":memory:", WithQueryHook(func(_ context.Context, e QueryEvent) {
+		events = append(events, e)
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery(`CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)`).Execute()
+	require.NoError(t, err)
+	_, err = db.NewQuery(`INSERT INTO notes (body) VALUES (?), (?)`, "a", "b").Execute()
+	require.NoError(t, err)
+
+	var body string
+	require.NoError(t, db.NewQuery(`SELECT body FROM notes WHERE id = ?`, 2).Row(&body))
+	assert.Equal(t, "b", body)
+
+	// Bind replaces the constructor parameters.
+	require.NoError(t, db.NewQuery(`SELECT body FROM notes WHERE id = ?`, 2).Bind(1).Row(&body))
+	assert.Equal(t, "a", body)
+
+	// Raw queries are reported to hooks and use the statement cache.
+	require.Len(t, events, 4)
+	assert.Equal(t, []interface{}{2}, events[2].Args)
+	assert.Equal(t, 2, db.StmtCacheStats().Size) // INSERT and SELECT; the DDL statement evicts itself
+
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	var n int
+	require.NoError(t, tx.NewQuery(`SELECT COUNT(*) FROM notes WHERE body <> ?`, "a").Row(&n))
+	assert.Equal(t, 1, n)
+}
+
+func TestQuery_WithContext(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var n int
+	err = db.NewQuery(`SELECT 1`).WithContext(ctx).Row(&n)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, db.NewQuery(`SELECT 1`).WithContext(context.Background()).Row(&n))
+	assert.Equal(t, 1, n)
+}
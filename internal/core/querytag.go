@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Query tags
+// ============================================================================
+//
+// Tag names a query for observability, e.g. "checkout.load_cart". The tag is
+// added to log records ("tag" key), QueryEvent.Tag for hooks and tracing, and
+// audit records (AuditEvent.Tag). Tagged queries are also aggregated per tag
+// and reported by DB.QueryStats.
+//
+// The tag travels in the query context (see security.WithQueryTag), so raw
+// DB.ExecContext/QueryContext calls can be tagged for auditing as well.
+
+// maxTagSamples bounds the number of recent durations kept per tag for
+// latency percentiles.
+const maxTagSamples = 1024
+
+// TagStats is the aggregate of all executions of queries with one tag.
+type TagStats struct {
+	// Count is the number of executions.
+	Count int64
+	// Errors is the number of failed executions (sql.ErrNoRows is not a failure).
+	Errors int64
+	// ErrorRate is Errors / Count.
+	ErrorRate float64
+	// P50 is the median latency of recent executions.
+	P50 time.Duration
+	// P95 is the 95th percentile latency of recent executions.
+	P95 time.Duration
+}
+
+// tagStatsRegistry aggregates executions per query tag.
+// It is shared by all copies of a DB, including pool views.
+type tagStatsRegistry struct {
+	mu   sync.Mutex
+	tags map[string]*tagStat
+}
+
+// tagStat holds the counters and a ring buffer of recent durations for one tag.
+type tagStat struct {
+	count   int64
+	errors  int64
+	samples []time.Duration
+	next    int
+}
+
+// record adds one execution of tag.
+func (r *tagStatsRegistry) record(tag string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.tags[tag]
+	if !ok {
+		s = &tagStat{}
+		r.tags[tag] = s
+	}
+	s.count++
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.errors++
+	}
+	if len(s.samples) < maxTagSamples {
+		s.samples = append(s.samples, d)
+	} else {
+		s.samples[s.next] = d
+		s.next = (s.next + 1) % maxTagSamples
+	}
+}
+
+// snapshot returns the current stats of every tag.
+func (r *tagStatsRegistry) snapshot() map[string]TagStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make(map[string]TagStats, len(r.tags))
+	for tag, s := range r.tags {
+		sorted := slices.Clone(s.samples)
+		slices.Sort(sorted)
+		result[tag] = TagStats{
+			Count:     s.count,
+			Errors:    s.errors,
+			ErrorRate: float64(s.errors) / float64(s.count),
+			P50:       percentile(sorted, 50),
+			P95:       percentile(sorted, 95),
+		}
+	}
+	return result
+}
+
+// percentile returns the p-th percentile (nearest rank) of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// QueryStats returns per-tag execution statistics of tagged queries,
+// keyed by tag. Untagged queries are not tracked.
+//
+// Example:
+//
+//	for tag, s := range db.QueryStats() {
+//	    fmt.Printf("%s: n=%d p95=%s errors=%.1f%%\n", tag, s.Count, s.P95, 100*s.ErrorRate)
+//	}
+func (db *DB) QueryStats() map[string]TagStats {
+	if db.tagStats == nil {
+		return map[string]TagStats{}
+	}
+	return db.tagStats.snapshot()
+}
+
+// ResetQueryStats discards all per-tag statistics.
+func (db *DB) ResetQueryStats() {
+	if db.tagStats == nil {
+		return
+	}
+	db.tagStats.mu.Lock()
+	defer db.tagStats.mu.Unlock()
+	clear(db.tagStats.tags)
+}
+
+// Tag names all queries built by this builder (see SelectQuery.Tag).
+// It returns a new builder; the receiver is unchanged.
+func (qb *QueryBuilder) Tag(name string) *QueryBuilder {
+	nb := *qb
+	nb.tag = name
+	return &nb
+}
+
+// Tag names this query for logs, hooks, audit records and DB.QueryStats.
+//
+// Example:
+//
+//	db.Builder().Select().From("cart_items").
+//	    Where(relica.HashExp{"cart_id": id}).
+//	    Tag("checkout.load_cart").
+//	    All(&items)
+func (sq *SelectQuery) Tag(name string) *SelectQuery {
+	sq = sq.own()
+	sq.builder = sq.builder.Tag(name)
+	return sq
+}
+
+// Tag names this query for logs, hooks, audit records and DB.QueryStats.
+func (q *Query) Tag(name string) *Query {
+	q.tag = name
+	return q
+}
+
+// emit records the execution of a tagged query, feeds the optimizer store, logs
+// slow queries, records metrics, feeds the N+1 detector, and invokes the query hook.
+func (q *Query) emit(ctx context.Context, event QueryEvent) {
+	event.Tag = q.tag
+	if q.tag != "" && q.db.tagStats != nil {
+		q.db.tagStats.record(q.tag, event.Duration, event.Error)
+	}
+	if q.db.optStore != nil {
+		q.db.optStore.recordQuery(event)
+	}
+	q.logSlowQuery(event)
+	if q.db.metrics != nil {
+		q.db.metrics.record(ctx, q.db.driverName, event)
+	}
+	if q.db.nplusone != nil && event.Error == nil {
+		if w, ok := q.db.nplusone.observe(ctx, event); ok {
+			q.db.reportNPlusOne(ctx, w)
+		}
+	}
+	q.db.invokeHook(ctx, event)
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica/internal/logger"
+	"github.com/coregx/relica/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestQueryTag_HooksAndStats(t *testing.T) {
+	var events []QueryEvent
+	var ctxTags []string
+	db, err := Open("sqlite", ":memory:", WithQueryHook(func(ctx context.Context, e QueryEvent) {
+		events = append(events, e)
+		ctxTags = append(ctxTags, security.GetQueryTag(ctx))
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("CREATE TABLE carts (id INTEGER PRIMARY KEY, total INTEGER)").Execute()
+	require.NoError(t, err)
+	_, err = db.Builder().Insert("carts", map[string]interface{}{"id": 1, "total": 10}).
+		Tag("checkout.save_cart").Execute()
+	require.NoError(t, err)
+
+	var rows []struct {
+		ID    int `db:"id"`
+		Total int `db:"total"`
+	}
+	for i := 0; i < 3; i++ {
+		err = db.Builder().Select().From("carts").Tag("checkout.load_cart").All(&rows)
+		require.NoError(t, err)
+	}
+	err = db.Builder().Select().From("missing").Tag("checkout.load_cart").All(&rows)
+	require.Error(t, err)
+
+	// sql.ErrNoRows is not counted as a failure.
+	var one struct {
+		ID int `db:"id"`
+	}
+	err = db.Builder().Select("id").From("carts").Where("id = ?", 99).Tag("checkout.find").One(&one)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.Len(t, events, 7)
+	assert.Empty(t, events[0].Tag)
+	assert.Empty(t, ctxTags[0])
+	assert.Equal(t, "checkout.save_cart", events[1].Tag)
+	assert.Equal(t, "checkout.load_cart", events[2].Tag)
+	assert.Equal(t, "checkout.load_cart", ctxTags[2])
+
+	stats := db.QueryStats()
+	require.Len(t, stats, 3)
+	load := stats["checkout.load_cart"]
+	assert.Equal(t, int64(4), load.Count)
+	assert.Equal(t, int64(1), load.Errors)
+	assert.InDelta(t, 0.25, load.ErrorRate, 1e-9)
+	assert.Positive(t, load.P95)
+	assert.LessOrEqual(t, load.P50, load.P95)
+	assert.Equal(t, int64(0), stats["checkout.find"].Errors)
+
+	db.ResetQueryStats()
+	assert.Empty(t, db.QueryStats())
+}
+
+func TestQueryTag_BuilderAndQuery(t *testing.T) {
+	db := mockDB("sqlite")
+
+	qb := db.Builder()
+	tagged := qb.Tag("reports")
+	assert.Equal(t, "reports", tagged.Select().From("t").Build().tag)
+	assert.Equal(t, "reports", tagged.Update("t").Set(map[string]interface{}{"a": 1}).Build().tag)
+	assert.Empty(t, qb.Select().From("t").Build().tag, "Tag must not modify the receiver")
+
+	// SelectQuery.Tag does not leak into other queries of the same builder.
+	assert.Equal(t, "one", qb.Select().From("t").Tag("one").Build().tag)
+	assert.Empty(t, qb.Select().From("t").Build().tag)
+
+	assert.Equal(t, "raw", db.NewQuery("SELECT 1").Tag("raw").tag)
+}
+
+func TestQueryTag_Logs(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewSlogAdapter(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	db, err := Open("sqlite", ":memory:", WithLogger(log))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("SELECT 1").Tag("health.ping").Execute()
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "tag=health.ping")
+
+	buf.Reset()
+	_, err = db.NewQuery("DELETE FROM missing").Tag("cleanup").Execute()
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "query execution failed")
+	assert.Contains(t, buf.String(), "tag=cleanup")
+}
+
+func TestQueryTag_Audit(t *testing.T) {
+	var buf bytes.Buffer
+	auditor := security.NewAuditor(slog.New(slog.NewJSONHandler(&buf, nil)), security.AuditAll)
+
+	db, err := Open("sqlite", ":memory:", WithAuditLog(auditor))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := security.WithQueryTag(context.Background(), "migrations.create")
+	_, err = db.ExecContext(ctx, "CREATE TABLE audit_t (id INTEGER)")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"tag":"migrations.create"`)
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(sorted, 95))
+	assert.Equal(t, 7*time.Millisecond, percentile(sorted[6:7], 95))
+}
+
+func TestTagStats_RingBuffer(t *testing.T) {
+	r := &tagStatsRegistry{tags: make(map[string]*tagStat)}
+	for i := 0; i < maxTagSamples; i++ {
+		r.record("t", time.Second, nil)
+	}
+	for i := 0; i < maxTagSamples; i++ {
+		r.record("t", time.Millisecond, errors.New("boom"))
+	}
+
+	s := r.snapshot()["t"]
+	assert.Equal(t, int64(2*maxTagSamples), s.Count)
+	assert.InDelta(t, 0.5, s.ErrorRate, 1e-9)
+	assert.Equal(t, time.Millisecond, s.P95, "old samples are evicted")
+}
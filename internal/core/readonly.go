@@ -0,0 +1,111 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ============================================================================
+// Read-only enforcement
+// ============================================================================
+//
+// A read-only handle (WithReadOnly) or query (SelectQuery.ReadOnly) rejects
+// statements that may change data with ErrReadOnly when the query is built,
+// so a reporting connection cannot write even if its database user could.
+// Only SELECT statements and WITH queries without a data-modifying statement
+// are allowed; row locks (FOR UPDATE, FOR SHARE, LOCK IN SHARE MODE) and
+// SELECT ... INTO are rejected as well.
+//
+// Builder queries are checked by Build, raw queries by NewQuery, and
+// ExecContext and QueryContext check their statement before running it.
+// QueryRowContext cannot return an error and is not checked.
+
+var (
+	// rowLockRegex detects row-locking clauses of a SELECT.
+	rowLockRegex = regexp.MustCompile(`(?i)\bFOR\s+(?:NO\s+KEY\s+UPDATE|UPDATE|KEY\s+SHARE|SHARE)\b|\bLOCK\s+IN\s+SHARE\s+MODE\b`)
+
+	// selectIntoRegex detects SELECT ... INTO, which creates a table or writes a file.
+	selectIntoRegex = regexp.MustCompile(`(?i)\bINTO\b`)
+
+	// quotedIdentifierRegex matches double-quoted and backquoted identifiers.
+	quotedIdentifierRegex = regexp.MustCompile("\"(?:[^\"]|\"\")*\"|`[^`]*`")
+)
+
+// WithReadOnly makes the DB a read-only handle: every statement built or
+// executed through it, or through its transactions, must be a SELECT, and
+// anything else fails with ErrReadOnly before it is sent. Use it for
+// reporting and analytics handles.
+//
+// Example:
+//
+//	reports, _ := relica.Open("postgres", replicaDSN, relica.WithReadOnly())
+//
+//	_, err := reports.Delete("users").Where(relica.Eq("id", 1)).Execute()
+//	// errors.Is(err, relica.ErrReadOnly)
+func WithReadOnly() Option {
+	return optionFunc(func(db *DB) {
+		db.readOnly = true
+	})
+}
+
+// ReadOnly makes Build fail with ErrReadOnly if the query may change data:
+// a WITH clause with a data-modifying statement, a row lock or SELECT ...
+// INTO in a raw expression.
+//
+// Example:
+//
+//	// cte comes from a plugin; reject it if it turns out to write
+//	err := db.Select("*").With("rows", cte).From("rows").ReadOnly().All(&rows)
+func (sq *SelectQuery) ReadOnly() *SelectQuery {
+	sq = sq.own()
+	sq.readOnly = true
+	return sq
+}
+
+// guard sets the prepErr of a built query q if it cannot be executed: db is
+// read-only and q may change data, or q has more parameters than the dialect
+// allows (see checkParamLimit).
+func (db *DB) guard(q *Query) *Query {
+	if db.readOnly && q.prepErr == nil {
+		q.prepErr = readOnlyViolation(q.sql)
+	}
+	if q.prepErr == nil {
+		q.prepErr = checkParamLimit(len(q.params), db.dialect)
+	}
+	return q
+}
+
+// checkReadOnly returns ErrReadOnly if db is read-only and query may change data.
+func (db *DB) checkReadOnly(query string) error {
+	if !db.readOnly {
+		return nil
+	}
+	return readOnlyViolation(query)
+}
+
+// readOnlyViolation returns ErrReadOnly if query is not a plain read: a
+// statement other than SELECT or a read-only WITH query, a row lock or
+// SELECT ... INTO.
+func readOnlyViolation(query string) error {
+	text := quotedIdentifierRegex.ReplaceAllString(stringLiteralRegex.ReplaceAllString(query, "''"), `""`)
+	var reason string
+	switch {
+	case !readOnlyStatement(query):
+		verb := "empty"
+		if fields := strings.Fields(query); len(fields) > 0 {
+			verb = strings.ToUpper(fields[0])
+		}
+		reason = verb + " statement"
+		if verb == "WITH" {
+			reason = "data-modifying WITH statement"
+		}
+	case rowLockRegex.MatchString(text):
+		reason = "row lock"
+	case selectIntoRegex.MatchString(text):
+		reason = "SELECT ... INTO"
+	default:
+		return nil
+	}
+	return fmt.Errorf("relica: read-only: %s is not allowed: %w", reason, ErrReadOnly)
+}
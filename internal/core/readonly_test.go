@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyViolation(t *testing.T) {
+	allowed := []string{
+		`SELECT * FROM users`,
+		`  select id from users where note = 'for update'`,
+		`WITH t AS (SELECT 1) SELECT * FROM t`,
+		`SELECT "into", ` + "`for update`" + ` FROM t`,
+	}
+	for _, query := range allowed {
+		assert.NoError(t, readOnlyViolation(query), query)
+	}
+
+	rejected := map[string]string{
+		`INSERT INTO users (name) VALUES (?)`:                        "INSERT statement",
+		`update users set name = ?`:                                  "UPDATE statement",
+		`TRUNCATE TABLE users`:                                       "TRUNCATE statement",
+		`DROP TABLE users`:                                           "DROP statement",
+		`WITH d AS (DELETE FROM users RETURNING id) SELECT * FROM d`: "data-modifying WITH statement",
+		`SELECT * FROM users WHERE id = 1 FOR UPDATE`:                "row lock",
+		`SELECT * FROM users FOR NO KEY UPDATE`:                      "row lock",
+		`SELECT * FROM users LOCK IN SHARE MODE`:                     "row lock",
+		`SELECT * INTO backup FROM users`:                            "SELECT ... INTO",
+		``:                                                           "empty statement",
+	}
+	for query, reason := range rejected {
+		err := readOnlyViolation(query)
+		assert.ErrorIs(t, err, ErrReadOnly, query)
+		assert.EqualError(t, err, "relica: read-only: "+reason+" is not allowed: relica: statement not allowed on read-only handle")
+	}
+}
+
+func TestWithReadOnly_Build(t *testing.T) {
+	db := mockDB("postgres")
+	WithReadOnly().apply(db)
+	qb := &QueryBuilder{db: db}
+
+	assert.NoError(t, qb.Select("id").From("users").Build().prepErr)
+	assert.NoError(t, db.NewQuery("SELECT 1").prepErr)
+
+	writes := []*Query{
+		qb.Insert("users", map[string]interface{}{"name": "ann"}),
+		qb.Upsert("users", map[string]interface{}{"id": 1}).OnConflict("id").DoNothing().Build(),
+		qb.Update("users").Set(map[string]interface{}{"name": "ann"}).Where("id = ?", 1).Build(),
+		qb.Delete("users").Where("id = ?", 1).Build(),
+		qb.BatchInsert("users", []string{"name"}).Values("ann").Build(),
+		qb.BatchUpdate("users", "id").Set(1, map[string]interface{}{"name": "ann"}).Build(),
+		qb.InsertFromSelect("archive", nil, qb.Select("*").From("users")).Build(),
+		qb.Truncate("users").Build(),
+		qb.With("d", qb.Delete("users").Where("id = ?", 1).Returning("id")).Select("id").From("d").Build(),
+		db.NewQuery("DELETE FROM users"),
+	}
+	for _, q := range writes {
+		assert.ErrorIs(t, q.prepErr, ErrReadOnly, q.sql)
+	}
+}
+
+func TestSelectQuery_ReadOnly(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	moved := qb.Delete("orders").Where("id = ?", 1).Returning("id")
+
+	q := qb.With("moved", moved).Select("id").From("moved").Build()
+	assert.NoError(t, q.prepErr, "not enforced without ReadOnly")
+
+	q = qb.With("moved", moved).Select("id").From("moved").ReadOnly().Build()
+	assert.ErrorIs(t, q.prepErr, ErrReadOnly)
+
+	q = qb.Select("id").From("orders").ReadOnly().Build()
+	assert.NoError(t, q.prepErr)
+}
+
+func TestWithReadOnly_Execute(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithReadOnly())
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.ErrorIs(t, err, ErrReadOnly)
+
+	// Created outside the read-only handle
+	_, err = db.sqlDB.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.sqlDB.ExecContext(ctx, "INSERT INTO users (name) VALUES ('ann')")
+	require.NoError(t, err)
+
+	var names []string
+	require.NoError(t, db.Builder().Select("name").From("users").Column(&names))
+	assert.Equal(t, []string{"ann"}, names)
+	rows, err := db.QueryContext(ctx, "SELECT name FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.Builder().Update("users").Set(map[string]interface{}{"name": "bob"}).Where("id = ?", 1).Execute()
+		return err
+	})
+	assert.ErrorIs(t, err, ErrReadOnly)
+
+	var name string
+	require.NoError(t, db.NewQuery("SELECT name FROM users WHERE id = 1").Row(&name))
+	assert.Equal(t, "ann", name)
+}
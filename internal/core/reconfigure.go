@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// Runtime reconfiguration
+// ============================================================================
+//
+// Most options configure a DB once, before it is shared between goroutines.
+// The options below may also be passed to DB.Reconfigure at runtime: the pool
+// limits are applied by database/sql under its own lock, and the slow query
+// threshold and log level are read atomically on every use.
+//
+//   - WithMaxOpenConns, WithMaxIdleConns, WithConnMaxLifetime, WithConnMaxIdleTime
+//   - WithSlowQueryThreshold
+//   - WithLogLevel
+
+// runtimeSettings holds the settings that can change while queries run.
+// It is shared by DB copies (WithContext, pool views).
+type runtimeSettings struct {
+	slowQuery atomic.Int64 // nanoseconds; 0 = disabled
+	logLevel  slog.LevelVar
+}
+
+// newRuntimeSettings creates settings that log every level and report no slow queries.
+func newRuntimeSettings() *runtimeSettings {
+	s := &runtimeSettings{}
+	s.logLevel.Set(slog.LevelDebug)
+	return s
+}
+
+// WithSlowQueryThreshold logs queries taking at least d as "slow query"
+// warnings. Zero disables slow query logging. Requires WithLogger.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithLogger(relica.NewSlogAdapter(slog.Default())),
+//	    relica.WithSlowQueryThreshold(500*time.Millisecond))
+func WithSlowQueryThreshold(d time.Duration) Option {
+	return runtimeOption(func(db *DB) {
+		if db.settings != nil {
+			db.settings.slowQuery.Store(int64(max(d, 0)))
+		}
+	})
+}
+
+// WithLogLevel sets the minimum level of the messages relica passes to its
+// logger (see WithLogger). The default passes all messages.
+//
+// Example:
+//
+//	db.Reconfigure(relica.WithLogLevel(slog.LevelWarn))
+func WithLogLevel(level slog.Level) Option {
+	return runtimeOption(func(db *DB) {
+		if db.settings != nil {
+			db.settings.logLevel.Set(level)
+		}
+	})
+}
+
+// runtimeOption is an Option that DB.Reconfigure may apply to a DB in use.
+// Only the options listed above return it; Reconfigure recognizes them by
+// this type and never applies an option of another type.
+type runtimeOption func(*DB)
+
+func (f runtimeOption) apply(db *DB) { f(db) }
+
+// Reconfigure applies options to a DB in use. It is safe to call while
+// queries run. Only the pool limits (WithMaxOpenConns, WithMaxIdleConns,
+// WithConnMaxLifetime, WithConnMaxIdleTime), WithSlowQueryThreshold and
+// WithLogLevel can change at runtime; if any other option is passed,
+// Reconfigure returns ErrNotReconfigurable and applies none of them.
+//
+// Pool limits apply to the primary pool; configure named pools through
+// their Set* methods.
+//
+// Example:
+//
+//	err := db.Reconfigure(
+//	    relica.WithMaxOpenConns(50),
+//	    relica.WithSlowQueryThreshold(200*time.Millisecond),
+//	    relica.WithLogLevel(slog.LevelDebug))
+func (db *DB) Reconfigure(opts ...Option) error {
+	for i, opt := range opts {
+		if _, ok := opt.(runtimeOption); !ok {
+			return fmt.Errorf("%w: option %d", ErrNotReconfigurable, i+1)
+		}
+	}
+
+	target := &DB{sqlDB: db.sqlDB, settings: db.settings}
+	for _, opt := range opts {
+		opt.apply(target)
+	}
+	return nil
+}
+
+// logSlowQuery logs event as a slow query if it reached the slow query threshold.
+func (q *Query) logSlowQuery(event QueryEvent) {
+	if q.db.settings == nil || q.db.logger == nil {
+		return
+	}
+	threshold := time.Duration(q.db.settings.slowQuery.Load())
+	if threshold <= 0 || event.Duration < threshold {
+		return
+	}
+
+	args := []interface{}{
+		"sql", q.sql,
+		"fingerprint", Fingerprint(q.sql),
+		"params", q.db.sanitizer.FormatParams(q.db.sanitizer.MaskParams(q.sql, q.params)),
+		"duration_ms", event.Duration.Milliseconds(),
+		"threshold_ms", threshold.Milliseconds(),
+		"database", q.db.driverName,
+	}
+	if q.tag != "" {
+		args = append(args, "tag", q.tag)
+	}
+	q.db.logger.Warn("slow query", args...)
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconfigure_Pool(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.Reconfigure(WithMaxOpenConns(7), WithMaxIdleConns(3), WithConnMaxLifetime(time.Minute)))
+	assert.Equal(t, 7, db.sqlDB.Stats().MaxOpenConnections)
+}
+
+func TestReconfigure_Rejected(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, opt := range []Option{
+		WithLogger(&logger.NoopLogger{}),
+		WithHealthCheck(time.Hour),
+		WithStmtCacheCapacity(10),
+		WithRetry(RetryPolicy{}),
+		WithReplicas("replica.db"),
+		WithReplicaLagCheck(time.Hour, 0),
+		WithSessionSettings(map[string]string{"time_zone": "UTC"}),
+	} {
+		err := db.Reconfigure(WithMaxOpenConns(9), opt)
+		assert.ErrorIs(t, err, ErrNotReconfigurable)
+	}
+	assert.Equal(t, 1, db.sqlDB.Stats().MaxOpenConnections, "nothing is applied when an option is rejected")
+	assert.Nil(t, db.healthChecker)
+}
+
+func TestReconfigure_RejectedOptionNotRun(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	mp := newRecordingMeter()
+	err = db.Reconfigure(WithMeterProvider(mp))
+	require.ErrorIs(t, err, ErrNotReconfigurable)
+	assert.Empty(t, mp.gauges, "the provider of a rejected option is never used")
+}
+
+func TestReconfigure_SlowQueryAndLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := Open("sqlite", ":memory:", WithMaxOpenConns(1), WithLogger(logger.NewSlogAdapter(l)))
+	require.NoError(t, err)
+	defer db.Close()
+	logged := func() string {
+		s := buf.String()
+		buf.Reset()
+		return s
+	}
+
+	var n int
+	require.NoError(t, db.NewQuery("SELECT 1").Tag("probe").Row(&n))
+	out := logged()
+	assert.NotContains(t, out, "slow query", "disabled by default")
+	assert.Contains(t, out, "query executed")
+
+	require.NoError(t, db.Reconfigure(WithSlowQueryThreshold(time.Nanosecond), WithLogLevel(slog.LevelWarn)))
+	require.NoError(t, db.NewQuery("SELECT 1").Tag("probe").Row(&n))
+	out = logged()
+	assert.Contains(t, out, `msg="slow query"`)
+	assert.Contains(t, out, "tag=probe")
+	assert.Contains(t, out, "fingerprint="+Fingerprint("SELECT 1"))
+	assert.NotContains(t, out, "query executed", "info messages are filtered")
+
+	require.NoError(t, db.Reconfigure(WithSlowQueryThreshold(0), WithLogLevel(slog.LevelInfo)))
+	require.NoError(t, db.NewQuery("SELECT 1").Row(&n))
+	out = logged()
+	assert.NotContains(t, out, "slow query")
+	assert.Contains(t, out, "query executed")
+}
+
+func TestReconfigure_Concurrent(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithMaxOpenConns(1), WithLogger(&logger.NoopLogger{}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var n int
+			for j := 0; j < 20; j++ {
+				assert.NoError(t, db.NewQuery("SELECT 1").Row(&n))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				assert.NoError(t, db.Reconfigure(
+					WithMaxOpenConns(1+j%3),
+					WithSlowQueryThreshold(time.Duration(j)*time.Millisecond),
+					WithLogLevel(slog.Level(j%8-4))))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ============================================================================
+// Query registry
+// ============================================================================
+//
+// RegisterQuery builds a query once, at startup, and stores it under a name.
+// Its statement is prepared and pinned in the statement cache, so executing
+// it with DB.Query never builds SQL or prepares a statement again.
+//
+// The parameters given while building are placeholders: DB.Query binds its
+// own parameters in their place, in the same order.
+
+// registeredQuery is a query stored with RegisterQuery.
+type registeredQuery struct {
+	sql     string
+	nparams int
+	tag     string
+	cached  string // statement cache key (SQL with static comments)
+}
+
+// queryRegistry holds the registered queries of a DB and its copies.
+type queryRegistry struct {
+	mu      sync.RWMutex
+	queries map[string]*registeredQuery
+}
+
+// RegisterQuery builds the query returned by build and stores it under name
+// for execution with Query. The statement is prepared and pinned in the
+// statement cache; drivers that prepare on the server (PostgreSQL, MySQL)
+// report a bad query at registration rather than on first use. Queries
+// without a tag are tagged with name (see QueryStats).
+//
+// Example:
+//
+//	err := db.RegisterQuery("activeUsersByOrg", func(qb *relica.QueryBuilder) *relica.Query {
+//	    return qb.Select("id", "name").From("users").
+//	        Where("org_id = ? AND status = ?", 0, "").
+//	        OrderBy("name").
+//	        Build()
+//	})
+//
+//	var users []User
+//	err = db.Query("activeUsersByOrg", orgID, "active").All(&users)
+func (db *DB) RegisterQuery(name string, build func(qb *QueryBuilder) *Query) error {
+	if name == "" {
+		return fmt.Errorf("relica: query name is empty")
+	}
+	q := build(db.Builder())
+	if q == nil {
+		return fmt.Errorf("relica: query %q: builder returned nil", name)
+	}
+	if q.prepErr != nil {
+		return fmt.Errorf("relica: query %q: %w", name, q.prepErr)
+	}
+
+	reg := &registeredQuery{sql: q.sql, nparams: len(q.params), tag: q.tag}
+	if reg.tag == "" {
+		reg.tag = name
+	}
+	reg.cached, _ = q.commentedSQL(context.Background())
+
+	db.queries.mu.Lock()
+	defer db.queries.mu.Unlock()
+	if _, ok := db.queries.queries[name]; ok {
+		return fmt.Errorf("relica: query %q is already registered", name)
+	}
+	stmt, err := db.sqlDB.PrepareContext(context.Background(), reg.cached)
+	if err != nil {
+		return fmt.Errorf("relica: query %q: %w", name, classifyError(err))
+	}
+	db.stmtCache.Set(reg.cached, stmt)
+	db.stmtCache.Pin(reg.cached)
+	db.queries.queries[name] = reg
+	return nil
+}
+
+// UnregisterQuery removes a query registered with RegisterQuery and unpins
+// its statement. Returns false if no query is registered under name.
+func (db *DB) UnregisterQuery(name string) bool {
+	db.queries.mu.Lock()
+	defer db.queries.mu.Unlock()
+	reg, ok := db.queries.queries[name]
+	if !ok {
+		return false
+	}
+	delete(db.queries.queries, name)
+	db.stmtCache.Unpin(reg.cached)
+	return true
+}
+
+// RegisteredQueries returns the sorted names of the registered queries.
+func (db *DB) RegisteredQueries() []string {
+	db.queries.mu.RLock()
+	defer db.queries.mu.RUnlock()
+	names := make([]string, 0, len(db.queries.queries))
+	for name := range db.queries.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query returns the query registered under name with params bound in place
+// of the parameters it was built with. An unknown name or a wrong number of
+// parameters is returned when the query is executed.
+//
+// Example:
+//
+//	var users []User
+//	err := db.Query("activeUsersByOrg", orgID, "active").WithContext(ctx).All(&users)
+func (db *DB) Query(name string, params ...interface{}) *Query {
+	q := &Query{params: params, db: db, tx: db.boundSQLTx()}
+
+	db.queries.mu.RLock()
+	reg, ok := db.queries.queries[name]
+	db.queries.mu.RUnlock()
+	switch {
+	case !ok:
+		q.prepErr = fmt.Errorf("%w: %q", ErrQueryNotRegistered, name)
+	case len(params) != reg.nparams:
+		q.prepErr = fmt.Errorf("relica: query %q takes %d parameters, got %d", name, reg.nparams, len(params))
+	default:
+		q.sql, q.tag = reg.sql, reg.tag
+	}
+	return db.guard(q)
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterQuery(t *testing.T) {
+	db := setupTypedTestDB(t)
+	db.sqlDB.SetMaxOpenConns(1)
+
+	err := db.RegisterQuery("authorsByActive", func(qb *QueryBuilder) *Query {
+		return qb.Select("id", "name").From("authors").Where("active = ?", false).OrderBy("id").Build()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"authorsByActive"}, db.RegisteredQueries())
+
+	const sql = `SELECT "id", "name" FROM "authors" WHERE active = ? ORDER BY "id"`
+	assert.True(t, db.stmtCache.IsPinned(sql), "statement is prepared and pinned")
+
+	var authors []typedAuthor
+	require.NoError(t, db.Query("authorsByActive", true).All(&authors))
+	require.Len(t, authors, 2)
+	assert.Equal(t, "cid", authors[1].Name)
+
+	var author typedAuthor
+	require.NoError(t, db.Query("authorsByActive", false).One(&author))
+	assert.Equal(t, "bob", author.Name)
+	assert.Contains(t, db.QueryStats(), "authorsByActive", "tagged with the query name")
+
+	err = db.RegisterQuery("authorsByActive", func(qb *QueryBuilder) *Query {
+		return qb.Select().From("authors").Build()
+	})
+	assert.ErrorContains(t, err, "already registered")
+
+	assert.True(t, db.UnregisterQuery("authorsByActive"))
+	assert.False(t, db.UnregisterQuery("authorsByActive"))
+	assert.False(t, db.stmtCache.IsPinned(sql))
+}
+
+func TestRegisterQuery_Errors(t *testing.T) {
+	db := setupTypedTestDB(t)
+	db.sqlDB.SetMaxOpenConns(1)
+
+	assert.Error(t, db.RegisterQuery("", func(qb *QueryBuilder) *Query { return qb.Select().From("authors").Build() }))
+	assert.Error(t, db.RegisterQuery("nil", func(*QueryBuilder) *Query { return nil }))
+	assert.Empty(t, db.RegisteredQueries())
+
+	err := db.RegisterQuery("badJoin", func(qb *QueryBuilder) *Query {
+		return qb.Select().From("authors").InnerJoin("books", 42).Build()
+	})
+	assert.Error(t, err, "build errors fail the registration")
+
+	err = db.RegisterQuery("chunked", func(qb *QueryBuilder) *Query {
+		return qb.Select().From("authors").WhereInChunked("id", []int{1}, 10).Build()
+	})
+	assert.ErrorIs(t, err, errChunkedExecution)
+
+	var author typedAuthor
+	err = db.Query("unknown").One(&author)
+	assert.True(t, errors.Is(err, ErrQueryNotRegistered))
+
+	require.NoError(t, db.RegisterQuery("authorByID", func(qb *QueryBuilder) *Query {
+		return qb.Select().From("authors").Where("id = ?", 0).Build()
+	}))
+	err = db.Query("authorByID").One(&author)
+	assert.ErrorContains(t, err, `query "authorByID" takes 1 parameters, got 0`)
+}
@@ -0,0 +1,353 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coregx/relica/internal/cache"
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/coregx/relica/internal/logger"
+)
+
+// ============================================================================
+// Read replicas
+// ============================================================================
+//
+// Replicas registered with WithReplicas are named pools ("replica-0",
+// "replica-1", ...) that serve the SELECT queries built outside transactions,
+// in turn. Writes, raw SQL, transactions, locking reads and queries routed
+// with OnPool stay on their pool.
+//
+// With WithReplicaLagCheck, the replication lag of every replica is measured
+// periodically (pg_last_xact_replay_timestamp on PostgreSQL, SHOW REPLICA
+// STATUS on MySQL). Replicas lagging more than the maximum, or whose lag is
+// unknown, serve no reads, and a query with RequireFresh only runs on a
+// replica whose lag is within its bound; otherwise it runs on the primary.
+
+// lockingReadRegex matches row-locking clauses, which must run on the primary.
+var lockingReadRegex = regexp.MustCompile(`(?i)\bFOR\s+(?:NO\s+KEY\s+)?(?:UPDATE|SHARE|KEY\s+SHARE)\b|\bLOCK\s+IN\s+SHARE\s+MODE\b`)
+
+// replicaPoolPrefix prefixes the pool names of read replicas.
+const replicaPoolPrefix = "replica-"
+
+// ReplicaStatus describes a read replica (see DB.Replicas).
+type ReplicaStatus struct {
+	Pool      string        // pool name ("replica-0", ...), usable with OnPool
+	Lag       time.Duration // last measured replication lag
+	LagKnown  bool          // false before the first successful measurement or after a failed one
+	CheckedAt time.Time     // time of the last lag measurement (zero if never measured)
+	Err       error         // error of the last lag measurement
+}
+
+// lagProbe measures the replication lag of a replica.
+type lagProbe func(ctx context.Context, db *sql.DB) (time.Duration, error)
+
+// replicaSet holds the read replicas of a DB. It is shared by DB copies.
+type replicaSet struct {
+	mu       sync.RWMutex
+	replicas []*replica
+	next     atomic.Uint64 // round-robin counter
+	interval time.Duration // lag check interval (0 = lag not measured)
+	maxLag   time.Duration // replicas lagging more serve no reads (0 = no limit)
+	probe    lagProbe
+	logger   logger.Logger
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// replica is a read replica pool with its last lag measurement.
+type replica struct {
+	pool *Pool
+
+	mu     sync.RWMutex
+	status ReplicaStatus
+}
+
+// WithReplicas adds read replicas opened with the DB's driver and the given
+// DSNs. SELECT queries built outside transactions are spread over them in
+// turn; everything else runs on the primary. Replicas are named pools
+// ("replica-0", "replica-1", ... in order) and can be configured with
+// DB.Pool or targeted with OnPool.
+//
+// Without WithReplicaLagCheck, replicas are assumed to be current.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", primaryDSN,
+//	    relica.WithReplicas(replica1DSN, replica2DSN),
+//	    relica.WithReplicaLagCheck(5*time.Second, 10*time.Second))
+func WithReplicas(dsns ...string) Option {
+	return optionFunc(func(db *DB) {
+		rs := db.replicaSet()
+		if db.pools == nil {
+			return // not a pool-owning DB (reconfiguration probe)
+		}
+		for _, dsn := range dsns {
+			name := replicaPoolPrefix + strconv.Itoa(len(rs.replicas))
+			session := &sessionSettings{parent: db.session}
+			sqlDB, err := openSessionPool(db.driverName, dsn, db.dialect, session)
+			if err != nil {
+				db.logger.Error("replica open failed", "pool", name, "error", err)
+				continue
+			}
+			p := &Pool{
+				name:      name,
+				parent:    db,
+				sqlDB:     sqlDB,
+				stmtCache: cache.NewStmtCacheWithShards(db.stmtCache.Stats().Capacity, db.stmtShards),
+				session:   session,
+			}
+			db.pools.mu.Lock()
+			db.pools.pools[name] = p
+			db.pools.mu.Unlock()
+
+			rs.mu.Lock()
+			rs.replicas = append(rs.replicas, &replica{pool: p, status: ReplicaStatus{Pool: name}})
+			rs.mu.Unlock()
+		}
+	})
+}
+
+// WithReplicaLagCheck measures the replication lag of the replicas every
+// interval. Replicas lagging more than maxLag (0 = no limit), or whose lag
+// could not be measured, serve no reads until a later check succeeds. Lag
+// measurements also enable RequireFresh. If interval <= 0, lag is not checked.
+func WithReplicaLagCheck(interval, maxLag time.Duration) Option {
+	return optionFunc(func(db *DB) {
+		if interval <= 0 {
+			return
+		}
+		rs := db.replicaSet()
+		rs.interval = interval
+		rs.maxLag = maxLag
+		rs.logger = db.logger
+		rs.stop = make(chan struct{})
+		rs.wg.Add(1)
+		go rs.run()
+	})
+}
+
+// replicaSet returns the replica set of db, creating it on first use.
+func (db *DB) replicaSet() *replicaSet {
+	if db.replicas == nil {
+		db.replicas = &replicaSet{probe: dialectLagProbe(db.dialect)}
+	}
+	return db.replicas
+}
+
+// Replicas returns the status of the read replicas, in WithReplicas order.
+func (db *DB) Replicas() []ReplicaStatus {
+	rs := db.primary().replicas
+	if rs == nil {
+		return nil
+	}
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	out := make([]ReplicaStatus, len(rs.replicas))
+	for i, r := range rs.replicas {
+		out[i] = r.currentStatus()
+	}
+	return out
+}
+
+// RequireFresh runs the query on a replica only if its last measured lag is
+// at most maxLag (see WithReplicaLagCheck); otherwise, or if lag is not
+// checked, it runs on the primary. Use it for reads that must see recent
+// writes.
+//
+// Example:
+//
+//	db.Builder().Select().From("orders").Where(relica.Eq("id", id)).
+//	    RequireFresh(2 * time.Second).One(&order)
+func (sq *SelectQuery) RequireFresh(maxLag time.Duration) *SelectQuery {
+	sq = sq.own()
+	sq.freshness = &maxLag
+	return sq
+}
+
+// readDB returns the DB that runs query, a SELECT built outside transactions:
+// a replica view if a replica qualifies, or db itself.
+func (db *DB) readDB(query string, fresh *time.Duration) *DB {
+	rs := db.replicas
+	if rs == nil || db.root != nil || db.bound != nil || db.pinned != nil ||
+		!readOnlyStatement(query) || lockingReadRegex.MatchString(query) {
+		return db
+	}
+	r := rs.pick(fresh)
+	if r == nil {
+		return db
+	}
+	return r.pool.view(db)
+}
+
+// pick returns the next replica that may serve a read, or nil.
+func (rs *replicaSet) pick(fresh *time.Duration) *replica {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	n := uint64(len(rs.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := rs.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := rs.replicas[(start+i)%n]
+		if rs.eligible(r, fresh) {
+			return r
+		}
+	}
+	return nil
+}
+
+// eligible reports whether r may serve a read with the given freshness bound.
+func (rs *replicaSet) eligible(r *replica, fresh *time.Duration) bool {
+	if rs.interval <= 0 {
+		return fresh == nil
+	}
+	st := r.currentStatus()
+	if !st.LagKnown || (rs.maxLag > 0 && st.Lag > rs.maxLag) {
+		return false
+	}
+	return fresh == nil || st.Lag <= *fresh
+}
+
+// run measures replica lag until shutdown.
+func (rs *replicaSet) run() {
+	defer rs.wg.Done()
+	rs.check()
+
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rs.check()
+		case <-rs.stop:
+			return
+		}
+	}
+}
+
+// check measures the lag of every replica once.
+func (rs *replicaSet) check() {
+	rs.mu.RLock()
+	replicas := slices.Clone(rs.replicas)
+	probe := rs.probe
+	rs.mu.RUnlock()
+
+	for _, r := range replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		lag, err := probe(ctx, r.pool.sqlDB)
+		cancel()
+
+		r.mu.Lock()
+		r.status.CheckedAt = time.Now()
+		r.status.Err = err
+		r.status.LagKnown = err == nil
+		if err == nil {
+			r.status.Lag = lag
+		}
+		r.mu.Unlock()
+
+		if err != nil && rs.logger != nil {
+			rs.logger.Warn("replica lag check failed", "pool", r.pool.name, "error", err)
+		}
+	}
+}
+
+// shutdown stops the lag checks.
+func (rs *replicaSet) shutdown() {
+	if rs == nil || rs.stop == nil {
+		return
+	}
+	rs.stopOnce.Do(func() { close(rs.stop) })
+	rs.wg.Wait()
+}
+
+// currentStatus returns a copy of the replica's status.
+func (r *replica) currentStatus() ReplicaStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+// dialectLagProbe returns the lag measurement of dialect.
+func dialectLagProbe(dialect dialects.Dialect) lagProbe {
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		return postgresReplicaLag
+	case *dialects.MySQLDialect:
+		return mysqlReplicaLag
+	default:
+		// SQLite has no replication.
+		return func(context.Context, *sql.DB) (time.Duration, error) { return 0, nil }
+	}
+}
+
+// postgresReplicaLag returns the time since the last replayed transaction,
+// or 0 if the replica has replayed all WAL it received (or is a primary).
+func postgresReplicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var seconds sql.NullFloat64
+	err := db.QueryRowContext(ctx, "SELECT CASE WHEN NOT pg_is_in_recovery() "+
+		"OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 "+
+		"ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END").Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+	if !seconds.Valid {
+		return 0, errors.New("relica: replica has not replayed any transaction yet")
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)), nil
+}
+
+// mysqlReplicaLag returns Seconds_Behind_Source (Seconds_Behind_Master before
+// MySQL 8.0.22), or 0 for a server that is not a replica.
+func mysqlReplicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return 0, err
+		}
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	values := make([]sql.RawBytes, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+	for i, col := range cols {
+		if !strings.EqualFold(col, "Seconds_Behind_Source") && !strings.EqualFold(col, "Seconds_Behind_Master") {
+			continue
+		}
+		if values[i] == nil {
+			return 0, errors.New("relica: replication is not running")
+		}
+		var seconds int64
+		if _, err := fmt.Sscan(string(values[i]), &seconds); err != nil {
+			return 0, fmt.Errorf("relica: replica lag %q: %w", values[i], err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, errors.New("relica: replica status has no lag column")
+}
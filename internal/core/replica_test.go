@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupReplicaFiles creates SQLite files whose "servers" table holds one row
+// naming the file, so a query reveals which database served it.
+func setupReplicaFiles(t *testing.T, names ...string) []string {
+	t.Helper()
+	dsns := make([]string, len(names))
+	for i, name := range names {
+		dsns[i] = filepath.Join(t.TempDir(), name+".db")
+		db, err := sql.Open("sqlite", dsns[i])
+		require.NoError(t, err)
+		_, err = db.Exec(`CREATE TABLE servers (name TEXT)`)
+		require.NoError(t, err)
+		_, err = db.Exec(`INSERT INTO servers (name) VALUES (?)`, name)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+	}
+	return dsns
+}
+
+func servedBy(t *testing.T, sq *SelectQuery) string {
+	t.Helper()
+	var name string
+	require.NoError(t, sq.Row(&name))
+	return name
+}
+
+// setReplicaLag waits for the first lag check, then makes later checks
+// report lags (nil entries fail).
+func setReplicaLag(t *testing.T, db *DB, lags ...*time.Duration) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		for _, st := range db.Replicas() {
+			if st.CheckedAt.IsZero() {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+
+	rs := db.replicas
+	byPool := make(map[*sql.DB]*time.Duration, len(lags))
+	for i, r := range rs.replicas {
+		byPool[r.pool.sqlDB] = lags[i]
+	}
+	rs.mu.Lock()
+	rs.probe = func(_ context.Context, sqlDB *sql.DB) (time.Duration, error) {
+		if lag := byPool[sqlDB]; lag != nil {
+			return *lag, nil
+		}
+		return 0, errors.New("replica unreachable")
+	}
+	rs.mu.Unlock()
+	rs.check()
+}
+
+func lagOf(d time.Duration) *time.Duration { return &d }
+
+func TestReplicas_RoundRobinReads(t *testing.T) {
+	dsns := setupReplicaFiles(t, "primary", "replica-a", "replica-b")
+	db, err := Open("sqlite", dsns[0], WithReplicas(dsns[1:]...))
+	require.NoError(t, err)
+	defer db.Close()
+
+	seen := map[string]int{}
+	for i := 0; i < 4; i++ {
+		seen[servedBy(t, db.Builder().Select("name").From("servers"))]++
+	}
+	assert.Equal(t, map[string]int{"replica-a": 2, "replica-b": 2}, seen)
+
+	// Writes, raw SQL, transactions, locking reads and explicit pools stay put.
+	_, err = db.Builder().Insert("servers", map[string]interface{}{"name": "written"}).Execute()
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM servers").Row(&n))
+	assert.Equal(t, 2, n, "raw SQL runs on the primary")
+
+	require.NoError(t, db.Transactional(context.Background(), func(tx *Tx) error {
+		assert.Equal(t, "primary", servedBy(t, tx.Builder().Select("name").From("servers").OrderBy("rowid")))
+		return nil
+	}))
+	assert.Equal(t, "replica-b", servedBy(t, db.Builder().Select("name").From("servers").OnPool("replica-1")))
+	assert.Equal(t, "primary", servedBy(t, db.Builder().Select("name").From("servers").OrderBy("rowid").RequireFresh(time.Hour)),
+		"freshness cannot be proven without lag checks")
+
+	statuses := db.Replicas()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "replica-0", statuses[0].Pool)
+	assert.False(t, statuses[0].LagKnown)
+}
+
+func TestReplicas_LagAwareRouting(t *testing.T) {
+	dsns := setupReplicaFiles(t, "primary", "replica-a", "replica-b")
+	db, err := Open("sqlite", dsns[0], WithReplicas(dsns[1:]...), WithReplicaLagCheck(time.Hour, 10*time.Second))
+	require.NoError(t, err)
+	defer db.Close()
+
+	read := func() *SelectQuery { return db.Builder().Select("name").From("servers") }
+
+	setReplicaLag(t, db, lagOf(time.Second), lagOf(30*time.Second))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "replica-a", servedBy(t, read()), "replicas over the maximum lag serve no reads")
+	}
+	assert.Equal(t, "replica-a", servedBy(t, read().RequireFresh(2*time.Second)))
+	assert.Equal(t, "primary", servedBy(t, read().RequireFresh(500*time.Millisecond)))
+	n, err := read().Where("name = ?", "primary").RequireFresh(500 * time.Millisecond).Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "Count keeps the freshness requirement")
+	exists, err := read().Where("name = ?", "replica-a").RequireFresh(2 * time.Second).Exists()
+	require.NoError(t, err)
+	assert.True(t, exists, "Exists reads from a fresh enough replica")
+
+	setReplicaLag(t, db, nil, lagOf(0))
+	assert.Equal(t, "replica-b", servedBy(t, read().RequireFresh(0)))
+	statuses := db.Replicas()
+	assert.False(t, statuses[0].LagKnown)
+	assert.EqualError(t, statuses[0].Err, "replica unreachable")
+	assert.Equal(t, time.Duration(0), statuses[1].Lag)
+
+	setReplicaLag(t, db, nil, nil)
+	assert.Equal(t, "primary", servedBy(t, read()), "without a serving replica reads go to the primary")
+}
+
+func TestLockingReadRegex(t *testing.T) {
+	for _, q := range []string{
+		`SELECT * FROM t FOR UPDATE`,
+		`SELECT * FROM t FOR NO KEY UPDATE SKIP LOCKED`,
+		`SELECT * FROM t for share`,
+		`SELECT * FROM t LOCK IN SHARE MODE`,
+	} {
+		assert.True(t, lockingReadRegex.MatchString(q), q)
+	}
+	assert.False(t, lockingReadRegex.MatchString(`SELECT "for_update" FROM t`))
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Repository provides generic CRUD operations for the struct type T, built on
+// Model (writes) and TypedQuery (reads). It removes the per-model repository
+// boilerplate that otherwise wraps the builder by hand.
+//
+// T follows the Model conventions: the table is TableName() or the pluralized
+// struct name, and the primary key is the db:"...,pk" field (or ID).
+type Repository[T any] struct {
+	db *DB
+	tx *Tx
+}
+
+// NewRepository creates a repository for T.
+//
+// Example:
+//
+//	users := relica.NewRepository[User](db)
+//	user, err := users.Get(ctx, 42)
+func NewRepository[T any](db *DB) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+// WithTx returns a copy of the repository whose operations run in tx.
+//
+// Example:
+//
+//	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+//	    return users.WithTx(tx).Create(ctx, &user)
+//	})
+func (r *Repository[T]) WithTx(tx *Tx) *Repository[T] {
+	return &Repository[T]{db: r.db, tx: tx}
+}
+
+// Query returns a typed query for T, for reads not covered by Get and List.
+func (r *Repository[T]) Query() *TypedQuery[T] {
+	if r.tx != nil {
+		return NewTypedQuery[T](r.tx.Builder())
+	}
+	return NewTypedQuery[T](r.db.Builder())
+}
+
+// Get returns the row whose primary key equals id, or sql.ErrNoRows.
+// T must have a single-column primary key.
+func (r *Repository[T]) Get(ctx context.Context, id interface{}) (T, error) {
+	var zero T
+	pkCols, _, err := r.model(new(T)).getPrimaryKeys()
+	if err != nil {
+		return zero, fmt.Errorf("repository: primary key not found: %w", err)
+	}
+	if len(pkCols) != 1 {
+		return zero, errors.New("repository: Get requires a single-column primary key")
+	}
+	return r.Query().Where(Eq(pkCols[0], id)).One(ctx)
+}
+
+// List returns the rows matching filter (any Where condition: an Expression,
+// a HashExp or nil for all rows).
+//
+// Example:
+//
+//	active, err := users.List(ctx, relica.HashExp{"status": "active"})
+func (r *Repository[T]) List(ctx context.Context, filter interface{}) ([]T, error) {
+	q := r.Query()
+	if filter != nil {
+		q.Where(filter)
+	}
+	return q.All(ctx)
+}
+
+// Count returns the number of rows matching filter (nil counts all rows).
+func (r *Repository[T]) Count(ctx context.Context, filter interface{}) (int64, error) {
+	q := r.Query()
+	if filter != nil {
+		q.Where(filter)
+	}
+	return q.Count(ctx)
+}
+
+// Create inserts entity. An auto-increment primary key is set on entity.
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	return r.model(entity).SetContext(ctx).Insert()
+}
+
+// Update updates entity by primary key. If attrs are given, only those
+// columns are updated.
+func (r *Repository[T]) Update(ctx context.Context, entity *T, attrs ...string) error {
+	return r.model(entity).SetContext(ctx).Update(attrs...)
+}
+
+// Delete deletes entity by primary key.
+func (r *Repository[T]) Delete(ctx context.Context, entity *T) error {
+	return r.model(entity).SetContext(ctx).Delete()
+}
+
+// model returns a ModelQuery for entity in the repository's transaction, if any.
+func (r *Repository[T]) model(entity *T) *ModelQuery {
+	if r.tx != nil {
+		return r.tx.Model(entity)
+	}
+	return r.db.Model(entity)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type repoAccount struct {
+	ID      int64  `db:"id,pk"`
+	Email   string `db:"email"`
+	Balance int    `db:"balance"`
+}
+
+func (repoAccount) TableName() string { return "accounts" }
+
+func setupRepoTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.ExecContext(context.Background(),
+		`CREATE TABLE accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT, balance INTEGER)`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestRepository_CRUD(t *testing.T) {
+	db := setupRepoTestDB(t)
+	ctx := context.Background()
+	repo := NewRepository[repoAccount](db)
+
+	a := repoAccount{Email: "a@example.com", Balance: 10}
+	require.NoError(t, repo.Create(ctx, &a))
+	assert.Equal(t, int64(1), a.ID)
+	require.NoError(t, repo.Create(ctx, &repoAccount{Email: "b@example.com", Balance: 0}))
+
+	got, err := repo.Get(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, a, got)
+
+	a.Balance = 50
+	a.Email = "ignored@example.com"
+	require.NoError(t, repo.Update(ctx, &a, "balance"))
+	got, err = repo.Get(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 50, got.Balance)
+	assert.Equal(t, "a@example.com", got.Email)
+
+	rich, err := repo.List(ctx, HashExp{"balance": 50})
+	require.NoError(t, err)
+	require.Len(t, rich, 1)
+	assert.Equal(t, a.ID, rich[0].ID)
+
+	n, err := repo.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	require.NoError(t, repo.Delete(ctx, &a))
+	_, err = repo.Get(ctx, a.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	all, err := repo.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	emails := repo.Query().Select("email").OrderBy("id")
+	rows, err := emails.All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "b@example.com", rows[0].Email)
+}
+
+func TestRepository_WithTx(t *testing.T) {
+	db := setupRepoTestDB(t)
+	ctx := context.Background()
+	repo := NewRepository[repoAccount](db)
+
+	errAbort := errors.New("abort")
+	err := db.Transactional(ctx, func(tx *Tx) error {
+		txRepo := repo.WithTx(tx)
+		require.NoError(t, txRepo.Create(ctx, &repoAccount{Email: "tx@example.com"}))
+
+		n, err := txRepo.Count(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	n, err := repo.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n) // rolled back
+}
+
+func TestRepository_GetCompositePK(t *testing.T) {
+	db := setupRepoTestDB(t)
+
+	_, err := NewRepository[TestOrderItem](db).Get(context.Background(), 1)
+	assert.ErrorContains(t, err, "single-column primary key")
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ============================================================================
+// Statement retry
+// ============================================================================
+//
+// WithRetry re-runs a statement that failed with a transient error, such as a
+// connection reset by a cloud load balancer or a database failover. Only
+// statements that are safe to repeat are retried: read-only queries, and
+// writes marked with Query.Idempotent. Statements inside a transaction are
+// never retried, as the transaction is lost with its connection.
+//
+// A retry covers sending the statement and receiving its result set; errors
+// while iterating rows already returned are not retried.
+
+// RetryPolicy configures how statements are retried on transient errors.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Zero means 3.
+	MaxAttempts int
+
+	// InitialBackoff is the upper bound of the delay before the first retry.
+	// Zero means 50ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay, which doubles after every retry. Zero means 2s.
+	MaxBackoff time.Duration
+
+	// Retryable decides which errors are retried. Nil means IsTransientError.
+	Retryable func(error) bool
+}
+
+// Retry policy defaults.
+const (
+	defaultRetryAttempts       = 3
+	defaultRetryInitialBackoff = 50 * time.Millisecond
+	defaultRetryMaxBackoff     = 2 * time.Second
+)
+
+// WithRetry retries read-only statements, and writes marked with
+// Query.Idempotent, that fail with a transient error. The delay between
+// attempts is drawn at random up to an exponentially growing bound (full
+// jitter), so that clients dropped together do not reconnect together.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithRetry(relica.RetryPolicy{
+//	        MaxAttempts:    4,
+//	        InitialBackoff: 100 * time.Millisecond,
+//	        MaxBackoff:     time.Second,
+//	    }))
+func WithRetry(policy RetryPolicy) Option {
+	return optionFunc(func(db *DB) {
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = defaultRetryAttempts
+		}
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = defaultRetryInitialBackoff
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = defaultRetryMaxBackoff
+		}
+		if policy.Retryable == nil {
+			policy.Retryable = IsTransientError
+		}
+		db.retry = &policy
+	})
+}
+
+// Idempotent marks a write as safe to repeat, allowing WithRetry to retry it.
+// Only mark statements whose repetition has no further effect, such as an
+// UPDATE setting columns to fixed values or an upsert: a retried statement may
+// have been applied before its connection failed.
+//
+// Example:
+//
+//	_, err := db.Builder().Update("users").
+//	    Set(relica.Params{"status": "active"}).
+//	    Where(relica.HashExp{"id": id}).
+//	    Build().
+//	    Idempotent().
+//	    Execute()
+func (q *Query) Idempotent() *Query {
+	q.idempotent = true
+	return q
+}
+
+// transientMessages are driver error messages of dropped or unavailable connections.
+var transientMessages = []string{
+	"connection reset by peer",
+	"connection refused",
+	"broken pipe",
+	"bad connection",
+	"invalid connection",
+	"unexpected eof",
+	"i/o timeout",
+	"server closed the connection unexpectedly",
+	"terminating connection due to administrator command",
+	"the database system is starting up",
+	"the database system is shutting down",
+	"server has gone away",
+	"lost connection to mysql server",
+}
+
+// IsTransientError reports whether err is a transient connection failure that
+// may succeed when retried: driver.ErrBadConn, a reset, refused or closed
+// connection, or a network timeout. Returns false for nil errors and for
+// context cancellation, which is the caller giving up.
+//
+// Example:
+//
+//	if err := db.Builder().Select().From("users").All(&users); relica.IsTransientError(err) {
+//	    // the database was unreachable, not the query wrong
+//	}
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// modifyingCTERegex detects data-modifying statements within a WITH query.
+	modifyingCTERegex = regexp.MustCompile(`(?i)\b(insert|update|delete|merge)\b`)
+
+	// stringLiteralRegex matches SQL string literals.
+	stringLiteralRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+)
+
+// readOnlyStatement reports whether query only reads data. WITH queries
+// qualify unless they contain a data-modifying statement.
+func readOnlyStatement(query string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(query))
+	switch {
+	case strings.HasPrefix(upper, opSelect):
+		return true
+	case strings.HasPrefix(upper, "WITH"):
+		return !modifyingCTERegex.MatchString(stringLiteralRegex.ReplaceAllString(query, "''"))
+	}
+	return false
+}
+
+// retryable reports whether this query may be retried under the retry policy.
+func (q *Query) retryable() bool {
+	return q.db != nil && q.db.retry != nil && q.tx == nil && q.db.pinned == nil &&
+		(q.idempotent || readOnlyStatement(q.sql))
+}
+
+// withRetry runs fn, retrying it on transient errors if the query is retryable.
+func (q *Query) withRetry(ctx context.Context, fn func() error) error {
+	if !q.retryable() {
+		return fn()
+	}
+	return q.db.retry.run(ctx, fn)
+}
+
+// run calls fn until it succeeds, fails with a non-retryable error, the
+// attempts are exhausted, or ctx is done. It returns the last error.
+func (p *RetryPolicy) run(ctx context.Context, fn func() error) error {
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.Retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(rand.N(backoff) + 1) //nolint:gosec // jitter does not need a secure source
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, p.MaxBackoff)
+	}
+}
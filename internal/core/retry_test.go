@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("query: %w", syscall.ECONNRESET), true},
+		{&net.OpError{Op: "read", Err: syscall.ECONNREFUSED}, true},
+		{errors.New("write tcp 10.0.0.1:5432: write: broken pipe"), true},
+		{errors.New("FATAL: terminating connection due to administrator command (SQLSTATE 57P01)"), true},
+		{errors.New("[mysql] invalid connection"), true},
+		{errors.New("read tcp: i/o timeout"), true},
+		{context.Canceled, false},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{errors.New("duplicate key value violates unique constraint"), false},
+		{errors.New("no such table: users"), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsTransientError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestReadOnlyStatement(t *testing.T) {
+	assert.True(t, readOnlyStatement("  select * from users"))
+	assert.True(t, readOnlyStatement("WITH r AS (SELECT 1) SELECT * FROM r WHERE note = 'update me'"))
+	assert.False(t, readOnlyStatement("WITH d AS (DELETE FROM logs RETURNING id) SELECT count(*) FROM d"))
+	assert.False(t, readOnlyStatement("UPDATE users SET name = ?"))
+	assert.False(t, readOnlyStatement("INSERT INTO users (name) SELECT name FROM staging"))
+}
+
+func TestRetryPolicy_Run(t *testing.T) {
+	transient := errors.New("connection reset by peer")
+	db := &DB{}
+	WithRetry(RetryPolicy{InitialBackoff: time.Millisecond}).apply(db)
+	policy := db.retry
+	assert.Equal(t, defaultRetryAttempts, policy.MaxAttempts)
+	assert.Equal(t, defaultRetryMaxBackoff, policy.MaxBackoff)
+
+	t.Run("succeeds after transient errors", func(t *testing.T) {
+		calls := 0
+		err := policy.run(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := policy.run(context.Background(), func() error {
+			calls++
+			return transient
+		})
+		assert.ErrorIs(t, err, transient)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		calls := 0
+		syntax := errors.New("syntax error")
+		err := policy.run(context.Background(), func() error {
+			calls++
+			return syntax
+		})
+		assert.ErrorIs(t, err, syntax)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stops when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls := 0
+		err := policy.run(ctx, func() error {
+			calls++
+			return transient
+		})
+		assert.ErrorIs(t, err, transient)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestQuery_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	// The first failure of each statement creates the missing table, so the
+	// retry succeeds where the first attempt failed.
+	var failures int
+	var db *DB
+	db, err := Open("sqlite", ":memory:", WithRetry(RetryPolicy{
+		InitialBackoff: time.Millisecond,
+		Retryable: func(err error) bool {
+			failures++
+			_, _ = db.sqlDB.Exec("CREATE TABLE IF NOT EXISTS items (id INTEGER PRIMARY KEY, name TEXT)")
+			return strings.Contains(err.Error(), "no such table")
+		},
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	reset := func() {
+		failures = 0
+		_, _ = db.sqlDB.Exec("DROP TABLE IF EXISTS items")
+	}
+
+	t.Run("reads are retried", func(t *testing.T) {
+		reset()
+		var names []string
+		require.NoError(t, db.NewQuery("SELECT name FROM items").Column(&names))
+		assert.Equal(t, 1, failures)
+
+		reset()
+		rows, err := db.QueryContext(ctx, "SELECT name FROM items")
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+		assert.Equal(t, 1, failures)
+	})
+
+	t.Run("writes are not retried", func(t *testing.T) {
+		reset()
+		_, err := db.NewQuery("INSERT INTO items (name) VALUES ('a')").Execute()
+		require.Error(t, err)
+		assert.Equal(t, 0, failures)
+	})
+
+	t.Run("idempotent writes are retried", func(t *testing.T) {
+		reset()
+		_, err := db.NewQuery("INSERT OR REPLACE INTO items (id, name) VALUES (1, 'a')").Idempotent().Execute()
+		require.NoError(t, err)
+		assert.Equal(t, 1, failures)
+	})
+
+	t.Run("transactions are not retried", func(t *testing.T) {
+		reset()
+		err := db.Transactional(ctx, func(tx *Tx) error {
+			var names []string
+			return tx.Builder().Select("name").From("items").Column(&names)
+		})
+		require.Error(t, err)
+		assert.Equal(t, 0, failures)
+	})
+}
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ============================================================================
+// Row mappers
+// ============================================================================
+//
+// A RowMapper turns each raw result row into a value while the rows are being
+// read, so computed fields, decryption or denormalization happen in a single
+// pass without first scanning into an intermediate slice of structs.
+//
+// Register a mapper on a typed query with TypedQuery.Map, or run a SelectQuery
+// through one with MapRows.
+
+// RowMapper converts one raw result row into a T. Returning an error stops
+// the query; the error is returned wrapped by All/One.
+type RowMapper[T any] func(raw RowData) (T, error)
+
+// RowData is one result row as returned by the driver, keyed by column name.
+// Accessors return the zero value for NULL, missing or unconvertible columns.
+type RowData struct {
+	columns []string
+	values  []interface{}
+}
+
+// Columns returns the column names in result order.
+func (r RowData) Columns() []string {
+	return r.columns
+}
+
+// Value returns the raw driver value of col (int64, float64, bool, []byte,
+// string, time.Time or nil) and whether the column exists.
+func (r RowData) Value(col string) (interface{}, bool) {
+	for i, c := range r.columns {
+		if c == col {
+			return r.values[i], true
+		}
+	}
+	return nil, false
+}
+
+// Has reports whether the row contains col.
+func (r RowData) Has(col string) bool {
+	_, ok := r.Value(col)
+	return ok
+}
+
+// IsNull reports whether col is NULL or missing.
+func (r RowData) IsNull(col string) bool {
+	v, _ := r.Value(col)
+	return v == nil
+}
+
+// String returns col as a string.
+func (r RowData) String(col string) string {
+	switch v, _ := r.Value(col); x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// Bytes returns col as a byte slice.
+func (r RowData) Bytes(col string) []byte {
+	switch v, _ := r.Value(col); x := v.(type) {
+	case []byte:
+		return x
+	case string:
+		return []byte(x)
+	default:
+		return nil
+	}
+}
+
+// Int64 returns col as an int64.
+func (r RowData) Int64(col string) int64 {
+	switch v, _ := r.Value(col); x := v.(type) {
+	case int64:
+		return x
+	case float64:
+		return int64(x)
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	case string, []byte:
+		n, _ := strconv.ParseInt(r.String(col), 10, 64)
+		return n
+	default:
+		return 0
+	}
+}
+
+// Float64 returns col as a float64.
+func (r RowData) Float64(col string) float64 {
+	switch v, _ := r.Value(col); x := v.(type) {
+	case float64:
+		return x
+	case int64:
+		return float64(x)
+	case string, []byte:
+		f, _ := strconv.ParseFloat(r.String(col), 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+// Bool returns col as a bool. Numeric values are true when non-zero.
+func (r RowData) Bool(col string) bool {
+	switch v, _ := r.Value(col); x := v.(type) {
+	case bool:
+		return x
+	case int64:
+		return x != 0
+	case float64:
+		return x != 0
+	case string, []byte:
+		b, _ := strconv.ParseBool(r.String(col))
+		return b
+	default:
+		return false
+	}
+}
+
+// Time returns col as a time.Time.
+func (r RowData) Time(col string) time.Time {
+	switch v, _ := r.Value(col); x := v.(type) {
+	case time.Time:
+		return x
+	case string, []byte:
+		t, _ := time.Parse(time.RFC3339Nano, r.String(col))
+		return t
+	default:
+		return time.Time{}
+	}
+}
+
+// rowSink consumes result rows one at a time. Query.One and Query.All pass
+// the current row to a rowSink destination instead of scanning into it.
+type rowSink interface {
+	scanRow(rows *sql.Rows) error
+}
+
+// mapSink applies a RowMapper to each row and collects the results.
+type mapSink[T any] struct {
+	fn      RowMapper[T]
+	columns []string
+	out     []T
+}
+
+func (s *mapSink[T]) scanRow(rows *sql.Rows) error {
+	if s.columns == nil {
+		cols, err := rows.Columns()
+		if err != nil {
+			return fmt.Errorf("scanner: failed to get columns: %w", err)
+		}
+		s.columns = cols
+	}
+
+	values := make([]interface{}, len(s.columns))
+	scanDests := make([]interface{}, len(s.columns))
+	for i := range values {
+		scanDests[i] = &values[i]
+	}
+	if err := rows.Scan(scanDests...); err != nil {
+		return fmt.Errorf("scanner: scan failed: %w", err)
+	}
+
+	v, err := s.fn(RowData{columns: s.columns, values: values})
+	if err != nil {
+		return fmt.Errorf("relica: row mapper: %w", err)
+	}
+	s.out = append(s.out, v)
+	return nil
+}
+
+// columnSink is a rowSink that needs the result columns before the first
+// row, even when the result is empty.
+type columnSink interface {
+	rowSink
+	setColumns(cols []*sql.ColumnType) error
+}
+
+// scanSinkRows passes every remaining row to sink.
+func scanSinkRows(rows *sql.Rows, sink rowSink) error {
+	if cs, ok := sink.(columnSink); ok {
+		cols, err := rows.ColumnTypes()
+		if err != nil {
+			return fmt.Errorf("scanner: failed to get columns: %w", err)
+		}
+		if err := cs.setColumns(cols); err != nil {
+			return err
+		}
+	}
+	for rows.Next() {
+		if err := sink.scanRow(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// MapRows executes sq and returns fn applied to each row.
+//
+// Example:
+//
+//	users, err := relica.MapRows(db.Builder().Select("*").From("users"),
+//	    func(raw relica.RowData) (User, error) {
+//	        email, err := decrypt(raw.Bytes("email_enc"))
+//	        return User{ID: int(raw.Int64("id")), Email: email}, err
+//	    })
+func MapRows[T any](sq *SelectQuery, fn RowMapper[T]) ([]T, error) {
+	sink := &mapSink[T]{fn: fn}
+	if err := sq.All(sink); err != nil {
+		return nil, err
+	}
+	return sink.out, nil
+}
+
+// Map registers fn to build results from raw rows instead of scanning them
+// into T's fields. The SELECT list still defaults to T's columns; use Select
+// to read other columns.
+//
+// Example:
+//
+//	users, err := relica.Q[User](db).
+//	    Map(func(raw relica.RowData) (User, error) {
+//	        return User{ID: int(raw.Int64("id")), Name: strings.ToUpper(raw.String("name"))}, nil
+//	    }).
+//	    All(ctx)
+func (q *TypedQuery[T]) Map(fn RowMapper[T]) *TypedQuery[T] {
+	q.mapper = fn
+	return q
+}
+
+// mapAll runs the query through the registered mapper.
+func (q *TypedQuery[T]) mapAll(ctx context.Context) ([]T, error) {
+	sink := &mapSink[T]{fn: q.mapper}
+	if err := q.sq.WithContext(ctx).All(sink); err != nil {
+		return nil, err
+	}
+	q.sq.builder.db.fillEmptySlice(&sink.out)
+	return sink.out, nil
+}
+
+// mapOne runs the query through the registered mapper for the first row.
+func (q *TypedQuery[T]) mapOne(ctx context.Context) (T, error) {
+	sink := &mapSink[T]{fn: q.mapper}
+	if err := q.sq.WithContext(ctx).One(sink); err != nil {
+		var zero T
+		return zero, err
+	}
+	return sink.out[0], nil
+}
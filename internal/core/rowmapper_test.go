@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestMapRows(t *testing.T) {
+	db := setupTypedTestDB(t)
+
+	type authorCard struct {
+		Label string
+		Bio   string
+	}
+	cards, err := MapRows(db.Builder().Select("id", "name", "bio").From("authors").OrderBy("id"),
+		func(raw RowData) (authorCard, error) {
+			return authorCard{
+				Label: strings.ToUpper(raw.String("name")) + "#" + raw.String("id"),
+				Bio:   raw.String("bio"),
+			}, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []authorCard{{"ANN#1", "x"}, {"BOB#2", "y"}, {"CID#3", "z"}}, cards)
+
+	none, err := MapRows(db.Builder().Select().From("authors").Where("id > ?", 100),
+		func(raw RowData) (int64, error) { return raw.Int64("id"), nil })
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestMapRows_Errors(t *testing.T) {
+	db := setupTypedTestDB(t)
+	boom := errors.New("boom")
+
+	calls := 0
+	_, err := MapRows(db.Builder().Select().From("authors"), func(RowData) (int, error) {
+		calls++
+		return 0, boom
+	})
+	require.ErrorIs(t, err, boom)
+	assert.Contains(t, err.Error(), "row mapper")
+	assert.Equal(t, 1, calls, "mapping stops at the first error")
+
+	_, err = MapRows(db.Builder().Select().From("missing"), func(RowData) (int, error) { return 0, nil })
+	require.Error(t, err)
+}
+
+func TestMapRows_Chunked(t *testing.T) {
+	db := setupTypedTestDB(t)
+
+	names, err := MapRows(db.Builder().Select("name").From("authors").
+		WhereInChunked("id", []interface{}{1, 2, 3}, 2).OrderBy("id"),
+		func(raw RowData) (string, error) { return raw.String("name"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ann", "bob", "cid"}, names)
+
+	_, err = MapRows(db.Builder().Select("name").From("authors").
+		WhereInChunked("id", []interface{}{1, 2, 3}, 2).PreserveOrder(),
+		func(raw RowData) (string, error) { return raw.String("name"), nil })
+	require.Error(t, err)
+}
+
+func TestTypedQuery_Map(t *testing.T) {
+	db := setupTypedTestDB(t)
+	ctx := context.Background()
+
+	upper := func(raw RowData) (typedAuthor, error) {
+		return typedAuthor{
+			ID:     raw.Int64("id"),
+			Name:   strings.ToUpper(raw.String("name")),
+			Active: raw.Bool("active"),
+			Note:   raw.String("bio"),
+		}, nil
+	}
+
+	authors, err := NewTypedQuery[typedAuthor](db.Builder()).
+		Select("id", "name", "active", "bio").
+		Where(Eq("active", true)).OrderBy("id").
+		Map(upper).
+		All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []typedAuthor{
+		{ID: 1, Name: "ANN", Active: true, Note: "x"},
+		{ID: 3, Name: "CID", Active: true, Note: "z"},
+	}, authors)
+
+	bob, err := NewTypedQuery[typedAuthor](db.Builder()).Where(Eq("name", "bob")).Map(upper).One(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, typedAuthor{ID: 2, Name: "BOB"}, bob)
+
+	_, err = NewTypedQuery[typedAuthor](db.Builder()).Where("id > ?", 100).Map(upper).One(ctx)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRowData_Accessors(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	r := RowData{
+		columns: []string{"i", "f", "b", "s", "raw", "t", "n"},
+		values:  []interface{}{int64(7), 2.5, true, "42", []byte("1.5"), ts, nil},
+	}
+
+	assert.Equal(t, []string{"i", "f", "b", "s", "raw", "t", "n"}, r.Columns())
+	assert.True(t, r.Has("n"))
+	assert.False(t, r.Has("missing"))
+	assert.True(t, r.IsNull("n"))
+	assert.True(t, r.IsNull("missing"))
+	assert.False(t, r.IsNull("i"))
+
+	v, ok := r.Value("i")
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), v)
+
+	assert.Equal(t, "7", r.String("i"))
+	assert.Equal(t, "1.5", r.String("raw"))
+	assert.Equal(t, "", r.String("n"))
+	assert.Equal(t, ts.Format(time.RFC3339Nano), r.String("t"))
+
+	assert.Equal(t, int64(7), r.Int64("i"))
+	assert.Equal(t, int64(2), r.Int64("f"))
+	assert.Equal(t, int64(42), r.Int64("s"))
+	assert.Equal(t, int64(1), r.Int64("b"))
+	assert.Equal(t, int64(0), r.Int64("n"))
+
+	assert.InDelta(t, 2.5, r.Float64("f"), 1e-9)
+	assert.InDelta(t, 1.5, r.Float64("raw"), 1e-9)
+	assert.InDelta(t, 7.0, r.Float64("i"), 1e-9)
+
+	assert.True(t, r.Bool("b"))
+	assert.True(t, r.Bool("i"))
+	assert.False(t, r.Bool("n"))
+
+	assert.Equal(t, []byte("1.5"), r.Bytes("raw"))
+	assert.Equal(t, []byte("42"), r.Bytes("s"))
+	assert.Nil(t, r.Bytes("i"))
+
+	assert.Equal(t, ts, r.Time("t"))
+	assert.Equal(t, ts, (RowData{columns: []string{"t"}, values: []interface{}{"2026-03-04T05:06:07Z"}}).Time("t"))
+	assert.True(t, r.Time("n").IsZero())
+}
@@ -0,0 +1,309 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// Safe dynamic ORDER BY / filtering from user input
+// ============================================================================
+//
+// Sorting and filtering parameters usually come straight from query strings
+// (?sort=-created_at&status=active). Column names cannot be bound as
+// parameters, so every user-supplied identifier is resolved through an
+// explicit whitelist; values are always bound as parameters.
+
+// AllowedColumnSet is a whitelist mapping public (user-facing) names to columns.
+type AllowedColumnSet struct {
+	columns map[string]string
+}
+
+// AllowedColumns creates a whitelist where each public name maps to the column of the same name.
+//
+// Example:
+//
+//	allowed := relica.AllowedColumns("name", "created_at").
+//	    Alias("signup", "u.created_at")
+func AllowedColumns(columns ...string) *AllowedColumnSet {
+	a := &AllowedColumnSet{columns: make(map[string]string, len(columns))}
+	for _, col := range columns {
+		a.columns[col] = col
+	}
+	return a
+}
+
+// Alias allows a public name that maps to a different column (e.g. "signup" → "u.created_at").
+func (a *AllowedColumnSet) Alias(name, column string) *AllowedColumnSet {
+	a.columns[name] = column
+	return a
+}
+
+// Resolve returns the column for a public name and whether it is allowed.
+func (a *AllowedColumnSet) Resolve(name string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	col, ok := a.columns[name]
+	return col, ok
+}
+
+// parseSortTerms parses user sort input into "column DIRECTION" terms.
+//
+// Accepted forms (comma-separated): "name", "-created_at", "+name", "name desc", "name ASC".
+func parseSortTerms(input string, allowed *AllowedColumnSet) ([]string, error) {
+	var terms []string
+
+	for _, raw := range strings.Split(input, ",") {
+		fields := strings.Fields(raw)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("%w: invalid sort term %q", ErrColumnNotAllowed, strings.TrimSpace(raw))
+		}
+
+		name := fields[0]
+		direction := "ASC"
+		switch {
+		case strings.HasPrefix(name, "-"):
+			name, direction = name[1:], "DESC"
+		case strings.HasPrefix(name, "+"):
+			name = name[1:]
+		}
+
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+				direction = "ASC"
+			case "DESC":
+				direction = "DESC"
+			default:
+				return nil, fmt.Errorf("%w: invalid sort direction %q", ErrColumnNotAllowed, fields[1])
+			}
+		}
+
+		col, ok := allowed.Resolve(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrColumnNotAllowed, name)
+		}
+		terms = append(terms, col+" "+direction)
+	}
+
+	return terms, nil
+}
+
+// OrderBySafe adds ORDER BY terms parsed from untrusted input, resolving every
+// column through the allowed whitelist.
+//
+// Input is a comma-separated list of terms: "name", "-created_at" (descending),
+// "+name" or "name desc". Empty input adds nothing. An unknown column or malformed
+// term stores an error wrapping ErrColumnNotAllowed, returned at execution time.
+//
+// Example:
+//
+//	db.Select().From("users").
+//	    OrderBySafe(r.URL.Query().Get("sort"), relica.AllowedColumns("name", "created_at"))
+//	// ?sort=-created_at,name → ORDER BY "created_at" DESC, "name" ASC
+func (sq *SelectQuery) OrderBySafe(input string, allowed *AllowedColumnSet) *SelectQuery {
+	sq = sq.own()
+	terms, err := parseSortTerms(input, allowed)
+	if err != nil {
+		sq.buildErr = err
+		return sq
+	}
+	return sq.OrderBy(terms...)
+}
+
+// FilterOp is a filter operator accepted by FilterSpec.
+type FilterOp string
+
+// Filter operators. In query strings they are written as a bracket suffix:
+// "age[gte]=18". A parameter without suffix uses FilterEq (FilterIn if repeated),
+// or the parameter's only operator when exactly one is allowed ("q=ali" for a FilterLike field).
+const (
+	FilterEq   FilterOp = "eq"   // column = value
+	FilterNe   FilterOp = "ne"   // column <> value
+	FilterGt   FilterOp = "gt"   // column > value
+	FilterGte  FilterOp = "gte"  // column >= value
+	FilterLt   FilterOp = "lt"   // column < value
+	FilterLte  FilterOp = "lte"  // column <= value
+	FilterLike FilterOp = "like" // column LIKE %value% (escaped)
+	FilterIn   FilterOp = "in"   // column IN (values...), comma-separated or repeated
+)
+
+// filterField describes one filterable parameter.
+type filterField struct {
+	column string
+	ops    map[FilterOp]bool
+}
+
+// defaultOp returns the operator for a parameter given without a bracket suffix:
+// FilterEq (FilterIn when repeated), or the field's only operator if just one is allowed.
+func (f *filterField) defaultOp(valueCount int) FilterOp {
+	op := FilterEq
+	if valueCount > 1 {
+		op = FilterIn
+	}
+	if !f.ops[op] && len(f.ops) == 1 {
+		for only := range f.ops {
+			return only
+		}
+	}
+	return op
+}
+
+// FilterSpec maps validated user-supplied filter and sort parameters into WHERE/ORDER BY.
+// Only declared parameters and operators are accepted; all values are bound as parameters.
+// Parameters not mentioned in the spec (e.g. "page") are ignored.
+//
+// Example:
+//
+//	spec := relica.NewFilterSpec().
+//	    Allow("status", "status", relica.FilterEq, relica.FilterIn).
+//	    Allow("age", "age", relica.FilterGte, relica.FilterLte).
+//	    Allow("q", "name", relica.FilterLike).
+//	    SortBy("sort", relica.AllowedColumns("name", "created_at"))
+//
+//	// GET /users?status=active&age[gte]=18&q=ali&sort=-created_at
+//	db.Select().From("users").ApplyFilters(spec, r.URL.Query()).All(&users)
+type FilterSpec struct {
+	fields    map[string]*filterField
+	sortParam string
+	sortCols  *AllowedColumnSet
+}
+
+// NewFilterSpec creates an empty FilterSpec.
+func NewFilterSpec() *FilterSpec {
+	return &FilterSpec{fields: make(map[string]*filterField)}
+}
+
+// Allow declares a filterable parameter mapped to column with the permitted operators.
+// If no operators are given, only FilterEq is allowed.
+func (fs *FilterSpec) Allow(param, column string, ops ...FilterOp) *FilterSpec {
+	if len(ops) == 0 {
+		ops = []FilterOp{FilterEq}
+	}
+	field := &filterField{column: column, ops: make(map[FilterOp]bool, len(ops))}
+	for _, op := range ops {
+		field.ops[op] = true
+	}
+	fs.fields[param] = field
+	return fs
+}
+
+// SortBy declares the query parameter that carries sort input (see OrderBySafe)
+// and the columns it may reference.
+func (fs *FilterSpec) SortBy(param string, allowed *AllowedColumnSet) *FilterSpec {
+	fs.sortParam = param
+	fs.sortCols = allowed
+	return fs
+}
+
+// parseFilterKey splits "age[gte]" into ("age", "gte"). Keys without a suffix return an empty op.
+func parseFilterKey(key string) (param string, op FilterOp) {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 || !strings.HasSuffix(key, "]") {
+		return key, ""
+	}
+	return key[:open], FilterOp(strings.ToLower(key[open+1 : len(key)-1]))
+}
+
+// Expressions converts filter values (typically url.Values) into WHERE expressions.
+// Keys are processed in sorted order so the generated SQL is deterministic.
+// Returns an error wrapping ErrFilterNotAllowed if a declared parameter is used
+// with an operator that was not allowed.
+func (fs *FilterSpec) Expressions(values map[string][]string) ([]Expression, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var exps []Expression
+	for _, key := range keys {
+		vals := values[key]
+		param, op := parseFilterKey(key)
+		field, ok := fs.fields[param]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+
+		if op == "" {
+			op = field.defaultOp(len(vals))
+		}
+		if !field.ops[op] {
+			return nil, fmt.Errorf("%w: %s[%s]", ErrFilterNotAllowed, param, op)
+		}
+
+		exp, err := buildFilterExp(field.column, op, vals)
+		if err != nil {
+			return nil, err
+		}
+		exps = append(exps, exp)
+	}
+
+	return exps, nil
+}
+
+// buildFilterExp builds the expression for a single filter operator.
+func buildFilterExp(column string, op FilterOp, vals []string) (Expression, error) {
+	value := vals[0]
+	switch op {
+	case FilterEq:
+		return Eq(column, value), nil
+	case FilterNe:
+		return NotEq(column, value), nil
+	case FilterGt:
+		return GreaterThan(column, value), nil
+	case FilterGte:
+		return GreaterOrEqual(column, value), nil
+	case FilterLt:
+		return LessThan(column, value), nil
+	case FilterLte:
+		return LessOrEqual(column, value), nil
+	case FilterLike:
+		return Like(column, value), nil
+	case FilterIn:
+		var items []interface{}
+		for _, v := range vals {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		return In(column, items...), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrFilterNotAllowed, op)
+	}
+}
+
+// ApplyFilters adds WHERE conditions and ORDER BY terms derived from values
+// (typically r.URL.Query()) according to spec. Conditions are ANDed with any
+// existing WHERE clause. Validation errors are stored and returned at execution time.
+func (sq *SelectQuery) ApplyFilters(spec *FilterSpec, values map[string][]string) *SelectQuery {
+	sq = sq.own()
+	if spec == nil {
+		sq.buildErr = fmt.Errorf("relica: ApplyFilters requires a non-nil FilterSpec")
+		return sq
+	}
+
+	exps, err := spec.Expressions(values)
+	if err != nil {
+		sq.buildErr = err
+		return sq
+	}
+	for _, exp := range exps {
+		sq = sq.Where(exp)
+	}
+
+	if spec.sortParam != "" {
+		if sortVals := values[spec.sortParam]; len(sortVals) > 0 {
+			sq = sq.OrderBySafe(strings.Join(sortVals, ","), spec.sortCols)
+		}
+	}
+
+	return sq
+}
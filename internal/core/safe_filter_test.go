@@ -0,0 +1,117 @@
+package core
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderBySafe(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+	allowed := AllowedColumns("name", "created_at").Alias("signup", "u.created_at")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"single ascending", "name", ` ORDER BY "name" ASC`},
+		{"dash descending", "-created_at", ` ORDER BY "created_at" DESC`},
+		{"plus and direction words", "+name, created_at desc", ` ORDER BY "name" ASC, "created_at" DESC`},
+		{"alias", "-signup", ` ORDER BY "u"."created_at" DESC`},
+		{"empty input", "", ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := qb.Select("id").From("users").OrderBySafe(tt.input, allowed).Build()
+			require.NoError(t, q.prepErr)
+			assert.Equal(t, `SELECT "id" FROM "users"`+tt.expected, q.sql)
+		})
+	}
+}
+
+func TestOrderBySafe_Rejects(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+	allowed := AllowedColumns("name")
+
+	for _, input := range []string{
+		"password",
+		"name; DROP TABLE users",
+		"name sideways",
+		`"name"`,
+		"(SELECT 1)",
+	} {
+		t.Run(input, func(t *testing.T) {
+			q := qb.Select("id").From("users").OrderBySafe(input, allowed).Build()
+			require.Error(t, q.prepErr)
+			assert.True(t, errors.Is(q.prepErr, ErrColumnNotAllowed))
+		})
+	}
+}
+
+func TestApplyFilters(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	spec := NewFilterSpec().
+		Allow("status", "status", FilterEq, FilterIn).
+		Allow("age", "age", FilterGte, FilterLte).
+		Allow("q", "name", FilterLike).
+		SortBy("sort", AllowedColumns("name", "created_at"))
+
+	values := url.Values{}
+	values.Set("status", "active")
+	values.Set("age[gte]", "18")
+	values.Set("q", "ali")
+	values.Set("page", "2")
+	values.Set("sort", "-created_at")
+
+	q := qb.Select("id").From("users").Where("deleted_at IS NULL").ApplyFilters(spec, values).Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t,
+		`SELECT "id" FROM "users" WHERE deleted_at IS NULL AND "age" >= $1 AND "name" LIKE $2 AND "status" = $3 ORDER BY "created_at" DESC`,
+		q.sql)
+	assert.Equal(t, []interface{}{"18", "%ali%", "active"}, q.params)
+}
+
+func TestApplyFilters_InOperator(t *testing.T) {
+	db := mockDB("mysql")
+	qb := &QueryBuilder{db: db}
+	spec := NewFilterSpec().Allow("status", "status", FilterEq, FilterIn)
+
+	q := qb.Select("id").From("users").
+		ApplyFilters(spec, url.Values{"status[in]": {"active,pending"}}).Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "SELECT `id` FROM `users` WHERE `status` IN (?, ?)", q.sql)
+	assert.Equal(t, []interface{}{"active", "pending"}, q.params)
+
+	// Repeated parameter without suffix becomes IN.
+	q = qb.Select("id").From("users").
+		ApplyFilters(spec, url.Values{"status": {"a", "b"}}).Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, []interface{}{"a", "b"}, q.params)
+}
+
+func TestApplyFilters_Rejects(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+	spec := NewFilterSpec().
+		Allow("age", "age", FilterGte).
+		SortBy("sort", AllowedColumns("name"))
+
+	q := qb.Select("id").From("users").ApplyFilters(spec, url.Values{"age[lt]": {"5"}}).Build()
+	assert.True(t, errors.Is(q.prepErr, ErrFilterNotAllowed))
+
+	q = qb.Select("id").From("users").ApplyFilters(spec, url.Values{"sort": {"password"}}).Build()
+	assert.True(t, errors.Is(q.prepErr, ErrColumnNotAllowed))
+
+	q = qb.Select("id").From("users").ApplyFilters(nil, url.Values{}).Build()
+	assert.Error(t, q.prepErr)
+}
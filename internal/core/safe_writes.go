@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// Safe writes
+// ============================================================================
+//
+// With WithSafeWrites, an UPDATE or DELETE built without a WHERE condition
+// fails with ErrMissingWhere instead of changing every row of the table. A
+// Where with an empty expression (e.g. an empty HashExp built from a filter
+// map) adds no condition and is caught as well. Statements meant to touch
+// every row say so with AllowFullTableWrite; Truncate is always allowed.
+
+// WithSafeWrites makes UPDATE and DELETE statements without a WHERE
+// condition fail with ErrMissingWhere, unless AllowFullTableWrite is called
+// on the query.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithSafeWrites())
+//
+//	_, err := db.Delete("sessions").Execute()
+//	// errors.Is(err, relica.ErrMissingWhere)
+//
+//	_, err = db.Delete("sessions").AllowFullTableWrite().Execute() // deletes all sessions
+func WithSafeWrites() Option {
+	return optionFunc(func(db *DB) {
+		db.safeWrites = true
+	})
+}
+
+// AllowFullTableWrite allows the UPDATE to change every row of the table
+// when it has no WHERE condition (see WithSafeWrites).
+func (uq *UpdateQuery) AllowFullTableWrite() *UpdateQuery {
+	uq.allowAll = true
+	return uq
+}
+
+// AllowFullTableWrite allows the DELETE to remove every row of the table
+// when it has no WHERE condition (see WithSafeWrites).
+func (dq *DeleteQuery) AllowFullTableWrite() *DeleteQuery {
+	dq.allowAll = true
+	return dq
+}
+
+// requireWhere returns ErrMissingWhere for an UPDATE or DELETE of table
+// without a WHERE condition in safe-writes mode, unless allowed.
+func (db *DB) requireWhere(op, table string, where []string, allowed bool) error {
+	if !db.safeWrites || allowed {
+		return nil
+	}
+	for _, cond := range where {
+		if strings.TrimSpace(cond) != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("relica: %s %s has no WHERE condition; call AllowFullTableWrite to change every row: %w", op, table, ErrMissingWhere)
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeWrites(t *testing.T) {
+	db := mockDB("postgres")
+	WithSafeWrites().apply(db)
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Delete("sessions").Build()
+	assert.ErrorIs(t, q.prepErr, ErrMissingWhere)
+	assert.EqualError(t, q.prepErr, "relica: DELETE sessions has no WHERE condition; call AllowFullTableWrite to change every row: relica: missing WHERE condition")
+
+	q = qb.Update("users").Set(map[string]interface{}{"active": false}).Build()
+	assert.ErrorIs(t, q.prepErr, ErrMissingWhere)
+
+	// An empty filter adds no condition
+	q = qb.Delete("sessions").Where(HashExp{}).Build()
+	assert.ErrorIs(t, q.prepErr, ErrMissingWhere)
+
+	q = qb.Delete("sessions").Where(Eq("user_id", 1)).Build()
+	assert.NoError(t, q.prepErr)
+	q = qb.Update("users").Set(map[string]interface{}{"active": false}).Where("id = ?", 1).Build()
+	assert.NoError(t, q.prepErr)
+
+	q = qb.Delete("sessions").AllowFullTableWrite().Build()
+	assert.NoError(t, q.prepErr)
+	assert.Equal(t, `DELETE FROM "sessions"`, q.sql)
+	q = qb.Update("users").Set(map[string]interface{}{"active": false}).AllowFullTableWrite().Build()
+	assert.NoError(t, q.prepErr)
+
+	q = qb.Truncate("sessions").Build()
+	assert.NoError(t, q.prepErr)
+}
+
+func TestSafeWrites_Disabled(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	assert.NoError(t, qb.Delete("sessions").Build().prepErr)
+	assert.NoError(t, qb.Update("users").Set(map[string]interface{}{"active": false}).Build().prepErr)
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Random ordering and sampling
+// ============================================================================
+//
+// OrderByRandom and Sample render the dialect's own random functions, so
+// sampling queries stay portable. PostgreSQL samples with TABLESAMPLE
+// BERNOULLI, which picks each row of the FROM table with the given
+// probability; MySQL and SQLite have no TABLESAMPLE and get the same per-row
+// filter as a WHERE predicate on RAND() / RANDOM(). In both cases the number
+// of rows returned varies around the percentage; combine OrderByRandom with
+// Limit for an exact count.
+
+// OrderByRandom adds a random term to the ORDER BY clause: RANDOM() on
+// PostgreSQL and SQLite, RAND() on MySQL. Random ordering sorts the whole
+// result, so prefer Sample on large tables.
+//
+// Example:
+//
+//	var winners []User
+//	err := db.Select().From("users").Where("opted_in = ?", true).
+//	    OrderByRandom().Limit(3).All(&winners)
+func (sq *SelectQuery) OrderByRandom() *SelectQuery {
+	sq = sq.own()
+	sq.subOrderByExprs = append(sq.subOrderByExprs, randomExp{})
+	return sq
+}
+
+// Sample reads about percent (0 < percent <= 100) percent of the rows of the
+// FROM table, each row chosen independently. PostgreSQL renders TABLESAMPLE
+// BERNOULLI; MySQL and SQLite filter rows with a random predicate. Count and
+// Exists sample the rows in the same way.
+//
+// Example:
+//
+//	var events []Event
+//	err := db.Select().From("events").Sample(1).All(&events)
+//	// PostgreSQL: SELECT * FROM "events" TABLESAMPLE BERNOULLI (1)
+//	// MySQL:      SELECT * FROM `events` WHERE RAND() < 0.01
+func (sq *SelectQuery) Sample(percent float64) *SelectQuery {
+	sq = sq.own()
+	if !(percent > 0 && percent <= 100) {
+		sq.buildErr = fmt.Errorf("relica: Sample() percent must be in (0, 100], got %v", percent)
+		return sq
+	}
+	sq.samplePct = &percent
+	return sq
+}
+
+// randomExp is the dialect's random number function.
+type randomExp struct{}
+
+// Build renders RAND() on MySQL and RANDOM() elsewhere.
+func (randomExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	if _, ok := dialect.(*dialects.MySQLDialect); ok {
+		return "RAND()", nil
+	}
+	return "RANDOM()", nil
+}
+
+// buildTableSample returns the TABLESAMPLE clause following the FROM table on
+// PostgreSQL, or "" when the query is not sampled or the dialect filters in
+// WHERE instead.
+func (sq *SelectQuery) buildTableSample(dialect dialects.Dialect) (string, error) {
+	if sq.samplePct == nil {
+		return "", nil
+	}
+	if _, ok := dialect.(*dialects.PostgresDialect); !ok {
+		return "", nil
+	}
+	if sq.asOf != nil {
+		return "", fmt.Errorf("relica: Sample cannot be combined with AsOf on PostgreSQL")
+	}
+	return " TABLESAMPLE BERNOULLI (" + strconv.FormatFloat(*sq.samplePct, 'f', -1, 64) + ")", nil
+}
+
+// samplePredicate returns the WHERE predicate that samples rows on MySQL and
+// SQLite, or "" when the query is not sampled or the dialect uses TABLESAMPLE.
+func (sq *SelectQuery) samplePredicate(dialect dialects.Dialect) string {
+	if sq.samplePct == nil {
+		return ""
+	}
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		return ""
+	case *dialects.MySQLDialect:
+		return "RAND() < " + strconv.FormatFloat(*sq.samplePct/100, 'f', -1, 64)
+	default:
+		// RANDOM() is a 64-bit integer; reduce it before ABS, which overflows
+		// on the minimum value.
+		return "ABS(RANDOM() % 1000000) < " + strconv.FormatInt(int64(*sq.samplePct*10000), 10)
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderByRandom_SQL(t *testing.T) {
+	tests := map[string]string{
+		"postgres": `SELECT * FROM "users" ORDER BY "name", RANDOM() LIMIT 3`,
+		"mysql":    "SELECT * FROM `users` ORDER BY `name`, RAND() LIMIT 3",
+		"sqlite":   `SELECT * FROM "users" ORDER BY "name", RANDOM() LIMIT 3`,
+	}
+	for dialect, want := range tests {
+		qb := &QueryBuilder{db: mockDB(dialect)}
+		assert.Equal(t, want, qb.Select().From("users").OrderBy("name").OrderByRandom().Limit(3).Build().SQL(), dialect)
+	}
+}
+
+func TestSample_SQL(t *testing.T) {
+	tests := map[string]string{
+		"postgres": `SELECT * FROM "events" AS "e" TABLESAMPLE BERNOULLI (2.5) WHERE kind = $1`,
+		"mysql":    "SELECT * FROM `events` AS `e` WHERE kind = ? AND RAND() < 0.025",
+		"sqlite":   `SELECT * FROM "events" AS "e" WHERE kind = ? AND ABS(RANDOM() % 1000000) < 25000`,
+	}
+	for dialect, want := range tests {
+		qb := &QueryBuilder{db: mockDB(dialect)}
+		q := qb.Select().From("events e").Where("kind = ?", "click").Sample(2.5).Build()
+		assert.Equal(t, want, q.SQL(), dialect)
+		assert.Equal(t, []interface{}{"click"}, q.Params(), dialect)
+	}
+
+	pg := &QueryBuilder{db: mockDB("postgres")}
+	assert.Equal(t, `SELECT COUNT(*) FROM "events" TABLESAMPLE BERNOULLI (1)`,
+		pg.Select("id").From("events").Sample(1).OrderBy("id").countQuery().Build().SQL())
+
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+	assert.Equal(t, `SELECT * FROM "events" WHERE ABS(RANDOM() % 1000000) < 1000000`,
+		qb.Select().From("events").Sample(100).Build().SQL())
+
+	var n int
+	assert.ErrorContains(t, qb.Select().From("events").Sample(0).Row(&n), "percent must be in (0, 100]")
+	assert.ErrorContains(t, qb.Select().From("events").Sample(120).Row(&n), "percent must be in (0, 100]")
+	assert.ErrorContains(t, qb.Select().FromSelect(qb.Select().From("events"), "e").Sample(5).Row(&n),
+		"Sample requires a FROM table")
+
+	hist := &QueryBuilder{db: &DB{dialect: pg.db.dialect, history: map[string][]string{"events": {"id"}}}}
+	assert.ErrorContains(t, hist.Select().From("events").AsOf(time.Now()).Sample(5).Row(&n),
+		"Sample cannot be combined with AsOf on PostgreSQL")
+}
+
+func TestSample_SQLite(t *testing.T) {
+	db := setupNullColumnDB(t)
+
+	var ids []int
+	require.NoError(t, db.Builder().Select("id").From("players").Sample(100).OrderBy("id").Column(&ids))
+	assert.Equal(t, []int{1, 2, 3}, ids)
+
+	ids = nil
+	require.NoError(t, db.Builder().Select("id").From("players").OrderByRandom().Column(&ids))
+	assert.ElementsMatch(t, []int{1, 2, 3}, ids)
+
+	n, err := db.Builder().Select().From("players").Sample(100).Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	n, err = db.Builder().Select().From("players").Sample(0.0001).Count()
+	require.NoError(t, err)
+	assert.Less(t, n, int64(3), "Count samples the rows it counts")
+}
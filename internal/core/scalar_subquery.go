@@ -0,0 +1,75 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ScalarSubqueryExp is a subquery that returns a single value. It can be
+// selected as a column (SelectExp) or used as the value of a comparison
+// (Eq, GreaterThan, ...). Its parameters are merged with the enclosing
+// query's in clause order and renumbered for the dialect.
+//
+// The subquery may reference columns of the enclosing query (a correlated
+// subquery); it must return at most one row and one column.
+type ScalarSubqueryExp struct {
+	query *SelectQuery
+	alias string
+}
+
+// ScalarSubquery wraps sub as a scalar subquery expression.
+//
+// Example:
+//
+//	latest := db.Builder().Select("MAX(o.created_at)").From("orders o").
+//	    Where(relica.EqCol("o.user_id", "u.id"))
+//	db.Builder().Select("u.id", "u.name").
+//	    SelectExp(relica.ScalarSubquery(latest).As("latest_order_at")).
+//	    From("users u")
+//	// SELECT "u"."id", "u"."name", (SELECT MAX(o.created_at) FROM "orders" AS "o"
+//	//   WHERE "o"."user_id" = "u"."id") AS "latest_order_at" FROM "users" AS "u"
+//
+//	avg := db.Builder().Select("AVG(a2.balance)").From("accounts a2").
+//	    Where(relica.EqCol("a2.region", "a.region"))
+//	db.Builder().Select("a.id").From("accounts a").
+//	    Where(relica.GreaterThan("a.balance", relica.ScalarSubquery(avg)))
+func ScalarSubquery(sub *SelectQuery) *ScalarSubqueryExp {
+	return &ScalarSubqueryExp{query: sub}
+}
+
+// As returns a copy of the expression selected under alias. The alias only
+// applies in the SELECT list; comparisons use the bare subquery.
+func (e *ScalarSubqueryExp) As(alias string) *ScalarSubqueryExp {
+	c := *e
+	c.alias = alias
+	return &c
+}
+
+// Build converts the scalar subquery into "(SELECT ...)", followed by
+// "AS alias" if an alias is set.
+func (e *ScalarSubqueryExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	sql, args := e.operand(dialect)
+	if e.alias != "" {
+		sql += " AS " + dialect.QuoteIdentifier(e.alias)
+	}
+	return sql, args
+}
+
+// operand returns the parenthesized subquery without its alias.
+func (e *ScalarSubqueryExp) operand(dialect dialects.Dialect) (string, []interface{}) {
+	if e.query == nil {
+		return "NULL", nil
+	}
+	sql, args := e.query.fragmentSQL(dialect)
+	return "(" + sql + ")", args
+}
+
+// checkDialect implements checkedExpression: a nil subquery or one with a
+// stored build error fails the enclosing query.
+func (e *ScalarSubqueryExp) checkDialect(_ dialects.Dialect) error {
+	if e.query == nil {
+		return errors.New("relica: ScalarSubquery requires a non-nil query")
+	}
+	return e.query.buildErr
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 COREGX. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScalarSubquery_SelectWithAlias(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	latest := qb.Select("MAX(o.created_at)").From("orders o").
+		Where(And(EqCol("o.user_id", "u.id"), Eq("o.status", "paid")))
+	q := qb.Select("u.id").
+		SelectExp(ScalarSubquery(latest).As("latest_order_at")).
+		From("users u").
+		Where(Eq("u.active", true)).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `SELECT "u"."id", (SELECT MAX(o.created_at) FROM "orders" AS "o" WHERE ("o"."user_id" = "u"."id") AND ("o"."status" = $1)) AS "latest_order_at" FROM "users" AS "u" WHERE "u"."active" = $2`, q.sql)
+	assert.Equal(t, []interface{}{"paid", true}, q.params)
+}
+
+func TestScalarSubquery_Comparison(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	avg := qb.Select("AVG(a2.balance)").From("accounts a2").
+		Where(And(EqCol("a2.region", "a.region"), Eq("a2.kind", "retail")))
+	q := qb.Select("a.id").From("accounts a").
+		Where(Eq("a.flag", 1)).
+		Where(GreaterThan("a.balance", ScalarSubquery(avg).As("ignored"))).
+		Where("a.score > ?", 5).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `SELECT "a"."id" FROM "accounts" AS "a" WHERE "a"."flag" = $1 AND "a"."balance" > (SELECT AVG(a2.balance) FROM "accounts" AS "a2" WHERE ("a2"."region" = "a"."region") AND ("a2"."kind" = $2)) AND a.score > $3`, q.sql)
+	assert.Equal(t, []interface{}{1, "retail", 5}, q.params)
+}
+
+func TestScalarSubquery_MySQLPlaceholders(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("mysql")}
+
+	sub := qb.Select("COUNT(*)").From("orders").Where(Eq("status", "paid"))
+	q := qb.Select("id").SelectExp(ScalarSubquery(sub).As("paid_orders")).From("users").
+		Where(Eq("id", 7)).Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "SELECT `id`, (SELECT COUNT(*) FROM `orders` WHERE `status` = ?) AS `paid_orders` FROM `users` WHERE `id` = ?", q.sql)
+	assert.Equal(t, []interface{}{"paid", 7}, q.params)
+}
+
+func TestSubqueryExpressions_PlaceholdersMergeWithEnclosingQuery(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	sub := qb.Select("user_id").From("orders").Where(Eq("status", "paid"))
+	q := qb.Select("id").From("users").
+		Where(Eq("active", true)).
+		Where(In("id", sub)).
+		Where(Exists(qb.Select("1").From("bans").Where(Eq("reason", "spam")).AsExpression())).
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE "active" = $1 AND "id" IN (SELECT "user_id" FROM "orders" WHERE "status" = $2) AND EXISTS (SELECT 1 FROM "bans" WHERE "reason" = $3)`, q.sql)
+	assert.Equal(t, []interface{}{true, "paid", "spam"}, q.params)
+}
+
+func TestScalarSubquery_Errors(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Select("id").From("users").Where(GreaterThan("balance", ScalarSubquery(nil))).Build()
+	assert.EqualError(t, q.prepErr, "relica: ScalarSubquery requires a non-nil query")
+
+	bad := qb.Select("id").From("orders").SelectSub(Eq("a", 1), "")
+	q = qb.Select("id").SelectExp(ScalarSubquery(bad).As("x")).From("users").Build()
+	assert.EqualError(t, q.prepErr, "relica: SelectSub requires a non-empty alias")
+}
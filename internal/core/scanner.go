@@ -6,6 +6,8 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+
+	"github.com/coregx/relica/internal/util"
 )
 
 // scanner handles reflection-based scanning of SQL rows into structs.
@@ -29,7 +31,8 @@ type structInfo struct {
 // fieldInfo describes how to scan into a struct field.
 type fieldInfo struct {
 	index  []int  // field index path for nested structs
-	dbName string // column name from db:"" tag or field name
+	dbName string // lowercased column name, used to match result columns
+	column string // column name from db:"" tag or the naming strategy
 	field  reflect.StructField
 }
 
@@ -43,6 +46,13 @@ func newScanner() *scanner {
 // globalScanner is the global scanner instance.
 var globalScanner = newScanner()
 
+// reset drops all cached struct metadata.
+func (s *scanner) reset() {
+	s.mu.Lock()
+	s.cache = make(map[reflect.Type]*structInfo)
+	s.mu.Unlock()
+}
+
 // getStructInfo returns cached struct metadata or builds it.
 func (s *scanner) getStructInfo(typ reflect.Type) (*structInfo, error) {
 	// Fast path: check cache with read lock
@@ -106,9 +116,9 @@ func (s *scanner) buildStructInfo(typ reflect.Type, index []int) (*structInfo, e
 			continue
 		}
 
-		// Get column name from db:"" tag or use field name
+		// Get column name from db:"" tag or the naming strategy
 		// Handles: "column", "column,pk", "-"
-		dbName := field.Name
+		dbName := util.FieldColumn(field.Name)
 		if tag, ok := field.Tag.Lookup("db"); ok {
 			column := parseDBTagForScanner(tag)
 			if column == "-" {
@@ -121,6 +131,7 @@ func (s *scanner) buildStructInfo(typ reflect.Type, index []int) (*structInfo, e
 		info.fields = append(info.fields, &fieldInfo{
 			index:  fieldIndex,
 			dbName: strings.ToLower(dbName), // normalize to lowercase
+			column: dbName,
 			field:  field,
 		})
 	}
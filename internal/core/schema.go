@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ============================================================================
+// Schema helpers
+// ============================================================================
+//
+// Schema groups DDL helpers for database objects managed through the
+// builder. Materialized views are PostgreSQL-only; on other databases the
+// helpers return ErrUnsupportedDialect instead of failing with a syntax error.
+
+// Schema manages database objects (see DB.Schema).
+type Schema struct {
+	db *DB
+}
+
+// Schema returns the schema helpers of the database.
+func (db *DB) Schema() *Schema {
+	return &Schema{db: db}
+}
+
+// ViewOption configures materialized view creation and refresh.
+type ViewOption func(*viewOptions)
+
+type viewOptions struct {
+	concurrently bool
+	noData       bool
+}
+
+// Concurrently refreshes a materialized view without locking out readers
+// (REFRESH MATERIALIZED VIEW CONCURRENTLY). PostgreSQL requires a unique
+// index on the view and a view that is already populated.
+func Concurrently() ViewOption {
+	return func(o *viewOptions) {
+		o.concurrently = true
+	}
+}
+
+// WithNoData creates or refreshes a materialized view without running its
+// query, leaving it unreadable until the next refresh.
+func WithNoData() ViewOption {
+	return func(o *viewOptions) {
+		o.noData = true
+	}
+}
+
+// CreateMaterializedView creates the named materialized view from query if
+// it does not exist, and populates it unless WithNoData is given. The query
+// cannot have bind parameters, as PostgreSQL does not accept them in DDL;
+// write constants into the query instead.
+//
+// Example:
+//
+//	daily := db.Select("date_trunc('day', created_at) AS day", "SUM(total) AS revenue").
+//	    From("orders").
+//	    GroupBy("day")
+//	err := db.Schema().CreateMaterializedView(ctx, "daily_revenue", daily)
+func (s *Schema) CreateMaterializedView(ctx context.Context, name string, query *SelectQuery, opts ...ViewOption) error {
+	if err := s.db.requireMaterializedViews(); err != nil {
+		return err
+	}
+	if query == nil {
+		return errors.New("relica: create materialized view " + name + ": query is nil")
+	}
+	o := applyViewOptions(opts)
+
+	q := query.Build()
+	if q.prepErr != nil {
+		return fmt.Errorf("relica: create materialized view %s: %w", name, q.prepErr)
+	}
+	if len(q.params) > 0 {
+		return fmt.Errorf("relica: create materialized view %s: query has %d bind parameters; views cannot take parameters", name, len(q.params))
+	}
+
+	if _, err := s.db.ExecContext(ctx, createMaterializedViewSQL(s.db, name, q.sql, o)); err != nil {
+		return fmt.Errorf("relica: create materialized view %s: %w", name, err)
+	}
+	return nil
+}
+
+// DropMaterializedView drops the named materialized view if it exists.
+func (s *Schema) DropMaterializedView(ctx context.Context, name string) error {
+	if err := s.db.requireMaterializedViews(); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, "DROP MATERIALIZED VIEW IF EXISTS "+s.db.dialect.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("relica: drop materialized view %s: %w", name, err)
+	}
+	return nil
+}
+
+// RefreshMaterializedView re-runs the query of the named materialized view
+// and replaces its contents. A plain refresh locks out readers until it is
+// done; with Concurrently they keep reading the old contents.
+//
+// Example:
+//
+//	err := db.RefreshMaterializedView(ctx, "daily_revenue", relica.Concurrently())
+func (db *DB) RefreshMaterializedView(ctx context.Context, name string, opts ...ViewOption) error {
+	if err := db.requireMaterializedViews(); err != nil {
+		return err
+	}
+	o := applyViewOptions(opts)
+	if o.concurrently && o.noData {
+		return errors.New("relica: refresh materialized view " + name + ": Concurrently cannot be combined with WithNoData")
+	}
+
+	if _, err := db.ExecContext(ctx, refreshMaterializedViewSQL(db, name, o)); err != nil {
+		return fmt.Errorf("relica: refresh materialized view %s: %w", name, err)
+	}
+	return nil
+}
+
+// requireMaterializedViews returns ErrUnsupportedDialect unless the database
+// is PostgreSQL.
+func (db *DB) requireMaterializedViews() error {
+	if !isPostgres(db.dialect) {
+		return fmt.Errorf("relica: materialized views are only supported on PostgreSQL, not %s: %w", db.driverName, ErrUnsupportedDialect)
+	}
+	return nil
+}
+
+func applyViewOptions(opts []ViewOption) viewOptions {
+	var o viewOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// createMaterializedViewSQL returns the CREATE MATERIALIZED VIEW statement.
+func createMaterializedViewSQL(db *DB, name, query string, o viewOptions) string {
+	ddl := "CREATE MATERIALIZED VIEW IF NOT EXISTS " + db.dialect.QuoteIdentifier(name) + " AS " + query
+	if o.noData {
+		ddl += " WITH NO DATA"
+	}
+	return ddl
+}
+
+// refreshMaterializedViewSQL returns the REFRESH MATERIALIZED VIEW statement.
+func refreshMaterializedViewSQL(db *DB, name string, o viewOptions) string {
+	ddl := "REFRESH MATERIALIZED VIEW "
+	if o.concurrently {
+		ddl += "CONCURRENTLY "
+	}
+	ddl += db.dialect.QuoteIdentifier(name)
+	if o.noData {
+		ddl += " WITH NO DATA"
+	}
+	return ddl
+}
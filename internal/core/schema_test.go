@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaterializedViewSQL(t *testing.T) {
+	db := mockDB("postgres")
+
+	assert.Equal(t, `CREATE MATERIALIZED VIEW IF NOT EXISTS "daily" AS SELECT 1`,
+		createMaterializedViewSQL(db, "daily", "SELECT 1", applyViewOptions(nil)))
+	assert.Equal(t, `CREATE MATERIALIZED VIEW IF NOT EXISTS "daily" AS SELECT 1 WITH NO DATA`,
+		createMaterializedViewSQL(db, "daily", "SELECT 1", applyViewOptions([]ViewOption{WithNoData()})))
+
+	assert.Equal(t, `REFRESH MATERIALIZED VIEW "daily"`,
+		refreshMaterializedViewSQL(db, "daily", applyViewOptions(nil)))
+	assert.Equal(t, `REFRESH MATERIALIZED VIEW CONCURRENTLY "daily"`,
+		refreshMaterializedViewSQL(db, "daily", applyViewOptions([]ViewOption{Concurrently()})))
+	assert.Equal(t, `REFRESH MATERIALIZED VIEW "daily" WITH NO DATA`,
+		refreshMaterializedViewSQL(db, "daily", applyViewOptions([]ViewOption{WithNoData()})))
+}
+
+func TestMaterializedView_Validation(t *testing.T) {
+	ctx := context.Background()
+	db := mockDB("postgres")
+
+	err := db.Schema().CreateMaterializedView(ctx, "active", db.Builder().Select().From("users").Where("active = ?", true))
+	assert.ErrorContains(t, err, "views cannot take parameters")
+	err = db.Schema().CreateMaterializedView(ctx, "active", nil)
+	assert.ErrorContains(t, err, "query is nil")
+	err = db.RefreshMaterializedView(ctx, "active", Concurrently(), WithNoData())
+	assert.ErrorContains(t, err, "cannot be combined")
+
+	sqlite, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer sqlite.Close()
+	err = sqlite.Schema().CreateMaterializedView(ctx, "active", sqlite.Builder().Select().From("users"))
+	assert.ErrorIs(t, err, ErrUnsupportedDialect)
+	assert.ErrorIs(t, sqlite.RefreshMaterializedView(ctx, "active"), ErrUnsupportedDialect)
+	assert.ErrorIs(t, sqlite.Schema().DropMaterializedView(ctx, "active"), ErrUnsupportedDialect)
+}
@@ -0,0 +1,564 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coregx/relica/internal/util"
+)
+
+// =============================================================================
+// Seeding
+// =============================================================================
+//
+// Seed generates rows from a model's struct fields and bulk-inserts them.
+// Values come from, in order of precedence:
+//
+//  1. a Faker set with WithFaker (returning ok=false falls through)
+//  2. the field's seed tag:
+//     seed:"-"              leave the column to its database default
+//     seed:"email"          a named generator (see seedKinds)
+//     seed:"oneof:a|b|c"    one of the listed values
+//     seed:"range:18,90"    a number in the inclusive range
+//  3. the column name (email, first_name, city, ...) and the field type
+//
+// Generated emails, usernames and UUIDs are unique per Seed call. Untagged
+// fields of types without a built-in generator (custom structs) are left to the
+// database default; tagged ones must be handled by the Faker. Tags other than
+// the built-in ones are passed to the Faker as well.
+
+// maxBatchParams caps bind parameters per multi-row INSERT, below SQLite's historical
+// 999-parameter limit.
+const maxBatchParams = 999
+
+// SeedField describes the column a value is generated for.
+type SeedField struct {
+	Table  string
+	Column string
+	Field  reflect.StructField
+	Row    int        // 0-based index of the row being generated
+	Rand   *rand.Rand // random source of the Seed call (deterministic with WithRandSeed)
+}
+
+// Faker generates seed values. Fake returns ok=false to fall back to the
+// built-in generator for the field.
+type Faker interface {
+	Fake(field SeedField) (value interface{}, ok bool)
+}
+
+// FakerFunc adapts a function to the Faker interface.
+type FakerFunc func(field SeedField) (interface{}, bool)
+
+// Fake implements Faker.
+func (f FakerFunc) Fake(field SeedField) (interface{}, bool) {
+	return f(field)
+}
+
+// SeedOption configures Seed.
+type SeedOption func(*seedConfig)
+
+type seedConfig struct {
+	faker     Faker
+	batchSize int
+	rand      *rand.Rand
+}
+
+// WithFaker sets a Faker consulted before the built-in generators, e.g. an
+// adapter for a third-party fake data library.
+func WithFaker(f Faker) SeedOption {
+	return func(c *seedConfig) {
+		c.faker = f
+	}
+}
+
+// WithSeedBatchSize sets the maximum number of rows per INSERT statement
+// (default: as many as fit in 999 bind parameters).
+func WithSeedBatchSize(n int) SeedOption {
+	return func(c *seedConfig) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// WithRandSeed makes the generated rows reproducible.
+func WithRandSeed(seed uint64) SeedOption {
+	return func(c *seedConfig) {
+		c.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// Seed generates n rows for model (a struct or pointer to struct) and inserts
+// them into the model's table inside one transaction, using batched multi-row
+// INSERT statements. A single integer primary key is left to the database;
+// other primary keys are generated like any other field. The DB's context
+// (see WithContext) is used.
+//
+// Example:
+//
+//	err := relica.Seed(db, &User{}, 1000, relica.WithRandSeed(42))
+func Seed(db *DB, model interface{}, n int, opts ...SeedOption) error {
+	cfg := seedConfig{rand: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	typ := reflect.TypeOf(model)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return fmt.Errorf("relica: Seed requires a struct model, got %T", model)
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	table := inferTableName(model)
+	fields := seedFields(typ)
+	if len(fields) == 0 {
+		return fmt.Errorf("relica: Seed: %s has no columns to generate", typ)
+	}
+
+	gen := &seedGenerator{cfg: cfg, table: table, now: time.Now()}
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		row := make([]interface{}, len(fields))
+		for j, f := range fields {
+			v, err := gen.value(f, i)
+			if err != nil {
+				return err
+			}
+			row[j] = v
+		}
+		rows[i] = row
+	}
+
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+	batch := maxBatchParams / len(columns)
+	if batch == 0 {
+		batch = 1
+	}
+	if cfg.batchSize > 0 && cfg.batchSize < batch {
+		batch = cfg.batchSize
+	}
+
+	ctx := db.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.Transactional(ctx, func(tx *Tx) error {
+		for start := 0; start < len(rows); start += batch {
+			end := min(start+batch, len(rows))
+			q := tx.Builder().BatchInsert(table, columns)
+			for _, row := range rows[start:end] {
+				q.Values(row...)
+			}
+			if _, err := q.Execute(); err != nil {
+				return fmt.Errorf("relica: Seed %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// seedField is a struct field that Seed generates a column for.
+type seedField struct {
+	field  reflect.StructField
+	column string
+	tag    string
+}
+
+// seedFields returns the generated fields of typ in declaration order.
+func seedFields(typ reflect.Type) []seedField {
+	var skipPK string
+	if pk, err := util.FindPrimaryKeyFields(reflect.New(typ)); err == nil && pk.IsSingle() {
+		if util.IsPrimaryKeyZero(reflect.New(pk.Fields[0].Type).Elem()) {
+			skipPK = pk.Fields[0].Name
+		}
+	}
+
+	var fields []seedField
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		column := util.ColumnName(f)
+		tag := f.Tag.Get("seed")
+		if !f.IsExported() || column == "-" || tag == "-" || f.Name == skipPK {
+			continue
+		}
+		if tag == "" && !seedable(f.Type) {
+			continue
+		}
+		fields = append(fields, seedField{field: f, column: column, tag: tag})
+	}
+	return fields
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	nullStringType = reflect.TypeOf(sql.NullString{})
+	nullInt64Type  = reflect.TypeOf(sql.NullInt64{})
+	nullFloatType  = reflect.TypeOf(sql.NullFloat64{})
+	nullBoolType   = reflect.TypeOf(sql.NullBool{})
+	nullTimeType   = reflect.TypeOf(sql.NullTime{})
+)
+
+// seedable reports whether the built-in generator supports t.
+func seedable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType, nullStringType, nullInt64Type, nullFloatType, nullBoolType, nullTimeType:
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// seedGenerator produces values for one Seed call.
+type seedGenerator struct {
+	cfg   seedConfig
+	table string
+	now   time.Time
+}
+
+// value generates the value of f for row.
+func (g *seedGenerator) value(f seedField, row int) (interface{}, error) {
+	if g.cfg.faker != nil {
+		sf := SeedField{Table: g.table, Column: f.column, Field: f.field, Row: row, Rand: g.cfg.rand}
+		if v, ok := g.cfg.faker.Fake(sf); ok {
+			return v, nil
+		}
+	}
+
+	kind, arg, _ := strings.Cut(f.tag, ":")
+	switch kind {
+	case "oneof":
+		choices := strings.Split(arg, "|")
+		return convertSeedString(choices[g.cfg.rand.IntN(len(choices))], f.field.Type)
+	case "range":
+		lo, hi, err := parseSeedRange(arg)
+		if err != nil {
+			return nil, fmt.Errorf("relica: Seed: field %s: %w", f.field.Name, err)
+		}
+		return g.number(f.field.Type, lo, hi), nil
+	case "":
+		kind = seedKindForColumn(f.column)
+	default:
+		if _, ok := seedKinds[kind]; !ok {
+			return nil, fmt.Errorf("relica: Seed: field %s: unknown seed tag %q", f.field.Name, f.tag)
+		}
+	}
+	if !seedable(f.field.Type) {
+		return nil, fmt.Errorf("relica: Seed: field %s: no generator for %s (handle it in a Faker)", f.field.Name, f.field.Type)
+	}
+	return g.typed(f.field.Type, kind, row)
+}
+
+// typed generates a value of type t, using kind for strings.
+func (g *seedGenerator) typed(t reflect.Type, kind string, row int) (interface{}, error) {
+	r := g.cfg.rand
+	switch t {
+	case timeType:
+		return g.now.Add(-time.Duration(r.Int64N(int64(365 * 24 * time.Hour)))).Truncate(time.Second), nil
+	case nullTimeType:
+		v, _ := g.typed(timeType, kind, row)
+		return sql.NullTime{Time: v.(time.Time), Valid: true}, nil
+	case nullStringType:
+		return sql.NullString{String: g.text(kind, row), Valid: true}, nil
+	case nullInt64Type:
+		return sql.NullInt64{Int64: g.number(reflect.TypeOf(int64(0)), 0, 1000).(int64), Valid: true}, nil
+	case nullFloatType:
+		return sql.NullFloat64{Float64: g.number(reflect.TypeOf(float64(0)), 0, 1000).(float64), Valid: true}, nil
+	case nullBoolType:
+		return sql.NullBool{Bool: r.IntN(2) == 1, Valid: true}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		v, err := g.typed(t.Elem(), kind, row)
+		if err != nil {
+			return nil, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(reflect.ValueOf(v).Convert(t.Elem()))
+		return p.Interface(), nil
+	case reflect.String:
+		return reflect.ValueOf(g.text(kind, row)).Convert(t).Interface(), nil
+	case reflect.Bool:
+		return reflect.ValueOf(r.IntN(2) == 1).Convert(t).Interface(), nil
+	case reflect.Slice:
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(r.UintN(256))
+		}
+		return b, nil
+	}
+
+	if lo, hi, ok := seedNumberRange(kind); ok {
+		return g.number(t, lo, hi), nil
+	}
+	return g.number(t, 0, 1000), nil
+}
+
+// number generates a number of type t in [lo, hi]; floats get two decimals.
+func (g *seedGenerator) number(t reflect.Type, lo, hi float64) interface{} {
+	r := g.cfg.rand
+	base := t
+	if base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	var v reflect.Value
+	switch base.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := lo + r.Float64()*(hi-lo)
+		v = reflect.ValueOf(float64(int64(f*100)) / 100)
+	default:
+		if max := maxSeedInt(base); hi > max {
+			hi = max
+		}
+		v = reflect.ValueOf(int64(lo) + r.Int64N(int64(hi)-int64(lo)+1))
+	}
+	v = v.Convert(base)
+	if t.Kind() == reflect.Pointer {
+		p := reflect.New(base)
+		p.Elem().Set(v)
+		return p.Interface()
+	}
+	return v.Interface()
+}
+
+// maxSeedInt returns the largest value generated for small integer types.
+func maxSeedInt(t reflect.Type) float64 {
+	switch t.Kind() {
+	case reflect.Int8:
+		return 127
+	case reflect.Uint8:
+		return 255
+	}
+	return 1 << 30
+}
+
+// text generates a string of the given kind; row makes unique kinds unique.
+func (g *seedGenerator) text(kind string, row int) string {
+	r := g.cfg.rand
+	pick := func(list []string) string { return list[r.IntN(len(list))] }
+	first, last := pick(seedFirstNames), pick(seedLastNames)
+
+	switch kind {
+	case "email":
+		return strings.ToLower(first+"."+last) + strconv.Itoa(row+1) + "@example.com"
+	case "username":
+		return strings.ToLower(first) + strconv.Itoa(row+1)
+	case "uuid":
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(r.UintN(256))
+		}
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		h := hex.EncodeToString(b)
+		return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+	case "name":
+		return first + " " + last
+	case "first_name":
+		return first
+	case "last_name":
+		return last
+	case "phone":
+		return fmt.Sprintf("+1-555-%03d-%04d", r.IntN(1000), r.IntN(10000))
+	case "city":
+		return pick(seedCities)
+	case "country":
+		return pick(seedCountries)
+	case "company":
+		return last + " " + pick(seedCompanySuffixes)
+	case "url":
+		return "https://" + strings.ToLower(last) + ".example.com/" + pick(seedWords)
+	case "sentence":
+		return g.sentence(4 + r.IntN(5))
+	case "paragraph":
+		parts := make([]string, 3+r.IntN(3))
+		for i := range parts {
+			parts[i] = g.sentence(6 + r.IntN(8))
+		}
+		return strings.Join(parts, " ")
+	}
+	return pick(seedWords)
+}
+
+// sentence returns n random words, capitalized and ending with a period.
+func (g *seedGenerator) sentence(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = seedWords[g.cfg.rand.IntN(len(seedWords))]
+	}
+	s := strings.Join(words, " ")
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+// seedKinds lists the named generators usable in seed tags.
+var seedKinds = map[string]bool{
+	"email": true, "username": true, "uuid": true, "name": true, "first_name": true,
+	"last_name": true, "phone": true, "city": true, "country": true, "company": true,
+	"url": true, "word": true, "sentence": true, "paragraph": true,
+	"age": true, "price": true, "quantity": true, "year": true,
+}
+
+// seedKindForColumn infers a generator from a column name.
+func seedKindForColumn(column string) string {
+	c := strings.ToLower(column)
+	switch {
+	case strings.Contains(c, "email"):
+		return "email"
+	case c == "username" || c == "login" || c == "handle":
+		return "username"
+	case c == "uuid" || c == "guid" || strings.HasSuffix(c, "_uuid"):
+		return "uuid"
+	case strings.Contains(c, "first") && strings.Contains(c, "name"):
+		return "first_name"
+	case strings.Contains(c, "last") && strings.Contains(c, "name"), c == "surname":
+		return "last_name"
+	case strings.Contains(c, "company") || strings.Contains(c, "organization"):
+		return "company"
+	case strings.Contains(c, "name"):
+		return "name"
+	case strings.Contains(c, "phone") || strings.Contains(c, "mobile"):
+		return "phone"
+	case strings.Contains(c, "city"):
+		return "city"
+	case strings.Contains(c, "country"):
+		return "country"
+	case strings.Contains(c, "url") || strings.Contains(c, "website"):
+		return "url"
+	case c == "title" || c == "subject" || c == "summary":
+		return "sentence"
+	case c == "description" || c == "bio" || c == "body" || c == "content" || c == "text":
+		return "paragraph"
+	case c == "age":
+		return "age"
+	case strings.Contains(c, "price") || strings.Contains(c, "amount") || strings.Contains(c, "total"):
+		return "price"
+	case strings.Contains(c, "quantity") || c == "qty" || strings.HasSuffix(c, "count"):
+		return "quantity"
+	case strings.HasSuffix(c, "year"):
+		return "year"
+	}
+	return "word"
+}
+
+// seedNumberRange returns the range of numeric generator kinds.
+func seedNumberRange(kind string) (lo, hi float64, ok bool) {
+	switch kind {
+	case "age":
+		return 18, 90, true
+	case "price":
+		return 1, 500, true
+	case "quantity":
+		return 1, 100, true
+	case "year":
+		return 1970, float64(time.Now().Year()), true
+	}
+	return 0, 0, false
+}
+
+// parseSeedRange parses "lo,hi".
+func parseSeedRange(arg string) (lo, hi float64, err error) {
+	a, b, ok := strings.Cut(arg, ",")
+	if ok {
+		lo, err = strconv.ParseFloat(strings.TrimSpace(a), 64)
+		if err == nil {
+			hi, err = strconv.ParseFloat(strings.TrimSpace(b), 64)
+		}
+	}
+	if !ok || err != nil || hi < lo {
+		return 0, 0, fmt.Errorf("invalid seed range %q (want range:lo,hi)", arg)
+	}
+	return lo, hi, nil
+}
+
+// convertSeedString converts a oneof choice to t.
+func convertSeedString(s string, t reflect.Type) (interface{}, error) {
+	base := t
+	if base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+
+	var v reflect.Value
+	switch base.Kind() {
+	case reflect.String:
+		v = reflect.ValueOf(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("relica: Seed: oneof value %q is not an integer", s)
+		}
+		v = reflect.ValueOf(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("relica: Seed: oneof value %q is not a number", s)
+		}
+		v = reflect.ValueOf(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("relica: Seed: oneof value %q is not a bool", s)
+		}
+		v = reflect.ValueOf(b)
+	default:
+		return nil, fmt.Errorf("relica: Seed: oneof is not supported for %s", t)
+	}
+
+	v = v.Convert(base)
+	if t.Kind() == reflect.Pointer {
+		p := reflect.New(base)
+		p.Elem().Set(v)
+		return p.Interface(), nil
+	}
+	return v.Interface(), nil
+}
+
+var (
+	seedFirstNames = []string{
+		"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Isla", "Jack",
+		"Karen", "Liam", "Mia", "Noah", "Olivia", "Paul", "Quinn", "Ruby", "Sam", "Tara",
+	}
+	seedLastNames = []string{
+		"Anderson", "Brown", "Clark", "Davis", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Johnson",
+		"Kim", "Lopez", "Miller", "Nguyen", "Owens", "Patel", "Rossi", "Smith", "Taylor", "Weber",
+	}
+	seedCities = []string{
+		"Amsterdam", "Berlin", "Boston", "Chicago", "Dublin", "Lisbon", "London", "Madrid",
+		"Melbourne", "Osaka", "Paris", "Prague", "Seattle", "Toronto", "Vienna", "Warsaw",
+	}
+	seedCountries = []string{
+		"Australia", "Austria", "Brazil", "Canada", "France", "Germany", "India", "Ireland",
+		"Italy", "Japan", "Netherlands", "Poland", "Portugal", "Spain", "United Kingdom", "United States",
+	}
+	seedCompanySuffixes = []string{"Inc", "LLC", "Group", "Labs", "Systems", "Partners"}
+	seedWords           = []string{
+		"alpha", "amber", "anchor", "bridge", "cloud", "copper", "delta", "ember", "falcon", "garden",
+		"harbor", "island", "jade", "kernel", "lantern", "meadow", "nebula", "orbit", "pixel", "quartz",
+		"river", "signal", "timber", "unity", "vector", "willow", "yonder", "zephyr",
+	}
+)
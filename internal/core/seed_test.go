@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type seedUser struct {
+	ID        int64          `db:"id"`
+	Email     string         `db:"email"`
+	FirstName string         `db:"first_name"`
+	Age       int8           `db:"age"`
+	Role      string         `db:"role" seed:"oneof:admin|member"`
+	Score     *float64       `db:"score" seed:"range:1,5"`
+	Bio       sql.NullString `db:"bio"`
+	Active    bool           `db:"active"`
+	CreatedAt time.Time      `db:"created_at"`
+	Notes     string         `db:"notes" seed:"-"`
+	Internal  string         `db:"-"`
+}
+
+func (seedUser) TableName() string { return "seed_users" }
+
+func setupSeedDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE seed_users (
+		id INTEGER PRIMARY KEY, email TEXT UNIQUE NOT NULL, first_name TEXT, age INTEGER, role TEXT,
+		score REAL, bio TEXT, active BOOLEAN, created_at DATETIME, notes TEXT DEFAULT 'none')`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestSeed(t *testing.T) {
+	db := setupSeedDB(t)
+
+	require.NoError(t, Seed(db, &seedUser{}, 250, WithRandSeed(7), WithSeedBatchSize(40)))
+
+	var users []seedUser
+	require.NoError(t, db.Builder().Select("id", "email", "first_name", "age", "role", "score", "bio", "active", "created_at").
+		From("seed_users").OrderBy("id").All(&users))
+	require.Len(t, users, 250)
+
+	emails := make(map[string]bool)
+	for i, u := range users {
+		assert.Equal(t, int64(i+1), u.ID, "primary key is assigned by the database")
+		assert.Contains(t, u.Email, "@example.com")
+		emails[u.Email] = true
+		assert.Contains(t, seedFirstNames, u.FirstName)
+		assert.GreaterOrEqual(t, u.Age, int8(18))
+		assert.LessOrEqual(t, u.Age, int8(90))
+		assert.Contains(t, []string{"admin", "member"}, u.Role)
+		require.NotNil(t, u.Score)
+		assert.GreaterOrEqual(t, *u.Score, 1.0)
+		assert.LessOrEqual(t, *u.Score, 5.0)
+		assert.True(t, u.Bio.Valid)
+		assert.True(t, strings.HasSuffix(u.Bio.String, "."))
+		assert.WithinDuration(t, time.Now(), u.CreatedAt, 366*24*time.Hour)
+	}
+	assert.Len(t, emails, 250, "emails are unique")
+
+	var notes []string
+	require.NoError(t, db.NewQuery("SELECT DISTINCT notes FROM seed_users").Column(&notes))
+	assert.Equal(t, []string{"none"}, notes, `seed:"-" keeps the database default`)
+}
+
+func TestSeed_Deterministic(t *testing.T) {
+	gen := func() []interface{} {
+		g := &seedGenerator{cfg: seedConfig{}, now: time.Unix(0, 0)}
+		WithRandSeed(42)(&g.cfg)
+		var out []interface{}
+		for i, f := range seedFields(reflect.TypeOf(seedUser{})) {
+			v, err := g.value(f, i)
+			require.NoError(t, err)
+			out = append(out, v)
+		}
+		return out
+	}
+	assert.Equal(t, gen(), gen())
+}
+
+func TestSeed_Faker(t *testing.T) {
+	db := setupSeedDB(t)
+
+	faker := FakerFunc(func(f SeedField) (interface{}, bool) {
+		if f.Column == "first_name" {
+			return "Zed", true
+		}
+		if f.Column == "email" {
+			return "user" + string(rune('a'+f.Row)) + "@test.dev", true
+		}
+		return nil, false
+	})
+	require.NoError(t, Seed(db.WithContext(context.Background()), seedUser{}, 3, WithFaker(faker)))
+
+	var names []string
+	require.NoError(t, db.Builder().Select("first_name").From("seed_users").Column(&names))
+	assert.Equal(t, []string{"Zed", "Zed", "Zed"}, names)
+}
+
+func TestSeed_Errors(t *testing.T) {
+	db := setupSeedDB(t)
+
+	assert.ErrorContains(t, Seed(db, 42, 1), "requires a struct model")
+	assert.NoError(t, Seed(db, &seedUser{}, 0))
+
+	type badRange struct {
+		Age int `db:"age" seed:"range:9,1"`
+	}
+	assert.ErrorContains(t, Seed(db, badRange{}, 1), "invalid seed range")
+
+	type unknownTag struct {
+		Age int `db:"age" seed:"lottery"`
+	}
+	assert.ErrorContains(t, Seed(db, unknownTag{}, 1), `unknown seed tag "lottery"`)
+
+	type custom struct {
+		Point Point `db:"point" seed:"location"`
+	}
+	assert.ErrorContains(t, Seed(db, custom{}, 1), `unknown seed tag "location"`)
+
+	type missingTable struct {
+		Name string `db:"name"`
+	}
+	assert.ErrorContains(t, Seed(db, missingTable{}, 1), "relica: Seed missingtables")
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// sequenceTable holds the counters of emulated sequences (MySQL, SQLite).
+const sequenceTable = "relica_sequences"
+
+// CreateSequence creates the named sequence if it does not exist. On
+// PostgreSQL this is CREATE SEQUENCE; MySQL and SQLite have no sequences and
+// keep counters in the relica_sequences table, which CreateSequence creates.
+// Call it once, e.g. from migrations, before NextSequence.
+func (db *DB) CreateSequence(ctx context.Context, name string) error {
+	d := db.dialect
+	var ddl string
+	if isPostgres(d) {
+		ddl = "CREATE SEQUENCE IF NOT EXISTS " + d.QuoteIdentifier(name)
+	} else {
+		ddl = "CREATE TABLE IF NOT EXISTS " + d.QuoteIdentifier(sequenceTable) + " (" +
+			d.QuoteIdentifier("name") + " VARCHAR(255) PRIMARY KEY, " +
+			d.QuoteIdentifier("value") + " BIGINT NOT NULL)"
+	}
+	if _, err := db.ExecContext(ctx, ddl); err != nil {
+		return fmt.Errorf("relica: create sequence %s: %w", name, err)
+	}
+	return nil
+}
+
+// NextSequence returns the next value of the named sequence, starting at 1.
+//
+// PostgreSQL uses nextval, whose values are never handed out twice, even if
+// the transaction that took one rolls back. The MySQL and SQLite emulation
+// increments a row of relica_sequences atomically; inside a transaction the
+// row stays locked until commit, and a rollback returns the value.
+//
+// Example:
+//
+//	n, err := db.NextSequence(ctx, "order_seq")
+//	order.Number = fmt.Sprintf("ORD-%06d", n)
+func (db *DB) NextSequence(ctx context.Context, name string) (int64, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	d := db.dialect
+	table, nameCol, valueCol := d.QuoteIdentifier(sequenceTable), d.QuoteIdentifier("name"), d.QuoteIdentifier("value")
+
+	var n int64
+	var err error
+	switch d.(type) {
+	case *dialects.PostgresDialect:
+		err = db.NewQuery("SELECT nextval($1)", d.QuoteIdentifier(name)).WithContext(ctx).Row(&n)
+	case *dialects.MySQLDialect:
+		// LAST_INSERT_ID(expr) reports the new value through the OK packet.
+		res, execErr := db.ExecContext(ctx, "INSERT INTO "+table+" ("+nameCol+", "+valueCol+") VALUES (?, LAST_INSERT_ID(1))"+
+			" ON DUPLICATE KEY UPDATE "+valueCol+" = LAST_INSERT_ID("+valueCol+" + 1)", name)
+		if err = execErr; err == nil {
+			n, err = res.LastInsertId()
+		}
+	default:
+		err = db.NewQuery("INSERT INTO "+table+" ("+nameCol+", "+valueCol+") VALUES (?, 1)"+
+			" ON CONFLICT ("+nameCol+") DO UPDATE SET "+valueCol+" = "+valueCol+" + 1 RETURNING "+valueCol, name).
+			WithContext(ctx).Row(&n)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("relica: next value of sequence %s: %w", name, err)
+	}
+	return n, nil
+}
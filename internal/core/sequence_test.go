@@ -0,0 +1,32 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextSequence(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	_, err = db.NextSequence(ctx, "order_seq")
+	assert.ErrorContains(t, err, "relica: next value of sequence order_seq")
+
+	require.NoError(t, db.CreateSequence(ctx, "order_seq"))
+	require.NoError(t, db.CreateSequence(ctx, "invoice_seq"))
+
+	for want := int64(1); want <= 3; want++ {
+		n, err := db.NextSequence(ctx, "order_seq")
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	n, err := db.NextSequence(ctx, "invoice_seq")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "sequences are independent")
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Session settings
+// ============================================================================
+//
+// Pools opened by Open and DB.Pool connect through a sessionConnector, which
+// applies the session settings of WithSessionSettings to every new
+// connection before database/sql hands it out:
+//
+//	PostgreSQL: SELECT set_config($1, $2, false)
+//	MySQL:      SET SESSION name = ?
+//	SQLite:     PRAGMA name = 'value'
+
+// sessionSettingNameRegex matches valid setting names, including PostgreSQL
+// custom settings such as "app.tenant_id".
+var sessionSettingNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// sessionSettings holds the settings applied to new connections. A named
+// pool inherits its parent's settings until it sets its own.
+type sessionSettings struct {
+	values atomic.Pointer[map[string]string]
+	parent *sessionSettings
+}
+
+// current returns the settings in effect, or nil.
+func (s *sessionSettings) current() map[string]string {
+	if s == nil {
+		return nil
+	}
+	if v := s.values.Load(); v != nil {
+		return *v
+	}
+	return s.parent.current()
+}
+
+// set replaces the settings with a copy of values.
+func (s *sessionSettings) set(values map[string]string) {
+	cp := make(map[string]string, len(values))
+	for k, v := range values {
+		cp[k] = v
+	}
+	s.values.Store(&cp)
+}
+
+// WithSessionSettings applies session settings to every new connection of
+// the DB and its named pools, such as search_path, time_zone or
+// statement_timeout on PostgreSQL, sql_mode or time_zone on MySQL, and
+// pragmas like foreign_keys or busy_timeout on SQLite. Settings are applied
+// when a connection is opened, the same way with every driver, instead of
+// through driver-specific DSN parameters. A connection whose settings fail
+// is closed and the error is returned by the statement that needed it.
+//
+// Connections opened before the option is applied keep their settings.
+// DBs created with WrapDB use their caller's pool and ignore the option.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithSessionSettings(map[string]string{
+//	    "search_path":       "app, public",
+//	    "statement_timeout": "5s",
+//	    "TimeZone":          "UTC",
+//	}))
+func WithSessionSettings(settings map[string]string) Option {
+	return optionFunc(func(db *DB) {
+		if db.session == nil {
+			db.session = &sessionSettings{}
+		}
+		db.session.set(settings)
+	})
+}
+
+// SetSessionSettings replaces the session settings applied to new
+// connections of this pool (see WithSessionSettings). By default a pool
+// uses the settings of its DB.
+func (p *Pool) SetSessionSettings(settings map[string]string) {
+	if p.session == nil {
+		return // pool not opened through a sessionConnector
+	}
+	p.session.set(settings)
+}
+
+// openSessionPool opens a pool for driverName and dsn whose new connections
+// get the settings of session.
+func openSessionPool(driverName, dsn string, dialect dialects.Dialect, session *sessionSettings) (*sql.DB, error) {
+	base, err := sessionBaseConnector(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(&sessionConnector{base: base, dialect: dialect, session: session}), nil
+}
+
+// sessionBaseConnector returns the connector sql.Open would use for
+// driverName and dsn.
+func sessionBaseConnector(driverName, dsn string) (driver.Connector, error) {
+	// sql.Open resolves the registered driver without connecting.
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	if dc, ok := drv.(driver.DriverContext); ok {
+		return dc.OpenConnector(dsn)
+	}
+	return dsnConnector{driver: drv, dsn: dsn}, nil
+}
+
+// dsnConnector connects with a driver that has no connector of its own.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+
+func (c dsnConnector) Driver() driver.Driver { return c.driver }
+
+// sessionConnector applies session settings to the connections of base.
+type sessionConnector struct {
+	base    driver.Connector
+	dialect dialects.Dialect
+	session *sessionSettings
+}
+
+// Connect opens a connection and applies the current session settings.
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings := c.session.current()
+	if len(settings) == 0 {
+		return conn, nil
+	}
+	if err := applySessionSettings(ctx, conn, c.dialect, settings); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Driver returns the underlying driver.
+func (c *sessionConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// applySessionSettings runs the statements setting settings on conn, in name order.
+func applySessionSettings(ctx context.Context, conn driver.Conn, dialect dialects.Dialect, settings map[string]string) error {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if !sessionSettingNameRegex.MatchString(name) {
+			return fmt.Errorf("relica: invalid session setting name %q", name)
+		}
+		value := settings[name]
+
+		var query string
+		var args []interface{}
+		switch dialect.(type) {
+		case *dialects.PostgresDialect:
+			query, args = "SELECT set_config($1, $2, false)", []interface{}{name, value}
+		case *dialects.MySQLDialect:
+			query, args = "SET SESSION "+name+" = ?", []interface{}{value}
+		default:
+			query = "PRAGMA " + name + " = '" + strings.ReplaceAll(value, "'", "''") + "'"
+		}
+		if err := execOnConn(ctx, conn, query, args); err != nil {
+			return fmt.Errorf("relica: session setting %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// execOnConn executes query on a driver connection.
+func execOnConn(ctx context.Context, conn driver.Conn, query string, args []interface{}) error {
+	named := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, named)
+		if err != driver.ErrSkip { //nolint:errorlint // drivers return ErrSkip unwrapped
+			return err
+		}
+	}
+
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	if sc, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = sc.ExecContext(ctx, named)
+		return err
+	}
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a
+	}
+	_, err = stmt.Exec(values) //nolint:staticcheck // fallback for drivers without StmtExecContext
+	return err
+}
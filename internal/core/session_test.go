@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingConn is a driver connection that records executed statements.
+type recordingConn struct {
+	execs []string
+	args  [][]driver.NamedValue
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *recordingConn) Close() error                        { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.execs = append(c.execs, query)
+	c.args = append(c.args, args)
+	return driver.RowsAffected(0), nil
+}
+
+func TestApplySessionSettings_Dialects(t *testing.T) {
+	settings := map[string]string{"search_path": "app, public", "TimeZone": "UTC"}
+
+	conn := &recordingConn{}
+	require.NoError(t, applySessionSettings(context.Background(), conn, dialects.GetDialect("postgres"), settings))
+	assert.Equal(t, []string{"SELECT set_config($1, $2, false)", "SELECT set_config($1, $2, false)"}, conn.execs)
+	assert.Equal(t, "TimeZone", conn.args[0][0].Value, "settings are applied in name order")
+	assert.Equal(t, "app, public", conn.args[1][1].Value)
+
+	conn = &recordingConn{}
+	require.NoError(t, applySessionSettings(context.Background(), conn, dialects.GetDialect("mysql"),
+		map[string]string{"sql_mode": "STRICT_ALL_TABLES"}))
+	assert.Equal(t, []string{"SET SESSION sql_mode = ?"}, conn.execs)
+
+	conn = &recordingConn{}
+	require.NoError(t, applySessionSettings(context.Background(), conn, dialects.GetDialect("sqlite"),
+		map[string]string{"journal_mode": "it's"}))
+	assert.Equal(t, []string{"PRAGMA journal_mode = 'it''s'"}, conn.execs)
+
+	err := applySessionSettings(context.Background(), &recordingConn{}, dialects.GetDialect("mysql"),
+		map[string]string{"sql_mode = ''; DROP TABLE users; --": "x"})
+	assert.ErrorContains(t, err, "invalid session setting name")
+}
+
+func TestWithSessionSettings_AppliedToNewConnections(t *testing.T) {
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "session.db"),
+		WithSessionSettings(map[string]string{"busy_timeout": "1234", "foreign_keys": "1"}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Hold several connections so each one is checked.
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		conn, err := db.sqlDB.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var timeout, fk int
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&timeout))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&fk))
+		assert.Equal(t, 1234, timeout)
+		assert.Equal(t, 1, fk)
+	}
+
+	p, err := db.Pool("reports")
+	require.NoError(t, err)
+	var timeout int
+	require.NoError(t, db.NewQuery("PRAGMA busy_timeout").OnPool("reports").Row(&timeout))
+	assert.Equal(t, 1234, timeout, "named pools inherit the DB's settings")
+
+	p.SetSessionSettings(map[string]string{"busy_timeout": "99"})
+	p.sqlDB.SetMaxIdleConns(0) // next query opens a new connection
+	require.NoError(t, db.NewQuery("PRAGMA busy_timeout").OnPool("reports").Row(&timeout))
+	assert.Equal(t, 99, timeout)
+}
+
+func TestWithSessionSettings_FailedSettingClosesConnection(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithSessionSettings(map[string]string{"bad name": "1"}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.sqlDB.PingContext(context.Background())
+	assert.ErrorContains(t, err, `invalid session setting name "bad name"`)
+	assert.Zero(t, db.sqlDB.Stats().OpenConnections)
+}
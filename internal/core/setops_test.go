@@ -409,3 +409,73 @@ func TestSelectQuery_Union_Empty_Result(t *testing.T) {
 	assert.Contains(t, query.sql, "UNION")
 	assert.Len(t, query.params, 2)
 }
+
+// TestSelectQuery_UnionOrderBy_PostgreSQL tests ORDER BY/LIMIT applied to the combined result
+func TestSelectQuery_UnionOrderBy_PostgreSQL(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q1 := qb.Select("name").From("users").Where("status = ?", 1)
+	q2 := qb.Select("name").From("archived_users").Where("status = ?", 2)
+
+	query := q1.UnionAll(q2).UnionOrderBy("name DESC").UnionLimit(10).UnionOffset(20).Build()
+	require.NoError(t, query.prepErr)
+
+	assert.Equal(t,
+		`(SELECT "name" FROM "users" WHERE status = $1) UNION ALL (SELECT "name" FROM "archived_users" WHERE status = $2) `+
+			`ORDER BY "name" DESC LIMIT 10 OFFSET 20`,
+		query.sql)
+	assert.Equal(t, []interface{}{1, 2}, query.params)
+}
+
+// TestSelectQuery_UnionOrderBy_MemberOrderKept tests that a member's own ORDER BY/LIMIT stays inside its parentheses
+func TestSelectQuery_UnionOrderBy_MemberOrderKept(t *testing.T) {
+	db := mockDB("mysql")
+	qb := &QueryBuilder{db: db}
+
+	q1 := qb.Select("id").From("recent_orders").OrderBy("id DESC").Limit(5)
+	q2 := qb.Select("id").From("pending_orders")
+
+	query := q1.Union(q2).UnionOrderBy("id").Build()
+	require.NoError(t, query.prepErr)
+
+	assert.Equal(t,
+		"(SELECT `id` FROM `recent_orders` ORDER BY `id` DESC LIMIT 5) UNION (SELECT `id` FROM `pending_orders`) ORDER BY `id`",
+		query.sql)
+}
+
+// TestSelectQuery_Union_SQLite_Parenthesization tests SQLite members are not parenthesized
+func TestSelectQuery_Union_SQLite_Parenthesization(t *testing.T) {
+	db := mockDB("sqlite")
+	qb := &QueryBuilder{db: db}
+
+	t.Run("bare members", func(t *testing.T) {
+		q1 := qb.Select("name").From("users")
+		q2 := qb.Select("name").From("archived_users")
+
+		query := q1.Union(q2).UnionOrderBy("name").UnionLimit(10).Build()
+		require.NoError(t, query.prepErr)
+		assert.Equal(t, `SELECT "name" FROM "users" UNION SELECT "name" FROM "archived_users" ORDER BY "name" LIMIT 10`, query.sql)
+	})
+
+	t.Run("member with own LIMIT", func(t *testing.T) {
+		q1 := qb.Select("id").From("recent_orders").OrderBy("id DESC").Limit(5)
+		q2 := qb.Select("id").From("pending_orders")
+
+		query := q1.UnionAll(q2).Build()
+		require.NoError(t, query.prepErr)
+		assert.Equal(t,
+			`SELECT * FROM (SELECT "id" FROM "recent_orders" ORDER BY "id" DESC LIMIT 5) UNION ALL SELECT "id" FROM "pending_orders"`,
+			query.sql)
+	})
+}
+
+// TestSelectQuery_UnionOrderBy_NoSetOperation tests that union-level ordering is ignored without set operations
+func TestSelectQuery_UnionOrderBy_NoSetOperation(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	query := qb.Select("id").From("users").UnionOrderBy("id").UnionLimit(5).Build()
+	require.NoError(t, query.prepErr)
+	assert.Equal(t, `SELECT "id" FROM "users"`, query.sql)
+}
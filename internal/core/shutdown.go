@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ============================================================================
+// Graceful shutdown
+// ============================================================================
+//
+// Close closes the connection pool at once, failing queries and transactions
+// that are still running. Shutdown first stops accepting new work, waits for
+// the work in flight to finish, and then closes, so that a deploy does not
+// abort the requests it is draining.
+//
+// In-flight work is a Query being executed, a raw DB.ExecContext or
+// DB.QueryContext call, and a transaction from Begin until Commit or
+// Rollback. Rows returned by DB.QueryContext are not tracked once returned.
+
+// drainGroup tracks the in-flight work of a DB for Shutdown. It is shared by
+// DB copies (WithContext, pool views), like the pool registry.
+type drainGroup struct {
+	mu         sync.Mutex
+	closing    bool
+	active     int
+	idle       chan struct{}  // closed when active reaches zero after closing
+	background sync.WaitGroup // async work started by queries (optimizer analysis)
+}
+
+// newDrainGroup creates an empty drain group.
+func newDrainGroup() *drainGroup {
+	return &drainGroup{idle: make(chan struct{})}
+}
+
+// enter registers new work, or returns ErrShuttingDown once Shutdown started.
+func (g *drainGroup) enter() error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closing {
+		return ErrShuttingDown
+	}
+	g.active++
+	return nil
+}
+
+// leave marks work registered with enter as finished.
+func (g *drainGroup) leave() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active--
+	if g.closing && g.active == 0 {
+		close(g.idle)
+	}
+}
+
+// close stops accepting work and returns a channel closed once the work in
+// flight has finished. Calling it again returns the same channel.
+func (g *drainGroup) close() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.closing {
+		g.closing = true
+		if g.active == 0 {
+			close(g.idle)
+		}
+	}
+	return g.idle
+}
+
+// goBackground runs fn in a goroutine that Shutdown waits for.
+func (g *drainGroup) goBackground(fn func()) {
+	if g == nil {
+		go fn()
+		return
+	}
+	g.background.Add(1)
+	go func() {
+		defer g.background.Done()
+		fn()
+	}()
+}
+
+// Shutdown gracefully closes the database. It rejects new queries and
+// transactions with ErrShuttingDown, waits for the in-flight ones to finish,
+// waits for pending optimizer analyses, and then closes like Close: it stops
+// the health checker, flushes the optimizer store and closes all pools.
+//
+// If ctx is done before the work in flight has finished, Shutdown closes the
+// database anyway and returns the context error along with any close error.
+// Shutting down a DB bound to a transaction (see Tx.DB) is a no-op; shutting
+// down a DB bound to a connection (see Conn) returns the connection.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := db.Shutdown(ctx); err != nil {
+//	    log.Printf("database shutdown: %v", err)
+//	}
+func (db *DB) Shutdown(ctx context.Context) error {
+	if db.bound != nil {
+		return nil
+	}
+	if db.pinned != nil {
+		return db.pinned.Close()
+	}
+	if db.drain == nil {
+		return db.Close()
+	}
+
+	// Background work is only started by in-flight work, so it is waited for
+	// once no work is left in flight.
+	idle := db.drain.close()
+	drained := make(chan struct{})
+	go func() {
+		<-idle
+		db.drain.background.Wait()
+		close(drained)
+	}()
+
+	var drainErr error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		drainErr = fmt.Errorf("relica: shutdown: in-flight work did not finish: %w", ctx.Err())
+	}
+
+	return errors.Join(drainErr, db.Close())
+}
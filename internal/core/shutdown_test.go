@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown_WaitsForTransaction(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.sqlDB.SetMaxOpenConns(2)
+	ctx := context.Background()
+
+	tx, err := db.Begin(ctx)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- db.Shutdown(ctx) }()
+
+	// New work is rejected while the transaction drains
+	require.Eventually(t, func() bool {
+		_, err := db.NewQuery("SELECT 1").Execute()
+		return errors.Is(err, ErrShuttingDown)
+	}, time.Second, time.Millisecond)
+	_, err = db.Begin(ctx)
+	assert.ErrorIs(t, err, ErrShuttingDown)
+	_, err = db.ExecContext(ctx, "SELECT 1")
+	assert.ErrorIs(t, err, ErrShuttingDown)
+
+	// The open transaction keeps working until it ends
+	_, err = tx.NewQuery("SELECT 1").Execute()
+	require.NoError(t, err)
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before the transaction ended")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	require.NoError(t, tx.Commit())
+	require.NoError(t, <-done)
+	assert.Error(t, db.sqlDB.Ping(), "pool is closed")
+}
+
+func TestShutdown_WaitsForQuery(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	db, err := Open("sqlite", ":memory:", WithQueryHook(func(_ context.Context, _ QueryEvent) {
+		close(started)
+		<-unblock
+	}))
+	require.NoError(t, err)
+
+	queryErr := make(chan error, 1)
+	go func() {
+		var n int
+		queryErr <- db.NewQuery("SELECT 1").Row(&n)
+	}()
+	<-started
+
+	done := make(chan error, 1)
+	go func() { done <- db.Shutdown(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before the query finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(unblock)
+	require.NoError(t, <-queryErr)
+	require.NoError(t, <-done)
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = db.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Error(t, db.sqlDB.Ping(), "pool is closed despite the open transaction")
+}
+
+func TestShutdown_Idle(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+
+	tx, err := db.Begin(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	require.Error(t, tx.Rollback(), "ending a transaction twice is tracked once")
+
+	require.NoError(t, db.Shutdown(context.Background()))
+	var n int
+	assert.ErrorIs(t, db.NewQuery("SELECT 1").Row(&n), ErrShuttingDown)
+}
+
+func TestDrainGroup_Nil(t *testing.T) {
+	var g *drainGroup
+	require.NoError(t, g.enter())
+	g.leave()
+
+	ran := make(chan struct{})
+	g.goBackground(func() { close(ran) })
+	<-ran
+}
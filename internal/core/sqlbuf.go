@@ -0,0 +1,152 @@
+package core
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Pooled SQL rendering buffers
+// ============================================================================
+//
+// Statements are rendered in a single pass into a sqlBuffer taken from a
+// sync.Pool, instead of concatenating a string per clause and joining them.
+// Identifiers and placeholders of the built-in dialects are written straight
+// into the buffer, so building a statement allocates little more than the
+// final SQL string and its parameter slice.
+
+// maxPooledSQLBuffer is the capacity above which a buffer is not returned to
+// the pool, so one huge statement (a bulk INSERT) does not pin its memory.
+const maxPooledSQLBuffer = 64 << 10
+
+var sqlBufferPool = sync.Pool{
+	New: func() interface{} { return new(sqlBuffer) },
+}
+
+// sqlBuffer accumulates the SQL text of a statement.
+type sqlBuffer struct {
+	bytes.Buffer
+	err error // first error met while writing the statement (see fail)
+}
+
+// getSQLBuffer returns an empty buffer from the pool. Release it with release
+// once its String has been taken.
+func getSQLBuffer() *sqlBuffer {
+	return sqlBufferPool.Get().(*sqlBuffer)
+}
+
+// release returns b to the pool.
+func (b *sqlBuffer) release() {
+	if b.Cap() > maxPooledSQLBuffer {
+		return
+	}
+	b.Reset()
+	b.err = nil
+	sqlBufferPool.Put(b)
+}
+
+// fail records err as the error of the statement being written, unless an
+// earlier error was recorded. Writers report errors here rather than storing
+// them on the query, which Build must not modify.
+func (b *sqlBuffer) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// writeIdentifier writes s quoted as an identifier, like
+// dialect.QuoteIdentifier(s) but without allocating for built-in dialects.
+func (b *sqlBuffer) writeIdentifier(s string, dialect dialects.Dialect) {
+	var q byte
+	switch dialect.(type) {
+	case *dialects.PostgresDialect, *dialects.SQLiteDialect:
+		q = '"'
+	case *dialects.MySQLDialect:
+		q = '`'
+	default:
+		b.WriteString(dialect.QuoteIdentifier(s))
+		return
+	}
+	b.WriteByte(q)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			// NUL bytes are dropped, as by QuoteIdentifier.
+		case q:
+			b.WriteByte(q)
+			b.WriteByte(q)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(q)
+}
+
+// writeColumn writes col quoted like quoteColumn: each part of a qualified
+// name is quoted, and function calls are written as-is.
+func (b *sqlBuffer) writeColumn(col string, dialect dialects.Dialect) {
+	if strings.Contains(col, "(") {
+		b.WriteString(col)
+		return
+	}
+	for {
+		part, rest, found := strings.Cut(col, ".")
+		b.writeIdentifier(part, dialect)
+		if !found {
+			return
+		}
+		b.WriteByte('.')
+		col = rest
+	}
+}
+
+// writeTable writes "table" or "table alias" like tableWithAlias.
+func (b *sqlBuffer) writeTable(table string, dialect dialects.Dialect) {
+	trimmed := strings.TrimSpace(table)
+	if i := strings.IndexFunc(trimmed, unicode.IsSpace); i > 0 {
+		alias := strings.TrimSpace(trimmed[i:])
+		if strings.IndexFunc(alias, unicode.IsSpace) < 0 {
+			b.writeColumn(trimmed[:i], dialect)
+			b.WriteString(" AS ")
+			b.writeIdentifier(alias, dialect)
+			return
+		}
+	}
+	b.writeColumn(table, dialect)
+}
+
+// writeInt writes n in decimal.
+func (b *sqlBuffer) writeInt(n int64) {
+	b.Write(strconv.AppendInt(b.AvailableBuffer(), n, 10))
+}
+
+// writePlaceholder writes the dialect's placeholder for parameter index.
+func (b *sqlBuffer) writePlaceholder(index int, dialect dialects.Dialect) {
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		b.WriteByte('$')
+		b.writeInt(int64(index))
+	case *dialects.MySQLDialect, *dialects.SQLiteDialect:
+		b.WriteByte('?')
+	default:
+		b.WriteString(dialect.Placeholder(index))
+	}
+}
+
+// numberedPlaceholders reports whether the dialect numbers its placeholders
+// ($1, $2) rather than using "?" for all of them.
+func numberedPlaceholders(dialect dialects.Dialect) bool {
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		return true
+	case *dialects.MySQLDialect, *dialects.SQLiteDialect:
+		return false
+	default:
+		return dialect.Placeholder(1) != "?"
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/stretchr/testify/assert"
+)
+
+// customDialect is a dialect sqlBuffer has no fast path for.
+type customDialect struct{ *dialects.PostgresDialect }
+
+func (customDialect) QuoteIdentifier(s string) string { return "[" + s + "]" }
+func (customDialect) Placeholder(i int) string        { return ":p" + string(rune('0'+i)) }
+
+func TestSQLBuffer_MatchesDialectQuoting(t *testing.T) {
+	names := []string{"users", `we"ird`, "back`tick", "nul\x00byte", ""}
+	tables := []string{"users", "users u", " public.users  u ", "public.users", "a b c", "users\tu"}
+	columns := []string{"id", "u.id", "public.users.id", "COUNT(id)"}
+
+	for _, name := range []string{"postgres", "mysql", "sqlite"} {
+		dialect := dialects.GetDialect(name)
+		for _, s := range names {
+			b := getSQLBuffer()
+			b.writeIdentifier(s, dialect)
+			assert.Equal(t, dialect.QuoteIdentifier(s), b.String(), "%s identifier %q", name, s)
+			b.release()
+		}
+		for _, s := range tables {
+			b := getSQLBuffer()
+			b.writeTable(s, dialect)
+			assert.Equal(t, tableWithAlias(s, dialect), b.String(), "%s table %q", name, s)
+			b.release()
+		}
+		for _, s := range columns {
+			b := getSQLBuffer()
+			b.writeColumn(s, dialect)
+			assert.Equal(t, quoteColumn(s, dialect), b.String(), "%s column %q", name, s)
+			b.release()
+		}
+	}
+
+	b := getSQLBuffer()
+	defer b.release()
+	b.writeTable("users u", customDialect{&dialects.PostgresDialect{}})
+	assert.Equal(t, "[users] AS [u]", b.String())
+}
+
+func TestSQLBuffer_WriteNumbered(t *testing.T) {
+	pg := dialects.GetDialect("postgres")
+	tests := []struct {
+		fragment  string
+		start, n  int
+		dialect   dialects.Dialect
+		want      string
+		wantCount int
+	}{
+		{"a = ? AND b = ?", 3, 2, pg, "a = $3 AND b = $4", 2},
+		{"a = ? AND b = ?", 1, 1, pg, "a = $1 AND b = ?", 1},
+		{"a IN (?, ?)", 1, 2, dialects.GetDialect("mysql"), "a IN (?, ?)", 0},
+		{"a = ?", 7, 1, customDialect{&dialects.PostgresDialect{}}, "a = :p7", 1},
+	}
+	for _, tt := range tests {
+		b := getSQLBuffer()
+		n := b.writeNumberedCount(tt.fragment, tt.start, tt.n, tt.dialect)
+		assert.Equal(t, tt.want, b.String(), tt.fragment)
+		assert.Equal(t, tt.wantCount, n, tt.fragment)
+		b.release()
+	}
+
+	// Fragments numbered from $1 are shifted like renumberFragment does.
+	b := getSQLBuffer()
+	defer b.release()
+	b.writeNumbered("x = $1 OR y = $2", 4, 2, pg)
+	assert.Equal(t, renumberFragment("x = $1 OR y = $2", 4, 2, pg), b.String())
+}
+
+func TestSQLBuffer_Release(t *testing.T) {
+	b := getSQLBuffer()
+	b.WriteString("SELECT 1")
+	b.release()
+
+	// Pooled buffers come back empty.
+	b = getSQLBuffer()
+	assert.Equal(t, 0, b.Len())
+	b.release()
+
+	// Oversized buffers are not pooled.
+	b = getSQLBuffer()
+	b.Grow(maxPooledSQLBuffer + 1)
+	b.WriteString("x")
+	b.release()
+	assert.Equal(t, 1, b.Len())
+}
+
+func TestBuildSQL_Allocations(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	sq := qb.Select("id", "name", "email").
+		From("users u").
+		InnerJoin("orders o", "o.user_id = u.id").
+		Where("u.status = ?", 1).
+		AndWhere("u.age > ?", 18).
+		GroupBy("u.id").
+		OrderBy("name").
+		Limit(10)
+	dialect := qb.db.dialect
+	sq.buildSQL(dialect) // warm the buffer pool
+
+	allocs := testing.AllocsPerRun(100, func() {
+		sq.buildSQL(dialect)
+	})
+	// The SQL string and the parameter slice, plus a new buffer when the pool
+	// drops one (as it does at random under the race detector). Rendering
+	// clause by clause took about 30.
+	assert.LessOrEqual(t, allocs, 10.0)
+}
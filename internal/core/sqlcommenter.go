@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// SQL comment injection (sqlcommenter)
+// ============================================================================
+//
+// With WithSQLCommenter, every statement executed through relica gets a trailing
+// comment in the sqlcommenter format:
+//
+//	SELECT * FROM "users" WHERE id = $1 /*application='billing',traceparent='00-...-01'*/
+//
+// Database slow query logs and pg_stat_activity then show which service and
+// trace issued the query. Keys are sorted, keys and values are URL-encoded and
+// values are single-quoted, so a tag can never close the comment early.
+//
+// Static tags (CommentTag, CommentApplication) produce the same SQL on every
+// call, so the prepared statement cache keeps working. Per-request tags (from
+// ContextWithCommentTags or CommentFromContext) make every statement unique;
+// those queries are executed directly instead of being prepared and cached.
+//
+// Raw DB.ExecContext/QueryContext calls are passed through unchanged; use
+// DB.NewQuery for hand-written SQL that should be commented.
+
+// SQLCommenterOption configures WithSQLCommenter.
+type SQLCommenterOption func(*sqlCommenter)
+
+// sqlCommenter holds the tag sources used to build statement comments.
+type sqlCommenter struct {
+	static    map[string]string
+	providers []func(ctx context.Context) map[string]string
+}
+
+// commentTagsKey is the context key for per-request comment tags.
+type commentTagsKey struct{}
+
+// WithSQLCommenter appends a sqlcommenter comment to every executed statement.
+// Without options, only per-request tags from ContextWithCommentTags are emitted.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn,
+//	    relica.WithSQLCommenter(
+//	        relica.CommentApplication("billing"),
+//	        relica.CommentFromContext(func(ctx context.Context) map[string]string {
+//	            return map[string]string{"traceparent": traceparentFrom(ctx)}
+//	        }),
+//	    ))
+func WithSQLCommenter(opts ...SQLCommenterOption) Option {
+	return optionFunc(func(db *DB) {
+		c := &sqlCommenter{static: make(map[string]string)}
+		for _, opt := range opts {
+			opt(c)
+		}
+		db.commenter = c
+	})
+}
+
+// CommentTag adds a static key/value tag to every statement comment.
+func CommentTag(key, value string) SQLCommenterOption {
+	return func(c *sqlCommenter) {
+		if key != "" {
+			c.static[key] = value
+		}
+	}
+}
+
+// CommentApplication adds the standard application='name' tag.
+func CommentApplication(name string) SQLCommenterOption {
+	return CommentTag("application", name)
+}
+
+// CommentFromContext registers a function that extracts tags from the query context,
+// typically "traceparent" (and "tracestate") from the active tracing span.
+// Empty values are skipped.
+func CommentFromContext(fn func(ctx context.Context) map[string]string) SQLCommenterOption {
+	return func(c *sqlCommenter) {
+		if fn != nil {
+			c.providers = append(c.providers, fn)
+		}
+	}
+}
+
+// ContextWithCommentTags returns a context carrying additional comment tags for
+// queries executed with it (e.g. route or controller). Tags accumulate across calls;
+// later values win. Ignored unless WithSQLCommenter is enabled.
+func ContextWithCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string)
+	if existing, ok := ctx.Value(commentTagsKey{}).(map[string]string); ok {
+		for k, v := range existing {
+			merged[k] = v
+		}
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, commentTagsKey{}, merged)
+}
+
+// comment builds the statement comment for ctx. dynamic reports whether any tag
+// came from the context, i.e. whether the comment may differ between calls.
+func (c *sqlCommenter) comment(ctx context.Context) (comment string, dynamic bool) {
+	tags := make(map[string]string, len(c.static))
+	for k, v := range c.static {
+		tags[k] = v
+	}
+	for _, fn := range c.providers {
+		for k, v := range fn(ctx) {
+			if k != "" && v != "" {
+				tags[k] = v
+				dynamic = true
+			}
+		}
+	}
+	if ctxTags, ok := ctx.Value(commentTagsKey{}).(map[string]string); ok {
+		for k, v := range ctxTags {
+			if k != "" {
+				tags[k] = v
+				dynamic = true
+			}
+		}
+	}
+	return formatSQLComment(tags), dynamic
+}
+
+// formatSQLComment serializes tags in the sqlcommenter format.
+// Returns an empty string if there are no tags.
+func formatSQLComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		// QueryEscape encodes '*', '/' and '\'', so the comment cannot be terminated early.
+		pairs[i] = url.QueryEscape(k) + "='" + url.QueryEscape(tags[k]) + "'"
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// appendSQLComment places comment at the end of query (before a trailing semicolon).
+// Statements already ending in a comment are left unchanged, so an outer
+// commenter layer does not add a second one.
+func appendSQLComment(query, comment string) string {
+	if comment == "" {
+		return query
+	}
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	if strings.HasSuffix(trimmed, "*/") {
+		return query
+	}
+	if strings.HasSuffix(trimmed, ";") {
+		return strings.TrimSuffix(trimmed, ";") + " " + comment + ";"
+	}
+	return trimmed + " " + comment
+}
+
+// sqlConn is the direct execution interface shared by *sql.DB, *sql.Tx and *sql.Conn.
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// commentedSQL returns the query text to send to the database and whether it
+// carries per-request tags (and must therefore bypass the statement cache).
+func (q *Query) commentedSQL(ctx context.Context) (string, bool) {
+	if q.db == nil || q.db.commenter == nil {
+		return q.sql, false
+	}
+	comment, dynamic := q.db.commenter.comment(ctx)
+	return appendSQLComment(q.sql, comment), dynamic
+}
+
+// directConn returns the connection and SQL text for direct (unprepared) execution.
+// Transactions and pinned connections (see Conn) always execute directly; so do
+// non-prepared queries whose comment carries per-request tags, which would
+// otherwise flood the statement cache.
+func (q *Query) directConn(ctx context.Context) (sqlConn, string, bool) {
+	if q.prepared {
+		return nil, "", false
+	}
+	query, dynamic := q.commentedSQL(ctx)
+	if q.tx != nil {
+		return q.tx, query, true
+	}
+	if q.db.pinned != nil {
+		return q.db.pinned, query, true
+	}
+	if dynamic {
+		return q.db.sqlDB, query, true
+	}
+	return nil, "", false
+}
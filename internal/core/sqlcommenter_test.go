@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestFormatSQLComment(t *testing.T) {
+	assert.Equal(t, "", formatSQLComment(nil))
+
+	comment := formatSQLComment(map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"application": "billing",
+		"route":       "/api/users/{id}",
+	})
+	assert.Equal(t,
+		"/*application='billing',route='%2Fapi%2Fusers%2F%7Bid%7D',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/",
+		comment)
+}
+
+func TestFormatSQLComment_CannotEscapeComment(t *testing.T) {
+	comment := formatSQLComment(map[string]string{"x": "a'*/ DROP TABLE users; /*"})
+	assert.Equal(t, 1, strings.Count(comment, "*/"))
+	assert.NotContains(t, comment, "'a'")
+	assert.Equal(t, "/*x='a%27%2A%2F+DROP+TABLE+users%3B+%2F%2A'*/", comment)
+}
+
+func TestAppendSQLComment(t *testing.T) {
+	c := "/*application='svc'*/"
+	assert.Equal(t, "SELECT 1", appendSQLComment("SELECT 1", ""))
+	assert.Equal(t, "SELECT 1 /*application='svc'*/", appendSQLComment("SELECT 1", c))
+	assert.Equal(t, "SELECT 1 /*application='svc'*/;", appendSQLComment("SELECT 1;", c))
+	assert.Equal(t, "SELECT 1 /*other*/", appendSQLComment("SELECT 1 /*other*/", c))
+}
+
+func TestSQLCommenter_ContextTags(t *testing.T) {
+	c := &sqlCommenter{static: map[string]string{"application": "svc"}}
+	c.providers = append(c.providers, func(ctx context.Context) map[string]string {
+		return map[string]string{"traceparent": "tp", "tracestate": ""}
+	})
+
+	comment, dynamic := c.comment(context.Background())
+	assert.True(t, dynamic)
+	assert.Equal(t, "/*application='svc',traceparent='tp'*/", comment)
+
+	ctx := ContextWithCommentTags(context.Background(), map[string]string{"route": "a"})
+	ctx = ContextWithCommentTags(ctx, map[string]string{"application": "override"})
+	comment, _ = c.comment(ctx)
+	assert.Equal(t, "/*application='override',route='a',traceparent='tp'*/", comment)
+
+	static := &sqlCommenter{static: map[string]string{"application": "svc"}}
+	comment, dynamic = static.comment(context.Background())
+	assert.False(t, dynamic)
+	assert.Equal(t, "/*application='svc'*/", comment)
+}
+
+func TestWithSQLCommenter_StaticUsesStatementCache(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithSQLCommenter(CommentApplication("svc")))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var n int
+	q := db.NewQuery("SELECT 1")
+	require.NoError(t, q.Row(&n))
+	assert.Equal(t, 1, n)
+
+	// The commented statement is what gets prepared and cached.
+	_, ok := db.stmtCache.Get("SELECT 1 /*application='svc'*/")
+	assert.True(t, ok)
+}
+
+func TestWithSQLCommenter_ContextTagsBypassStatementCache(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithSQLCommenter(
+		CommentApplication("svc"),
+		CommentFromContext(func(ctx context.Context) map[string]string {
+			return map[string]string{"traceparent": "00-abc-def-01"}
+		}),
+	))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	_, err = db.Builder().Insert("items", map[string]interface{}{"id": 1, "name": "a"}).Execute()
+	require.NoError(t, err)
+
+	var name string
+	err = db.Builder().Select("name").From("items").Where("id = ?", 1).Row(&name)
+	require.NoError(t, err)
+	assert.Equal(t, "a", name)
+
+	assert.Equal(t, 0, db.stmtCache.Stats().Size)
+}
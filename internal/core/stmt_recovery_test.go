@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openStmtRecoveryDB returns a SQLite DB with an empty events table.
+func openStmtRecoveryDB(t *testing.T, opts ...Option) *DB {
+	t.Helper()
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "stmt.db"), opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	return db
+}
+
+func TestPrepareStatement_CanceledNotCached(t *testing.T) {
+	db := openStmtRecoveryDB(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var n int
+	err := db.NewQuery("SELECT COUNT(*) FROM events").WithContext(ctx).Row(&n)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, db.stmtCache.Stats().Size, "a canceled preparation is not cached")
+
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM events").Row(&n))
+	assert.Equal(t, 1, db.stmtCache.Stats().Size)
+}
+
+func TestRunStmt_ClosedStatementPreparedAgain(t *testing.T) {
+	db := openStmtRecoveryDB(t)
+	insert := "INSERT INTO events (name) VALUES (?)"
+
+	_, err := db.NewQuery(insert, "first").Execute()
+	require.NoError(t, err)
+	stale, ok := db.stmtCache.Get(insert)
+	require.True(t, ok)
+
+	// An eviction closes the statement while a query still holds it
+	require.NoError(t, stale.Close())
+	_, err = db.NewQuery(insert, "second").Execute()
+	require.NoError(t, err)
+
+	_, ok = db.stmtCache.Get(insert)
+	assert.False(t, ok, "the closed statement was evicted")
+
+	var names []string
+	require.NoError(t, db.NewQuery("SELECT name FROM events ORDER BY id").Column(&names))
+	assert.Equal(t, []string{"first", "second"}, names, "the write ran exactly once")
+}
+
+func TestRunStmt_CanceledKeepsStatement(t *testing.T) {
+	db := openStmtRecoveryDB(t)
+	query := "SELECT COUNT(*) FROM events WHERE id > ?"
+
+	var n int
+	require.NoError(t, db.NewQuery(query, 0).Row(&n))
+	cached, ok := db.stmtCache.Get(query)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := db.NewQuery(slowSQLiteQuery).WithContext(ctx).Row(&n)
+	require.Error(t, err)
+
+	err = db.NewQuery(query, 0).Row(&n)
+	require.NoError(t, err)
+	got, ok := db.stmtCache.Get(query)
+	require.True(t, ok)
+	assert.Same(t, cached, got)
+}
+
+func TestIsStaleStmt(t *testing.T) {
+	assert.True(t, isStaleStmt(errors.New("sql: statement is closed")))
+	assert.True(t, isStaleStmt(errors.New(`pq: prepared statement "3" does not exist`)))
+	assert.True(t, isStaleStmt(errors.New("Error 1243 (HY000): Unknown prepared statement handler (1) given to mysqld_stmt_execute")))
+	assert.True(t, isStaleStmt(sqlStateError("26000")))
+	assert.False(t, isStaleStmt(errors.New("no such table: events")))
+	assert.False(t, isStaleStmt(context.Canceled))
+}
+
+// sqlStateError is a driver error with a SQLSTATE code.
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "driver error " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+// TestStmtCache_CancellationUnderLoad runs queries concurrently through a
+// cache too small to hold them, canceling many of them mid-flight. Only the
+// canceled queries may fail, and the cache must keep working afterwards.
+func TestStmtCache_CancellationUnderLoad(t *testing.T) {
+	db := openStmtRecoveryDB(t, WithStmtCacheCapacity(2))
+	db.sqlDB.SetMaxOpenConns(8)
+
+	const (
+		workers    = 32
+		iterations = 100
+		queries    = 20
+	)
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*iterations)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				ctx, cancel := context.Background(), context.CancelFunc(func() {})
+				if (w+i)%2 == 0 {
+					ctx, cancel = context.WithTimeout(ctx, time.Duration(i%5)*100*time.Microsecond)
+				}
+				var n int
+				query := fmt.Sprintf("SELECT COUNT(*) + %d FROM events WHERE id > ?", (w+i)%queries)
+				err := db.NewQuery(query, 0).WithContext(ctx).Row(&n)
+				if err != nil && ctx.Err() == nil {
+					errs <- err
+				}
+				cancel()
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("query without canceled context failed: %v", err)
+	}
+
+	assert.LessOrEqual(t, db.stmtCache.Stats().Size, 2)
+	for i := 0; i < queries; i++ {
+		var n int
+		query := fmt.Sprintf("SELECT COUNT(*) + %d FROM events WHERE id > ?", i)
+		require.NoError(t, db.NewQuery(query, 0).Row(&n))
+		assert.Equal(t, i, n)
+	}
+}
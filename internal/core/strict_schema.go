@@ -0,0 +1,361 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Strict schema mode
+// ============================================================================
+//
+// With WithStrictSchema, builder queries check the tables and columns they
+// name against the connected database before sending SQL, so a typo fails
+// with ErrUnknownTable or ErrUnknownColumn instead of a driver error:
+//
+//	relica: strict schema: column "emial" does not exist in table "users" (did you mean "email"?)
+//
+// The columns of each table are read once from the database catalog
+// (information_schema on PostgreSQL and MySQL, pragma_table_info on SQLite)
+// and cached. DDL run through relica forgets the cached tables it changes, a
+// missing column reloads its table once, and a missing table is never cached,
+// so the catalog follows migrations without a restart.
+//
+// Only names the builders know structurally are checked: FROM and JOIN
+// tables and plain SELECT columns, the table and columns of INSERT, UPDATE,
+// UPSERT and batch statements, and DELETE and TRUNCATE tables. WHERE strings,
+// expressions, subqueries, CTE names and raw SQL are not parsed. Names are
+// compared case-insensitively.
+
+// WithStrictSchema makes builder queries check that the tables and columns
+// they reference exist before executing (see ErrUnknownTable,
+// ErrUnknownColumn). The catalog is read lazily, once per table.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithStrictSchema())
+//
+//	_, err := db.Update("users").Set(relica.Params{"emial": addr}).Where(relica.Eq("id", 1)).Execute()
+//	// errors.Is(err, relica.ErrUnknownColumn)
+func WithStrictSchema() Option {
+	return optionFunc(func(db *DB) {
+		db.catalog = &schemaCatalog{tables: make(map[string]map[string]bool)}
+	})
+}
+
+// schemaRefs are the tables and columns a query references.
+type schemaRefs struct {
+	tables  []string
+	columns []columnRef
+}
+
+// columnRef is a referenced column, which must exist in one of tables.
+type columnRef struct {
+	tables []string
+	column string
+}
+
+// schemaCatalog caches the columns of the tables of a database. It is shared
+// by DB copies.
+type schemaCatalog struct {
+	mu     sync.Mutex
+	tables map[string]map[string]bool // lower-case table → lower-case columns
+}
+
+// newSchemaRefs returns the references of a statement on table with the
+// given columns, or nil if strict schema mode is disabled.
+func (db *DB) newSchemaRefs(table string, columns ...string) *schemaRefs {
+	if db.catalog == nil || !plainIdentifierRegex.MatchString(table) {
+		return nil
+	}
+	refs := &schemaRefs{tables: []string{table}}
+	for _, col := range columns {
+		refs.column([]string{table}, col)
+	}
+	return refs
+}
+
+// column adds a column of one of tables; expressions are skipped.
+func (r *schemaRefs) column(tables []string, column string) {
+	if column == "*" || !plainIdentifierRegex.MatchString(column) {
+		return
+	}
+	r.columns = append(r.columns, columnRef{tables: tables, column: column})
+}
+
+// selectSchemaRefs returns the FROM and JOIN tables and plain SELECT columns
+// of sq, or nil if strict schema mode is disabled.
+func (sq *SelectQuery) selectSchemaRefs() *schemaRefs {
+	db := sq.builder.db
+	if db.catalog == nil {
+		return nil
+	}
+
+	ctes := make(map[string]bool)
+	for _, cte := range sq.builder.ctes {
+		ctes[strings.ToLower(cte.name)] = true
+	}
+	for _, cte := range sq.ctes {
+		ctes[strings.ToLower(cte.name)] = true
+	}
+	refs := &schemaRefs{}
+	aliases := make(map[string]string) // lower-case alias or table → table
+	opaque := false                    // FROM or JOIN source that is not a checked table
+	addTable := func(source string) {
+		parts := strings.Fields(source)
+		if len(parts) == 0 || len(parts) > 2 || !plainIdentifierRegex.MatchString(parts[0]) || ctes[strings.ToLower(parts[0])] {
+			opaque = true
+			if len(parts) == 2 {
+				aliases[strings.ToLower(parts[1])] = ""
+			}
+			return
+		}
+		table := parts[0]
+		refs.tables = append(refs.tables, table)
+		aliases[strings.ToLower(lastSegment(table))] = table
+		if len(parts) == 2 {
+			aliases[strings.ToLower(parts[1])] = table
+		}
+	}
+
+	switch {
+	case sq.fromSrc != nil && sq.fromSrc.isSubquery:
+		opaque = true
+		aliases[strings.ToLower(sq.fromSrc.alias)] = ""
+	case sq.fromSrc != nil:
+		addTable(sq.fromSrc.table)
+	case sq.table != "":
+		addTable(sq.table)
+	}
+	for _, join := range sq.joins {
+		if join.subquery != nil {
+			opaque = true
+			aliases[strings.ToLower(join.Table)] = ""
+			continue
+		}
+		addTable(join.Table)
+	}
+
+	for _, col := range sq.columns {
+		if m := selectAliasRegex.FindStringSubmatch(col); len(m) > 0 {
+			col = col[:len(col)-len(m[0])]
+		}
+		col = strings.TrimSpace(col)
+		if i := strings.LastIndex(col, "."); i >= 0 {
+			table, ok := aliases[strings.ToLower(col[:i])]
+			if !ok || table == "" {
+				continue // unknown qualifier: left to the database
+			}
+			refs.column([]string{table}, col[i+1:])
+			continue
+		}
+		if !opaque && len(refs.tables) > 0 {
+			refs.column(refs.tables, col)
+		}
+	}
+
+	if len(refs.tables) == 0 {
+		return nil
+	}
+	return refs
+}
+
+// checkSchema returns ErrUnknownTable or ErrUnknownColumn if a table or
+// column referenced by the query does not exist.
+func (q *Query) checkSchema(ctx context.Context) error {
+	c := q.db.catalog
+	var conn sqlConn = q.db.conn()
+	if q.tx != nil {
+		conn = q.tx
+	}
+
+	for _, table := range q.refs.tables {
+		cols, err := c.columns(ctx, q.db, conn, table, false)
+		if err != nil {
+			return err
+		}
+		if cols == nil {
+			return fmt.Errorf("relica: strict schema: table %q does not exist: %w", table, ErrUnknownTable)
+		}
+	}
+
+	for _, ref := range q.refs.columns {
+		if c.hasColumn(ref) {
+			continue
+		}
+		// Reload once in case the table changed outside relica.
+		for _, table := range ref.tables {
+			if _, err := c.columns(ctx, q.db, conn, table, true); err != nil {
+				return err
+			}
+		}
+		if c.hasColumn(ref) {
+			continue
+		}
+
+		quoted := make([]string, len(ref.tables))
+		for i, table := range ref.tables {
+			quoted[i] = fmt.Sprintf("%q", table)
+		}
+		msg := fmt.Sprintf("relica: strict schema: column %q does not exist in table %s", ref.column, quoted[0])
+		if len(quoted) > 1 {
+			msg = fmt.Sprintf("relica: strict schema: column %q does not exist in tables %s", ref.column, strings.Join(quoted, ", "))
+		}
+		if s := c.suggest(ref); s != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", s)
+		}
+		return fmt.Errorf("%s: %w", msg, ErrUnknownColumn)
+	}
+	return nil
+}
+
+// hasColumn reports whether ref.column exists in one of ref.tables.
+func (c *schemaCatalog) hasColumn(ref columnRef) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	col := strings.ToLower(lastSegment(ref.column))
+	for _, table := range ref.tables {
+		if c.tables[strings.ToLower(table)][col] {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the column of ref.tables closest to ref.column, or "" if
+// none is within two edits.
+func (c *schemaCatalog) suggest(ref columnRef) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	col := strings.ToLower(lastSegment(ref.column))
+	best, bestDist := "", 3
+	for _, table := range ref.tables {
+		for name := range c.tables[strings.ToLower(table)] {
+			if d := editDistance(col, name); d < bestDist || (d == bestDist && name < best) {
+				best, bestDist = name, d
+			}
+		}
+	}
+	return best
+}
+
+// columns returns the lower-case column set of table, loading it from the
+// database on first use or when reload is set. Returns nil for a table that
+// does not exist.
+func (c *schemaCatalog) columns(ctx context.Context, db *DB, conn sqlConn, table string, reload bool) (map[string]bool, error) {
+	key := strings.ToLower(table)
+	c.mu.Lock()
+	cols, ok := c.tables[key]
+	c.mu.Unlock()
+	if ok && !reload {
+		return cols, nil
+	}
+
+	cols, err := loadColumns(ctx, db.dialect, conn, table)
+	if err != nil {
+		return nil, fmt.Errorf("relica: strict schema: inspect table %q: %w", table, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cols == nil {
+		delete(c.tables, key)
+	} else {
+		c.tables[key] = cols
+	}
+	return cols, nil
+}
+
+// forget drops tables from the cache; no tables drops all of them.
+func (c *schemaCatalog) forget(tables []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(tables) == 0 {
+		clear(c.tables)
+		return
+	}
+	for _, table := range tables {
+		table = strings.ToLower(table)
+		for key := range c.tables {
+			if key == table || lastSegment(key) == table {
+				delete(c.tables, key)
+			}
+		}
+	}
+}
+
+// loadColumns reads the columns of table (optionally schema-qualified) from
+// the database catalog. Returns nil if the table does not exist.
+func loadColumns(ctx context.Context, dialect dialects.Dialect, conn sqlConn, table string) (map[string]bool, error) {
+	schema, name := "", table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+
+	var query string
+	var args []interface{}
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		query = "SELECT column_name FROM information_schema.columns WHERE table_name = $1 AND " +
+			"(table_schema = $2::text OR ($2::text = '' AND table_schema = ANY (current_schemas(false))))"
+		args = []interface{}{name, schema}
+	case *dialects.MySQLDialect:
+		query = "SELECT column_name FROM information_schema.columns WHERE table_name = ? AND table_schema = COALESCE(NULLIF(?, ''), DATABASE())"
+		args = []interface{}{name, schema}
+	default:
+		query = "SELECT name FROM pragma_table_info(?)"
+		args = []interface{}{name}
+		if schema != "" {
+			query = "SELECT name FROM pragma_table_info(?, ?)"
+			args = append(args, schema)
+		}
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols map[string]bool
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, err
+		}
+		if cols == nil {
+			cols = make(map[string]bool)
+		}
+		cols[strings.ToLower(col)] = true
+	}
+	return cols, rows.Err()
+}
+
+// lastSegment returns the part of a dotted name after the last dot.
+func lastSegment(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
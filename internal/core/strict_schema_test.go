@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStrictSchemaDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:", WithStrictSchema())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER, title TEXT)")
+	require.NoError(t, err)
+	return db
+}
+
+func TestStrictSchema_Valid(t *testing.T) {
+	db := setupStrictSchemaDB(t)
+	qb := db.Builder()
+
+	_, err := qb.Insert("users", map[string]interface{}{"id": 1, "name": "ann", "email": "ann@example.com"}).Execute()
+	require.NoError(t, err)
+	_, err = qb.Update("users").Set(map[string]interface{}{"NAME": "Ann"}).Where("id = ?", 1).Execute()
+	require.NoError(t, err)
+	_, err = qb.BatchInsert("posts", []string{"user_id", "title"}).Values(1, "hello").Execute()
+	require.NoError(t, err)
+
+	var rows []struct {
+		Name  string `db:"name"`
+		Title string `db:"title"`
+	}
+	err = qb.Select("u.name", "p.title AS title", "COUNT(*) OVER () AS total").
+		From("users u").
+		InnerJoin("posts p", "p.user_id = u.id").
+		All(&rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	// CTE names and subquery sources are not checked
+	var n int
+	err = qb.With("recent", qb.Select("id").From("posts")).
+		Select("id").From("recent").Row(&n)
+	require.NoError(t, err)
+	err = qb.Select("x.cnt").FromSelect(qb.Select("COUNT(*) AS cnt").From("posts"), "x").Row(&n)
+	require.NoError(t, err)
+
+	var name string
+	var cnt int
+	err = qb.Select("u.name", "c.cnt").From("users u").
+		InnerJoinSelect(qb.Select("user_id", "COUNT(*) AS cnt").From("posts").GroupBy("user_id"), "c", "c.user_id = u.id").
+		Row(&name, &cnt)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cnt)
+
+	require.NoError(t, qb.Truncate("posts").Execute())
+	_, err = qb.Delete("users").Where("id = ?", 1).Execute()
+	require.NoError(t, err)
+}
+
+func TestStrictSchema_Unknown(t *testing.T) {
+	db := setupStrictSchemaDB(t)
+	qb := db.Builder()
+
+	_, err := qb.Insert("userz", map[string]interface{}{"name": "ann"}).Execute()
+	assert.ErrorIs(t, err, ErrUnknownTable)
+	assert.EqualError(t, err, `relica: strict schema: table "userz" does not exist: relica: unknown table`)
+
+	_, err = qb.Update("users").Set(map[string]interface{}{"emial": "a@b.c"}).Where("id = ?", 1).Execute()
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+	assert.EqualError(t, err, `relica: strict schema: column "emial" does not exist in table "users" (did you mean "email"?): relica: unknown column`)
+
+	var names []string
+	err = qb.Select("u.nmae").From("users u").Column(&names)
+	assert.ErrorContains(t, err, `column "nmae" does not exist in table "users" (did you mean "name"?)`)
+
+	err = qb.Select("title", "body").From("users").InnerJoin("posts", "posts.user_id = users.id").Column(&names)
+	assert.ErrorContains(t, err, `column "body" does not exist in tables "users", "posts"`)
+
+	err = qb.Truncate("post").Execute()
+	assert.ErrorIs(t, err, ErrUnknownTable)
+
+	// Raw SQL is not checked
+	_, err = db.NewQuery("SELECT nope FROM users").Execute()
+	assert.NotErrorIs(t, err, ErrUnknownColumn)
+}
+
+func TestStrictSchema_FollowsDDL(t *testing.T) {
+	db := setupStrictSchemaDB(t)
+	ctx := context.Background()
+	qb := db.Builder()
+
+	_, err := qb.Insert("users", map[string]interface{}{"nickname": "a"}).Execute()
+	require.ErrorIs(t, err, ErrUnknownColumn)
+
+	_, err = db.ExecContext(ctx, "ALTER TABLE users ADD COLUMN nickname TEXT")
+	require.NoError(t, err)
+	_, err = qb.Insert("users", map[string]interface{}{"nickname": "a"}).Execute()
+	require.NoError(t, err)
+
+	// Changed outside relica: the missing column reloads the table
+	_, err = db.sqlDB.ExecContext(ctx, "ALTER TABLE posts ADD COLUMN body TEXT")
+	require.NoError(t, err)
+	_, err = qb.Insert("posts", map[string]interface{}{"body": "b"}).Execute()
+	require.NoError(t, err)
+
+	// Created in a transaction: visible to the transaction's queries
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		if _, err := tx.tx.ExecContext(ctx, "CREATE TABLE tags (id INTEGER PRIMARY KEY, label TEXT)"); err != nil {
+			return err
+		}
+		_, err := tx.Builder().Insert("tags", map[string]interface{}{"label": "go"}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestStrictSchema_Disabled(t *testing.T) {
+	db := mockDB("postgres")
+	q := db.Builder().Insert("users", map[string]interface{}{"name": "ann"})
+	assert.Nil(t, q.refs)
+	assert.Nil(t, (&QueryBuilder{db: db}).Select("id").From("users").Build().refs)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("email", "email"))
+	assert.Equal(t, 2, editDistance("emial", "email"))
+	assert.Equal(t, 1, editDistance("nam", "name"))
+	assert.Equal(t, 5, editDistance("", "email"))
+}
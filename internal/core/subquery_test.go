@@ -9,6 +9,7 @@ import (
 
 	"github.com/coregx/relica/internal/dialects"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // ============================================================================
@@ -152,7 +153,8 @@ func TestSelectQuery_FromSelect_PostgreSQL(t *testing.T) {
 
 	// Create outer query with FROM subquery
 	outer := qb.Select("user_id", "cnt").FromSelect(sub, "order_counts").Where("cnt > ?", 10)
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `SELECT`)
 	assert.Contains(t, sql, `FROM (SELECT`)
@@ -169,7 +171,8 @@ func TestSelectQuery_FromSelect_MySQL(t *testing.T) {
 
 	sub := qb.Select("user_id", "SUM(total) as total").From("orders").GroupBy("user_id")
 	outer := qb.Select("*").FromSelect(sub, "user_totals").Where("total > ?", 1000)
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, "FROM (SELECT")
 	assert.Contains(t, sql, ") AS `user_totals`")
@@ -199,7 +202,8 @@ func TestSelectQuery_FromSelect_WithWhere(t *testing.T) {
 
 	sub := qb.Select("user_id").From("orders").Where("status = ?", "pending")
 	outer := qb.Select("user_id").FromSelect(sub, "pending_orders")
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `FROM (SELECT`)
 	assert.Contains(t, sql, `WHERE status =`) // PostgreSQL converts ? to $1
@@ -220,7 +224,8 @@ func TestSelectQuery_FromSelect_Nested(t *testing.T) {
 
 	// Outer query
 	outer := qb.Select("user_id").FromSelect(middle, "active_users")
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, "FROM (SELECT")
 	assert.Contains(t, sql, "FROM (SELECT")
@@ -238,7 +243,8 @@ func TestSelectQuery_FromSelect_WithJoin(t *testing.T) {
 	outer := qb.Select("u.name", "ot.total").
 		FromSelect(sub, "ot").
 		InnerJoin("users u", "ot.user_id = u.id")
-	sql, _ := outer.buildSQL(dialect)
+	sql, _, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `FROM (SELECT`)
 	assert.Contains(t, sql, `) AS "ot"`)
@@ -257,7 +263,8 @@ func TestSelectQuery_SelectExpr_ScalarSubquery(t *testing.T) {
 	outer := qb.Select("id", "name").
 		SelectExpr("(SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id) as order_count").
 		From("users")
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `SELECT "id", "name", (SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id) as order_count`)
 	assert.Contains(t, sql, `FROM "users"`)
@@ -272,7 +279,8 @@ func TestSelectQuery_SelectExpr_WithParams(t *testing.T) {
 	outer := qb.Select("id", "name").
 		SelectExpr("(SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id AND status = ?) as order_count", "completed").
 		From("users")
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `(SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id AND status = ?) as order_count`)
 	assert.Equal(t, []interface{}{"completed"}, args)
@@ -287,7 +295,8 @@ func TestSelectQuery_SelectExpr_Multiple(t *testing.T) {
 		SelectExpr("(SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id) as order_count").
 		SelectExpr("(SELECT SUM(total) FROM orders WHERE orders.user_id = users.id) as total_spent").
 		From("users")
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `(SELECT COUNT(*) FROM orders`)
 	assert.Contains(t, sql, `(SELECT SUM(total) FROM orders`)
@@ -302,7 +311,8 @@ func TestSelectQuery_SelectExpr_MySQL(t *testing.T) {
 	outer := qb.Select("id").
 		SelectExpr("(SELECT MAX(created_at) FROM orders WHERE user_id = users.id) as last_order").
 		From("users")
-	sql, _ := outer.buildSQL(dialect)
+	sql, _, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, "SELECT `id`, (SELECT MAX(created_at)")
 	assert.Contains(t, sql, "FROM `users`")
@@ -327,7 +337,8 @@ func TestSelectQuery_Combined_FromSelect_And_IN(t *testing.T) {
 	outer := qb.Select("user_id", "cnt").
 		FromSelect(fromSub, "oc").
 		Where(In("user_id", inSub))
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `FROM (SELECT`)
 	assert.Contains(t, sql, `) AS "oc"`)
@@ -349,7 +360,8 @@ func TestSelectQuery_Combined_All_Features(t *testing.T) {
 		FromSelect(fromSub, "ot").
 		Where(In("ot.user_id", inSub)).
 		Where("ot.total > ?", 1000)
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `FROM (SELECT`)
 	assert.Contains(t, sql, ` IN (SELECT`)
@@ -383,7 +395,8 @@ func TestSelectQuery_FromSelect_Empty(t *testing.T) {
 	// Empty subquery (no WHERE)
 	sub := qb.Select("*").From("users")
 	outer := qb.Select("*").FromSelect(sub, "all_users")
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `FROM (SELECT`) // "*" gets quoted as "*" in PostgreSQL
 	assert.Contains(t, sql, `FROM "users") AS "all_users"`)
@@ -398,7 +411,8 @@ func TestSelectQuery_SelectExpr_NoParams(t *testing.T) {
 	outer := qb.Select("id").
 		SelectExpr("CURRENT_TIMESTAMP as created").
 		From("users")
-	sql, args := outer.buildSQL(dialect)
+	sql, args, err := outer.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, "CURRENT_TIMESTAMP as created")
 	assert.Empty(t, args)
@@ -433,7 +447,8 @@ func TestSelectQuery_From_Backward_Compatibility(t *testing.T) {
 
 	// Old From() API should still work
 	sq := qb.Select("*").From("users").Where("id = ?", 1)
-	sql, args := sq.buildSQL(dialect)
+	sql, args, err := sq.buildSQL(dialect)
+	require.NoError(t, err)
 
 	assert.Contains(t, sql, `FROM "users"`)
 	assert.Equal(t, []interface{}{1}, args)
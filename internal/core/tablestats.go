@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Table statistics
+// ============================================================================
+//
+// TableStats reads the size and maintenance state of a table from the
+// database catalog, so capacity dashboards can use the same handle that runs
+// the queries:
+//
+//   - PostgreSQL: pg_class and pg_stat_user_tables. Rows is the planner
+//     estimate, sizes come from pg_table_size and pg_indexes_size, and the
+//     bloat is estimated from the share of dead rows.
+//   - MySQL: information_schema.TABLES (estimated rows, data and index
+//     length, data_free as bloat) and mysql.innodb_table_stats for the last
+//     statistics update, when the user may read it.
+//   - SQLite: an exact COUNT(*), and sizes from the dbstat virtual table when
+//     the driver is built with it. SQLite keeps no analyze or vacuum times.
+
+// TableStats describes the size and maintenance state of a table.
+// Values the database does not report are zero.
+type TableStats struct {
+	Table       string    // table name as given to TableStats
+	Rows        int64     // estimated number of rows (exact on SQLite)
+	DeadRows    int64     // rows deleted or updated but not yet vacuumed (PostgreSQL)
+	TableBytes  int64     // size of the table data, including TOAST on PostgreSQL
+	IndexBytes  int64     // size of all indexes of the table
+	TotalBytes  int64     // TableBytes + IndexBytes
+	BloatBytes  int64     // estimated reclaimable space in TableBytes
+	LastAnalyze time.Time // last ANALYZE, manual or automatic
+	LastVacuum  time.Time // last VACUUM, manual or automatic (PostgreSQL)
+}
+
+// TableStats returns the size and maintenance statistics of table. A table
+// may be schema-qualified ("app.orders"). Statistics are estimates kept by
+// the database and are as fresh as its last ANALYZE.
+//
+// Example:
+//
+//	stats, err := db.TableStats(ctx, "orders")
+//	log.Printf("orders: ~%d rows, %d MiB (%d MiB indexes)",
+//	    stats.Rows, stats.TotalBytes>>20, stats.IndexBytes>>20)
+func (db *DB) TableStats(ctx context.Context, table string) (*TableStats, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stats := &TableStats{Table: table}
+	var err error
+	switch db.dialect.(type) {
+	case *dialects.PostgresDialect:
+		err = db.postgresTableStats(ctx, stats)
+	case *dialects.MySQLDialect:
+		err = db.mysqlTableStats(ctx, stats)
+	case *dialects.SQLiteDialect:
+		err = db.sqliteTableStats(ctx, stats)
+	default:
+		err = ErrUnsupportedDialect
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("relica: table stats %s: table does not exist", table)
+		}
+		return nil, fmt.Errorf("relica: table stats %s: %w", table, err)
+	}
+	stats.TotalBytes = stats.TableBytes + stats.IndexBytes
+	return stats, nil
+}
+
+// postgresTableStats reads stats from pg_class and pg_stat_user_tables.
+func (db *DB) postgresTableStats(ctx context.Context, stats *TableStats) error {
+	var estimate, live int64
+	var lastAnalyze, lastVacuum sql.NullTime
+	err := db.NewQuery(`SELECT c.reltuples::bigint, COALESCE(s.n_live_tup, 0), COALESCE(s.n_dead_tup, 0),
+    pg_table_size(c.oid), pg_indexes_size(c.oid),
+    GREATEST(s.last_analyze, s.last_autoanalyze), GREATEST(s.last_vacuum, s.last_autovacuum)
+FROM pg_class c LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+WHERE c.oid = to_regclass($1)`, quoteColumn(stats.Table, db.dialect)).
+		WithContext(ctx).
+		Row(&estimate, &live, &stats.DeadRows, &stats.TableBytes, &stats.IndexBytes, &lastAnalyze, &lastVacuum)
+	if err != nil {
+		return err
+	}
+
+	// reltuples is -1 until the table is first analyzed (PostgreSQL 14+).
+	stats.Rows = estimate
+	if estimate < 0 {
+		stats.Rows = live
+	}
+	if total := live + stats.DeadRows; total > 0 {
+		stats.BloatBytes = stats.TableBytes * stats.DeadRows / total
+	}
+	stats.LastAnalyze = lastAnalyze.Time
+	stats.LastVacuum = lastVacuum.Time
+	return nil
+}
+
+// mysqlTableStats reads stats from information_schema.TABLES and, if
+// readable, mysql.innodb_table_stats.
+func (db *DB) mysqlTableStats(ctx context.Context, stats *TableStats) error {
+	schema, name := splitQualifiedTable(stats.Table)
+	var rows, data, index, free sql.NullInt64
+	err := db.NewQuery(`SELECT TABLE_ROWS, DATA_LENGTH, INDEX_LENGTH, DATA_FREE
+FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?`, schema, name).
+		WithContext(ctx).
+		Row(&rows, &data, &index, &free)
+	if err != nil {
+		return err
+	}
+	stats.Rows, stats.TableBytes, stats.IndexBytes, stats.BloatBytes = rows.Int64, data.Int64, index.Int64, free.Int64
+
+	// The mysql schema is often not readable by application users; the
+	// analyze time is then left unknown.
+	var updated sql.NullInt64
+	err = db.NewQuery(`SELECT UNIX_TIMESTAMP(last_update) FROM mysql.innodb_table_stats
+WHERE database_name = COALESCE(?, DATABASE()) AND table_name = ?`, schema, name).
+		WithContext(ctx).
+		Row(&updated)
+	if err == nil && updated.Valid {
+		stats.LastAnalyze = time.Unix(updated.Int64, 0)
+	}
+	return nil
+}
+
+// sqliteTableStats counts the rows of the table and reads its sizes from
+// dbstat, if available.
+func (db *DB) sqliteTableStats(ctx context.Context, stats *TableStats) error {
+	schema, name := "main", stats.Table
+	if qualifier, table := splitQualifiedTable(stats.Table); qualifier != nil {
+		schema, name = *qualifier, table
+	}
+	master := db.dialect.QuoteIdentifier(schema) + ".sqlite_master"
+	var exists int
+	err := db.NewQuery("SELECT 1 FROM "+master+" WHERE type = ? AND name = ?", "table", name).
+		WithContext(ctx).
+		Row(&exists)
+	if err != nil {
+		return err
+	}
+	if err := db.NewQuery("SELECT COUNT(*) FROM " + quoteColumn(stats.Table, db.dialect)).
+		WithContext(ctx).
+		Row(&stats.Rows); err != nil {
+		return err
+	}
+
+	// dbstat needs SQLITE_ENABLE_DBSTAT_VTAB; without it sizes stay zero.
+	var tableBytes, indexBytes sql.NullInt64
+	err = db.NewQuery("SELECT "+
+		"(SELECT SUM(pgsize) FROM dbstat WHERE schema = ? AND name = ?), "+
+		"(SELECT SUM(d.pgsize) FROM dbstat d JOIN "+master+" m ON m.name = d.name "+
+		"WHERE d.schema = ? AND m.type = ? AND m.tbl_name = ?)", schema, name, schema, "index", name).
+		WithContext(ctx).
+		Row(&tableBytes, &indexBytes)
+	if err == nil {
+		stats.TableBytes, stats.IndexBytes = tableBytes.Int64, indexBytes.Int64
+	}
+	return nil
+}
+
+// splitQualifiedTable splits "schema.table" into its schema, nil when the
+// table is not qualified, and its name.
+func splitQualifiedTable(table string) (*string, string) {
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		return &schema, name
+	}
+	return nil, table
+}
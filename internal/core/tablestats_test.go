@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStats_SQLite(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY, customer TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "CREATE INDEX orders_customer ON orders (customer)")
+	require.NoError(t, err)
+	bq := db.Builder().BatchInsert("orders", []string{"customer"})
+	for i := 0; i < 50; i++ {
+		bq.Values("customer")
+	}
+	_, err = bq.Execute()
+	require.NoError(t, err)
+
+	stats, err := db.TableStats(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "orders", stats.Table)
+	assert.Equal(t, int64(50), stats.Rows)
+	assert.Positive(t, stats.TableBytes)
+	assert.Positive(t, stats.IndexBytes)
+	assert.Equal(t, stats.TableBytes+stats.IndexBytes, stats.TotalBytes)
+	assert.True(t, stats.LastAnalyze.IsZero())
+
+	qualified, err := db.TableStats(ctx, "main.orders")
+	require.NoError(t, err)
+	assert.Equal(t, stats.Rows, qualified.Rows)
+	assert.Equal(t, stats.TotalBytes, qualified.TotalBytes)
+}
+
+func TestTableStats_MissingTable(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.TableStats(context.Background(), "missing")
+	assert.EqualError(t, err, "relica: table stats missing: table does not exist")
+}
+
+func TestSplitQualifiedTable(t *testing.T) {
+	schema, name := splitQualifiedTable("orders")
+	assert.Nil(t, schema)
+	assert.Equal(t, "orders", name)
+
+	schema, name = splitQualifiedTable("app.orders")
+	require.NotNil(t, schema)
+	assert.Equal(t, "app", *schema)
+	assert.Equal(t, "orders", name)
+}
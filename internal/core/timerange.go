@@ -0,0 +1,147 @@
+package core
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// =============================================================================
+// Time range expressions
+// =============================================================================
+//
+// Today, WithinLast and DateBetween compute their bounds in Go and bind them as
+// time.Time parameters of a half-open range (col >= from AND col < to), so the
+// same SQL works on every dialect, uses indexes on col, and does not depend on
+// the database server's clock or time zone. Calendar days are taken in
+// time.Local unless another location is set with In.
+//
+// Drivers encode time.Time parameters in the zone they carry. Enable WithUTCTimes
+// to convert all time parameters to UTC before execution; this matters for
+// columns without time zone information, such as MySQL DATETIME or SQLite text
+// timestamps, which compare correctly only when written in the same zone.
+
+// timeNow returns the current time (replaced in tests).
+var timeNow = time.Now
+
+// TimeRangeExp is a half-open time range condition on a column.
+type TimeRangeExp struct {
+	col     string
+	loc     *time.Location
+	bounds  func(now time.Time, loc *time.Location) (from, to time.Time)
+	openEnd bool // no upper bound (WithinLast)
+}
+
+// Today generates a condition matching the current calendar day:
+// col >= midnight AND col < next midnight.
+//
+// Example:
+//
+//	db.Builder().Select().From("orders").Where(relica.Today("created_at").In(berlin))
+func Today(col string) *TimeRangeExp {
+	return &TimeRangeExp{col: col, bounds: func(now time.Time, loc *time.Location) (time.Time, time.Time) {
+		start := startOfDay(now.In(loc))
+		return start, start.AddDate(0, 0, 1)
+	}}
+}
+
+// WithinLast generates a condition matching the last d up to now: col >= now - d.
+//
+// Example:
+//
+//	db.Builder().Select().From("events").Where(relica.WithinLast("created_at", 24*time.Hour))
+func WithinLast(col string, d time.Duration) *TimeRangeExp {
+	return &TimeRangeExp{col: col, openEnd: true, bounds: func(now time.Time, _ *time.Location) (time.Time, time.Time) {
+		return now.Add(-d), time.Time{}
+	}}
+}
+
+// DateBetween generates a condition matching the calendar days from through to,
+// both inclusive: col >= start of from AND col < start of the day after to.
+// Only the dates of from and to (in the expression's location) are used.
+// If to is before from, the condition matches nothing.
+//
+// Example:
+//
+//	relica.DateBetween("paid_at", monthStart, monthEnd)
+func DateBetween(col string, from, to time.Time) *TimeRangeExp {
+	return &TimeRangeExp{col: col, bounds: func(_ time.Time, loc *time.Location) (time.Time, time.Time) {
+		return startOfDay(from.In(loc)), startOfDay(to.In(loc)).AddDate(0, 0, 1)
+	}}
+}
+
+// In sets the location used for calendar day boundaries (default time.Local).
+func (e *TimeRangeExp) In(loc *time.Location) *TimeRangeExp {
+	e.loc = loc
+	return e
+}
+
+// Build implements the Expression interface.
+func (e *TimeRangeExp) Build(dialect dialects.Dialect) (string, []interface{}) {
+	loc := e.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	from, to := e.bounds(timeNow(), loc)
+	if !e.openEnd && !to.After(from) {
+		return "1 = 0", nil
+	}
+
+	col := quoteColumn(e.col, dialect)
+	if e.openEnd {
+		return col + " >= ?", []interface{}{from}
+	}
+	return col + " >= ? AND " + col + " < ?", []interface{}{from, to}
+}
+
+// startOfDay returns midnight of t's calendar day in t's location.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// WithUTCTimes converts time.Time, *time.Time and sql.NullTime query
+// parameters to UTC before execution, so times are bound consistently
+// regardless of the zone they were created in.
+//
+// Example:
+//
+//	db, _ := relica.Open("mysql", dsn, relica.WithUTCTimes())
+func WithUTCTimes() Option {
+	return optionFunc(func(db *DB) {
+		db.utcTimes = true
+	})
+}
+
+// utcTimeParams returns params with time values converted to UTC.
+// The input slice is not modified.
+func utcTimeParams(params []interface{}) []interface{} {
+	var out []interface{}
+	for i, p := range params {
+		var utc interface{}
+		switch v := p.(type) {
+		case time.Time:
+			utc = v.UTC()
+		case *time.Time:
+			if v != nil {
+				utc = v.UTC()
+			}
+		case sql.NullTime:
+			if v.Valid {
+				utc = sql.NullTime{Time: v.Time.UTC(), Valid: true}
+			}
+		}
+		if utc == nil {
+			continue
+		}
+		if out == nil {
+			out = append([]interface{}(nil), params...)
+		}
+		out[i] = utc
+	}
+	if out == nil {
+		return params
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func withTimeNow(t *testing.T, now time.Time) {
+	t.Helper()
+	prev := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = prev })
+}
+
+func TestTimeRangeExp_Build(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC) // 00:30 on March 11 in Berlin
+	withTimeNow(t, now)
+	pg := dialects.GetDialect("postgres")
+
+	sql, args := Today("o.created_at").In(time.UTC).Build(pg)
+	assert.Equal(t, `"o"."created_at" >= ? AND "o"."created_at" < ?`, sql)
+	assert.Equal(t, []interface{}{
+		time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+	}, args)
+
+	_, args = Today("created_at").In(berlin).Build(pg)
+	assert.Equal(t, []interface{}{
+		time.Date(2026, 3, 11, 0, 0, 0, 0, berlin),
+		time.Date(2026, 3, 12, 0, 0, 0, 0, berlin),
+	}, args)
+
+	sql, args = WithinLast("created_at", 24*time.Hour).Build(dialects.GetDialect("mysql"))
+	assert.Equal(t, "`created_at` >= ?", sql)
+	assert.Equal(t, []interface{}{now.Add(-24 * time.Hour)}, args)
+
+	from := time.Date(2026, 2, 1, 15, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC)
+	sql, args = DateBetween("paid_at", from, to).In(time.UTC).Build(pg)
+	assert.Equal(t, `"paid_at" >= ? AND "paid_at" < ?`, sql)
+	assert.Equal(t, []interface{}{
+		time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}, args)
+
+	sql, args = DateBetween("paid_at", to, from).In(time.UTC).Build(pg)
+	assert.Equal(t, "1 = 0", sql)
+	assert.Nil(t, args)
+}
+
+func TestTimeRangeExp_DST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	withTimeNow(t, time.Date(2026, 3, 29, 12, 0, 0, 0, berlin)) // 23-hour day
+
+	_, args := Today("created_at").In(berlin).Build(dialects.GetDialect("postgres"))
+	require.Len(t, args, 2)
+	assert.Equal(t, 23*time.Hour, args[1].(time.Time).Sub(args[0].(time.Time)))
+}
+
+func TestUTCTimeParams(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*3600)
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, loc)
+	var nilTime *time.Time
+
+	params := []interface{}{1, ts, &ts, nilTime, sql.NullTime{Time: ts, Valid: true}, sql.NullTime{}}
+	got := utcTimeParams(params)
+
+	assert.Equal(t, []interface{}{
+		1, ts.UTC(), ts.UTC(), nilTime, sql.NullTime{Time: ts.UTC(), Valid: true}, sql.NullTime{},
+	}, got)
+	assert.Equal(t, ts, params[1], "input must not be modified")
+
+	noTimes := []interface{}{1, "a"}
+	assert.Equal(t, noTimes, utcTimeParams(noTimes))
+}
+
+func TestWithUTCTimes(t *testing.T) {
+	var args []interface{}
+	db, err := Open("sqlite", ":memory:", WithUTCTimes(),
+		WithQueryHook(func(_ context.Context, e QueryEvent) { args = e.Args }))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("CREATE TABLE events (id INTEGER PRIMARY KEY, created_at DATETIME)").Execute()
+	require.NoError(t, err)
+
+	now := time.Now()
+	local := time.FixedZone("UTC-5", -5*3600)
+	for i, ts := range []time.Time{now.Add(-2 * time.Hour).In(local), now.Add(-48 * time.Hour)} {
+		_, err = db.Builder().Insert("events", map[string]interface{}{"id": i + 1, "created_at": ts}).Execute()
+		require.NoError(t, err)
+		require.Contains(t, args, ts.UTC())
+	}
+
+	var ids []int
+	err = db.Builder().Select("id").From("events").Where(WithinLast("created_at", 24*time.Hour)).Column(&ids)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, ids)
+}
@@ -0,0 +1,337 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Tracing
+// ============================================================================
+//
+// WithTracer creates a span for every query and transaction. Relica has no
+// tracing dependency: Tracer and Span are small interfaces that an adapter
+// implements on top of OpenTelemetry or another tracing library.
+//
+// Query spans are named after the query tag (see Tag) or, for untagged
+// queries, after the operation and table ("SELECT users"), so flame graphs
+// show what each query does. A transaction gets a "relica.transaction" span
+// from Begin to Commit or Rollback, and the spans of its queries are its
+// children, also when the query runs with another context.
+//
+// Tracing options trade detail for cost. TraceSampleRate keeps a fraction of
+// the query spans; queries that fail, or run longer than TraceSlowQueries,
+// are traced regardless. Their span is started once the outcome is known,
+// with the query's start time if the Tracer is a TimestampTracer.
+// TraceStatement replaces the SQL attribute with a fingerprint or drops it,
+// and TraceParams adds the parameters, masked like in logs.
+
+// Tracer starts spans for queries and transactions (see WithTracer).
+type Tracer interface {
+	// StartSpan starts a span named name as a child of the span in ctx, if
+	// any, and returns a context carrying the new span.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// TimestampTracer is a Tracer that can start a span at a past time. Query
+// spans created after the query ran (see TraceSampleRate) use it to cover
+// the whole query.
+type TimestampTracer interface {
+	Tracer
+	// StartSpanAt is StartSpan for a span that started at start.
+	StartSpanAt(ctx context.Context, name string, start time.Time) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute; values are strings, ints and int64s.
+	SetAttribute(key string, value interface{})
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// Span attribute keys, following the OpenTelemetry database conventions.
+const (
+	attrDBSystem       = "db.system"
+	attrDBStatement    = "db.statement"
+	attrDBParams       = "db.statement.params"
+	attrDBOperation    = "db.operation"
+	attrDBTable        = "db.sql.table"
+	attrDBRowsAffected = "db.rows_affected"
+	attrQueryTag       = "relica.tag"
+	attrTxOutcome      = "relica.tx.outcome"
+)
+
+// txSpanName is the name of transaction spans.
+const txSpanName = "relica.transaction"
+
+// WithTracer creates a span for every query and transaction with tracer.
+// Options sample query spans and control their attributes.
+//
+// Example (OpenTelemetry adapter):
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) StartSpan(ctx context.Context, name string) (context.Context, relica.Span) {
+//	    ctx, span := o.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//	    return ctx, otelSpan{span}
+//	}
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithTracer(otelTracer{otel.Tracer("relica")}))
+func WithTracer(tracer Tracer, opts ...TracingOption) Option {
+	return optionFunc(func(db *DB) {
+		if tracer == nil {
+			return
+		}
+		t := &tracing{tracer: tracer, defaultRate: 1}
+		for _, opt := range opts {
+			opt(t)
+		}
+		db.tracing = t
+	})
+}
+
+// TracingOption configures WithTracer.
+type TracingOption func(*tracing)
+
+// TraceStatementMode selects the statement attribute of query spans (see
+// TraceStatement).
+type TraceStatementMode int
+
+const (
+	// TraceStatementFull attaches the SQL as executed (the default).
+	TraceStatementFull TraceStatementMode = iota
+	// TraceStatementFingerprint attaches the SQL with literals replaced by ?
+	// and IN lists collapsed, so no values reach the tracing backend.
+	TraceStatementFingerprint
+	// TraceStatementNone attaches no SQL.
+	TraceStatementNone
+)
+
+// TraceSampleRate traces the given fraction (0 to 1) of the queries of
+// operations ("SELECT", "INSERT", ...), or of all queries if none are given.
+// Rates for named operations take precedence over the rate for all queries.
+// Failed queries, and queries slower than TraceSlowQueries, are always
+// traced. Transaction spans are not sampled.
+//
+// Example:
+//
+//	relica.WithTracer(tracer,
+//	    relica.TraceSampleRate(0.01, "SELECT"),
+//	    relica.TraceSlowQueries(500*time.Millisecond))
+func TraceSampleRate(rate float64, operations ...string) TracingOption {
+	rate = min(max(rate, 0), 1)
+	return func(t *tracing) {
+		if len(operations) == 0 {
+			t.defaultRate = rate
+			return
+		}
+		if t.rates == nil {
+			t.rates = make(map[string]float64, len(operations))
+		}
+		for _, op := range operations {
+			t.rates[strings.ToUpper(op)] = rate
+		}
+	}
+}
+
+// TraceSlowQueries traces queries running for at least threshold even if
+// TraceSampleRate left them out.
+func TraceSlowQueries(threshold time.Duration) TracingOption {
+	return func(t *tracing) {
+		t.slow = threshold
+	}
+}
+
+// TraceStatement selects how the SQL of a query is attached to its span.
+func TraceStatement(mode TraceStatementMode) TracingOption {
+	return func(t *tracing) {
+		t.statement = mode
+	}
+}
+
+// TraceParams attaches the query parameters to query spans as
+// db.statement.params. Parameters of statements mentioning sensitive fields
+// are masked as in logs (see WithSensitiveFields).
+func TraceParams() TracingOption {
+	return func(t *tracing) {
+		t.params = true
+	}
+}
+
+// tracing is the tracer of a DB, its options and the contexts of its open
+// transaction spans. It is shared by DB copies.
+type tracing struct {
+	tracer      Tracer
+	defaultRate float64            // sample rate of operations without their own rate
+	rates       map[string]float64 // sample rates by operation (TraceSampleRate)
+	slow        time.Duration      // queries at least this slow are always traced (0 = disabled)
+	statement   TraceStatementMode // statement attribute (TraceStatement)
+	params      bool               // attach masked parameters (TraceParams)
+	txs         sync.Map           // *sql.Tx -> context.Context carrying the transaction span
+}
+
+// sampled reports whether a query of operation op gets a span up front.
+func (t *tracing) sampled(op string) bool {
+	rate, ok := t.rates[op]
+	if !ok {
+		rate = t.defaultRate
+	}
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
+
+// querySpan is the span of one query execution; nil when tracing is disabled.
+// For a query left out by sampling, span is nil until end decides to keep it.
+type querySpan struct {
+	span   Span
+	query  *Query
+	parent context.Context // parent context of a span started by end
+	start  time.Time
+}
+
+// startSpan starts the span of a query execution and returns the context to
+// execute the query with. Queries in a traced transaction are children of
+// the transaction span.
+func (q *Query) startSpan() (context.Context, *querySpan) {
+	ctx := q.getContext()
+	if q.db == nil || q.db.tracing == nil {
+		return ctx, nil
+	}
+
+	parent, inTx := ctx, false
+	if q.tx != nil {
+		if txCtx, ok := q.db.tracing.txs.Load(q.tx); ok {
+			parent, inTx = txCtx.(context.Context), true
+		}
+	}
+	if !q.db.tracing.sampled(DetectOperation(q.sql)) {
+		return ctx, &querySpan{query: q, parent: parent, start: time.Now()}
+	}
+
+	spanCtx, span := q.db.tracing.tracer.StartSpan(parent, q.spanName())
+	if inTx {
+		// Keep the query's own deadline and values; only the parent span differs
+		spanCtx = ctx
+	}
+	q.setSpanAttributes(span)
+	return spanCtx, &querySpan{span: span}
+}
+
+// setSpanAttributes sets the attributes describing the query on span.
+func (q *Query) setSpanAttributes(span Span) {
+	t := q.db.tracing
+	span.SetAttribute(attrDBSystem, q.db.driverName)
+	switch t.statement {
+	case TraceStatementFull:
+		span.SetAttribute(attrDBStatement, q.sql)
+	case TraceStatementFingerprint:
+		span.SetAttribute(attrDBStatement, NormalizeSQL(q.sql))
+	}
+	if t.params && len(q.params) > 0 {
+		sanitizer := q.db.sanitizer
+		span.SetAttribute(attrDBParams, sanitizer.FormatParams(sanitizer.MaskParams(q.sql, q.params)))
+	}
+	span.SetAttribute(attrDBOperation, DetectOperation(q.sql))
+	if table := statementTable(q.sql); table != "" {
+		span.SetAttribute(attrDBTable, table)
+	}
+	if q.tag != "" {
+		span.SetAttribute(attrQueryTag, q.tag)
+	}
+}
+
+// end completes the span of a query execution with its outcome. A query left
+// out by sampling gets a span now if it failed or was slow.
+func (s *querySpan) end(err error, result sql.Result) {
+	if s == nil {
+		return
+	}
+	failed := err != nil && !errors.Is(err, sql.ErrNoRows)
+	if s.span == nil {
+		t := s.query.db.tracing
+		if !failed && (t.slow <= 0 || time.Since(s.start) < t.slow) {
+			return
+		}
+		if tt, ok := t.tracer.(TimestampTracer); ok {
+			_, s.span = tt.StartSpanAt(s.parent, s.query.spanName(), s.start)
+		} else {
+			_, s.span = t.tracer.StartSpan(s.parent, s.query.spanName())
+		}
+		s.query.setSpanAttributes(s.span)
+	}
+	if failed {
+		s.span.RecordError(err)
+	}
+	if result != nil {
+		if n, rerr := result.RowsAffected(); rerr == nil {
+			s.span.SetAttribute(attrDBRowsAffected, n)
+		}
+	}
+	s.span.End()
+}
+
+// spanName returns the span name of the query: its tag, else the operation
+// and table, else the operation.
+func (q *Query) spanName() string {
+	if q.tag != "" {
+		return q.tag
+	}
+	op := DetectOperation(q.sql)
+	if op == opUnknown {
+		if fields := strings.Fields(q.sql); len(fields) > 0 {
+			op = strings.ToUpper(fields[0])
+		}
+	}
+	if table := statementTable(q.sql); table != "" {
+		return op + " " + table
+	}
+	return op
+}
+
+// writeTableRegex captures the table of an INSERT, UPDATE or DELETE statement.
+var writeTableRegex = regexp.MustCompile("(?i)^\\s*(?:INSERT\\s+(?:OR\\s+\\w+\\s+)?INTO|REPLACE\\s+INTO|UPDATE|DELETE\\s+FROM)\\s+[\"`\\[]?([A-Za-z_][\\w]*)")
+
+// statementTable returns the table a statement reads or writes, or "".
+func statementTable(query string) string {
+	if m := writeTableRegex.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	if DetectOperation(query) == opSelect {
+		return firstTable(query)
+	}
+	return ""
+}
+
+// startTxSpan starts the span of a transaction and returns the context to
+// begin it with.
+func (db *DB) startTxSpan(ctx context.Context) (context.Context, Span) {
+	if db.tracing == nil {
+		return ctx, nil
+	}
+	ctx, span := db.tracing.tracer.StartSpan(ctx, txSpanName)
+	span.SetAttribute(attrDBSystem, db.driverName)
+	return ctx, span
+}
+
+// endSpan completes the transaction span, if any, with the outcome of Commit
+// or Rollback. Later calls do nothing.
+func (tx *Tx) endSpan(outcome string, err error) {
+	if tx.span == nil {
+		return
+	}
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		tx.span.RecordError(err)
+	}
+	tx.span.SetAttribute(attrTxOutcome, outcome)
+	tx.span.End()
+	tx.span = nil
+	tx.builder.db.tracing.txs.Delete(tx.tx)
+}
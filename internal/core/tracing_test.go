@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedSpan is a span captured by recordingTracer.
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordedSpan) RecordError(err error)                      { s.err = err }
+func (s *recordedSpan) End()                                       { s.ended = true }
+
+type spanKey struct{}
+
+// recordingTracer records spans and their parents, like an in-memory exporter.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	s := &recordedSpan{name: name, parent: parent, attrs: make(map[string]interface{})}
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (r *recordingTracer) last() *recordedSpan {
+	return r.spans[len(r.spans)-1]
+}
+
+func TestTracer_QuerySpans(t *testing.T) {
+	tracer := &recordingTracer{}
+	db, err := Open("sqlite", ":memory:", WithTracer(tracer))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	_, err = db.NewQuery("CREATE TABLE orders (id INTEGER PRIMARY KEY, total INTEGER)").Execute()
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE", tracer.last().name)
+
+	_, err = db.Builder().Insert("orders", map[string]interface{}{"id": 1, "total": 10}).Execute()
+	require.NoError(t, err)
+	span := tracer.last()
+	assert.Equal(t, "INSERT orders", span.name)
+	assert.Equal(t, "sqlite", span.attrs[attrDBSystem])
+	assert.Equal(t, "orders", span.attrs[attrDBTable])
+	assert.Equal(t, int64(1), span.attrs[attrDBRowsAffected])
+	assert.True(t, span.ended)
+
+	var totals []int
+	require.NoError(t, db.Builder().Select("total").From("orders").Tag("checkout.totals").Column(&totals))
+	span = tracer.last()
+	assert.Equal(t, "checkout.totals", span.name, "the tag names the span")
+	assert.Equal(t, "SELECT", span.attrs[attrDBOperation])
+	assert.Equal(t, "checkout.totals", span.attrs[attrQueryTag])
+
+	var order struct {
+		ID    int `db:"id"`
+		Total int `db:"total"`
+	}
+	err = db.Builder().Select().From("orders").Where("id = ?", 2).One(&order)
+	require.Error(t, err)
+	assert.Equal(t, "SELECT orders", tracer.last().name)
+	assert.NoError(t, tracer.last().err, "no rows is not a span error")
+
+	err = db.NewQuery("SELECT * FROM missing").Row(&order.ID)
+	require.Error(t, err)
+	assert.Error(t, tracer.last().err)
+}
+
+func TestTracer_TransactionSpans(t *testing.T) {
+	tracer := &recordingTracer{}
+	db, err := Open("sqlite", ":memory:", WithTracer(tracer))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+
+	err = db.Transactional(ctx, func(tx *Tx) error {
+		if _, err := tx.Builder().Insert("items", map[string]interface{}{"id": 1}).Execute(); err != nil {
+			return err
+		}
+		// A query run with an unrelated context still attaches to the transaction
+		var ids []int
+		return tx.Builder().Select("id").From("items").WithContext(context.Background()).Column(&ids)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, tracer.spans, 3)
+	txSpan := tracer.spans[0]
+	assert.Equal(t, "relica.transaction", txSpan.name)
+	assert.Equal(t, "commit", txSpan.attrs[attrTxOutcome])
+	assert.True(t, txSpan.ended)
+	for _, s := range tracer.spans[1:] {
+		assert.Same(t, txSpan, s.parent, s.name)
+	}
+
+	failure := errors.New("abort")
+	err = db.Transactional(ctx, func(tx *Tx) error { return failure })
+	require.ErrorIs(t, err, failure)
+	assert.Equal(t, "rollback", tracer.last().attrs[attrTxOutcome])
+
+	// Ended transactions are forgotten
+	count := 0
+	db.tracing.txs.Range(func(_, _ interface{}) bool { count++; return true })
+	assert.Zero(t, count)
+}
+
+// timestampTracer is a recordingTracer that records the start times passed
+// to StartSpanAt.
+type timestampTracer struct {
+	recordingTracer
+	starts []time.Time
+}
+
+func (r *timestampTracer) StartSpanAt(ctx context.Context, name string, start time.Time) (context.Context, Span) {
+	r.starts = append(r.starts, start)
+	return r.StartSpan(ctx, name)
+}
+
+func TestTracer_Sampling(t *testing.T) {
+	tracer := &timestampTracer{}
+	db, err := Open("sqlite", ":memory:", WithTracer(tracer,
+		TraceSampleRate(0, "select"),
+		TraceSlowQueries(time.Hour)))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	_, err = db.NewQuery("CREATE TABLE orders (id INTEGER PRIMARY KEY)").Execute()
+	require.NoError(t, err)
+	require.Len(t, tracer.spans, 1, "other operations keep the default rate")
+
+	var ids []int
+	require.NoError(t, db.Builder().Select("id").From("orders").Column(&ids))
+	assert.Len(t, tracer.spans, 1, "sampled out SELECT gets no span")
+	assert.Empty(t, tracer.starts)
+
+	err = db.NewQuery("SELECT id FROM missing").Column(&ids)
+	require.Error(t, err)
+	require.Len(t, tracer.spans, 2, "failed queries are always traced")
+	span := tracer.last()
+	assert.Equal(t, "SELECT missing", span.name)
+	assert.Error(t, span.err)
+	assert.True(t, span.ended)
+	require.Len(t, tracer.starts, 1, "late spans start at the query's start time")
+
+	db.tracing.slow = time.Nanosecond
+	require.NoError(t, db.Builder().Select("id").From("orders").Column(&ids))
+	assert.Len(t, tracer.spans, 3, "slow queries are always traced")
+}
+
+func TestTracer_StatementAndParams(t *testing.T) {
+	tracer := &recordingTracer{}
+	db, err := Open("sqlite", ":memory:", WithTracer(tracer,
+		TraceStatement(TraceStatementFingerprint), TraceParams()))
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+	_, err = db.NewQuery("CREATE TABLE users (id INTEGER, name TEXT, password TEXT)").Execute()
+	require.NoError(t, err)
+
+	var ids []int
+	require.NoError(t, db.NewQuery("SELECT id FROM users WHERE name = 'ann' AND id IN (?, ?)", 1, 2).Column(&ids))
+	span := tracer.last()
+	assert.Equal(t, "select id from users where name = ? and id in (?)", span.attrs[attrDBStatement])
+
+	_, err = db.Builder().Insert("users", map[string]interface{}{"id": 1, "name": "ann", "password": "hunter22"}).Execute()
+	require.NoError(t, err)
+	assert.NotContains(t, tracer.last().attrs[attrDBParams], "hunter22")
+
+	db.tracing.statement = TraceStatementNone
+	db.tracing.params = false
+	require.NoError(t, db.Builder().Select("id").From("users").Where("id = ?", 1).Column(&ids))
+	assert.NotContains(t, tracer.last().attrs, attrDBStatement)
+	assert.NotContains(t, tracer.last().attrs, attrDBParams)
+}
+
+func TestStatementTable(t *testing.T) {
+	assert.Equal(t, "users", statementTable(`INSERT INTO "users" ("name") VALUES (?)`))
+	assert.Equal(t, "users", statementTable("INSERT OR REPLACE INTO users (id) VALUES (1)"))
+	assert.Equal(t, "users", statementTable("UPDATE `users` SET name = ?"))
+	assert.Equal(t, "users", statementTable("DELETE FROM users WHERE id = 1"))
+	assert.Equal(t, "orders", statementTable("SELECT * FROM orders o JOIN users u ON u.id = o.user_id"))
+	assert.Empty(t, statementTable("VACUUM"))
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// TRUNCATE
+// ============================================================================
+//
+// TruncateQuery empties a table. The statement depends on the database:
+//
+//	PostgreSQL: TRUNCATE TABLE "t" [RESTART IDENTITY] [CASCADE]
+//	MySQL:      TRUNCATE TABLE `t` (always resets AUTO_INCREMENT)
+//	SQLite:     DELETE FROM "t" (has no TRUNCATE), and with RestartIdentity
+//	            the AUTOINCREMENT counter in sqlite_sequence is reset too
+//
+// Like the other builders it runs through the query validator, hooks and
+// change feed. Unlike them it is also written to the audit log (operation
+// TRUNCATE), as emptying a table is a maintenance operation worth tracing.
+
+// TruncateQuery represents a TRUNCATE query being built.
+type TruncateQuery struct {
+	builder         *QueryBuilder
+	table           string
+	cascade         bool
+	restartIdentity bool
+	ctx             context.Context // context for this specific query
+}
+
+// Truncate creates a query removing every row of table.
+//
+// Example:
+//
+//	err := db.Builder().Truncate("events").Cascade().RestartIdentity().Execute()
+func (qb *QueryBuilder) Truncate(table string) *TruncateQuery {
+	return &TruncateQuery{builder: qb, table: table}
+}
+
+// WithContext sets the context for this TRUNCATE query.
+// This overrides any context set on the QueryBuilder.
+func (tq *TruncateQuery) WithContext(ctx context.Context) *TruncateQuery {
+	tq.ctx = ctx
+	return tq
+}
+
+// Cascade also truncates the tables with foreign keys referencing the table
+// (PostgreSQL only; Execute returns ErrUnsupportedDialect elsewhere).
+func (tq *TruncateQuery) Cascade() *TruncateQuery {
+	tq.cascade = true
+	return tq
+}
+
+// RestartIdentity resets the sequences and auto-increment counters of the
+// table, so the next inserted row gets the first ID again. MySQL always does
+// this on TRUNCATE.
+func (tq *TruncateQuery) RestartIdentity() *TruncateQuery {
+	tq.restartIdentity = true
+	return tq
+}
+
+// Build constructs the Query object from TruncateQuery. On SQLite it is the
+// DELETE statement only; Execute also resets the AUTOINCREMENT counter.
+func (tq *TruncateQuery) Build() *Query {
+	// Context priority: query ctx > builder ctx > nil
+	ctx := tq.ctx
+	if ctx == nil {
+		ctx = tq.builder.ctx
+	}
+
+	db := tq.builder.db
+	q := &Query{
+		db:  db,
+		tx:  tq.builder.tx,
+		tag: tq.builder.tag,
+		ctx: ctx,
+	}
+	query, err := tq.buildStatement(db.dialect)
+	if err != nil {
+		q.prepErr = err
+		return q
+	}
+	q.sql = query
+	q.change = db.newChange(tq.table, opDelete, nil, []string{defaultChangeKey}, nil)
+	q.refs = db.newSchemaRefs(tq.table)
+	q.allRows = true
+	return db.guard(q)
+}
+
+// buildStatement constructs the TRUNCATE statement for dialect.
+func (tq *TruncateQuery) buildStatement(dialect dialects.Dialect) (string, error) {
+	if tq.table == "" {
+		return "", fmt.Errorf("relica: truncate: table name is empty")
+	}
+	table := dialect.QuoteIdentifier(tq.table)
+
+	switch dialect.(type) {
+	case *dialects.PostgresDialect:
+		query := "TRUNCATE TABLE " + table
+		if tq.restartIdentity {
+			query += " RESTART IDENTITY"
+		}
+		if tq.cascade {
+			query += " CASCADE"
+		}
+		return query, nil
+	case *dialects.SQLiteDialect:
+		if tq.cascade {
+			return "", fmt.Errorf("relica: truncate %s: Cascade is not supported by SQLite: %w", tq.table, ErrUnsupportedDialect)
+		}
+		return "DELETE FROM " + table, nil
+	default:
+		if tq.cascade {
+			return "", fmt.Errorf("relica: truncate %s: Cascade is not supported by MySQL: %w", tq.table, ErrUnsupportedDialect)
+		}
+		return "TRUNCATE TABLE " + table, nil
+	}
+}
+
+// Execute removes every row of the table.
+func (tq *TruncateQuery) Execute() error {
+	q := tq.Build()
+	if q.prepErr != nil {
+		return q.prepErr
+	}
+
+	start := time.Now()
+	result, err := q.Execute()
+	if err == nil && tq.restartIdentity {
+		if _, ok := q.db.dialect.(*dialects.SQLiteDialect); ok {
+			err = tq.resetSQLiteSequence(q)
+		}
+	}
+
+	if q.db.auditor != nil {
+		ctx := q.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		q.db.auditor.LogOperation(ctx, "TRUNCATE", q.sql, nil, result, err, time.Since(start))
+	}
+	return err
+}
+
+// resetSQLiteSequence removes the AUTOINCREMENT counter of the table. The
+// sqlite_sequence table only exists once an AUTOINCREMENT table was created;
+// without it there is no counter to reset.
+func (tq *TruncateQuery) resetSQLiteSequence(q *Query) error {
+	var n int
+	exists := &Query{
+		sql: "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_sequence'",
+		db:  q.db,
+		tx:  q.tx,
+		tag: q.tag,
+		ctx: q.ctx,
+	}
+	if err := exists.Row(&n); err != nil || n == 0 {
+		return err
+	}
+	reset := &Query{
+		sql:    "DELETE FROM sqlite_sequence WHERE name = ?",
+		params: []interface{}{tq.table},
+		db:     q.db,
+		tx:     q.tx,
+		tag:    q.tag,
+		ctx:    q.ctx,
+	}
+	_, err := reset.Execute()
+	return err
+}
+
+// ToSQL returns the SQL string without executing the query.
+//
+// Example:
+//
+//	sql, _ := db.Builder().Truncate("events").RestartIdentity().ToSQL()
+func (tq *TruncateQuery) ToSQL() (string, []interface{}) {
+	q := tq.Build()
+	return q.sql, q.params
+}
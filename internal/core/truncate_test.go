@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/coregx/relica/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateQuery_SQL(t *testing.T) {
+	tests := []struct {
+		dialect string
+		build   func(*TruncateQuery) *TruncateQuery
+		want    string
+	}{
+		{"postgres", func(tq *TruncateQuery) *TruncateQuery { return tq }, `TRUNCATE TABLE "events"`},
+		{"postgres", func(tq *TruncateQuery) *TruncateQuery { return tq.RestartIdentity() }, `TRUNCATE TABLE "events" RESTART IDENTITY`},
+		{"postgres", func(tq *TruncateQuery) *TruncateQuery { return tq.Cascade().RestartIdentity() }, `TRUNCATE TABLE "events" RESTART IDENTITY CASCADE`},
+		{"mysql", func(tq *TruncateQuery) *TruncateQuery { return tq.RestartIdentity() }, "TRUNCATE TABLE `events`"},
+		{"sqlite", func(tq *TruncateQuery) *TruncateQuery { return tq.RestartIdentity() }, `DELETE FROM "events"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			qb := &QueryBuilder{db: mockDB(tt.dialect)}
+			sql, params := tt.build(qb.Truncate("events")).ToSQL()
+			assert.Equal(t, tt.want, sql)
+			assert.Empty(t, params)
+		})
+	}
+}
+
+func TestTruncateQuery_Errors(t *testing.T) {
+	for _, dialect := range []string{"mysql", "sqlite"} {
+		qb := &QueryBuilder{db: mockDB(dialect)}
+		q := qb.Truncate("events").Cascade().Build()
+		assert.ErrorIs(t, q.prepErr, ErrUnsupportedDialect, dialect)
+	}
+
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	assert.ErrorContains(t, qb.Truncate("").Execute(), "table name is empty")
+}
+
+func TestTruncateQuery_Execute(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	var buf bytes.Buffer
+	db.auditor = security.NewAuditor(slog.New(slog.NewJSONHandler(&buf, nil)), security.AuditWrites)
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	require.NoError(t, err)
+	insert := func() int64 {
+		result, err := db.Builder().Insert("events", map[string]interface{}{"name": "e"}).Execute()
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+		return id
+	}
+	insert()
+	insert()
+
+	// Without RestartIdentity the AUTOINCREMENT counter continues
+	require.NoError(t, db.Builder().Truncate("events").Execute())
+	n, err := db.Builder().Select().From("events").Count()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, int64(3), insert())
+
+	require.NoError(t, db.Builder().Truncate("events").RestartIdentity().Execute())
+	assert.Equal(t, int64(1), insert())
+
+	assert.Contains(t, buf.String(), `"operation":"TRUNCATE"`)
+	assert.Contains(t, buf.String(), `"table":"\"events\""`)
+}
+
+func TestTruncateQuery_RestartIdentityWithoutSequence(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.sqlDB.SetMaxOpenConns(1)
+
+	// No AUTOINCREMENT table, so sqlite_sequence does not exist
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	assert.NoError(t, db.Builder().Truncate("events").RestartIdentity().Execute())
+}
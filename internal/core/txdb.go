@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync/atomic"
+)
+
+// boundTx is the transaction shared by a DB returned from Tx.DB and its copies.
+type boundTx struct {
+	tx  *sql.Tx
+	seq atomic.Uint64 // savepoint name counter
+}
+
+// DB returns a DB handle whose queries all run inside this transaction.
+// Begin and Transactional on the handle create savepoints, so code written
+// against *DB can be exercised inside a transaction that is rolled back
+// afterwards (see relicatest.NewRollbackDB). Close on the handle is a no-op;
+// the transaction is ended with tx.Commit or tx.Rollback as usual.
+//
+// A transaction uses a single connection: do not run queries on the handle
+// concurrently, and close Rows before issuing the next query.
+func (tx *Tx) DB() *DB {
+	v := *tx.builder.db
+	if v.bound == nil || v.bound.tx != tx.tx {
+		v.bound = &boundTx{tx: tx.tx}
+	}
+	v.healthChecker = nil
+	return &v
+}
+
+// boundSQLTx returns the transaction db is bound to, or nil.
+func (db *DB) boundSQLTx() *sql.Tx {
+	if db.bound == nil {
+		return nil
+	}
+	return db.bound.tx
+}
+
+// conn returns the connection raw queries execute on.
+func (db *DB) conn() sqlConn {
+	if db.bound != nil {
+		return db.bound.tx
+	}
+	if db.pinned != nil {
+		return db.pinned
+	}
+	return db.sqlDB
+}
+
+// beginSavepoint starts a nested transaction on a bound DB.
+func (db *DB) beginSavepoint(ctx context.Context) (*Tx, error) {
+	name := "relica_sp_" + strconv.FormatUint(db.bound.seq.Add(1), 10)
+	if _, err := db.bound.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &Tx{
+		tx:        db.bound.tx,
+		builder:   NewQueryBuilder(db, db.bound.tx),
+		ctx:       ctx,
+		savepoint: name,
+	}, nil
+}
+
+// endSavepoint runs the given savepoint statements (e.g. "RELEASE SAVEPOINT")
+// for the nested transaction. Like sql.Tx, it returns sql.ErrTxDone once the
+// savepoint has been released or rolled back.
+func (tx *Tx) endSavepoint(stmts ...string) error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	ctx := tx.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.tx.ExecContext(ctx, stmt+" "+tx.savepoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
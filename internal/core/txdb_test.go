@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestTx_DB(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open("sqlite", filepath.Join(t.TempDir(), "tx.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	tx, err := db.Begin(ctx)
+	require.NoError(t, err)
+	bound := tx.DB()
+
+	count := func() int {
+		var n int
+		require.NoError(t, bound.NewQuery("SELECT COUNT(*) FROM items").Row(&n))
+		return n
+	}
+
+	// Every entry point runs inside the transaction.
+	_, err = bound.Builder().Insert("items", map[string]interface{}{"name": "a"}).Execute()
+	require.NoError(t, err)
+	_, err = bound.ExecContext(ctx, "INSERT INTO items (name) VALUES (?)", "b")
+	require.NoError(t, err)
+	require.NoError(t, bound.Model(&struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}{Name: "c"}).Table("items").Insert())
+	var n int
+	require.NoError(t, bound.QueryRowContext(ctx, "SELECT COUNT(*) FROM items").Scan(&n))
+	assert.Equal(t, 3, n)
+
+	// Nested transactions map to savepoints.
+	nested, err := bound.Begin(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "relica_sp_1", nested.savepoint)
+	_, err = nested.NewQuery("DELETE FROM items").Execute()
+	require.NoError(t, err)
+	require.NoError(t, nested.Rollback())
+	assert.ErrorIs(t, nested.Commit(), sql.ErrTxDone)
+	assert.Equal(t, 3, count())
+
+	errBoom := errors.New("boom")
+	err = bound.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.Builder().Delete("items").Where("name = ?", "a").Execute()
+		require.NoError(t, err)
+		return tx.DB().Transactional(ctx, func(inner *Tx) error {
+			assert.Equal(t, "relica_sp_3", inner.savepoint)
+			return errBoom
+		})
+	})
+	require.ErrorIs(t, err, errBoom)
+	assert.Equal(t, 3, count())
+
+	require.NoError(t, bound.Transactional(ctx, func(tx *Tx) error {
+		_, err := tx.NewQuery("DELETE FROM items WHERE name = 'a'").Execute()
+		return err
+	}))
+	assert.Equal(t, 2, count())
+
+	// Closing the handle leaves the connection pool and transaction open.
+	require.NoError(t, bound.Close())
+	assert.Equal(t, 2, count())
+
+	require.NoError(t, tx.Rollback())
+	var total int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM items").Row(&total))
+	assert.Equal(t, 0, total)
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ============================================================================
+// Typed queries
+// ============================================================================
+//
+// TypedQuery is a generic layer over SelectQuery for the common "load rows of
+// one model" case. The table and the SELECT column list are derived from T
+// (using the scanner's cached struct metadata), and results are returned as
+// []T / T instead of being scanned into an interface{} destination, so
+// destination type mistakes are caught by the compiler.
+
+// TypedQuery is a SELECT query whose rows are scanned into values of type T.
+// T must be a struct type. Create one with NewTypedQuery (relica.Q / relica.QTx).
+type TypedQuery[T any] struct {
+	sq     *SelectQuery
+	mapper RowMapper[T] // builds results from raw rows (see Map)
+}
+
+// NewTypedQuery creates a typed query for T using the given builder.
+//
+// The table is T's TableName() if implemented, otherwise the pluralized
+// lowercase struct name (same rules as Model). The SELECT list contains the
+// columns mapped by T's fields; use Select to override it.
+func NewTypedQuery[T any](qb *QueryBuilder) *TypedQuery[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		sq := qb.Select()
+		sq.buildErr = fmt.Errorf("relica: typed query requires a struct type, got %s", typ)
+		return &TypedQuery[T]{sq: sq}
+	}
+
+	info, err := globalScanner.getStructInfo(typ)
+	if err != nil {
+		sq := qb.Select()
+		sq.buildErr = err
+		return &TypedQuery[T]{sq: sq}
+	}
+	cols := make([]string, len(info.fields))
+	for i, f := range info.fields {
+		cols[i] = f.column
+	}
+
+	return &TypedQuery[T]{sq: qb.Select(cols...).From(inferTableName(new(T)))}
+}
+
+// Select replaces the SELECT column list. Unselected fields keep their zero values.
+func (q *TypedQuery[T]) Select(cols ...string) *TypedQuery[T] {
+	q.sq.columns = cols
+	return q
+}
+
+// Table overrides the table name derived from T.
+func (q *TypedQuery[T]) Table(name string) *TypedQuery[T] {
+	q.sq = q.sq.From(name)
+	return q
+}
+
+// Where sets the WHERE condition. See SelectQuery.Where.
+func (q *TypedQuery[T]) Where(condition interface{}, params ...interface{}) *TypedQuery[T] {
+	q.sq = q.sq.Where(condition, params...)
+	return q
+}
+
+// AndWhere adds a condition combined with AND. See SelectQuery.AndWhere.
+func (q *TypedQuery[T]) AndWhere(condition interface{}, params ...interface{}) *TypedQuery[T] {
+	q.sq = q.sq.AndWhere(condition, params...)
+	return q
+}
+
+// OrWhere adds a condition combined with OR. See SelectQuery.OrWhere.
+func (q *TypedQuery[T]) OrWhere(condition interface{}, params ...interface{}) *TypedQuery[T] {
+	q.sq = q.sq.OrWhere(condition, params...)
+	return q
+}
+
+// OrderBy adds ORDER BY columns. See SelectQuery.OrderBy.
+func (q *TypedQuery[T]) OrderBy(columns ...string) *TypedQuery[T] {
+	q.sq = q.sq.OrderBy(columns...)
+	return q
+}
+
+// Limit sets the LIMIT clause.
+func (q *TypedQuery[T]) Limit(limit int64) *TypedQuery[T] {
+	q.sq = q.sq.Limit(limit)
+	return q
+}
+
+// Offset sets the OFFSET clause.
+func (q *TypedQuery[T]) Offset(offset int64) *TypedQuery[T] {
+	q.sq = q.sq.Offset(offset)
+	return q
+}
+
+// Distinct adds the DISTINCT keyword to the SELECT clause.
+func (q *TypedQuery[T]) Distinct() *TypedQuery[T] {
+	q.sq = q.sq.Distinct()
+	return q
+}
+
+// OnPool executes the query on the named connection pool (see DB.Pool).
+func (q *TypedQuery[T]) OnPool(name string) *TypedQuery[T] {
+	q.sq = q.sq.OnPool(name)
+	return q
+}
+
+// All returns all matching rows. An empty result is a nil slice, or an empty
+// slice with WithEmptySlices, and no error.
+func (q *TypedQuery[T]) All(ctx context.Context) ([]T, error) {
+	if q.mapper != nil {
+		return q.mapAll(ctx)
+	}
+	var rows []T
+	if err := q.sq.WithContext(ctx).All(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// One returns the first matching row, or sql.ErrNoRows if there is none.
+func (q *TypedQuery[T]) One(ctx context.Context) (T, error) {
+	if q.mapper != nil {
+		return q.mapOne(ctx)
+	}
+	var row T
+	if err := q.sq.WithContext(ctx).One(&row); err != nil {
+		var zero T
+		return zero, err
+	}
+	return row, nil
+}
+
+// Count returns the number of matching rows.
+func (q *TypedQuery[T]) Count(ctx context.Context) (int64, error) {
+	return q.sq.WithContext(ctx).Count()
+}
+
+// Exists reports whether at least one row matches.
+func (q *TypedQuery[T]) Exists(ctx context.Context) (bool, error) {
+	return q.sq.WithContext(ctx).Exists()
+}
+
+// ToSQL returns the generated SQL and parameters without executing the query.
+func (q *TypedQuery[T]) ToSQL() (string, []interface{}) {
+	return q.sq.ToSQL()
+}
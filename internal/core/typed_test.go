@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type typedAuthor struct {
+	ID     int64  `db:"id"`
+	Name   string `db:"name"`
+	Active bool   `db:"active"`
+	Note   string `db:"-"`
+}
+
+func (typedAuthor) TableName() string { return "authors" }
+
+func setupTypedTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.ExecContext(context.Background(),
+		`CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN, bio TEXT)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(),
+		`INSERT INTO authors (name, active, bio) VALUES ('ann', 1, 'x'), ('bob', 0, 'y'), ('cid', 1, 'z')`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestTypedQuery_SQL(t *testing.T) {
+	db := mockDB("postgres")
+
+	sql, params := NewTypedQuery[typedAuthor](db.Builder()).Where("active = ?", true).OrderBy("name").Limit(5).ToSQL()
+	assert.Equal(t, `SELECT "id", "name", "active" FROM "authors" WHERE active = $1 ORDER BY "name" LIMIT 5`, sql)
+	assert.Equal(t, []interface{}{true}, params)
+
+	sql, _ = NewTypedQuery[typedAuthor](db.Builder()).Select("id").Table("authors_archive").ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "authors_archive"`, sql)
+}
+
+func TestTypedQuery_All(t *testing.T) {
+	db := setupTypedTestDB(t)
+	ctx := context.Background()
+
+	authors, err := NewTypedQuery[typedAuthor](db.Builder()).Where(Eq("active", true)).OrderBy("id").All(ctx)
+	require.NoError(t, err)
+	require.Len(t, authors, 2)
+	assert.Equal(t, "ann", authors[0].Name)
+	assert.Equal(t, "cid", authors[1].Name)
+
+	none, err := NewTypedQuery[typedAuthor](db.Builder()).Where("id > ?", 100).All(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestTypedQuery_OneCountExists(t *testing.T) {
+	db := setupTypedTestDB(t)
+	ctx := context.Background()
+
+	author, err := NewTypedQuery[typedAuthor](db.Builder()).Where("name = ?", "bob").One(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), author.ID)
+	assert.False(t, author.Active)
+
+	_, err = NewTypedQuery[typedAuthor](db.Builder()).Where("name = ?", "zed").One(ctx)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	n, err := NewTypedQuery[typedAuthor](db.Builder()).Where("active = ?", true).Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	ok, err := NewTypedQuery[typedAuthor](db.Builder()).Where("name = ?", "cid").Exists(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestTypedQuery_Transaction(t *testing.T) {
+	db := setupTypedTestDB(t)
+	ctx := context.Background()
+
+	tx, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = tx.NewQuery(`INSERT INTO authors (name, active) VALUES ('dee', 1)`).Execute()
+	require.NoError(t, err)
+
+	n, err := NewTypedQuery[typedAuthor](tx.Builder()).Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+}
+
+func TestTypedQuery_NonStruct(t *testing.T) {
+	db := setupTypedTestDB(t)
+
+	_, err := NewTypedQuery[int](db.Builder()).All(context.Background())
+	assert.ErrorContains(t, err, "requires a struct type")
+}
@@ -359,3 +359,34 @@ func TestUpdateQuery_MultiColumnMultiWhere(t *testing.T) {
 	// Verify parameters (sorted SET columns, then WHERE params in order)
 	assert.Equal(t, []interface{}{10, 99.99, 50, "2025-01-15", "electronics", true, 150.00}, q.params)
 }
+
+// TestUpdateQuery_Returning tests UPDATE ... RETURNING for PostgreSQL.
+func TestUpdateQuery_Returning(t *testing.T) {
+	db := mockDB("postgres")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Update("users").
+		Set(map[string]interface{}{"status": 0}).
+		Where("id = ?", 1).
+		Returning("id", "u.email", "*").
+		Build()
+
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `UPDATE "users" SET "status" = $1 WHERE id = $2 RETURNING "id", "u"."email", *`, q.sql)
+	assert.Equal(t, []interface{}{0, 1}, q.params)
+}
+
+// TestDeleteQuery_Returning tests DELETE ... RETURNING for SQLite and MySQL rejection.
+func TestDeleteQuery_Returning(t *testing.T) {
+	db := mockDB("sqlite")
+	qb := &QueryBuilder{db: db}
+
+	q := qb.Delete("sessions").Where("expires_at < ?", 100).Returning("id").Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `DELETE FROM "sessions" WHERE expires_at < ? RETURNING "id"`, q.sql)
+
+	mysql := &QueryBuilder{db: mockDB("mysql")}
+	q = mysql.Delete("sessions").Returning("id").Build()
+	require.Error(t, q.prepErr)
+	assert.Contains(t, q.prepErr.Error(), "RETURNING is not supported by MySQL")
+}
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Advanced ON CONFLICT options
+// ============================================================================
+//
+// OnConstraint, DoUpdateExpr and Where extend an UPSERT beyond copying the
+// inserted values: the conflict target can be a named constraint, updated
+// columns can be set to expressions over the existing row and the EXCLUDED
+// (proposed) row, and the update can be made conditional.
+//
+// MySQL has no conflict target or WHERE for ON DUPLICATE KEY UPDATE:
+// OnConstraint is ignored like OnConflict, EXCLUDED.col is rendered as
+// VALUES(col), and Where fails with a build error. SQLite does not support
+// ON CONSTRAINT.
+
+// excludedRefRegex matches EXCLUDED.column references in DoUpdateExpr expressions.
+var excludedRefRegex = regexp.MustCompile("(?i)\\bEXCLUDED\\.(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\w+)")
+
+// upsertExpr is a DO UPDATE SET assignment with an expression value.
+type upsertExpr struct {
+	column string
+	expr   string
+	args   []interface{}
+}
+
+// OnConstraint specifies the conflict target by constraint name instead of
+// columns (PostgreSQL: ON CONFLICT ON CONSTRAINT name).
+//
+// Example:
+//
+//	db.Builder().Upsert("users", values).
+//	    OnConstraint("users_email_key").
+//	    DoUpdate("name")
+func (uq *UpsertQuery) OnConstraint(name string) *UpsertQuery {
+	if name == "" {
+		uq.buildErr = errors.New("relica: OnConstraint requires a constraint name")
+		return uq
+	}
+	uq.constraint = name
+	return uq
+}
+
+// DoUpdateExpr sets column to a SQL expression on conflict, instead of
+// copying the inserted value. The expression may reference the existing row
+// by table name and the proposed row as EXCLUDED, and may contain "?"
+// placeholders for args. It can be combined with DoUpdate; without DoUpdate
+// only the DoUpdateExpr columns are updated.
+//
+// Example:
+//
+//	db.Builder().Upsert("page_views", map[string]interface{}{"path": "/", "count": 1}).
+//	    OnConflict("path").
+//	    DoUpdateExpr("count", "page_views.count + EXCLUDED.count").
+//	    DoUpdateExpr("updated_at", "?", time.Now())
+//	// PostgreSQL: ... ON CONFLICT ("path") DO UPDATE SET "count" = page_views.count + EXCLUDED.count, "updated_at" = $3
+//	// MySQL:      ... ON DUPLICATE KEY UPDATE `count` = page_views.count + VALUES(`count`), `updated_at` = ?
+func (uq *UpsertQuery) DoUpdateExpr(column, expr string, args ...interface{}) *UpsertQuery {
+	if column == "" || strings.TrimSpace(expr) == "" {
+		uq.buildErr = errors.New("relica: DoUpdateExpr requires a column and an expression")
+		return uq
+	}
+	uq.updateExprs = append(uq.updateExprs, upsertExpr{column: column, expr: expr, args: args})
+	uq.doNothing = false
+	return uq
+}
+
+// Where adds a condition to the DO UPDATE action: a conflicting row is only
+// updated if the condition holds (PostgreSQL, SQLite). Accepts either a string
+// with placeholders or an Expression; multiple Where calls are combined with AND.
+//
+// Example:
+//
+//	db.Builder().Upsert("products", values).
+//	    OnConflict("sku").
+//	    DoUpdate("price", "updated_at").
+//	    Where("EXCLUDED.updated_at > products.updated_at")
+func (uq *UpsertQuery) Where(condition interface{}, params ...interface{}) *UpsertQuery {
+	switch cond := condition.(type) {
+	case string:
+		resolved, resolvedArgs, err := resolveNamedParams(cond, params)
+		if err != nil {
+			uq.buildErr = err
+			return uq
+		}
+		uq.where = append(uq.where, resolved)
+		uq.whereParams = append(uq.whereParams, resolvedArgs...)
+
+	case Expression:
+		if err := checkExpressionTree(cond, uq.builder.db.dialect); err != nil {
+			uq.buildErr = err
+			return uq
+		}
+		sqlStr, args := cond.Build(uq.builder.db.dialect)
+		if sqlStr != "" {
+			uq.where = append(uq.where, sqlStr)
+			uq.whereParams = append(uq.whereParams, args...)
+		}
+
+	default:
+		uq.buildErr = fmt.Errorf("relica: Where() expects string or Expression, got %T", condition)
+	}
+	return uq
+}
+
+// advancedConflict reports whether the conflict clause uses options the
+// dialects' UpsertSQL does not render.
+func (uq *UpsertQuery) advancedConflict() bool {
+	return uq.constraint != "" || len(uq.updateExprs) > 0 || len(uq.where) > 0
+}
+
+// buildConflictClause renders the conflict clause for the advanced options.
+// Its parameters are numbered after the paramCount INSERT parameters.
+func (uq *UpsertQuery) buildConflictClause(keys []string, paramCount int) (string, []interface{}, error) {
+	if uq.buildErr != nil {
+		return "", nil, uq.buildErr
+	}
+	dialect := uq.builder.db.dialect
+	_, mysql := dialect.(*dialects.MySQLDialect)
+	_, sqlite := dialect.(*dialects.SQLiteDialect)
+
+	switch {
+	case len(uq.where) > 0 && uq.doNothing:
+		return "", nil, errors.New("relica: Upsert Where requires DO UPDATE, not DoNothing")
+	case len(uq.where) > 0 && mysql:
+		return "", nil, errors.New("relica: Upsert Where is not supported on mysql (ON DUPLICATE KEY UPDATE has no WHERE)")
+	case uq.constraint != "" && sqlite:
+		return "", nil, errors.New("relica: OnConstraint is not supported on sqlite, use OnConflict(columns...)")
+	}
+
+	target := ""
+	if uq.constraint != "" {
+		target = " ON CONSTRAINT " + dialect.QuoteIdentifier(uq.constraint)
+	} else if len(uq.conflictColumns) > 0 {
+		quoted := make([]string, len(uq.conflictColumns))
+		for i, c := range uq.conflictColumns {
+			quoted[i] = dialect.QuoteIdentifier(c)
+		}
+		target = " (" + strings.Join(quoted, ", ") + ")"
+	}
+
+	if uq.doNothing {
+		if mysql {
+			return dialect.UpsertSQL(uq.table, nil, nil), nil, nil
+		}
+		return " ON CONFLICT" + target + " DO NOTHING", nil, nil
+	}
+	if target == "" && !mysql {
+		return "", nil, errors.New("relica: Upsert DO UPDATE requires OnConflict(columns...) or OnConstraint(name)")
+	}
+
+	// Columns copied from the proposed row, then expression assignments.
+	exprCols := make(map[string]bool, len(uq.updateExprs))
+	for _, e := range uq.updateExprs {
+		exprCols[e.column] = true
+	}
+	copyCols := uq.updateColumns
+	if len(copyCols) == 0 && len(uq.updateExprs) == 0 {
+		copyCols = filterKeys(keys, uq.conflictColumns)
+	}
+
+	sets := make([]string, 0, len(copyCols)+len(uq.updateExprs))
+	var params []interface{}
+	for _, col := range copyCols {
+		if exprCols[col] {
+			continue
+		}
+		quoted := dialect.QuoteIdentifier(col)
+		sets = append(sets, quoted+" = "+excludedColumn(quoted, dialect))
+	}
+	for _, e := range uq.updateExprs {
+		expr := e.expr
+		if mysql {
+			expr = mysqlExcludedRefs(expr, dialect)
+		}
+		expr = renumberFragment(expr, paramCount+len(params)+1, len(e.args), dialect)
+		sets = append(sets, dialect.QuoteIdentifier(e.column)+" = "+expr)
+		params = append(params, e.args...)
+	}
+	if len(sets) == 0 {
+		return "", nil, errors.New("relica: Upsert DO UPDATE has no columns to update")
+	}
+
+	if mysql {
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), params, nil
+	}
+
+	clause := " ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(sets, ", ")
+	if len(uq.where) > 0 {
+		where := strings.Join(uq.where, " AND ")
+		clause += " WHERE " + renumberFragment(where, paramCount+len(params)+1, len(uq.whereParams), dialect)
+		params = append(params, uq.whereParams...)
+	}
+	return clause, params, nil
+}
+
+// excludedColumn returns the reference to quoted in the proposed row.
+func excludedColumn(quoted string, dialect dialects.Dialect) string {
+	switch dialect.(type) {
+	case *dialects.MySQLDialect:
+		return "VALUES(" + quoted + ")"
+	case *dialects.SQLiteDialect:
+		return "excluded." + quoted
+	default:
+		return "EXCLUDED." + quoted
+	}
+}
+
+// mysqlExcludedRefs rewrites EXCLUDED.col references in expr as VALUES(col).
+func mysqlExcludedRefs(expr string, dialect dialects.Dialect) string {
+	return excludedRefRegex.ReplaceAllStringFunc(expr, func(ref string) string {
+		col := ref[len("EXCLUDED."):]
+		if strings.HasPrefix(col, `"`) {
+			col = dialect.QuoteIdentifier(strings.ReplaceAll(col[1:len(col)-1], `""`, `"`))
+		}
+		return "VALUES(" + col + ")"
+	})
+}
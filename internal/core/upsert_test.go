@@ -267,3 +267,109 @@ func TestFilterKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestUpsertQuery_OnConstraint_PostgreSQL(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Upsert("users", map[string]interface{}{"email": "a@example.com", "name": "Alice"}).
+		OnConstraint("users_email_key").
+		DoUpdate("name").
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "users" ("email", "name") VALUES ($1, $2) ON CONFLICT ON CONSTRAINT "users_email_key" DO UPDATE SET "name" = EXCLUDED."name"`, q.sql)
+
+	q = qb.Upsert("users", map[string]interface{}{"email": "a@example.com"}).
+		OnConstraint("users_email_key").
+		DoNothing().
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "users" ("email") VALUES ($1) ON CONFLICT ON CONSTRAINT "users_email_key" DO NOTHING`, q.sql)
+}
+
+func TestUpsertQuery_DoUpdateExprAndWhere_PostgreSQL(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+
+	q := qb.Upsert("counters", map[string]interface{}{"name": "hits", "count": 1, "note": "x"}).
+		OnConflict("name").
+		DoUpdate("note").
+		DoUpdateExpr("count", "counters.count + EXCLUDED.count + ?", 10).
+		Where("counters.locked = ?", false).
+		Where(LessThan("counters.count", 1000)).
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "counters" ("count", "name", "note") VALUES ($1, $2, $3) ON CONFLICT ("name") DO UPDATE SET "note" = EXCLUDED."note", "count" = counters.count + EXCLUDED.count + $4 WHERE counters.locked = $5 AND "counters"."count" < $6`, q.sql)
+	assert.Equal(t, []interface{}{1, "hits", "x", 10, false, 1000}, q.params)
+}
+
+func TestUpsertQuery_DoUpdateExprOnly_SQLite(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+
+	q := qb.Upsert("counters", map[string]interface{}{"name": "hits", "count": 1}).
+		OnConflict("name").
+		DoUpdateExpr("count", "counters.count + excluded.count").
+		Where("excluded.count > 0").
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, `INSERT INTO "counters" ("count", "name") VALUES (?, ?) ON CONFLICT ("name") DO UPDATE SET "count" = counters.count + excluded.count WHERE excluded.count > 0`, q.sql)
+}
+
+func TestUpsertQuery_DoUpdateExpr_MySQL(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("mysql")}
+
+	q := qb.Upsert("counters", map[string]interface{}{"name": "hits", "count": 1}).
+		OnConflict("name").
+		DoUpdateExpr("count", `counters.count + EXCLUDED.count + EXCLUDED."count" * ?`, 2).
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "INSERT INTO `counters` (`count`, `name`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `count` = counters.count + VALUES(count) + VALUES(`count`) * ?", q.sql)
+	assert.Equal(t, []interface{}{1, "hits", 2}, q.params)
+
+	// MySQL cannot target a constraint; like OnConflict it is ignored.
+	q = qb.Upsert("users", map[string]interface{}{"email": "a@example.com", "name": "Alice"}).
+		OnConstraint("users_email_key").
+		DoUpdate("name").
+		Build()
+	require.NoError(t, q.prepErr)
+	assert.Equal(t, "INSERT INTO `users` (`email`, `name`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)", q.sql)
+}
+
+func TestUpsertQuery_AdvancedConflictErrors(t *testing.T) {
+	values := map[string]interface{}{"id": 1, "name": "Alice"}
+
+	tests := []struct {
+		name    string
+		dialect string
+		build   func(qb *QueryBuilder) *UpsertQuery
+		want    string
+	}{
+		{"where on mysql", "mysql", func(qb *QueryBuilder) *UpsertQuery {
+			return qb.Upsert("users", values).OnConflict("id").DoUpdate("name").Where("name <> ?", "x")
+		}, "relica: Upsert Where is not supported on mysql (ON DUPLICATE KEY UPDATE has no WHERE)"},
+		{"constraint on sqlite", "sqlite", func(qb *QueryBuilder) *UpsertQuery {
+			return qb.Upsert("users", values).OnConstraint("users_pkey").DoUpdate("name")
+		}, "relica: OnConstraint is not supported on sqlite, use OnConflict(columns...)"},
+		{"where with do nothing", "postgres", func(qb *QueryBuilder) *UpsertQuery {
+			return qb.Upsert("users", values).OnConflict("id").Where("name <> ?", "x").DoNothing()
+		}, "relica: Upsert Where requires DO UPDATE, not DoNothing"},
+		{"no conflict target", "postgres", func(qb *QueryBuilder) *UpsertQuery {
+			return qb.Upsert("users", values).DoUpdateExpr("name", "'x'")
+		}, "relica: Upsert DO UPDATE requires OnConflict(columns...) or OnConstraint(name)"},
+		{"empty constraint", "postgres", func(qb *QueryBuilder) *UpsertQuery {
+			return qb.Upsert("users", values).OnConstraint("")
+		}, "relica: OnConstraint requires a constraint name"},
+		{"empty expression", "postgres", func(qb *QueryBuilder) *UpsertQuery {
+			return qb.Upsert("users", values).OnConflict("id").DoUpdateExpr("name", " ")
+		}, "relica: DoUpdateExpr requires a column and an expression"},
+		{"bad where", "postgres", func(qb *QueryBuilder) *UpsertQuery {
+			return qb.Upsert("users", values).OnConflict("id").Where(42)
+		}, "relica: Where() expects string or Expression, got int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.build(&QueryBuilder{db: mockDB(tt.dialect)}).Build()
+			assert.EqualError(t, q.prepErr, tt.want)
+			assert.Empty(t, q.sql)
+		})
+	}
+}
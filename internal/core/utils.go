@@ -3,6 +3,8 @@ package core
 import (
 	"reflect"
 	"strings"
+
+	"github.com/coregx/relica/internal/util"
 )
 
 // DefaultFieldMapFunc converts Go struct field names to snake_case database column names.
@@ -17,6 +19,40 @@ func DefaultFieldMapFunc(field string) string {
 	return strings.ToLower(string(result))
 }
 
+// NamingStrategy maps a Go struct field name to a database column name for
+// exported fields without a db tag.
+type NamingStrategy = util.NamingStrategy
+
+// Built-in naming strategies for SetNamingStrategy.
+var (
+	// FieldNameNaming uses the field name unchanged (the default).
+	FieldNameNaming NamingStrategy = func(field string) string { return field }
+
+	// SnakeCaseNaming maps "CreatedAt" to "created_at" and "UserID" to "user_id".
+	SnakeCaseNaming NamingStrategy = util.SnakeCase
+
+	// CamelCaseNaming maps "CreatedAt" to "createdAt" and "ID" to "id".
+	CamelCaseNaming NamingStrategy = util.CamelCase
+)
+
+// SetNamingStrategy sets how fields without a db tag map to columns, for
+// reads (scanning) and writes (Model, Insert/Update with structs) alike.
+// nil restores the default. The setting is global: call it once during
+// initialization, before running queries.
+//
+// Example:
+//
+//	relica.SetNamingStrategy(relica.SnakeCaseNaming)
+//
+//	type User struct {
+//	    UserID    int `relica:"pk"` // column user_id, primary key
+//	    CreatedAt time.Time         // column created_at
+//	}
+func SetNamingStrategy(ns NamingStrategy) {
+	util.SetNamingStrategy(ns)
+	globalScanner.reset()
+}
+
 // GetTableName extracts the database table name from a model struct or interface.
 func GetTableName(model interface{}) string {
 	if tm, ok := model.(TableModel); ok {
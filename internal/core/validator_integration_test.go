@@ -231,6 +231,69 @@ func TestDB_WithStrictValidator(t *testing.T) {
 	}
 }
 
+func TestDB_WithValidatorPolicies(t *testing.T) {
+	validator := security.NewValidator(
+		security.WithDenyLiterals(true),
+		security.WithDeniedTables("secrets"),
+		security.WithMaxParams(2),
+	)
+	db, err := NewDB("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.validator = validator
+	defer db.Close()
+
+	_, err = db.sqlDB.Exec("CREATE TABLE secrets (k TEXT)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM secrets WHERE k = ?", "a"); err == nil ||
+		!strings.Contains(err.Error(), "denied") {
+		t.Errorf("Expected denied table error, got %v", err)
+	}
+	if _, err := db.QueryContext(ctx, "SELECT 1 WHERE 'a' = 'a'"); err == nil {
+		t.Error("Expected literal in WHERE to be blocked")
+	}
+	if _, err := db.ExecContext(ctx, "SELECT ?, ?, ?", 1, 2, 3); err == nil {
+		t.Error("Expected parameter limit to be enforced")
+	}
+}
+
+func TestDB_WithReportOnlyValidator(t *testing.T) {
+	validator := security.NewValidator(
+		security.WithDeniedTables("secrets"),
+		security.WithReportOnly(true),
+	)
+	db, err := NewDB("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.validator = validator
+	defer db.Close()
+
+	_, err = db.sqlDB.Exec("CREATE TABLE secrets (k TEXT)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Violations are reported, not blocked
+	if _, err := db.ExecContext(context.Background(), "INSERT INTO secrets (k) VALUES (?)", "a"); err != nil {
+		t.Errorf("Expected report-only validator to allow query, got %v", err)
+	}
+
+	var count int
+	if err := db.sqlDB.QueryRow("SELECT COUNT(*) FROM secrets").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 row, got %d", count)
+	}
+}
+
 // Benchmark validation overhead
 func BenchmarkDB_ExecContext_WithValidator(b *testing.B) {
 	validator := security.NewValidator()
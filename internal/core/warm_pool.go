@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ============================================================================
+// Connection pool warm-up
+// ============================================================================
+//
+// database/sql opens connections lazily, so the first requests after a deploy
+// pay for TCP and TLS handshakes and authentication. WarmPool opens the
+// connections up front: it checks out n connections at the same time (so the
+// pool has to open n distinct ones), pings each and returns them to the pool,
+// where they stay idle until traffic arrives.
+
+// WarmPool pre-establishes n connections before the service starts taking
+// traffic. If queries are given, they are also prepared into the statement
+// cache as by WarmCache.
+//
+// The pool keeps at most WithMaxIdleConns idle connections (2 by default), so
+// set it to at least n or the extra connections are closed again right away;
+// WarmPool logs a warning when that happens. n is capped at WithMaxOpenConns.
+// Cached statements are prepared on one connection, and on each other
+// connection the first time it runs them.
+//
+// Returns the number of connections established and the first error.
+//
+// Example:
+//
+//	db, err := relica.Open("postgres", dsn, relica.WithMaxIdleConns(20))
+//	...
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if _, err := db.WarmPool(ctx, 20, "SELECT * FROM users WHERE id = $1"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (db *DB) WarmPool(ctx context.Context, n int, queries ...string) (int, error) {
+	if db.bound != nil || db.pinned != nil {
+		return 0, errors.New("relica: WarmPool() on a DB bound to a transaction or connection")
+	}
+	if db.poolErr != nil {
+		return 0, db.poolErr
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("relica: WarmPool() connection count must not be negative, got %d", n)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if limit := db.sqlDB.Stats().MaxOpenConnections; limit > 0 && n > limit {
+		// Holding more connections than the pool may open would block until
+		// ctx is done.
+		n = limit
+	}
+
+	if err := db.drain.enter(); err != nil {
+		return 0, err
+	}
+	defer db.drain.leave()
+
+	before := db.sqlDB.Stats().MaxIdleClosed
+	conns, err := checkoutConns(ctx, db.sqlDB, n)
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+	if err != nil {
+		return len(conns), classifyError(err)
+	}
+	if closed := db.sqlDB.Stats().MaxIdleClosed - before; closed > 0 {
+		db.logger.Warn("pool warm-up connections closed by the idle limit; raise WithMaxIdleConns",
+			"requested", n,
+			"closed", closed,
+		)
+	}
+
+	for _, query := range queries {
+		stmt, err := db.sqlDB.PrepareContext(ctx, query)
+		if err != nil {
+			return len(conns), err
+		}
+		db.stmtCache.Set(query, stmt)
+	}
+	return len(conns), nil
+}
+
+// checkoutConns checks out n connections of sqlDB concurrently and pings each.
+// It returns the connections it obtained, which the caller must close, and
+// the first error.
+func checkoutConns(ctx context.Context, sqlDB *sql.DB, n int) ([]*sql.Conn, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		conns    []*sql.Conn
+		firstErr error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := sqlDB.Conn(ctx)
+			if err == nil {
+				if err = conn.PingContext(ctx); err != nil {
+					_ = conn.Close()
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}()
+	}
+	wg.Wait()
+	return conns, firstErr
+}
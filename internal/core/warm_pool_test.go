@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_WarmPool(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithMaxIdleConns(4))
+	require.NoError(t, err)
+	defer db.Close()
+
+	n, err := db.WarmPool(context.Background(), 4, "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	stats := db.sqlDB.Stats()
+	assert.Equal(t, 4, stats.OpenConnections)
+	assert.Equal(t, 4, stats.Idle)
+	_, cached := db.stmtCache.Get("SELECT 1")
+	assert.True(t, cached)
+}
+
+func TestDB_WarmPool_CappedAtMaxOpenConns(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithMaxOpenConns(2), WithMaxIdleConns(2))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	n, err := db.WarmPool(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, db.sqlDB.Stats().Idle)
+}
+
+func TestDB_WarmPool_Errors(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.WarmPool(context.Background(), -1)
+	assert.ErrorContains(t, err, "must not be negative")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err := db.WarmPool(ctx, 2)
+	assert.Error(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,248 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// Long-running query watchdog
+// ============================================================================
+//
+// A query whose context has no deadline runs until the database finishes it,
+// which for a runaway report can be hours of a held connection and locks.
+// The watchdog bounds every query relica issues: with WithQueryWatchdog the
+// query runs under a deadline of at most the maximum runtime, so the driver
+// cancels it once it is exceeded. A caller deadline that is sooner still
+// applies.
+//
+// For queries already running, ActiveQueries lists the statements of all
+// sessions from pg_stat_activity or the MySQL process list, and CancelQuery
+// stops one of them by its process ID.
+
+// WithQueryWatchdog cancels queries issued through the DB that run longer
+// than maxRuntime, even when the caller's context has no deadline. A
+// canceled query fails with an error that is both ErrQueryWatchdog and
+// ErrTimeout, and is logged as a warning. It covers Query executions
+// (builders and NewQuery) and ExecContext; rows returned by QueryContext
+// belong to the caller and are not bounded. Zero or negative disables the
+// watchdog.
+//
+// PostgreSQL drivers cancel the statement on the server; MySQL drivers close
+// the connection, and the server stops the statement when it notices. Use
+// CancelQuery to stop a statement on the server explicitly.
+//
+// Example:
+//
+//	db, _ := relica.Open("postgres", dsn, relica.WithQueryWatchdog(30*time.Second))
+func WithQueryWatchdog(maxRuntime time.Duration) Option {
+	return optionFunc(func(db *DB) {
+		db.maxRuntime = maxRuntime
+	})
+}
+
+// watchdog returns ctx bounded by the maximum query runtime of db, and the
+// function to call with the error of the query once it has finished. The
+// finish function releases the deadline and marks an error caused by the
+// watchdog with ErrQueryWatchdog.
+func (db *DB) watchdog(ctx context.Context, query string) (context.Context, func(error) error) {
+	if db == nil || db.maxRuntime <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= db.maxRuntime {
+		return ctx, func(err error) error { return err }
+	}
+
+	wctx, cancel := context.WithTimeoutCause(ctx, db.maxRuntime, ErrQueryWatchdog)
+	return wctx, func(err error) error {
+		canceled := errors.Is(context.Cause(wctx), ErrQueryWatchdog)
+		cancel()
+		if err == nil || !canceled {
+			return err
+		}
+		db.logger.Warn("query canceled by watchdog",
+			"sql", query,
+			"max_runtime_ms", db.maxRuntime.Milliseconds(),
+			"database", db.driverName)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			// Drivers may report the cancellation with an error of their own
+			err = fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+		}
+		return fmt.Errorf("%w: %w", ErrQueryWatchdog, err)
+	}
+}
+
+// watch bounds ctx with the query watchdog of the DB of q (see DB.watchdog).
+func (q *Query) watch(ctx context.Context) (context.Context, func(error) error) {
+	if q.db == nil {
+		return ctx, func(err error) error { return err }
+	}
+	return q.db.watchdog(ctx, q.sql)
+}
+
+// ActiveQuery is a statement running in a database session.
+type ActiveQuery struct {
+	PID      int64         // process ID of the session, for CancelQuery
+	User     string        // database user of the session
+	Database string        // database the session is connected to
+	State    string        // session state, such as "active" or "idle in transaction"
+	Query    string        // current or, for idle transactions, last statement
+	Started  time.Time     // start of the statement
+	Duration time.Duration // time the statement has been running
+}
+
+// ActiveQueries returns the statements currently running in other sessions
+// of the database server, longest running first: non-idle sessions of
+// pg_stat_activity on PostgreSQL and the process list on MySQL. Sessions of
+// other users are only listed with the privilege to see them. SQLite returns
+// ErrUnsupportedDialect.
+//
+// Example:
+//
+//	queries, err := db.ActiveQueries(ctx)
+//	for _, q := range queries {
+//	    if q.Duration > 10*time.Minute {
+//	        err = db.CancelQuery(ctx, q.PID)
+//	    }
+//	}
+func (db *DB) ActiveQueries(ctx context.Context) ([]ActiveQuery, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var queries []ActiveQuery
+	var err error
+	switch db.dialect.(type) {
+	case *dialects.PostgresDialect:
+		queries, err = db.postgresActiveQueries(ctx)
+	case *dialects.MySQLDialect:
+		queries, err = db.mysqlActiveQueries(ctx)
+	default:
+		err = ErrUnsupportedDialect
+	}
+	if err != nil {
+		return nil, fmt.Errorf("relica: active queries: %w", err)
+	}
+	return queries, nil
+}
+
+// activeQueryRow is a row of the active query listings.
+type activeQueryRow struct {
+	PID      int64   `db:"pid"`
+	User     *string `db:"usr"`
+	Database *string `db:"dbname"`
+	State    *string `db:"state"`
+	Query    *string `db:"query"`
+	Seconds  float64 `db:"seconds"`
+}
+
+// activeQuery converts r, measured at now, to an ActiveQuery.
+func (r activeQueryRow) activeQuery(now time.Time) ActiveQuery {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	d := time.Duration(r.Seconds * float64(time.Second))
+	return ActiveQuery{
+		PID:      r.PID,
+		User:     deref(r.User),
+		Database: deref(r.Database),
+		State:    deref(r.State),
+		Query:    deref(r.Query),
+		Started:  now.Add(-d),
+		Duration: d,
+	}
+}
+
+// postgresActiveQueries lists the non-idle client sessions of
+// pg_stat_activity.
+func (db *DB) postgresActiveQueries(ctx context.Context) ([]ActiveQuery, error) {
+	var rows []activeQueryRow
+	err := db.NewQuery(`SELECT pid, usename AS usr, datname AS dbname, state, query,
+    EXTRACT(EPOCH FROM clock_timestamp() - query_start)::float8 AS seconds
+FROM pg_stat_activity
+WHERE pid <> pg_backend_pid() AND state <> $1 AND query_start IS NOT NULL
+ORDER BY query_start`, "idle").
+		WithContext(ctx).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+	return activeQueries(rows), nil
+}
+
+// mysqlActiveQueries lists the sessions of the process list that are not
+// sleeping.
+func (db *DB) mysqlActiveQueries(ctx context.Context) ([]ActiveQuery, error) {
+	var rows []activeQueryRow
+	err := db.NewQuery(`SELECT ID AS pid, USER AS usr, DB AS dbname, STATE AS state, INFO AS query,
+    TIME AS seconds
+FROM information_schema.PROCESSLIST
+WHERE ID <> CONNECTION_ID() AND COMMAND <> ?
+ORDER BY TIME DESC`, "Sleep").
+		WithContext(ctx).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+	return activeQueries(rows), nil
+}
+
+// activeQueries converts the rows of an active query listing.
+func activeQueries(rows []activeQueryRow) []ActiveQuery {
+	now := time.Now()
+	queries := make([]ActiveQuery, len(rows))
+	for i, r := range rows {
+		queries[i] = r.activeQuery(now)
+	}
+	return queries
+}
+
+// CancelQuery cancels the statement running in the session with process ID
+// pid (see ActiveQueries), leaving the session connected: pg_cancel_backend
+// on PostgreSQL and KILL QUERY on MySQL. It fails if no such session exists
+// or the user may not cancel it. The cancellation is written to the audit
+// log as a CANCEL operation. SQLite returns ErrUnsupportedDialect.
+//
+// Example:
+//
+//	err := db.CancelQuery(ctx, 4711)
+func (db *DB) CancelQuery(ctx context.Context, pid int64) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var stmt string
+	switch db.dialect.(type) {
+	case *dialects.PostgresDialect:
+		stmt = "SELECT pg_cancel_backend($1)"
+	case *dialects.MySQLDialect:
+		stmt = "KILL QUERY ?"
+	default:
+		return fmt.Errorf("relica: cancel query %d: %w", pid, ErrUnsupportedDialect)
+	}
+
+	start := time.Now()
+	q := db.NewQuery(stmt, pid).WithContext(ctx)
+	var err error
+	if _, ok := db.dialect.(*dialects.PostgresDialect); ok {
+		var canceled bool
+		if err = q.Row(&canceled); err == nil && !canceled {
+			err = errors.New("no query canceled")
+		}
+	} else {
+		_, err = q.Execute()
+	}
+
+	if db.auditor != nil {
+		db.auditor.LogOperation(ctx, "CANCEL", stmt, []interface{}{pid}, nil, err, time.Since(start))
+	}
+	if err != nil {
+		return fmt.Errorf("relica: cancel query %d: %w", pid, err)
+	}
+	return nil
+}
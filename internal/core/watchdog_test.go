@@ -0,0 +1,92 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowSQLiteQuery counts to a billion, which takes far longer than the tests
+// wait for it.
+const slowSQLiteQuery = `WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 1000000000)
+SELECT COUNT(*) FROM n`
+
+func TestQueryWatchdog_CancelsLongQuery(t *testing.T) {
+	var buf bytes.Buffer
+	db, err := Open("sqlite", ":memory:",
+		WithQueryWatchdog(50*time.Millisecond),
+		WithLogger(logger.NewSlogAdapter(slog.New(slog.NewTextHandler(&buf, nil)))))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var n int64
+	start := time.Now()
+	err = db.NewQuery(slowSQLiteQuery).Row(&n)
+	require.ErrorIs(t, err, ErrQueryWatchdog)
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Contains(t, buf.String(), "query canceled by watchdog")
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE t AS "+slowSQLiteQuery)
+	assert.ErrorIs(t, err, ErrQueryWatchdog)
+
+	// Fast queries are not affected
+	require.NoError(t, db.NewQuery("SELECT 1").Row(&n))
+	assert.Equal(t, int64(1), n)
+}
+
+func TestQueryWatchdog_CallerDeadline(t *testing.T) {
+	db, err := Open("sqlite", ":memory:", WithQueryWatchdog(time.Hour))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var n int64
+	err = db.NewQuery(slowSQLiteQuery).WithContext(ctx).Row(&n)
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.NotErrorIs(t, err, ErrQueryWatchdog, "the caller's deadline expired first")
+}
+
+func TestQueryWatchdog_Disabled(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	wctx, finish := db.watchdog(ctx, "SELECT 1")
+	assert.Equal(t, ctx, wctx)
+	_, ok := wctx.Deadline()
+	assert.False(t, ok)
+	assert.NoError(t, finish(nil))
+}
+
+func TestActiveQueries_Unsupported(t *testing.T) {
+	db, err := Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ActiveQueries(context.Background())
+	assert.ErrorIs(t, err, ErrUnsupportedDialect)
+	err = db.CancelQuery(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrUnsupportedDialect)
+}
+
+func TestActiveQueryRow(t *testing.T) {
+	user, state := "app", "active"
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	q := activeQueryRow{PID: 42, User: &user, State: &state, Seconds: 1.5}.activeQuery(now)
+	assert.Equal(t, ActiveQuery{
+		PID:      42,
+		User:     "app",
+		State:    "active",
+		Started:  now.Add(-1500 * time.Millisecond),
+		Duration: 1500 * time.Millisecond,
+	}, q)
+}
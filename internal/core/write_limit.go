@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/coregx/relica/internal/dialects"
+)
+
+// ============================================================================
+// LIMIT and ORDER BY on UPDATE and DELETE
+// ============================================================================
+//
+// MySQL accepts ORDER BY and LIMIT on single-table UPDATE and DELETE. The
+// other dialects do not (SQLite only when compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT, which the common drivers are not), so
+// the rows are chosen by a subquery on the row's physical address instead:
+// ctid on PostgreSQL, rowid on SQLite.
+//
+//	DELETE FROM "sessions" WHERE ctid = ANY(ARRAY(
+//	    SELECT ctid FROM "sessions" WHERE expires_at < $1 ORDER BY "expires_at" LIMIT 10000))
+//
+// A ctid is only unique within one table, so the emulation does not support
+// partitioned PostgreSQL tables; SQLite WITHOUT ROWID tables have no rowid.
+
+// OrderBy sets the order in which rows are updated, which decides the rows
+// a Limit keeps. Terms are formatted like SelectQuery.OrderBy. Without Limit,
+// only MySQL accepts it.
+func (uq *UpdateQuery) OrderBy(columns ...string) *UpdateQuery {
+	uq.orderBy = append(uq.orderBy, columns...)
+	return uq
+}
+
+// Limit updates at most limit rows, the first ones by OrderBy if set.
+//
+// Example:
+//
+//	db.Update("jobs").Set(map[string]interface{}{"state": "queued"}).
+//	    Where("state = ?", "new").OrderBy("created_at").Limit(500).Execute()
+func (uq *UpdateQuery) Limit(limit int64) *UpdateQuery {
+	uq.limitValue = &limit
+	return uq
+}
+
+// OrderBy sets the order in which rows are deleted, which decides the rows
+// a Limit keeps. Terms are formatted like SelectQuery.OrderBy. Without Limit,
+// only MySQL accepts it.
+func (dq *DeleteQuery) OrderBy(columns ...string) *DeleteQuery {
+	dq.orderBy = append(dq.orderBy, columns...)
+	return dq
+}
+
+// Limit deletes at most limit rows, the first ones by OrderBy if set. Run it
+// in a loop until no rows are affected to clean up a large table in batches.
+//
+// Example:
+//
+//	for {
+//	    res, err := db.Delete("sessions").Where("expires_at < ?", now).
+//	        OrderBy("expires_at").Limit(10000).Execute()
+//	    ...
+//	}
+func (dq *DeleteQuery) Limit(limit int64) *DeleteQuery {
+	dq.limitValue = &limit
+	return dq
+}
+
+// limitedWrite returns the WHERE clause and the trailing clauses of an UPDATE
+// or DELETE (stmt) of table restricted by orderBy and limit. whereClause is
+// the statement's own WHERE clause with placeholders already numbered.
+func limitedWrite(stmt, table, whereClause string, orderBy []string, limit *int64, dialect dialects.Dialect) (string, string, error) {
+	if len(orderBy) == 0 && limit == nil {
+		return whereClause, "", nil
+	}
+
+	orderClause, err := formatOrderByTerms(orderBy, dialect)
+	if err != nil {
+		return "", "", err
+	}
+	if limit != nil && *limit < 0 {
+		return "", "", fmt.Errorf("relica: %s Limit() must not be negative, got %d", stmt, *limit)
+	}
+	limitClause := limitOffsetSQL(limit, nil)
+
+	if _, ok := dialect.(*dialects.MySQLDialect); ok {
+		return whereClause, orderClause + limitClause, nil
+	}
+	if limit == nil {
+		return "", "", fmt.Errorf("relica: %s OrderBy() requires Limit() except on MySQL", stmt)
+	}
+
+	rows := " FROM " + dialect.QuoteIdentifier(table) + whereClause + orderClause + limitClause
+	if _, ok := dialect.(*dialects.PostgresDialect); ok {
+		return " WHERE ctid = ANY(ARRAY(SELECT ctid" + rows + "))", "", nil
+	}
+	return " WHERE rowid IN (SELECT rowid" + rows + ")", "", nil
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteLimit_SQL(t *testing.T) {
+	tests := map[string]string{
+		"postgres": `DELETE FROM "sessions" WHERE ctid = ANY(ARRAY(SELECT ctid FROM "sessions" WHERE expires_at < $1 ORDER BY "expires_at" LIMIT 100)) RETURNING "id"`,
+		"sqlite":   `DELETE FROM "sessions" WHERE rowid IN (SELECT rowid FROM "sessions" WHERE expires_at < ? ORDER BY "expires_at" LIMIT 100) RETURNING "id"`,
+	}
+	for dialect, want := range tests {
+		qb := &QueryBuilder{db: mockDB(dialect)}
+		q := qb.Delete("sessions").Where("expires_at < ?", 5).OrderBy("expires_at").Limit(100).Returning("id").Build()
+		require.NoError(t, q.prepErr, dialect)
+		assert.Equal(t, want, q.SQL(), dialect)
+		assert.Equal(t, []interface{}{5}, q.Params(), dialect)
+	}
+
+	qb := &QueryBuilder{db: mockDB("mysql")}
+	assert.Equal(t, "DELETE FROM `sessions` WHERE expires_at < ? ORDER BY `expires_at` LIMIT 100",
+		qb.Delete("sessions").Where("expires_at < ?", 5).OrderBy("expires_at").Limit(100).Build().SQL())
+	assert.Equal(t, "DELETE FROM `sessions` WHERE id > ? ORDER BY `id` DESC",
+		qb.Delete("sessions").Where("id > ?", 5).OrderBy("id DESC").Build().SQL())
+}
+
+func TestUpdateLimit_SQL(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("postgres")}
+	q := qb.Update("jobs").Set(map[string]interface{}{"state": "queued"}).
+		Where("state = ?", "new").OrderBy("created_at").Limit(10).Build()
+	assert.Equal(t, `UPDATE "jobs" SET "state" = $1 WHERE ctid = ANY(ARRAY(SELECT ctid FROM "jobs" WHERE state = $2 ORDER BY "created_at" LIMIT 10))`, q.SQL())
+	assert.Equal(t, []interface{}{"queued", "new"}, q.Params())
+
+	qb = &QueryBuilder{db: mockDB("mysql")}
+	assert.Equal(t, "UPDATE `jobs` SET `state` = ? WHERE state = ? LIMIT 10",
+		qb.Update("jobs").Set(map[string]interface{}{"state": "queued"}).Where("state = ?", "new").Limit(10).Build().SQL())
+}
+
+func TestWriteLimit_Errors(t *testing.T) {
+	qb := &QueryBuilder{db: mockDB("sqlite")}
+	_, err := qb.Delete("t").Where("a = 1").OrderBy("a").Execute()
+	assert.ErrorContains(t, err, "DELETE OrderBy() requires Limit() except on MySQL")
+	_, err = qb.Update("t").Set(map[string]interface{}{"a": 1}).Where("a = 1").Limit(-1).Execute()
+	assert.ErrorContains(t, err, "UPDATE Limit() must not be negative")
+	_, err = qb.Delete("t").Where("a = 1").OrderBy("a; DROP TABLE t").Limit(1).Execute()
+	assert.ErrorContains(t, err, "statement separator")
+}
+
+func TestWriteLimit_SQLite(t *testing.T) {
+	db := setupNullColumnDB(t)
+	ctx := context.Background()
+	_, err := db.ExecContext(ctx, `INSERT INTO players (id, name, score) VALUES (4, 'dee', 40), (5, 'eve', 50)`)
+	require.NoError(t, err)
+
+	res, err := db.Builder().Update("players").Set(map[string]interface{}{"score": 0}).
+		Where("score IS NOT NULL").OrderBy("score DESC").Limit(2).Execute()
+	require.NoError(t, err)
+	n, err := res.(sql.Result).RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	res, err = db.Builder().Delete("players").Where("id > ?", 0).OrderBy("id").Limit(2).Execute()
+	require.NoError(t, err)
+	n, err = res.(sql.Result).RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	var scores []*int
+	require.NoError(t, db.Builder().Select("score").From("players").OrderBy("id").Column(&scores))
+	require.Len(t, scores, 3)
+	assert.Equal(t, 30, *scores[0])
+	assert.Equal(t, 0, *scores[1])
+	assert.Equal(t, 0, *scores[2])
+}
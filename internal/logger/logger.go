@@ -64,3 +64,43 @@ func (a *SlogAdapter) Warn(msg string, args ...any) {
 func (a *SlogAdapter) Error(msg string, args ...any) {
 	a.logger.Error(msg, args...)
 }
+
+// LevelFilter drops messages below a minimum level before passing them on.
+// The level is a *slog.LevelVar, so it can be changed while logging.
+type LevelFilter struct {
+	next  Logger
+	level *slog.LevelVar
+}
+
+// NewLevelFilter creates a logger passing messages at or above level to next.
+func NewLevelFilter(next Logger, level *slog.LevelVar) *LevelFilter {
+	return &LevelFilter{next: next, level: level}
+}
+
+// Debug logs a debug-level message if debug messages are enabled.
+func (f *LevelFilter) Debug(msg string, args ...any) {
+	if f.level.Level() <= slog.LevelDebug {
+		f.next.Debug(msg, args...)
+	}
+}
+
+// Info logs an info-level message if info messages are enabled.
+func (f *LevelFilter) Info(msg string, args ...any) {
+	if f.level.Level() <= slog.LevelInfo {
+		f.next.Info(msg, args...)
+	}
+}
+
+// Warn logs a warning-level message if warnings are enabled.
+func (f *LevelFilter) Warn(msg string, args ...any) {
+	if f.level.Level() <= slog.LevelWarn {
+		f.next.Warn(msg, args...)
+	}
+}
+
+// Error logs an error-level message if errors are enabled.
+func (f *LevelFilter) Error(msg string, args ...any) {
+	if f.level.Level() <= slog.LevelError {
+		f.next.Error(msg, args...)
+	}
+}
@@ -168,3 +168,25 @@ func BenchmarkSlogAdapter(b *testing.B) {
 			"rows", 100)
 	}
 }
+
+func TestLevelFilter(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	l := NewLevelFilter(NewSlogAdapter(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))), level)
+
+	l.Debug("debug message")
+	l.Info("info message")
+	l.Warn("warn message")
+	l.Error("error message")
+	assert.NotContains(t, buf.String(), "debug message")
+	assert.NotContains(t, buf.String(), "info message")
+	assert.Contains(t, buf.String(), "warn message")
+	assert.Contains(t, buf.String(), "error message")
+
+	// Level changes apply to subsequent messages
+	buf.Reset()
+	level.Set(slog.LevelDebug)
+	l.Debug("debug message")
+	assert.Contains(t, buf.String(), "debug message")
+}
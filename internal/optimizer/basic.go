@@ -121,6 +121,11 @@ func (o *BasicOptimizer) Suggest(analysis *Analysis) []Suggestion {
 func categorizeIndexRecommendation(idx IndexRecommendation) SuggestionType {
 	reason := strings.ToLower(idx.Reason)
 
+	// Partial index
+	if idx.Where != "" {
+		return SuggestionPartialIndex
+	}
+
 	// Covering index
 	if strings.Contains(reason, "covering index") {
 		return SuggestionCoveringIndex
@@ -152,7 +157,7 @@ func determineSeverity(suggestionType SuggestionType) Severity {
 		return SeverityWarning
 	case SuggestionCompositeIndex, SuggestionJoinOptimize:
 		return SeverityWarning
-	case SuggestionCoveringIndex:
+	case SuggestionCoveringIndex, SuggestionPartialIndex:
 		return SeverityInfo
 	default:
 		return SeverityWarning
@@ -166,6 +171,8 @@ func suggestionTypeMessage(suggestionType SuggestionType) string {
 		return "Composite index recommended"
 	case SuggestionCoveringIndex:
 		return "Covering index recommended"
+	case SuggestionPartialIndex:
+		return "Partial index recommended"
 	case SuggestionJoinOptimize:
 		return "Index recommended"
 	case SuggestionFunctionIndex:
@@ -177,7 +184,7 @@ func suggestionTypeMessage(suggestionType SuggestionType) string {
 
 // detectMissingIndexes analyzes the query to recommend indexes.
 // Phase 2: Enhanced with composite index, JOIN, and covering index analysis.
-func (o *BasicOptimizer) detectMissingIndexes(query string, _ *analyzer.QueryPlan) []IndexRecommendation {
+func (o *BasicOptimizer) detectMissingIndexes(query string, plan *analyzer.QueryPlan) []IndexRecommendation {
 	var recommendations []IndexRecommendation
 
 	// Extract table name from query
@@ -213,6 +220,20 @@ func (o *BasicOptimizer) detectMissingIndexes(query string, _ *analyzer.QueryPla
 		})
 	}
 
+	// Analyze partial index opportunities (PostgreSQL and SQLite only)
+	if plan != nil && (plan.Database == dialectPostgres || plan.Database == dialectSQLite) {
+		partialAnalysis := AnalyzePartialIndex(query)
+		if partialAnalysis.Recommended {
+			recommendations = append(recommendations, IndexRecommendation{
+				Table:   table,
+				Columns: partialAnalysis.Columns,
+				Type:    indexTypeBTree,
+				Reason:  fmt.Sprintf("Partial index: %s", partialAnalysis.Benefit),
+				Where:   partialAnalysis.Predicate,
+			})
+		}
+	}
+
 	return recommendations
 }
 
@@ -370,11 +391,15 @@ func (o *BasicOptimizer) analyzeJoinIndexes(join string) []IndexRecommendation {
 	return recommendations
 }
 
-// generateIndexSQL generates a CREATE INDEX statement for the recommendation.
+// generateIndexSQL generates a CREATE INDEX statement for the recommendation,
+// with a WHERE clause for partial indexes.
 func generateIndexSQL(idx IndexRecommendation) string {
 	indexName := fmt.Sprintf("idx_%s_%s", idx.Table, strings.Join(idx.Columns, "_"))
 	columnList := strings.Join(idx.Columns, ", ")
 
+	if idx.Where != "" {
+		return fmt.Sprintf("CREATE INDEX %s_partial ON %s(%s) WHERE %s;", indexName, idx.Table, columnList, idx.Where)
+	}
 	return fmt.Sprintf("CREATE INDEX %s ON %s(%s);", indexName, idx.Table, columnList)
 }
 
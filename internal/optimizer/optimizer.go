@@ -80,6 +80,9 @@ const (
 	// SuggestionJoinOptimize indicates JOIN optimization opportunities
 	SuggestionJoinOptimize SuggestionType = "join_optimize"
 
+	// SuggestionPartialIndex indicates a partial (filtered) index would serve a constant predicate
+	SuggestionPartialIndex SuggestionType = "partial_index"
+
 	// SuggestionFunctionIndex indicates a function-based index could help with function calls in WHERE
 	SuggestionFunctionIndex SuggestionType = "function_index"
 
@@ -143,10 +146,14 @@ type IndexRecommendation struct {
 
 	// Reason explains why this index is recommended
 	Reason string
+
+	// Where is the predicate of a partial index (e.g. "status = 'pending'");
+	// empty for an index over all rows
+	Where string
 }
 
 // IndexName returns the suggested name for this index.
-// Format: idx_<table>_<column1>_<column2>_...
+// Format: idx_<table>_<column1>_<column2>_..., with a _partial suffix for partial indexes.
 func (i IndexRecommendation) IndexName() string {
 	if len(i.Columns) == 0 {
 		return fmt.Sprintf("idx_%s", i.Table)
@@ -158,5 +165,8 @@ func (i IndexRecommendation) IndexName() string {
 		}
 		columnsPart += col
 	}
+	if i.Where != "" {
+		columnsPart += "_partial"
+	}
 	return fmt.Sprintf("idx_%s_%s", i.Table, columnsPart)
 }
@@ -0,0 +1,123 @@
+package optimizer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PartialIndexAnalysis represents the result of partial index analysis.
+// A partial (filtered) index only contains the rows matching its predicate, so a
+// query that always filters on the same constant, such as status = 'pending',
+// can use an index that is a fraction of the size of a full one.
+type PartialIndexAnalysis struct {
+	// Recommended indicates if a partial index would be beneficial
+	Recommended bool
+
+	// Columns are the columns to index within the filtered rows
+	Columns []string
+
+	// Predicate is the WHERE clause of the index, e.g. "status = 'pending'"
+	Predicate string
+
+	// Benefit explains why a partial index would help
+	Benefit string
+}
+
+var (
+	// whereKeywordRegex finds the WHERE keyword of a query.
+	whereKeywordRegex = regexp.MustCompile(`(?i)\swhere\s`)
+
+	// whereEndRegex finds the end of a WHERE clause.
+	whereEndRegex = regexp.MustCompile(`(?i)\s(order\s+by|group\s+by|limit|having)\s|;`)
+
+	// constantEqualityRegex matches column = constant, where the constant is a
+	// string, number or boolean literal. Qualified columns (t.col) do not match.
+	constantEqualityRegex = regexp.MustCompile(`(?i)(?:^|[\s(])([a-z_][a-z0-9_]*)\s*=\s*('(?:[^']|'')*'|true|false|-?\d+(?:\.\d+)?)(?:$|[\s)])`)
+
+	// nullCheckRegex matches column IS [NOT] NULL.
+	nullCheckRegex = regexp.MustCompile(`(?i)(?:^|[\s(])([a-z_][a-z0-9_]*)\s+is\s+(not\s+)?null\b`)
+
+	// stringLiteralRegex matches SQL string literals.
+	stringLiteralRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+	// orderByColumnRegex captures the first ORDER BY column.
+	orderByColumnRegex = regexp.MustCompile(`(?i)\border\s+by\s+(?:[a-z_][a-z0-9_]*\.)?([a-z_][a-z0-9_]*)`)
+
+	// orJoinRegex detects OR logic and JOINs, which rule out a partial index.
+	orJoinRegex = regexp.MustCompile(`(?i)\s(or|join)\s`)
+)
+
+// AnalyzePartialIndex checks if a partial index would improve query performance.
+// Constant predicates (status = 'pending', is_active = true, deleted_at IS NULL)
+// usually select a small, frequently queried subset of a table: they become
+// the index predicate, and the remaining filter and ORDER BY columns are indexed.
+//
+// Only single-table queries whose WHERE clause is a conjunction qualify, as the
+// database uses a partial index only when the query implies its predicate.
+// Partial indexes exist in PostgreSQL and SQLite, not in MySQL.
+func AnalyzePartialIndex(query string) *PartialIndexAnalysis {
+	loc := whereKeywordRegex.FindStringIndex(query)
+	if loc == nil {
+		return &PartialIndexAnalysis{Benefit: "No WHERE clause to filter the index"}
+	}
+	where := query[loc[1]:]
+	if end := whereEndRegex.FindStringIndex(where); end != nil {
+		where = where[:end[0]]
+	}
+	if orJoinRegex.MatchString(query) {
+		return &PartialIndexAnalysis{Benefit: "OR conditions and JOINs cannot be served by a partial index"}
+	}
+
+	var predicates []string
+	constant := make(map[string]bool)
+	for _, m := range constantEqualityRegex.FindAllStringSubmatch(where, -1) {
+		col := strings.ToLower(m[1])
+		predicates = append(predicates, col+" = "+m[2])
+		constant[col] = true
+	}
+	for _, m := range nullCheckRegex.FindAllStringSubmatch(where, -1) {
+		col := strings.ToLower(m[1])
+		if m[2] != "" {
+			predicates = append(predicates, col+" IS NOT NULL")
+		} else {
+			predicates = append(predicates, col+" IS NULL")
+		}
+		constant[col] = true
+	}
+	if len(predicates) == 0 {
+		return &PartialIndexAnalysis{Benefit: "No constant predicate to filter the index"}
+	}
+
+	// Index the remaining filter columns, then the sort column. Literals are
+	// blanked so their contents are not mistaken for conditions.
+	var columns []string
+	seen := make(map[string]bool)
+	clause, _ := ParseWhereClause("where " + stringLiteralRegex.ReplaceAllString(where, "?"))
+	for _, cond := range clause.Conditions {
+		if cond.Function == "" && !constant[cond.Column] && !seen[cond.Column] {
+			columns = append(columns, cond.Column)
+			seen[cond.Column] = true
+		}
+	}
+	if m := orderByColumnRegex.FindStringSubmatch(query); m != nil {
+		if col := strings.ToLower(m[1]); !seen[col] {
+			columns = append(columns, col)
+			seen[col] = true
+		}
+	}
+	if len(columns) == 0 {
+		for col := range constant {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+	}
+
+	predicate := strings.Join(predicates, " AND ")
+	return &PartialIndexAnalysis{
+		Recommended: true,
+		Columns:     columns,
+		Predicate:   predicate,
+		Benefit:     "index only the rows matching " + predicate + ", a small subset of the table",
+	}
+}
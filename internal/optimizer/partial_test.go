@@ -0,0 +1,114 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/coregx/relica/internal/analyzer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzePartialIndex(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		recommended bool
+		columns     []string
+		predicate   string
+	}{
+		{
+			name:        "constant predicate with parameter filter",
+			query:       "SELECT * FROM orders WHERE status = 'pending' AND customer_id = ?",
+			recommended: true,
+			columns:     []string{"customer_id"},
+			predicate:   "status = 'pending'",
+		},
+		{
+			name:        "constant predicate with ORDER BY",
+			query:       "SELECT id FROM jobs WHERE Status = 'Queued' ORDER BY created_at LIMIT 10",
+			recommended: true,
+			columns:     []string{"created_at"},
+			predicate:   "status = 'Queued'",
+		},
+		{
+			name:        "NULL check and boolean",
+			query:       "SELECT * FROM users WHERE deleted_at IS NULL AND is_active = true AND email = ?",
+			recommended: true,
+			columns:     []string{"email"},
+			predicate:   "is_active = true AND deleted_at IS NULL",
+		},
+		{
+			name:        "constant predicate only",
+			query:       "SELECT * FROM orders WHERE status = 'pending'",
+			recommended: true,
+			columns:     []string{"status"},
+			predicate:   "status = 'pending'",
+		},
+		{
+			name:        "literal contents are not conditions",
+			query:       "SELECT * FROM tasks WHERE state = 'in progress' AND owner = ?",
+			recommended: true,
+			columns:     []string{"owner"},
+			predicate:   "state = 'in progress'",
+		},
+		{
+			name:  "placeholders only",
+			query: "SELECT * FROM orders WHERE status = ? AND customer_id = ?",
+		},
+		{
+			name:  "OR logic",
+			query: "SELECT * FROM orders WHERE status = 'pending' OR status = 'failed'",
+		},
+		{
+			name:  "JOIN",
+			query: "SELECT * FROM orders o JOIN users u ON u.id = o.user_id WHERE o.status = 'pending'",
+		},
+		{
+			name:  "no WHERE clause",
+			query: "SELECT * FROM orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AnalyzePartialIndex(tt.query)
+			assert.Equal(t, tt.recommended, result.Recommended, result.Benefit)
+			if tt.recommended {
+				assert.Equal(t, tt.columns, result.Columns)
+				assert.Equal(t, tt.predicate, result.Predicate)
+			}
+		})
+	}
+}
+
+func TestBasicOptimizer_Suggest_PartialIndex(t *testing.T) {
+	query := "SELECT * FROM orders WHERE status = 'pending' AND customer_id = ?"
+
+	for _, database := range []string{dialectPostgres, dialectSQLite, dialectMySQL} {
+		t.Run(database, func(t *testing.T) {
+			plan := &analyzer.QueryPlan{FullScan: true, Database: database}
+			opt := NewBasicOptimizer(&mockAnalyzer{plan: plan}, 0)
+			suggestions := opt.Suggest(&Analysis{QueryPlan: plan, MissingIndexes: opt.detectMissingIndexes(query, plan)})
+
+			var partial []Suggestion
+			for _, s := range suggestions {
+				if s.Type == SuggestionPartialIndex {
+					partial = append(partial, s)
+				}
+			}
+			if database == dialectMySQL {
+				assert.Empty(t, partial, "MySQL has no partial indexes")
+				return
+			}
+			if assert.Len(t, partial, 1) {
+				assert.Equal(t, SeverityInfo, partial[0].Severity)
+				assert.Equal(t, "CREATE INDEX idx_orders_customer_id_partial ON orders(customer_id) WHERE status = 'pending';", partial[0].SQL)
+			}
+		})
+	}
+}
+
+func TestIndexRecommendation_IndexName_Partial(t *testing.T) {
+	idx := IndexRecommendation{Table: "orders", Columns: []string{"customer_id"}, Where: "status = 'pending'"}
+	assert.Equal(t, "idx_orders_customer_id_partial", idx.IndexName())
+	assert.Equal(t, SuggestionPartialIndex, categorizeIndexRecommendation(idx))
+}
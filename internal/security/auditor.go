@@ -20,7 +20,8 @@ type AuditLevel int
 const (
 	// AuditNone disables audit logging.
 	AuditNone AuditLevel = iota
-	// AuditWrites logs only write operations (INSERT, UPDATE, DELETE).
+	// AuditWrites logs only write operations (INSERT, UPDATE, DELETE, TRUNCATE)
+	// and administrative commands (ANALYZE, VACUUM, OPTIMIZE, CANCEL).
 	AuditWrites
 	// AuditReads logs read operations (SELECT) in addition to writes.
 	AuditReads
@@ -39,22 +40,33 @@ type AuditEvent struct {
 	ParamsHash   string    `json:"params_hash,omitempty"` // SHA256 hash of parameters
 	ClientIP     string    `json:"client_ip,omitempty"`   // Client IP from context
 	RequestID    string    `json:"request_id,omitempty"`  // Request ID from context
+	Tag          string    `json:"tag,omitempty"`         // Query tag from context
 	Success      bool      `json:"success"`               // Whether operation succeeded
 	Error        string    `json:"error,omitempty"`       // Error message if failed
 	Duration     int64     `json:"duration_ms,omitempty"` // Query execution time in milliseconds
 }
 
+// AuditSink receives audit events, for example to store them.
+// WriteAuditEvent is called synchronously on the query path, so sinks
+// that do I/O should buffer events and write them asynchronously.
+type AuditSink interface {
+	WriteAuditEvent(event AuditEvent)
+}
+
 // Auditor handles audit logging of database operations.
 type Auditor struct {
 	logger *slog.Logger
 	level  AuditLevel
+	sinks  []AuditSink
 }
 
-// NewAuditor creates a new audit logger.
-func NewAuditor(logger *slog.Logger, level AuditLevel) *Auditor {
+// NewAuditor creates a new audit logger. Events are logged to logger, if not
+// nil, and passed to sinks.
+func NewAuditor(logger *slog.Logger, level AuditLevel, sinks ...AuditSink) *Auditor {
 	return &Auditor{
 		logger: logger,
 		level:  level,
+		sinks:  sinks,
 	}
 }
 
@@ -83,6 +95,9 @@ func (a *Auditor) LogOperation(ctx context.Context, operation, query string, arg
 	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
 		event.RequestID = requestID
 	}
+	if tag, ok := ctx.Value(queryTagKey).(string); ok {
+		event.Tag = tag
+	}
 
 	// Hash parameters for privacy (don't log actual values)
 	if len(args) > 0 {
@@ -110,7 +125,7 @@ func (a *Auditor) LogOperation(ctx context.Context, operation, query string, arg
 
 // LogSecurityEvent logs a security-related event (blocked query, validation failure, etc.).
 func (a *Auditor) LogSecurityEvent(ctx context.Context, eventType, query string, err error) {
-	if a.logger == nil {
+	if a.logger == nil && len(a.sinks) == 0 {
 		return
 	}
 
@@ -132,6 +147,16 @@ func (a *Auditor) LogSecurityEvent(ctx context.Context, eventType, query string,
 	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
 		event.RequestID = requestID
 	}
+	if tag, ok := ctx.Value(queryTagKey).(string); ok {
+		event.Tag = tag
+	}
+
+	for _, sink := range a.sinks {
+		sink.WriteAuditEvent(event)
+	}
+	if a.logger == nil {
+		return
+	}
 
 	// Log as security event
 	a.logger.Warn("security_event",
@@ -140,6 +165,7 @@ func (a *Auditor) LogSecurityEvent(ctx context.Context, eventType, query string,
 		"user", event.User,
 		"client_ip", event.ClientIP,
 		"request_id", event.RequestID,
+		"tag", event.Tag,
 		"query", query,
 		"error", err.Error(),
 	)
@@ -147,14 +173,15 @@ func (a *Auditor) LogSecurityEvent(ctx context.Context, eventType, query string,
 
 // shouldLog determines if an operation should be logged based on audit level.
 func (a *Auditor) shouldLog(operation string) bool {
-	if a.logger == nil || a.level == AuditNone {
+	if (a.logger == nil && len(a.sinks) == 0) || a.level == AuditNone {
 		return false
 	}
 
 	switch a.level {
 	case AuditWrites:
 		// Log only write operations
-		return operation == auditOpInsert || operation == "UPDATE" || operation == "DELETE" || operation == "UPSERT"
+		return operation == auditOpInsert || operation == "UPDATE" || operation == "DELETE" || operation == "UPSERT" || operation == "TRUNCATE" ||
+			operation == "ANALYZE" || operation == "VACUUM" || operation == "OPTIMIZE" || operation == "CANCEL"
 	case AuditReads:
 		// Log reads and writes
 		return true
@@ -166,8 +193,11 @@ func (a *Auditor) shouldLog(operation string) bool {
 	}
 }
 
-// logEvent writes the audit event to the logger.
+// logEvent writes the audit event to the sinks and the logger.
 func (a *Auditor) logEvent(event AuditEvent) {
+	for _, sink := range a.sinks {
+		sink.WriteAuditEvent(event)
+	}
 	if a.logger == nil {
 		return
 	}
@@ -188,6 +218,7 @@ func (a *Auditor) logEvent(event AuditEvent) {
 		"params_hash", event.ParamsHash,
 		"client_ip", event.ClientIP,
 		"request_id", event.RequestID,
+		"tag", event.Tag,
 		"success", event.Success,
 		"error", event.Error,
 		"duration_ms", event.Duration,
@@ -249,6 +280,12 @@ func findTableStart(upper string) (int, bool) {
 		}
 	case strings.HasPrefix(upper, "UPDATE"):
 		return 6, true
+	case strings.HasPrefix(upper, "TRUNCATE"):
+		rest := strings.TrimLeft(upper[8:], " \t\n\r")
+		if strings.HasPrefix(rest, "TABLE ") {
+			return len(upper) - len(rest) + 5, true
+		}
+		return 8, true
 	}
 	return 0, false
 }
@@ -260,6 +297,7 @@ const (
 	userKey      contextKey = "relica:user"
 	clientIPKey  contextKey = "relica:client_ip"
 	requestIDKey contextKey = "relica:request_id"
+	queryTagKey  contextKey = "relica:query_tag"
 )
 
 // WithUser adds user information to the context for audit logging.
@@ -277,6 +315,11 @@ func WithRequestID(ctx context.Context, requestID string) context.Context {
 	return context.WithValue(ctx, requestIDKey, requestID)
 }
 
+// WithQueryTag adds a query tag to the context for audit logging.
+func WithQueryTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, queryTagKey, tag)
+}
+
 // GetUser retrieves user from context (for testing/debugging).
 func GetUser(ctx context.Context) string {
 	user, _ := ctx.Value(userKey).(string)
@@ -294,3 +337,9 @@ func GetRequestID(ctx context.Context) string {
 	requestID, _ := ctx.Value(requestIDKey).(string)
 	return requestID
 }
+
+// GetQueryTag retrieves the query tag from context (for testing/debugging).
+func GetQueryTag(ctx context.Context) string {
+	tag, _ := ctx.Value(queryTagKey).(string)
+	return tag
+}
@@ -77,6 +77,36 @@ func TestAuditor_LogOperation(t *testing.T) {
 			err:       errors.New("record not found"),
 			wantLog:   true,
 		},
+		{
+			name:      "truncate_audit_writes",
+			level:     AuditWrites,
+			operation: "TRUNCATE",
+			query:     "TRUNCATE TABLE logs",
+			args:      nil,
+			result:    nil,
+			err:       nil,
+			wantLog:   true,
+		},
+		{
+			name:      "vacuum_audit_writes",
+			level:     AuditWrites,
+			operation: "VACUUM",
+			query:     "VACUUM FULL logs",
+			args:      nil,
+			result:    nil,
+			err:       nil,
+			wantLog:   true,
+		},
+		{
+			name:      "cancel_audit_writes",
+			level:     AuditWrites,
+			operation: "CANCEL",
+			query:     "SELECT pg_cancel_backend($1)",
+			args:      []interface{}{4711},
+			result:    nil,
+			err:       nil,
+			wantLog:   true,
+		},
 		{
 			name:      "audit_none",
 			level:     AuditNone,
@@ -137,6 +167,7 @@ func TestAuditor_ContextMetadata(t *testing.T) {
 	ctx = WithUser(ctx, "john.doe@example.com")
 	ctx = WithClientIP(ctx, "192.168.1.100")
 	ctx = WithRequestID(ctx, "req-12345")
+	ctx = WithQueryTag(ctx, "checkout.load_cart")
 
 	auditor.LogOperation(ctx, "INSERT", "INSERT INTO logs (message) VALUES (?)",
 		[]interface{}{"test message"}, &mockResult{rows: 1}, nil, 5*time.Millisecond)
@@ -153,6 +184,9 @@ func TestAuditor_ContextMetadata(t *testing.T) {
 	if !strings.Contains(logOutput, "req-12345") {
 		t.Error("Log missing request ID from context")
 	}
+	if !strings.Contains(logOutput, "checkout.load_cart") {
+		t.Error("Log missing query tag from context")
+	}
 }
 
 func TestAuditor_ParamsHash(t *testing.T) {
@@ -259,6 +293,12 @@ func TestContextHelpers(t *testing.T) {
 		t.Errorf("GetRequestID() = %s, want req-xyz-789", reqID)
 	}
 
+	// Test WithQueryTag and GetQueryTag
+	ctx = WithQueryTag(ctx, "orders.list")
+	if tag := GetQueryTag(ctx); tag != "orders.list" {
+		t.Errorf("GetQueryTag() = %s, want orders.list", tag)
+	}
+
 	// Test empty context
 	emptyCtx := context.Background()
 	if user := GetUser(emptyCtx); user != "" {
@@ -360,7 +400,7 @@ func TestExtractTableName(t *testing.T) {
 	}{
 		{"empty", "", ""},
 		{"whitespace only", "   ", ""},
-		{"unknown statement", "TRUNCATE users", ""},
+		{"unknown statement", "VACUUM users", ""},
 
 		// SELECT
 		{"select simple", "SELECT * FROM users", "users"},
@@ -380,6 +420,11 @@ func TestExtractTableName(t *testing.T) {
 		{"delete simple", "DELETE FROM users WHERE id = ?", "users"},
 		{"delete no from", "DELETE users", ""},
 
+		// TRUNCATE
+		{"truncate table", `TRUNCATE TABLE "events" RESTART IDENTITY`, `"events"`},
+		{"truncate without table keyword", "TRUNCATE users", "users"},
+		{"truncate table-like name", "TRUNCATE table_log", "table_log"},
+
 		// Edge cases
 		{"trailing whitespace", "  SELECT * FROM  users  ", "users"},
 		{"table only", "UPDATE users", "users"},
@@ -409,7 +454,9 @@ func TestFindTableStart(t *testing.T) {
 		{"update", "UPDATE USERS SET", 6, true},        // len("UPDATE")=6
 		{"delete from", "DELETE FROM USERS", 11, true}, // "FROM" at 7, +4=11
 		{"delete no from", "DELETE USERS", 0, false},
-		{"unknown", "TRUNCATE USERS", 0, false},
+		{"truncate table", "TRUNCATE TABLE USERS", 14, true}, // "TABLE" at 9, +5=14
+		{"truncate", "TRUNCATE USERS", 8, true},              // len("TRUNCATE")=8
+		{"unknown", "VACUUM USERS", 0, false},
 	}
 
 	for _, tt := range tests {
@@ -421,3 +468,32 @@ func TestFindTableStart(t *testing.T) {
 		})
 	}
 }
+
+// recordingSink collects the events passed to it.
+type recordingSink struct {
+	events []AuditEvent
+}
+
+func (s *recordingSink) WriteAuditEvent(event AuditEvent) {
+	s.events = append(s.events, event)
+}
+
+func TestAuditor_Sinks(t *testing.T) {
+	sink := &recordingSink{}
+	auditor := NewAuditor(nil, AuditWrites, sink)
+	ctx := WithUser(context.Background(), "alice")
+
+	auditor.LogOperation(ctx, "SELECT", "SELECT * FROM users", nil, nil, nil, time.Millisecond)
+	auditor.LogOperation(ctx, "UPDATE", "UPDATE users SET name = ?", []interface{}{"bob"}, &mockResult{rows: 2}, nil, time.Millisecond)
+	auditor.LogSecurityEvent(ctx, "query_blocked", "SELECT 1 OR 1=1", errors.New("blocked"))
+
+	if len(sink.events) != 2 {
+		t.Fatalf("sink got %d events, want 2 (reads are not audited at AuditWrites)", len(sink.events))
+	}
+	if e := sink.events[0]; e.Operation != "UPDATE" || e.Table != "users" || e.User != "alice" || e.AffectedRows != 2 {
+		t.Errorf("unexpected operation event: %+v", e)
+	}
+	if e := sink.events[1]; e.Operation != "query_blocked" || e.Success || e.Error != "blocked" {
+		t.Errorf("unexpected security event: %+v", e)
+	}
+}
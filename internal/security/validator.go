@@ -8,10 +8,16 @@ import (
 	"strings"
 )
 
-// Validator validates SQL queries and parameters against dangerous patterns.
+// Validator validates SQL queries and parameters against dangerous patterns
+// and the policies configured with its options.
 type Validator struct {
-	patterns []*regexp.Regexp
-	strict   bool
+	patterns      []*regexp.Regexp
+	strict        bool
+	denyLiterals  bool            // string literals in WHERE are violations
+	maxParams     int             // maximum number of parameters (0 = unlimited)
+	allowedTables map[string]bool // tables queries may reference (nil = all)
+	deniedTables  map[string]bool // tables queries must not reference
+	reportOnly    bool            // violations are reported, not blocked
 }
 
 // ValidatorOption configures the Validator.
@@ -24,6 +30,67 @@ func WithStrict(strict bool) ValidatorOption {
 	}
 }
 
+// WithDenyLiterals rejects queries comparing columns with string literals in
+// their WHERE clause (name = 'ann', id IN ('a', 'b')), a heuristic for values
+// concatenated into the SQL instead of passed as parameters.
+func WithDenyLiterals(deny bool) ValidatorOption {
+	return func(v *Validator) {
+		v.denyLiterals = deny
+	}
+}
+
+// WithMaxParams rejects queries with more than n parameters. Zero means no
+// limit.
+func WithMaxParams(n int) ValidatorOption {
+	return func(v *Validator) {
+		v.maxParams = n
+	}
+}
+
+// WithAllowedTables rejects queries referencing tables not in tables. Names
+// are matched case-insensitively; a schema-qualified reference such as
+// app.users also matches the entry "users".
+func WithAllowedTables(tables ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.allowedTables = tableSet(v.allowedTables, tables)
+	}
+}
+
+// WithDeniedTables rejects queries referencing any of tables. Names are
+// matched case-insensitively; a schema-qualified reference such as app.users
+// also matches the entry "users".
+func WithDeniedTables(tables ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.deniedTables = tableSet(v.deniedTables, tables)
+	}
+}
+
+// WithReportOnly makes violations reportable instead of blocking: the
+// validator still returns them, and ReportOnly tells the caller to log them
+// and run the query anyway. Use it to try out a policy in production.
+func WithReportOnly(reportOnly bool) ValidatorOption {
+	return func(v *Validator) {
+		v.reportOnly = reportOnly
+	}
+}
+
+// ReportOnly reports whether violations should be logged rather than
+// blocked (see WithReportOnly).
+func (v *Validator) ReportOnly() bool {
+	return v.reportOnly
+}
+
+// tableSet adds the normalized names of tables to set.
+func tableSet(set map[string]bool, tables []string) map[string]bool {
+	if set == nil {
+		set = make(map[string]bool, len(tables))
+	}
+	for _, table := range tables {
+		set[normalizeTable(table)] = true
+	}
+	return set
+}
+
 // NewValidator creates a new SQL injection validator with default dangerous patterns.
 func NewValidator(opts ...ValidatorOption) *Validator {
 	v := &Validator{
@@ -91,8 +158,9 @@ var strictPatterns = []string{
 	`\bEXECUTE\b`, // Any EXECUTE
 }
 
-// ValidateQuery checks if a query contains dangerous SQL injection patterns.
-// Returns an error if a dangerous pattern is detected.
+// ValidateQuery checks if a query contains dangerous SQL injection patterns
+// or violates the literal and table policies.
+// Returns an error if a dangerous pattern or violation is detected.
 func (v *Validator) ValidateQuery(query string) error {
 	// Normalize query for pattern matching
 	normalized := strings.ToUpper(query)
@@ -103,12 +171,34 @@ func (v *Validator) ValidateQuery(query string) error {
 		}
 	}
 
+	if v.denyLiterals && whereHasLiteral(query) {
+		return fmt.Errorf("string literal in WHERE clause: pass values as parameters")
+	}
+
+	if v.allowedTables != nil || v.deniedTables != nil {
+		for _, table := range referencedTables(query) {
+			name := normalizeTable(table)
+			short := name[strings.LastIndexByte(name, '.')+1:]
+			if v.deniedTables[name] || v.deniedTables[short] {
+				return fmt.Errorf("table %q is denied by the validator policy", table)
+			}
+			if v.allowedTables != nil && !v.allowedTables[name] && !v.allowedTables[short] {
+				return fmt.Errorf("table %q is not allowed by the validator policy", table)
+			}
+		}
+	}
+
 	return nil
 }
 
-// ValidateParams checks query parameters for SQL injection attempts.
+// ValidateParams checks query parameters for SQL injection attempts and the
+// parameter count limit.
 // Looks for suspicious string patterns that may bypass prepared statements.
 func (v *Validator) ValidateParams(params []interface{}) error {
+	if v.maxParams > 0 && len(params) > v.maxParams {
+		return fmt.Errorf("query has %d parameters, more than the maximum of %d", len(params), v.maxParams)
+	}
+
 	for i, param := range params {
 		str, ok := param.(string)
 		if !ok {
@@ -149,6 +239,102 @@ func containsSQLInjection(value string) bool {
 	return false
 }
 
+var (
+	// whereRegex finds the start of a WHERE clause.
+	whereRegex = regexp.MustCompile(`(?i)\bWHERE\b`)
+
+	// whereEndRegex finds the clause ending a WHERE clause.
+	whereEndRegex = regexp.MustCompile(`(?i)\b(?:GROUP\s+BY|ORDER\s+BY|HAVING|LIMIT|RETURNING)\b`)
+
+	// literalComparisonRegex matches a comparison with a string literal.
+	literalComparisonRegex = regexp.MustCompile(`(?i)(?:=|<>|!=|<|>|\bLIKE|\bILIKE|\bIN\s*\()\s*'`)
+
+	// tableKeywordRegex matches the keywords followed by a table name.
+	tableKeywordRegex = regexp.MustCompile(`(?i)\b(FROM|JOIN|INTO|UPDATE|TABLE)\s+`)
+
+	// tableNameRegex matches a possibly quoted and schema-qualified table name.
+	tableNameRegex = regexp.MustCompile("^[`\"\\[]?[A-Za-z_][\\w$]*[`\"\\]]?(?:\\.[`\"\\[]?[A-Za-z_][\\w$]*[`\"\\]]?)?")
+
+	// tableListRegex matches the separator and alias before the next table of
+	// a FROM list.
+	tableListRegex = regexp.MustCompile(`(?i)^(?:\s+(?:AS\s+)?[A-Za-z_]\w*)?\s*,\s*`)
+)
+
+// whereHasLiteral reports whether the WHERE clauses of query compare with a
+// string literal.
+func whereHasLiteral(query string) bool {
+	loc := whereRegex.FindStringIndex(query)
+	if loc == nil {
+		return false
+	}
+	clause := query[loc[1]:]
+	if end := whereEndRegex.FindStringIndex(clause); end != nil {
+		clause = clause[:end[0]]
+	}
+	return literalComparisonRegex.MatchString(clause)
+}
+
+// referencedTables returns the tables named after FROM (including the rest
+// of a comma-separated FROM list), JOIN, INTO, UPDATE and TABLE in query.
+// Subqueries are skipped; their tables are found at their own FROM.
+func referencedTables(query string) []string {
+	var tables []string
+	for _, m := range tableKeywordRegex.FindAllStringSubmatchIndex(query, -1) {
+		if !tableContext(query, m[0]) {
+			continue
+		}
+		rest := query[m[1]:]
+		for {
+			name := tableNameRegex.FindString(rest)
+			if name == "" {
+				break
+			}
+			tables = append(tables, name)
+			if !strings.EqualFold(query[m[2]:m[3]], "FROM") {
+				break
+			}
+			rest = rest[len(name):]
+			sep := tableListRegex.FindString(rest)
+			if sep == "" {
+				break
+			}
+			rest = rest[len(sep):]
+		}
+	}
+	return tables
+}
+
+// tableContext reports whether the keyword at pos of query can name a table:
+// it is outside string literals and, if inside parentheses, in a subquery
+// rather than an expression such as EXTRACT(YEAR FROM created_at).
+func tableContext(query string, pos int) bool {
+	var subquery []bool // open parentheses, innermost last
+	inString := false
+	for i := 0; i < pos; i++ {
+		switch c := query[i]; {
+		case c == '\'':
+			inString = !inString
+		case inString:
+		case c == '(':
+			next := strings.TrimLeft(query[i+1:], " \t\r\n")
+			subquery = append(subquery, hasPrefixFold(next, "SELECT") || hasPrefixFold(next, "WITH"))
+		case c == ')' && len(subquery) > 0:
+			subquery = subquery[:len(subquery)-1]
+		}
+	}
+	return !inString && (len(subquery) == 0 || subquery[len(subquery)-1])
+}
+
+// hasPrefixFold reports whether s begins with prefix, ignoring case.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// normalizeTable lowercases table and removes identifier quotes.
+func normalizeTable(table string) string {
+	return strings.ToLower(strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(table))
+}
+
 // compilePatterns compiles string patterns to regexp.Regexp.
 func compilePatterns(patterns []string) []*regexp.Regexp {
 	compiled := make([]*regexp.Regexp, 0, len(patterns))
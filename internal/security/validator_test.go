@@ -381,3 +381,77 @@ func BenchmarkValidator_ValidateParams_Malicious(b *testing.B) {
 		_ = validator.ValidateParams(params)
 	}
 }
+
+func TestValidator_DenyLiterals(t *testing.T) {
+	v := NewValidator(WithDenyLiterals(true))
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"SELECT * FROM users WHERE id = ?", false},
+		{"SELECT * FROM users WHERE name = 'ann'", true},
+		{"SELECT * FROM users WHERE status IN ('a', 'b')", true},
+		{"SELECT * FROM users WHERE name LIKE 'a%'", true},
+		{"SELECT * FROM users WHERE id > 10", false},
+		{"SELECT 'x' AS label FROM users WHERE id = ?", false},
+		{"SELECT * FROM users WHERE id = ? ORDER BY name = 'x'", false},
+		{"UPDATE users SET name = 'ann' WHERE id = ?", false},
+	}
+	for _, tt := range tests {
+		if err := v.ValidateQuery(tt.query); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateQuery(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_MaxParams(t *testing.T) {
+	v := NewValidator(WithMaxParams(2))
+	if err := v.ValidateParams([]interface{}{1, 2}); err != nil {
+		t.Errorf("2 params: unexpected error %v", err)
+	}
+	err := v.ValidateParams([]interface{}{1, 2, 3})
+	if err == nil || err.Error() != "query has 3 parameters, more than the maximum of 2" {
+		t.Errorf("3 params: error = %v", err)
+	}
+}
+
+func TestValidator_TablePolicies(t *testing.T) {
+	allow := NewValidator(WithAllowedTables("users", "app.orders"))
+	deny := NewValidator(WithDeniedTables("secrets"))
+	tests := []struct {
+		query             string
+		allowErr, denyErr bool
+	}{
+		{`SELECT * FROM "users" WHERE id = ?`, false, false},
+		{"SELECT * FROM users u JOIN app.orders o ON o.user_id = u.id", false, false},
+		{"SELECT * FROM users u, secrets s WHERE s.id = u.id", true, true},
+		{"INSERT INTO `Secrets` (k) VALUES (?)", true, true},
+		{"UPDATE app.orders SET total = ?", false, false},
+		{"UPDATE orders SET total = ?", true, false},
+		{"DELETE FROM payments WHERE id = ?", true, false},
+		{"SELECT EXTRACT(YEAR FROM created_at) FROM users", false, false},
+		{"SELECT * FROM users WHERE note = 'copied from secrets'", false, false},
+		{"SELECT * FROM users WHERE id IN (SELECT user_id FROM secrets)", true, true},
+	}
+	for _, tt := range tests {
+		if err := allow.ValidateQuery(tt.query); (err != nil) != tt.allowErr {
+			t.Errorf("allow list: ValidateQuery(%q) error = %v, wantErr %v", tt.query, err, tt.allowErr)
+		}
+		if err := deny.ValidateQuery(tt.query); (err != nil) != tt.denyErr {
+			t.Errorf("deny list: ValidateQuery(%q) error = %v, wantErr %v", tt.query, err, tt.denyErr)
+		}
+	}
+}
+
+func TestValidator_ReportOnly(t *testing.T) {
+	if NewValidator().ReportOnly() {
+		t.Error("validators block by default")
+	}
+	v := NewValidator(WithReportOnly(true), WithDenyLiterals(true))
+	if !v.ReportOnly() {
+		t.Error("ReportOnly() = false")
+	}
+	if err := v.ValidateQuery("SELECT * FROM t WHERE a = 'x'"); err == nil {
+		t.Error("report-only validators still return violations")
+	}
+}
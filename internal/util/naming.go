@@ -0,0 +1,91 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// NamingStrategy maps a Go struct field name to a database column name.
+// It is applied to exported fields without a db tag.
+type NamingStrategy func(field string) string
+
+// namingStrategy holds the active NamingStrategy (nil means field name as-is).
+var namingStrategy atomic.Pointer[NamingStrategy]
+
+// SetNamingStrategy sets the global strategy for untagged fields.
+// nil restores the default (the field name unchanged).
+func SetNamingStrategy(ns NamingStrategy) {
+	if ns == nil {
+		namingStrategy.Store(nil)
+		return
+	}
+	namingStrategy.Store(&ns)
+}
+
+// FieldColumn returns the column name for an untagged field named field.
+func FieldColumn(field string) string {
+	if ns := namingStrategy.Load(); ns != nil {
+		return (*ns)(field)
+	}
+	return field
+}
+
+// IsRelicaPK reports whether field is tagged relica:"pk", marking it as part of
+// the primary key independently of its db tag.
+func IsRelicaPK(field reflect.StructField) bool {
+	for _, opt := range strings.Split(field.Tag.Get("relica"), ",") {
+		if strings.TrimSpace(opt) == "pk" {
+			return true
+		}
+	}
+	return false
+}
+
+// RelicaIDGenerator returns the ID generator named in the relica tag of field
+// ("ulid", "snowflake", "uuid" or "uuidv7"), or "" if the field has none.
+// "uuidv4" is an alias of "uuid".
+func RelicaIDGenerator(field reflect.StructField) string {
+	for _, opt := range strings.Split(field.Tag.Get("relica"), ",") {
+		switch opt = strings.TrimSpace(opt); opt {
+		case "ulid", "snowflake", "uuid", "uuidv7":
+			return opt
+		case "uuidv4":
+			return "uuid"
+		}
+	}
+	return ""
+}
+
+// SnakeCase converts a Go identifier to snake_case, keeping acronyms together:
+// "CreatedAt" -> "created_at", "UserID" -> "user_id", "HTTPStatus" -> "http_status".
+func SnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// CamelCase converts a Go identifier to lowerCamelCase, lowering a leading
+// acronym: "CreatedAt" -> "createdAt", "ID" -> "id", "HTTPStatus" -> "httpStatus".
+func CamelCase(s string) string {
+	runes := []rune(s)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
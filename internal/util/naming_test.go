@@ -0,0 +1,112 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Name":       "name",
+		"CreatedAt":  "created_at",
+		"UserID":     "user_id",
+		"ID":         "id",
+		"HTTPStatus": "http_status",
+		"Address2":   "address2",
+		"Line2Text":  "line2_text",
+	}
+	for in, want := range tests {
+		if got := SnakeCase(in); got != want {
+			t.Errorf("SnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	tests := map[string]string{
+		"Name":       "name",
+		"CreatedAt":  "createdAt",
+		"UserID":     "userID",
+		"ID":         "id",
+		"HTTPStatus": "httpStatus",
+	}
+	for in, want := range tests {
+		if got := CamelCase(in); got != want {
+			t.Errorf("CamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNamingStrategy_StructToMap(t *testing.T) {
+	type legacy struct {
+		AccountNo int    `relica:"pk"`
+		FullName  string // untagged
+		Email     string `db:"mail"`
+	}
+
+	SetNamingStrategy(SnakeCase)
+	defer SetNamingStrategy(nil)
+
+	m, err := StructToMap(legacy{AccountNo: 7, FullName: "Ann", Email: "a@x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"account_no": 7, "full_name": "Ann", "mail": "a@x"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("StructToMap() = %v, want %v", m, want)
+	}
+
+	SetNamingStrategy(nil)
+	if got := FieldColumn("FullName"); got != "FullName" {
+		t.Errorf("default FieldColumn() = %q, want field name", got)
+	}
+}
+
+func TestFindPrimaryKeyFields_RelicaTag(t *testing.T) {
+	type legacyUntagged struct {
+		ID        int
+		AccountNo int `relica:"pk"`
+	}
+	type legacyTagged struct {
+		Code   string `db:"code" relica:"pk"`
+		Region string `db:"region" relica:"pk"`
+		Name   string `db:"name"`
+	}
+
+	SetNamingStrategy(SnakeCase)
+	defer SetNamingStrategy(nil)
+
+	info, err := FindPrimaryKeyFields(reflect.ValueOf(legacyUntagged{AccountNo: 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info.Columns, []string{"account_no"}) || info.Values[0].Interface() != 5 {
+		t.Errorf("relica:\"pk\" should override the ID fallback, got %v", info.Columns)
+	}
+
+	info, err = FindPrimaryKeyFields(reflect.ValueOf(legacyTagged{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info.Columns, []string{"code", "region"}) {
+		t.Errorf("Columns = %v, want [code region]", info.Columns)
+	}
+}
+
+func TestRelicaIDGenerator(t *testing.T) {
+	type model struct {
+		ID    string `db:"id" relica:"pk, ulid"`
+		Seq   int64  `relica:"snowflake"`
+		Other int    `relica:"pk"`
+		Plain string
+		V4    string `relica:"pk,uuidv4"`
+		V7    string `relica:"uuidv7"`
+	}
+	typ := reflect.TypeOf(model{})
+	want := []string{"ulid", "snowflake", "", "", "uuid", "uuidv7"}
+	for i, w := range want {
+		if got := RelicaIDGenerator(typ.Field(i)); got != w {
+			t.Errorf("RelicaIDGenerator(%s) = %q, want %q", typ.Field(i).Name, got, w)
+		}
+	}
+}
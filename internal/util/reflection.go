@@ -56,11 +56,13 @@ func parseDBTag(tag string) (column string, isPK bool) {
 // FindPrimaryKeyFields finds all primary key fields in a struct.
 //
 // Priority for single PK (backwards compatible):
-//  1. Field with db:"pk" tag (explicit single PK)
-//  2. Fields with db:"column,pk" tags (composite PK)
+//  1. Fields with db:"column,pk" or relica:"pk" tags (single or composite PK)
+//  2. Field with db:"pk" tag (legacy single PK)
 //  3. Field named "ID" (fallback)
 //  4. Field named "Id" (last resort)
 //
+// A relica:"pk" field without a db tag takes its column from the naming strategy.
+//
 // For composite PK, fields are returned in struct declaration order.
 //
 //nolint:cyclop,gocognit,gocyclo,funlen // Acceptable complexity for PK field search with multiple priorities.
@@ -102,6 +104,16 @@ func FindPrimaryKeyFields(v reflect.Value) (*PrimaryKeyInfo, error) {
 
 		tag, hasTag := field.Tag.Lookup("db")
 		if !hasTag {
+			// relica:"pk" marks an untagged field as PK; column from the naming strategy
+			if IsRelicaPK(field) {
+				pkFields = append(pkFields, pkField{
+					index:  i,
+					field:  field,
+					value:  v.Field(i),
+					column: FieldColumn(field.Name),
+				})
+				continue
+			}
 			// Track "ID" field as fallback
 			if field.Name == "ID" {
 				idFieldIndex = i
@@ -119,6 +131,9 @@ func FindPrimaryKeyFields(v reflect.Value) (*PrimaryKeyInfo, error) {
 		if column == "-" {
 			continue
 		}
+		if IsRelicaPK(field) && column != "pk" {
+			isPK = true
+		}
 
 		if isPK {
 			pf := pkField{
@@ -237,13 +252,23 @@ func ModelToColumns(model interface{}) map[string]string {
 	return columns
 }
 
+// ColumnName returns the column name of a struct field: the column of its db
+// tag ("-" for skipped fields), or the naming strategy's name if untagged.
+func ColumnName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("db"); ok {
+		column, _ := parseDBTag(tag)
+		return column
+	}
+	return FieldColumn(field.Name)
+}
+
 // StructToMap converts a struct to map[string]interface{} using db tags.
 //
 // Rules:
 //   - Unexported fields are skipped.
 //   - db:"-" fields are skipped.
 //   - db:"column_name" or db:"column_name,pk" maps to column_name.
-//   - Fields without db tag use field name.
+//   - Fields without db tag use the naming strategy (field name by default).
 //   - Zero values are included.
 //
 // Returns error if:
@@ -273,15 +298,9 @@ func StructToMap(data interface{}) (map[string]interface{}, error) {
 			continue
 		}
 
-		// Get column name from db tag.
-		dbName := field.Name
-		if tag, ok := field.Tag.Lookup("db"); ok {
-			// Parse db tag: "column" or "column,pk" or "-"
-			column, _ := parseDBTag(tag)
-			if column == "-" {
-				continue // Skip db:"-" fields.
-			}
-			dbName = column
+		dbName := ColumnName(field)
+		if dbName == "-" {
+			continue // Skip db:"-" fields.
 		}
 
 		// Get field value.
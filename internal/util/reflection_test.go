@@ -2,6 +2,7 @@ package util
 
 import (
 	"database/sql"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -281,3 +282,19 @@ func TestStructToMap_NullTypes(t *testing.T) {
 		t.Errorf("age = %v, want sql.NullInt64{Int64: 25, Valid: true}", result["age"])
 	}
 }
+
+func TestColumnName(t *testing.T) {
+	typ := reflect.TypeOf(struct {
+		ID       int    `db:"id,pk"`
+		Name     string `db:"full_name"`
+		Password string `db:"-"`
+		Email    string
+	}{})
+
+	want := []string{"id", "full_name", "-", "Email"}
+	for i, w := range want {
+		if got := ColumnName(typ.Field(i)); got != w {
+			t.Errorf("ColumnName(%s) = %q, want %q", typ.Field(i).Name, got, w)
+		}
+	}
+}
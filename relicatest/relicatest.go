@@ -0,0 +1,45 @@
+// Package relicatest provides helpers for testing code that uses Relica.
+package relicatest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/coregx/relica"
+)
+
+// NewRollbackDB begins a transaction on db and returns a handle whose queries
+// all run inside it. The transaction is rolled back when the test finishes, so
+// tests can write freely without re-creating the schema or cleaning up rows.
+//
+// Begin and Transactional on the returned handle map to savepoints: a nested
+// transaction that commits stays visible for the rest of the test, one that
+// fails is rolled back to its savepoint.
+//
+// The handle uses a single connection. Do not share it between parallel
+// tests; give each test its own NewRollbackDB instead.
+//
+// Example:
+//
+//	func TestCreateUser(t *testing.T) {
+//	    db := relicatest.NewRollbackDB(t, sharedDB)
+//	    svc := NewUserService(db)
+//	    require.NoError(t, svc.Create(ctx, "alice"))
+//	    // the insert is rolled back after the test
+//	}
+func NewRollbackDB(t testing.TB, db *relica.DB) *relica.DB {
+	t.Helper()
+
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("relicatest: begin transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			t.Errorf("relicatest: roll back transaction: %v", err)
+		}
+	})
+	return tx.DB()
+}
@@ -0,0 +1,96 @@
+package relicatest_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/coregx/relica/relicatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *relica.DB {
+	t.Helper()
+	db, err := relica.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	return db
+}
+
+func countUsers(t *testing.T, db *relica.DB) int {
+	t.Helper()
+	var n int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM users").Row(&n))
+	return n
+}
+
+func TestNewRollbackDB(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	t.Run("writes", func(t *testing.T) {
+		tdb := relicatest.NewRollbackDB(t, db)
+
+		_, err := tdb.Insert("users", map[string]interface{}{"name": "alice"}).Execute()
+		require.NoError(t, err)
+		_, err = tdb.ExecContext(ctx, "INSERT INTO users (name) VALUES (?)", "bob")
+		require.NoError(t, err)
+		assert.Equal(t, 2, countUsers(t, tdb))
+
+		// A committed nested transaction stays visible until the test ends.
+		err = tdb.Transactional(ctx, func(tx *relica.Tx) error {
+			_, err := tx.Insert("users", map[string]interface{}{"name": "carol"}).Execute()
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, countUsers(t, tdb))
+
+		// A failed nested transaction rolls back to its savepoint only.
+		errBoom := errors.New("boom")
+		err = tdb.Transactional(ctx, func(tx *relica.Tx) error {
+			if _, err := tx.Delete("users").Execute(); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		require.ErrorIs(t, err, errBoom)
+		assert.Equal(t, 3, countUsers(t, tdb))
+
+		require.NoError(t, tdb.Close())
+	})
+
+	assert.Equal(t, 0, countUsers(t, db), "writes must be rolled back after the test")
+}
+
+func TestNewRollbackDB_BeginFails(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.Close())
+
+	ft := &fatalRecorder{T: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relicatest.NewRollbackDB(ft, db)
+	}()
+	<-done
+	assert.True(t, ft.fatal)
+}
+
+// fatalRecorder records Fatalf calls instead of failing the test.
+type fatalRecorder struct {
+	*testing.T
+	fatal bool
+}
+
+func (r *fatalRecorder) Fatalf(string, ...interface{}) {
+	r.fatal = true
+	runtime.Goexit()
+}
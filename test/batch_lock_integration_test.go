@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBatchUpdateLockOrdered_PostgreSQL runs workers that batch-update the same
+// rows, added in opposite orders; locking in key order keeps them deadlock-free.
+func TestBatchUpdateLockOrdered_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	testBatchUpdateLockOrdered(t, ds.DB, "CREATE TABLE batch_lock_test (id INT PRIMARY KEY, hits INT NOT NULL)")
+}
+
+// TestBatchUpdateLockOrdered_MySQL is the MySQL variant of the test above.
+func TestBatchUpdateLockOrdered_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+	testBatchUpdateLockOrdered(t, ds.DB, "CREATE TABLE batch_lock_test (id INT PRIMARY KEY, hits INT NOT NULL) ENGINE=InnoDB")
+}
+
+func testBatchUpdateLockOrdered(t *testing.T, db *relica.DB, ddl string) {
+	ctx := context.Background()
+	const rows, workers, rounds = 20, 4, 10
+	_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS batch_lock_test")
+	_, err := db.ExecContext(ctx, ddl)
+	require.NoError(t, err)
+	defer db.ExecContext(ctx, "DROP TABLE IF EXISTS batch_lock_test") //nolint:errcheck
+
+	insert := db.Builder().BatchInsert("batch_lock_test", []string{"id", "hits"})
+	for id := 1; id <= rows; id++ {
+		insert.Values(id, 0)
+	}
+	_, err = insert.Execute()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				batch := db.Builder().BatchUpdate("batch_lock_test", "id").LockOrdered()
+				for i := 1; i <= rows; i++ {
+					id := i
+					if w%2 == 1 {
+						id = rows + 1 - i // odd workers add keys in descending order
+					}
+					batch.Set(id, map[string]interface{}{"hits": w*rounds + r})
+				}
+				if _, err := batch.Execute(); !assert.NoError(t, err) {
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Batches are serialized, so every row holds the values of the last one.
+	var hits []int
+	require.NoError(t, db.NewQuery("SELECT hits FROM batch_lock_test ORDER BY id").Column(&hits))
+	require.Len(t, hits, rows)
+	for _, h := range hits {
+		assert.Equal(t, hits[0], h)
+	}
+}
@@ -0,0 +1,97 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeferConstraints_PostgreSQL inserts a child before its parent with the
+// DEFERRABLE foreign key checked at commit.
+func TestDeferConstraints_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS dc_children",
+		"DROP TABLE IF EXISTS dc_parents",
+		"CREATE TABLE dc_parents (id INT PRIMARY KEY)",
+		"CREATE TABLE dc_children (id INT PRIMARY KEY, parent_id INT NOT NULL REFERENCES dc_parents (id) DEFERRABLE INITIALLY IMMEDIATE)",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS dc_children")
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS dc_parents")
+	}()
+
+	err := db.Transactional(ctx, func(tx *relica.Tx) error {
+		if err := tx.DeferConstraints(); err != nil {
+			return err
+		}
+		if _, err := tx.Insert("dc_children", map[string]interface{}{"id": 1, "parent_id": 1}).Execute(); err != nil {
+			return err
+		}
+		_, err := tx.Insert("dc_parents", map[string]interface{}{"id": 1}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		if err := tx.DeferConstraints(); err != nil {
+			return err
+		}
+		_, err := tx.Insert("dc_children", map[string]interface{}{"id": 2, "parent_id": 99}).Execute()
+		return err
+	})
+	assert.ErrorIs(t, err, relica.ErrForeignKeyViolation, "checked at commit")
+}
+
+// TestDisableForeignKeys_MySQL loads a child without its parent and checks
+// that foreign_key_checks is restored on the connection afterwards.
+func TestDisableForeignKeys_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS dc_children",
+		"DROP TABLE IF EXISTS dc_parents",
+		"CREATE TABLE dc_parents (id INT PRIMARY KEY) ENGINE=InnoDB",
+		"CREATE TABLE dc_children (id INT PRIMARY KEY, parent_id INT NOT NULL, FOREIGN KEY (parent_id) REFERENCES dc_parents (id)) ENGINE=InnoDB",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS dc_children")
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS dc_parents")
+	}()
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.Transactional(ctx, func(tx *relica.Tx) error {
+		if err := tx.DisableForeignKeys(); err != nil {
+			return err
+		}
+		_, err := tx.Insert("dc_children", map[string]interface{}{"id": 1, "parent_id": 99}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+
+	var checks int
+	require.NoError(t, conn.NewQuery("SELECT @@SESSION.foreign_key_checks").Row(&checks))
+	assert.Equal(t, 1, checks, "restored on commit")
+}
@@ -0,0 +1,67 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCoordinator_PostgreSQL commits a coordinated transaction over two
+// handles of the same server. With prepared transactions enabled both
+// participants go through PREPARE TRANSACTION / COMMIT PREPARED; otherwise
+// they are committed in order.
+func TestCoordinator_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS co_orders",
+		"DROP TABLE IF EXISTS co_invoices",
+		"CREATE TABLE co_orders (id INT PRIMARY KEY)",
+		"CREATE TABLE co_invoices (id INT PRIMARY KEY, order_id INT NOT NULL)",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS co_invoices")
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS co_orders")
+	}()
+
+	err := relica.Coordinator(db, db).Transactional(ctx, func(ct *relica.CoordinatedTx) error {
+		if _, err := ct.Tx(0).Insert("co_orders", map[string]interface{}{"id": 1}).Execute(); err != nil {
+			return err
+		}
+		_, err := ct.Tx(1).Insert("co_invoices", map[string]interface{}{"id": 10, "order_id": 1}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+
+	var orders, invoices, prepared int
+	require.NoError(t, db.Select("COUNT(*)").From("co_orders").Row(&orders))
+	require.NoError(t, db.Select("COUNT(*)").From("co_invoices").Row(&invoices))
+	require.NoError(t, db.Select("COUNT(*)").From("pg_prepared_xacts").Where("gid LIKE 'relica_%'").Row(&prepared))
+	assert.Equal(t, 1, orders)
+	assert.Equal(t, 1, invoices)
+	assert.Zero(t, prepared, "no prepared transaction is left behind")
+
+	// A failing participant rolls back the other one.
+	err = relica.Coordinator(db, db).Transactional(ctx, func(ct *relica.CoordinatedTx) error {
+		if _, err := ct.Tx(0).Insert("co_orders", map[string]interface{}{"id": 2}).Execute(); err != nil {
+			return err
+		}
+		_, err := ct.Tx(1).Insert("co_invoices", map[string]interface{}{"id": 10, "order_id": 2}).Execute()
+		return err
+	})
+	require.Error(t, err)
+	require.NoError(t, db.Select("COUNT(*)").From("co_orders").Row(&orders))
+	assert.Equal(t, 1, orders)
+}
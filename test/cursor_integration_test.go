@@ -0,0 +1,68 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithCursor_PostgreSQL reads results through DECLARE CURSOR / FETCH.
+func TestWithCursor_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	CreateUsersTable(t, ds.DB, ds.Dialect)
+	InsertTestUsers(t, ds.DB, 25)
+
+	type user struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	t.Run("All", func(t *testing.T) {
+		var users []user
+		err := ds.DB.Select("id", "name").From("users").Where("age > ?", 0).OrderBy("id").WithCursor(10).All(&users)
+		require.NoError(t, err)
+		require.Len(t, users, 25)
+		assert.Equal(t, "User1", users[0].Name)
+		assert.Equal(t, "User25", users[24].Name)
+	})
+
+	t.Run("Each", func(t *testing.T) {
+		var u user
+		var count int
+		err := ds.DB.Select("id", "name").From("users").WithCursor(5).Each(&u, func() error {
+			count++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 25, count)
+
+		errStop := errors.New("stop")
+		err = ds.DB.Select("id", "name").From("users").WithCursor(5).Each(&u, func() error {
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+	})
+
+	t.Run("InTransaction", func(t *testing.T) {
+		err := ds.DB.Transactional(ctx, func(tx *relica.Tx) error {
+			var ids []int
+			err := relica.QTx[user](tx).WithCursor(7).Each(ctx, func(u user) error {
+				ids = append(ids, u.ID)
+				return nil
+			})
+			assert.Len(t, ids, 25)
+			return err
+		})
+		require.NoError(t, err)
+	})
+}
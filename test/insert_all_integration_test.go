@@ -0,0 +1,52 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type insertAllRow struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+// TestInsertAll_PostgreSQL checks that RETURNING populates the IDs of every row.
+func TestInsertAll_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	testInsertAll(t, ds.DB, "CREATE TABLE insert_all_test (id SERIAL PRIMARY KEY, name TEXT NOT NULL)")
+}
+
+// TestInsertAll_MySQL checks that LastInsertId populates the IDs of every row.
+func TestInsertAll_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+	testInsertAll(t, ds.DB, "CREATE TABLE insert_all_test (id BIGINT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(50) NOT NULL) ENGINE=InnoDB")
+}
+
+func testInsertAll(t *testing.T, db *relica.DB, ddl string) {
+	ctx := context.Background()
+	_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS insert_all_test")
+	_, err := db.ExecContext(ctx, ddl)
+	require.NoError(t, err)
+	defer db.ExecContext(ctx, "DROP TABLE IF EXISTS insert_all_test") //nolint:errcheck
+
+	rows := make([]insertAllRow, 7)
+	for i := range rows {
+		rows[i].Name = string(rune('a' + i))
+	}
+	n, err := db.InsertAll("insert_all_test", rows).BatchSize(3).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(rows)), n)
+
+	var stored []insertAllRow
+	require.NoError(t, db.Select().From("insert_all_test").OrderBy("id").All(&stored))
+	assert.Equal(t, stored, rows)
+}
@@ -0,0 +1,56 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJoinSelect_PostgreSQL checks placeholder numbering across FROM, JOIN
+// subqueries, JOIN ON expressions and WHERE against a real server.
+func TestJoinSelect_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS js_orders",
+		"DROP TABLE IF EXISTS js_users",
+		"CREATE TABLE js_users (id SERIAL PRIMARY KEY, name TEXT, active BOOLEAN)",
+		"CREATE TABLE js_orders (id SERIAL PRIMARY KEY, user_id INT, total INT, status TEXT)",
+		"INSERT INTO js_users (name, active) VALUES ('ann', true), ('bob', true), ('carl', false)",
+		"INSERT INTO js_orders (user_id, total, status) VALUES (1, 10, 'paid'), (1, 5, 'paid'), (2, 7, 'open'), (3, 50, 'paid')",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS js_orders")
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS js_users")
+	}()
+
+	active := db.Select("id", "name").From("js_users").Where("active = ?", true)
+	totals := db.Select("user_id", "SUM(total) AS spent").From("js_orders").
+		Where("status = ?", "paid").GroupBy("user_id")
+
+	var rows []struct {
+		Name  string `db:"name"`
+		Spent int    `db:"spent"`
+	}
+	err := db.Select("u.name", "t.spent").
+		FromSelect(active, "u").
+		InnerJoinSelect(totals, "t", relica.And(relica.EqCol("t.user_id", "u.id"), relica.GreaterThan("t.spent", 1))).
+		Where("u.name <> ?", "bob").
+		All(&rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "ann", rows[0].Name)
+	assert.Equal(t, 15, rows[0].Spent)
+}
@@ -0,0 +1,54 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdvisoryLock_PostgreSQL checks pg_advisory_lock based locking across sessions.
+func TestAdvisoryLock_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	testAdvisoryLock(t, ds.DB)
+}
+
+// TestAdvisoryLock_MySQL checks GET_LOCK based locking across sessions.
+func TestAdvisoryLock_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+	testAdvisoryLock(t, ds.DB)
+}
+
+func testAdvisoryLock(t *testing.T, db *relica.DB) {
+	ctx := context.Background()
+
+	lock, err := db.TryAdvisoryLock(ctx, "relica-test-leader")
+	require.NoError(t, err)
+
+	_, err = db.TryAdvisoryLock(ctx, "relica-test-leader")
+	assert.ErrorIs(t, err, relica.ErrLockHeld, "another session cannot take the lock")
+
+	timeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = db.AdvisoryLock(timeout, "relica-test-leader")
+	assert.Error(t, err, "waiting stops at the context deadline")
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = lock.Unlock()
+		close(released)
+	}()
+	next, err := db.AdvisoryLock(ctx, "relica-test-leader")
+	require.NoError(t, err)
+	<-released
+	require.NoError(t, next.Unlock())
+}
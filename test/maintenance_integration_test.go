@@ -0,0 +1,52 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaintenance_PostgreSQL runs ANALYZE, VACUUM [FULL] and VACUUM (FULL, ANALYZE).
+func TestMaintenance_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	_, _ = ds.DB.ExecContext(ctx, `DROP TABLE IF EXISTS relica_maintenance`)
+	_, err := ds.DB.ExecContext(ctx, `CREATE TABLE relica_maintenance (id SERIAL PRIMARY KEY, note TEXT)`)
+	require.NoError(t, err)
+	defer ds.DB.ExecContext(ctx, `DROP TABLE IF EXISTS relica_maintenance`)
+
+	testMaintenance(t, ds.DB)
+}
+
+// TestMaintenance_MySQL runs ANALYZE TABLE and OPTIMIZE TABLE.
+func TestMaintenance_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	_, _ = ds.DB.ExecContext(ctx, "DROP TABLE IF EXISTS relica_maintenance")
+	_, err := ds.DB.ExecContext(ctx, "CREATE TABLE relica_maintenance (id INT AUTO_INCREMENT PRIMARY KEY, note TEXT)")
+	require.NoError(t, err)
+	defer ds.DB.ExecContext(ctx, "DROP TABLE IF EXISTS relica_maintenance")
+
+	testMaintenance(t, ds.DB)
+}
+
+func testMaintenance(t *testing.T, db *relica.DB) {
+	ctx := context.Background()
+	m := db.Maintenance()
+	require.NoError(t, m.Analyze(ctx, "relica_maintenance"))
+	require.NoError(t, m.Vacuum(ctx, "relica_maintenance"))
+	require.NoError(t, m.Vacuum(ctx, "relica_maintenance", relica.Full()))
+	require.NoError(t, m.OptimizeTable(ctx, "relica_maintenance"))
+
+	assert.Error(t, m.Analyze(ctx, "relica_maintenance_missing"), "a missing table is reported")
+}
@@ -0,0 +1,51 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaterializedView_PostgreSQL creates, refreshes and drops a rollup view.
+func TestMaterializedView_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	_, _ = db.ExecContext(ctx, "DROP MATERIALIZED VIEW IF EXISTS matview_totals")
+	_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS matview_orders")
+	_, err := db.ExecContext(ctx, "CREATE TABLE matview_orders (id SERIAL PRIMARY KEY, customer TEXT NOT NULL, total INT NOT NULL)")
+	require.NoError(t, err)
+	defer db.ExecContext(ctx, "DROP TABLE IF EXISTS matview_orders") //nolint:errcheck
+	_, err = db.ExecContext(ctx, "INSERT INTO matview_orders (customer, total) VALUES ('ann', 10), ('ann', 5), ('bob', 7)")
+	require.NoError(t, err)
+
+	totals := db.Select("customer", "SUM(total) AS total").From("matview_orders").GroupBy("customer")
+	require.NoError(t, db.Schema().CreateMaterializedView(ctx, "matview_totals", totals))
+	defer db.Schema().DropMaterializedView(ctx, "matview_totals") //nolint:errcheck
+	_, err = db.ExecContext(ctx, "CREATE UNIQUE INDEX matview_totals_customer ON matview_totals (customer)")
+	require.NoError(t, err)
+
+	sum := func() int {
+		var n int
+		require.NoError(t, db.NewQuery("SELECT total FROM matview_totals WHERE customer = 'ann'").Row(&n))
+		return n
+	}
+	assert.Equal(t, 15, sum())
+
+	_, err = db.ExecContext(ctx, "INSERT INTO matview_orders (customer, total) VALUES ('ann', 20)")
+	require.NoError(t, err)
+	assert.Equal(t, 15, sum(), "stale until refreshed")
+	require.NoError(t, db.RefreshMaterializedView(ctx, "matview_totals", relica.Concurrently()))
+	assert.Equal(t, 35, sum())
+
+	// Creating an existing view is a no-op
+	require.NoError(t, db.Schema().CreateMaterializedView(ctx, "matview_totals", totals))
+}
@@ -0,0 +1,60 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMerge_PostgreSQL runs a MERGE with conditional WHEN clauses against a
+// real server (PostgreSQL 15+).
+func TestMerge_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS mg_staging",
+		"DROP TABLE IF EXISTS mg_users",
+		"CREATE TABLE mg_users (id INT PRIMARY KEY, name TEXT, synced BOOLEAN DEFAULT false)",
+		"CREATE TABLE mg_staging (id INT, name TEXT, deleted BOOLEAN, batch INT)",
+		"INSERT INTO mg_users (id, name) VALUES (1, 'ann'), (2, 'bob'), (4, 'dora')",
+		"INSERT INTO mg_staging (id, name, deleted, batch) VALUES (1, 'Ann', false, 7), (2, 'bob', true, 7), (3, 'carl', false, 7), (4, 'Dora', false, 8)",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS mg_staging")
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS mg_users")
+	}()
+
+	staged := db.Select("id", "name", "deleted").From("mg_staging").Where(relica.Eq("batch", 7))
+	_, err := db.Merge("mg_users u").
+		Using(staged, "s", "u.id = s.id").
+		WhenMatchedDelete().And("s.deleted = ?", true).
+		WhenMatchedUpdate(map[string]interface{}{"name": relica.NewExp("s.name"), "synced": true}).
+		WhenNotMatchedInsert(map[string]interface{}{"id": relica.NewExp("s.id"), "name": relica.NewExp("s.name"), "synced": true}).
+		Execute()
+	require.NoError(t, err)
+
+	var rows []struct {
+		ID     int    `db:"id"`
+		Name   string `db:"name"`
+		Synced bool   `db:"synced"`
+	}
+	require.NoError(t, db.Select("id", "name", "synced").From("mg_users").OrderBy("id").All(&rows))
+	require.Len(t, rows, 3)
+	assert.Equal(t, "Ann", rows[0].Name)
+	assert.True(t, rows[0].Synced)
+	assert.Equal(t, 3, rows[1].ID)
+	assert.Equal(t, "dora", rows[2].Name)
+	assert.False(t, rows[2].Synced)
+}
@@ -0,0 +1,77 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lockCounter struct {
+	ID   int64 `db:"id"`
+	Hits int   `db:"hits"`
+}
+
+func (lockCounter) TableName() string { return "model_lock_test" }
+
+// TestModelLockForUpdate_PostgreSQL runs concurrent read-modify-write
+// increments; the row lock serializes them, so no update is lost.
+func TestModelLockForUpdate_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	testModelLockForUpdate(t, ds.DB, "CREATE TABLE model_lock_test (id INT PRIMARY KEY, hits INT NOT NULL)")
+}
+
+// TestModelLockForUpdate_MySQL is the MySQL variant of the test above.
+func TestModelLockForUpdate_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+	testModelLockForUpdate(t, ds.DB, "CREATE TABLE model_lock_test (id INT PRIMARY KEY, hits INT NOT NULL) ENGINE=InnoDB")
+}
+
+func testModelLockForUpdate(t *testing.T, db *relica.DB, ddl string) {
+	ctx := context.Background()
+	const workers, rounds = 4, 10
+	_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS model_lock_test")
+	_, err := db.ExecContext(ctx, ddl)
+	require.NoError(t, err)
+	defer db.ExecContext(ctx, "DROP TABLE IF EXISTS model_lock_test") //nolint:errcheck
+	require.NoError(t, db.Model(&lockCounter{ID: 1}).Insert())
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				err := db.Transactional(ctx, func(tx *relica.Tx) error {
+					c := lockCounter{ID: 1}
+					if err := tx.Model(&c).LockForUpdate().One(); err != nil {
+						return err
+					}
+					c.Hits++
+					return tx.Model(&c).Update("hits")
+				})
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	c := lockCounter{ID: 1}
+	require.NoError(t, db.Model(&c).One())
+	assert.Equal(t, workers*rounds, c.Hits)
+
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		return tx.Model(&c).LockForShare().One()
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOutboxRelay_PostgreSQL runs concurrent relays against one outbox table;
+// FOR UPDATE SKIP LOCKED hands every message to exactly one of them.
+func TestOutboxRelay_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	const table = "outbox_relay_test"
+	_, _ = ds.DB.ExecContext(ctx, "DROP TABLE IF EXISTS "+table)
+	require.NoError(t, ds.DB.CreateOutboxTable(ctx, table))
+	defer ds.DB.ExecContext(ctx, "DROP TABLE IF EXISTS "+table) //nolint:errcheck
+
+	err := ds.DB.Transactional(ctx, func(tx *relica.Tx) error {
+		for i := 0; i < 50; i++ {
+			if err := tx.Outbox().Table(table).Publish("tick", map[string]int{"n": i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	seen := make(map[int64]int)
+	handler := func(_ context.Context, msg relica.OutboxMessage) error {
+		mu.Lock()
+		seen[msg.ID]++
+		mu.Unlock()
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay := ds.DB.OutboxRelay(handler).Table(table).BatchSize(5)
+			for {
+				n, err := relay.Poll(ctx)
+				if !assert.NoError(t, err) || n == 0 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, 50)
+	for id, n := range seen {
+		assert.Equal(t, 1, n, "message %d delivered more than once", id)
+	}
+}
@@ -0,0 +1,61 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipeline_PostgreSQL sends independent writes as one statement of
+// data-modifying CTEs and checks the per-statement affected rows.
+func TestPipeline_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS pl_events",
+		"DROP TABLE IF EXISTS pl_counters",
+		"CREATE TABLE pl_events (id SERIAL PRIMARY KEY, name TEXT UNIQUE NOT NULL)",
+		"CREATE TABLE pl_counters (id INT PRIMARY KEY, n INT NOT NULL)",
+		"INSERT INTO pl_counters (id, n) VALUES (1, 0), (2, 0)",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS pl_events")
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS pl_counters")
+	}()
+
+	affected, err := db.Pipeline(ctx, func(p *relica.Pipeline) {
+		for _, name := range []string{"a", "b", "c"} {
+			p.Queue(db.Insert("pl_events", map[string]interface{}{"name": name}))
+		}
+		p.Queue(db.Update("pl_counters").Set(map[string]interface{}{"n": 3}).Where(relica.Eq("id", 1)).Build())
+		p.Queue(db.Delete("pl_counters").Where(relica.Eq("id", 99)).Build())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 1, 1, 1, 0}, affected)
+
+	var n int
+	require.NoError(t, db.Select("n").From("pl_counters").Where(relica.Eq("id", 1)).Row(&n))
+	assert.Equal(t, 3, n)
+
+	// The combined statement is atomic.
+	_, err = db.Pipeline(ctx, func(p *relica.Pipeline) {
+		p.Queue(db.Update("pl_counters").Set(map[string]interface{}{"n": 9}).Where(relica.Eq("id", 2)).Build())
+		p.Queue(db.Insert("pl_events", map[string]interface{}{"name": "a"}))
+	})
+	require.True(t, errors.Is(err, relica.ErrUniqueViolation), "got %v", err)
+	require.NoError(t, db.Select("n").From("pl_counters").Where(relica.Eq("id", 2)).Row(&n))
+	assert.Zero(t, n)
+}
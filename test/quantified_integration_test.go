@@ -0,0 +1,61 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuantifiedSubqueries_PostgreSQL runs ANY/ALL comparisons mixed with
+// other parameters against a real server.
+func TestQuantifiedSubqueries_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS qa_players",
+		"CREATE TABLE qa_players (id SERIAL PRIMARY KEY, name TEXT, team TEXT, score INT)",
+		"INSERT INTO qa_players (name, team, score) VALUES ('ann', 'red', 10), ('bob', 'red', 20), ('carl', 'blue', 25), ('dora', 'blue', 15)",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS qa_players")
+	}()
+
+	red := db.Select("score").From("qa_players").Where(relica.Eq("team", "red"))
+
+	var names []string
+	err := db.Select("name").From("qa_players").
+		Where(relica.Eq("team", "blue")).
+		Where(relica.GtAll("score", red)).
+		Column(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carl"}, names)
+
+	names = nil
+	err = db.Select("name").From("qa_players").
+		Where(relica.Eq("team", "blue")).
+		Where(relica.GtAny("score", red)).
+		OrderBy("id").
+		Column(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carl", "dora"}, names)
+
+	names = nil
+	err = db.Select("name").From("qa_players").
+		Where(relica.EqAny("score", red)).
+		OrderBy("id").
+		Column(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ann", "bob"}, names)
+}
@@ -0,0 +1,45 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNextSequence_PostgreSQL uses a native sequence.
+func TestNextSequence_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	_, _ = ds.DB.ExecContext(ctx, `DROP SEQUENCE IF EXISTS "relica_test_seq"`)
+	testNextSequence(t, ds.DB)
+}
+
+// TestNextSequence_MySQL uses the relica_sequences emulation table.
+func TestNextSequence_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	_, _ = ds.DB.ExecContext(ctx, "DROP TABLE IF EXISTS relica_sequences")
+	testNextSequence(t, ds.DB)
+}
+
+func testNextSequence(t *testing.T, db *relica.DB) {
+	ctx := context.Background()
+	require.NoError(t, db.CreateSequence(ctx, "relica_test_seq"))
+	require.NoError(t, db.CreateSequence(ctx, "relica_test_seq"), "creating an existing sequence is a no-op")
+
+	for want := int64(1); want <= 3; want++ {
+		n, err := db.NextSequence(ctx, "relica_test_seq")
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+}
@@ -0,0 +1,67 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTableStats_PostgreSQL reads pg_class and pg_stat_user_tables.
+func TestTableStats_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	_, _ = ds.DB.ExecContext(ctx, `DROP TABLE IF EXISTS relica_stats`)
+	_, err := ds.DB.ExecContext(ctx, `CREATE TABLE relica_stats (id SERIAL PRIMARY KEY, note TEXT)`)
+	require.NoError(t, err)
+	defer ds.DB.ExecContext(ctx, `DROP TABLE IF EXISTS relica_stats`)
+
+	testTableStats(t, ds.DB, "ANALYZE relica_stats")
+
+	stats, err := ds.DB.TableStats(ctx, "public.relica_stats")
+	require.NoError(t, err)
+	assert.False(t, stats.LastAnalyze.IsZero(), "ANALYZE time is recorded")
+}
+
+// TestTableStats_MySQL reads information_schema.TABLES.
+func TestTableStats_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+
+	ctx := context.Background()
+	_, _ = ds.DB.ExecContext(ctx, "DROP TABLE IF EXISTS relica_stats")
+	_, err := ds.DB.ExecContext(ctx, "CREATE TABLE relica_stats (id INT AUTO_INCREMENT PRIMARY KEY, note TEXT, KEY note_idx (note(10)))")
+	require.NoError(t, err)
+	defer ds.DB.ExecContext(ctx, "DROP TABLE IF EXISTS relica_stats")
+
+	testTableStats(t, ds.DB, "ANALYZE TABLE relica_stats")
+}
+
+func testTableStats(t *testing.T, db *relica.DB, analyze string) {
+	ctx := context.Background()
+	bq := db.BatchInsert("relica_stats", []string{"note"})
+	for i := 0; i < 100; i++ {
+		bq.Values("note")
+	}
+	_, err := bq.Execute()
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, analyze)
+	require.NoError(t, err)
+
+	stats, err := db.TableStats(ctx, "relica_stats")
+	require.NoError(t, err)
+	assert.Positive(t, stats.Rows)
+	assert.Positive(t, stats.TableBytes)
+	assert.Positive(t, stats.IndexBytes)
+	assert.Equal(t, stats.TableBytes+stats.IndexBytes, stats.TotalBytes)
+
+	_, err = db.TableStats(ctx, "relica_stats_missing")
+	assert.Error(t, err)
+}
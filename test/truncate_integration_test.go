@@ -0,0 +1,66 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTruncate_PostgreSQL truncates a parent table with CASCADE and
+// RESTART IDENTITY.
+func TestTruncate_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS truncate_items, truncate_orders")
+	_, err := db.ExecContext(ctx, "CREATE TABLE truncate_orders (id SERIAL PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "CREATE TABLE truncate_items (id SERIAL PRIMARY KEY, order_id INT REFERENCES truncate_orders (id))")
+	require.NoError(t, err)
+	defer db.ExecContext(ctx, "DROP TABLE IF EXISTS truncate_items, truncate_orders") //nolint:errcheck
+	_, err = db.ExecContext(ctx, "INSERT INTO truncate_orders (name) VALUES ('a'), ('b')")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO truncate_items (order_id) VALUES (1), (2)")
+	require.NoError(t, err)
+
+	// Referenced by truncate_items, so a plain TRUNCATE fails
+	assert.Error(t, db.Truncate("truncate_orders").Execute())
+
+	require.NoError(t, db.Truncate("truncate_orders").Cascade().RestartIdentity().Execute())
+	count, err := db.Select().From("truncate_items").Count()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	var id int
+	require.NoError(t, db.NewQuery("INSERT INTO truncate_orders (name) VALUES ('c') RETURNING id").Row(&id))
+	assert.Equal(t, 1, id)
+}
+
+// TestTruncate_MySQL truncates a table, which always resets AUTO_INCREMENT.
+func TestTruncate_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS truncate_events")
+	_, err := db.ExecContext(ctx, "CREATE TABLE truncate_events (id INT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(20))")
+	require.NoError(t, err)
+	defer db.ExecContext(ctx, "DROP TABLE IF EXISTS truncate_events") //nolint:errcheck
+	_, err = db.ExecContext(ctx, "INSERT INTO truncate_events (name) VALUES ('a'), ('b')")
+	require.NoError(t, err)
+
+	require.NoError(t, db.Truncate("truncate_events").Execute())
+	result, err := db.ExecContext(ctx, "INSERT INTO truncate_events (name) VALUES ('c')")
+	require.NoError(t, err)
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
@@ -0,0 +1,52 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpsertConflictOptions_PostgreSQL runs OnConstraint, DoUpdateExpr and
+// Where against a real server.
+func TestUpsertConflictOptions_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS uc_counters",
+		"CREATE TABLE uc_counters (id SERIAL PRIMARY KEY, name TEXT CONSTRAINT uc_counters_name_key UNIQUE, count INT, locked BOOLEAN DEFAULT false)",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS uc_counters")
+	}()
+
+	bump := func(name string, n int) {
+		_, err := db.Upsert("uc_counters", map[string]interface{}{"name": name, "count": n}).
+			OnConstraint("uc_counters_name_key").
+			DoUpdateExpr("count", "uc_counters.count + EXCLUDED.count + ?", 100).
+			Where(relica.Eq("uc_counters.locked", false)).
+			Execute()
+		require.NoError(t, err)
+	}
+	bump("hits", 1)
+	bump("hits", 2)
+	bump("misses", 4)
+	_, err := db.ExecContext(ctx, "UPDATE uc_counters SET locked = true WHERE name = 'misses'")
+	require.NoError(t, err)
+	bump("misses", 4)
+
+	var counts []int
+	require.NoError(t, db.Select("count").From("uc_counters").OrderBy("name").Column(&counts))
+	assert.Equal(t, []int{103, 4}, counts)
+}
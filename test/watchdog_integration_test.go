@@ -0,0 +1,99 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coregx/relica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestActiveQueries_PostgreSQL lists a running pg_sleep and cancels it.
+func TestActiveQueries_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ds.DB.NewQuery("SELECT pg_sleep(30)").Execute()
+		done <- err
+	}()
+
+	pid := waitForActiveQuery(t, ds.DB, "pg_sleep")
+	require.NoError(t, ds.DB.CancelQuery(context.Background(), pid))
+
+	select {
+	case err := <-done:
+		assert.Error(t, err, "the canceled statement fails")
+	case <-time.After(10 * time.Second):
+		t.Fatal("query was not canceled")
+	}
+	assert.Error(t, ds.DB.CancelQuery(context.Background(), 0), "no session has pid 0")
+}
+
+// TestActiveQueries_MySQL lists a running SLEEP and kills it.
+func TestActiveQueries_MySQL(t *testing.T) {
+	ds := SetupMySQLTestDB(t)
+	defer ds.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		var slept int
+		done <- ds.DB.NewQuery("SELECT SLEEP(30)").Row(&slept)
+	}()
+
+	pid := waitForActiveQuery(t, ds.DB, "SLEEP")
+	require.NoError(t, ds.DB.CancelQuery(context.Background(), pid))
+
+	select {
+	case <-done: // an interrupted SLEEP returns 1 rather than failing
+	case <-time.After(10 * time.Second):
+		t.Fatal("query was not killed")
+	}
+}
+
+// TestQueryWatchdog_PostgreSQL cancels a query without a deadline on the server.
+func TestQueryWatchdog_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+
+	db, err := relica.Open("postgres", getDSN(t, ds, "PostgreSQL"), relica.WithQueryWatchdog(200*time.Millisecond))
+	require.NoError(t, err)
+	defer db.Close()
+
+	start := time.Now()
+	_, err = db.NewQuery("SELECT pg_sleep(30)").Execute()
+	require.ErrorIs(t, err, relica.ErrQueryWatchdog)
+	assert.ErrorIs(t, err, relica.ErrTimeout)
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	var one int
+	require.NoError(t, db.NewQuery("SELECT 1").Row(&one), "the pool stays usable")
+}
+
+// waitForActiveQuery polls ActiveQueries until a statement containing text
+// runs and returns its process ID.
+func waitForActiveQuery(t *testing.T, db *relica.DB, text string) int64 {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		queries, err := db.ActiveQueries(context.Background())
+		require.NoError(t, err)
+		for _, q := range queries {
+			if strings.Contains(q.Query, text) {
+				assert.Positive(t, q.PID)
+				assert.False(t, q.Started.IsZero())
+				return q.PID
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("no active query contains %q", text)
+	return 0
+}
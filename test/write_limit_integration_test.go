@@ -0,0 +1,54 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeleteLimit_PostgreSQL deletes expired rows in batches through the
+// ctid subquery that stands in for DELETE ... LIMIT.
+func TestDeleteLimit_PostgreSQL(t *testing.T) {
+	ds := SetupPostgreSQLTestDB(t)
+	defer ds.Close()
+	db := ds.DB
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS wl_sessions",
+		"CREATE TABLE wl_sessions (id SERIAL PRIMARY KEY, expires_at INT NOT NULL)",
+		"INSERT INTO wl_sessions (expires_at) SELECT g FROM generate_series(1, 25) AS g",
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	defer func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS wl_sessions")
+	}()
+
+	var batches []int64
+	for {
+		var ids []int
+		err := db.Delete("wl_sessions").Where("expires_at <= ?", 20).
+			OrderBy("expires_at").Limit(8).Returning("id").Build().Column(&ids)
+		require.NoError(t, err)
+		if len(ids) == 0 {
+			break
+		}
+		batches = append(batches, int64(len(ids)))
+	}
+	assert.Equal(t, []int64{8, 8, 4}, batches)
+
+	_, err := db.Update("wl_sessions").Set(map[string]interface{}{"expires_at": 0}).
+		Where("expires_at > ?", 20).OrderBy("expires_at DESC").Limit(2).Execute()
+	require.NoError(t, err)
+
+	var remaining []int
+	require.NoError(t, db.Select("expires_at").From("wl_sessions").OrderBy("expires_at").Column(&remaining))
+	assert.Equal(t, []int{0, 0, 21, 22, 23}, remaining)
+}
@@ -1,9 +1,17 @@
 package relica_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/coregx/relica"
 	"github.com/stretchr/testify/assert"
@@ -353,12 +361,34 @@ func TestSelectQuery_Wrapper(t *testing.T) {
 		assert.NotNil(t, sq)
 	})
 
+	t.Run("UnionOrderBy", func(t *testing.T) {
+		db.ExecContext(ctx, "CREATE TABLE tags (name TEXT, kind INTEGER)")
+		db.ExecContext(ctx, "INSERT INTO tags (name, kind) VALUES ('go', 1), ('sql', 1), ('api', 2), ('zen', 2)")
+		q1 := db.Builder().Select("name").From("tags").Where("kind = ?", 1).OrderBy("name DESC").Limit(1)
+		q2 := db.Builder().Select("name").From("tags").Where("kind = ?", 2)
+		var names []string
+		err := q1.UnionAll(q2).UnionOrderBy("name").UnionLimit(2).Column(&names)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api", "sql"}, names)
+	})
+
 	t.Run("With", func(t *testing.T) {
 		cte := db.Builder().Select("status", "COUNT(*) as count").From("users").GroupBy("status")
 		sq := db.Builder().Select("*").With("stats", cte).From("stats")
 		assert.NotNil(t, sq)
 	})
 
+	t.Run("WithMaterialized", func(t *testing.T) {
+		type stat struct {
+			Status int `db:"status"`
+			Count  int `db:"count"`
+		}
+		cte := db.Builder().Select("status", "COUNT(*) as count").From("users").GroupBy("status")
+		var stats []stat
+		err := db.Builder().Select("*").With("stats", cte, relica.Materialized()).From("stats").All(&stats)
+		assert.NoError(t, err)
+	})
+
 	t.Run("WithRecursive", func(t *testing.T) {
 		db.ExecContext(ctx, "CREATE TABLE tree (id INTEGER, parent_id INTEGER)")
 		anchor := db.Builder().Select("id", "parent_id", "1 as level").From("tree").Where("parent_id IS NULL")
@@ -366,6 +396,15 @@ func TestSelectQuery_Wrapper(t *testing.T) {
 		cte := anchor.UnionAll(recursive)
 		sq := db.Builder().Select("*").WithRecursive("hierarchy", cte).From("hierarchy")
 		assert.NotNil(t, sq)
+
+		// SQLite rejects parenthesized compound members, so executing checks the generated SQL.
+		db.ExecContext(ctx, "INSERT INTO tree (id, parent_id) VALUES (1, NULL), (2, 1), (3, 2)")
+		descendants := db.Builder().Select("id").From("tree").Where("parent_id IS NULL").
+			UnionAll(db.Builder().Select("t.id").From("tree t").InnerJoin("descendants d", "t.parent_id = d.id"))
+		var ids []int
+		err := db.Builder().Select("id").WithRecursive("descendants", descendants).From("descendants").Column(&ids)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int{1, 2, 3}, ids)
 	})
 
 	t.Run("Build", func(t *testing.T) {
@@ -445,6 +484,14 @@ func TestUpdateQuery_Wrapper(t *testing.T) {
 		rows, _ := result.RowsAffected()
 		assert.Equal(t, int64(1), rows)
 	})
+
+	t.Run("Returning", func(t *testing.T) {
+		var names []string
+		err := db.Builder().Update("users").Set(map[string]interface{}{"status": 3}).Where("id = ?", 1).
+			Returning("name").Build().Column(&names)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Alice"}, names)
+	})
 }
 
 // TestDeleteQuery_Wrapper tests all DeleteQuery wrapper methods.
@@ -477,6 +524,53 @@ func TestDeleteQuery_Wrapper(t *testing.T) {
 		rows, _ := result.RowsAffected()
 		assert.Equal(t, int64(1), rows)
 	})
+
+	t.Run("Returning", func(t *testing.T) {
+		db.ExecContext(ctx, "INSERT INTO users (id, name) VALUES (2, 'Bob')")
+		var ids []int
+		err := db.Builder().Delete("users").Where("name = ?", "Bob").Returning("id").Build().Column(&ids)
+		require.NoError(t, err)
+		assert.Equal(t, []int{2}, ids)
+	})
+}
+
+// TestInsertSelectQuery_Wrapper tests INSERT ... SELECT wrapper methods.
+func TestInsertSelectQuery_Wrapper(t *testing.T) {
+	db, _ := relica.Open("sqlite", ":memory:")
+	defer db.Close()
+
+	ctx := context.Background()
+	db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, deleted INTEGER)")
+	db.ExecContext(ctx, "CREATE TABLE users_archive (id INTEGER PRIMARY KEY, name TEXT)")
+	db.ExecContext(ctx, "INSERT INTO users (name, deleted) VALUES ('Alice', 0), ('Bob', 1), ('Carol', 1)")
+
+	t.Run("Execute", func(t *testing.T) {
+		result, err := db.Builder().InsertFromSelect("users_archive", []string{"id", "name"},
+			db.Builder().Select("id", "name").From("users").Where("deleted = ?", 1),
+		).WithContext(ctx).Execute()
+		require.NoError(t, err)
+		rows, _ := result.RowsAffected()
+		assert.Equal(t, int64(2), rows)
+	})
+
+	t.Run("WithCTE", func(t *testing.T) {
+		cte := db.Builder().Select("id", "name").From("users").Where("deleted = ?", 0)
+		var names []string
+		err := db.Builder().With("active", cte).
+			InsertFromSelect("users_archive", []string{"id", "name"}, db.Builder().Select("id", "name").From("active")).
+			Returning("name").
+			Build().Column(&names)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Alice"}, names)
+	})
+
+	t.Run("DataModifyingCTE_NotSupported", func(t *testing.T) {
+		moved := db.Builder().Delete("users").Returning("*")
+		_, err := db.Builder().With("moved", moved).
+			InsertFromSelect("users_archive", nil, db.Builder().Select("id", "name").From("moved")).
+			Execute()
+		assert.Error(t, err)
+	})
 }
 
 // TestUpsertQuery_Wrapper tests all UpsertQuery wrapper methods.
@@ -598,6 +692,19 @@ func TestBatchUpdateQuery_Wrapper(t *testing.T) {
 			Execute()
 		assert.NoError(t, err)
 	})
+
+	t.Run("LockOrdered", func(t *testing.T) {
+		buq := db.Builder().BatchUpdate("users", "id").
+			Set(2, map[string]interface{}{"status": 5}).
+			Set(1, map[string]interface{}{"status": 4}).
+			LockOrdered()
+		_, params := buq.ToSQL()
+		assert.Equal(t, 1, params[0], "keys are sorted")
+		result, err := buq.Execute()
+		require.NoError(t, err)
+		rows, _ := result.RowsAffected()
+		assert.Equal(t, int64(2), rows)
+	})
 }
 
 // TestQuery_Wrapper tests all Query wrapper methods.
@@ -707,6 +814,60 @@ func TestWrapper_ContextPropagation(t *testing.T) {
 	})
 }
 
+// TestWrapper_SQLCommenter verifies commented statements execute through the wrappers.
+func TestWrapper_SQLCommenter(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithSQLCommenter(
+		relica.CommentApplication("svc"),
+		relica.CommentTag("db_driver", "modernc"),
+	))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE notes (id INTEGER, body TEXT)")
+	require.NoError(t, err)
+
+	ctx := relica.ContextWithCommentTags(context.Background(), map[string]string{"route": "/notes"})
+	_, err = db.Builder().WithContext(ctx).Insert("notes", map[string]interface{}{"id": 1, "body": "hi"}).Execute()
+	require.NoError(t, err)
+
+	var body string
+	err = db.Builder().Select("body").From("notes").Where("id = ?", 1).WithContext(ctx).Row(&body)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", body)
+
+	var count int
+	err = db.Builder().Select("COUNT(*)").From("notes").Row(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+// TestWrapper_MaxConcurrentQueries verifies limited queries execute through the wrappers.
+func TestWrapper_MaxConcurrentQueries(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:",
+		relica.WithMaxConcurrentQueries(2, relica.QueuePolicy{MaxQueueLength: 100, Timeout: time.Second}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE jobs (id INTEGER)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), "INSERT INTO jobs (id) VALUES (1), (2)")
+	require.NoError(t, err)
+
+	ctx := relica.WithQueryPriority(context.Background(), relica.PriorityHigh)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var n int
+			assert.NoError(t, db.Builder().WithContext(ctx).Select("COUNT(*)").From("jobs").Row(&n))
+			assert.Equal(t, 2, n)
+		}()
+	}
+	wg.Wait()
+}
+
 // TestWrapper_NilSafety ensures graceful handling of nil values.
 func TestWrapper_NilSafety(t *testing.T) {
 	t.Run("WrapNilDB", func(t *testing.T) {
@@ -717,3 +878,1881 @@ func TestWrapper_NilSafety(t *testing.T) {
 		})
 	})
 }
+
+// TestWrapper_TypedQuery tests the generic Q / QTx typed query API.
+func TestWrapper_TypedQuery(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE typed_users (id INTEGER PRIMARY KEY, name TEXT, status TEXT)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO typed_users (name, status) VALUES ('ann', 'active'), ('bob', 'banned')`)
+	require.NoError(t, err)
+
+	type typedUser struct {
+		ID     int    `db:"id"`
+		Name   string `db:"name"`
+		Status string `db:"status"`
+	}
+
+	users, err := relica.Q[typedUser](db).Table("typed_users").Where(relica.Eq("status", "active")).All(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "ann", users[0].Name)
+
+	tx, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	user, err := relica.QTx[typedUser](tx).Table("typed_users").Where("id = ?", 2).One(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.Name)
+}
+
+// TestWrapper_Repository tests the generic Repository wrapper.
+func TestWrapper_Repository(t *testing.T) {
+	db := newCoverageTestDB(t)
+	setupCoverageTable(t, db)
+	ctx := context.Background()
+	users := relica.NewRepository[coverageUser](db)
+
+	u := coverageUser{Name: "Ann", Email: "ann@example.com", Status: "active"}
+	require.NoError(t, users.Create(ctx, &u))
+	require.NotZero(t, u.ID)
+
+	got, err := users.Get(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, u, got)
+
+	u.Status = "inactive"
+	require.NoError(t, users.Update(ctx, &u, "status"))
+	inactive, err := users.List(ctx, relica.HashExp{"status": "inactive"})
+	require.NoError(t, err)
+	assert.Len(t, inactive, 1)
+
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		return users.WithTx(tx).Delete(ctx, &u)
+	})
+	require.NoError(t, err)
+
+	n, err := users.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	_, err = users.Query().Where("id = ?", u.ID).One(ctx)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// legacyAccount maps to a legacy schema through the naming strategy.
+type legacyAccount struct {
+	AccountNo   int `relica:"pk"`
+	OwnerName   string
+	OpenedOnDay string
+}
+
+func (legacyAccount) TableName() string { return "legacy_accounts" }
+
+// TestWrapper_SetNamingStrategy tests snake_case mapping and relica:"pk".
+func TestWrapper_SetNamingStrategy(t *testing.T) {
+	relica.SetNamingStrategy(relica.SnakeCaseNaming)
+	defer relica.SetNamingStrategy(nil)
+
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE legacy_accounts (account_no INTEGER PRIMARY KEY, owner_name TEXT, opened_on_day TEXT)`)
+	require.NoError(t, err)
+
+	acc := legacyAccount{OwnerName: "Ann", OpenedOnDay: "mon"}
+	require.NoError(t, db.Model(&acc).Insert())
+	require.Equal(t, 1, acc.AccountNo)
+
+	var loaded legacyAccount
+	require.NoError(t, db.Builder().Select().From("legacy_accounts").Where("account_no = ?", 1).One(&loaded))
+	assert.Equal(t, acc, loaded)
+}
+
+type wrapperColor string
+
+var wrapperColors = relica.RegisterEnum[wrapperColor]("red", "green")
+
+// TestWrapper_Enum tests enum registration, validation and InEnum.
+func TestWrapper_Enum(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `CREATE TABLE paints (id INTEGER PRIMARY KEY, color TEXT)`)
+	require.NoError(t, err)
+
+	_, err = db.Builder().Insert("paints", map[string]interface{}{"color": wrapperColor("red")}).Execute()
+	require.NoError(t, err)
+	_, err = db.Builder().Insert("paints", map[string]interface{}{"color": wrapperColor("blue")}).Execute()
+	assert.ErrorIs(t, err, relica.ErrInvalidEnumValue)
+
+	var colors []wrapperColor
+	err = db.Builder().Select("color").From("paints").Where(relica.InEnum("color", wrapperColors.Values()...)).Column(&colors)
+	require.NoError(t, err)
+	assert.Equal(t, []wrapperColor{"red"}, colors)
+}
+
+// TestWrapper_NewQueryParams tests NewQuery parameters and Query.WithContext.
+func TestWrapper_NewQueryParams(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.NewQuery(`CREATE TABLE kv (k TEXT PRIMARY KEY, v TEXT)`).WithContext(ctx).Execute()
+	require.NoError(t, err)
+	_, err = db.NewQuery(`INSERT INTO kv (k, v) VALUES (?, ?)`, "a", "1").Execute()
+	require.NoError(t, err)
+
+	var v string
+	require.NoError(t, db.NewQuery(`SELECT v FROM kv WHERE k = ?`, "a").WithContext(ctx).Row(&v))
+	assert.Equal(t, "1", v)
+
+	var keys []string
+	require.NoError(t, db.NewQuery(`SELECT k FROM kv WHERE v = ?`, "1").Column(&keys))
+	assert.Equal(t, []string{"a"}, keys)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = db.NewQuery(`SELECT v FROM kv`).WithContext(canceled).Row(&v)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestWrapper_ExpandedSQL tests ExpandedSQL on raw and builder queries.
+func TestWrapper_ExpandedSQL(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithExpandedSQLLogging())
+	require.NoError(t, err)
+	defer db.Close()
+
+	q := db.NewQuery(`SELECT * FROM users WHERE name = ? AND active = ?`, "O'Hara", true)
+	assert.Equal(t, `SELECT * FROM users WHERE name = 'O''Hara' AND active = TRUE`, q.ExpandedSQL())
+
+	sq := db.Builder().Select("id").From("users").Where(relica.Eq("id", 7))
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE "id" = 7`, sq.ExpandedSQL())
+}
+
+func TestWrapper_QueryTag(t *testing.T) {
+	var tags []string
+	db, err := relica.Open("sqlite", ":memory:", relica.WithQueryHook(func(_ context.Context, e relica.QueryEvent) {
+		tags = append(tags, e.Tag)
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("CREATE TABLE carts (id INTEGER PRIMARY KEY)").Tag("setup").Execute()
+	require.NoError(t, err)
+	_, err = db.Builder().Tag("checkout.save_cart").Insert("carts", map[string]interface{}{"id": 1}).Execute()
+	require.NoError(t, err)
+
+	var ids []int
+	err = db.Builder().Select("id").From("carts").Tag("checkout.load_cart").Column(&ids)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, ids)
+	assert.Equal(t, []string{"setup", "checkout.save_cart", "checkout.load_cart"}, tags)
+
+	stats := db.QueryStats()
+	require.Len(t, stats, 3)
+	assert.Equal(t, int64(1), stats["checkout.load_cart"].Count)
+	assert.Zero(t, stats["checkout.load_cart"].ErrorRate)
+
+	db.ResetQueryStats()
+	assert.Empty(t, db.QueryStats())
+}
+
+func TestWrapper_RowMapper(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)").Execute()
+	require.NoError(t, err)
+	_, err = db.NewQuery("INSERT INTO notes (body) VALUES ('hello'), ('world')").Execute()
+	require.NoError(t, err)
+
+	lengths, err := relica.MapRows(db.Builder().Select("body").From("notes").OrderBy("id"),
+		func(raw relica.RowData) (int, error) { return len(raw.String("body")), nil })
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 5}, lengths)
+
+	type note struct {
+		ID   int    `db:"id"`
+		Body string `db:"body"`
+	}
+	notes, err := relica.Q[note](db).Table("notes").OrderBy("id").
+		Map(func(raw relica.RowData) (note, error) {
+			return note{ID: int(raw.Int64("id")), Body: raw.String("body") + "!"}, nil
+		}).
+		All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []note{{1, "hello!"}, {2, "world!"}}, notes)
+}
+
+func TestWrapper_IsDistinctFrom(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	sql, args := db.Builder().Select("id").From("emp").
+		Where(relica.IsDistinctFrom("manager_id", nil)).
+		AndWhere(relica.IsNotDistinctFrom("team_id", 3)).
+		ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "emp" WHERE "manager_id" IS DISTINCT FROM ? AND "team_id" IS NOT DISTINCT FROM ?`, sql)
+	assert.Equal(t, []interface{}{nil, 3}, args)
+}
+
+func TestWrapper_Match(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.NewQuery("CREATE VIRTUAL TABLE posts USING fts5(title, body)").Execute()
+	require.NoError(t, err)
+	_, err = db.NewQuery("INSERT INTO posts (title, body) VALUES ('pooling', 'connection pooling in go'), ('misc', 'pooling')").Execute()
+	require.NoError(t, err)
+
+	var titles []string
+	err = db.Builder().Select("title").From("posts").
+		Where(relica.Match("title,body", "connection pooling")).
+		OrderBySub(relica.MatchRank("title,body", "connection pooling").Desc()).
+		Column(&titles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pooling"}, titles)
+}
+
+func TestWrapper_Geo(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	berlin := relica.Point{Lng: 13.4, Lat: 52.52}
+	sql, args := db.Builder().Select("id").From("shops").
+		Where(relica.STDWithin("location", berlin, 500)).
+		ToSQL()
+	assert.Equal(t, `SELECT "id" FROM "shops" WHERE PtDistWithin("location", MakePoint(?, ?, 4326), ?)`, sql)
+	assert.Equal(t, []interface{}{13.4, 52.52, 500.0}, args)
+
+	// Geometry values returned as text scan into Point and BBox.
+	var p relica.Point
+	var box relica.BBox
+	err = db.NewQuery("SELECT 'POINT(13.4 52.52)', 'LINESTRING(13 52, 14 53)'").Row(&p, &box)
+	require.NoError(t, err)
+	assert.Equal(t, berlin, p)
+	assert.Equal(t, relica.BBox{MinLng: 13, MinLat: 52, MaxLng: 14, MaxLat: 53}, box)
+}
+
+func TestWrapper_TimeRange(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithUTCTimes())
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(),
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, created_at DATETIME)")
+	require.NoError(t, err)
+
+	now := time.Now()
+	for i, ts := range []time.Time{now.Add(-time.Hour), now.AddDate(0, 0, -3), now.AddDate(0, 0, -10)} {
+		_, err = db.Builder().Insert("events", map[string]interface{}{"id": i + 1, "created_at": ts}).Execute()
+		require.NoError(t, err)
+	}
+
+	var ids []int
+	err = db.Builder().Select("id").From("events").
+		Where(relica.WithinLast("created_at", 2*time.Hour)).Column(&ids)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, ids)
+
+	ids = nil
+	err = db.Builder().Select("id").From("events").
+		Where(relica.DateBetween("created_at", now.AddDate(0, 0, -4), now).In(time.UTC)).
+		OrderBy("id").Column(&ids)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids)
+
+	sql, args := db.Builder().Select().From("events").Where(relica.Today("created_at").In(time.UTC)).ToSQL()
+	assert.Equal(t, `SELECT * FROM "events" WHERE "created_at" >= ? AND "created_at" < ?`, sql)
+	assert.Len(t, args, 2)
+}
+
+func TestWrapper_Seed(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(),
+		"CREATE TABLE products (id INTEGER PRIMARY KEY, sku TEXT, name TEXT, price REAL, created_at DATETIME)")
+	require.NoError(t, err)
+
+	type Product struct {
+		ID        int64     `db:"id"`
+		SKU       string    `db:"sku"`
+		Name      string    `db:"name"`
+		Price     float64   `db:"price"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	faker := relica.FakerFunc(func(f relica.SeedField) (interface{}, bool) {
+		if f.Column == "sku" {
+			return "SKU-" + string(rune('A'+f.Row%26)), true
+		}
+		return nil, false
+	})
+	err = relica.Seed(db, &Product{}, 120, relica.WithFaker(faker), relica.WithRandSeed(1))
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM products WHERE price BETWEEN 1 AND 500").Row(&count))
+	assert.Equal(t, 120, count)
+
+	var sku string
+	require.NoError(t, db.NewQuery("SELECT sku FROM products WHERE id = 2").Row(&sku))
+	assert.Equal(t, "SKU-B", sku)
+}
+
+func TestWrapper_BatchRows(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE events (event_no INTEGER PRIMARY KEY, kind TEXT)")
+	require.NoError(t, err)
+	bi := db.BatchInsert("events", []string{"event_no", "kind"})
+	for i := 1; i <= 50; i++ {
+		bi.Values(i*10, "click")
+	}
+	_, err = bi.Execute()
+	require.NoError(t, err)
+
+	type Event struct {
+		No   int    `db:"event_no"`
+		Kind string `db:"kind"`
+	}
+	var batches, rows int
+	err = relica.BatchRows(db.Select().From("events").Where("kind = ?", "click"), 20,
+		func(batch []Event) error {
+			batches++
+			rows += len(batch)
+			return nil
+		}, relica.BatchKey("event_no"), relica.BatchTimeout(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 3, batches)
+	assert.Equal(t, 50, rows)
+
+	type TypedEvent struct {
+		No   int    `db:"event_no" relica:"pk"`
+		Kind string `db:"kind"`
+	}
+	var last int
+	err = relica.Q[TypedEvent](db).Table("events").Batch(context.Background(), 7, func(batch []TypedEvent) error {
+		last = batch[len(batch)-1].No
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 500, last)
+}
+
+func TestWrapper_Each(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE events (id INTEGER PRIMARY KEY, kind TEXT)")
+	require.NoError(t, err)
+	_, err = db.BatchInsert("events", []string{"id", "kind"}).Values(1, "click").Values(2, "view").Execute()
+	require.NoError(t, err)
+
+	type Event struct {
+		ID   int    `db:"id"`
+		Kind string `db:"kind"`
+	}
+	var ev Event
+	var kinds []string
+	err = db.Select().From("events").OrderBy("id").WithCursor(100).Each(&ev, func() error {
+		kinds = append(kinds, ev.Kind)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"click", "view"}, kinds)
+
+	var ids []int
+	err = relica.Q[Event](db).WithCursor(1).Each(context.Background(), func(e Event) error {
+		ids = append(ids, e.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{1, 2}, ids)
+}
+
+func TestWrapper_Export(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE sales (region TEXT, total REAL)")
+	require.NoError(t, err)
+	_, err = db.BatchInsert("sales", []string{"region", "total"}).Values("north", 10.5).Values("south", nil).Execute()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, db.Select().From("sales").OrderBy("region").ExportCSV(&buf, &relica.CSVOptions{Null: "-"}))
+	assert.Equal(t, "region,total\nnorth,10.5\nsouth,-\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, db.Select().From("sales").OrderBy("region").ExportNDJSON(&buf))
+	assert.Equal(t, "{\"region\":\"north\",\"total\":10.5}\n{\"region\":\"south\",\"total\":null}\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, db.Select("region").From("sales").OrderBy("region").ExportJSON(&buf))
+	assert.Equal(t, `[{"region":"north"},{"region":"south"}]`+"\n", buf.String())
+}
+
+func TestWrapper_ImportCSV(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE sales (region TEXT, total REAL)")
+	require.NoError(t, err)
+
+	res, err := db.ImportCSV(context.Background(), "sales", strings.NewReader("Region,Total\nnorth,10.5\nsouth,n/a\n"), relica.ImportOptions{
+		ColumnMap: map[string]string{"Region": "region", "Total": "total"},
+		Coerce:    map[string]relica.CoerceFunc{"total": relica.CoerceFloat},
+		OnError:   func(*relica.ImportError) error { return nil },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Inserted)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "total", res.Errors[0].Column)
+
+	var total float64
+	require.NoError(t, db.NewQuery("SELECT total FROM sales WHERE region = 'north'").Row(&total))
+	assert.Equal(t, 10.5, total)
+}
+
+func TestWrapper_ChangeSink(t *testing.T) {
+	var events []relica.ChangeEvent
+	db, err := relica.Open("sqlite", ":memory:", relica.WithChangeSink(func(_ context.Context, e relica.ChangeEvent) {
+		events = append(events, e)
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)")
+	require.NoError(t, err)
+
+	_, err = db.Insert("notes", map[string]interface{}{"id": 7, "body": "hi"}).Execute()
+	require.NoError(t, err)
+	err = db.Transactional(context.Background(), func(tx *relica.Tx) error {
+		_, err := tx.Update("notes").Set(map[string]interface{}{"body": "bye"}).Where("id = ?", 7).Execute()
+		return err
+	})
+	require.NoError(t, err)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "notes", events[0].Table)
+	assert.Equal(t, []map[string]interface{}{{"id": 7}}, events[0].Keys)
+	assert.Equal(t, "UPDATE", events[1].Operation)
+	assert.Equal(t, []string{"body"}, events[1].Columns)
+}
+
+func TestWrapper_Outbox(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.CreateOutboxTable(ctx, ""))
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		return tx.Outbox().Publish("users.created", map[string]int{"id": 1})
+	})
+	require.NoError(t, err)
+
+	var topics []string
+	n, err := db.OutboxRelay(func(_ context.Context, msg relica.OutboxMessage) error {
+		topics = append(topics, msg.Topic)
+		return nil
+	}).Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"users.created"}, topics)
+
+	var left int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM "+relica.DefaultOutboxTable).Row(&left))
+	assert.Equal(t, 0, left)
+}
+
+func TestWrapper_AdvisoryLock(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	lock, err := db.AdvisoryLock(ctx, "leader")
+	require.NoError(t, err)
+	_, err = db.TryAdvisoryLock(ctx, "leader")
+	assert.ErrorIs(t, err, relica.ErrLockHeld)
+	require.NoError(t, lock.Unlock())
+}
+
+func TestWrapper_Sequences(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithSnowflakeNode(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.CreateSequence(ctx, "order_seq"))
+	n, err := db.NextSequence(ctx, "order_seq")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	assert.Len(t, relica.NewULID(), 26)
+	sf, err := relica.NewSnowflake(1)
+	require.NoError(t, err)
+	assert.Less(t, sf.Next(), sf.Next())
+}
+
+func TestWrapper_ModelInsertUUID(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE tenants (id TEXT PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	type tenant struct {
+		ID   string `db:"id" relica:"pk,uuidv7"`
+		Name string `db:"name"`
+	}
+	tn := tenant{Name: "acme"}
+	require.NoError(t, db.Model(&tn).Table("tenants").Insert())
+	assert.Len(t, tn.ID, 36)
+	assert.Len(t, relica.NewUUID(), 36)
+	assert.NotEqual(t, relica.NewUUIDv7(), relica.NewUUIDv7())
+}
+
+func TestWrapper_NPlusOneDetector(t *testing.T) {
+	var warnings []relica.NPlusOneWarning
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1),
+		relica.WithNPlusOneDetector(3, func(_ context.Context, w relica.NPlusOneWarning) {
+			warnings = append(warnings, w)
+		}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	scoped := relica.WithQueryScope(ctx)
+	for id := 1; id <= 3; id++ {
+		var names []string
+		err := db.Select("name").From("tags").Where("id = ?", id).WithContext(scoped).Column(&names)
+		require.NoError(t, err)
+	}
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "tags", warnings[0].Table)
+	assert.Equal(t, "id", warnings[0].Column)
+}
+
+func TestWrapper_OptimizerStore(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithOptimizerStore(""))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.CreateOptimizerStore(ctx))
+	_, err = db.ExecContext(ctx, "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)")
+	require.NoError(t, err)
+	for id := 1; id <= 3; id++ {
+		_, err := db.Insert("notes", map[string]interface{}{"id": id, "body": "x"}).Execute()
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.FlushOptimizerStore(ctx))
+
+	profiles, err := db.QueryProfiles(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, int64(3), profiles[0].Calls)
+
+	suggestions, err := db.OptimizerSuggestions(ctx, relica.SuggestionOpen)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+	assert.Error(t, db.DismissSuggestion(ctx, "unknown"))
+}
+
+func TestWrapper_Retry(t *testing.T) {
+	assert.True(t, relica.IsTransientError(driver.ErrBadConn))
+	assert.False(t, relica.IsTransientError(context.Canceled))
+
+	var attempts int
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1),
+		relica.WithRetry(relica.RetryPolicy{
+			InitialBackoff: time.Millisecond,
+			Retryable: func(error) bool {
+				attempts++
+				return true
+			},
+		}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var names []string
+	err = db.Select("name").From("missing").Column(&names)
+	require.Error(t, err)
+	assert.Equal(t, 2, attempts, "the last of 3 attempts is not followed by a retry")
+
+	attempts = 0
+	_, err = db.Insert("missing", map[string]interface{}{"name": "x"}).Execute()
+	require.Error(t, err)
+	assert.Zero(t, attempts, "writes are not retried")
+
+	_, err = db.Insert("missing", map[string]interface{}{"name": "x"}).Idempotent().Execute()
+	require.Error(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestWrapper_Shutdown(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tx, err := db.Begin(ctx)
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- db.Shutdown(ctx) }()
+
+	require.Eventually(t, func() bool {
+		_, err := db.ExecContext(ctx, "SELECT 1")
+		return errors.Is(err, relica.ErrShuttingDown)
+	}, time.Second, time.Millisecond)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, <-done)
+}
+
+func TestWrapper_Reconfigure(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1),
+		relica.WithLogger(relica.NewSlogAdapter(l)))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.Reconfigure(relica.WithMaxOpenConns(4), relica.WithSlowQueryThreshold(time.Nanosecond)))
+	assert.Equal(t, 4, db.Stats().MaxOpenConnections)
+
+	var n int
+	require.NoError(t, db.NewQuery("SELECT 1").Row(&n))
+	assert.Contains(t, buf.String(), "slow query")
+
+	err = db.Reconfigure(relica.WithLogger(&relica.NoopLogger{}))
+	assert.ErrorIs(t, err, relica.ErrNotReconfigurable)
+}
+
+// spanRecorder is a Tracer recording span names and parents.
+type spanRecorder struct {
+	names   []string
+	parents []string
+}
+
+type recorderSpan struct{}
+
+func (recorderSpan) SetAttribute(string, interface{}) {}
+func (recorderSpan) RecordError(error)                {}
+func (recorderSpan) End()                             {}
+
+type recorderKey struct{}
+
+func (r *spanRecorder) StartSpan(ctx context.Context, name string) (context.Context, relica.Span) {
+	parent, _ := ctx.Value(recorderKey{}).(string)
+	r.names = append(r.names, name)
+	r.parents = append(r.parents, parent)
+	return context.WithValue(ctx, recorderKey{}, name), recorderSpan{}
+}
+
+func TestWrapper_Tracer(t *testing.T) {
+	tracer := &spanRecorder{}
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithTracer(tracer))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE events (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		_, err := tx.Insert("events", map[string]interface{}{"id": 1}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"relica.transaction", "INSERT events"}, tracer.names)
+	assert.Equal(t, []string{"", "relica.transaction"}, tracer.parents)
+}
+
+// meterRecorder is a relica.MeterProvider counting histogram records and
+// collecting gauges on demand.
+type meterRecorder struct {
+	durations int
+	gauges    map[string]func(record func(int64, ...relica.Attribute))
+}
+
+func (m *meterRecorder) Meter(string) relica.Meter { return m }
+
+func (m *meterRecorder) Float64Histogram(string, string, string) relica.Float64Histogram {
+	return m
+}
+
+func (m *meterRecorder) Int64Counter(string, string, string) relica.Int64Counter { return m }
+
+func (m *meterRecorder) Int64ObservableGauge(name, _, _ string, observe func(record func(int64, ...relica.Attribute))) {
+	m.gauges[name] = observe
+}
+
+func (m *meterRecorder) Record(context.Context, float64, ...relica.Attribute) { m.durations++ }
+func (m *meterRecorder) Add(context.Context, int64, ...relica.Attribute)      {}
+
+func TestWrapper_MeterProvider(t *testing.T) {
+	meter := &meterRecorder{gauges: make(map[string]func(record func(int64, ...relica.Attribute)))}
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithMeterProvider(meter))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var n int
+	require.NoError(t, db.NewQuery("SELECT 1").Row(&n))
+	assert.Equal(t, 1, meter.durations)
+
+	var maxConns int64
+	meter.gauges["db.client.connection.max"](func(v int64, _ ...relica.Attribute) { maxConns = v })
+	assert.Equal(t, int64(1), maxConns)
+}
+
+func TestWrapper_DBError(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT UNIQUE)")
+	require.NoError(t, err)
+	_, err = db.Insert("users", map[string]interface{}{"id": 1, "email": "a@example.com"}).Execute()
+	require.NoError(t, err)
+
+	_, err = db.Insert("users", map[string]interface{}{"id": 2, "email": "a@example.com"}).Execute()
+	var dbErr *relica.DBError
+	require.ErrorAs(t, err, &dbErr)
+	assert.Equal(t, relica.ErrUniqueViolation, dbErr.Kind)
+	assert.Equal(t, "users", dbErr.Table)
+	assert.Equal(t, "email", dbErr.Column)
+	assert.NotErrorIs(t, err, relica.ErrDeadlock)
+}
+
+func TestWrapper_OneOrNil(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithEmptySlices())
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	var users []struct {
+		ID int `db:"id"`
+	}
+	require.NoError(t, db.Select().From("users").All(&users))
+	assert.NotNil(t, users)
+	assert.Empty(t, users)
+
+	var user struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	found, err := db.Select().From("users").Where("id = ?", 1).OneOrNil(&user)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = db.Insert("users", map[string]interface{}{"id": 1, "name": "ann"}).Execute()
+	require.NoError(t, err)
+	found, err = db.NewQuery("SELECT id, name FROM users WHERE id = 1").OneOrNil(&user)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "ann", user.Name)
+}
+
+type signup struct {
+	ID    int64  `db:"id"`
+	Email string `db:"email" validate:"required,email"`
+}
+
+func (signup) TableName() string { return "signups" }
+
+func TestWrapper_ModelValidator(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithModelValidator(relica.ValidateTags))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), "CREATE TABLE signups (id INTEGER PRIMARY KEY, email TEXT)")
+	require.NoError(t, err)
+
+	err = db.Model(&signup{Email: "nope"}).Insert()
+	var verr *relica.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []relica.FieldError{{Field: "email", Message: "must be a valid email address"}}, verr.Fields)
+
+	require.NoError(t, db.Model(&signup{Email: "ann@example.com"}).Insert())
+}
+
+func TestWrapper_Immutable(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	base := db.Select("id").From("users").Where("active = ?", true).Immutable()
+	team := base.Where("team_id = ?", 3).OrderBy("id").Limit(5)
+
+	sql, params := base.ToSQL()
+	assert.NotContains(t, sql, "team_id")
+	assert.NotContains(t, sql, "LIMIT")
+	assert.Len(t, params, 1)
+
+	sql, params = team.ToSQL()
+	assert.Contains(t, sql, "team_id")
+	assert.Contains(t, sql, "LIMIT 5")
+	assert.Len(t, params, 2)
+
+	mutable := db.Select("id").From("users")
+	clone := mutable.Clone().Where("id = ?", 1)
+	sql, _ = mutable.ToSQL()
+	assert.NotContains(t, sql, "WHERE")
+	sql, _ = clone.ToSQL()
+	assert.Contains(t, sql, "WHERE")
+}
+
+func TestWrapper_RegisterQuery(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, org_id INTEGER, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO users (org_id, name) VALUES (1, 'ann'), (2, 'bob'), (1, 'cid')")
+	require.NoError(t, err)
+
+	require.NoError(t, db.RegisterQuery("usersByOrg", func(qb *relica.QueryBuilder) *relica.Query {
+		return qb.Select("id", "name").From("users").Where("org_id = ?", 0).OrderBy("name").Build()
+	}))
+	assert.Equal(t, []string{"usersByOrg"}, db.RegisteredQueries())
+
+	var users []struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	require.NoError(t, db.Query("usersByOrg", 1).WithContext(ctx).All(&users))
+	require.Len(t, users, 2)
+	assert.Equal(t, "cid", users[1].Name)
+
+	err = db.Query("nope").All(&users)
+	assert.ErrorIs(t, err, relica.ErrQueryNotRegistered)
+	assert.True(t, db.UnregisterQuery("usersByOrg"))
+}
+
+func TestWrapper_InsertAll(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	type user struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	users := []user{{Name: "ann"}, {Name: "bob"}, {Name: "cid"}}
+	n, err := db.InsertAll("users", users).BatchSize(2).WithContext(ctx).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, int64(3), users[2].ID)
+
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		_, err := tx.InsertAll("users", []*user{{Name: "dan"}}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+	count, err := db.Select().From("users").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestWrapper_ModelLockForUpdate(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE accounts (id INTEGER PRIMARY KEY, balance INTEGER)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO accounts (id, balance) VALUES (1, 100)")
+	require.NoError(t, err)
+
+	type account struct {
+		ID      int64 `db:"id"`
+		Balance int   `db:"balance"`
+	}
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		acc := account{ID: 1}
+		if err := tx.Model(&acc).Table("accounts").LockForUpdate().One(); err != nil {
+			return err
+		}
+		acc.Balance -= 30
+		return tx.Model(&acc).Table("accounts").Update("balance")
+	})
+	require.NoError(t, err)
+
+	acc := account{ID: 1}
+	require.NoError(t, db.Model(&acc).Table("accounts").One())
+	assert.Equal(t, 70, acc.Balance)
+	assert.ErrorIs(t, db.Model(&account{ID: 9}).Table("accounts").One(), relica.ErrNotFound)
+}
+
+func TestWrapper_MaterializedViewUnsupported(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	err = db.Schema().CreateMaterializedView(ctx, "daily", db.Select("id").From("orders"), relica.WithNoData())
+	assert.ErrorIs(t, err, relica.ErrUnsupportedDialect)
+	assert.ErrorIs(t, db.RefreshMaterializedView(ctx, "daily", relica.Concurrently()), relica.ErrUnsupportedDialect)
+	assert.ErrorIs(t, db.Schema().DropMaterializedView(ctx, "daily"), relica.ErrUnsupportedDialect)
+}
+
+func TestWrapper_Truncate(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO events (name) VALUES ('a'), ('b')")
+	require.NoError(t, err)
+
+	sql, _ := db.Truncate("events").RestartIdentity().ToSQL()
+	assert.Equal(t, `DELETE FROM "events"`, sql)
+	assert.ErrorIs(t, db.Truncate("events").Cascade().Execute(), relica.ErrUnsupportedDialect)
+
+	// Rolled back with the transaction
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		if err := tx.Truncate("events").Execute(); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+	count, err := db.Select().From("events").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	require.NoError(t, db.Truncate("events").WithContext(ctx).Execute())
+	count, err = db.Select().From("events").Count()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestWrapper_StrictSchema(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithStrictSchema())
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT)")
+	require.NoError(t, err)
+
+	_, err = db.Insert("users", map[string]interface{}{"email": "a@b.c"}).Execute()
+	require.NoError(t, err)
+	_, err = db.Insert("users", map[string]interface{}{"emial": "a@b.c"}).Execute()
+	assert.ErrorIs(t, err, relica.ErrUnknownColumn)
+	assert.ErrorContains(t, err, `did you mean "email"?`)
+
+	var emails []string
+	err = db.Select("email").From("user").Column(&emails)
+	assert.ErrorIs(t, err, relica.ErrUnknownTable)
+}
+
+func TestWrapper_QueryLint(t *testing.T) {
+	var warnings []relica.LintWarning
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1),
+		relica.WithQueryLint(relica.LintPolicy{
+			Block: []relica.LintRule{relica.LintWriteWithoutWhere},
+			Handler: func(_ context.Context, w relica.LintWarning) {
+				warnings = append(warnings, w)
+			},
+		}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT)")
+	require.NoError(t, err)
+
+	_, err = db.Update("users").Set(map[string]interface{}{"email": ""}).Execute()
+	assert.ErrorIs(t, err, relica.ErrLintViolation)
+
+	var ids []int64
+	err = db.Select("id").From("users").Where("LOWER(email) = ?", "a@b.c").Column(&ids)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, relica.LintNonSargable, warnings[0].Rule)
+}
+
+func TestWrapper_SafeWrites(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithSafeWrites())
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE sessions (id INTEGER PRIMARY KEY, active BOOLEAN)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO sessions (active) VALUES (1), (1)")
+	require.NoError(t, err)
+
+	_, err = db.Update("sessions").Set(map[string]interface{}{"active": false}).Execute()
+	assert.ErrorIs(t, err, relica.ErrMissingWhere)
+	_, err = db.Delete("sessions").Where(relica.HashExp{}).Execute()
+	assert.ErrorIs(t, err, relica.ErrMissingWhere)
+	count, err := db.Select().From("sessions").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, err = db.Delete("sessions").AllowFullTableWrite().Execute()
+	require.NoError(t, err)
+	count, err = db.Select().From("sessions").Count()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestWrapper_ReadOnly(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithReadOnly())
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.ErrorIs(t, err, relica.ErrReadOnly)
+	_, err = db.Insert("users", map[string]interface{}{"name": "ann"}).Execute()
+	assert.ErrorIs(t, err, relica.ErrReadOnly)
+
+	var n int
+	require.NoError(t, db.Select("COUNT(*)").From("sqlite_master").ReadOnly().Row(&n))
+	assert.Zero(t, n)
+}
+
+func TestWrapper_RawOrder(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO users (name) VALUES ('bob'), ('Ann'), ('carl')")
+	require.NoError(t, err)
+
+	var names []string
+	require.NoError(t, db.Select("name").From("users").OrderBy("LOWER(name) DESC").Column(&names))
+	assert.Equal(t, []string{"carl", "bob", "Ann"}, names)
+
+	names = nil
+	err = db.Select("name").From("users").OrderBySub(relica.RawOrder("name = ? DESC, id DESC", "bob")).Column(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carl", "Ann"}, names)
+}
+
+func TestWrapper_SelectAliases(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, price INTEGER, qty INTEGER)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO items (name, price, qty) VALUES ('pen', 2, 3)")
+	require.NoError(t, err)
+
+	var row struct {
+		Order     string `db:"order"`
+		LineTotal int    `db:"lineTotal"`
+		Count     int    `db:"group"`
+	}
+	err = db.Select(`i.name AS "order"`, "price * qty AS lineTotal", "COUNT(*) AS group").From("items i").One(&row)
+	require.NoError(t, err)
+	assert.Equal(t, "pen", row.Order)
+	assert.Equal(t, 6, row.LineTotal)
+	assert.Equal(t, 1, row.Count)
+}
+
+func TestWrapper_JoinSelect(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, total INTEGER, status TEXT)",
+		"INSERT INTO users (name) VALUES ('ann'), ('bob'), ('carl')",
+		"INSERT INTO orders (user_id, total, status) VALUES (1, 10, 'paid'), (1, 5, 'paid'), (2, 7, 'open'), (3, 1, 'paid')",
+	} {
+		_, err = db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	totals := db.Select("user_id", "SUM(total) AS spent").From("orders").
+		Where("status = ?", "paid").GroupBy("user_id")
+	var rows []struct {
+		Name  string `db:"name"`
+		Spent int    `db:"spent"`
+	}
+	err = db.Select("u.name", "t.spent").From("users u").
+		InnerJoinSelect(totals, "t", "t.user_id = u.id").
+		Where("t.spent > ?", 2).
+		All(&rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "ann", rows[0].Name)
+	assert.Equal(t, 15, rows[0].Spent)
+
+	var names []string
+	err = db.Select("u.name").From("users u").
+		LeftJoinSelect(totals, "t", "t.user_id = u.id").
+		Where("t.user_id IS NULL").
+		Column(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, names)
+}
+
+func TestWrapper_ScalarSubquery(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, balance INTEGER)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, total INTEGER, status TEXT)",
+		"INSERT INTO users (name, balance) VALUES ('ann', 30), ('bob', 5), ('carl', 12)",
+		"INSERT INTO orders (user_id, total, status) VALUES (1, 10, 'paid'), (1, 5, 'paid'), (2, 7, 'open'), (3, 1, 'paid')",
+	} {
+		_, err = db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	paid := db.Select("SUM(o.total)").From("orders o").
+		Where(relica.And(relica.EqCol("o.user_id", "u.id"), relica.Eq("o.status", "paid")))
+	var rows []struct {
+		Name string `db:"name"`
+		Paid *int   `db:"paid_total"`
+	}
+	err = db.Select("u.name").
+		SelectExp(relica.ScalarSubquery(paid).As("paid_total")).
+		From("users u").
+		Where(relica.GreaterThan("u.balance", relica.ScalarSubquery(paid))).
+		OrderBy("u.id").
+		All(&rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "ann", rows[0].Name)
+	assert.Equal(t, 15, *rows[0].Paid)
+	assert.Equal(t, "carl", rows[1].Name)
+	assert.Equal(t, 1, *rows[1].Paid)
+
+	err = db.Select("u.name").From("users u").
+		Where(relica.GreaterThan("u.balance", relica.ScalarSubquery(nil))).
+		All(&rows)
+	assert.Error(t, err)
+}
+
+func TestWrapper_QuantifiedSubqueries(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE bans (user_id INTEGER, reason TEXT)",
+		"INSERT INTO users (name) VALUES ('ann'), ('bob'), ('carl')",
+		"INSERT INTO bans (user_id, reason) VALUES (2, 'spam'), (3, 'abuse')",
+	} {
+		_, err = db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	spam := db.Select("user_id").From("bans").Where(relica.Eq("reason", "spam"))
+	var names []string
+	err = db.Select("name").From("users").Where(relica.EqAny("id", spam)).Column(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, names)
+
+	names = nil
+	err = db.Select("name").From("users").Where(relica.NotEqAll("id", spam)).OrderBy("id").Column(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ann", "carl"}, names)
+
+	err = db.Select("name").From("users").Where(relica.GtAll("id", spam)).Column(&names)
+	assert.Error(t, err)
+
+	err = db.Select("name").From("users").Where(relica.EqAny("id", nil)).Column(&names)
+	assert.Error(t, err)
+}
+
+func TestWrapper_UpsertConflictOptions(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE page_views (path TEXT PRIMARY KEY, count INTEGER, locked INTEGER DEFAULT 0)")
+	require.NoError(t, err)
+
+	upsert := func(path string, n int) {
+		_, err := db.Upsert("page_views", map[string]interface{}{"path": path, "count": n}).
+			OnConflict("path").
+			DoUpdateExpr("count", "page_views.count + excluded.count").
+			Where("page_views.locked = ?", 0).
+			Execute()
+		require.NoError(t, err)
+	}
+	upsert("/", 1)
+	upsert("/", 2)
+	upsert("/about", 5)
+
+	_, err = db.ExecContext(ctx, "UPDATE page_views SET locked = 1 WHERE path = '/about'")
+	require.NoError(t, err)
+	upsert("/about", 5)
+
+	var rows []struct {
+		Path  string `db:"path"`
+		Count int    `db:"count"`
+	}
+	require.NoError(t, db.Select("path", "count").From("page_views").OrderBy("path").All(&rows))
+	require.Len(t, rows, 2)
+	assert.Equal(t, 3, rows[0].Count)
+	assert.Equal(t, 5, rows[1].Count)
+
+	_, err = db.Upsert("page_views", map[string]interface{}{"path": "/"}).
+		OnConstraint("page_views_pkey").DoNothing().Execute()
+	assert.Error(t, err)
+}
+
+func TestWrapper_Merge(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, visits INTEGER)",
+		"CREATE TABLE staging (id INTEGER, name TEXT, visits INTEGER, batch INTEGER)",
+		"INSERT INTO users (id, name, visits) VALUES (1, 'ann', 10), (2, 'bob', 3)",
+		"INSERT INTO staging (id, name, visits, batch) VALUES (1, 'Ann', 5, 7), (3, 'carl', 1, 7), (2, 'Bobby', 9, 8)",
+	} {
+		_, err = db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	staged := db.Select("id", "name", "visits").From("staging").Where(relica.Eq("batch", 7))
+	_, err = db.Merge("users u").
+		Using(staged, "s", "u.id = s.id").
+		Keys("id").
+		WhenMatchedUpdate(map[string]interface{}{"name": relica.NewExp("s.name"), "visits": relica.NewExp("u.visits + s.visits")}).
+		WhenNotMatchedInsert(map[string]interface{}{"id": relica.NewExp("s.id"), "name": relica.NewExp("s.name"), "visits": relica.NewExp("s.visits")}).
+		Execute()
+	require.NoError(t, err)
+
+	var rows []struct {
+		ID     int    `db:"id"`
+		Name   string `db:"name"`
+		Visits int    `db:"visits"`
+	}
+	require.NoError(t, db.Select("id", "name", "visits").From("users").OrderBy("id").All(&rows))
+	require.Len(t, rows, 3)
+	assert.Equal(t, "Ann", rows[0].Name)
+	assert.Equal(t, 15, rows[0].Visits)
+	assert.Equal(t, "bob", rows[1].Name)
+	assert.Equal(t, "carl", rows[2].Name)
+
+	_, err = db.Merge("users").UsingTable("staging", "s", "users.id = s.id").Keys("id").
+		WhenMatchedDelete().
+		WhenNotMatchedInsert(map[string]interface{}{"id": relica.NewExp("s.id")}).
+		Execute()
+	assert.Error(t, err)
+}
+
+type wrapperAccount struct {
+	ID      int `db:"id,pk"`
+	Balance int `db:"balance"`
+}
+
+func (wrapperAccount) TableName() string { return "accounts" }
+
+func TestWrapper_History(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithHistory("accounts"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE accounts (id INTEGER PRIMARY KEY, balance INTEGER)")
+	require.NoError(t, err)
+	require.NoError(t, db.CreateHistoryTable(ctx, "accounts"))
+
+	acc := wrapperAccount{ID: 1, Balance: 100}
+	require.NoError(t, db.Model(&acc).Insert())
+	time.Sleep(5 * time.Millisecond)
+	before := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	acc.Balance = 50
+	require.NoError(t, db.Model(&acc).Update())
+
+	var versions []wrapperAccount
+	require.NoError(t, db.Select().From("accounts"+relica.HistoryTableSuffix).All(&versions))
+	assert.Equal(t, []wrapperAccount{{ID: 1, Balance: 100}, {ID: 1, Balance: 100}}, versions, "the insert and the version before the update")
+
+	var then []wrapperAccount
+	require.NoError(t, db.Select().From("accounts").AsOf(before).All(&then))
+	assert.Equal(t, []wrapperAccount{{ID: 1, Balance: 100}}, then)
+
+	var now []wrapperAccount
+	require.NoError(t, db.Select().From("accounts").AsOf(time.Now()).All(&now))
+	assert.Equal(t, []wrapperAccount{{ID: 1, Balance: 50}}, now)
+}
+
+func TestWrapper_Coordinator(t *testing.T) {
+	ctx := context.Background()
+	open := func() *relica.DB {
+		db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, "CREATE TABLE entries (id INTEGER PRIMARY KEY, note TEXT)")
+		require.NoError(t, err)
+		return db
+	}
+	orders, billing := open(), open()
+	defer orders.Close()
+	defer billing.Close()
+
+	count := func(db *relica.DB) int {
+		var n int
+		require.NoError(t, db.Select("COUNT(*)").From("entries").Row(&n))
+		return n
+	}
+
+	err := relica.Coordinator(orders, billing).Transactional(ctx, func(ct *relica.CoordinatedTx) error {
+		if _, err := ct.Tx(0).Insert("entries", map[string]interface{}{"note": "order"}).Execute(); err != nil {
+			return err
+		}
+		ct.OnCompensate(0, func(ctx context.Context) error { return nil })
+		_, err := ct.Tx(1).Insert("entries", map[string]interface{}{"note": "invoice"}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count(orders))
+	assert.Equal(t, 1, count(billing))
+
+	errAbort := errors.New("abort")
+	err = relica.Coordinator(orders, billing).Transactional(ctx, func(ct *relica.CoordinatedTx) error {
+		_, err := ct.Tx(0).Insert("entries", map[string]interface{}{"note": "lost"}).Execute()
+		require.NoError(t, err)
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	assert.Equal(t, 1, count(orders))
+	assert.NotErrorIs(t, err, relica.ErrPartialCommit)
+}
+
+func TestWrapper_Replicas(t *testing.T) {
+	dir := t.TempDir()
+	primaryDSN, replicaDSN := filepath.Join(dir, "primary.db"), filepath.Join(dir, "replica.db")
+	for _, dsn := range []string{primaryDSN, replicaDSN} {
+		db, err := relica.Open("sqlite", dsn)
+		require.NoError(t, err)
+		_, err = db.ExecContext(context.Background(), "CREATE TABLE servers (name TEXT)")
+		require.NoError(t, err)
+		_, err = db.Insert("servers", map[string]interface{}{"name": filepath.Base(dsn)}).Execute()
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+	}
+
+	db, err := relica.Open("sqlite", primaryDSN,
+		relica.WithReplicas(replicaDSN),
+		relica.WithReplicaLagCheck(time.Hour, time.Second))
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.Eventually(t, func() bool { return db.Replicas()[0].LagKnown }, time.Second, time.Millisecond)
+	assert.Equal(t, "replica-0", db.Replicas()[0].Pool)
+
+	var name string
+	require.NoError(t, db.Select("name").From("servers").Row(&name))
+	assert.Equal(t, "replica.db", name)
+	require.NoError(t, db.Select("name").From("servers").RequireFresh(0).Row(&name))
+	assert.Equal(t, "replica.db", name, "SQLite replicas report no lag")
+	require.NoError(t, db.NewQuery("SELECT name FROM servers").Row(&name))
+	assert.Equal(t, "primary.db", name)
+}
+
+func TestWrapper_SessionSettings(t *testing.T) {
+	db, err := relica.Open("sqlite", filepath.Join(t.TempDir(), "session.db"),
+		relica.WithSessionSettings(map[string]string{"foreign_keys": "1"}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var fk int
+	require.NoError(t, db.NewQuery("PRAGMA foreign_keys").Row(&fk))
+	assert.Equal(t, 1, fk)
+
+	pool, err := db.Pool("bulk")
+	require.NoError(t, err)
+	pool.SetSessionSettings(map[string]string{"foreign_keys": "0"})
+	require.NoError(t, db.NewQuery("PRAGMA foreign_keys").OnPool("bulk").Row(&fk))
+	assert.Equal(t, 0, fk)
+}
+
+func TestWrapper_Conn(t *testing.T) {
+	db, err := relica.Open("sqlite", filepath.Join(t.TempDir(), "conn.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "CREATE TEMP TABLE scratch (note TEXT)")
+	require.NoError(t, err)
+	_, err = conn.Insert("scratch", map[string]interface{}{"note": "kept"}).Execute()
+	require.NoError(t, err)
+
+	var note string
+	require.NoError(t, conn.Select("note").From("scratch").Row(&note))
+	assert.Equal(t, "kept", note)
+	require.NoError(t, conn.Close())
+
+	var n int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM sqlite_master").Row(&n), "the DB stays open")
+}
+
+func TestWrapper_Pipeline(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+
+	affected, err := db.Pipeline(ctx, func(p *relica.Pipeline) {
+		p.Queue(db.Insert("events", map[string]interface{}{"name": "a"}))
+		p.Queue(db.Update("events").Set(map[string]interface{}{"name": "b"}).Where(relica.Eq("name", "a")).Build())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 1}, affected)
+
+	_, err = db.Pipeline(ctx, func(p *relica.Pipeline) {
+		p.Queue(db.Insert("events", map[string]interface{}{"name": "c"}))
+		p.Queue(db.Builder().InsertStruct("events", 42))
+	})
+	assert.Error(t, err)
+	var n int
+	require.NoError(t, db.Select("COUNT(*)").From("events").Row(&n))
+	assert.Equal(t, 1, n, "statements after a construction error are not run")
+}
+
+func TestWrapper_ColumnNulls(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithColumnNulls(relica.ColumnNullsSkip))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT)`)
+	require.NoError(t, err)
+	for _, email := range []interface{}{"a@example.com", nil, "c@example.com"} {
+		_, err = db.Insert("users", map[string]interface{}{"email": email}).Execute()
+		require.NoError(t, err)
+	}
+
+	var emails []string
+	require.NoError(t, db.Select("email").From("users").OrderBy("id").Column(&emails))
+	assert.Equal(t, []string{"a@example.com", "c@example.com"}, emails)
+
+	emails = nil
+	var valid []bool
+	require.NoError(t, db.NewQuery("SELECT email FROM users ORDER BY id").ColumnNullable(&emails, &valid))
+	assert.Equal(t, []string{"a@example.com", "", "c@example.com"}, emails)
+	assert.Equal(t, []bool{true, false, true}, valid)
+}
+
+func TestWrapper_PluckToMap(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE products (id INTEGER PRIMARY KEY, sku TEXT, price REAL)`)
+	require.NoError(t, err)
+	for _, sku := range []string{"a-1", "b-2"} {
+		_, err = db.Insert("products", map[string]interface{}{"sku": sku, "price": 9.5}).Execute()
+		require.NoError(t, err)
+	}
+
+	var ids []int
+	var skus []string
+	require.NoError(t, db.Select("id", "sku").From("products").OrderBy("id").Pluck(&ids, &skus))
+	assert.Equal(t, []int{1, 2}, ids)
+	assert.Equal(t, []string{"a-1", "b-2"}, skus)
+
+	var prices map[string]float64
+	require.NoError(t, db.Select("sku", "price").From("products").ToMap(&prices, "sku", "price"))
+	assert.Equal(t, map[string]float64{"a-1": 9.5, "b-2": 9.5}, prices)
+
+	var byID map[int64]string
+	require.NoError(t, db.NewQuery("SELECT id, sku FROM products").ToMap(&byID, "id", "sku"))
+	assert.Equal(t, "b-2", byID[2])
+}
+
+func TestWrapper_FirstLastTake(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE products (id INTEGER PRIMARY KEY, sku TEXT)`)
+	require.NoError(t, err)
+	for _, sku := range []string{"b-2", "a-1", "c-3"} {
+		_, err = db.Insert("products", map[string]interface{}{"sku": sku}).Execute()
+		require.NoError(t, err)
+	}
+
+	type product struct {
+		ID  int    `db:"id"`
+		SKU string `db:"sku"`
+	}
+	var p product
+	require.NoError(t, db.Select().From("products").First(&p))
+	assert.Equal(t, "b-2", p.SKU)
+	require.NoError(t, db.Select().From("products").Last(&p))
+	assert.Equal(t, "c-3", p.SKU)
+	require.NoError(t, db.Select().From("products").OrderBy("sku DESC").Last(&p))
+	assert.Equal(t, "a-1", p.SKU)
+	require.NoError(t, db.Select().From("products").Where("sku = ?", "a-1").Take(&p))
+	assert.Equal(t, 2, p.ID)
+}
+
+func TestWrapper_OrderByRandomSample(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	q := db.Select().From("events").Sample(10).OrderByRandom().Limit(5).Build()
+	assert.Equal(t, `SELECT * FROM "events" WHERE ABS(RANDOM() % 1000000) < 100000 ORDER BY RANDOM() LIMIT 5`, q.SQL())
+}
+
+func TestWrapper_ConditionBuilder(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE accounts (id INTEGER PRIMARY KEY, tenant INTEGER, expired INTEGER)`)
+	require.NoError(t, err)
+	for i := 1; i <= 4; i++ {
+		_, err = db.Insert("accounts", map[string]interface{}{"tenant": i % 2, "expired": i > 2}).Execute()
+		require.NoError(t, err)
+	}
+
+	filter := relica.NewCondition(relica.Eq("tenant", 1))
+	total, err := db.Select().From("accounts").WhereCond(filter).Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	_, err = db.Update("accounts").Set(map[string]interface{}{"tenant": 5}).WhereCond(filter.And("expired = ?", true)).Execute()
+	require.NoError(t, err)
+	_, err = db.Delete("accounts").WhereCond(filter).Execute()
+	require.NoError(t, err)
+
+	var ids []int
+	require.NoError(t, db.Select("id").From("accounts").OrderBy("id").Column(&ids))
+	assert.Equal(t, []int{2, 3, 4}, ids)
+}
+
+func TestWrapper_UpdateDeleteLimit(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE jobs (id INTEGER PRIMARY KEY, state TEXT)`)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err = db.Insert("jobs", map[string]interface{}{"state": "new"}).Execute()
+		require.NoError(t, err)
+	}
+
+	_, err = db.Update("jobs").Set(map[string]interface{}{"state": "queued"}).
+		Where("state = ?", "new").OrderBy("id").Limit(3).Execute()
+	require.NoError(t, err)
+	_, err = db.Delete("jobs").Where("state = ?", "queued").OrderBy("id DESC").Limit(1).Execute()
+	require.NoError(t, err)
+
+	var states []string
+	require.NoError(t, db.Select("state").From("jobs").OrderBy("id").Column(&states))
+	assert.Equal(t, []string{"queued", "queued", "new", "new"}, states)
+}
+
+func TestWrapper_DeferConstraints(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `PRAGMA foreign_keys = ON;
+		CREATE TABLE orders (id INTEGER PRIMARY KEY);
+		CREATE TABLE lines (id INTEGER PRIMARY KEY, order_id INTEGER NOT NULL REFERENCES orders (id))`)
+	require.NoError(t, err)
+
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		if err := tx.DeferConstraints(); err != nil {
+			return err
+		}
+		if _, err := tx.Insert("lines", map[string]interface{}{"id": 1, "order_id": 7}).Execute(); err != nil {
+			return err
+		}
+		_, err := tx.Insert("orders", map[string]interface{}{"id": 7}).Execute()
+		return err
+	})
+	require.NoError(t, err)
+
+	err = db.Transactional(ctx, func(tx *relica.Tx) error {
+		return tx.DisableForeignKeys()
+	})
+	assert.ErrorContains(t, err, "SQLite cannot disable foreign keys inside a transaction")
+}
+
+func TestWrapper_HealthCheckOptions(t *testing.T) {
+	changes := make(chan relica.HealthChange, 1)
+	db, err := relica.Open("sqlite", ":memory:", relica.WithHealthCheck(10*time.Millisecond,
+		relica.HealthCheckQuery("SELECT * FROM missing_table"),
+		relica.HealthCheckDegradedAfter(time.Second),
+		relica.HealthCheckOnChange(func(c relica.HealthChange) {
+			select {
+			case changes <- c:
+			default:
+			}
+		})))
+	require.NoError(t, err)
+	defer db.Close()
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, relica.HealthStateHealthy, c.From)
+		assert.Equal(t, relica.HealthStateUnhealthy, c.To)
+		assert.Error(t, c.Err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no health state change reported")
+	}
+	assert.False(t, db.IsHealthy())
+	assert.Equal(t, relica.HealthStateUnhealthy, db.Stats().HealthState)
+}
+
+func TestWrapper_WarmPool(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxIdleConns(3))
+	require.NoError(t, err)
+	defer db.Close()
+
+	n, err := db.WarmPool(context.Background(), 3, "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 3, db.Stats().Idle)
+}
+
+func TestWrapper_TracerSampling(t *testing.T) {
+	tracer := &spanRecorder{}
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1), relica.WithTracer(tracer,
+		relica.TraceSampleRate(0),
+		relica.TraceStatement(relica.TraceStatementNone)))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var n int
+	require.NoError(t, db.NewQuery("SELECT 1").Row(&n))
+	assert.Empty(t, tracer.names)
+	require.Error(t, db.NewQuery("SELECT * FROM missing").Row(&n))
+	assert.Equal(t, []string{"SELECT missing"}, tracer.names, "failed queries are always traced")
+}
+
+func TestWrapper_Fingerprint(t *testing.T) {
+	assert.Equal(t,
+		relica.Fingerprint("SELECT * FROM users WHERE id IN (1, 2, 3)"),
+		relica.Fingerprint("select * from users  where id in ($1, $2)"))
+	assert.Equal(t, "select * from users where id = ?", relica.NormalizeSQL("SELECT * FROM users WHERE id = 42"))
+}
+
+func TestWrapper_AuditTable(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithMaxOpenConns(1),
+		relica.WithAuditTable("", relica.AuditWrites, relica.AuditTableFlushInterval(time.Hour)))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := relica.WithAuditUser(context.Background(), "alice")
+	require.NoError(t, db.CreateAuditTable(ctx))
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE notes (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO notes (id) VALUES (?)", 1)
+	require.NoError(t, err)
+	require.NoError(t, db.FlushAuditLog(ctx))
+
+	var records []relica.AuditRecord
+	require.NoError(t, db.Select().From(relica.DefaultAuditTable).All(&records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "alice", records[0].User)
+	assert.Equal(t, "notes", records[0].Table)
+
+	n, err := db.PruneAuditLog(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestWrapper_ValidatorPolicies(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:",
+		relica.WithValidator(relica.ValidatorDenyTables("secrets"), relica.ValidatorMaxParams(1)))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE notes (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO notes (id) VALUES (?)", 1)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "SELECT * FROM secrets")
+	assert.Error(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO notes (id) VALUES (?), (?)", 2, 3)
+	assert.Error(t, err)
+
+	report, err := relica.Open("sqlite", ":memory:",
+		relica.WithValidator(relica.ValidatorAllowTables("notes"), relica.ValidatorReportOnly()))
+	require.NoError(t, err)
+	defer report.Close()
+	_, err = report.ExecContext(ctx, "CREATE TABLE other (id INTEGER PRIMARY KEY)")
+	assert.NoError(t, err)
+}
+
+func TestWrapper_StmtCacheShards(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithStmtCacheShards(2))
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, 2, db.StmtCacheStats().Shards)
+}
+
+func TestWrapper_TooManyParams(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ids := make([]interface{}, 40000)
+	for i := range ids {
+		ids[i] = i
+	}
+	var names []string
+	err = db.Select("name").From("users").Where(relica.In("id", ids...)).Column(&names)
+	require.ErrorIs(t, err, relica.ErrTooManyParams)
+	assert.Contains(t, err.Error(), "40000 parameters, SQLite allows at most 32766")
+}
+
+func TestWrapper_BoundLimits(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithBoundLimits())
+	require.NoError(t, err)
+	defer db.Close()
+
+	q := db.Select("id").From("users").Limit(20).Offset(40).Build()
+	assert.Equal(t, `SELECT "id" FROM "users" LIMIT ? OFFSET ?`, q.SQL())
+	assert.Equal(t, []interface{}{int64(20), int64(40)}, q.Params())
+
+	plain, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer plain.Close()
+	q = plain.Select("id").From("users").Limit(20).BindLimits().Build()
+	assert.Equal(t, `SELECT "id" FROM "users" LIMIT ?`, q.SQL())
+}
+
+func TestWrapper_BatchDelete(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE events (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	ids := make([]int64, 12)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+		_, err = db.Insert("events", map[string]interface{}{"id": ids[i]}).Execute()
+		require.NoError(t, err)
+	}
+
+	var chunks int
+	n, err := db.BatchDelete("events", "id").
+		Keys(ids[:10]).
+		ChunkSize(4).
+		Sleep(time.Millisecond).
+		Progress(func(relica.BatchDeleteProgress) { chunks++ }).
+		Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+	assert.Equal(t, 3, chunks)
+
+	var left int
+	require.NoError(t, db.NewQuery("SELECT COUNT(*) FROM events").Row(&left))
+	assert.Equal(t, 2, left)
+}
+
+func TestWrapper_TableStats(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	_, err = db.Insert("orders", map[string]interface{}{"id": 1}).Execute()
+	require.NoError(t, err)
+
+	stats, err := db.TableStats(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Rows)
+	assert.Positive(t, stats.TotalBytes)
+}
+
+func TestWrapper_Maintenance(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+
+	m := db.Maintenance()
+	assert.NoError(t, m.Analyze(ctx, "orders"))
+	assert.NoError(t, m.Vacuum(ctx, "orders", relica.Full()))
+	assert.NoError(t, m.OptimizeTable(ctx, "orders"))
+	assert.Error(t, m.OptimizeTable(ctx, ""))
+}
+
+func TestWrapper_QueryWatchdog(t *testing.T) {
+	db, err := relica.Open("sqlite", ":memory:", relica.WithQueryWatchdog(50*time.Millisecond))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var n int64
+	err = db.NewQuery(`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 1000000000)
+SELECT COUNT(*) FROM n`).Row(&n)
+	assert.ErrorIs(t, err, relica.ErrQueryWatchdog)
+	assert.ErrorIs(t, err, relica.ErrTimeout)
+
+	_, err = db.ActiveQueries(context.Background())
+	assert.ErrorIs(t, err, relica.ErrUnsupportedDialect)
+}